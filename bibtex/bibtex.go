@@ -0,0 +1,223 @@
+// Package bibtex parses BibTeX bibliographies:
+//
+//	@string{acm = "ACM Press"}
+//
+//	@inproceedings{knuth84,
+//	  author    = {Donald E. Knuth},
+//	  title     = {Literate {Programming}},
+//	  publisher = acm # ", New York",
+//	  year      = 1984,
+//	  month     = jan,
+//	}
+//
+// Entries, @string abbreviations and @preamble commands are delimited by braces or
+// parentheses. Field values are literals in braces or double quotes, which may contain
+// nested braces, numbers, and abbreviations, concatenated with '#'. Abbreviations must be
+// defined by an earlier @string command, except for the names of months, jan to dec. Text
+// outside of commands, and after @comment, is ignored, as BibTeX does. Entry types, field
+// names and abbreviations are not case sensitive and are returned in lower case.
+package bibtex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Part is a part of a field value: a Literal, a Number or an Abbrev.
+type Part interface {
+	// String returns the part as written in a bibliography.
+	String() string
+	// part is a method that all part types must implement.
+	// It serves as a marker for the part type.
+	part()
+}
+
+// Literal is text in braces or double quotes. Nested braces, which protect text from
+// changes of case, are kept in Text.
+type Literal struct {
+	Text string
+	// Quoted is set for text in double quotes.
+	Quoted bool
+}
+
+func (Literal) part() {}
+
+// String returns the text in braces or double quotes.
+func (l Literal) String() string {
+	if l.Quoted {
+		return `"` + l.Text + `"`
+	}
+	return "{" + l.Text + "}"
+}
+
+// Number is an unquoted number, e.g. 1984.
+type Number string
+
+func (Number) part() {}
+
+// String returns the number.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Abbrev is a reference to an abbreviation, in lower case.
+type Abbrev string
+
+func (Abbrev) part() {}
+
+// String returns the name of the abbreviation.
+func (a Abbrev) String() string {
+	return string(a)
+}
+
+// Value is a field value, the concatenation of its parts.
+type Value []Part
+
+// String returns the parts separated by " # ".
+func (v Value) String() string {
+	ps := make([]string, len(v))
+	for i, p := range v {
+		ps[i] = p.String()
+	}
+	return strings.Join(ps, " # ")
+}
+
+// Field is a field of an entry.
+type Field struct {
+	// Name is the name of the field in lower case, e.g. "author".
+	Name string
+	// Value is the value as written.
+	Value Value
+	// Text is the value with its abbreviations expanded and its parts concatenated, e.g.
+	// "ACM Press, New York".
+	Text string
+}
+
+// Entry is a bibliography entry, e.g. @article{key, ...}.
+type Entry struct {
+	// Type is the entry type in lower case, e.g. "article".
+	Type string
+	// Key is the cite key.
+	Key string
+	// Fields lists the fields of the entry in order.
+	Fields []Field
+}
+
+// Get returns the text of the named field, and false if the entry has no such field. The name
+// is not case sensitive.
+func (e *Entry) Get(name string) (string, bool) {
+	for _, f := range e.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f.Text, true
+		}
+	}
+	return "", false
+}
+
+// String returns the entry as written in a bibliography, with one field per line.
+func (e *Entry) String() string {
+	var b strings.Builder
+	b.WriteString("@" + e.Type + "{" + e.Key + ",\n")
+	for _, f := range e.Fields {
+		b.WriteString("  " + f.Name + " = " + f.Value.String() + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Bibliography is the content of a BibTeX file.
+type Bibliography struct {
+	// Preambles lists the expanded values of the @preamble commands.
+	Preambles []string
+	// Strings maps the names of the abbreviations defined by @string commands, in lower case,
+	// to their expanded values.
+	Strings map[string]string
+	// Entries lists the entries in order.
+	Entries []*Entry
+}
+
+// Lookup returns the entry with the cite key, compared without regard to case, or nil if
+// there is none.
+func (b *Bibliography) Lookup(key string) *Entry {
+	for _, e := range b.Entries {
+		if strings.EqualFold(e.Key, key) {
+			return e
+		}
+	}
+	return nil
+}
+
+// months are the abbreviations predefined by the standard bibliography styles.
+var months = map[string]string{
+	"jan": "January", "feb": "February", "mar": "March", "apr": "April",
+	"may": "May", "jun": "June", "jul": "July", "aug": "August",
+	"sep": "September", "oct": "October", "nov": "November", "dec": "December",
+}
+
+// Parse parses the BibTeX file src. It reports an error wrapping ErrUndefinedString for an
+// undefined abbreviation, and ErrDuplicateKey for a repeated cite key.
+func Parse(src string) (*Bibliography, error) {
+	cmds, err := parser.Run(file(), src)
+	if err != nil {
+		return nil, parser.Wrap(err, "bibtex")
+	}
+	b := &Bibliography{Strings: map[string]string{}}
+	keys := map[string]bool{}
+	for _, c := range cmds {
+		switch c.kind {
+		case "comment":
+		case "preamble":
+			s, err := b.expand(src, c.value)
+			if err != nil {
+				return nil, err
+			}
+			b.Preambles = append(b.Preambles, s)
+		case "string":
+			s, err := b.expand(src, c.value)
+			if err != nil {
+				return nil, err
+			}
+			b.Strings[c.key] = s
+		default:
+			if keys[strings.ToLower(c.key)] {
+				return nil, parser.ErrorAt("bibtex", src, c.offset, fmt.Errorf("%w: %s", ErrDuplicateKey, c.key))
+			}
+			keys[strings.ToLower(c.key)] = true
+			e := &Entry{Type: c.kind, Key: c.key}
+			for _, f := range c.fields {
+				s, err := b.expand(src, f.value)
+				if err != nil {
+					return nil, err
+				}
+				e.Fields = append(e.Fields, Field{Name: f.name, Value: f.value.parts, Text: s})
+			}
+			b.Entries = append(b.Entries, e)
+		}
+	}
+	return b, nil
+}
+
+// expand returns the text of v, replacing abbreviations by their values.
+func (b *Bibliography) expand(src string, v rawValue) (string, error) {
+	var sb strings.Builder
+	for i, p := range v.parts {
+		switch p := p.(type) {
+		case Literal:
+			sb.WriteString(p.Text)
+		case Number:
+			sb.WriteString(string(p))
+		case Abbrev:
+			s, ok := b.Strings[string(p)]
+			if !ok {
+				s, ok = months[string(p)]
+			}
+			if !ok {
+				return "", parser.ErrorAt("bibtex", src, v.offsets[i], fmt.Errorf("%w: %s", ErrUndefinedString, p))
+			}
+			sb.WriteString(s)
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,124 @@
+package bibtex_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/bibtex"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `This text is ignored.
+
+@String{acm = "ACM Press"}
+@preamble{ "\newcommand{\noop}[1]{}" }
+
+@InProceedings{knuth84,
+  Author    = {Donald E. Knuth},
+  title     = {Literate {Programming}},
+  publisher = acm # ", New York",
+  year      = 1984,
+  month     = jan,
+}
+
+@comment this is ignored too
+@misc(lamport94, title = "{LaTeX}: A Document {"}Preparation{"} System")
+`
+
+func TestParse(t *testing.T) {
+	b, err := bibtex.Parse(sample)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`\newcommand{\noop}[1]{}`}, b.Preambles)
+	assert.Equal(t, map[string]string{"acm": "ACM Press"}, b.Strings)
+	assert.Equal(t, []*bibtex.Entry{
+		{Type: "inproceedings", Key: "knuth84", Fields: []bibtex.Field{
+			{Name: "author", Value: bibtex.Value{bibtex.Literal{Text: "Donald E. Knuth"}}, Text: "Donald E. Knuth"},
+			{Name: "title", Value: bibtex.Value{bibtex.Literal{Text: "Literate {Programming}"}}, Text: "Literate {Programming}"},
+			{Name: "publisher", Value: bibtex.Value{bibtex.Abbrev("acm"), bibtex.Literal{Text: ", New York", Quoted: true}}, Text: "ACM Press, New York"},
+			{Name: "year", Value: bibtex.Value{bibtex.Number("1984")}, Text: "1984"},
+			{Name: "month", Value: bibtex.Value{bibtex.Abbrev("jan")}, Text: "January"},
+		}},
+		{Type: "misc", Key: "lamport94", Fields: []bibtex.Field{
+			{Name: "title", Value: bibtex.Value{bibtex.Literal{Text: `{LaTeX}: A Document {"}Preparation{"} System`, Quoted: true}}, Text: `{LaTeX}: A Document {"}Preparation{"} System`},
+		}},
+	}, b.Entries)
+
+	e := b.Lookup("KNUTH84")
+	if assert.NotNil(t, e) {
+		s, ok := e.Get("Publisher")
+		assert.True(t, ok)
+		assert.Equal(t, "ACM Press, New York", s)
+		_, ok = e.Get("volume")
+		assert.False(t, ok)
+	}
+	assert.Nil(t, b.Lookup("missing"))
+
+	tests := []struct {
+		input    string
+		expected []*bibtex.Entry
+	}{
+		{"", nil},
+		{"no entries here", nil},
+		{"@misc{key}", []*bibtex.Entry{{Type: "misc", Key: "key"}}},
+		{"@misc{key,}", []*bibtex.Entry{{Type: "misc", Key: "key"}}},
+		{"@misc { a:b/c-1 , note = {x} # {y} # 3 }", []*bibtex.Entry{{Type: "misc", Key: "a:b/c-1", Fields: []bibtex.Field{
+			{Name: "note", Value: bibtex.Value{bibtex.Literal{Text: "x"}, bibtex.Literal{Text: "y"}, bibtex.Number("3")}, Text: "xy3"},
+		}}}},
+		{"@string{a = {A}} @string{b = a # {B}} @misc{k, x = b # a}", []*bibtex.Entry{{Type: "misc", Key: "k", Fields: []bibtex.Field{
+			{Name: "x", Value: bibtex.Value{bibtex.Abbrev("b"), bibtex.Abbrev("a")}, Text: "ABA"},
+		}}}},
+		{"@misc{k, title = {Über {Ärger}}}", []*bibtex.Entry{{Type: "misc", Key: "k", Fields: []bibtex.Field{
+			{Name: "title", Value: bibtex.Value{bibtex.Literal{Text: "Über {Ärger}"}}, Text: "Über {Ärger}"},
+		}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			b, err := bibtex.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, b.Entries)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	b, err := bibtex.Parse(sample)
+	assert.NoError(t, err)
+	assert.Equal(t, `@inproceedings{knuth84,
+  author = {Donald E. Knuth},
+  title = {Literate {Programming}},
+  publisher = acm # ", New York",
+  year = 1984,
+  month = jan,
+}`, b.Entries[0].String())
+
+	again, err := bibtex.Parse(`@string{acm = "ACM Press"}` + b.Entries[0].String() + b.Entries[1].String())
+	assert.NoError(t, err)
+	assert.Equal(t, b.Entries, again.Entries)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"@misc{key, title = {a}", parser.ErrUnexpectedEOF, "bibtex: line 1, col 23: unexpected end of input"},
+		{"@misc{key title = {a}}", parser.ErrNoMatch, "bibtex: line 1, col 11: unexpected 't', expected ',' or '}'"},
+		{"@misc(key, title = {a}}", parser.ErrNoMatch, "bibtex: line 1, col 23: unexpected '}'"},
+		{"@misc{key,\n  title = }", parser.ErrNoMatch, "bibtex: line 2, col 11: unexpected '}', expected value"},
+		{"@misc{key,\n  title {a}}", parser.ErrNoMatch, "bibtex: line 2, col 9: unexpected '{', expected '='"},
+		{`@misc{key, title = "a}"}`, parser.ErrNoMatch, `bibtex: line 1, col 22: unexpected '}'`},
+		{"@misc{k1, x = {a}}\n@misc{K1, x = {b}}", bibtex.ErrDuplicateKey, "bibtex: line 2, col 7: duplicate cite key: K1"},
+		{"@misc{k,\n  publisher = acm # {x}}", bibtex.ErrUndefinedString, "bibtex: line 2, col 15: undefined string: acm"},
+		{"@misc{k, x = later}\n@string{later = {x}}", bibtex.ErrUndefinedString, "bibtex: line 1, col 14: undefined string: later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := bibtex.Parse(tt.input)
+			var perr *bibtex.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
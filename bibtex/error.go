@@ -0,0 +1,23 @@
+// Package bibtex provides error reporting for malformed bibliographies.
+package bibtex
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrUndefinedString is reported for a field value referring to an abbreviation that is
+	// neither defined by an earlier @string command nor the name of a month, e.g. jan.
+	ErrUndefinedString = errors.New("undefined string")
+	// ErrDuplicateKey is reported for an entry with the same cite key as an earlier one,
+	// compared without regard to case as BibTeX does.
+	ErrDuplicateKey = errors.New("duplicate cite key")
+)
+
+// ParseError describes why a bibliography could not be parsed. Expected lists what the bibliography
+// should contain at the error. Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping one of the errors of this
+// package.
+type ParseError = parser.Error
@@ -0,0 +1,173 @@
+// Package bibtex provides the grammar of BibTeX files, built with the tiny-parsec
+// combinators.
+package bibtex
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// isIdent reports whether r may appear in an entry type, a cite key, a field name or an
+// abbreviation, which BibTeX calls identifiers.
+func isIdent(r rune) bool {
+	return !isSpace(r) && !strings.ContainsRune(`"#%'(),={}`, r)
+}
+
+// command is an @ command as written, before abbreviations are expanded.
+type command struct {
+	// kind is the entry type in lower case, or "string", "preamble" or "comment".
+	kind string
+	// key is the cite key of an entry, or the name of an abbreviation defined by @string.
+	key string
+	// offset is the byte offset of the key.
+	offset int
+	// fields lists the fields of an entry.
+	fields []rawField
+	// value is the value of @string and @preamble.
+	value rawValue
+}
+
+type rawField struct {
+	name  string
+	value rawValue
+}
+
+// rawValue is a value with the byte offset of each of its parts, to report undefined
+// abbreviations.
+type rawValue struct {
+	parts   Value
+	offsets []int
+}
+
+// ws parses optional whitespace.
+func ws() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isSpace))
+}
+
+// lexeme parses p followed by optional whitespace.
+func lexeme[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitRight(p, ws())
+}
+
+// ident parses an identifier, returning it in lower case.
+func ident(expected string) parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isIdent, expected)), func(rs []rune) string {
+		return strings.ToLower(parser.Text(rs))
+	})
+}
+
+// braced parses text in balanced braces, returning it without the outer braces.
+func braced() parser.Parser[string] {
+	var p parser.Parser[string]
+	ref := parser.Lazy(func() parser.Parser[string] { return p })
+	plain := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r != '{' && r != '}' })), parser.Text)
+	inner := parser.Fmap(ref, func(s string) string { return "{" + s + "}" })
+	p = parser.Nested(parser.Fmap(parser.Between(parser.Char('{'), parser.ZeroOrMore(parser.OrElse(plain, inner)), parser.Char('}')), func(ss []string) string {
+		return strings.Join(ss, "")
+	}))
+	return p
+}
+
+// quoted parses text in double quotes, in which braces must be balanced and quotes may only
+// appear in braces.
+func quoted() parser.Parser[string] {
+	plain := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r != '"' && r != '{' && r != '}' })), parser.Text)
+	inner := parser.Fmap(braced(), func(s string) string { return "{" + s + "}" })
+	return parser.Fmap(parser.Between(parser.Char('"'), parser.ZeroOrMore(parser.OrElse(plain, inner)), parser.Char('"')), func(ss []string) string {
+		return strings.Join(ss, "")
+	})
+}
+
+// part parses a part of a value: a braced or quoted literal, a number or an abbreviation.
+func part() parser.Parser[Part] {
+	return parser.OrElse(
+		parser.Fmap(braced(), func(s string) Part { return Literal{Text: s} }),
+		parser.Fmap(quoted(), func(s string) Part { return Literal{Text: s, Quoted: true} }),
+		parser.Fmap(parser.Digits(), func(s string) Part { return Number(s) }),
+		parser.Fmap(ident("value"), func(s string) Part { return Abbrev(s) }),
+	)
+}
+
+// value parses parts concatenated with '#'.
+func value() parser.Parser[rawValue] {
+	located := lexeme(parser.Bind(parser.Pos(), func(offset int) parser.Parser[parser.Tuple[Part, int]] {
+		return parser.Fmap(part(), func(p Part) parser.Tuple[Part, int] { return parser.NewTuple(p, offset) })
+	}))
+	return parser.Fmap(parser.SepBy(located, lexeme(parser.Char('#'))), func(ts []parser.Tuple[Part, int]) rawValue {
+		var v rawValue
+		for _, t := range ts {
+			v.parts = append(v.parts, t.First)
+			v.offsets = append(v.offsets, t.Second)
+		}
+		return v
+	})
+}
+
+// nonEmpty parses a value of at least one part.
+func nonEmpty() parser.Parser[rawValue] {
+	return parser.SatisfyWithMsg(value(), func(v rawValue) bool { return len(v.parts) > 0 }, "value")
+}
+
+// assignment parses name = value, returning the name in lower case.
+func assignment() parser.Parser[rawField] {
+	return parser.Bind(lexeme(ident("field name")), func(name string) parser.Parser[rawField] {
+		return parser.Fmap(parser.OmitLeft(lexeme(parser.Char('=')), nonEmpty()), func(v rawValue) rawField {
+			return rawField{name: name, value: v}
+		})
+	})
+}
+
+// body parses p between braces or between parentheses.
+func body[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OrElse(
+		parser.Between(lexeme(parser.Char('{')), p, parser.Char('}')),
+		parser.Between(lexeme(parser.Char('(')), p, parser.Char(')')),
+	)
+}
+
+// entry parses the body of an entry: a cite key followed by comma-separated fields, with an
+// optional trailing comma.
+func entry(kind string) parser.Parser[command] {
+	key := lexeme(parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isIdent, "cite key")), parser.Text))
+	fields := parser.OmitRight(parser.ZeroOrMore(parser.OmitLeft(lexeme(parser.Char(',')), assignment())), parser.ZeroOrOne(lexeme(parser.Char(','))))
+	return body(parser.Bind(parser.Pos(), func(offset int) parser.Parser[command] {
+		return parser.Bind(key, func(key string) parser.Parser[command] {
+			return parser.Fmap(fields, func(fs []rawField) command {
+				return command{kind: kind, key: key, offset: offset, fields: fs}
+			})
+		})
+	}))
+}
+
+// atCommand parses an @ command, after the '@'.
+func atCommand() parser.Parser[command] {
+	return parser.Bind(lexeme(ident("entry type")), func(kind string) parser.Parser[command] {
+		switch kind {
+		case "comment":
+			return parser.Pure(command{kind: kind})
+		case "preamble":
+			return body(parser.Fmap(nonEmpty(), func(v rawValue) command {
+				return command{kind: kind, value: v}
+			}))
+		case "string":
+			return body(parser.Bind(parser.Pos(), func(offset int) parser.Parser[command] {
+				return parser.Fmap(assignment(), func(f rawField) command {
+					return command{kind: kind, key: f.name, offset: offset, value: f.value}
+				})
+			}))
+		}
+		return entry(kind)
+	})
+}
+
+// file parses the @ commands of a file. Text outside of commands is ignored, as BibTeX
+// does.
+func file() parser.Parser[[]command] {
+	junk := parser.ZeroOrMore(parser.NotChar('@'))
+	return parser.OmitLeft(junk, parser.ZeroOrMore(parser.OmitRight(parser.OmitLeft(parser.Char('@'), lexeme(atCommand())), junk)))
+}
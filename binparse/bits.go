@@ -0,0 +1,68 @@
+// Package binparse provides bit-level parsers for packed binary fields.
+package binparse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Bits runs the bit-level parser p over the next n bytes, which p must consume entirely.
+//
+// Inside Bits the input is a view of those bytes with one position per bit, most
+// significant bit first, so the offset of the parser state is the bit cursor.
+// Bit-level parsers such as TakeBits and Flag only work inside Bits, where they
+// combine with Bind, Fmap, Seq and the other combinators like any parser.
+// If p fails, the error returned by Run points at the byte p failed in and lists what p
+// expected, followed by the position of the bit in that byte, e.g. "4 bits at bit 6".
+func Bits[T any](n int, p parser.Parser[T]) parser.Parser[T] {
+	locate := func(offset int) (int, string) {
+		if offset == 8*n {
+			return n, ""
+		}
+		return offset / 8, fmt.Sprintf(" at bit %d", offset%8)
+	}
+	return within(n, p, bitView, locate, fmt.Sprintf("end of %d-byte bit field", n))
+}
+
+// TakeBits parses the next n bits as an unsigned integer, most significant bit first.
+// It panics if n is negative or greater than 64, the size of the result.
+func TakeBits(n int) parser.Parser[uint64] {
+	if n < 0 || n > 64 {
+		panic(fmt.Sprintf("binparse: TakeBits(%d): bit count must be between 0 and 64", n))
+	}
+	return parser.Fmap(take(n, fmt.Sprintf("%d bits", n)), func(bits string) uint64 {
+		var v uint64
+		for i := 0; i < len(bits); i++ {
+			v = v<<1 | uint64(bits[i]-'0')
+		}
+		return v
+	})
+}
+
+// Flag parses a single bit and reports whether it is set.
+func Flag() parser.Parser[bool] {
+	return parser.Fmap(take(1, "flag bit"), func(bit string) bool {
+		return bit == "1"
+	})
+}
+
+// SkipBits consumes n bits and discards them, e.g. for reserved fields.
+func SkipBits(n int) parser.Parser[struct{}] {
+	return parser.Fmap(take(n, fmt.Sprintf("%d bits", n)), func(string) struct{} {
+		return struct{}{}
+	})
+}
+
+// bitView expands each byte of s into eight '0' or '1' characters, most significant bit first.
+func bitView(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) * 8)
+	for i := 0; i < len(s); i++ {
+		for shift := 7; shift >= 0; shift-- {
+			b.WriteByte('0' + s[i]>>shift&1)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,243 @@
+// Package binparse provides parsers for binary data such as file headers and network frames.
+//
+// The parsers are ordinary parser.Parser values running over the bytes of the input,
+// so they combine with Bind, Fmap, OrElse and the rest of the tiny-parsec combinators.
+package binparse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Error describes why binary data did not match a parser.
+type Error struct {
+	// Offset is the byte offset of the failure in the data.
+	Offset int
+	// Expected lists what the parsers failing at Offset were looking for.
+	Expected []string
+	// Err is the underlying cause, either parser.ErrNoMatch or parser.ErrUnexpectedEOF.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("binparse: offset %d: %v", e.Offset, e.Err)
+	if len(e.Expected) > 0 {
+		msg += ", expected " + strings.Join(e.Expected, " or ")
+	}
+	return msg
+}
+
+// Unwrap returns the underlying cause so that errors.Is matches parser.ErrNoMatch and parser.ErrUnexpectedEOF.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Run parses all of data with p and returns the parsed value.
+// It fails with an *Error if p does not match or leaves data unconsumed.
+func Run[T any](p parser.Parser[T], data []byte) (T, error) {
+	return RunWith(p, data, parser.Options{})
+}
+
+// RunWith is like Run but enforces the limits in opts.
+// If a limit is exceeded the returned error is a *parser.LimitError.
+func RunWith[T any](p parser.Parser[T], data []byte, opts parser.Options) (T, error) {
+	v, err := parser.RunWith(p, string(data), opts)
+	var perr *parser.Error
+	if errors.As(err, &perr) {
+		return v, &Error{Offset: perr.Offset, Expected: perr.Expected, Err: perr.Err}
+	}
+	return v, err
+}
+
+// TakeN parses exactly n bytes and returns them.
+func TakeN(n int) parser.Parser[[]byte] {
+	return parser.Fmap(take(n, fmt.Sprintf("%d bytes", n)), func(s string) []byte {
+		return []byte(s)
+	})
+}
+
+// Skip consumes exactly n bytes and discards them, e.g. for padding or reserved fields.
+func Skip(n int) parser.Parser[struct{}] {
+	return parser.Fmap(take(n, fmt.Sprintf("%d bytes", n)), func(string) struct{} {
+		return struct{}{}
+	})
+}
+
+// Rest consumes and returns all remaining bytes.
+func Rest() parser.Parser[[]byte] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[[]byte] {
+		in := st.Input()
+		next, ok := st.Advance(len(in))
+		if !ok {
+			return parser.Nothing[parser.Tuple[[]byte, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple([]byte(in), next))
+	})
+}
+
+// Magic matches the exact byte sequence magic, such as a file signature, and returns it.
+func Magic(magic []byte) parser.Parser[[]byte] {
+	expected := fmt.Sprintf("magic % x", magic)
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[[]byte] {
+		in := st.Input()
+		n := min(len(in), len(magic))
+		if !bytes.Equal([]byte(in[:n]), magic[:n]) {
+			st.Fail(expected)
+			return parser.Nothing[parser.Tuple[[]byte, parser.State]]()
+		}
+		if n < len(magic) {
+			st.Truncated(expected)
+			return parser.Nothing[parser.Tuple[[]byte, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[[]byte, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(magic, next))
+	})
+}
+
+// Uint8 parses a single unsigned byte.
+func Uint8() parser.Parser[uint8] {
+	return parser.Fmap(take(1, "uint8"), func(s string) uint8 {
+		return s[0]
+	})
+}
+
+// Int8 parses a single signed byte.
+func Int8() parser.Parser[int8] {
+	return parser.Fmap(Uint8(), func(u uint8) int8 {
+		return int8(u)
+	})
+}
+
+// Uint16 parses a 2-byte unsigned integer in the given byte order.
+func Uint16(order binary.ByteOrder) parser.Parser[uint16] {
+	return parser.Fmap(take(2, "uint16"), func(s string) uint16 {
+		return order.Uint16([]byte(s))
+	})
+}
+
+// Int16 parses a 2-byte signed integer in the given byte order.
+func Int16(order binary.ByteOrder) parser.Parser[int16] {
+	return parser.Fmap(Uint16(order), func(u uint16) int16 {
+		return int16(u)
+	})
+}
+
+// Uint32 parses a 4-byte unsigned integer in the given byte order.
+func Uint32(order binary.ByteOrder) parser.Parser[uint32] {
+	return parser.Fmap(take(4, "uint32"), func(s string) uint32 {
+		return order.Uint32([]byte(s))
+	})
+}
+
+// Int32 parses a 4-byte signed integer in the given byte order.
+func Int32(order binary.ByteOrder) parser.Parser[int32] {
+	return parser.Fmap(Uint32(order), func(u uint32) int32 {
+		return int32(u)
+	})
+}
+
+// Uint64 parses an 8-byte unsigned integer in the given byte order.
+func Uint64(order binary.ByteOrder) parser.Parser[uint64] {
+	return parser.Fmap(take(8, "uint64"), func(s string) uint64 {
+		return order.Uint64([]byte(s))
+	})
+}
+
+// Int64 parses an 8-byte signed integer in the given byte order.
+func Int64(order binary.ByteOrder) parser.Parser[int64] {
+	return parser.Fmap(Uint64(order), func(u uint64) int64 {
+		return int64(u)
+	})
+}
+
+// Float32 parses a 4-byte IEEE 754 floating-point number in the given byte order.
+func Float32(order binary.ByteOrder) parser.Parser[float32] {
+	return parser.Fmap(Uint32(order), math.Float32frombits)
+}
+
+// Float64 parses an 8-byte IEEE 754 floating-point number in the given byte order.
+func Float64(order binary.ByteOrder) parser.Parser[float64] {
+	return parser.Fmap(Uint64(order), math.Float64frombits)
+}
+
+// LengthPrefixed parses a length with the given parser followed by that many bytes, and returns the bytes.
+func LengthPrefixed[L uint8 | uint16 | uint32 | uint64](length parser.Parser[L]) parser.Parser[[]byte] {
+	valid := parser.SatisfyWithMsg(length, func(n L) bool { return uint64(n) <= math.MaxInt32 }, fmt.Sprintf("length of at most %d", math.MaxInt32))
+	return parser.Bind(valid, func(n L) parser.Parser[[]byte] {
+		return TakeN(int(n))
+	})
+}
+
+// Sized runs p over exactly the next n bytes, which p must consume entirely.
+// It is used for fields whose size is known up front, such as a length-prefixed record.
+// If p fails, the error returned by Run points at the byte p failed at and lists what p expected.
+func Sized[T any](n int, p parser.Parser[T]) parser.Parser[T] {
+	return within(n, p, func(s string) string { return s }, func(offset int) (int, string) { return offset, "" }, fmt.Sprintf("end of %d-byte field", n))
+}
+
+// within runs p over view applied to the next n bytes, which p must consume entirely. A failure of
+// p is recorded at the byte of the outer input that locate maps its offset in the view to, with what
+// p expected followed by the suffix returned by locate, and with "end of input" replaced by end.
+func within[T any](n int, p parser.Parser[T], view func(string) string, locate func(offset int) (int, string), end string) parser.Parser[T] {
+	field := take(n, fmt.Sprintf("%d bytes", n))
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[T] {
+		m := field.RunState(st)
+		if m.IsNothing() {
+			return parser.Nothing[parser.Tuple[T, parser.State]]()
+		}
+		v, err := parser.Run(p, view(m.Get().First))
+		if err == nil {
+			return parser.Just(parser.NewTuple(v, m.Get().Second))
+		}
+		var perr *parser.Error
+		if !errors.As(err, &perr) {
+			return parser.Nothing[parser.Tuple[T, parser.State]]()
+		}
+		offset, suffix := locate(perr.Offset)
+		at, ok := st.Advance(offset)
+		if !ok {
+			return parser.Nothing[parser.Tuple[T, parser.State]]()
+		}
+		if len(perr.Expected) == 0 {
+			at.Fail(perr.Err.Error() + suffix)
+		}
+		for _, e := range perr.Expected {
+			if e == "end of input" {
+				e = end
+			}
+			at.Fail(e + suffix)
+		}
+		return parser.Nothing[parser.Tuple[T, parser.State]]()
+	})
+}
+
+// take consumes exactly n bytes and returns them as a string without copying.
+// It fails for a negative n, e.g. a length read from a signed field.
+func take(n int, expected string) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		in := st.Input()
+		if n < 0 {
+			st.Fail("length of at least 0")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		if len(in) < n {
+			st.Truncated(expected)
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(in[:n], next))
+	})
+}
@@ -0,0 +1,203 @@
+package binparse_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/81120/tiny-parsec/binparse"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type chunk struct {
+	Type string
+	Data []byte
+}
+
+func pngChunk() parser.Parser[chunk] {
+	return parser.Bind(binparse.Uint32(binary.BigEndian), func(n uint32) parser.Parser[chunk] {
+		return parser.Bind(binparse.TakeN(4), func(typ []byte) parser.Parser[chunk] {
+			return parser.OmitRight(
+				parser.Fmap(binparse.TakeN(int(n)), func(data []byte) chunk {
+					return chunk{Type: string(typ), Data: data}
+				}),
+				binparse.Skip(4), // CRC
+			)
+		})
+	})
+}
+
+func TestIntegers(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03, 0x04}
+
+	t.Run("big endian", func(t *testing.T) {
+		v, err := binparse.Run(binparse.Uint32(binary.BigEndian), data)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0x01020304), v)
+	})
+
+	t.Run("little endian", func(t *testing.T) {
+		v, err := binparse.Run(parser.Seq(binparse.Uint16(binary.LittleEndian), binparse.Uint16(binary.LittleEndian)), data)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint16{0x0201, 0x0403}, v)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		v, err := binparse.Run(binparse.Int16(binary.BigEndian), []byte{0xff, 0xfe})
+		assert.NoError(t, err)
+		assert.Equal(t, int16(-2), v)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := binparse.Run(binparse.Uint64(binary.BigEndian), data)
+		assert.ErrorIs(t, err, parser.ErrUnexpectedEOF)
+		var berr *binparse.Error
+		assert.ErrorAs(t, err, &berr)
+		assert.Equal(t, 4, berr.Offset)
+	})
+}
+
+func TestPNG(t *testing.T) {
+	signature := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	png := parser.OmitLeft(binparse.Magic(signature), parser.OneOrMore(pngChunk()))
+
+	data := append([]byte{}, signature...)
+	data = append(data, 0, 0, 0, 2, 'I', 'H', 'D', 'R', 0xaa, 0xbb, 1, 2, 3, 4)
+	data = append(data, 0, 0, 0, 0, 'I', 'E', 'N', 'D', 5, 6, 7, 8)
+
+	t.Run("valid file", func(t *testing.T) {
+		chunks, err := binparse.Run(png, data)
+		assert.NoError(t, err)
+		assert.Equal(t, []chunk{
+			{Type: "IHDR", Data: []byte{0xaa, 0xbb}},
+			{Type: "IEND", Data: []byte{}},
+		}, chunks)
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		_, err := binparse.Run(png, []byte("GIF89a"))
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.ErrorContains(t, err, "binparse: offset 0")
+	})
+}
+
+func TestLengthPrefixed(t *testing.T) {
+	t.Run("uint8 length", func(t *testing.T) {
+		v, err := binparse.Run(binparse.LengthPrefixed(binparse.Uint8()), []byte{3, 'a', 'b', 'c'})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("abc"), v)
+	})
+
+	t.Run("sized record", func(t *testing.T) {
+		record := parser.Bind(binparse.Uint8(), func(n uint8) parser.Parser[[]uint8] {
+			return binparse.Sized(int(n), parser.ZeroOrMore(binparse.Uint8()))
+		})
+		v, err := binparse.Run(parser.Seq(record, record), []byte{2, 7, 8, 1, 9})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]uint8{{7, 8}, {9}}, v)
+	})
+
+	t.Run("sized errors", func(t *testing.T) {
+		pair := binparse.Sized(3, parser.OmitLeft(binparse.Uint16(binary.BigEndian), binparse.Magic([]byte{0xff})))
+		_, err := binparse.Run(parser.OmitLeft(binparse.Skip(1), pair), []byte{0, 1, 2, 3, 4})
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.EqualError(t, err, "binparse: offset 3: no match, expected magic ff")
+
+		_, err = binparse.Run(binparse.Sized(3, binparse.Uint16(binary.BigEndian)), []byte{1, 2, 3})
+		assert.EqualError(t, err, "binparse: offset 2: no match, expected end of 3-byte field")
+
+		_, err = binparse.Run(binparse.Sized(2, binparse.Uint32(binary.BigEndian)), []byte{1, 2, 3, 4})
+		assert.EqualError(t, err, "binparse: offset 2: no match, expected uint32")
+
+		either := parser.OrElse(binparse.Sized(2, binparse.Magic([]byte("v1"))), binparse.TakeN(2))
+		v, err := binparse.Run(either, []byte("v2"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v2"), v)
+	})
+
+	t.Run("length too large", func(t *testing.T) {
+		_, err := binparse.Run(binparse.LengthPrefixed(binparse.Uint32(binary.BigEndian)), []byte{0xff, 0xff, 0xff, 0xff})
+		assert.ErrorContains(t, err, "binparse: offset 0: no match, expected length of at most 2147483647")
+	})
+
+	t.Run("negative length", func(t *testing.T) {
+		signed := parser.Bind(binparse.Int8(), func(n int8) parser.Parser[[]byte] {
+			return binparse.TakeN(int(n))
+		})
+		_, err := binparse.Run(signed, []byte{0xff, 'a'})
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.ErrorContains(t, err, "binparse: offset 1: no match, expected length of at least 0")
+		_, err = binparse.Run(binparse.Sized(-1, binparse.Rest()), []byte("a"))
+		assert.ErrorContains(t, err, "expected length of at least 0")
+		_, err = binparse.Run(binparse.Skip(-2), nil)
+		assert.ErrorContains(t, err, "expected length of at least 0")
+	})
+
+	t.Run("alternatives", func(t *testing.T) {
+		p := parser.OrElse(binparse.Magic([]byte("v1")), binparse.Magic([]byte("v2")))
+		v, err := binparse.Run(p, []byte("v2"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("v2"), v)
+	})
+}
+
+type dnsFlags struct {
+	Response  bool
+	Opcode    uint64
+	Recursion bool
+	RCode     uint64
+}
+
+func TestBits(t *testing.T) {
+	flags := binparse.Bits(2,
+		parser.Bind(binparse.Flag(), func(qr bool) parser.Parser[dnsFlags] {
+			return parser.Bind(binparse.TakeBits(4), func(opcode uint64) parser.Parser[dnsFlags] {
+				return parser.Bind(parser.OmitLeft(binparse.SkipBits(2), binparse.Flag()), func(rd bool) parser.Parser[dnsFlags] {
+					return parser.Fmap(parser.OmitLeft(binparse.SkipBits(4), binparse.TakeBits(4)), func(rcode uint64) dnsFlags {
+						return dnsFlags{Response: qr, Opcode: opcode, Recursion: rd, RCode: rcode}
+					})
+				})
+			})
+		}))
+
+	t.Run("dns header flags", func(t *testing.T) {
+		header := parser.Seq(
+			parser.Fmap(binparse.Uint16(binary.BigEndian), func(id uint16) dnsFlags { return dnsFlags{Opcode: uint64(id)} }),
+			flags,
+		)
+		v, err := binparse.Run(header, []byte{0x12, 0x34, 0x81, 0x83})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0x1234), v[0].Opcode)
+		assert.Equal(t, dnsFlags{Response: true, Opcode: 0, Recursion: true, RCode: 3}, v[1])
+	})
+
+	t.Run("fields must fill the bytes", func(t *testing.T) {
+		_, err := binparse.Run(binparse.Bits(1, binparse.TakeBits(4)), []byte{0xff})
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.EqualError(t, err, "binparse: offset 0: no match, expected end of 1-byte bit field at bit 4")
+
+		_, err = binparse.Run(binparse.Bits(1, binparse.TakeBits(12)), []byte{0xff, 0})
+		assert.EqualError(t, err, "binparse: offset 1: no match, expected 12 bits")
+
+		_, err = binparse.Run(binparse.Bits(2, parser.OmitLeft(binparse.SkipBits(14), binparse.Flag())), []byte{0xff, 0x01})
+		assert.EqualError(t, err, "binparse: offset 1: no match, expected end of 2-byte bit field at bit 7")
+	})
+
+	t.Run("bit count out of range", func(t *testing.T) {
+		assert.PanicsWithValue(t, "binparse: TakeBits(65): bit count must be between 0 and 64", func() { binparse.TakeBits(65) })
+		assert.PanicsWithValue(t, "binparse: TakeBits(-1): bit count must be between 0 and 64", func() { binparse.TakeBits(-1) })
+		v, err := binparse.Run(binparse.Bits(8, binparse.TakeBits(64)), []byte{0x80, 0, 0, 0, 0, 0, 0, 1})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1<<63|1), v)
+	})
+
+	t.Run("alternatives on bit patterns", func(t *testing.T) {
+		version := parser.OrElse(
+			parser.OmitLeft(parser.SatisfyWith(binparse.TakeBits(4), func(v uint64) bool { return v == 4 }), binparse.TakeBits(4)),
+			parser.OmitLeft(binparse.TakeBits(4), parser.Pure(uint64(0))),
+		)
+		v, err := binparse.Run(binparse.Bits(1, version), []byte{0x45})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), v)
+	})
+}
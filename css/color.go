@@ -0,0 +1,54 @@
+// Package css parses CSS color values into normalized RGBA colors:
+//
+//	#f80, #ff8800cc
+//	rgb(255, 136, 0), rgba(255 136 0 / 80%)
+//	hsl(32deg, 100%, 50%), hsla(0.09turn 100% 50% / 0.8)
+//	orange, RebeccaPurple, transparent
+//
+// Colors are written back in hex notation by RGBA.Hex.
+package css
+
+import (
+	"fmt"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// RGBA is a color in the sRGB color space with an alpha channel, which is not premultiplied.
+type RGBA struct {
+	R, G, B uint8
+	// A is the opacity of the color, from 0 for transparent to 255 for opaque.
+	A uint8
+}
+
+// Hex returns the color in hex notation, "#rrggbb" for opaque colors and "#rrggbbaa"
+// otherwise.
+func (c RGBA) Hex() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// String returns the color in hex notation, as Hex does.
+func (c RGBA) String() string {
+	return c.Hex()
+}
+
+// ParseColor parses a CSS color value in hex notation, as a rgb(), rgba(), hsl() or hsla()
+// function, or as one of the named colors of CSS Color Level 4, including "transparent".
+// Function and color names are case-insensitive.
+//
+// Functions accept both the legacy comma-separated syntax and the modern space-separated
+// one, which separates the alpha with a "/". Channels are numbers from 0 to 255 or
+// percentages, hues are numbers of degrees or angles in deg, grad, rad or turn, and alphas
+// are numbers from 0 to 1 or percentages. Values out of range are clamped and fractions are
+// rounded to the nearest of the 256 levels of each channel. The "none" keyword and
+// currentColor are not supported.
+func ParseColor(s string) (RGBA, error) {
+	c, err := parser.Run(color(), s)
+	if err != nil {
+		return RGBA{}, parser.Wrap(err, "css")
+	}
+	return c, nil
+}
@@ -0,0 +1,80 @@
+package css_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/css"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected css.RGBA
+	}{
+		{"#f80", css.RGBA{255, 136, 0, 255}},
+		{"#F80C", css.RGBA{255, 136, 0, 204}},
+		{"#ff8800", css.RGBA{255, 136, 0, 255}},
+		{"#ff880080", css.RGBA{255, 136, 0, 128}},
+		{"rgb(255, 136, 0)", css.RGBA{255, 136, 0, 255}},
+		{"rgba(255,136,0,.5)", css.RGBA{255, 136, 0, 128}},
+		{"RGB(100%, 50%, 0%)", css.RGBA{255, 128, 0, 255}},
+		{"rgb(255 136 0 / 25%)", css.RGBA{255, 136, 0, 64}},
+		{"rgba( 300 -10 127.5 )", css.RGBA{255, 0, 128, 255}},
+		{"rgb(1e2 0 0 / 2)", css.RGBA{100, 0, 0, 255}},
+		{"hsl(120, 100%, 50%)", css.RGBA{0, 255, 0, 255}},
+		{"hsla(240 100% 50% / 0.5)", css.RGBA{0, 0, 255, 128}},
+		{"hsl(0.5turn 100 25)", css.RGBA{0, 128, 128, 255}},
+		{"hsl(-120deg, 100%, 50%)", css.RGBA{0, 0, 255, 255}},
+		{"hsl(200grad 0% 100%)", css.RGBA{255, 255, 255, 255}},
+		{"hsl(30, 100%, 50%)", css.RGBA{255, 128, 0, 255}},
+		{"RebeccaPurple", css.RGBA{102, 51, 153, 255}},
+		{" red ", css.RGBA{255, 0, 0, 255}},
+		{"transparent", css.RGBA{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := css.ParseColor(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, c)
+		})
+	}
+}
+
+func TestHex(t *testing.T) {
+	assert.Equal(t, "#ff8800", css.RGBA{255, 136, 0, 255}.Hex())
+	assert.Equal(t, "#0a0b0c80", css.RGBA{10, 11, 12, 128}.String())
+
+	for _, s := range []string{"#663399", "#00000000", "#12345678"} {
+		c, err := css.ParseColor(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, c.Hex())
+	}
+}
+
+func TestParseColorErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"#ff888", parser.ErrNoMatch, "css: line 1, col 2: unexpected 'f', expected 3, 4, 6 or 8 hex digits"},
+		{"#ggg", parser.ErrNoMatch, "css: line 1, col 2: unexpected 'g', expected hex digit"},
+		{"bluish", parser.ErrNoMatch, "css: line 1, col 1: unexpected 'b', expected color"},
+		{"rgb(1, 2)", parser.ErrNoMatch, "css: line 1, col 9: unexpected ')'"},
+		{"rgb(1, 2 3)", parser.ErrNoMatch, "css: line 1, col 10: unexpected '3', expected ','"},
+		{"rgb(1 2 3, 1)", parser.ErrNoMatch, "css: line 1, col 10: unexpected ','"},
+		{"hsl(1foo 0% 0%)", parser.ErrNoMatch, "css: line 1, col 6: unexpected 'f', expected angle unit"},
+		{"rgb(1, 2, 3", parser.ErrUnexpectedEOF, "css: line 1, col 12: unexpected end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := css.ParseColor(tt.input)
+			var perr *css.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Package css provides error reporting for malformed CSS values.
+package css
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a CSS value could not be parsed. Expected lists what the value should
+// contain at the error, e.g. "number".
+type ParseError = parser.Error
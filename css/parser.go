@@ -0,0 +1,267 @@
+// Package css provides the grammar of CSS color values, built with the tiny-parsec
+// combinators.
+package css
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// units maps the angle units of hues to their size in degrees.
+var units = map[string]float64{
+	"deg":  1,
+	"grad": 0.9,
+	"rad":  180 / math.Pi,
+	"turn": 360,
+}
+
+// ws parses optional whitespace.
+func ws() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isSpace))
+}
+
+// word parses a keyword, such as a color name or an angle unit, and returns it in lower case.
+func word() parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.Satisfy(isLetter)), func(rs []rune) string {
+		return strings.ToLower(parser.Text(rs))
+	})
+}
+
+// numberLen returns the length of the CSS number at the start of s, or 0 if there is none.
+func numberLen(s string) int {
+	i := 0
+	digits := func() int {
+		start := i
+		for i < len(s) && isDigit(rune(s[i])) {
+			i++
+		}
+		return i - start
+	}
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	n := digits()
+	if i+1 < len(s) && s[i] == '.' && isDigit(rune(s[i+1])) {
+		i++
+		n += digits()
+	}
+	if n == 0 {
+		return 0
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isDigit(rune(s[j])) {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+// number parses a CSS number, e.g. "12", "-.5" or "1e3".
+func number() parser.Parser[float64] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[float64] {
+		s := st.Input()
+		n := numberLen(s)
+		if n == 0 {
+			if s == "" {
+				st.Truncated("number")
+			} else {
+				st.Fail("number")
+			}
+			return parser.Nothing[parser.Tuple[float64, parser.State]]()
+		}
+		v, _ := strconv.ParseFloat(s[:n], 64)
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[float64, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(v, next))
+	})
+}
+
+// numeric parses a number, optionally followed by '%', and returns it scaled by plain, or
+// scaled so that 100% is percent.
+func numeric(plain, percent float64) parser.Parser[float64] {
+	return parser.Bind(number(), func(v float64) parser.Parser[float64] {
+		return parser.Fmap(parser.ZeroOrOne(parser.Char('%')), func(m parser.Maybe[rune]) float64 {
+			if m.IsJust() {
+				return v * percent / 100
+			}
+			return v * plain
+		})
+	})
+}
+
+// channel parses a red, green or blue channel, from 0 to 255 or 0% to 100%.
+func channel() parser.Parser[float64] {
+	return numeric(1, 255)
+}
+
+// alpha parses an opacity, from 0 to 1 or 0% to 100%.
+func alpha() parser.Parser[float64] {
+	return numeric(1, 1)
+}
+
+// percentage parses the saturation or lightness of a hsl() color as a fraction. The "%" is
+// optional, as in the space-separated syntax.
+func percentage() parser.Parser[float64] {
+	return numeric(0.01, 1)
+}
+
+// hue parses a hue in degrees, given as a number or an angle.
+func hue() parser.Parser[float64] {
+	unit := parser.SatisfyWithMsg(parser.ZeroOrOne(word()), func(m parser.Maybe[string]) bool {
+		_, ok := units[m.Get()]
+		return m.IsNothing() || ok
+	}, "angle unit")
+	return parser.Bind(number(), func(v float64) parser.Parser[float64] {
+		return parser.Fmap(unit, func(m parser.Maybe[string]) float64 {
+			if m.IsNothing() {
+				return v
+			}
+			return v * units[m.Get()]
+		})
+	})
+}
+
+// components parses the arguments of a color function: three components, the first parsed
+// by first and the others by rest, and an optional alpha, defaulting to 1. They are
+// separated by commas in the legacy syntax, "rgb(255, 0, 0, 0.5)", and by whitespace with a
+// "/" before the alpha in the modern one, "rgb(255 0 0 / 50%)".
+func components(first, rest parser.Parser[float64]) parser.Parser[[4]float64] {
+	comma := parser.Between(ws(), parser.Char(','), ws())
+	space := parser.OmitLeft(parser.SatisfyMsg(isSpace, "whitespace"), ws())
+	slash := parser.Between(ws(), parser.Char('/'), ws())
+	opacity := func(sep parser.Parser[rune]) parser.Parser[float64] {
+		return parser.Fmap(parser.ZeroOrOne(parser.OmitLeft(sep, alpha())), func(m parser.Maybe[float64]) float64 {
+			if m.IsJust() {
+				return m.Get()
+			}
+			return 1
+		})
+	}
+	legacy := parser.Seq(parser.OmitLeft(comma, rest), parser.OmitLeft(comma, rest), opacity(comma))
+	modern := parser.Seq(parser.OmitLeft(space, rest), parser.OmitLeft(space, rest), opacity(slash))
+	return parser.Bind(first, func(v float64) parser.Parser[[4]float64] {
+		return parser.Fmap(parser.OrElse(legacy, modern), func(vs []float64) [4]float64 {
+			return [4]float64{v, vs[0], vs[1], vs[2]}
+		})
+	})
+}
+
+// call parses the parenthesized arguments of a color function with p.
+func call(p parser.Parser[RGBA]) parser.Parser[RGBA] {
+	return parser.Between(parser.OmitRight(parser.Char('('), ws()), p, parser.OmitLeft(ws(), parser.Char(')')))
+}
+
+// rgb parses the arguments of rgb() and rgba().
+func rgb() parser.Parser[RGBA] {
+	return parser.Fmap(components(channel(), channel()), func(v [4]float64) RGBA {
+		return rgba(v[0], v[1], v[2], v[3])
+	})
+}
+
+// hsl parses the arguments of hsl() and hsla().
+func hsl() parser.Parser[RGBA] {
+	return parser.Fmap(components(hue(), percentage()), func(v [4]float64) RGBA {
+		r, g, b := hslToRGB(v[0], v[1], v[2])
+		return rgba(r*255, g*255, b*255, v[3])
+	})
+}
+
+// hex parses a color in hex notation: "#rgb", "#rgba", "#rrggbb" or "#rrggbbaa".
+func hex() parser.Parser[RGBA] {
+	digits := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isHexDigit, "hex digit")), parser.Text)
+	valid := parser.SatisfyWithMsg(digits, func(s string) bool {
+		return len(s) == 3 || len(s) == 4 || len(s) == 6 || len(s) == 8
+	}, "3, 4, 6 or 8 hex digits")
+	return parser.OmitLeft(parser.Char('#'), parser.Fmap(valid, func(s string) RGBA {
+		if len(s) <= 4 {
+			var b strings.Builder
+			for _, c := range s {
+				b.WriteRune(c)
+				b.WriteRune(c)
+			}
+			s = b.String()
+		}
+		if len(s) == 6 {
+			s += "ff"
+		}
+		v, _ := strconv.ParseUint(s, 16, 32)
+		return RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+	}))
+}
+
+// keyword parses a color function or a named color.
+func keyword() parser.Parser[RGBA] {
+	name := parser.SatisfyWithMsg(word(), func(s string) bool {
+		_, ok := namedColors[s]
+		return ok || s == "rgb" || s == "rgba" || s == "hsl" || s == "hsla"
+	}, "color")
+	return parser.Bind(name, func(s string) parser.Parser[RGBA] {
+		switch s {
+		case "rgb", "rgba":
+			return call(rgb())
+		case "hsl", "hsla":
+			return call(hsl())
+		}
+		return parser.Pure(namedColors[s])
+	})
+}
+
+// color parses a color value, surrounded by optional whitespace.
+func color() parser.Parser[RGBA] {
+	return parser.Between(ws(), parser.OrElse(hex(), keyword()), ws())
+}
+
+// hslToRGB converts a hue in degrees and a saturation and lightness between 0 and 1 to red,
+// green and blue between 0 and 1, as specified by CSS Color Level 4.
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s, l = clamp(s, 0, 1), clamp(l, 0, 1)
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return f(0), f(8), f(4)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// rgba rounds channels between 0 and 255 and an alpha between 0 and 1 to an RGBA, clamping
+// values out of range.
+func rgba(r, g, b, a float64) RGBA {
+	c := func(v float64) uint8 {
+		return uint8(math.Round(clamp(v, 0, 255)))
+	}
+	return RGBA{R: c(r), G: c(g), B: c(b), A: c(a * 255)}
+}
@@ -0,0 +1,23 @@
+// Package csv provides error reporting for malformed CSV documents.
+package csv
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrFieldCount is reported for a record with a different number of fields than the first one.
+	ErrFieldCount = errors.New("wrong number of fields")
+	// ErrDuplicateHeader is reported by ParseMaps for a header naming a column more than once.
+	ErrDuplicateHeader = errors.New("duplicate column name")
+	// ErrInvalidDelimiter is reported for a delimiter, quote or comment prefix that is a line
+	// break or the same as another.
+	ErrInvalidDelimiter = errors.New("invalid delimiter, quote or comment prefix")
+)
+
+// ParseError describes why a CSV document could not be parsed. Expected lists what the document
+// should contain at the error. Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or ErrFieldCount or ErrDuplicateHeader.
+type ParseError = parser.Error
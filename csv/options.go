@@ -0,0 +1,91 @@
+// Package csv provides options that configure the CSV grammar.
+package csv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Option configures the CSV grammar built by Document, Parse and the other parsers of this package.
+type Option func(*config)
+
+// config holds the settings selected by options.
+type config struct {
+	// variableFields accepts records with different numbers of fields.
+	variableFields bool
+	// delimiter separates the fields of a record.
+	delimiter string
+	// quote encloses quoted fields; quoting is disabled if it is empty.
+	quote string
+	// comment starts comment lines; there are none if it is empty.
+	comment string
+	// lazyQuotes accepts quotes in unquoted fields and single quotes in quoted fields.
+	lazyQuotes bool
+}
+
+// newConfig applies opts to the default configuration.
+func newConfig(opts []Option) *config {
+	c := &config{delimiter: ",", quote: `"`}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// VariableFields accepts records with a different number of fields than the first record,
+// which RFC 4180 does not allow and Parse rejects by default. It does not apply to ParseMaps.
+func VariableFields() Option {
+	return func(c *config) {
+		c.variableFields = true
+	}
+}
+
+// Delimiter separates the fields of records with r instead of a comma, e.g. '\t' for
+// tab-separated values or ';' for the CSV files of locales using a decimal comma. The delimiter
+// must not be a line break or the quote, otherwise Parse and ParseMaps fail with ErrInvalidDelimiter.
+func Delimiter(r rune) Option {
+	return func(c *config) {
+		c.delimiter = string(r)
+	}
+}
+
+// Quote encloses quoted fields in r instead of a double quote; it is also doubled to write it
+// within quoted fields. Quote(0) disables quoting, so that quotes are ordinary characters as in
+// most tab-separated files.
+func Quote(r rune) Option {
+	return func(c *config) {
+		c.quote = ""
+		if r != 0 {
+			c.quote = string(r)
+		}
+	}
+}
+
+// Comment skips the lines starting with prefix, e.g. "#", between records. A line within a quoted
+// field is not a comment, and neither is a line whose prefix is preceded by whitespace.
+func Comment(prefix string) Option {
+	return func(c *config) {
+		c.comment = prefix
+	}
+}
+
+// LazyQuotes accepts malformed quotes as written by some spreadsheet exports, like the option of
+// the same name of encoding/csv: a quote may appear in an unquoted field, and a single quote in a
+// quoted field is kept as is unless it is followed by a delimiter or a line break. A quoted field
+// that is not closed extends to the end of the input.
+func LazyQuotes() Option {
+	return func(c *config) {
+		c.lazyQuotes = true
+	}
+}
+
+// check reports whether the delimiter, quote and comment prefix of c can be told apart.
+func (c *config) check() error {
+	switch {
+	case c.delimiter == "\x00", strings.ContainsAny(c.delimiter+c.quote, "\r\n"), c.delimiter == c.quote:
+	case c.comment != "" && (strings.HasPrefix(c.comment, c.delimiter) || c.quote != "" && strings.HasPrefix(c.comment, c.quote)):
+	default:
+		return nil
+	}
+	return fmt.Errorf("csv: %w", ErrInvalidDelimiter)
+}
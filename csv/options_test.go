@@ -0,0 +1,58 @@
+package csv_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []csv.Option
+		want  [][]string
+	}{
+		{"semicolon", "name;price\n\"a;b\";1,50\n", []csv.Option{csv.Delimiter(';')}, [][]string{{"name", "price"}, {"a;b", "1,50"}}},
+		{"tab", "a\tb\n\"x\"\ty\"z\n", []csv.Option{csv.Delimiter('\t'), csv.Quote(0)}, [][]string{{"a", "b"}, {`"x"`, `y"z`}}},
+		{"non-ascii delimiter", "a§b\n", []csv.Option{csv.Delimiter('§')}, [][]string{{"a", "b"}}},
+		{"single quote", "'a,b','it''s'\n", []csv.Option{csv.Quote('\'')}, [][]string{{"a,b", "it's"}}},
+		{"comments", "# header\na,b\n#1,2\n\n3,\"\n# not a comment\"\n", []csv.Option{csv.Comment("#")}, [][]string{{"a", "b"}, {"3", "\n# not a comment"}}},
+		{"comment prefix", "// note\r\na\r\n", []csv.Option{csv.Comment("//")}, [][]string{{"a"}}},
+		{"lazy quote in unquoted field", "a\"b,c\n", []csv.Option{csv.LazyQuotes()}, [][]string{{`a"b`, "c"}}},
+		{"lazy quote in quoted field", "\"a \"b\" c\",d\n", []csv.Option{csv.LazyQuotes()}, [][]string{{`a "b" c`, "d"}}},
+		{"lazy unterminated quote", "x,\"a\nb", []csv.Option{csv.LazyQuotes()}, [][]string{{"x", "a\nb"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csv.Parse(tt.input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOptionErrors(t *testing.T) {
+	t.Run("comment without option", func(t *testing.T) {
+		_, err := csv.Parse("a\n#b,c\n")
+		assert.ErrorIs(t, err, csv.ErrFieldCount)
+	})
+
+	t.Run("strict quotes", func(t *testing.T) {
+		_, err := csv.Parse("\"a \"b\" c\",d\n")
+		assert.ErrorContains(t, err, "csv: line 1, col 5: unexpected 'b'")
+	})
+
+	for name, opts := range map[string][]csv.Option{
+		"newline delimiter":         {csv.Delimiter('\n')},
+		"delimiter is quote":        {csv.Delimiter('"')},
+		"comment is delimiter":      {csv.Comment(",")},
+		"comment starts with quote": {csv.Comment(`"#`)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := csv.Parse("a\n", opts...)
+			assert.ErrorIs(t, err, csv.ErrInvalidDelimiter)
+		})
+	}
+}
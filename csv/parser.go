@@ -0,0 +1,232 @@
+// Package csv provides a parser for comma-separated values as specified by RFC 4180.
+//
+// A document is a sequence of records on separate lines, each a sequence of fields separated by
+// commas. A field may be enclosed in double quotes, in which case it may contain commas, line
+// breaks and double quotes, the latter written twice. Lines end with "\r\n" or "\n".
+//
+// Options select other delimiter-separated formats, such as tab-separated values with
+// Delimiter('\t') and Quote(0), and relax the syntax with Comment and LazyQuotes.
+package csv
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Field returns a parser for a single field, quoted or not, and returns its value.
+func Field(opts ...Option) parser.Parser[string] {
+	return newConfig(opts).field()
+}
+
+// until returns a parser for one or more bytes of input up to the first of stops, or the end of
+// the input, and returns them.
+func until(stops ...string) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		i := 0
+		for i < len(s) && !slices.ContainsFunc(stops, func(stop string) bool { return strings.HasPrefix(s[i:], stop) }) {
+			i++
+		}
+		if i == 0 {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(i)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:i], next))
+	})
+}
+
+// fieldEnd reports whether s starts where a field ends: at a delimiter, a line break or the end
+// of the input.
+func (c *config) fieldEnd(s string) bool {
+	return s == "" || strings.HasPrefix(s, c.delimiter) || s[0] == '\n' || strings.HasPrefix(s, "\r\n")
+}
+
+// strayQuote parses a quote within a quoted field that does not end it, as LazyQuotes accepts.
+func (c *config) strayQuote() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if !strings.HasPrefix(s, c.quote) || c.fieldEnd(s[len(c.quote):]) {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(len(c.quote))
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(c.quote, next))
+	})
+}
+
+// field parses a field according to the configuration c.
+func (c *config) field() parser.Parser[string] {
+	join := func(parts []string) string { return strings.Join(parts, "") }
+	plainStops := []string{c.delimiter, "\n", "\r"}
+	if c.quote == "" {
+		return parser.Fmap(parser.ZeroOrOne(until(plainStops...)), parser.Maybe[string].Get)
+	}
+	if !c.lazyQuotes {
+		plainStops = append(plainStops, c.quote)
+	}
+	plain := parser.Fmap(parser.ZeroOrOne(until(plainStops...)), parser.Maybe[string].Get)
+	quote := parser.Str(c.quote)
+	// Inside quotes, a doubled quote stands for one quote
+	parts := []parser.Parser[string]{parser.Fmap(parser.Str(c.quote+c.quote), func(string) string { return c.quote }), until(c.quote)}
+	closing := parser.Fmap(quote, func(string) struct{} { return struct{}{} })
+	if c.lazyQuotes {
+		parts = append(parts, c.strayQuote())
+		closing = parser.OrElse(closing, parser.EOF())
+	}
+	quoted := parser.Fmap(parser.Between(quote, parser.ZeroOrMore(parser.OrElse(parts...)), closing), join)
+	return parser.OrElse(quoted, plain)
+}
+
+// Record returns a parser for a record: one or more fields separated by commas, or the delimiter
+// given with the Delimiter option.
+// It does not consume the line break ending the record.
+func Record(opts ...Option) parser.Parser[[]string] {
+	return newConfig(opts).record()
+}
+
+// record parses a record according to the configuration c.
+func (c *config) record() parser.Parser[[]string] {
+	field := c.field()
+	return parser.Bind(field, func(first string) parser.Parser[[]string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Str(c.delimiter), field)), func(rest []string) []string {
+			return append([]string{first}, rest...)
+		})
+	})
+}
+
+// skip parses the empty lines and, if comments are enabled, the comment lines before a record.
+func (c *config) skip() parser.Parser[[]string] {
+	if c.comment == "" {
+		return parser.ZeroOrMore(lineBreak())
+	}
+	comment := parser.OmitLeft(parser.Str(c.comment), parser.OmitRight(
+		parser.Fmap(parser.ZeroOrMore(parser.NotChar('\n')), func([]rune) string { return "" }),
+		parser.OrElse(parser.Fmap(parser.Char('\n'), func(rune) struct{} { return struct{}{} }), parser.EOF()),
+	))
+	return parser.ZeroOrMore(parser.OrElse(lineBreak(), comment))
+}
+
+// lineBreak parses the end of a line, "\r\n" or "\n".
+func lineBreak() parser.Parser[string] {
+	return parser.OrElse(parser.Str("\r\n"), parser.Str("\n"))
+}
+
+// lineStart succeeds without consuming input where a record can start: not on an empty line
+// and not at the end of the input.
+func lineStart() parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		s := st.Input()
+		if s == "" || s[0] == '\n' || strings.HasPrefix(s, "\r\n") {
+			st.Fail("record")
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+}
+
+// located is a record with the offset it starts at.
+type located struct {
+	offset int
+	fields []string
+}
+
+// Document returns a parser for a complete CSV document and returns its records. Empty lines, and
+// comment lines if the Comment option is given, are skipped, and the last record may or may not end with a line break. Unlike Parse, it does not
+// check the number of fields of the records.
+func Document(opts ...Option) parser.Parser[[][]string] {
+	return parser.Fmap(newConfig(opts).document(), func(recs []located) [][]string {
+		records := make([][]string, len(recs))
+		for i, r := range recs {
+			records[i] = r.fields
+		}
+		return records
+	})
+}
+
+// document parses a complete CSV document according to the configuration c.
+func (c *config) document() parser.Parser[[]located] {
+	blank := c.skip()
+	record := parser.Bind(parser.OmitLeft(lineStart(), parser.Pos()), func(offset int) parser.Parser[located] {
+		return parser.Fmap(c.record(), func(fields []string) located { return located{offset, fields} })
+	})
+	end := parser.OrElse(parser.Fmap(lineBreak(), func(string) struct{} { return struct{}{} }), parser.EOF())
+	return parser.OmitLeft(blank, parser.OmitRight(
+		parser.ZeroOrMore(parser.OmitRight(record, parser.OmitLeft(end, blank))),
+		parser.EOF(),
+	))
+}
+
+// Parse parses the CSV document s and returns its records. Unless the VariableFields option is
+// given, every record must have as many fields as the first. It fails with a *ParseError, or with
+// ErrInvalidDelimiter if the options conflict.
+func Parse(s string, opts ...Option) ([][]string, error) {
+	c := newConfig(opts)
+	recs, err := c.parse(s)
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]string, len(recs))
+	for i, r := range recs {
+		records[i] = r.fields
+	}
+	return records, nil
+}
+
+// parse parses the CSV document s according to the configuration c and checks the number of
+// fields of its records.
+func (c *config) parse(s string) ([]located, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	recs, err := parser.Run(c.document(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "csv")
+	}
+	if c.variableFields {
+		return recs, nil
+	}
+	for _, r := range recs[min(1, len(recs)):] {
+		if len(r.fields) != len(recs[0].fields) {
+			return nil, parser.ErrorAt("csv", s, r.offset, ErrFieldCount)
+		}
+	}
+	return recs, nil
+}
+
+// ParseMaps parses the CSV document s, whose first record is a header naming the columns, and
+// returns the other records as maps from column names to field values. Every record must have
+// as many fields as the header, and the names in the header must be unique.
+func ParseMaps(s string, opts ...Option) ([]map[string]string, error) {
+	c := *newConfig(opts)
+	c.variableFields = false
+	recs, err := c.parse(s)
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	header := recs[0].fields
+	for i, name := range header {
+		for _, prev := range header[:i] {
+			if name == prev {
+				return nil, parser.ErrorAt("csv", s, 0, ErrDuplicateHeader)
+			}
+		}
+	}
+	maps := make([]map[string]string, len(recs)-1)
+	for i, r := range recs[1:] {
+		m := make(map[string]string, len(header))
+		for j, name := range header {
+			m[name] = r.fields[j]
+		}
+		maps[i] = m
+	}
+	return maps, nil
+}
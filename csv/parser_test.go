@@ -0,0 +1,105 @@
+package csv_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/81120/tiny-parsec/csv"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]string
+	}{
+		{"simple", "a,b,c\n1,2,3\n", [][]string{{"a", "b", "c"}, {"1", "2", "3"}}},
+		{"no trailing newline", "a,b\n1,2", [][]string{{"a", "b"}, {"1", "2"}}},
+		{"crlf", "a,b\r\n1,2\r\n", [][]string{{"a", "b"}, {"1", "2"}}},
+		{"empty fields", ",x,\n,,", [][]string{{"", "x", ""}, {"", "", ""}}},
+		{"quoted", `"a,b","c"` + "\n", [][]string{{"a,b", "c"}}},
+		{"embedded quotes", `"say ""hi""",""` + "\n", [][]string{{`say "hi"`, ""}}},
+		{"embedded newline", "\"line 1\r\nline 2\",x\n", [][]string{{"line 1\r\nline 2", "x"}}},
+		{"blank lines", "\na\n\r\n\nb\n\n", [][]string{{"a"}, {"b"}}},
+		{"spaces kept", " a , b \n", [][]string{{" a ", " b "}}},
+		{"non-ascii", "café,\"日本\"\n", [][]string{{"café", "日本"}}},
+		{"empty", "", [][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := csv.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+		msg   string
+	}{
+		{"bare quote", "a,b\"c\n", parser.ErrNoMatch, `csv: line 1, col 4: unexpected '"'`},
+		{"unterminated quote", "a,\"b\n", parser.ErrUnexpectedEOF, "csv: line 2, col 1: unexpected end of input"},
+		{"text after quote", "\"a\"b\n", parser.ErrNoMatch, "csv: line 1, col 4: unexpected 'b'"},
+		{"field count", "a,b\n\n1,2,3\n", csv.ErrFieldCount, "csv: line 3, col 1: wrong number of fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := csv.Parse(tt.input)
+			var perr *csv.ParseError
+			assert.True(t, errors.As(err, &perr))
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestVariableFields(t *testing.T) {
+	got, err := csv.Parse("a,b\n1\n1,2,3", csv.VariableFields())
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1"}, {"1", "2", "3"}}, got)
+}
+
+func TestParseMaps(t *testing.T) {
+	t.Run("records", func(t *testing.T) {
+		got, err := csv.ParseMaps("name,note\r\nada,\"first, \"\"only\"\"\"\r\nbob,\r\n")
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]string{
+			{"name": "ada", "note": `first, "only"`},
+			{"name": "bob", "note": ""},
+		}, got)
+	})
+
+	t.Run("header only", func(t *testing.T) {
+		got, err := csv.ParseMaps("a,b\n")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := csv.ParseMaps("")
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("duplicate header", func(t *testing.T) {
+		_, err := csv.ParseMaps("a,b,a\n1,2,3\n")
+		assert.ErrorIs(t, err, csv.ErrDuplicateHeader)
+	})
+
+	t.Run("field count", func(t *testing.T) {
+		_, err := csv.ParseMaps("a,b\n1\n", csv.VariableFields())
+		assert.ErrorIs(t, err, csv.ErrFieldCount)
+	})
+}
+
+func TestRecord(t *testing.T) {
+	got, err := parser.Run(csv.Record(), `x,"y",`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y", ""}, got)
+}
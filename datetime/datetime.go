@@ -0,0 +1,172 @@
+// Package datetime parses dates and times as written in RFC 3339 and ISO 8601, e.g.
+// "2024-02-29T07:32:00.5+05:30", with strict validation: months, days, hours, minutes, seconds
+// and offsets must be in range, and February 29 only exists in leap years.
+//
+// Parse and ParseRFC3339 read a date-time with an offset from UTC into a time.Time. ParseValue
+// also accepts the partial forms, a date-time without an offset, a date or a time of day, and
+// returns them as the typed variants LocalDateTime, Date and Time.
+//
+// Besides the RFC 3339 syntax, the ISO 8601 forms accepted are:
+//
+//	20240229T073200Z        basic format, without separators
+//	2024-060                ordinal date, the 60th day of 2024
+//	2024-W09-4, 2024W094    week date, Thursday of ISO week 9
+//	07:32, T0732            time without seconds
+//	07:32:00,5              decimal comma
+//	+05, +0530              offset in hours, or without a colon
+//
+// Leap seconds are rejected, as time.Time cannot represent them.
+package datetime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Value is a date-time, a date or a time of day: DateTime, LocalDateTime, Date or Time.
+type Value interface {
+	fmt.Stringer
+	isValue()
+}
+
+// DateTime is a date-time with an offset from UTC, e.g. "2024-02-29T07:32:00Z".
+type DateTime struct {
+	Val time.Time
+}
+
+// LocalDateTime is a date-time without an offset, e.g. "2024-02-29T07:32:00", which does not
+// denote an instant until placed in a location.
+type LocalDateTime struct {
+	Date Date
+	Time Time
+}
+
+// Date is a calendar date without a time, e.g. "2024-02-29".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// Time is a time of day without a date or an offset, e.g. "07:32:00.5".
+type Time struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+func (DateTime) isValue()      {}
+func (LocalDateTime) isValue() {}
+func (Date) isValue()          {}
+func (Time) isValue()          {}
+
+// String formats the date-time in RFC 3339, with as many fractional digits as needed.
+func (v DateTime) String() string {
+	return v.Val.Format(time.RFC3339Nano)
+}
+
+// String formats the date-time as "2024-02-29T07:32:00".
+func (v LocalDateTime) String() string {
+	return v.Date.String() + "T" + v.Time.String()
+}
+
+// In returns the instant of the date-time in loc.
+func (v LocalDateTime) In(loc *time.Location) time.Time {
+	return join(v.Date, v.Time, loc)
+}
+
+// String formats the date as "2024-02-29".
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the instant at which the date starts in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return join(d, Time{}, loc)
+}
+
+// String formats the time as "07:32:00", followed by as many fractional digits as needed.
+func (t Time) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond == 0 {
+		return s
+	}
+	frac := fmt.Sprintf("%09d", t.Nanosecond)
+	for frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+	return s + "." + frac
+}
+
+// dateOf returns the date of t.
+func dateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// join returns the instant of a date and a time in loc.
+func join(d Date, t Time, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, loc)
+}
+
+// zone returns the location of an offset from UTC in seconds: time.UTC for zero, and a fixed
+// zone without a name otherwise, as time.Parse does.
+func zone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", offset)
+}
+
+// Parse parses a date-time with an offset from UTC in any of the RFC 3339 and ISO 8601 forms,
+// e.g. "2024-02-29T07:32:00Z" or "2024-W09-4T0732+0530".
+func Parse(s string) (time.Time, error) {
+	t, err := parser.Run(dateTime(true), s)
+	if err != nil {
+		return time.Time{}, parser.Wrap(err, "datetime")
+	}
+	return t, nil
+}
+
+// ParseRFC3339 parses a date-time in the stricter syntax of RFC 3339: extended calendar date,
+// time with seconds, fraction after a dot, and an offset of "Z" or "+hh:mm". Unlike time.Parse,
+// it accepts a lowercase 't' or 'z' and a space between the date and the time, as the RFC does.
+func ParseRFC3339(s string) (time.Time, error) {
+	t, err := parser.Run(dateTime(false), s)
+	if err != nil {
+		return time.Time{}, parser.Wrap(err, "datetime")
+	}
+	return t, nil
+}
+
+// ParseValue parses a date-time with or without an offset, a date, or a time of day, in any of
+// the ISO 8601 forms. A time of day in the basic format must start with 'T', e.g. "T0732", so as
+// not to read as a date.
+func ParseValue(s string) (Value, error) {
+	v, err := parser.Run(value(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "datetime")
+	}
+	return v, nil
+}
+
+// ParseDate parses a date alone in any of the ISO 8601 forms, e.g. "2024-02-29" or "2024-060".
+func ParseDate(s string) (Date, error) {
+	d, err := parser.Run(date(true), s)
+	if err != nil {
+		return Date{}, parser.Wrap(err, "datetime")
+	}
+	return d, nil
+}
+
+// ParseTime parses a time of day alone, e.g. "07:32:00.5" or "0732", without an offset.
+func ParseTime(s string) (Time, error) {
+	t, err := parser.Run(parser.OmitLeft(parser.ZeroOrOne(parser.Char('T')), clock(true)), s)
+	if err != nil {
+		return Time{}, parser.Wrap(err, "datetime")
+	}
+	return t, nil
+}
@@ -0,0 +1,164 @@
+package datetime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/datetime"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	ist := time.FixedZone("", 5*3600+30*60)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2024-02-29T07:32:00Z", time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)},
+		{"2024-02-29T07:32:00.5+05:30", time.Date(2024, 2, 29, 7, 32, 0, 500000000, ist)},
+		{"1985-04-12t23:20:50.52z", time.Date(1985, 4, 12, 23, 20, 50, 520000000, time.UTC)},
+		{"1985-04-12 23:20:50Z", time.Date(1985, 4, 12, 23, 20, 50, 0, time.UTC)},
+		{"1996-12-19T16:39:57-08:00", time.Date(1996, 12, 19, 16, 39, 57, 0, time.FixedZone("", -8*3600))},
+		{"0000-01-01T00:00:00Z", time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20240229T073200Z", time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)},
+		{"2024-02-29T07:32Z", time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)},
+		{"2024-02-29T07:32:00,25+0530", time.Date(2024, 2, 29, 7, 32, 0, 250000000, ist)},
+		{"2024-02-29T07:32:00+05", time.Date(2024, 2, 29, 7, 32, 0, 0, time.FixedZone("", 5*3600))},
+		{"2024-060T12:00Z", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"2024060T1200Z", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"2024-W09-4T07:32Z", time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)},
+		{"2024W094T0732Z", time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)},
+		{"2020-W53-7T00:00Z", time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"2025-W01-1T00:00Z", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-01T00:00:00.123456789123Z", time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := datetime.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+			_, wantOffset := tt.want.Zone()
+			_, gotOffset := got.Zone()
+			assert.Equal(t, wantOffset, gotOffset)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"2023-02-29T00:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 9: unexpected '2', expected day between 01 and 28"},
+		{"2024-04-31T00:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 9: unexpected '3', expected day between 01 and 30"},
+		{"2024-13-01T00:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 6: unexpected '1', expected month between 01 and 12"},
+		{"2024-00-01T00:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 6: unexpected '0', expected month between 01 and 12"},
+		{"2024-01-01T24:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 12: unexpected '2', expected hour between 00 and 23"},
+		{"2024-01-01T00:60:00Z", parser.ErrNoMatch, "datetime: line 1, col 15: unexpected '6', expected minute between 00 and 59"},
+		{"2024-01-01T23:59:60Z", parser.ErrNoMatch, "datetime: line 1, col 18: unexpected '6', expected second between 00 and 59"},
+		{"2024-01-01T00:00:00+24:00", parser.ErrNoMatch, "datetime: line 1, col 21: unexpected '2', expected offset hour between 00 and 23"},
+		{"2024-01-01T00:00:00+05:60", parser.ErrNoMatch, "datetime: line 1, col 24: unexpected '6', expected offset minute between 00 and 59"},
+		{"2024-01-01T00:00:00", parser.ErrUnexpectedEOF, "datetime: line 1, col 20: unexpected end of input, expected '.', ',', 'Z', 'z', '+' or '-'"},
+		{"2023-366T00:00Z", parser.ErrNoMatch, "datetime: line 1, col 6: unexpected '3', expected day of year between 001 and 365"},
+		{"2024-W53-1T00:00Z", parser.ErrNoMatch, "datetime: line 1, col 7: unexpected '5', expected week between 01 and 52"},
+		{"2024-W01-8T00:00Z", parser.ErrNoMatch, "datetime: line 1, col 10: unexpected '8', expected weekday between 1 and 7"},
+		{"24-01-01T00:00:00Z", parser.ErrNoMatch, "datetime: line 1, col 3: unexpected '-', expected digit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := datetime.Parse(tt.input)
+			var perr *datetime.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestParseRFC3339(t *testing.T) {
+	got, err := datetime.ParseRFC3339("1985-04-12T23:20:50.52+01:00")
+	assert.NoError(t, err)
+	assert.True(t, time.Date(1985, 4, 12, 22, 20, 50, 520000000, time.UTC).Equal(got))
+
+	tests := []struct {
+		input string
+		msg   string
+	}{
+		{"19850412T232050Z", "datetime: line 1, col 5: unexpected '0', expected '-'"},
+		{"1985-102T23:20:50Z", "datetime: line 1, col 8: unexpected '2', expected '-'"},
+		{"1985-04-12T23:20Z", "datetime: line 1, col 17: unexpected 'Z', expected ':'"},
+		{"1985-04-12T23:20:50,52Z", "datetime: line 1, col 20: unexpected ',', expected '.', 'Z', 'z', '+' or '-'"},
+		{"1985-04-12T23:20:50+0100", "datetime: line 1, col 23: unexpected '0', expected ':'"},
+		{"1985-04-12T23:20:50+01", "datetime: line 1, col 23: unexpected end of input, expected ':'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := datetime.ParseRFC3339(tt.input)
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  datetime.Value
+		str   string
+	}{
+		{"2024-02-29T07:32:00Z", datetime.DateTime{Val: time.Date(2024, 2, 29, 7, 32, 0, 0, time.UTC)}, "2024-02-29T07:32:00Z"},
+		{"2024-02-29T07:32:00.5", datetime.LocalDateTime{
+			Date: datetime.Date{Year: 2024, Month: time.February, Day: 29},
+			Time: datetime.Time{Hour: 7, Minute: 32, Nanosecond: 500000000},
+		}, "2024-02-29T07:32:00.5"},
+		{"2024-02-29", datetime.Date{Year: 2024, Month: time.February, Day: 29}, "2024-02-29"},
+		{"20240229", datetime.Date{Year: 2024, Month: time.February, Day: 29}, "2024-02-29"},
+		{"2024-060", datetime.Date{Year: 2024, Month: time.February, Day: 29}, "2024-02-29"},
+		{"2024-W09-4", datetime.Date{Year: 2024, Month: time.February, Day: 29}, "2024-02-29"},
+		{"07:32:00.25", datetime.Time{Hour: 7, Minute: 32, Nanosecond: 250000000}, "07:32:00.25"},
+		{"07:32", datetime.Time{Hour: 7, Minute: 32}, "07:32:00"},
+		{"T073215", datetime.Time{Hour: 7, Minute: 32, Second: 15}, "07:32:15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := datetime.ParseValue(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+			assert.Equal(t, tt.str, v.String())
+		})
+	}
+}
+
+func TestParseValueErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+	}{
+		{"25:00", "datetime: line 1, col 1: unexpected '2', expected hour between 00 and 23"},
+		{"2024-02-30", "datetime: line 1, col 9: unexpected '3', expected day between 01 and 29"},
+		{"0732", "datetime: line 1, col 5: unexpected end of input, expected digit"},
+		{"2024-02-29T", "datetime: line 1, col 12: unexpected end of input, expected digit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := datetime.ParseValue(tt.input)
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+}
+
+func TestLocal(t *testing.T) {
+	loc := time.FixedZone("", -5*3600)
+	d, err := datetime.ParseDate("2024-02-29")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, loc), d.In(loc))
+
+	tm, err := datetime.ParseTime("T07:32")
+	assert.NoError(t, err)
+	ldt := datetime.LocalDateTime{Date: d, Time: tm}
+	assert.Equal(t, time.Date(2024, 2, 29, 7, 32, 0, 0, loc), ldt.In(loc))
+
+	_, err = datetime.ParseTime("07:32Z")
+	assert.EqualError(t, err, "datetime: line 1, col 6: unexpected 'Z', expected ':' or end of input")
+}
@@ -0,0 +1,8 @@
+// Package datetime provides error reporting for malformed dates and times.
+package datetime
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a date or time could not be parsed. Expected lists what the input should
+// contain at the error, e.g. "month between 01 and 12".
+type ParseError = parser.Error
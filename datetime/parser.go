@@ -0,0 +1,242 @@
+// Package datetime provides the grammar of RFC 3339 and ISO 8601 dates and times, built with
+// the tiny-parsec combinators.
+package datetime
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// lookahead succeeds without consuming input if f accepts the rest of the input. It records no
+// failure, so that the alternative it guards is not blamed for an error in another one.
+func lookahead(f func(s string) bool) parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		if !f(st.Input()) {
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+}
+
+// at reports whether s has the byte c at index i.
+func at(s string, i int, c byte) bool {
+	return len(s) > i && s[i] == c
+}
+
+// leadingDigits returns the number of digits at the start of s.
+func leadingDigits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// digits parses exactly n digits as a number.
+func digits(n int) parser.Parser[int] {
+	ds := make([]parser.Parser[rune], n)
+	for i := range ds {
+		ds[i] = parser.Digit()
+	}
+	return parser.Fmap(parser.Seq(ds...), func(rs []rune) int {
+		v, _ := strconv.Atoi(parser.Text(rs))
+		return v
+	})
+}
+
+// ranged parses exactly n digits as a number between lo and hi, e.g. a month.
+func ranged(n, lo, hi int, name string) parser.Parser[int] {
+	return parser.SatisfyWithMsg(digits(n), func(v int) bool { return v >= lo && v <= hi },
+		fmt.Sprintf("%s between %0*d and %0*d", name, n, lo, n, hi))
+}
+
+// sep parses the separator of the extended format, or nothing in the basic format.
+func sep(c rune, extended bool) parser.Parser[struct{}] {
+	if !extended {
+		return parser.Pure(struct{}{})
+	}
+	return parser.Fmap(parser.Char(c), func(rune) struct{} { return struct{}{} })
+}
+
+// daysIn returns the number of days of a month.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// weeksIn returns the number of ISO weeks of a year: 53 if it starts on a Thursday, or on a
+// Wednesday in a leap year, and 52 otherwise.
+func weeksIn(year int) int {
+	_, w := time.Date(year, 12, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return w
+}
+
+// calendarDate parses the month and day following the year, e.g. "02-29" in "2024-02-29".
+func calendarDate(year int, extended bool) parser.Parser[Date] {
+	return parser.Bind(parser.OmitLeft(sep('-', extended), ranged(2, 1, 12, "month")), func(m int) parser.Parser[Date] {
+		day := ranged(2, 1, daysIn(year, time.Month(m)), "day")
+		return parser.Fmap(parser.OmitLeft(sep('-', extended), day), func(d int) Date {
+			return Date{Year: year, Month: time.Month(m), Day: d}
+		})
+	})
+}
+
+// ordinalDate parses the day of the year following the year, e.g. "060" in "2024-060".
+func ordinalDate(year int, extended bool) parser.Parser[Date] {
+	days := 365
+	if daysIn(year, time.February) == 29 {
+		days = 366
+	}
+	return parser.Fmap(parser.OmitLeft(sep('-', extended), ranged(3, 1, days, "day of year")), func(d int) Date {
+		return dateOf(time.Date(year, 1, d, 0, 0, 0, 0, time.UTC))
+	})
+}
+
+// weekDate parses the ISO week and weekday following the year, e.g. "W09-4" in "2024-W09-4".
+func weekDate(year int, extended bool) parser.Parser[Date] {
+	week := parser.OmitLeft(sep('-', extended), parser.OmitLeft(parser.Char('W'), ranged(2, 1, weeksIn(year), "week")))
+	return parser.Bind(week, func(w int) parser.Parser[Date] {
+		return parser.Fmap(parser.OmitLeft(sep('-', extended), ranged(1, 1, 7, "weekday")), func(wd int) Date {
+			// Week 1 is the week with the first Thursday of the year, hence with January 4.
+			jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+			monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+			return dateOf(monday.AddDate(0, 0, (w-1)*7+wd-1))
+		})
+	})
+}
+
+// date parses a date: "2024-02-29" in RFC 3339, and in ISO 8601 also the basic format
+// "20240229", ordinal dates such as "2024-060" and week dates such as "2024-W09-4".
+func date(iso bool) parser.Parser[Date] {
+	year := digits(4)
+	if !iso {
+		return parser.Bind(year, func(y int) parser.Parser[Date] { return calendarDate(y, true) })
+	}
+	return parser.Bind(year, func(y int) parser.Parser[Date] {
+		extended := parser.OmitLeft(lookahead(func(s string) bool { return at(s, 0, '-') }), parser.OrElse(
+			parser.OmitLeft(lookahead(func(s string) bool { return at(s, 1, 'W') }), weekDate(y, true)),
+			parser.OmitLeft(lookahead(func(s string) bool { return at(s, 3, '-') }), calendarDate(y, true)),
+			parser.OmitLeft(lookahead(func(s string) bool { return !at(s, 1, 'W') && !at(s, 3, '-') }), ordinalDate(y, true)),
+		))
+		basic := parser.OrElse(
+			parser.OmitLeft(lookahead(func(s string) bool { return at(s, 0, 'W') }), weekDate(y, false)),
+			parser.OmitLeft(lookahead(func(s string) bool { return leadingDigits(s) != 3 }), calendarDate(y, false)),
+			parser.OmitLeft(lookahead(func(s string) bool { return leadingDigits(s) == 3 }), ordinalDate(y, false)),
+		)
+		return parser.OrElse(extended, basic)
+	})
+}
+
+// fraction parses a decimal fraction of a second, with a dot or in ISO 8601 also a comma, as
+// nanoseconds. Digits beyond nanoseconds are dropped.
+func fraction(iso bool) parser.Parser[int] {
+	mark := parser.Char('.')
+	if iso {
+		mark = parser.OrElse(mark, parser.Char(','))
+	}
+	return parser.Fmap(parser.OmitLeft(mark, parser.OneOrMore(parser.Digit())), func(rs []rune) int {
+		s := parser.Text(rs)
+		for len(s) < 9 {
+			s += "0"
+		}
+		ns, _ := strconv.Atoi(s[:9])
+		return ns
+	})
+}
+
+// clock parses a time of day: "07:32:00.5" in RFC 3339, and in ISO 8601 also "07:32" without
+// seconds and the basic formats "073200.5" and "0732". Leap seconds are rejected, as time.Time
+// cannot represent them.
+func clock(iso bool) parser.Parser[Time] {
+	build := func(extended, secondsOptional bool) parser.Parser[Time] {
+		seconds := parser.Bind(parser.OmitLeft(sep(':', extended), ranged(2, 0, 59, "second")), func(s int) parser.Parser[Time] {
+			return parser.Fmap(parser.ZeroOrOne(fraction(iso)), func(ns parser.Maybe[int]) Time {
+				return Time{Second: s, Nanosecond: ns.Get()}
+			})
+		})
+		if secondsOptional {
+			seconds = parser.Fmap(parser.ZeroOrOne(seconds), parser.Maybe[Time].Get)
+		}
+		return parser.Bind(ranged(2, 0, 23, "hour"), func(h int) parser.Parser[Time] {
+			return parser.Bind(parser.OmitLeft(sep(':', extended), ranged(2, 0, 59, "minute")), func(m int) parser.Parser[Time] {
+				return parser.Fmap(seconds, func(t Time) Time {
+					t.Hour, t.Minute = h, m
+					return t
+				})
+			})
+		})
+	}
+	if !iso {
+		return build(true, false)
+	}
+	return parser.OrElse(
+		parser.OmitLeft(lookahead(func(s string) bool { return at(s, 2, ':') }), build(true, true)),
+		parser.OmitLeft(lookahead(func(s string) bool { return !at(s, 2, ':') }), build(false, true)),
+	)
+}
+
+// offset parses "Z" or an offset from UTC such as "+05:30", and in ISO 8601 also "+0530" and
+// "+05", as a number of seconds east of UTC. Hours range from 00 to 23.
+func offset(iso bool) parser.Parser[int] {
+	zulu := parser.Fmap(parser.OrElse(parser.Char('Z'), parser.Char('z')), func(rune) int { return 0 })
+	hour := ranged(2, 0, 23, "offset hour")
+	minute := ranged(2, 0, 59, "offset minute")
+	var minutes parser.Parser[int]
+	if iso {
+		minutes = parser.Fmap(parser.ZeroOrOne(parser.OmitLeft(parser.ZeroOrOne(parser.Char(':')), minute)), parser.Maybe[int].Get)
+	} else {
+		minutes = parser.OmitLeft(parser.Char(':'), minute)
+	}
+	numeric := parser.Bind(parser.OrElse(parser.Char('+'), parser.Char('-')), func(sign rune) parser.Parser[int] {
+		return parser.Bind(hour, func(h int) parser.Parser[int] {
+			return parser.Fmap(minutes, func(m int) int {
+				if sign == '-' {
+					return -(h*3600 + m*60)
+				}
+				return h*3600 + m*60
+			})
+		})
+	})
+	return parser.OrElse(zulu, numeric)
+}
+
+// dateTimeSep parses the separator between a date and a time: 'T', or as RFC 3339 permits, a
+// lowercase 't' or a space.
+func dateTimeSep() parser.Parser[rune] {
+	return parser.OrElse(parser.Char('T'), parser.Char('t'), parser.Char(' '))
+}
+
+// dateTime parses a date and a time with an offset from UTC.
+func dateTime(iso bool) parser.Parser[time.Time] {
+	return parser.Bind(parser.OmitRight(date(iso), dateTimeSep()), func(d Date) parser.Parser[time.Time] {
+		return parser.Bind(clock(iso), func(t Time) parser.Parser[time.Time] {
+			return parser.Fmap(offset(iso), func(off int) time.Time { return join(d, t, zone(off)) })
+		})
+	})
+}
+
+// value parses any of the ISO 8601 forms: a date-time with or without an offset, a date, or a
+// time of day, which must contain a colon or start with 'T' so as not to read as a date.
+func value() parser.Parser[Value] {
+	withDate := parser.Bind(date(true), func(d Date) parser.Parser[Value] {
+		rest := parser.Bind(parser.OmitLeft(dateTimeSep(), clock(true)), func(t Time) parser.Parser[Value] {
+			return parser.Fmap(parser.ZeroOrOne(offset(true)), func(off parser.Maybe[int]) Value {
+				if off.IsJust() {
+					return DateTime{Val: join(d, t, zone(off.Get()))}
+				}
+				return LocalDateTime{Date: d, Time: t}
+			})
+		})
+		return parser.OrElse(rest, parser.Pure[Value](d))
+	})
+	timeOnly := parser.Fmap(parser.OrElse(
+		parser.OmitLeft(parser.Char('T'), clock(true)),
+		parser.OmitLeft(lookahead(func(s string) bool { return at(s, 2, ':') }), clock(true)),
+	), func(t Time) Value { return t })
+	return parser.OrElse(
+		parser.OmitLeft(lookahead(func(s string) bool { return !at(s, 0, 'T') && !at(s, 2, ':') }), withDate),
+		timeOnly,
+	)
+}
@@ -0,0 +1,109 @@
+// Package emailaddr parses email addresses as written in the From, To and Cc header fields
+// of messages, following RFC 5322:
+//
+//	john@example.com
+//	John Doe <john@example.com>
+//	"Doe, John" <john@example.com> (work)
+//	friends: alice@example.com, Bob <bob@example.org>;
+//
+// Comments and folding whitespace are skipped wherever the grammar allows them. Display
+// names are returned as written, without decoding RFC 2047 encoded words.
+package emailaddr
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Address is an element of an address list: a *Mailbox or a *Group.
+type Address interface {
+	// String returns the address in RFC 5322 syntax.
+	String() string
+	// address is a method that all address types must implement.
+	// It serves as a marker for the address type.
+	address()
+}
+
+// Mailbox is the address of a single recipient.
+type Mailbox struct {
+	// Name is the display name, with its quotes and escapes undone and the words of an
+	// unquoted name separated by single spaces. It is empty for bare addresses.
+	Name string
+	// Local is the local part, before the "@", with its quotes and escapes undone.
+	Local string
+	// Domain is the domain, after the "@", either a dotted name or a domain literal in
+	// brackets, e.g. "[192.0.2.1]".
+	Domain string
+}
+
+func (*Mailbox) address() {}
+
+// Address returns the address without its display name, e.g. "john@example.com", quoting
+// the local part if needed.
+func (m *Mailbox) Address() string {
+	local := m.Local
+	if !isDotAtom(local) {
+		local = quote(local)
+	}
+	return local + "@" + m.Domain
+}
+
+// String returns the mailbox as "Name <address>", or only the address if it has no display
+// name. The name is quoted if needed.
+func (m *Mailbox) String() string {
+	if m.Name == "" {
+		return m.Address()
+	}
+	return phrase(m.Name) + " <" + m.Address() + ">"
+}
+
+// Group is a named list of mailboxes, e.g. "friends: alice@example.com, bob@example.org;".
+type Group struct {
+	Name string
+	// Members lists the mailboxes of the group, which may be empty.
+	Members []*Mailbox
+}
+
+func (*Group) address() {}
+
+// String returns the group as "Name: member, member;".
+func (g *Group) String() string {
+	members := make([]string, len(g.Members))
+	for i, m := range g.Members {
+		members[i] = m.String()
+	}
+	return phrase(g.Name) + ": " + strings.Join(members, ", ") + ";"
+}
+
+// Mailboxes returns the mailboxes of list, replacing groups by their members.
+func Mailboxes(list []Address) []*Mailbox {
+	var ms []*Mailbox
+	for _, a := range list {
+		switch a := a.(type) {
+		case *Mailbox:
+			ms = append(ms, a)
+		case *Group:
+			ms = append(ms, a.Members...)
+		}
+	}
+	return ms
+}
+
+// ParseAddress parses a single mailbox, with or without a display name.
+func ParseAddress(s string, opts ...Option) (*Mailbox, error) {
+	m, err := parser.Run(mailbox(newConfig(opts)), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "emailaddr")
+	}
+	return m, nil
+}
+
+// ParseList parses a comma-separated list of mailboxes and groups.
+func ParseList(s string, opts ...Option) ([]Address, error) {
+	list, err := parser.Run(addressList(newConfig(opts)), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "emailaddr")
+	}
+	return list, nil
+}
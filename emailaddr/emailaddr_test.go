@@ -0,0 +1,148 @@
+package emailaddr_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/emailaddr"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected emailaddr.Mailbox
+	}{
+		{"john@example.com", emailaddr.Mailbox{Local: "john", Domain: "example.com"}},
+		{"John Doe <john@example.com>", emailaddr.Mailbox{Name: "John Doe", Local: "john", Domain: "example.com"}},
+		{"<john@example.com>", emailaddr.Mailbox{Local: "john", Domain: "example.com"}},
+		{`"Doe, John" <john@example.com>`, emailaddr.Mailbox{Name: "Doe, John", Local: "john", Domain: "example.com"}},
+		{`"John \"Jr\"" Doe <j@example.com>`, emailaddr.Mailbox{Name: `John "Jr" Doe`, Local: "j", Domain: "example.com"}},
+		{"  John \t Doe  <john@example.com>  ", emailaddr.Mailbox{Name: "John Doe", Local: "john", Domain: "example.com"}},
+		{"John (the man) Doe <john@example.com> (work)", emailaddr.Mailbox{Name: "John Doe", Local: "john", Domain: "example.com"}},
+		{"john(nested (comment) \\) here)@example.com", emailaddr.Mailbox{Local: "john", Domain: "example.com"}},
+		{"John\r\n Doe <john@example.com>", emailaddr.Mailbox{Name: "John Doe", Local: "john", Domain: "example.com"}},
+		{`"john doe"@example.com`, emailaddr.Mailbox{Local: "john doe", Domain: "example.com"}},
+		{`"a\@b"@example.com`, emailaddr.Mailbox{Local: "a@b", Domain: "example.com"}},
+		{"user+tag@mail.example.com", emailaddr.Mailbox{Local: "user+tag", Domain: "mail.example.com"}},
+		{"postmaster@[192.0.2.1]", emailaddr.Mailbox{Local: "postmaster", Domain: "[192.0.2.1]"}},
+		{"John Q. Public <jqp@example.com>", emailaddr.Mailbox{Name: "John Q. Public", Local: "jqp", Domain: "example.com"}},
+		{"john..doe.@example.com", emailaddr.Mailbox{Local: "john..doe.", Domain: "example.com"}},
+		{`"john".doe@example.com`, emailaddr.Mailbox{Local: "john.doe", Domain: "example.com"}},
+		{"Jürgen <jürgen@bücher.example>", emailaddr.Mailbox{Name: "Jürgen", Local: "jürgen", Domain: "bücher.example"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := emailaddr.ParseAddress(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, &tt.expected, m)
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	list, err := emailaddr.ParseList(`alice@example.com, "Bob B." <bob@example.org>, friends: carol@example.net, Dan <dan@example.net>;, empty:;`)
+	assert.NoError(t, err)
+	assert.Equal(t, []emailaddr.Address{
+		&emailaddr.Mailbox{Local: "alice", Domain: "example.com"},
+		&emailaddr.Mailbox{Name: "Bob B.", Local: "bob", Domain: "example.org"},
+		&emailaddr.Group{Name: "friends", Members: []*emailaddr.Mailbox{
+			{Local: "carol", Domain: "example.net"},
+			{Name: "Dan", Local: "dan", Domain: "example.net"},
+		}},
+		&emailaddr.Group{Name: "empty"},
+	}, list)
+	assert.Equal(t, []*emailaddr.Mailbox{
+		{Local: "alice", Domain: "example.com"},
+		{Name: "Bob B.", Local: "bob", Domain: "example.org"},
+		{Local: "carol", Domain: "example.net"},
+		{Name: "Dan", Local: "dan", Domain: "example.net"},
+	}, emailaddr.Mailboxes(list))
+
+	list, err = emailaddr.ParseList("a@example.com, , (none),b@example.com,")
+	assert.NoError(t, err)
+	assert.Equal(t, []emailaddr.Address{
+		&emailaddr.Mailbox{Local: "a", Domain: "example.com"},
+		&emailaddr.Mailbox{Local: "b", Domain: "example.com"},
+	}, list)
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"john@example.com", "john@example.com"},
+		{"John   Doe <john@example.com> (work)", "John Doe <john@example.com>"},
+		{`"Doe, John" <john@example.com>`, `"Doe, John" <john@example.com>`},
+		{"John Q. Public <jqp@example.com>", `"John Q. Public" <jqp@example.com>`},
+		{`"say \"hi\"" <"john doe"@example.com>`, `"say \"hi\"" <"john doe"@example.com>`},
+		{"john..doe@example.com", `"john..doe"@example.com`},
+		{"friends: a@example.com, B <b@example.com>;", "friends: a@example.com, B <b@example.com>;"},
+		{"undisclosed recipients: ;", "undisclosed recipients: ;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			list, err := emailaddr.ParseList(tt.input)
+			assert.NoError(t, err)
+			if assert.Len(t, list, 1) {
+				assert.Equal(t, tt.expected, list[0].String())
+				again, err := emailaddr.ParseList(list[0].String())
+				assert.NoError(t, err)
+				assert.Equal(t, list, again)
+			}
+		})
+	}
+}
+
+func TestStrict(t *testing.T) {
+	m, err := emailaddr.ParseAddress(`"Doe, John" <"john doe"@[192.0.2.1]> (work)`, emailaddr.Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, &emailaddr.Mailbox{Name: "Doe, John", Local: "john doe", Domain: "[192.0.2.1]"}, m)
+
+	tests := []string{
+		"John Q. Public <jqp@example.com>",
+		"john..doe@example.com",
+		".john@example.com",
+		`"john".doe@example.com`,
+		"jürgen@example.com",
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := emailaddr.ParseAddress(input)
+			assert.NoError(t, err)
+			_, err = emailaddr.ParseAddress(input, emailaddr.Strict())
+			var perr *emailaddr.ParseError
+			assert.ErrorAs(t, err, &perr)
+		})
+	}
+
+	_, err = emailaddr.ParseList("a@example.com,,b@example.com", emailaddr.Strict())
+	assert.ErrorContains(t, err, "emailaddr: line 1, col 15:")
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"", parser.ErrUnexpectedEOF, "emailaddr: line 1, col 1: unexpected end of input"},
+		{"john", parser.ErrUnexpectedEOF, "emailaddr: line 1, col 5: unexpected end of input"},
+		{"john@", parser.ErrUnexpectedEOF, "emailaddr: line 1, col 6: unexpected end of input"},
+		{"John <john@example.com", parser.ErrUnexpectedEOF, "emailaddr: line 1, col 23: unexpected end of input"},
+		{"john@example..com", parser.ErrNoMatch, "emailaddr: line 1, col 14: unexpected '.', expected atom"},
+		{"john@example.com>", parser.ErrNoMatch, "emailaddr: line 1, col 17: unexpected '>'"},
+		{"john (comment@example.com", parser.ErrUnexpectedEOF, "emailaddr: line 1, col 26: unexpected end of input"},
+		{`"john@example.com`, parser.ErrUnexpectedEOF, "emailaddr: line 1, col 18: unexpected end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := emailaddr.ParseAddress(tt.input)
+			var perr *emailaddr.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Package emailaddr provides error reporting for malformed email addresses.
+package emailaddr
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why an address could not be parsed. Expected lists what the input should
+// contain at the error, e.g. "domain".
+type ParseError = parser.Error
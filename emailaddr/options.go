@@ -0,0 +1,31 @@
+// Package emailaddr provides options for parsing email addresses.
+package emailaddr
+
+// Option configures ParseAddress and ParseList.
+type Option func(*config)
+
+// config holds the settings of ParseAddress and ParseList.
+type config struct {
+	// strict restricts addresses to the grammar of RFC 5322 without its obsolete forms.
+	strict bool
+}
+
+// newConfig returns the configuration set by opts.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Strict accepts only the syntax RFC 5322 allows new messages to generate, in ASCII. By
+// default, addresses are parsed in a practical mode that also accepts what mail software
+// commonly produces: UTF-8 text as allowed by RFC 6532, periods in unquoted display names,
+// as in John Q. Public, local parts with leading, trailing or consecutive periods, and
+// empty elements in address lists.
+func Strict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
@@ -0,0 +1,212 @@
+// Package emailaddr provides the grammar of RFC 5322 addresses, built with the tiny-parsec
+// combinators.
+package emailaddr
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isWSP(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isVchar reports whether r is a visible ASCII character.
+func isVchar(r rune) bool {
+	return r >= '!' && r <= '~'
+}
+
+// isAtext reports whether r may appear in an atom.
+func isAtext(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r)
+}
+
+// isDotAtom reports whether s may be written as a dot-atom: atoms separated by periods.
+func isDotAtom(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || strings.IndexFunc(part, func(r rune) bool { return !isAtext(r) && r < 0x80 }) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// quote returns s as a quoted string, escaping '"' and '\'.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// phrase returns the display name s as written in a mailbox, quoted unless it consists of
+// atoms separated by single spaces.
+func phrase(s string) string {
+	for _, word := range strings.Split(s, " ") {
+		if word == "" || strings.IndexFunc(word, func(r rune) bool { return !isAtext(r) && r < 0x80 }) >= 0 {
+			return quote(s)
+		}
+	}
+	return s
+}
+
+// utf8 extends the character class f with the bytes of non-ASCII UTF-8 characters, except
+// in strict mode.
+func (c *config) utf8(f func(rune) bool) func(rune) bool {
+	return func(r rune) bool {
+		return f(r) || !c.strict && r >= 0x80
+	}
+}
+
+// fws parses folding whitespace, returning the whitespace without its line breaks.
+func fws() parser.Parser[[]rune] {
+	fold := parser.OmitLeft(parser.Str("\r\n"), parser.Satisfy(isWSP))
+	return parser.OneOrMore(parser.OrElse(parser.Satisfy(isWSP), fold))
+}
+
+// quotedPair parses a backslash escaping the next character.
+func (c *config) quotedPair() parser.Parser[rune] {
+	return parser.OmitLeft(parser.Char('\\'), parser.SatisfyMsg(c.utf8(func(r rune) bool {
+		return isVchar(r) || isWSP(r)
+	}), "escaped character"))
+}
+
+// cfws parses any mix of comments and folding whitespace, which may be nested comments in
+// parentheses.
+func (c *config) cfws() parser.Parser[[][]rune] {
+	var comment parser.Parser[[]rune]
+	ref := parser.Lazy(func() parser.Parser[[]rune] { return comment })
+	ctext := parser.Satisfy(c.utf8(func(r rune) bool { return isVchar(r) && r != '(' && r != ')' && r != '\\' }))
+	content := parser.OrElse(fws(), ref, parser.Fmap(parser.OrElse(ctext, c.quotedPair()), func(r rune) []rune { return []rune{r} }))
+	comment = parser.Nested(parser.Fmap(parser.Between(parser.Char('('), parser.ZeroOrMore(content), parser.Char(')')), func([][]rune) []rune {
+		return nil
+	}))
+	return parser.ZeroOrMore(parser.OrElse(fws(), comment))
+}
+
+// token parses p surrounded by optional comments and folding whitespace.
+func token[T any](c *config, p parser.Parser[T]) parser.Parser[T] {
+	return parser.Between(c.cfws(), p, c.cfws())
+}
+
+// quoted parses the content of a quoted string, with its quotes and escapes undone.
+func (c *config) quoted() parser.Parser[string] {
+	qtext := parser.Satisfy(c.utf8(func(r rune) bool { return isVchar(r) && r != '"' && r != '\\' }))
+	content := parser.OrElse(fws(), parser.Fmap(parser.OrElse(qtext, c.quotedPair()), func(r rune) []rune { return []rune{r} }))
+	return parser.Fmap(parser.Between(parser.Char('"'), parser.ZeroOrMore(content), parser.Char('"')), func(rss [][]rune) string {
+		var rs []rune
+		for _, s := range rss {
+			rs = append(rs, s...)
+		}
+		return parser.Text(rs)
+	})
+}
+
+// atext parses one or more characters accepted by f.
+func atext(f func(rune) bool) parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(f, "atom")), parser.Text)
+}
+
+// dotAtom parses atoms separated by periods.
+func (c *config) dotAtom() parser.Parser[string] {
+	atom := atext(c.utf8(isAtext))
+	return parser.Fmap(parser.Bind(atom, func(first string) parser.Parser[[]string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Char('.'), atom)), func(rest []string) []string {
+			return append([]string{first}, rest...)
+		})
+	}), func(parts []string) string { return strings.Join(parts, ".") })
+}
+
+// displayName parses a phrase, one or more atoms and quoted strings, joining them with
+// single spaces. Atoms may contain periods in practical mode.
+func (c *config) displayName() parser.Parser[string] {
+	atom := atext(c.utf8(func(r rune) bool { return isAtext(r) || !c.strict && r == '.' }))
+	word := token(c, parser.OrElse(c.quoted(), atom))
+	return parser.Fmap(parser.OneOrMore(word), func(words []string) string { return strings.Join(words, " ") })
+}
+
+// localPart parses the local part of an address: a dot-atom or a quoted string, or in
+// practical mode any sequence of atoms, quoted strings and periods.
+func (c *config) localPart() parser.Parser[string] {
+	if c.strict {
+		return token(c, parser.OrElse(c.dotAtom(), c.quoted()))
+	}
+	part := parser.OrElse(c.quoted(), atext(c.utf8(func(r rune) bool { return isAtext(r) || r == '.' })))
+	return token(c, parser.Fmap(parser.OneOrMore(part), func(parts []string) string { return strings.Join(parts, "") }))
+}
+
+// domain parses a dotted domain name or a domain literal in brackets.
+func (c *config) domain() parser.Parser[string] {
+	dtext := parser.Satisfy(c.utf8(func(r rune) bool { return isVchar(r) && r != '[' && r != ']' && r != '\\' }))
+	content := parser.OrElse(parser.Fmap(fws(), func([]rune) []rune { return nil }), parser.Fmap(dtext, func(r rune) []rune { return []rune{r} }))
+	literal := parser.Fmap(parser.Between(parser.Char('['), parser.ZeroOrMore(content), parser.Char(']')), func(rss [][]rune) string {
+		var rs []rune
+		for _, s := range rss {
+			rs = append(rs, s...)
+		}
+		return "[" + parser.Text(rs) + "]"
+	})
+	return token(c, parser.OrElse(c.dotAtom(), literal))
+}
+
+// addrSpec parses an address without a display name, e.g. john@example.com.
+func (c *config) addrSpec() parser.Parser[*Mailbox] {
+	return parser.Bind(c.localPart(), func(local string) parser.Parser[*Mailbox] {
+		return parser.Fmap(parser.OmitLeft(parser.Char('@'), c.domain()), func(domain string) *Mailbox {
+			return &Mailbox{Local: local, Domain: domain}
+		})
+	})
+}
+
+// mailbox parses an address with an optional display name, e.g. John <john@example.com>,
+// or without angle brackets.
+func mailbox(c *config) parser.Parser[*Mailbox] {
+	angle := token(c, parser.Between(parser.Char('<'), c.addrSpec(), parser.Char('>')))
+	named := parser.Bind(parser.ZeroOrOne(c.displayName()), func(name parser.Maybe[string]) parser.Parser[*Mailbox] {
+		return parser.Fmap(angle, func(m *Mailbox) *Mailbox {
+			m.Name = name.Get()
+			return m
+		})
+	})
+	return parser.OrElse(named, c.addrSpec())
+}
+
+// list parses a comma-separated list of one or more p. In practical mode, elements may be
+// empty.
+func list[T any](c *config, p parser.Parser[T]) parser.Parser[[]T] {
+	if c.strict {
+		return parser.Bind(p, func(first T) parser.Parser[[]T] {
+			return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Char(','), p)), func(rest []T) []T {
+				return append([]T{first}, rest...)
+			})
+		})
+	}
+	elem := parser.OrElse(parser.Fmap(p, parser.Just[T]), parser.Fmap(c.cfws(), func([][]rune) parser.Maybe[T] { return parser.Nothing[T]() }))
+	all := parser.Fmap(parser.SepBy(elem, parser.Char(',')), func(ms []parser.Maybe[T]) []T {
+		var ts []T
+		for _, m := range ms {
+			if m.IsJust() {
+				ts = append(ts, m.Get())
+			}
+		}
+		return ts
+	})
+	return parser.SatisfyWithMsg(all, func(ts []T) bool { return len(ts) > 0 }, "address")
+}
+
+// group parses a named group of mailboxes, e.g. "friends: alice@example.com;".
+func group(c *config) parser.Parser[*Group] {
+	members := parser.OrElse(list(c, mailbox(c)), parser.Fmap(c.cfws(), func([][]rune) []*Mailbox { return nil }))
+	return parser.Bind(parser.OmitRight(c.displayName(), parser.Char(':')), func(name string) parser.Parser[*Group] {
+		return parser.Fmap(parser.OmitRight(members, token(c, parser.Char(';'))), func(ms []*Mailbox) *Group {
+			return &Group{Name: name, Members: ms}
+		})
+	})
+}
+
+// addressList parses a comma-separated list of mailboxes and groups.
+func addressList(c *config) parser.Parser[[]Address] {
+	address := parser.OrElse(
+		parser.Fmap(group(c), func(g *Group) Address { return g }),
+		parser.Fmap(mailbox(c), func(m *Mailbox) Address { return m }),
+	)
+	return list(c, address)
+}
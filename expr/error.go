@@ -0,0 +1,23 @@
+// Package expr provides error reporting for invalid expressions.
+package expr
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrUnknownFunction is reported by Compile for a call to a function that is not registered.
+	ErrUnknownFunction = errors.New("unknown function")
+	// ErrArgumentCount is reported by Compile for a call with the wrong number of arguments.
+	ErrArgumentCount = errors.New("wrong number of arguments")
+	// ErrUndefinedVariable is reported by Eval for an identifier missing from the environment.
+	ErrUndefinedVariable = errors.New("undefined variable")
+)
+
+// ParseError describes why an expression could not be compiled. Expected lists what the expression
+// should contain at the error. Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping ErrUnknownFunction or
+// ErrArgumentCount.
+type ParseError = parser.Error
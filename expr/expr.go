@@ -0,0 +1,307 @@
+// Package expr provides an embeddable engine for arithmetic expressions such as
+// "min(a, b) * 2 + sqrt(x)".
+//
+// Expressions combine numbers, identifiers and function calls with the operators
+//
+//   - -        addition and subtraction
+//   - / %      multiplication, division and remainder
+//   - +        negation and unary plus
+//     ^          exponentiation, grouping from the right
+//
+// and parentheses. Identifiers are looked up in an Env supplied by the caller when the
+// expression is evaluated. Functions are registered on an Engine and resolved when the
+// expression is compiled, so that a misspelled name or a wrong number of arguments is
+// reported before any evaluation.
+package expr
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Func is a function callable from expressions. It receives the evaluated arguments.
+type Func func(args ...float64) (float64, error)
+
+// Variadic is the arity to register a function accepting one or more arguments.
+const Variadic = -1
+
+// Env supplies the values of the identifiers in an expression.
+type Env interface {
+	// Lookup returns the value of the named identifier and whether it is defined.
+	Lookup(name string) (float64, bool)
+}
+
+// Vars is an Env holding the values of identifiers in a map.
+type Vars map[string]float64
+
+// Lookup implements the Env interface.
+func (v Vars) Lookup(name string) (float64, bool) {
+	f, ok := v[name]
+	return f, ok
+}
+
+// function is a registered function with the number of arguments it accepts.
+type function struct {
+	arity int
+	fn    Func
+}
+
+// Engine compiles expressions against a set of registered functions. It is safe for
+// concurrent use.
+type Engine struct {
+	mu    sync.RWMutex
+	funcs map[string]function
+}
+
+// New returns an Engine with the builtin functions registered:
+//
+//	abs(x) ceil(x) floor(x) round(x) trunc(x) sqrt(x) cbrt(x) exp(x) ln(x) log2(x) log10(x)
+//	sin(x) cos(x) tan(x) asin(x) acos(x) atan(x) atan2(y, x) pow(x, y) hypot(x, y)
+//	min(x, ...) max(x, ...) sum(x, ...) avg(x, ...)
+func New() *Engine {
+	e := &Engine{funcs: map[string]function{}}
+	for name, f := range map[string]func(float64) float64{
+		"abs": math.Abs, "ceil": math.Ceil, "floor": math.Floor, "round": math.Round, "trunc": math.Trunc,
+		"sqrt": math.Sqrt, "cbrt": math.Cbrt, "exp": math.Exp, "ln": math.Log, "log2": math.Log2, "log10": math.Log10,
+		"sin": math.Sin, "cos": math.Cos, "tan": math.Tan, "asin": math.Asin, "acos": math.Acos, "atan": math.Atan,
+	} {
+		e.funcs[name] = function{1, func(args ...float64) (float64, error) { return f(args[0]), nil }}
+	}
+	for name, f := range map[string]func(float64, float64) float64{
+		"atan2": math.Atan2, "pow": math.Pow, "hypot": math.Hypot,
+	} {
+		e.funcs[name] = function{2, func(args ...float64) (float64, error) { return f(args[0], args[1]), nil }}
+	}
+	e.funcs["min"] = function{Variadic, func(args ...float64) (float64, error) { return slices.Min(args), nil }}
+	e.funcs["max"] = function{Variadic, func(args ...float64) (float64, error) { return slices.Max(args), nil }}
+	e.funcs["sum"] = function{Variadic, func(args ...float64) (float64, error) { return sum(args), nil }}
+	e.funcs["avg"] = function{Variadic, func(args ...float64) (float64, error) { return sum(args) / float64(len(args)), nil }}
+	return e
+}
+
+// sum adds up args.
+func sum(args []float64) float64 {
+	total := 0.0
+	for _, a := range args {
+		total += a
+	}
+	return total
+}
+
+// Register makes fn callable from expressions compiled afterwards under name, replacing any
+// previous function with that name. arity is the number of arguments fn accepts, or Variadic
+// for one or more. Expressions already compiled keep the functions they were compiled with.
+func (e *Engine) Register(name string, arity int, fn Func) error {
+	if _, err := parser.Run(identifier(), name); err != nil {
+		return fmt.Errorf("expr: invalid function name %q", name)
+	}
+	if arity < Variadic || fn == nil {
+		return fmt.Errorf("expr: invalid function %q", name)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.funcs == nil {
+		e.funcs = map[string]function{}
+	}
+	e.funcs[name] = function{arity, fn}
+	return nil
+}
+
+// Funcs returns the names of the registered functions in sorted order.
+func (e *Engine) Funcs() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return slices.Sorted(maps.Keys(e.funcs))
+}
+
+// Expr is a compiled expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses an expression and resolves its function calls against the registered functions.
+// Syntax errors, unknown functions and calls with the wrong number of arguments are reported as a
+// *ParseError.
+func (e *Engine) Compile(src string) (*Expr, error) {
+	root, err := parser.Run(expression(), src)
+	if err != nil {
+		return nil, parser.Wrap(err, "expr")
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if err := e.resolve(src, root); err != nil {
+		return nil, err
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func (e *Engine) MustCompile(src string) *Expr {
+	x, err := e.Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// Eval compiles src and evaluates it in env.
+func (e *Engine) Eval(src string, env Env) (float64, error) {
+	x, err := e.Compile(src)
+	if err != nil {
+		return 0, err
+	}
+	return x.Eval(env)
+}
+
+// resolve binds the function calls in n to the registered functions.
+func (e *Engine) resolve(src string, n node) error {
+	switch n := n.(type) {
+	case unary:
+		return e.resolve(src, n.x)
+	case binary:
+		if err := e.resolve(src, n.x); err != nil {
+			return err
+		}
+		return e.resolve(src, n.y)
+	case *call:
+		f, ok := e.funcs[n.name]
+		if !ok {
+			return parser.ErrorAt("expr", src, n.offset, fmt.Errorf("%w %q", ErrUnknownFunction, n.name))
+		}
+		if f.arity == Variadic && len(n.args) == 0 || f.arity != Variadic && len(n.args) != f.arity {
+			want := "1 or more"
+			if f.arity != Variadic {
+				want = fmt.Sprint(f.arity)
+			}
+			return parser.ErrorAt("expr", src, n.offset, fmt.Errorf("%w: %s takes %s, got %d", ErrArgumentCount, n.name, want, len(n.args)))
+		}
+		n.fn = f.fn
+		for _, arg := range n.args {
+			if err := e.resolve(src, arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defaultEngine is the Engine used by the package-level functions.
+var defaultEngine = New()
+
+// Compile parses an expression using the builtin functions listed in New.
+func Compile(src string) (*Expr, error) {
+	return defaultEngine.Compile(src)
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(src string) *Expr {
+	return defaultEngine.MustCompile(src)
+}
+
+// Eval compiles src using the builtin functions and evaluates it in env.
+func Eval(src string, env Env) (float64, error) {
+	return defaultEngine.Eval(src, env)
+}
+
+// String returns the source of the expression.
+func (x *Expr) String() string {
+	return x.src
+}
+
+// Vars returns the identifiers the expression reads from its environment, in sorted order.
+func (x *Expr) Vars() []string {
+	seen := map[string]bool{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case variable:
+			seen[string(n)] = true
+		case unary:
+			walk(n.x)
+		case binary:
+			walk(n.x)
+			walk(n.y)
+		case *call:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(x.root)
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// Eval evaluates the expression with the identifiers bound in env, which may be nil if the
+// expression has none. It fails with ErrUndefinedVariable for an identifier missing from env,
+// and with the error of a function that fails.
+func (x *Expr) Eval(env Env) (float64, error) {
+	if env == nil {
+		env = Vars(nil)
+	}
+	return x.root.eval(env)
+}
+
+func (n number) eval(Env) (float64, error) {
+	return float64(n), nil
+}
+
+func (n variable) eval(env Env) (float64, error) {
+	if f, ok := env.Lookup(string(n)); ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("expr: %w %q", ErrUndefinedVariable, string(n))
+}
+
+func (n unary) eval(env Env) (float64, error) {
+	x, err := n.x.eval(env)
+	if n.op == '-' {
+		x = -x
+	}
+	return x, err
+}
+
+func (n binary) eval(env Env) (float64, error) {
+	x, err := n.x.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	y, err := n.y.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return x + y, nil
+	case '-':
+		return x - y, nil
+	case '*':
+		return x * y, nil
+	case '/':
+		return x / y, nil
+	case '%':
+		return math.Mod(x, y), nil
+	}
+	return math.Pow(x, y), nil
+}
+
+func (n *call) eval(env Env) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		f, err := arg.eval(env)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = f
+	}
+	f, err := n.fn(args...)
+	if err != nil {
+		return 0, fmt.Errorf("expr: %s: %w", n.name, err)
+	}
+	return f, nil
+}
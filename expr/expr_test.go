@@ -0,0 +1,150 @@
+package expr_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/81120/tiny-parsec/expr"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	env := expr.Vars{"a": 3, "b": 5, "x": 16, "rate_2": 0.5}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"42", 42},
+		{"1.5e2", 150},
+		{".5 + 2.", 2.5},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"2 ^ -1", 0.5},
+		{"- -a", 3},
+		{"+a", 3},
+		{"10 % 4", 2},
+		{"7 / 2", 3.5},
+		{"min(a, b) * 2 + sqrt(x)", 10},
+		{"max(a, b, x)", 16},
+		{"avg(a, b)", 4},
+		{"pow(2, 10)", 1024},
+		{"abs(a - b)", 2},
+		{"round(x * rate_2 + 0.4)", 8},
+		{"  sqrt( x )  ", 4},
+		{"max(min(a, b), 1)", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := expr.Eval(tt.src, env)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	x := expr.MustCompile("hypot(dx, dy) / n + dx")
+	assert.Equal(t, "hypot(dx, dy) / n + dx", x.String())
+	assert.Equal(t, []string{"dx", "dy", "n"}, x.Vars())
+
+	for _, env := range []expr.Vars{{"dx": 3, "dy": 4, "n": 1}, {"dx": 6, "dy": 8, "n": 2}} {
+		got, err := x.Eval(env)
+		assert.NoError(t, err)
+		assert.Equal(t, env["dx"]+5, got)
+	}
+
+	_, err := x.Eval(expr.Vars{"dx": 3, "dy": 4})
+	assert.ErrorIs(t, err, expr.ErrUndefinedVariable)
+	assert.EqualError(t, err, `expr: undefined variable "n"`)
+
+	got, err := expr.MustCompile("1 / 0").Eval(nil)
+	assert.NoError(t, err)
+	assert.True(t, math.IsInf(got, 1))
+}
+
+func TestRegister(t *testing.T) {
+	e := expr.New()
+	_, err := e.Compile("clamp(v, 0, 10)")
+	assert.ErrorIs(t, err, expr.ErrUnknownFunction)
+
+	assert.NoError(t, e.Register("clamp", 3, func(args ...float64) (float64, error) {
+		return min(max(args[0], args[1]), args[2]), nil
+	}))
+	errNegative := errors.New("negative argument")
+	assert.NoError(t, e.Register("fact", 1, func(args ...float64) (float64, error) {
+		if args[0] < 0 {
+			return 0, errNegative
+		}
+		f := 1.0
+		for i := 2.0; i <= args[0]; i++ {
+			f *= i
+		}
+		return f, nil
+	}))
+	assert.Contains(t, e.Funcs(), "clamp")
+
+	clamp := e.MustCompile("clamp(v, 0, 10)")
+	got, err := clamp.Eval(expr.Vars{"v": 12})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, got)
+
+	got, err = e.Eval("fact(n) / 2", expr.Vars{"n": 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, got)
+	_, err = e.Eval("fact(-n)", expr.Vars{"n": 5})
+	assert.ErrorIs(t, err, errNegative)
+	assert.EqualError(t, err, "expr: fact: negative argument")
+
+	// Compiled expressions keep the functions they were compiled with.
+	assert.NoError(t, e.Register("clamp", 3, func(args ...float64) (float64, error) { return 0, nil }))
+	got, err = clamp.Eval(expr.Vars{"v": 12})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, got)
+
+	// Engines are independent of each other and of the package-level functions.
+	_, err = expr.Compile("clamp(1, 2, 3)")
+	assert.ErrorIs(t, err, expr.ErrUnknownFunction)
+
+	assert.Error(t, e.Register("2x", 1, func(args ...float64) (float64, error) { return 0, nil }))
+	assert.Error(t, e.Register("f", -2, func(args ...float64) (float64, error) { return 0, nil }))
+	assert.Error(t, e.Register("f", 1, nil))
+
+	var zero expr.Engine
+	assert.NoError(t, zero.Register("one", 0, func(...float64) (float64, error) { return 1, nil }))
+	got, err = zero.Eval("one() + 1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, got)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+		msg string
+	}{
+		{"1 +", parser.ErrUnexpectedEOF, "expr: line 1, col 4: unexpected end of input, expected '-', '+', number, identifier or '('"},
+		{"a b", parser.ErrNoMatch, "expr: line 1, col 3: unexpected 'b', expected '(', '^', '*', '/', '%', '+', '-' or end of input"},
+		{"(1 + 2", parser.ErrUnexpectedEOF, "expr: line 1, col 7: unexpected end of input"},
+		{"min(1,)", parser.ErrNoMatch, "expr: line 1, col 7: unexpected ')'"},
+		{"", parser.ErrUnexpectedEOF, "expr: line 1, col 1: unexpected end of input"},
+		{"1 + nope(2)", expr.ErrUnknownFunction, `expr: line 1, col 5: unknown function "nope"`},
+		{"sqrt(1, 2)", expr.ErrArgumentCount, "expr: line 1, col 1: wrong number of arguments: sqrt takes 1, got 2"},
+		{"2 *\n  max()", expr.ErrArgumentCount, "expr: line 2, col 3: wrong number of arguments: max takes 1 or more, got 0"},
+		{"abs(nope(1))", expr.ErrUnknownFunction, `expr: line 1, col 5: unknown function "nope"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := expr.Compile(tt.src)
+			var perr *expr.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+	assert.Panics(t, func() { expr.MustCompile("1 +") })
+}
@@ -0,0 +1,191 @@
+// Package expr provides the grammar of arithmetic expressions, built with the tiny-parsec combinators.
+package expr
+
+import (
+	"strconv"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// node is an element of the syntax tree of an expression.
+type node interface {
+	eval(env Env) (float64, error)
+}
+
+// number is a numeric literal.
+type number float64
+
+// variable is an identifier whose value is looked up in the environment.
+type variable string
+
+// unary is a negated or explicitly positive operand.
+type unary struct {
+	op byte
+	x  node
+}
+
+// binary applies one of the operators + - * / % ^ to two operands.
+type binary struct {
+	op   byte
+	x, y node
+}
+
+// call is a function call. fn is set by Engine.Compile once the name has been resolved.
+type call struct {
+	name   string
+	offset int
+	args   []node
+	fn     Func
+}
+
+// step is an operator with its right operand, as parsed after the first operand of a chain.
+type step struct {
+	op byte
+	y  node
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isIdentChar(r rune) bool {
+	return isIdentStart(r) || isDigit(r)
+}
+
+// spaces skips whitespace. Unlike parser.Spaces it records no expectation, so that errors
+// list the tokens that could follow rather than whitespace.
+func spaces() parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		s := st.Input()
+		i := 0
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+			i++
+		}
+		next, _ := st.Advance(i)
+		return parser.Just(parser.NewTuple(struct{}{}, next))
+	})
+}
+
+// token skips the whitespace following p.
+func token[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitRight(p, spaces())
+}
+
+// identifier parses a name made of letters, digits and underscores, not starting with a digit.
+func identifier() parser.Parser[string] {
+	start := parser.SatisfyMsg(isIdentStart, "identifier")
+	return parser.Fmap(
+		parser.Seq(
+			parser.Fmap(start, func(r rune) []rune { return []rune{r} }),
+			parser.ZeroOrMore(parser.Satisfy(isIdentChar)),
+		),
+		func(parts [][]rune) string { return parser.Text(append(parts[0], parts[1]...)) },
+	)
+}
+
+// numberLit parses a decimal literal with an optional fraction and exponent, e.g. "42", ".5" or "6.02e23".
+func numberLit() parser.Parser[node] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[node] {
+		s := st.Input()
+		i := 0
+		digits := func() int {
+			n := 0
+			for i < len(s) && isDigit(rune(s[i])) {
+				i++
+				n++
+			}
+			return n
+		}
+		n := digits()
+		if i < len(s) && s[i] == '.' {
+			i++
+			n += digits()
+		}
+		if n == 0 {
+			st.Fail("number")
+			return parser.Nothing[parser.Tuple[node, parser.State]]()
+		}
+		if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+			j := i
+			i++
+			if i < len(s) && (s[i] == '+' || s[i] == '-') {
+				i++
+			}
+			if digits() == 0 {
+				i = j
+			}
+		}
+		f, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			st.Fail("number")
+			return parser.Nothing[parser.Tuple[node, parser.State]]()
+		}
+		next, _ := st.Advance(i)
+		return parser.Just(parser.NewTuple[node](number(f), next))
+	})
+}
+
+// chain parses operands separated by the operators in ops, grouping them from the left.
+func chain(operand parser.Parser[node], ops ...rune) parser.Parser[node] {
+	alts := make([]parser.Parser[rune], len(ops))
+	for i, op := range ops {
+		alts[i] = token(parser.Char(op))
+	}
+	rest := parser.ZeroOrMore(parser.Bind(parser.OrElse(alts...), func(op rune) parser.Parser[step] {
+		return parser.Fmap(operand, func(y node) step { return step{byte(op), y} })
+	}))
+	return parser.Bind(operand, func(x node) parser.Parser[node] {
+		return parser.Fmap(rest, func(steps []step) node {
+			for _, s := range steps {
+				x = binary{s.op, x, s.y}
+			}
+			return x
+		})
+	})
+}
+
+// expression parses a complete expression. From the loosest to the tightest binding, the
+// operators are + and -, then * / and %, then unary - and +, then ^, which groups from the right.
+func expression() parser.Parser[node] {
+	var sum parser.Parser[node]
+	ref := parser.Lazy(func() parser.Parser[node] { return sum })
+	var signed parser.Parser[node]
+	signedRef := parser.Lazy(func() parser.Parser[node] { return signed })
+
+	args := parser.Between(
+		token(parser.Char('(')),
+		parser.SepBy(ref, token(parser.Char(','))),
+		token(parser.Char(')')),
+	)
+	name := parser.Bind(parser.Pos(), func(offset int) parser.Parser[node] {
+		return parser.Bind(token(identifier()), func(name string) parser.Parser[node] {
+			return parser.OrElse(
+				parser.Fmap(args, func(args []node) node { return &call{name: name, offset: offset, args: args} }),
+				parser.Pure[node](variable(name)),
+			)
+		})
+	})
+	primary := parser.OrElse(
+		token(numberLit()),
+		name,
+		parser.Between(token(parser.Char('(')), ref, token(parser.Char(')'))),
+	)
+	power := parser.Bind(primary, func(x node) parser.Parser[node] {
+		return parser.OrElse(
+			parser.Fmap(parser.OmitLeft(token(parser.Char('^')), signedRef), func(y node) node { return binary{'^', x, y} }),
+			parser.Pure(x),
+		)
+	})
+	signed = parser.OrElse(
+		parser.Bind(token(parser.OrElse(parser.Char('-'), parser.Char('+'))), func(op rune) parser.Parser[node] {
+			return parser.Fmap(signedRef, func(x node) node { return unary{byte(op), x} })
+		}),
+		power,
+	)
+	sum = chain(chain(signed, '*', '/', '%'), '+', '-')
+	return parser.OmitLeft(spaces(), sum)
+}
@@ -0,0 +1,18 @@
+// Package frontmatter provides error reporting for malformed front matter.
+package frontmatter
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ErrNoDecoder is reported by Parse for YAML front matter when no decoder for it is configured
+// with DecodeYAMLWith.
+var ErrNoDecoder = errors.New("no decoder configured")
+
+// ParseError describes why the front matter of a document could not be split off or decoded.
+// Expected lists what the document should contain at the error. Err is the underlying cause:
+// parser.ErrUnexpectedEOF for front matter missing its closing delimiter, an error wrapping
+// ErrNoDecoder, or the error of the YAML decoder.
+type ParseError = parser.Error
@@ -0,0 +1,154 @@
+// Package frontmatter extracts the front matter at the top of a document, as used by static
+// site generators, and decodes it into a mapping of keys to values. Three formats are
+// recognized: YAML between "---" lines, which may also be closed by a "..." line,
+//
+//	---
+//	title: Hello
+//	---
+//	Body text.
+//
+// TOML between "+++" lines, decoded with the toml package, and a JSON object, decoded with
+// the json package, whose closing brace stands on a line of its own:
+//
+//	{
+//	  "title": "Hello"
+//	}
+//	Body text.
+//
+// There is no YAML package in this module, so YAML front matter is decoded by a function
+// supplied with DecodeYAMLWith; without one, Parse fails with ErrNoDecoder.
+package frontmatter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/toml"
+)
+
+// Format is the format of the front matter of a document.
+type Format int
+
+const (
+	// None is the format of documents without front matter.
+	None Format = iota
+	// YAML front matter is delimited by "---" lines.
+	YAML
+	// TOML front matter is delimited by "+++" lines.
+	TOML
+	// JSON front matter is an object starting the document.
+	JSON
+)
+
+// String returns the name of the format, e.g. "yaml".
+func (f Format) String() string {
+	switch f {
+	case YAML:
+		return "yaml"
+	case TOML:
+		return "toml"
+	case JSON:
+		return "json"
+	}
+	return "none"
+}
+
+// Document is a document split into its front matter and body.
+type Document struct {
+	// Format is the format of the front matter, or None if the document has none.
+	Format Format
+	// Raw is the text of the front matter, without the delimiter lines of YAML and TOML but
+	// with the braces of JSON.
+	Raw string
+	// Metadata holds the decoded front matter as plain Go values, as returned by
+	// toml.ToNative and json.ToNative. It is nil for documents split by Split, and empty for
+	// documents without front matter.
+	Metadata map[string]any
+	// Body is the text of the document following the front matter.
+	Body string
+}
+
+// Split splits the front matter off the document s without decoding it. A byte order mark
+// at the start of s is skipped. Front matter missing its closing delimiter is reported as
+// a *ParseError wrapping parser.ErrUnexpectedEOF.
+func Split(s string) (*Document, error) {
+	d, _, err := split(s)
+	return d, err
+}
+
+// split splits the document s, also returning the offset of its front matter.
+func split(s string) (*Document, int, error) {
+	t, err := parser.Run(document(), s)
+	if err != nil {
+		return nil, 0, parser.Wrap(err, "frontmatter")
+	}
+	fm := t.First
+	return &Document{Format: fm.format, Raw: fm.raw, Body: s[t.Second:]}, fm.offset, nil
+}
+
+// Parse splits the front matter off the document s like Split and decodes it. YAML front
+// matter requires the DecodeYAMLWith option. Errors in TOML and JSON front matter are reported as the *toml.ParseError or *json.SyntaxError of
+// the decoder, with their positions counted from the start of s.
+func Parse(s string, opts ...Option) (*Document, error) {
+	c := newConfig(opts)
+	d, offset, err := split(s)
+	if err != nil {
+		return nil, err
+	}
+	d.Metadata = map[string]any{}
+	switch d.Format {
+	case YAML:
+		if c.decodeYAML == nil {
+			return nil, parser.ErrorAt("frontmatter", s, offset, fmt.Errorf("%w for %s front matter, see DecodeYAMLWith", ErrNoDecoder, d.Format))
+		}
+		m, err := c.decodeYAML(d.Raw)
+		if err != nil {
+			return nil, parser.ErrorAt("frontmatter", s, offset, err)
+		}
+		if m != nil {
+			d.Metadata = m
+		}
+	case TOML:
+		tbl, err := toml.Parse(d.Raw)
+		if err != nil {
+			return nil, rebase(s, offset, err)
+		}
+		d.Metadata = toml.ToNative(tbl).(map[string]any)
+	case JSON:
+		j, err := json.ParseJSON(d.Raw)
+		if err != nil {
+			return nil, rebase(s, offset, err)
+		}
+		d.Metadata = json.ToNative(j).(map[string]any)
+	}
+	return d, nil
+}
+
+// rebase moves the position of an error of the toml or json package in front matter
+// starting at offset to the position in the document s.
+func rebase(s string, offset int, err error) error {
+	before := s[:offset]
+	start := strings.LastIndexByte(before, '\n') + 1
+	line := strings.Count(before, "\n")
+	col := utf8.RuneCountInString(before[start:])
+	move := func(o, l, c *int) {
+		if *l == 1 {
+			*c += col
+		}
+		*o += offset
+		*l += line
+	}
+	var terr *toml.ParseError
+	var jerr *json.SyntaxError
+	switch {
+	case errors.As(err, &terr):
+		move(&terr.Offset, &terr.Line, &terr.Column)
+	case errors.As(err, &jerr):
+		move(&jerr.Offset, &jerr.Line, &jerr.Column)
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package frontmatter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/frontmatter"
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format frontmatter.Format
+		raw    string
+		body   string
+	}{
+		{"yaml", "---\ntitle: Hello\n---\nBody\n", frontmatter.YAML, "title: Hello\n", "Body\n"},
+		{"yaml closed by dots", "--- \r\na: 1\r\n...\r\nBody", frontmatter.YAML, "a: 1\r\n", "Body"},
+		{"toml", "+++\ntitle = \"Hello\"\n+++\n\nBody", frontmatter.TOML, "title = \"Hello\"\n", "\nBody"},
+		{"json", "{\n  \"title\": \"Hello\"\n}\nBody", frontmatter.JSON, "{\n  \"title\": \"Hello\"\n}", "Body"},
+		{"empty", "---\n---\n", frontmatter.YAML, "", ""},
+		{"at end", "+++\na = 1\n+++", frontmatter.TOML, "a = 1\n", ""},
+		{"byte order mark", "\ufeff---\na: 1\n---\nBody", frontmatter.YAML, "a: 1\n", "Body"},
+		{"none", "# Title\n---\nBody", frontmatter.None, "", "# Title\n---\nBody"},
+		{"rule is not a delimiter", "----\nBody", frontmatter.None, "", "----\nBody"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := frontmatter.Split(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, &frontmatter.Document{Format: tt.format, Raw: tt.raw, Body: tt.body}, d)
+		})
+	}
+
+	_, err := frontmatter.Split("---\na: 1\nBody")
+	var perr *frontmatter.ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.ErrorIs(t, err, parser.ErrUnexpectedEOF)
+	assert.EqualError(t, err, `frontmatter: line 3, col 5: unexpected end of input, expected "---"`)
+}
+
+func TestParse(t *testing.T) {
+	d, err := frontmatter.Parse("+++\ntitle = \"Hello\"\ndate = 2024-01-02T03:04:05Z\ntags = [\"a\", \"b\"]\n+++\nBody")
+	assert.NoError(t, err)
+	assert.Equal(t, frontmatter.TOML, d.Format)
+	assert.Equal(t, map[string]any{
+		"title": "Hello",
+		"date":  time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		"tags":  []any{"a", "b"},
+	}, d.Metadata)
+	assert.Equal(t, "Body", d.Body)
+
+	d, err = frontmatter.Parse("{\n  \"title\": \"Hello\",\n  \"draft\": true\n}\nBody")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"title": "Hello", "draft": true}, d.Metadata)
+
+	d, err = frontmatter.Parse("Just a body")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{}, d.Metadata)
+	assert.Equal(t, "Just a body", d.Body)
+}
+
+func TestParseYAML(t *testing.T) {
+	doc := "---\ntitle: Hello\n---\nBody"
+
+	_, err := frontmatter.Parse(doc)
+	assert.ErrorIs(t, err, frontmatter.ErrNoDecoder)
+	assert.EqualError(t, err, "frontmatter: line 2, col 1: no decoder configured for yaml front matter, see DecodeYAMLWith")
+
+	decode := func(s string) (map[string]any, error) {
+		k, v, _ := strings.Cut(strings.TrimSpace(s), ": ")
+		return map[string]any{k: v}, nil
+	}
+	d, err := frontmatter.Parse(doc, frontmatter.DecodeYAMLWith(decode))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"title": "Hello"}, d.Metadata)
+
+	boom := errors.New("boom")
+	_, err = frontmatter.Parse(doc, frontmatter.DecodeYAMLWith(func(string) (map[string]any, error) { return nil, boom }))
+	assert.ErrorIs(t, err, boom)
+	assert.EqualError(t, err, "frontmatter: line 2, col 1: boom")
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := frontmatter.Parse("+++\na = 1\nb = \n+++\n")
+	var terr *toml.ParseError
+	assert.ErrorAs(t, err, &terr)
+	assert.Equal(t, 3, terr.Line)
+	assert.Equal(t, 5, terr.Column)
+
+	_, err = frontmatter.Parse("\ufeff{\"a\": }\n}\n")
+	var jerr *json.SyntaxError
+	assert.ErrorAs(t, err, &jerr)
+	assert.Equal(t, 1, jerr.Line)
+	assert.Equal(t, 8, jerr.Column)
+	assert.Equal(t, 9, jerr.Offset)
+}
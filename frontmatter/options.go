@@ -0,0 +1,29 @@
+// Package frontmatter provides options for decoding front matter.
+package frontmatter
+
+// Option configures Parse.
+type Option func(*config)
+
+// config holds the settings of Parse.
+type config struct {
+	// decodeYAML decodes YAML front matter; Parse fails with ErrNoDecoder if it is nil.
+	decodeYAML func(s string) (map[string]any, error)
+}
+
+// newConfig returns the configuration set by opts.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DecodeYAMLWith decodes YAML front matter with decode, which returns the mapping at the top
+// of the YAML document s, e.g. using a third-party YAML package. Without it, Parse reports
+// YAML front matter as an error wrapping ErrNoDecoder.
+func DecodeYAMLWith(decode func(s string) (map[string]any, error)) Option {
+	return func(c *config) {
+		c.decodeYAML = decode
+	}
+}
@@ -0,0 +1,127 @@
+// Package frontmatter provides the grammar splitting the front matter off a document, built
+// with the tiny-parsec combinators.
+package frontmatter
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// section is the front matter of a document as written.
+type section struct {
+	format Format
+	// raw is the text of the front matter, without the delimiter lines of YAML and TOML.
+	raw string
+	// offset is the byte offset of raw in the document.
+	offset int
+}
+
+// formatOf returns the format of the front matter starting s, which is opened by a "---"
+// line for YAML, a "+++" line for TOML and a "{" for JSON, or None.
+func formatOf(s string) Format {
+	first, _, _ := strings.Cut(s, "\n")
+	switch strings.TrimRight(first, " \t\r") {
+	case "---":
+		return YAML
+	case "+++":
+		return TOML
+	}
+	if strings.HasPrefix(s, "{") {
+		return JSON
+	}
+	return None
+}
+
+// detect returns the format of the front matter at the start of the input, without
+// consuming it.
+func detect() parser.Parser[Format] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[Format] {
+		return parser.Just(parser.NewTuple(formatOf(st.Input()), st))
+	})
+}
+
+// rest parses the remaining input.
+func rest() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		next, ok := st.Advance(len(s))
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s, next))
+	})
+}
+
+// until parses lines up to and including the first line consisting of one of closes,
+// ignoring trailing whitespace, and returns the text before that line.
+func until(closes ...string) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		for i := 0; ; {
+			end, next := len(s), len(s)
+			if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+				end, next = i+n, i+n+1
+			}
+			if slices.Contains(closes, strings.TrimRight(s[i:end], " \t\r")) {
+				after, ok := st.Advance(next)
+				if !ok {
+					return parser.Nothing[parser.Tuple[string, parser.State]]()
+				}
+				return parser.Just(parser.NewTuple(s[:i], after))
+			}
+			if next == len(s) {
+				break
+			}
+			i = next
+		}
+		if end, ok := st.Advance(len(s)); ok {
+			end.Truncated(strconv.Quote(closes[0]))
+		}
+		return parser.Nothing[parser.Tuple[string, parser.State]]()
+	})
+}
+
+// fenced parses front matter between two delim lines, or a line in alts for the closing one.
+func fenced(format Format, delim string, alts ...string) parser.Parser[section] {
+	open := parser.OmitLeft(parser.Str(delim), parser.ZeroOrMore(parser.NotChar('\n')))
+	body := parser.OmitLeft(parser.OmitLeft(open, parser.Char('\n')), parser.Pos())
+	return parser.Bind(body, func(offset int) parser.Parser[section] {
+		return parser.Fmap(until(append([]string{delim}, alts...)...), func(raw string) section {
+			return section{format: format, raw: raw, offset: offset}
+		})
+	})
+}
+
+// braced parses JSON front matter, from a "{" at the start of the document to a line
+// consisting of "}".
+func braced() parser.Parser[section] {
+	return parser.Bind(parser.OmitRight(parser.Pos(), parser.Char('{')), func(offset int) parser.Parser[section] {
+		return parser.Fmap(until("}"), func(raw string) section {
+			return section{format: JSON, raw: "{" + raw + "}", offset: offset}
+		})
+	})
+}
+
+// document parses a document, returning its front matter and the offset of its body.
+func document() parser.Parser[parser.Tuple[section, int]] {
+	fm := parser.Bind(detect(), func(f Format) parser.Parser[section] {
+		switch f {
+		case YAML:
+			return fenced(YAML, "---", "...")
+		case TOML:
+			return fenced(TOML, "+++")
+		case JSON:
+			return braced()
+		}
+		return parser.Pure(section{})
+	})
+	bom := parser.ZeroOrOne(parser.Str("\ufeff"))
+	return parser.OmitLeft(bom, parser.Bind(fm, func(s section) parser.Parser[parser.Tuple[section, int]] {
+		return parser.OmitRight(parser.Fmap(parser.Pos(), func(body int) parser.Tuple[section, int] {
+			return parser.NewTuple(s, body)
+		}), rest())
+	}))
+}
@@ -0,0 +1,75 @@
+// Package hocon provides error reporting for malformed HOCON documents.
+package hocon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrUnresolved is reported for a substitution of a path that is not set, unless it is
+	// optional, i.e. written ${?path}.
+	ErrUnresolved = errors.New("unresolved substitution")
+	// ErrCycle is reported for a substitution whose value depends on itself.
+	ErrCycle = errors.New("substitution cycle")
+	// ErrConcat is reported for a concatenation of values of different kinds, e.g. of an object
+	// and a string.
+	ErrConcat = errors.New("cannot concatenate values of different kinds")
+	// ErrIncludeCycle is reported for a resource that includes itself, directly or through others.
+	ErrIncludeCycle = errors.New("include cycle")
+	// ErrIncludeNotFound is reported for a required include of a resource that does not exist.
+	ErrIncludeNotFound = errors.New("included resource not found")
+)
+
+// ParseError describes why a HOCON document could not be parsed or resolved.
+type ParseError struct {
+	// Include is the name of the included resource containing the error; it is empty for the
+	// document passed to Parse.
+	Include string
+	// Offset is the byte offset of the error in the document.
+	Offset int
+	// Line is the 1-based line number of the error.
+	Line int
+	// Column is the 1-based column of the error, counted in runes.
+	Column int
+	// Found describes the input at the error for syntax errors; it is empty at the end of input.
+	Found string
+	// Expected lists what the document should contain at the error, for syntax errors.
+	Expected []string
+	// Err is the underlying cause: parser.ErrNoMatch or parser.ErrUnexpectedEOF for syntax
+	// errors, or an error wrapping one of the errors of this package.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	msg := "hocon: "
+	if e.Include != "" {
+		msg += e.Include + ": "
+	}
+	msg += fmt.Sprintf("line %d, col %d: ", e.Line, e.Column)
+	return msg + parser.Describe(e.Found, e.Expected, e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError converts an error returned by the parser package into a *ParseError.
+// Other errors are returned unchanged.
+func parseError(err error) error {
+	var e *parser.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	return &ParseError{Offset: e.Offset, Line: e.Line, Column: e.Column, Found: e.Found, Expected: e.Expected, Err: e.Err}
+}
+
+// errorAt returns a *ParseError for err at the given byte offset of src.
+func errorAt(src string, offset int, err error) *ParseError {
+	e := parser.ErrorAt("hocon", src, offset, err)
+	return &ParseError{Offset: e.Offset, Line: e.Line, Column: e.Column, Err: err}
+}
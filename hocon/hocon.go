@@ -0,0 +1,441 @@
+// Package hocon parses HOCON (Human-Optimized Config Object Notation), the configuration format of
+// the Typesafe Config library, into json.Json values.
+//
+// HOCON is a superset of JSON meant to be written by hand:
+//
+//	# Comments start with '#' or "//".
+//	server {                        // the root braces and the ':' before an object are optional
+//	  host = localhost              // '=' is the same as ':', and strings need no quotes
+//	  port = 8080
+//	}
+//	server.timeout = 30s            // a path key sets a nested field
+//	server { port = 9090 }          // objects with the same key merge; other values replace
+//	base = /opt/app
+//	paths = [ ${base}/bin           // substitutions refer to other fields; commas may be
+//	          ${base}/lib ]         // replaced by line breaks
+//	paths += ${base}/share          // "+=" appends to an array
+//	greeting = Hello ${?USER}       // ${?x} is optional and vanishes if x is not set
+//	include "defaults.conf"         // fields of another document, see IncludeFS
+//
+// Values written next to each other on a line concatenate: strings join with the whitespace
+// between them, arrays append and objects merge. A substitution of the field being set refers
+// to its previous value, e.g. path = ${path}":/usr/bin".
+//
+// Substitutions are resolved once the whole document is read, against the root of the document,
+// including in included documents. The resulting object keeps its keys in the order they first
+// appear.
+package hocon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Parse parses and resolves the HOCON document s into a json.JsonObject, or a json.JsonArray if
+// the document is an array.
+func Parse(s string, opts ...Option) (json.Json, error) {
+	c := newConfig(opts)
+	d := &document{text: s}
+	lit, err := parser.Run(d.root(), s)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	b := &builder{c: c}
+	root, err := b.build(lit, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &resolver{c: c, done: map[node]result{}, active: map[node]bool{}}
+	if o, ok := root.(*object); ok {
+		r.root = o
+	} else {
+		r.root = &object{fields: map[string]node{}}
+	}
+	j, _, err := r.resolve(root)
+	return j, err
+}
+
+// object is an object whose fields are merged, but whose values are not resolved yet.
+type object struct {
+	keys   []string
+	fields map[string]node
+}
+
+// put sets the field key to v, keeping the position of an existing key.
+func (o *object) put(key string, v node) {
+	if _, ok := o.fields[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.fields[key] = v
+}
+
+// array is an array whose elements are not resolved yet.
+type array struct {
+	elems []node
+}
+
+// prior is the value a field had before a substitution of the field itself, at the path rest
+// below it; n is nil if the field had none.
+type prior struct {
+	n    node
+	rest []string
+	s    *subst
+}
+
+// builder builds the objects of a document, merging repeated keys and processing includes.
+type builder struct {
+	c *config
+	// including lists the names of the documents being included, to detect cycles.
+	including []string
+}
+
+// build converts a node as written into a node with built objects and arrays. The fields of
+// objects are at path below the root.
+func (b *builder) build(n node, path []string) (node, error) {
+	switch n := n.(type) {
+	case *objectLit:
+		o := &object{fields: map[string]node{}}
+		return o, b.fill(o, n, path)
+	case *arrayLit:
+		a := &array{}
+		for _, e := range n.elems {
+			v, err := b.build(e, path)
+			if err != nil {
+				return nil, err
+			}
+			a.elems = append(a.elems, v)
+		}
+		return a, nil
+	case *concat:
+		c := &concat{doc: n.doc, offset: n.offset}
+		for _, p := range n.parts {
+			v, err := b.build(p, path)
+			if err != nil {
+				return nil, err
+			}
+			c.parts = append(c.parts, v)
+		}
+		return c, nil
+	}
+	return n, nil
+}
+
+// fill sets the fields of lit in o, which is at path below the root.
+func (b *builder) fill(o *object, lit *objectLit, path []string) error {
+	for _, f := range lit.fields {
+		if f.include != nil {
+			if err := b.include(o, f, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := b.set(o, f, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set sets the field f in o, which is at path below the root. An object merges into an object
+// set before at the same key; any other value replaces it.
+func (b *builder) set(o *object, f field, path []string) error {
+	for _, k := range f.path[:len(f.path)-1] {
+		child, ok := o.fields[k].(*object)
+		if !ok {
+			child = &object{fields: map[string]node{}}
+			o.put(k, child)
+		}
+		o = child
+	}
+	full := slices.Concat(path, f.path)
+	key := f.path[len(f.path)-1]
+	prev, had := o.fields[key]
+	if lit, ok := f.value.(*objectLit); ok && !f.appends {
+		if po, ok := prev.(*object); ok {
+			return b.fill(po, lit, full)
+		}
+	}
+	v, err := b.build(f.value, full)
+	if err != nil {
+		return err
+	}
+	if f.appends {
+		self := &subst{doc: f.doc, offset: f.offset, path: full, optional: true}
+		v = &concat{doc: f.doc, offset: f.offset, parts: []node{self, &array{elems: []node{v}}}}
+	}
+	if !had {
+		prev = nil
+	}
+	o.put(key, replaceSelf(v, full, prev))
+	return nil
+}
+
+// replaceSelf replaces the substitutions of path within v, which is being set at path, by
+// references to prev, the value previously set there. It does not descend into objects, whose
+// fields are set at other paths.
+func replaceSelf(v node, path []string, prev node) node {
+	switch v := v.(type) {
+	case *subst:
+		if len(v.path) >= len(path) && slices.Equal(v.path[:len(path)], path) {
+			return &prior{n: prev, rest: v.path[len(path):], s: v}
+		}
+	case *array:
+		for i, e := range v.elems {
+			v.elems[i] = replaceSelf(e, path, prev)
+		}
+	case *concat:
+		for i, p := range v.parts {
+			v.parts[i] = replaceSelf(p, path, prev)
+		}
+	}
+	return v
+}
+
+// include sets the fields of the document included by f in o, which is at path below the root.
+// A missing document is skipped unless the include is required.
+func (b *builder) include(o *object, f field, path []string) error {
+	name := f.include.name
+	if slices.Contains(b.including, name) {
+		return f.doc.errorAt(f.offset, fmt.Errorf("%w: %s", ErrIncludeCycle, name))
+	}
+	src, err := "", fs.ErrNotExist
+	if b.c.include != nil {
+		src, err = b.c.include(name)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		if f.include.required {
+			return f.doc.errorAt(f.offset, fmt.Errorf("%w: %s", ErrIncludeNotFound, name))
+		}
+		return nil
+	}
+	if err != nil {
+		return f.doc.errorAt(f.offset, err)
+	}
+	d := &document{name: name, text: src}
+	lit, err := parser.Run(d.root(), src)
+	if err != nil {
+		err = parseError(err)
+		if perr, ok := err.(*ParseError); ok {
+			perr.Include = name
+		}
+		return err
+	}
+	ol, ok := lit.(*objectLit)
+	if !ok {
+		return d.errorAt(0, errors.New("included document is not an object"))
+	}
+	b.including = append(b.including, name)
+	defer func() { b.including = b.including[:len(b.including)-1] }()
+	return b.fill(o, ol, path)
+}
+
+// result is a resolved value; ok is false for an optional substitution of a path that is not
+// set, which vanishes.
+type result struct {
+	j  json.Json
+	ok bool
+}
+
+// resolver resolves the substitutions of a document.
+type resolver struct {
+	c    *config
+	root *object
+	// done holds the values already resolved, and active the nodes being resolved, to detect
+	// cycles.
+	done   map[node]result
+	active map[node]bool
+}
+
+// resolve returns the value of n with its substitutions resolved.
+func (r *resolver) resolve(n node) (json.Json, bool, error) {
+	if res, ok := r.done[n]; ok {
+		return res.j, res.ok, nil
+	}
+	r.active[n] = true
+	j, ok, err := r.eval(n)
+	delete(r.active, n)
+	if err != nil {
+		return nil, false, err
+	}
+	r.done[n] = result{j, ok}
+	return j, ok, nil
+}
+
+// eval computes the value of n, which resolve memoizes.
+func (r *resolver) eval(n node) (json.Json, bool, error) {
+	switch n := n.(type) {
+	case *scalar:
+		return n.val, true, nil
+	case *object:
+		obj := json.JsonObject{Val: make(map[string]json.Json, len(n.keys)), Keys: make([]string, 0, len(n.keys))}
+		for _, k := range n.keys {
+			v, ok, err := r.resolve(n.fields[k])
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				obj.Set(k, v)
+			}
+		}
+		return obj, true, nil
+	case *array:
+		arr := json.JsonArray{Val: []json.Json{}}
+		for _, e := range n.elems {
+			v, ok, err := r.resolve(e)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				arr.Val = append(arr.Val, v)
+			}
+		}
+		return arr, true, nil
+	case *subst:
+		return r.lookup(n, r.root, n.path)
+	case *prior:
+		if n.n == nil {
+			return r.missing(n.s)
+		}
+		return r.lookup(n.s, n.n, n.rest)
+	case *concat:
+		return r.concat(n)
+	}
+	panic(fmt.Sprintf("hocon: unexpected node %T", n))
+}
+
+// lookup returns the value at path below n for the substitution s.
+func (r *resolver) lookup(s *subst, n node, path []string) (json.Json, bool, error) {
+	for i, k := range path {
+		o, ok := n.(*object)
+		if !ok {
+			// The value is not an object as written, e.g. a substitution of one: look the rest
+			// of the path up in its resolved value.
+			j, ok, err := r.get(s, n)
+			if err != nil || !ok {
+				return nil, false, err
+			}
+			for _, k := range path[i:] {
+				obj, ok := j.(json.JsonObject)
+				if !ok {
+					return r.missing(s)
+				}
+				if j, ok = obj.Get(k); !ok {
+					return r.missing(s)
+				}
+			}
+			return j, true, nil
+		}
+		if n, ok = o.fields[k]; !ok {
+			return r.missing(s)
+		}
+	}
+	return r.get(s, n)
+}
+
+// get resolves n, the target of the substitution s, failing with ErrCycle if n is being resolved.
+func (r *resolver) get(s *subst, n node) (json.Json, bool, error) {
+	if r.active[n] {
+		return nil, false, s.doc.errorAt(s.offset, fmt.Errorf("%w: ${%s}", ErrCycle, strings.Join(s.path, ".")))
+	}
+	j, ok, err := r.resolve(n)
+	if err != nil || ok {
+		return j, ok, err
+	}
+	return r.missing(s)
+}
+
+// missing returns the value of the substitution s of a path that is not set: that of the
+// environment variable of the same name if enabled, nothing if s is optional, and ErrUnresolved
+// otherwise.
+func (r *resolver) missing(s *subst) (json.Json, bool, error) {
+	name := strings.Join(s.path, ".")
+	if r.c.lookupEnv != nil {
+		if v, ok := r.c.lookupEnv(name); ok {
+			return json.JsonString{Val: v}, true, nil
+		}
+	}
+	if s.optional {
+		return nil, false, nil
+	}
+	return nil, false, s.doc.errorAt(s.offset, fmt.Errorf("%w: ${%s}", ErrUnresolved, name))
+}
+
+// concat joins the parts of a concatenation: strings with the whitespace between them, arrays
+// by appending them, and objects by merging them. Parts that vanish are skipped.
+func (r *resolver) concat(c *concat) (json.Json, bool, error) {
+	var parts []json.Json
+	var b strings.Builder
+	var objects, arrays, strs bool
+	for _, p := range c.parts {
+		if s, ok := p.(*scalar); ok {
+			// Scalars concatenate as written, e.g. 1.50 rather than 1.5.
+			b.WriteString(s.text)
+			strs = strs || !s.space
+			continue
+		}
+		j, ok, err := r.resolve(p)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		switch j := j.(type) {
+		case json.JsonObject:
+			objects = true
+		case json.JsonArray:
+			arrays = true
+		case json.JsonString:
+			strs = true
+			b.WriteString(j.Val)
+		default:
+			strs = true
+			b.WriteString(j.String())
+		}
+		parts = append(parts, j)
+	}
+	switch {
+	case objects && !arrays && !strs:
+		merged := json.JsonObject{Val: map[string]json.Json{}}
+		for _, p := range parts {
+			merged = merge(merged, p.(json.JsonObject))
+		}
+		return merged, true, nil
+	case arrays && !objects && !strs:
+		arr := json.JsonArray{Val: []json.Json{}}
+		for _, p := range parts {
+			arr.Val = append(arr.Val, p.(json.JsonArray).Val...)
+		}
+		return arr, true, nil
+	case !objects && !arrays:
+		if !strs {
+			return nil, false, nil
+		}
+		return json.JsonString{Val: b.String()}, true, nil
+	}
+	return nil, false, c.doc.errorAt(c.offset, ErrConcat)
+}
+
+// merge returns the fields of a with those of b, merging the objects of both at the same key.
+func merge(a, b json.JsonObject) json.JsonObject {
+	out := json.JsonObject{Val: make(map[string]json.Json, len(a.Val)+len(b.Val))}
+	for _, m := range a.Members() {
+		out.Set(m.Key, m.Value)
+	}
+	for _, m := range b.Members() {
+		if ao, ok := out.Val[m.Key].(json.JsonObject); ok {
+			if bo, ok := m.Value.(json.JsonObject); ok {
+				out.Set(m.Key, merge(ao, bo))
+				continue
+			}
+		}
+		out.Set(m.Key, m.Value)
+	}
+	return out
+}
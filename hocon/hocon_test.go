@@ -0,0 +1,160 @@
+package hocon_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/81120/tiny-parsec/hocon"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"json", `{"a": {"b": [1, 2.5, "x", true, null]}}`, `{"a":{"b":[1,2.5,"x",true,null]}}`},
+		{"root array", `[1, 2]`, `[1,2]`},
+		{"root without braces", "a = 1\nb : 2", `{"a":1,"b":2}`},
+		{"empty", "", `{}`},
+		{"comments", "# one\na = 1 // two\n// three\nb = 2 # four", `{"a":1,"b":2}`},
+		{"line breaks separate", "a = [\n  1\n  2,\n  3,\n]\nb = {\n  x = 1\n  y = 2\n}", `{"a":[1,2,3],"b":{"x":1,"y":2}}`},
+		{"unquoted strings", "a = localhost\nb = 30s\nc = /opt/app-1.0", `{"a":"localhost","b":"30s","c":"/opt/app-1.0"}`},
+		{"quoted number stays a string", `a = "10"`, `{"a":"10"}`},
+		{"escapes", `a = "tab\there \u00e9"`, `{"a":"tab\there é"}`},
+		{"triple quotes", "a = \"\"\"no \\escapes\n\"here\\\"\"\"\"\"", `{"a":"no \\escapes\n\"here\\\"\""}`},
+		{"path keys", "a.b.c = 1\na.b.d = 2\n\"x.y\".z = 3", `{"a":{"b":{"c":1,"d":2}},"x.y":{"z":3}}`},
+		{"object without separator", "a { b = 1 }", `{"a":{"b":1}}`},
+		{"objects merge", "a { x = 1, y = 2 }\na { y = 3, z = 4 }", `{"a":{"x":1,"y":3,"z":4}}`},
+		{"values replace", "a { x = 1 }\na = 2\na.y = 3", `{"a":{"y":3}}`},
+		{"key keeps position", "a = 1\nb = 2\na = 3", `{"a":3,"b":2}`},
+		{"string concatenation", `a = 10 apples "and"  oranges`, `{"a":"10 apples and  oranges"}`},
+		{"numbers concatenate as written", `a = 1.50 "x"`, `{"a":"1.50 x"}`},
+		{"array concatenation", `a = [1] [2, 3]`, `{"a":[1,2,3]}`},
+		{"object concatenation", `a = {x: 1} {y: 2}`, `{"a":{"x":1,"y":2}}`},
+		{"substitution", "a = 1\nb = ${a}\nc { d = ${a} }", `{"a":1,"b":1,"c":{"d":1}}`},
+		{"substitution of path", "a.b = [1]\nc = ${a.b}", `{"a":{"b":[1]},"c":[1]}`},
+		{"forward substitution", "a = ${b}\nb = 2", `{"a":2,"b":2}`},
+		{"substitution in string", "base = /opt\nbin = ${base}/bin\nq = \"${base}\"", `{"base":"/opt","bin":"/opt/bin","q":"${base}"}`},
+		{"substitution of object", "a = {x: 1}\nb = ${a} {y: 2}", `{"a":{"x":1},"b":{"x":1,"y":2}}`},
+		{"substitution through substitution", "a = {x: {y: 1}}\nb = ${a}\nc = ${b.x.y}", `{"a":{"x":{"y":1}},"b":{"x":{"y":1}},"c":1}`},
+		{"optional substitution", "a = ${?missing}\nb = x${?missing}y\nc = [${?missing}]", `{"b":"xy","c":[]}`},
+		{"self reference", "path = /bin\npath = ${path}\":/usr/bin\"", `{"path":"/bin:/usr/bin"}`},
+		{"self reference in object", "a { x = 1 }\na { x = ${a.x}0 }", `{"a":{"x":"10"}}`},
+		{"optional self reference", "a = ${?a} [1]", `{"a":[1]}`},
+		{"append", "a = [1]\na += 2\na += ${b}\nb = 3\nc += x", `{"a":[1,2,3],"b":3,"c":["x"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := hocon.Parse(tt.input)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.Equal(t, tt.expected, j.String())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"a = }", parser.ErrNoMatch, "hocon: line 1, col 5: unexpected '}', expected '{', '[', \"${\", string or unquoted string"},
+		{"a = \"open\nb = 1", parser.ErrNoMatch, "hocon: line 1, col 10: unexpected '\\n', expected '\"'"},
+		{"a = \"\"\"open", parser.ErrUnexpectedEOF, "hocon: line 1, col 12: unexpected end of input, expected '\"\"\"'"},
+		{"a = [1, 2", parser.ErrUnexpectedEOF, "hocon: line 1, col 10: unexpected end of input"},
+		{"a = 1\nb = ${c}", hocon.ErrUnresolved, "hocon: line 2, col 5: unresolved substitution: ${c}"},
+		{"a = ${b}\nb = ${a}", hocon.ErrCycle, "hocon: line 2, col 5: substitution cycle: ${a}"},
+		{"a { b = ${a} }", hocon.ErrCycle, "hocon: line 1, col 9: substitution cycle: ${a}"},
+		{"a = ${a}", hocon.ErrUnresolved, "hocon: line 1, col 5: unresolved substitution: ${a}"},
+		{"a = {x: 1} foo", hocon.ErrConcat, "hocon: line 1, col 5: cannot concatenate values of different kinds"},
+		{"a = 1\na += 2", hocon.ErrConcat, "hocon: line 2, col 1: cannot concatenate values of different kinds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := hocon.Parse(tt.input)
+			var perr *hocon.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults.conf": {Data: []byte("port = 80\nhost = localhost\n")},
+		"nested.conf":   {Data: []byte("include \"defaults.conf\"\nnested = yes\n")},
+		"loop.conf":     {Data: []byte("include \"loop.conf\"\n")},
+		"bad.conf":      {Data: []byte("x = [\n")},
+		"ref.conf":      {Data: []byte("url = ${host}\":\"${port}\n")},
+	}
+
+	t.Run("merged at the include", func(t *testing.T) {
+		j, err := hocon.Parse("port = 8080\ninclude \"defaults.conf\"\nhost = example.com", hocon.IncludeFS(fsys))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"port":80,"host":"example.com"}`, j.String())
+	})
+
+	t.Run("within an object", func(t *testing.T) {
+		j, err := hocon.Parse("server { include file(\"nested.conf\") }", hocon.IncludeFS(fsys))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"server":{"port":80,"host":"localhost","nested":"yes"}}`, j.String())
+	})
+
+	t.Run("substitutions resolve against the root", func(t *testing.T) {
+		j, err := hocon.Parse("host = h\nport = 1\ninclude \"ref.conf\"", hocon.IncludeFS(fsys))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"host":"h","port":1,"url":"h:1"}`, j.String())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		j, err := hocon.Parse("include \"none.conf\"\na = 1", hocon.IncludeFS(fsys))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, j.String())
+
+		_, err = hocon.Parse("a = 1\ninclude required(\"none.conf\")", hocon.IncludeFS(fsys))
+		assert.ErrorIs(t, err, hocon.ErrIncludeNotFound)
+		assert.EqualError(t, err, "hocon: line 2, col 1: included resource not found: none.conf")
+
+		j, err = hocon.Parse("include \"defaults.conf\"\na = 1")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, j.String())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := hocon.Parse("include \"loop.conf\"", hocon.IncludeFS(fsys))
+		assert.ErrorIs(t, err, hocon.ErrIncludeCycle)
+		assert.EqualError(t, err, "hocon: loop.conf: line 1, col 1: include cycle: loop.conf")
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := hocon.Parse("include \"bad.conf\"", hocon.IncludeFS(fsys))
+		var perr *hocon.ParseError
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, "bad.conf", perr.Include)
+		assert.Equal(t, 2, perr.Line)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		boom := errors.New("boom")
+		_, err := hocon.Parse("include \"x\"", hocon.IncludeWith(func(string) (string, error) { return "", boom }))
+		assert.ErrorIs(t, err, boom)
+	})
+}
+
+func TestExpandWith(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "a.b": "dotted"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	j, err := hocon.Parse("home = ${HOME}\nd = ${a.b}\nuser = ${?USER}\nlocal = ${x}\nx = 1", hocon.ExpandWith(lookup))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"home":"/home/me","d":"dotted","local":1,"x":1}`, j.String())
+}
@@ -0,0 +1,59 @@
+// Package hocon provides options for parsing HOCON documents.
+package hocon
+
+import (
+	"io/fs"
+	"os"
+)
+
+// Option configures Parse.
+type Option func(*config)
+
+// config holds the settings of Parse.
+type config struct {
+	// include returns the text of an included resource, or an error wrapping fs.ErrNotExist if
+	// it does not exist. Includes are ignored, unless required, if it is nil.
+	include func(name string) (string, error)
+	// lookupEnv looks up the environment variables substitutions fall back to, if not nil.
+	lookupEnv func(name string) (string, bool)
+}
+
+// newConfig returns the configuration set by opts.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// IncludeFS resolves include directives to files of fsys. The names in include directives are
+// slash-separated paths from the root of fsys.
+func IncludeFS(fsys fs.FS) Option {
+	return IncludeWith(func(name string) (string, error) {
+		b, err := fs.ReadFile(fsys, name)
+		return string(b), err
+	})
+}
+
+// IncludeWith resolves include directives with read, which returns the text of the named
+// resource, or an error wrapping fs.ErrNotExist if there is none.
+func IncludeWith(read func(name string) (string, error)) Option {
+	return func(c *config) {
+		c.include = read
+	}
+}
+
+// ExpandEnv resolves substitutions of paths missing from the document to the environment
+// variable of the same name, e.g. ${HOME}, as the HOCON specification requires.
+func ExpandEnv() Option {
+	return ExpandWith(os.LookupEnv)
+}
+
+// ExpandWith resolves substitutions of missing paths like ExpandEnv, looking the variables up
+// with lookup instead of in the environment, e.g. to supply fixed values in tests.
+func ExpandWith(lookup func(name string) (string, bool)) Option {
+	return func(c *config) {
+		c.lookupEnv = lookup
+	}
+}
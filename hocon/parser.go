@@ -0,0 +1,361 @@
+// Package hocon provides the grammar of HOCON documents, built with the tiny-parsec combinators.
+package hocon
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// document is the source of a HOCON document: the one passed to Parse or an included one.
+type document struct {
+	// name is the name of an included document, or "" for the document passed to Parse.
+	name string
+	text string
+}
+
+// errorAt returns a *ParseError for err at the given byte offset of the document.
+func (d *document) errorAt(offset int, err error) *ParseError {
+	e := errorAt(d.text, offset, err)
+	e.Include = d.name
+	return e
+}
+
+// node is a value as written, before includes and substitutions are resolved: an *objectLit, an
+// *arrayLit, a *scalar, a *subst or a *concat.
+type node any
+
+// objectLit is an object as written, whose fields may repeat keys and include other documents.
+type objectLit struct {
+	fields []field
+}
+
+// field is a field of an object or an include directive.
+type field struct {
+	doc    *document
+	offset int
+	// path is the key of the field split at its dots, e.g. "a", "b" for a.b = 1.
+	path []string
+	// appends reports whether the field was written with "+=".
+	appends bool
+	value   node
+	// include is the included resource of an include directive, which has no path and value.
+	include *include
+}
+
+// include is the argument of an include directive.
+type include struct {
+	name     string
+	required bool
+}
+
+// arrayLit is an array as written.
+type arrayLit struct {
+	elems []node
+}
+
+// scalar is a string, number, boolean or null, or the whitespace between the parts of a
+// concatenation.
+type scalar struct {
+	val json.Json
+	// text is the value as written, which a concatenation joins, e.g. "1.50" for the number 1.5.
+	text string
+	// space reports whether the scalar is whitespace in a concatenation, which is only kept
+	// between strings.
+	space bool
+}
+
+// subst is a substitution ${path}, or ${?path} if optional.
+type subst struct {
+	doc      *document
+	offset   int
+	path     []string
+	optional bool
+}
+
+// concat is a concatenation of values written next to each other, e.g. ${a} "b" [c].
+type concat struct {
+	doc    *document
+	offset int
+	parts  []node
+}
+
+// isForbidden reports whether r may not appear in an unquoted string.
+func isForbidden(r rune) bool {
+	return strings.ContainsRune("$\"{}[]:=,+#`^?!@*&\\", r) || isBlank(r) || r == '\n'
+}
+
+// isBlank reports whether r is whitespace within a line.
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\f' || r == '\v'
+}
+
+// unquoted parses an unquoted string, in which "//" starts a comment. In a key, where inKey is
+// set, it also stops at a dot.
+func unquoted(inKey bool) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		i := 0
+		for i < len(s) && !isForbidden(rune(s[i])) && !(inKey && s[i] == '.') && !strings.HasPrefix(s[i:], "//") {
+			i++
+		}
+		if i == 0 {
+			st.Fail("unquoted string")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(i)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:i], next))
+	})
+}
+
+// quoted parses a JSON string literal, decoding its escapes, or a triple-quoted string, which
+// has none and may span lines.
+func quoted() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if !strings.HasPrefix(s, `"`) {
+			st.Fail("string")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		var val string
+		n := 0
+		if strings.HasPrefix(s, `"""`) {
+			end := strings.Index(s[3:], `"""`)
+			if end < 0 {
+				st.Truncated(`'"""'`)
+				return parser.Nothing[parser.Tuple[string, parser.State]]()
+			}
+			n = 3 + end + 3
+			// Quotes before the closing ones belong to the string.
+			for n < len(s) && s[n] == '"' {
+				n++
+			}
+			val = s[3 : n-3]
+		} else {
+			for n = 1; n < len(s) && s[n] != '"' && s[n] != '\n'; n++ {
+				if s[n] == '\\' {
+					n++
+				}
+			}
+			if n >= len(s) {
+				st.Truncated(`'"'`)
+				return parser.Nothing[parser.Tuple[string, parser.State]]()
+			}
+			if s[n] == '\n' {
+				if bad, ok := st.Advance(n); ok {
+					bad.Fail(`'"'`)
+				}
+				return parser.Nothing[parser.Tuple[string, parser.State]]()
+			}
+			n++
+			j, err := json.ParseJSON(s[:n], json.StrictStrings())
+			if err != nil {
+				st.Fail("string")
+				return parser.Nothing[parser.Tuple[string, parser.State]]()
+			}
+			val = j.(json.JsonString).Val
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(val, next))
+	})
+}
+
+// comment parses a comment, starting with '#' or "//", up to the end of the line. It records no
+// failure, so that comments are not listed among the expected input of every error.
+func comment() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if !strings.HasPrefix(s, "#") && !strings.HasPrefix(s, "//") {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		n := strings.IndexByte(s, '\n')
+		if n < 0 {
+			n = len(s)
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:n], next))
+	})
+}
+
+// blanks parses whitespace and comments within a line.
+func blanks() parser.Parser[[]string] {
+	return parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(isBlank)), parser.Text),
+		comment(),
+	))
+}
+
+// spaces parses whitespace, comments and line breaks.
+func spaces() parser.Parser[[]string] {
+	return parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return isBlank(r) || r == '\n' })), parser.Text),
+		comment(),
+	))
+}
+
+// ahead succeeds without consuming input if the input is exhausted or starts with one of the
+// bytes of cs. It records no failure.
+func ahead(cs string) parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		if s := st.Input(); s != "" && !strings.ContainsRune(cs, rune(s[0])) {
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+}
+
+// items parses zero or more p, each ended by a comma or a line break, or followed by the byte
+// close that ends the list, which it does not consume.
+func items[T any](p parser.Parser[T], close string) parser.Parser[[]T] {
+	end := parser.OmitLeft(blanks(), parser.OrElse(
+		parser.Fmap(parser.OrElse(parser.Char(','), parser.Char('\n')), func(rune) struct{} { return struct{}{} }),
+		ahead(close),
+	))
+	return parser.OmitLeft(spaces(), parser.ZeroOrMore(parser.OmitRight(p, parser.OmitLeft(end, spaces()))))
+}
+
+// path parses a path expression: keys separated by dots, e.g. a.b."c.d".
+func path() parser.Parser[[]string] {
+	key := parser.Fmap(parser.OneOrMore(parser.OrElse(
+		quoted(),
+		unquoted(true),
+	)), func(ss []string) string { return strings.Join(ss, "") })
+	return parser.Bind(key, func(first string) parser.Parser[[]string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Char('.'), key)), func(rest []string) []string {
+			return append([]string{first}, rest...)
+		})
+	})
+}
+
+// substitution parses ${path} or ${?path}.
+func (d *document) substitution() parser.Parser[node] {
+	return parser.Bind(parser.OmitLeft(parser.Str("${"), parser.Pos()), func(offset int) parser.Parser[node] {
+		optional := parser.ZeroOrOne(parser.Char('?'))
+		return parser.Bind(optional, func(q parser.Maybe[rune]) parser.Parser[node] {
+			return parser.Fmap(parser.OmitRight(path(), parser.Char('}')), func(p []string) node {
+				return &subst{doc: d, offset: offset - 2, path: p, optional: q.IsJust()}
+			})
+		})
+	})
+}
+
+// value parses a value: an object, an array, a string, a number, a boolean, null or a
+// substitution, or several of them written next to each other on a line, which concatenate.
+func (d *document) value() parser.Parser[node] {
+	str := parser.Fmap(quoted(), func(s string) node {
+		return &scalar{val: json.JsonString{Val: s}, text: s}
+	})
+	word := parser.Fmap(unquoted(false), typed)
+	part := parser.OrElse(
+		parser.Lazy(d.object),
+		parser.Lazy(d.array),
+		d.substitution(),
+		str,
+		word,
+	)
+	space := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r == ' ' || r == '\t' })), func(rs []rune) node {
+		return &scalar{val: json.JsonString{Val: parser.Text(rs)}, text: parser.Text(rs), space: true}
+	})
+	return parser.Bind(parser.Pos(), func(offset int) parser.Parser[node] {
+		return parser.Bind(part, func(first node) parser.Parser[node] {
+			next := parser.Bind(parser.ZeroOrOne(space), func(sp parser.Maybe[node]) parser.Parser[[]node] {
+				return parser.Fmap(part, func(n node) []node {
+					if sp.IsJust() {
+						return []node{sp.Get(), n}
+					}
+					return []node{n}
+				})
+			})
+			return parser.Fmap(parser.ZeroOrMore(next), func(rest [][]node) node {
+				if len(rest) == 0 {
+					return first
+				}
+				c := &concat{doc: d, offset: offset, parts: []node{first}}
+				for _, ns := range rest {
+					c.parts = append(c.parts, ns...)
+				}
+				return c
+			})
+		})
+	})
+}
+
+// typed returns the number, boolean or null written as the unquoted string s, or s as a string.
+func typed(s string) node {
+	if j, err := json.ParseJSON(s); err == nil {
+		switch j.(type) {
+		case json.JsonInt, json.JsonFloat, json.JsonBool, json.JsonNull:
+			return &scalar{val: j, text: s}
+		}
+	}
+	return &scalar{val: json.JsonString{Val: s}, text: s}
+}
+
+// array parses an array, whose elements are separated by commas or line breaks.
+func (d *document) array() parser.Parser[node] {
+	return parser.Fmap(
+		parser.Between(parser.Char('['), items(d.value(), "]"), parser.Char(']')),
+		func(elems []node) node { return &arrayLit{elems: elems} },
+	)
+}
+
+// object parses an object between braces.
+func (d *document) object() parser.Parser[node] {
+	return parser.Fmap(
+		parser.Between(parser.Char('{'), d.fields("}"), parser.Char('}')),
+		func(fs []field) node { return &objectLit{fields: fs} },
+	)
+}
+
+// fields parses the fields of an object, separated by commas or line breaks, up to close.
+func (d *document) fields(close string) parser.Parser[[]field] {
+	resource := parser.OrElse(
+		quoted(),
+		parser.Between(parser.Str("file("), quoted(), parser.Char(')')),
+	)
+	directive := parser.OmitLeft(parser.Str("include"), parser.OmitLeft(parser.OneOrMore(parser.Satisfy(isBlank)), parser.OrElse(
+		parser.Fmap(parser.Between(parser.Str("required("), resource, parser.Char(')')), func(name string) field {
+			return field{include: &include{name: name, required: true}}
+		}),
+		parser.Fmap(resource, func(name string) field { return field{include: &include{name: name}} }),
+	)))
+	sep := parser.OmitLeft(blanks(), parser.OrElse(
+		parser.Fmap(parser.Str("+="), func(string) bool { return true }),
+		parser.Fmap(parser.OrElse(parser.Char(':'), parser.Char('=')), func(rune) bool { return false }),
+	))
+	assignment := parser.Bind(path(), func(p []string) parser.Parser[field] {
+		withSep := parser.Bind(sep, func(appends bool) parser.Parser[field] {
+			return parser.Fmap(parser.OmitLeft(blanks(), d.value()), func(v node) field {
+				return field{path: p, appends: appends, value: v}
+			})
+		})
+		// The separator may be omitted before an object.
+		bare := parser.Fmap(parser.OmitLeft(blanks(), parser.Lazy(d.object)), func(v node) field {
+			return field{path: p, value: v}
+		})
+		return parser.OrElse(withSep, bare)
+	})
+	item := parser.Bind(parser.Pos(), func(offset int) parser.Parser[field] {
+		return parser.Fmap(parser.OrElse(directive, assignment), func(f field) field {
+			f.doc, f.offset = d, offset
+			return f
+		})
+	})
+	return items(item, close)
+}
+
+// root parses a document: an object with or without braces, or an array.
+func (d *document) root() parser.Parser[node] {
+	bare := parser.Fmap(d.fields(""), func(fs []field) node { return &objectLit{fields: fs} })
+	return parser.Between(spaces(), parser.OrElse(d.array(), d.object(), bare), spaces())
+}
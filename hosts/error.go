@@ -0,0 +1,8 @@
+// Package hosts provides error reporting for malformed lines of hosts files.
+package hosts
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a line of a hosts file could not be parsed. Its Offset and Line are
+// those of the error in the file, and Expected lists what the line should contain, e.g. "host name".
+type ParseError = parser.Error
@@ -0,0 +1,203 @@
+// Package hosts parses and edits hosts files, such as /etc/hosts, which map host names to
+// IP addresses:
+//
+//	# loopback
+//	127.0.0.1   localhost
+//	::1         localhost ip6-localhost ip6-loopback
+//	192.0.2.10  web.example.com web   # staging
+//
+// Each entry is an address followed by the canonical name of the host and its aliases,
+// separated by blanks. A '#' starts a comment running to the end of the line. Parse returns
+// a File that keeps the text of every line, so that Set and Remove can edit a file
+// maintained by hand and String can write it back with its comments and layout intact.
+package hosts
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Entry is a line of a hosts file mapping an address to host names.
+type Entry struct {
+	IP netip.Addr
+	// Names lists the canonical name of the host followed by its aliases.
+	Names []string
+	// Comment is the text of the comment at the end of the line, without the '#' and
+	// surrounding blanks, or empty if there is none.
+	Comment string
+}
+
+// Canonical returns the canonical name of the host, the first of its names.
+func (e *Entry) Canonical() string {
+	return e.Names[0]
+}
+
+// Aliases returns the other names of the host.
+func (e *Entry) Aliases() []string {
+	return e.Names[1:]
+}
+
+// String returns the entry as a line of a hosts file, without a line break, e.g.
+// "192.0.2.10\tweb.example.com web # staging".
+func (e *Entry) String() string {
+	s := e.IP.String() + "\t" + strings.Join(e.Names, " ")
+	if e.Comment != "" {
+		s += " # " + e.Comment
+	}
+	return s
+}
+
+// File is a hosts file that keeps the text of every line. Lines that are not edited are
+// written back exactly as they were read.
+type File struct {
+	lines []fileLine
+	// newline is the line break of new lines: "\r\n" if the source uses it, "\n" otherwise.
+	newline string
+}
+
+// fileLine is a line of a file.
+type fileLine struct {
+	// text is the source text, including the final line break.
+	text string
+	// entry is the entry on the line, or nil if the line is a comment or blank.
+	entry *Entry
+}
+
+// Parse parses the hosts file s. Malformed lines are reported together, each as a
+// *ParseError, joined with errors.Join.
+func Parse(s string) (*File, error) {
+	f := &File{newline: "\n"}
+	if strings.Contains(s, "\r\n") {
+		f.newline = "\r\n"
+	}
+	f, errs := parser.ParseLines(s, parser.LineOptions{Source: "hosts"}, line(), f, func(f *File, l parser.Line, e *Entry) (*File, error) {
+		text := s[l.Offset:]
+		if text == "" {
+			// The empty line after the final line break
+			return f, nil
+		}
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = text[:i+1]
+		}
+		f.lines = append(f.lines, fileLine{text: text, entry: e})
+		return f, nil
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return f, nil
+}
+
+// String returns the text of the file with the edits made to it.
+func (f *File) String() string {
+	var b strings.Builder
+	for _, l := range f.lines {
+		b.WriteString(l.text)
+	}
+	return b.String()
+}
+
+// Entries returns copies of the entries of the file in order.
+func (f *File) Entries() []Entry {
+	var es []Entry
+	for _, l := range f.lines {
+		if l.entry != nil {
+			e := *l.entry
+			e.Names = slices.Clone(e.Names)
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// Lookup returns the addresses of the host name, in the order of their entries. Host names
+// are compared without regard to case.
+func (f *File) Lookup(name string) []netip.Addr {
+	var ips []netip.Addr
+	for _, l := range f.lines {
+		if l.entry != nil && slices.ContainsFunc(l.entry.Names, matcher(name)) {
+			ips = append(ips, l.entry.IP)
+		}
+	}
+	return ips
+}
+
+// Names returns the host names of the address ip, in the order of their entries.
+func (f *File) Names(ip netip.Addr) []string {
+	var names []string
+	for _, l := range f.lines {
+		if l.entry != nil && l.entry.IP == ip {
+			names = append(names, l.entry.Names...)
+		}
+	}
+	return names
+}
+
+// Set maps the host name to ip only. The name is removed from the entries of other
+// addresses, and added to the first entry of ip, or to a new entry at the end of the file if
+// there is none.
+func (f *File) Set(name string, ip netip.Addr) {
+	f.remove(name, func(e *Entry) bool { return e.IP != ip })
+	for _, l := range f.lines {
+		if l.entry != nil && l.entry.IP == ip && slices.ContainsFunc(l.entry.Names, matcher(name)) {
+			return
+		}
+	}
+	for i, l := range f.lines {
+		if l.entry != nil && l.entry.IP == ip {
+			l.entry.Names = append(l.entry.Names, name)
+			f.rewrite(i)
+			return
+		}
+	}
+	if n := len(f.lines); n > 0 && !strings.HasSuffix(f.lines[n-1].text, "\n") {
+		f.lines[n-1].text += f.newline
+	}
+	e := &Entry{IP: ip, Names: []string{name}}
+	f.lines = append(f.lines, fileLine{text: e.String() + f.newline, entry: e})
+}
+
+// Remove removes the host name from every entry, and reports whether there was one.
+// Entries left without names are removed, with their comment.
+func (f *File) Remove(name string) bool {
+	return f.remove(name, func(*Entry) bool { return true })
+}
+
+// remove removes the host name from the entries accepted by ok, and reports whether there
+// was one.
+func (f *File) remove(name string, ok func(*Entry) bool) bool {
+	found := false
+	for i := 0; i < len(f.lines); i++ {
+		e := f.lines[i].entry
+		if e == nil || !ok(e) || !slices.ContainsFunc(e.Names, matcher(name)) {
+			continue
+		}
+		found = true
+		e.Names = slices.DeleteFunc(e.Names, matcher(name))
+		if len(e.Names) == 0 {
+			f.lines = slices.Delete(f.lines, i, i+1)
+			i--
+			continue
+		}
+		f.rewrite(i)
+	}
+	return found
+}
+
+// rewrite replaces the text of the i-th line by its edited entry, keeping its line break.
+func (f *File) rewrite(i int) {
+	l := &f.lines[i]
+	brk := l.text[len(strings.TrimRight(l.text, "\r\n")):]
+	l.text = l.entry.String() + brk
+}
+
+// matcher returns a function reporting whether a host name is name, without regard to case.
+func matcher(name string) func(string) bool {
+	return func(s string) bool {
+		return strings.EqualFold(s, name)
+	}
+}
@@ -0,0 +1,119 @@
+package hosts_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/81120/tiny-parsec/hosts"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `# loopback
+127.0.0.1   localhost
+::1         localhost ip6-localhost ip6-loopback
+
+192.0.2.10  web.example.com web   # staging
+fe80::1%eth0	router
+`
+
+func TestParse(t *testing.T) {
+	f, err := hosts.Parse(sample)
+	assert.NoError(t, err)
+	assert.Equal(t, []hosts.Entry{
+		{IP: netip.MustParseAddr("127.0.0.1"), Names: []string{"localhost"}},
+		{IP: netip.MustParseAddr("::1"), Names: []string{"localhost", "ip6-localhost", "ip6-loopback"}},
+		{IP: netip.MustParseAddr("192.0.2.10"), Names: []string{"web.example.com", "web"}, Comment: "staging"},
+		{IP: netip.MustParseAddr("fe80::1%eth0"), Names: []string{"router"}},
+	}, f.Entries())
+	assert.Equal(t, sample, f.String())
+
+	e := f.Entries()[1]
+	assert.Equal(t, "localhost", e.Canonical())
+	assert.Equal(t, []string{"ip6-localhost", "ip6-loopback"}, e.Aliases())
+	assert.Equal(t, "::1\tlocalhost ip6-localhost ip6-loopback", e.String())
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")}, f.Lookup("LocalHost"))
+	assert.Empty(t, f.Lookup("missing"))
+	assert.Equal(t, []string{"web.example.com", "web"}, f.Names(netip.MustParseAddr("192.0.2.10")))
+
+	tests := []struct {
+		input    string
+		expected []hosts.Entry
+	}{
+		{"", nil},
+		{"\n  \t\n# only comments\n", nil},
+		{"10.0.0.1 a#not a comment\r\n", []hosts.Entry{{IP: netip.MustParseAddr("10.0.0.1"), Names: []string{"a"}, Comment: "not a comment"}}},
+		{"  10.0.0.1\tb_c.local  ", []hosts.Entry{{IP: netip.MustParseAddr("10.0.0.1"), Names: []string{"b_c.local"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := hosts.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, f.Entries())
+			assert.Equal(t, tt.input, f.String())
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	f, err := hosts.Parse(sample)
+	assert.NoError(t, err)
+
+	f.Set("api", netip.MustParseAddr("192.0.2.10"))
+	f.Set("web", netip.MustParseAddr("192.0.2.10"))
+	f.Set("db", netip.MustParseAddr("192.0.2.20"))
+	assert.True(t, f.Remove("ip6-localhost"))
+	assert.True(t, f.Remove("router"))
+	assert.False(t, f.Remove("router"))
+	assert.Equal(t, `# loopback
+127.0.0.1   localhost
+::1	localhost ip6-loopback
+
+192.0.2.10	web.example.com web api # staging
+192.0.2.20	db
+`, f.String())
+
+	f.Set("WEB", netip.MustParseAddr("192.0.2.20"))
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.20")}, f.Lookup("web"))
+	assert.Equal(t, []string{"web.example.com", "api"}, f.Names(netip.MustParseAddr("192.0.2.10")))
+
+	f, err = hosts.Parse("127.0.0.1 localhost\r\n10.0.0.1 a")
+	assert.NoError(t, err)
+	f.Set("b", netip.MustParseAddr("10.0.0.2"))
+	assert.Equal(t, "127.0.0.1 localhost\r\n10.0.0.1 a\r\n10.0.0.2\tb\r\n", f.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"localhost 127.0.0.1", parser.ErrNoMatch, "hosts: line 1, col 1: unexpected 'l', expected IP address, '#' or end of input"},
+		{"# ok\n256.0.0.1 a", parser.ErrNoMatch, "hosts: line 2, col 1: unexpected '2', expected IP address, '#' or end of input"},
+		{"127.0.0.1\n", parser.ErrUnexpectedEOF, "hosts: line 1, col 10: unexpected end of input, expected host name"},
+		{"127.0.0.1  # no names", parser.ErrNoMatch, "hosts: line 1, col 12: unexpected '#', expected host name"},
+		{"127.0.0.1 bad/name", parser.ErrNoMatch, "hosts: line 1, col 14: unexpected '/', expected host name, '#' or end of input"},
+		{"127.0.0.1 ok\n::1 ü", parser.ErrNoMatch, "hosts: line 2, col 5: unexpected 'ü', expected host name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := hosts.Parse(tt.input)
+			var perr *hosts.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+
+	_, err := hosts.Parse("x\n127.0.0.1 a\ny z\n")
+	assert.EqualError(t, err, "hosts: line 1, col 1: unexpected 'x', expected IP address, '#' or end of input\nhosts: line 3, col 1: unexpected 'y', expected IP address, '#' or end of input")
+	var perr *hosts.ParseError
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, 0, perr.Offset)
+	_, err = hosts.Parse("127.0.0.1 a\n::1 b c/d")
+	assert.ErrorAs(t, err, &perr)
+	assert.Equal(t, 2, perr.Line)
+	assert.Equal(t, 19, perr.Offset)
+}
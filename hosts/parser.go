@@ -0,0 +1,74 @@
+// Package hosts provides the grammar of the lines of hosts files, built with the tiny-parsec
+// combinators.
+package hosts
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isHost reports whether r may appear in a host name. Underscores are accepted, as
+// resolvers do, although RFC 952 does not allow them.
+func isHost(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_'
+}
+
+// blanks parses optional blanks.
+func blanks() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isBlank))
+}
+
+// comment parses a comment, returning its text after the '#' with surrounding blanks
+// removed.
+func comment() parser.Parser[string] {
+	return parser.OmitLeft(parser.Char('#'), parser.Fmap(parser.ZeroOrMore(parser.Satisfy(func(rune) bool { return true })), func(rs []rune) string {
+		return strings.TrimSpace(parser.Text(rs))
+	}))
+}
+
+// ip parses an IPv4 or IPv6 address, with an optional zone for IPv6, e.g. fe80::1%eth0.
+func ip() parser.Parser[netip.Addr] {
+	isWord := func(r rune) bool { return !isBlank(r) && r != '#' }
+	word := parser.Bind(parser.SatisfyMsg(isWord, "IP address"), func(r rune) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isWord)), func(rs []rune) string {
+			return parser.Text(append([]rune{r}, rs...))
+		})
+	})
+	valid := parser.SatisfyWithMsg(word, func(s string) bool {
+		_, err := netip.ParseAddr(s)
+		return err == nil
+	}, "IP address")
+	return parser.Fmap(valid, func(s string) netip.Addr {
+		return netip.MustParseAddr(s)
+	})
+}
+
+// entry parses an address followed by its host names and an optional comment. An address
+// without a host name is reported as a missing "host name".
+func entry() parser.Parser[*Entry] {
+	name := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isHost, "host name")), parser.Text)
+	aliases := parser.ZeroOrMore(parser.OmitLeft(parser.OneOrMore(parser.Satisfy(isBlank)), name))
+	names := parser.Bind(parser.OmitLeft(blanks(), name), func(canonical string) parser.Parser[[]string] {
+		return parser.Fmap(aliases, func(aliases []string) []string { return append([]string{canonical}, aliases...) })
+	})
+	return parser.Bind(ip(), func(addr netip.Addr) parser.Parser[*Entry] {
+		return parser.Bind(parser.OmitRight(names, blanks()), func(names []string) parser.Parser[*Entry] {
+			return parser.Fmap(parser.ZeroOrOne(comment()), func(c parser.Maybe[string]) *Entry {
+				return &Entry{IP: addr, Names: names, Comment: c.Get()}
+			})
+		})
+	})
+}
+
+// line parses a line without its line break: an entry, a comment or a blank line. It
+// returns nil for comments and blank lines.
+func line() parser.Parser[*Entry] {
+	other := parser.Fmap(parser.ZeroOrOne(comment()), func(parser.Maybe[string]) *Entry { return nil })
+	return parser.OmitLeft(blanks(), parser.OrElse(entry(), other))
+}
@@ -0,0 +1,285 @@
+// Package httpsyntax provides parsing of the Accept, Accept-Language and Accept-Encoding
+// header fields used for content negotiation.
+package httpsyntax
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Param is a parameter of a header field element, e.g. "charset=utf-8".
+type Param struct {
+	// Name is the parameter name, lowercased since names are case-insensitive.
+	Name string
+	// Value is the parameter value, unquoted if it was a quoted string.
+	Value string
+}
+
+// String returns the parameter as "name=value", quoting the value if it is not a token.
+func (p Param) String() string {
+	return p.Name + "=" + quote(p.Value)
+}
+
+// MediaRange is an element of an Accept header, e.g. "text/html;level=1;q=0.5".
+type MediaRange struct {
+	// Type is the lowercased top-level type, or "*" for any type.
+	Type string
+	// Subtype is the lowercased subtype, or "*" for any subtype of Type.
+	Subtype string
+	// Params lists the media type parameters, in order.
+	Params []Param
+	// Quality is the weight between 0 and 1, which is 1 if the range has none. A range
+	// with quality 0 marks the types it matches as not acceptable.
+	Quality float64
+	// Extensions lists the parameters following the weight, which RFC 7231 allowed as accept
+	// extensions.
+	Extensions []Param
+}
+
+// String returns the range in the syntax of an Accept header, omitting a quality of 1.
+func (m MediaRange) String() string {
+	s := m.Type + "/" + m.Subtype + params(m.Params)
+	if m.Quality != 1 || len(m.Extensions) > 0 {
+		s += ";q=" + strconv.FormatFloat(m.Quality, 'f', -1, 64) + params(m.Extensions)
+	}
+	return s
+}
+
+// Match reports whether the media type, e.g. "text/html; charset=utf-8", falls in the range:
+// its type and subtype match, ignoring case, and it has every parameter of the range.
+func (m MediaRange) Match(mediaType string) bool {
+	t, err := parser.Run(parser.OmitLeft(ows(), mediaRange()), mediaType)
+	if err != nil || t.Type == "*" || t.Subtype == "*" {
+		return false
+	}
+	if m.Type != "*" && m.Type != t.Type || m.Subtype != "*" && m.Subtype != t.Subtype {
+		return false
+	}
+	for _, p := range m.Params {
+		if !slices.ContainsFunc(t.Params, func(q Param) bool {
+			return q.Name == p.Name && strings.EqualFold(q.Value, p.Value)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity ranks the range by how specific it is, so that "text/html;level=1" ranks
+// above "text/html", which ranks above "text/*", which ranks above "*/*".
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	default:
+		return 2 + len(m.Params)
+	}
+}
+
+// LanguageRange is an element of an Accept-Language header, e.g. "en-GB;q=0.8".
+type LanguageRange struct {
+	// Tag is the language range as written, e.g. "en-GB", or "*" for any language.
+	Tag string
+	// Quality is the weight between 0 and 1, which is 1 if the range has none.
+	Quality float64
+}
+
+// String returns the range in the syntax of an Accept-Language header.
+func (l LanguageRange) String() string {
+	return weighted(l.Tag, l.Quality)
+}
+
+// Match reports whether the language tag, e.g. "en-GB", falls in the range, i.e. the range
+// equals the tag or a prefix of it ending at a hyphen, ignoring case, as in RFC 4647 basic
+// filtering.
+func (l LanguageRange) Match(tag string) bool {
+	if l.Tag == "*" {
+		return true
+	}
+	n := len(l.Tag)
+	return len(tag) >= n && strings.EqualFold(tag[:n], l.Tag) && (len(tag) == n || tag[n] == '-')
+}
+
+// Coding is an element of an Accept-Encoding header, e.g. "gzip;q=0.5".
+type Coding struct {
+	// Name is the lowercased content coding, "identity" for none, or "*" for any coding.
+	Name string
+	// Quality is the weight between 0 and 1, which is 1 if the coding has none.
+	Quality float64
+}
+
+// String returns the coding in the syntax of an Accept-Encoding header.
+func (c Coding) String() string {
+	return weighted(c.Name, c.Quality)
+}
+
+// Match reports whether the content coding, e.g. "gzip", is the coding, ignoring case.
+func (c Coding) Match(name string) bool {
+	return c.Name == "*" || strings.EqualFold(c.Name, name)
+}
+
+func params(ps []Param) string {
+	var b strings.Builder
+	for _, p := range ps {
+		b.WriteString(";" + p.String())
+	}
+	return b.String()
+}
+
+func weighted(s string, q float64) string {
+	if q == 1 {
+		return s
+	}
+	return s + ";q=" + strconv.FormatFloat(q, 'f', -1, 64)
+}
+
+// quality converts a qvalue matched by the grammar to a number.
+func quality(s string) float64 {
+	q, _ := strconv.ParseFloat(s, 64)
+	return q
+}
+
+// mediaRange parses a media range without its weight, e.g. "text/*" or "text/html;level=1".
+func mediaRange() parser.Parser[MediaRange] {
+	lower := parser.Fmap(token(), strings.ToLower)
+	types := parser.Bind(parser.OmitRight(lower, parser.Char('/')), func(typ string) parser.Parser[MediaRange] {
+		subtype := lower
+		if typ == "*" {
+			subtype = parser.Fmap(parser.Char('*'), func(rune) string { return "*" })
+		}
+		return parser.Fmap(subtype, func(subtype string) MediaRange {
+			return MediaRange{Type: typ, Subtype: subtype, Quality: 1}
+		})
+	})
+	isQ := func(name string) bool { return name == "q" }
+	ps := parser.ZeroOrMore(parser.OmitLeft(semicolon(), param(isQ)))
+	return parser.Bind(types, func(m MediaRange) parser.Parser[MediaRange] {
+		return parser.Fmap(ps, func(ps []Param) MediaRange {
+			m.Params = ps
+			return m
+		})
+	})
+}
+
+// accept parses the elements of an Accept header.
+func accept() parser.Parser[[]MediaRange] {
+	none := func(string) bool { return false }
+	ext := parser.ZeroOrMore(parser.OmitLeft(semicolon(), param(none)))
+	w := parser.Bind(parser.OmitLeft(semicolon(), weight()), func(q string) parser.Parser[MediaRange] {
+		return parser.Fmap(ext, func(ext []Param) MediaRange {
+			return MediaRange{Quality: quality(q), Extensions: ext}
+		})
+	})
+	return list(parser.Bind(mediaRange(), func(m MediaRange) parser.Parser[MediaRange] {
+		return parser.Fmap(parser.ZeroOrOne(w), func(w parser.Maybe[MediaRange]) MediaRange {
+			if w.IsJust() {
+				m.Quality, m.Extensions = w.Get().Quality, w.Get().Extensions
+			}
+			return m
+		})
+	}))
+}
+
+// languageRange parses a language range such as "en-GB" or "*".
+func languageRange() parser.Parser[string] {
+	subtag := func(f func(rune) bool, expected string) parser.Parser[string] {
+		return parser.SatisfyWithMsg(parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(f, expected)), parser.Text), func(s string) bool {
+			return len(s) <= 8
+		}, "subtag of at most 8 characters")
+	}
+	alphanum := func(r rune) bool { return isAlpha(r) || isDigit(r) }
+	tag := parser.Bind(subtag(isAlpha, "letter"), func(primary string) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Char('-'), subtag(alphanum, "letter or digit"))), func(ss []string) string {
+			return strings.Join(append([]string{primary}, ss...), "-")
+		})
+	})
+	return parser.OrElse(parser.Fmap(parser.Char('*'), func(rune) string { return "*" }), tag)
+}
+
+// weightedList parses a list of p with optional weights, returning each
+// element with its quality.
+func weightedList(p parser.Parser[string]) parser.Parser[[]parser.Tuple[string, float64]] {
+	w := parser.ZeroOrOne(parser.OmitLeft(semicolon(), weight()))
+	return list(parser.Bind(p, func(s string) parser.Parser[parser.Tuple[string, float64]] {
+		return parser.Fmap(w, func(w parser.Maybe[string]) parser.Tuple[string, float64] {
+			if w.IsJust() {
+				return parser.NewTuple(s, quality(w.Get()))
+			}
+			return parser.NewTuple(s, 1.0)
+		})
+	}))
+}
+
+// byQuality orders elements by descending quality, then wildcards after the elements
+// they would match. Equal elements keep the order of the header.
+func byQuality(qa, qb float64, wa, wb bool) int {
+	if c := cmp.Compare(qb, qa); c != 0 {
+		return c
+	}
+	switch {
+	case wa && !wb:
+		return 1
+	case wb && !wa:
+		return -1
+	}
+	return 0
+}
+
+// ParseAccept parses the value of an Accept header, e.g. "text/html, application/*;q=0.8".
+// The ranges are sorted by precedence: by descending quality, then from the most to the
+// least specific, otherwise keeping the order of the header. An empty value gives no ranges.
+func ParseAccept(s string) ([]MediaRange, error) {
+	ms, err := parser.Run(accept(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "httpsyntax")
+	}
+	slices.SortStableFunc(ms, func(a, b MediaRange) int {
+		if c := cmp.Compare(b.Quality, a.Quality); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.specificity(), a.specificity())
+	})
+	return ms, nil
+}
+
+// ParseAcceptLanguage parses the value of an Accept-Language header, e.g. "en-GB, en;q=0.8".
+// The ranges are sorted by descending quality, with "*" after the other ranges of the same
+// quality, otherwise keeping the order of the header.
+func ParseAcceptLanguage(s string) ([]LanguageRange, error) {
+	ts, err := parser.Run(weightedList(languageRange()), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "httpsyntax")
+	}
+	ls := make([]LanguageRange, len(ts))
+	for i, t := range ts {
+		ls[i] = LanguageRange{Tag: t.First, Quality: t.Second}
+	}
+	slices.SortStableFunc(ls, func(a, b LanguageRange) int {
+		return byQuality(a.Quality, b.Quality, a.Tag == "*", b.Tag == "*")
+	})
+	return ls, nil
+}
+
+// ParseAcceptEncoding parses the value of an Accept-Encoding header, e.g. "gzip, br;q=0.9".
+// The codings are sorted by descending quality, with "*" after the other codings of the same
+// quality, otherwise keeping the order of the header.
+func ParseAcceptEncoding(s string) ([]Coding, error) {
+	ts, err := parser.Run(weightedList(parser.Fmap(token(), strings.ToLower)), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "httpsyntax")
+	}
+	cs := make([]Coding, len(ts))
+	for i, t := range ts {
+		cs[i] = Coding{Name: t.First, Quality: t.Second}
+	}
+	slices.SortStableFunc(cs, func(a, b Coding) int {
+		return byQuality(a.Quality, b.Quality, a.Name == "*", b.Name == "*")
+	})
+	return cs, nil
+}
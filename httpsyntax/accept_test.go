@@ -0,0 +1,103 @@
+package httpsyntax_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/httpsyntax"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"single", "text/html", []string{"text/html"}},
+		{"empty", "", nil},
+		{"empty elements", " , text/html ,, ", []string{"text/html"}},
+		{"lowercased", "Text/HTML;Charset=UTF-8", []string{"text/html;charset=UTF-8"}},
+		{"quality", "text/plain; q=0.5, text/html", []string{"text/html", "text/plain;q=0.5"}},
+		{"specificity", "*/*, text/*, text/html, text/html;level=1", []string{"text/html;level=1", "text/html", "text/*", "*/*"}},
+		{"quality before specificity", "text/html;q=0.1, */*", []string{"*/*", "text/html;q=0.1"}},
+		{"stable", "b/b, a/a, c/c;q=1.000", []string{"b/b", "a/a", "c/c"}},
+		{"quoted parameter", `text/plain;title="a \"b\"";q=0`, []string{`text/plain;title="a \"b\"";q=0`}},
+		{"extensions", "text/html;q=0.8;ext=1", []string{"text/html;q=0.8;ext=1"}},
+		{"rfc 9110 example", "text/*;q=0.3, text/plain;q=0.7, text/plain;format=flowed, text/plain;format=fixed;q=0.4, */*;q=0.5",
+			[]string{"text/plain;format=flowed", "text/plain;q=0.7", "*/*;q=0.5", "text/plain;format=fixed;q=0.4", "text/*;q=0.3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := httpsyntax.ParseAccept(tt.input)
+			assert.NoError(t, err)
+			var got []string
+			for _, m := range ms {
+				got = append(got, m.String())
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseAcceptErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"text", parser.ErrUnexpectedEOF, "httpsyntax: line 1, col 5: unexpected end of input, expected token or '/'"},
+		{"*/html", parser.ErrNoMatch, "httpsyntax: line 1, col 3: unexpected 'h', expected '*'"},
+		{"text/html;q=2", parser.ErrNoMatch, "httpsyntax: line 1, col 13: unexpected '2', expected qvalue"},
+		{"text/html;q=1.5", parser.ErrNoMatch, "httpsyntax: line 1, col 13: unexpected '1', expected qvalue"},
+		{"text/html;q=0.1234", parser.ErrNoMatch, "httpsyntax: line 1, col 18: unexpected '4'"},
+		{"text/html;level", parser.ErrUnexpectedEOF, "httpsyntax: line 1, col 16: unexpected end of input, expected token or '='"},
+		{`text/html;a="b`, parser.ErrUnexpectedEOF, "httpsyntax: line 1, col 15: unexpected end of input"},
+		{"text/html text/plain", parser.ErrNoMatch, "httpsyntax: line 1, col 11: unexpected 't'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := httpsyntax.ParseAccept(tt.input)
+			var perr *httpsyntax.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestMediaRangeMatch(t *testing.T) {
+	ms, err := httpsyntax.ParseAccept("text/plain;format=flowed, text/*, */*;q=0")
+	assert.NoError(t, err)
+	assert.True(t, ms[0].Match("Text/Plain; charset=utf-8; format=Flowed"))
+	assert.False(t, ms[0].Match("text/plain"))
+	assert.True(t, ms[1].Match("text/html"))
+	assert.False(t, ms[1].Match("image/png"))
+	assert.True(t, ms[2].Match("image/png"))
+	assert.False(t, ms[2].Match("image/*"))
+	assert.False(t, ms[2].Match("not a type"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	ls, err := httpsyntax.ParseAcceptLanguage("*;q=0.5, da, en-GB;q=0.8, en;q=0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []httpsyntax.LanguageRange{{"da", 1}, {"en-GB", 0.8}, {"en", 0.5}, {"*", 0.5}}, ls)
+	assert.True(t, ls[2].Match("EN-us"))
+	assert.False(t, ls[2].Match("eng"))
+	assert.True(t, ls[3].Match("fr"))
+	assert.Equal(t, "en-GB;q=0.8", ls[1].String())
+
+	_, err = httpsyntax.ParseAcceptLanguage("en-abcdefghi")
+	assert.EqualError(t, err, "httpsyntax: line 1, col 4: unexpected 'a', expected subtag of at most 8 characters")
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	cs, err := httpsyntax.ParseAcceptEncoding("*;q=0.1, GZIP;q=0.5, br, identity;q=0")
+	assert.NoError(t, err)
+	assert.Equal(t, []httpsyntax.Coding{{"br", 1}, {"gzip", 0.5}, {"*", 0.1}, {"identity", 0}}, cs)
+	assert.True(t, cs[1].Match("Gzip"))
+	assert.True(t, cs[2].Match("deflate"))
+
+	_, err = httpsyntax.ParseAcceptEncoding("gzip;level=1")
+	assert.EqualError(t, err, "httpsyntax: line 1, col 6: unexpected 'l', expected 'q' or 'Q'")
+}
@@ -0,0 +1,160 @@
+// Package httpsyntax provides parsing of the Content-Security-Policy header field, e.g. for
+// tools auditing the policies of responses.
+package httpsyntax
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// directives lists the directive names defined by CSP Level 3 and the specifications
+// extending it, including deprecated ones that are still sent.
+var directives = map[string]bool{
+	// Fetch directives.
+	"child-src": true, "connect-src": true, "default-src": true, "fenced-frame-src": true,
+	"font-src": true, "frame-src": true, "img-src": true, "manifest-src": true, "media-src": true,
+	"object-src": true, "prefetch-src": true, "script-src": true, "script-src-elem": true,
+	"script-src-attr": true, "style-src": true, "style-src-elem": true, "style-src-attr": true,
+	"worker-src": true,
+	// Document directives.
+	"base-uri": true, "sandbox": true, "plugin-types": true,
+	// Navigation directives.
+	"form-action": true, "frame-ancestors": true, "navigate-to": true,
+	// Reporting directives.
+	"report-uri": true, "report-to": true,
+	// Other directives.
+	"block-all-mixed-content": true, "require-sri-for": true, "require-trusted-types-for": true,
+	"trusted-types": true, "upgrade-insecure-requests": true, "webrtc": true,
+}
+
+// fallbacks lists, for the fetch directives, the directives whose sources apply in turn
+// when the directive is missing from a policy.
+var fallbacks = map[string][]string{
+	"script-src-elem":  {"script-src", "default-src"},
+	"script-src-attr":  {"script-src", "default-src"},
+	"style-src-elem":   {"style-src", "default-src"},
+	"style-src-attr":   {"style-src", "default-src"},
+	"worker-src":       {"child-src", "script-src", "default-src"},
+	"frame-src":        {"child-src", "default-src"},
+	"fenced-frame-src": {"frame-src", "child-src", "default-src"},
+	"child-src":        {"default-src"},
+	"connect-src":      {"default-src"},
+	"font-src":         {"default-src"},
+	"img-src":          {"default-src"},
+	"manifest-src":     {"default-src"},
+	"media-src":        {"default-src"},
+	"object-src":       {"default-src"},
+	"prefetch-src":     {"default-src"},
+	"script-src":       {"default-src"},
+	"style-src":        {"default-src"},
+}
+
+// Policy is a Content-Security-Policy, e.g. "default-src 'self'; img-src *".
+type Policy struct {
+	// Directives maps the lowercased directive names to their values, e.g. script-src to the
+	// source list "'self'", "https://cdn.example.com". Directives without a value, e.g.
+	// upgrade-insecure-requests, map to an empty list.
+	Directives map[string][]string
+	// Names lists the directive names in the order of the header.
+	Names []string
+}
+
+// Sources returns the source list governing the fetch directive name, which is its own if
+// the policy has it, or else that of the directive it falls back to, e.g. default-src for
+// img-src. The result is false if neither the directive nor a fallback is in the policy.
+func (p Policy) Sources(name string) ([]string, bool) {
+	name = strings.ToLower(name)
+	for _, n := range append([]string{name}, fallbacks[name]...) {
+		if v, ok := p.Directives[n]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the policy in the syntax of a Content-Security-Policy header.
+func (p Policy) String() string {
+	ds := make([]string, len(p.Names))
+	for i, name := range p.Names {
+		ds[i] = strings.Join(append([]string{name}, p.Directives[name]...), " ")
+	}
+	return strings.Join(ds, "; ")
+}
+
+// directive is a directive of a policy as written, with the offset of its name.
+type directive struct {
+	offset int
+	name   string
+	values []string
+}
+
+// cspDirective parses a directive such as "img-src 'self' data:".
+func cspDirective() parser.Parser[directive] {
+	isNameChar := func(r rune) bool { return isAlpha(r) || isDigit(r) || r == '-' }
+	name := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isNameChar, "directive name")), func(rs []rune) string {
+		return strings.ToLower(parser.Text(rs))
+	})
+	isValueChar := func(r rune) bool { return r > ' ' && r < 0x7f && r != ',' && r != ';' }
+	value := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isValueChar, "source expression")), parser.Text)
+	rws := parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r == ' ' || r == '\t' }))
+	values := parser.ZeroOrMore(parser.OmitLeft(rws, value))
+	return parser.Bind(parser.Pos(), func(offset int) parser.Parser[directive] {
+		return parser.Bind(name, func(name string) parser.Parser[directive] {
+			return parser.Fmap(values, func(values []string) directive {
+				return directive{offset: offset, name: name, values: values}
+			})
+		})
+	})
+}
+
+// csp parses the policies of a Content-Security-Policy header, each a list of directives
+// separated by semicolons, skipping empty ones as browsers do.
+func csp() parser.Parser[[][]directive] {
+	ds := parser.SepBy(parser.OmitLeft(ows(), parser.ZeroOrOne(cspDirective())), parser.OmitLeft(ows(), parser.Char(';')))
+	policy := parser.Fmap(ds, func(ms []parser.Maybe[directive]) []directive {
+		var ds []directive
+		for _, m := range ms {
+			if m.IsJust() {
+				ds = append(ds, m.Get())
+			}
+		}
+		return ds
+	})
+	return list(policy)
+}
+
+// ParseCSP parses the value of a Content-Security-Policy header, e.g.
+// "default-src 'self'; script-src 'self' https://cdn.example.com", which holds one policy
+// or several separated by commas. Directives must have a name defined by the CSP
+// specifications and appear at most once in a policy; errors wrapping ErrUnknownDirective
+// and ErrDuplicateDirective are reported otherwise. Source expressions are not validated.
+func ParseCSP(s string) ([]Policy, error) {
+	pss, err := parser.Run(csp(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "httpsyntax")
+	}
+	var policies []Policy
+	for _, ds := range pss {
+		if len(ds) == 0 {
+			continue
+		}
+		p := Policy{Directives: map[string][]string{}}
+		for _, d := range ds {
+			if !directives[d.name] {
+				return nil, parser.ErrorAt("httpsyntax", s, d.offset, fmt.Errorf("%w: %s", ErrUnknownDirective, d.name))
+			}
+			if _, ok := p.Directives[d.name]; ok {
+				return nil, parser.ErrorAt("httpsyntax", s, d.offset, fmt.Errorf("%w: %s", ErrDuplicateDirective, d.name))
+			}
+			if d.values == nil {
+				d.values = []string{}
+			}
+			p.Directives[d.name] = d.values
+			p.Names = append(p.Names, d.name)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
@@ -0,0 +1,90 @@
+package httpsyntax_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/httpsyntax"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSP(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"single", "default-src 'self'", []string{"default-src 'self'"}},
+		{"several directives", "default-src 'none'; img-src 'self' data:;script-src https://cdn.example.com",
+			[]string{"default-src 'none'; img-src 'self' data:; script-src https://cdn.example.com"}},
+		{"lowercased names", "Default-SRC 'SELF'", []string{"default-src 'SELF'"}},
+		{"no value", "upgrade-insecure-requests; block-all-mixed-content", []string{"upgrade-insecure-requests; block-all-mixed-content"}},
+		{"whitespace and empty directives", "  ; img-src \t a.com  b.com ;; ", []string{"img-src a.com b.com"}},
+		{"several policies", "default-src 'self', img-src *, ", []string{"default-src 'self'", "img-src *"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := httpsyntax.ParseCSP(tt.input)
+			assert.NoError(t, err)
+			var got []string
+			for _, p := range ps {
+				got = append(got, p.String())
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseCSPErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"default-src 'self'; scirpt-src 'none'", httpsyntax.ErrUnknownDirective, "httpsyntax: line 1, col 21: unknown directive: scirpt-src"},
+		{"img-src a; IMG-SRC b", httpsyntax.ErrDuplicateDirective, "httpsyntax: line 1, col 12: duplicate directive: img-src"},
+		{"img-src a, img-src b", nil, ""},
+		{"script-src'self'", parser.ErrNoMatch, "httpsyntax: line 1, col 11: unexpected '\\''"},
+		{"img-src é", parser.ErrNoMatch, "httpsyntax: line 1, col 9: unexpected 'é', expected source expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := httpsyntax.ParseCSP(tt.input)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var perr *httpsyntax.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestPolicySources(t *testing.T) {
+	ps, err := httpsyntax.ParseCSP("default-src 'self'; script-src 'none'; child-src blob:; upgrade-insecure-requests")
+	assert.NoError(t, err)
+	p := ps[0]
+
+	tests := []struct {
+		name     string
+		expected []string
+		ok       bool
+	}{
+		{"img-src", []string{"'self'"}, true},
+		{"script-src-elem", []string{"'none'"}, true},
+		{"worker-src", []string{"blob:"}, true},
+		{"Style-Src", []string{"'self'"}, true},
+		{"upgrade-insecure-requests", []string{}, true},
+		{"form-action", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcs, ok := p.Sources(tt.name)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, srcs)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package httpsyntax provides error reporting for malformed header values.
+package httpsyntax
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrUnknownDirective is reported for a Content-Security-Policy directive whose name is
+	// not defined by the CSP specifications, which browsers ignore, e.g. a misspelt one.
+	ErrUnknownDirective = errors.New("unknown directive")
+	// ErrDuplicateDirective is reported for a Content-Security-Policy directive that is
+	// repeated in a policy, which browsers ignore after the first.
+	ErrDuplicateDirective = errors.New("duplicate directive")
+	// ErrDuplicateParameter is reported for a media type parameter that is given more than
+	// once, including through RFC 2231 continuations.
+	ErrDuplicateParameter = errors.New("duplicate parameter")
+	// ErrInvalidParameter is reported for a media type parameter using the RFC 2231
+	// extensions that cannot be decoded, e.g. for a missing continuation or an unsupported
+	// charset.
+	ErrInvalidParameter = errors.New("invalid parameter")
+)
+
+// ParseError describes why a header value could not be parsed. Expected lists what the value should
+// contain at the error, e.g. "qvalue". Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping one of the errors of this
+// package.
+type ParseError = parser.Error
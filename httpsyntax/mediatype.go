@@ -0,0 +1,262 @@
+// Package httpsyntax provides parsing of MIME media types such as the value of a
+// Content-Type header, including the RFC 2231 extensions of parameter values.
+package httpsyntax
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// MediaType is a media type such as "text/html; charset=utf-8", in canonical form.
+type MediaType struct {
+	// Type is the lowercased top-level type, e.g. "text".
+	Type string
+	// Subtype is the lowercased subtype, e.g. "html".
+	Subtype string
+	// Params lists the parameters sorted by name, with their values decoded: unquoted, and
+	// with the RFC 2231 continuations joined and charsets converted to UTF-8.
+	Params []Param
+}
+
+// Param returns the value of the parameter name, ignoring case.
+func (m MediaType) Param(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, p := range m.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// String returns the media type in canonical form, e.g. `text/plain; charset=utf-8`. Values
+// that are not printable ASCII are written with the RFC 2231 extensions in UTF-8.
+func (m MediaType) String() string {
+	var b strings.Builder
+	b.WriteString(m.Type + "/" + m.Subtype)
+	for _, p := range m.Params {
+		b.WriteString("; ")
+		if isPrintable(p.Value) {
+			b.WriteString(p.String())
+			continue
+		}
+		b.WriteString(p.Name + "*=utf-8''")
+		for i := 0; i < len(p.Value); i++ {
+			if c := p.Value[i]; isAttrChar(rune(c)) {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+	}
+	return b.String()
+}
+
+func isPrintable(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r != '\t' && (r < 0x20 || r >= 0x7f) }) < 0
+}
+
+// isAttrChar reports whether r may appear unencoded in an RFC 2231 extended value.
+func isAttrChar(r rune) bool {
+	return isTchar(r) && r != '*' && r != '\'' && r != '%'
+}
+
+// rawParam is a parameter as written, with the offset of its name.
+type rawParam struct {
+	offset int
+	Param
+}
+
+// mediaType parses a media type with its parameters as written, skipping empty parameters.
+func mediaType() parser.Parser[parser.Tuple[MediaType, []rawParam]] {
+	lower := parser.Fmap(token(), strings.ToLower)
+	raw := parser.Bind(parser.Pos(), func(offset int) parser.Parser[rawParam] {
+		return parser.Fmap(param(func(string) bool { return false }), func(p Param) rawParam {
+			return rawParam{offset: offset, Param: p}
+		})
+	})
+	ps := parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(semicolon(), parser.ZeroOrOne(raw))), func(ms []parser.Maybe[rawParam]) []rawParam {
+		var ps []rawParam
+		for _, m := range ms {
+			if m.IsJust() {
+				ps = append(ps, m.Get())
+			}
+		}
+		return ps
+	})
+	return parser.Bind(parser.OmitRight(parser.OmitLeft(ows(), lower), parser.Char('/')), func(typ string) parser.Parser[parser.Tuple[MediaType, []rawParam]] {
+		return parser.Bind(lower, func(subtype string) parser.Parser[parser.Tuple[MediaType, []rawParam]] {
+			return parser.Fmap(parser.OmitRight(ps, ows()), func(ps []rawParam) parser.Tuple[MediaType, []rawParam] {
+				return parser.NewTuple(MediaType{Type: typ, Subtype: subtype}, ps)
+			})
+		})
+	})
+}
+
+// section is a part of an RFC 2231 parameter value.
+type section struct {
+	offset  int
+	value   string
+	encoded bool
+}
+
+// splitName splits an RFC 2231 parameter name such as "title*1*" into its base name, its
+// section number, or -1 if it has none, and whether its value is encoded.
+func splitName(name string) (string, int, bool, bool) {
+	base, rest, found := strings.Cut(name, "*")
+	if !found {
+		return name, -1, false, true
+	}
+	if rest == "" {
+		return base, -1, true, true
+	}
+	digits, encoded := strings.CutSuffix(rest, "*")
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 || digits[0] == '+' || len(digits) > 1 && digits[0] == '0' {
+		return "", 0, false, false
+	}
+	return base, n, encoded, true
+}
+
+// decodeSection decodes the value of an encoded section, returning its charset, which
+// only the first section has, and its bytes.
+func decodeSection(v string, first bool) (string, []byte, error) {
+	var charset string
+	if first {
+		parts := strings.SplitN(v, "'", 3)
+		if len(parts) != 3 {
+			return "", nil, errors.New("missing charset")
+		}
+		charset, v = parts[0], parts[2]
+	}
+	b := make([]byte, 0, len(v))
+	for i := 0; i < len(v); i++ {
+		if v[i] != '%' {
+			b = append(b, v[i])
+			continue
+		}
+		if i+2 >= len(v) || !isHexDigit(v[i+1]) || !isHexDigit(v[i+2]) {
+			return "", nil, errors.New("invalid percent-encoding")
+		}
+		n, _ := strconv.ParseUint(v[i+1:i+3], 16, 8)
+		b = append(b, byte(n))
+		i += 2
+	}
+	return charset, b, nil
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(rune(c)) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// convert converts text in the charset to UTF-8.
+func convert(charset string, b []byte) (string, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "us-ascii":
+		if !utf8.Valid(b) {
+			return "", fmt.Errorf("invalid %s text", cmp.Or(charset, "UTF-8"))
+		}
+		return string(b), nil
+	case "iso-8859-1", "latin1":
+		rs := make([]rune, len(b))
+		for i, c := range b {
+			rs[i] = rune(c)
+		}
+		return string(rs), nil
+	}
+	return "", fmt.Errorf("unsupported charset %s", charset)
+}
+
+// decodeParams joins the sections of the parameters and decodes their values, reporting
+// errors at their offsets in s.
+func decodeParams(s string, raws []rawParam) ([]Param, error) {
+	type sections struct {
+		offset int
+		byNum  map[int]section
+	}
+	byName := map[string]*sections{}
+	var names []string
+	for _, r := range raws {
+		name, n, encoded, ok := splitName(r.Name)
+		if !ok {
+			return nil, parser.ErrorAt("httpsyntax", s, r.offset, fmt.Errorf("%w: %s: invalid section", ErrInvalidParameter, r.Name))
+		}
+		ss := byName[name]
+		if ss == nil {
+			ss = &sections{offset: r.offset, byNum: map[int]section{}}
+			byName[name] = ss
+			names = append(names, name)
+		}
+		_, dup := ss.byNum[n]
+		_, whole := ss.byNum[-1]
+		if dup || whole || n == -1 && len(ss.byNum) > 0 {
+			return nil, parser.ErrorAt("httpsyntax", s, r.offset, fmt.Errorf("%w: %s", ErrDuplicateParameter, name))
+		}
+		ss.byNum[n] = section{offset: r.offset, value: r.Value, encoded: encoded}
+	}
+	ps := make([]Param, 0, len(names))
+	for _, name := range names {
+		ss := byName[name]
+		var secs []section
+		if whole, ok := ss.byNum[-1]; ok {
+			secs = []section{whole}
+		}
+		for i := range len(ss.byNum) - len(secs) {
+			sec, ok := ss.byNum[i]
+			if !ok {
+				return nil, parser.ErrorAt("httpsyntax", s, ss.offset, fmt.Errorf("%w: %s: missing section %d", ErrInvalidParameter, name, i))
+			}
+			secs = append(secs, sec)
+		}
+		var charset string
+		var b []byte
+		for i, sec := range secs {
+			if !sec.encoded {
+				b = append(b, sec.value...)
+				continue
+			}
+			cs, v, err := decodeSection(sec.value, i == 0)
+			if err != nil {
+				return nil, parser.ErrorAt("httpsyntax", s, sec.offset, fmt.Errorf("%w: %s: %w", ErrInvalidParameter, name, err))
+			}
+			if i == 0 {
+				charset = cs
+			}
+			b = append(b, v...)
+		}
+		v, err := convert(charset, b)
+		if err != nil {
+			return nil, parser.ErrorAt("httpsyntax", s, ss.offset, fmt.Errorf("%w: %s: %w", ErrInvalidParameter, name, err))
+		}
+		ps = append(ps, Param{Name: name, Value: v})
+	}
+	slices.SortFunc(ps, func(a, b Param) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return ps, nil
+}
+
+// ParseMediaType parses a media type such as the value of a Content-Type header, e.g.
+// "text/html; charset=utf-8". Parameter values may be tokens or quoted strings, and may use the
+// RFC 2231 extensions: continuations such as "title*0", "title*1", which are joined, and
+// encoded values such as "title*=iso-8859-1'en'%A3", which are decoded from the charsets
+// UTF-8, US-ASCII and ISO-8859-1. Errors wrapping ErrDuplicateParameter and
+// ErrInvalidParameter are reported for parameters that cannot be decoded.
+func ParseMediaType(s string) (MediaType, error) {
+	t, err := parser.Run(mediaType(), s)
+	if err != nil {
+		return MediaType{}, parser.Wrap(err, "httpsyntax")
+	}
+	m := t.First
+	if m.Params, err = decodeParams(s, t.Second); err != nil {
+		return MediaType{}, err
+	}
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package httpsyntax_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/httpsyntax"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMediaType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "text/html", "text/html"},
+		{"canonical", " Text/HTML ;Charset=UTF-8 ", "text/html; charset=UTF-8"},
+		{"sorted parameters", "multipart/mixed; boundary=x; a=1", "multipart/mixed; a=1; boundary=x"},
+		{"quoted value", `text/plain; title="a \"b\" c"`, `text/plain; title="a \"b\" c"`},
+		{"unnecessary quotes", `text/plain; charset="utf-8"`, "text/plain; charset=utf-8"},
+		{"empty parameters", "text/plain;; a=1;", "text/plain; a=1"},
+		{"encoded", "text/plain; title*=us-ascii'en-us'This%20is%20%2A%2A%2Afun%2A%2A%2A", `text/plain; title="This is ***fun***"`},
+		{"continuations", `text/plain; url*0="ftp://"; url*1="example.com/a"`, `text/plain; url="ftp://example.com/a"`},
+		{"encoded continuations", `text/plain; t*1*=%E2%82%AC; t*0*=utf-8''a; t*2=" b"`, "text/plain; t*=utf-8''a%E2%82%AC%20b"},
+		{"latin-1", "text/plain; name*=iso-8859-1''%A3", "text/plain; name*=utf-8''%C2%A3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := httpsyntax.ParseMediaType(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m.String())
+		})
+	}
+}
+
+func TestParseMediaTypeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"text", parser.ErrUnexpectedEOF, "httpsyntax: line 1, col 5: unexpected end of input, expected token or '/'"},
+		{"text/plain; a", parser.ErrUnexpectedEOF, "httpsyntax: line 1, col 14: unexpected end of input"},
+		{"text/plain; a=1; A=2", httpsyntax.ErrDuplicateParameter, "httpsyntax: line 1, col 18: duplicate parameter: a"},
+		{"text/plain; a=1; a*=utf-8''2", httpsyntax.ErrDuplicateParameter, "httpsyntax: line 1, col 18: duplicate parameter: a"},
+		{"text/plain; a*0=1; a*2=3", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a: missing section 1"},
+		{"text/plain; a*01=1", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a*01: invalid section"},
+		{"text/plain; a*=1", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a: missing charset"},
+		{"text/plain; a*=utf-8''%2", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a: invalid percent-encoding"},
+		{"text/plain; a*=utf-8''%FF", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a: invalid utf-8 text"},
+		{"text/plain; a*=koi8-r''x", httpsyntax.ErrInvalidParameter, "httpsyntax: line 1, col 13: invalid parameter: a: unsupported charset koi8-r"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := httpsyntax.ParseMediaType(tt.input)
+			var perr *httpsyntax.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestMediaTypeParam(t *testing.T) {
+	m, err := httpsyntax.ParseMediaType("text/plain; charset=utf-8")
+	assert.NoError(t, err)
+	v, ok := m.Param("Charset")
+	assert.True(t, ok)
+	assert.Equal(t, "utf-8", v)
+	_, ok = m.Param("format")
+	assert.False(t, ok)
+}
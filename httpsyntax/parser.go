@@ -0,0 +1,140 @@
+// Package httpsyntax provides the RFC 9110 grammar shared by HTTP header fields: tokens,
+// quoted strings, parameters and comma-separated lists, built with the tiny-parsec combinators.
+package httpsyntax
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isAlpha(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isTchar reports whether r may appear in a token.
+func isTchar(r rune) bool {
+	return isAlpha(r) || isDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// isQdtext reports whether r may appear unescaped in a quoted string.
+func isQdtext(r rune) bool {
+	return r == '\t' || r == ' ' || r == 0x21 || r >= 0x23 && r <= 0x5b || r >= 0x5d && r <= 0x7e || r >= 0x80
+}
+
+// isQuotable reports whether r may follow a backslash in a quoted string.
+func isQuotable(r rune) bool {
+	return r == '\t' || r >= 0x20 && r != 0x7f
+}
+
+// isToken reports whether s is a non-empty token, and so may be written without quotes.
+func isToken(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool { return !isTchar(r) }) < 0
+}
+
+// ows parses optional whitespace.
+func ows() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return r == ' ' || r == '\t' }))
+}
+
+// token parses a token such as "gzip" or "text".
+func token() parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isTchar, "token")), parser.Text)
+}
+
+// quotedString parses a quoted string, returning its content with quoted pairs unescaped.
+func quotedString() parser.Parser[string] {
+	pair := parser.OmitLeft(parser.Char('\\'), parser.SatisfyMsg(isQuotable, "quoted character"))
+	content := parser.ZeroOrMore(parser.OrElse(parser.SatisfyMsg(isQdtext, "character"), pair))
+	return parser.Fmap(parser.Between(parser.Char('"'), content, parser.Char('"')), parser.Text)
+}
+
+// quote writes s as a token if it is one and as a quoted string otherwise.
+func quote(s string) string {
+	if isToken(s) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// semicolon parses the separator before a parameter.
+func semicolon() parser.Parser[rune] {
+	return parser.OmitRight(parser.OmitLeft(ows(), parser.Char(';')), ows())
+}
+
+// param parses a parameter such as "charset=utf-8" or `title="a b"`, lowercasing its name.
+// Names for which exclude returns true are rejected, e.g. the "q" of a weight.
+func param(exclude func(name string) bool) parser.Parser[Param] {
+	name := parser.SatisfyWithMsg(parser.Fmap(token(), strings.ToLower), func(name string) bool {
+		return !exclude(name)
+	}, "parameter")
+	value := parser.OrElse(token(), quotedString())
+	return parser.Bind(parser.OmitRight(name, parser.Char('=')), func(name string) parser.Parser[Param] {
+		return parser.Fmap(value, func(value string) Param {
+			return Param{Name: name, Value: value}
+		})
+	})
+}
+
+// qvalue parses the value of a weight, between "0" and "1" with at most three decimals.
+func qvalue() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		n := 0
+		if s != "" && (s[0] == '0' || s[0] == '1') {
+			n = 1
+			if n < len(s) && s[n] == '.' {
+				n++
+				for n < len(s) && n < 5 && isDigit(rune(s[n])) {
+					n++
+				}
+			}
+		}
+		if n == 0 || s[0] == '1' && strings.Trim(s[1:n], ".0") != "" {
+			if s == "" {
+				st.Truncated("qvalue")
+			} else {
+				st.Fail("qvalue")
+			}
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:n], next))
+	})
+}
+
+// weight parses the "q=" parameter giving the preference for a list element.
+func weight() parser.Parser[string] {
+	q := parser.OrElse(parser.Char('q'), parser.Char('Q'))
+	return parser.OmitLeft(q, parser.OmitLeft(parser.Char('='), qvalue()))
+}
+
+// list parses a comma-separated list of elements, skipping empty ones as RFC 9110 requires.
+func list[T any](p parser.Parser[T]) parser.Parser[[]T] {
+	elems := parser.SepBy(parser.OmitLeft(ows(), parser.ZeroOrOne(p)), parser.OmitLeft(ows(), parser.Char(',')))
+	return parser.Fmap(parser.OmitRight(elems, ows()), func(ms []parser.Maybe[T]) []T {
+		var ts []T
+		for _, m := range ms {
+			if m.IsJust() {
+				ts = append(ts, m.Get())
+			}
+		}
+		return ts
+	})
+}
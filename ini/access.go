@@ -0,0 +1,189 @@
+package ini
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lookup returns the value of key in the named section, and whether it was found.
+// The empty section name refers to the global entries before the first section header.
+// If a section or key occurs more than once, the last occurrence wins.
+func (ini Ini) Lookup(section, key string) (string, bool) {
+	entries := ini.Global
+	if section != "" {
+		found := false
+		for _, s := range ini.Sections {
+			if ini.match(s.Name, section) && ini.hasKey(s.Entries, key) {
+				entries, found = s.Entries, true
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	value, ok := "", false
+	for _, e := range entries {
+		if ini.match(e.Key, key) {
+			value, ok = e.Value, true
+		}
+	}
+	return value, ok
+}
+
+// match reports whether the section names or keys a and b are the same, ignoring case if IgnoreCase is set.
+func (ini Ini) match(a, b string) bool {
+	return a == b || ini.IgnoreCase && strings.EqualFold(a, b)
+}
+
+// hasKey reports whether entries contain key.
+func (ini Ini) hasKey(entries []Entry, key string) bool {
+	for _, e := range entries {
+		if ini.match(e.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetString returns the value of key in the named section, like Lookup.
+func (ini Ini) GetString(section, key string) (string, bool) {
+	return ini.Lookup(section, key)
+}
+
+// GetStrings returns the values of all occurrences of key in the named section, in document order,
+// and reports whether there was any. It collects both the entries written with the array suffix,
+// as in "key[] = value", and the plain repeated entries kept by the default DuplicateCollect policy.
+// Unlike Lookup, it collects the entries of every section with the name.
+func (ini Ini) GetStrings(section, key string) ([]string, bool) {
+	var values []string
+	collect := func(entries []Entry) {
+		for _, e := range entries {
+			if ini.match(e.Key, key) || ini.match(e.Key, key+"[]") {
+				values = append(values, e.Value)
+			}
+		}
+	}
+	if section == "" {
+		collect(ini.Global)
+		return values, len(values) > 0
+	}
+	for _, s := range ini.Sections {
+		if ini.match(s.Name, section) {
+			collect(s.Entries)
+		}
+	}
+	return values, len(values) > 0
+}
+
+// GetInt returns the value of key in the named section as an int. Values may be written in
+// decimal or with a 0x, 0o or 0b prefix. It reports false if the key is missing or not an integer.
+func (ini Ini) GetInt(section, key string) (int, bool) {
+	v, ok := ini.Lookup(section, key)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 0, strconv.IntSize)
+	if err != nil {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// GetBool returns the value of key in the named section as a bool. The words true, yes and on,
+// and false, no and off are accepted in any case, as well as the values strconv.ParseBool accepts.
+// It reports false if the key is missing or not a boolean.
+func (ini Ini) GetBool(section, key string) (bool, bool) {
+	v, ok := ini.Lookup(section, key)
+	if !ok {
+		return false, false
+	}
+	return parseBool(v)
+}
+
+// parseBool parses a boolean INI value, reporting whether it is one.
+func parseBool(v string) (bool, bool) {
+	switch strings.ToLower(v) {
+	case "yes", "on":
+		return true, true
+	case "no", "off":
+		return false, true
+	}
+	b, err := strconv.ParseBool(strings.ToLower(v))
+	return b, err == nil
+}
+
+// GetFloat returns the value of key in the named section as a float64.
+// It reports false if the key is missing or not a number.
+func (ini Ini) GetFloat(section, key string) (float64, bool) {
+	v, ok := ini.Lookup(section, key)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// GetDuration returns the value of key in the named section as a time.Duration written as
+// time.ParseDuration expects, e.g. "1m30s". It reports false if the key is missing or not a duration.
+func (ini Ini) GetDuration(section, key string) (time.Duration, bool) {
+	v, ok := ini.Lookup(section, key)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// Path returns the components of the section name of a hierarchical configuration: the name split
+// at its dots, e.g. ["server", "tls"] for [server.tls]. A gitconfig-style subsection name in double
+// quotes after the first component is a single component, e.g. ["remote", "origin"] for
+// [remote "origin"].
+func (s Section) Path() []string {
+	return sectionPath(s.Name)
+}
+
+// sectionPath splits a section name into its components, as described for Section.Path.
+func sectionPath(name string) []string {
+	base, sub, quoted := strings.Cut(name, `"`)
+	if !quoted || !strings.HasSuffix(sub, `"`) || strings.TrimSpace(base) == "" {
+		base, sub, quoted = name, "", false
+	}
+	var path []string
+	for _, c := range strings.Split(strings.TrimSpace(base), ".") {
+		path = append(path, strings.TrimSpace(c))
+	}
+	if quoted {
+		path = append(path, sub[:len(sub)-1])
+	}
+	return path
+}
+
+// Sub returns the part of the document below the named section, with names written as for
+// Section.Path: the entries of the section itself become the global entries, and each of its
+// subsections becomes a section named after the rest of its path, joined with dots. For example,
+// Sub("server") of a document with the sections [server] and [server.tls] holds the entries of
+// [server] as global entries and a section [tls].
+func (ini Ini) Sub(name string) Ini {
+	prefix := sectionPath(name)
+	sub := Ini{Sections: []Section{}, IgnoreCase: ini.IgnoreCase}
+	for _, s := range ini.Sections {
+		path := s.Path()
+		if len(path) < len(prefix) || !slices.EqualFunc(path[:len(prefix)], prefix, ini.match) {
+			continue
+		}
+		if len(path) == len(prefix) {
+			sub.Global = append(sub.Global, s.Entries...)
+			continue
+		}
+		sub.Sections = append(sub.Sections, Section{Name: strings.Join(path[len(prefix):], "."), Entries: s.Entries})
+	}
+	return sub
+}
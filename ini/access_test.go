@@ -0,0 +1,281 @@
+package ini_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessors(t *testing.T) {
+	doc := ini.ParseINI(`name = app
+[server]
+port = 8080
+mask = 0x1F
+debug = On
+ratio = 0.75
+timeout = 1m30s
+[server]
+port = 9090
+[flags]
+a = yes
+b = off
+c = 1
+d = maybe
+`).Get().First
+
+	t.Run("string", func(t *testing.T) {
+		v, ok := doc.GetString("", "name")
+		assert.True(t, ok)
+		assert.Equal(t, "app", v)
+		_, ok = doc.GetString("server", "name")
+		assert.False(t, ok)
+		_, ok = doc.GetString("missing", "port")
+		assert.False(t, ok)
+	})
+
+	t.Run("int", func(t *testing.T) {
+		v, ok := doc.GetInt("server", "port")
+		assert.True(t, ok)
+		assert.Equal(t, 9090, v, "the last occurrence wins")
+		v, ok = doc.GetInt("server", "mask")
+		assert.True(t, ok)
+		assert.Equal(t, 31, v)
+		_, ok = doc.GetInt("server", "ratio")
+		assert.False(t, ok)
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		tests := []struct {
+			section, key string
+			value, ok    bool
+		}{
+			{"server", "debug", true, true},
+			{"flags", "a", true, true},
+			{"flags", "b", false, true},
+			{"flags", "c", true, true},
+			{"flags", "d", false, false},
+			{"flags", "e", false, false},
+		}
+		for _, tt := range tests {
+			v, ok := doc.GetBool(tt.section, tt.key)
+			assert.Equal(t, tt.ok, ok, tt.key)
+			assert.Equal(t, tt.value, v, tt.key)
+		}
+	})
+
+	t.Run("float", func(t *testing.T) {
+		v, ok := doc.GetFloat("server", "ratio")
+		assert.True(t, ok)
+		assert.Equal(t, 0.75, v)
+		_, ok = doc.GetFloat("flags", "a")
+		assert.False(t, ok)
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		v, ok := doc.GetDuration("server", "timeout")
+		assert.True(t, ok)
+		assert.Equal(t, 90*time.Second, v)
+		_, ok = doc.GetDuration("server", "port")
+		assert.False(t, ok)
+	})
+}
+
+func TestSub(t *testing.T) {
+	doc := ini.ParseINI(`[core]
+bare = false
+[remote "origin"]
+url = https://example.com/repo.git
+[remote "upstream"]
+url = https://example.com/upstream.git
+[server]
+port = 443
+[server.tls]
+cert = a.pem
+[server.tls.client]
+verify = yes
+`).Get().First
+
+	assert.Equal(t, []string{"remote", "origin"}, doc.Sections[1].Path())
+	assert.Equal(t, []string{"server", "tls", "client"}, doc.Sections[5].Path())
+
+	remotes := doc.Sub("remote")
+	assert.Equal(t, []ini.Section{
+		{Name: "origin", Entries: []ini.Entry{{Key: "url", Value: "https://example.com/repo.git"}}},
+		{Name: "upstream", Entries: []ini.Entry{{Key: "url", Value: "https://example.com/upstream.git"}}},
+	}, remotes.Sections)
+	assert.Nil(t, remotes.Global)
+
+	tls := doc.Sub("server").Sub("tls")
+	assert.Equal(t, []ini.Entry{{Key: "cert", Value: "a.pem"}}, tls.Global)
+	v, ok := tls.GetBool("client", "verify")
+	assert.True(t, ok)
+	assert.True(t, v)
+	assert.Equal(t, tls, doc.Sub("server.tls"))
+
+	url, ok := doc.Sub(`remote "origin"`).GetString("", "url")
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com/repo.git", url)
+	assert.Empty(t, doc.Sub("missing").Sections)
+}
+
+func TestGetStrings(t *testing.T) {
+	input := "tag[] = global\n[build]\nflags[] = -O2\nflags[] = -g\nsrc = a.c\nsrc = b.c\n[build]\nflags[] = -Wall\n"
+	doc := ini.ParseINI(input).Get().First
+
+	flags, ok := doc.GetStrings("build", "flags")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"-O2", "-g", "-Wall"}, flags)
+
+	src, ok := doc.GetStrings("build", "src")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a.c", "b.c"}, src)
+
+	tags, ok := doc.GetStrings("", "tag")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"global"}, tags)
+
+	_, ok = doc.GetStrings("build", "missing")
+	assert.False(t, ok)
+
+	t.Run("with a duplicate key policy", func(t *testing.T) {
+		doc := ini.ParseINI(input, ini.OnDuplicateKey(ini.DuplicateError))
+		assert.True(t, doc.IsNothing(), "plain repeated keys are rejected")
+
+		doc = ini.ParseINI(input, ini.OnDuplicateKey(ini.DuplicateKeepLast))
+		assert.True(t, doc.IsJust())
+		flags, _ := doc.Get().First.GetStrings("build", "flags")
+		assert.Equal(t, []string{"-O2", "-g", "-Wall"}, flags)
+		src, _ := doc.Get().First.GetStrings("build", "src")
+		assert.Equal(t, []string{"b.c"}, src)
+	})
+}
+
+func TestIgnoreCase(t *testing.T) {
+	input := "Name = app\n[Server]\nPort = 80\nTag[] = a\n[SERVER.TLS]\nCert = a.pem\n"
+	doc := ini.ParseINI(input, ini.IgnoreCase()).Get().First
+	assert.True(t, doc.IgnoreCase)
+	assert.Equal(t, "Server", doc.Sections[0].Name, "the casing is kept")
+	assert.Equal(t, "Port", doc.Sections[0].Entries[0].Key)
+
+	port, ok := doc.GetInt("server", "PORT")
+	assert.True(t, ok)
+	assert.Equal(t, 80, port)
+	name, ok := doc.GetString("", "name")
+	assert.True(t, ok)
+	assert.Equal(t, "app", name)
+	tags, _ := doc.GetStrings("SERVER", "tag")
+	assert.Equal(t, []string{"a"}, tags)
+	cert, ok := doc.Sub("server").GetString("tls", "cert")
+	assert.True(t, ok)
+	assert.Equal(t, "a.pem", cert)
+
+	_, ok = ini.ParseINI(input).Get().First.GetInt("server", "PORT")
+	assert.False(t, ok, "names are case-sensitive by default")
+
+	t.Run("duplicates", func(t *testing.T) {
+		result := ini.ParseINI("[s]\nkey = 1\nKEY = 2\n", ini.IgnoreCase(), ini.OnDuplicateKey(ini.DuplicateKeepLast))
+		assert.Equal(t, []ini.Entry{{Key: "key", Value: "2"}}, result.Get().First.Sections[0].Entries)
+	})
+
+	t.Run("document", func(t *testing.T) {
+		d, err := ini.ParseDocument(input, ini.IgnoreCase())
+		assert.NoError(t, err)
+		d.Set("server", "port", "8080")
+		assert.True(t, d.Delete("server", "TAG[]"))
+		assert.Equal(t, "Name = app\n[Server]\nPort = 8080\n[SERVER.TLS]\nCert = a.pem\n", d.String())
+	})
+}
+
+func TestSection(t *testing.T) {
+	doc := ini.ParseINI("name = app\n[server]\nhost = a\nport = 80\n[log]\nlevel = info\n[server]\nport = 8080\n").Get().First
+
+	s, ok := doc.Section("server")
+	assert.True(t, ok)
+	assert.Equal(t, "server", s.Name)
+	assert.Len(t, s.Entries, 3)
+	v, ok := s.Get("port")
+	assert.True(t, ok)
+	assert.Equal(t, "8080", v, "the last occurrence wins")
+	v, _ = s.Get("host")
+	assert.Equal(t, "a", v)
+	_, ok = s.Get("PORT")
+	assert.False(t, ok)
+
+	g, ok := doc.Section("")
+	assert.True(t, ok)
+	v, _ = g.Get("name")
+	assert.Equal(t, "app", v)
+
+	_, ok = doc.Section("missing")
+	assert.False(t, ok)
+
+	t.Run("indexed at parse time", func(t *testing.T) {
+		doc, err := ini.Parse("name = app\n[server]\nhost = a\nport = 80\n")
+		assert.NoError(t, err)
+		assert.Equal(t, ini.Ini{
+			Global:   []ini.Entry{{Key: "name", Value: "app"}},
+			Sections: []ini.Section{{Name: "server", Entries: []ini.Entry{{Key: "host", Value: "a"}, {Key: "port", Value: "80"}}}},
+		}, doc, "the index is not part of the document")
+		// Only the entries returned by Section are allocated, not the index
+		allocs := testing.AllocsPerRun(100, func() {
+			s, _ := doc.Section("server")
+			s.Get("port")
+			s.Get("host")
+		})
+		assert.Equal(t, 1.0, allocs)
+	})
+
+	t.Run("ignore case", func(t *testing.T) {
+		doc := ini.ParseINI("[Server]\nPort = 80\n", ini.IgnoreCase()).Get().First
+		s, ok := doc.Section("SERVER")
+		assert.True(t, ok)
+		v, ok := s.Get("port")
+		assert.True(t, ok)
+		assert.Equal(t, "80", v)
+	})
+
+	t.Run("edited document", func(t *testing.T) {
+		doc := ini.ParseINI("name = app\n[server]\nport = 80\n[log]\nlevel = info\n").Get().First
+		doc.Sections[1].Name = "logging"
+		doc.Sections[0].Entries[0].Key = "listen"
+		doc.Global = append(doc.Global, ini.Entry{Key: "env", Value: "prod"})
+		doc.Sections[0].Entries = append(doc.Sections[0].Entries, ini.Entry{Key: "host", Value: "a"})
+		doc.Sections = append(doc.Sections, ini.Section{Name: "extra", Entries: []ini.Entry{{Key: "k", Value: "v"}}})
+
+		for _, tt := range []struct{ section, key, value string }{
+			{"", "env", "prod"},
+			{"server", "listen", "80"},
+			{"server", "host", "a"},
+			{"logging", "level", "info"},
+			{"extra", "k", "v"},
+		} {
+			v, ok := doc.Lookup(tt.section, tt.key)
+			assert.True(t, ok, tt.key)
+			assert.Equal(t, tt.value, v)
+			s, ok := doc.Section(tt.section)
+			assert.True(t, ok, tt.section)
+			v, _ = s.Get(tt.key)
+			assert.Equal(t, tt.value, v)
+		}
+		_, ok := doc.Section("log")
+		assert.False(t, ok)
+		_, ok = doc.Lookup("server", "port")
+		assert.False(t, ok)
+
+		s, _ := doc.Section("server")
+		s.Entries[0].Key = "bind"
+		_, ok = s.Get("listen")
+		assert.False(t, ok, "the index is not trusted after the key changed")
+		s.Entries[1].Key = "bind"
+		v, _ := s.Get("bind")
+		assert.Equal(t, "a", v, "a later entry renamed to the key wins")
+		s.Entries = append(s.Entries, ini.Entry{Key: "host", Value: "b"})
+		v, _ = s.Get("host")
+		assert.Equal(t, "b", v, "the index is not trusted after entries were added")
+		v, ok = ini.Section{Entries: []ini.Entry{{Key: "k", Value: "1"}, {Key: "k", Value: "2"}}}.Get("k")
+		assert.True(t, ok)
+		assert.Equal(t, "2", v)
+	})
+}
@@ -1,15 +1,36 @@
 package ini
 
 type Ini struct {
+	// Global holds the entries before the first section header.
+	Global   []Entry
 	Sections []Section
+	// IgnoreCase makes Lookup, the typed accessors and Sub match section names and keys
+	// case-insensitively, as Windows does. It is set by the IgnoreCase option.
+	IgnoreCase bool
+	// Typed holds the global entries with typed values if the document was parsed with the
+	// InferTypes option, and is nil otherwise.
+	Typed []TypedEntry
 }
 
 type Section struct {
 	Name    string
 	Entries []Entry
+	// Typed holds the entries with typed values, like Ini.Typed.
+	Typed []TypedEntry
+	// keys is the index of the keys of a section returned by Ini.Section, from the index of its
+	// document. The parsers leave it nil, so that parsed sections compare equal to sections built
+	// by hand.
+	keys *keyIndex
 }
 
 type Entry struct {
 	Key   string
 	Value string
 }
+
+// TypedEntry is an entry whose value has the type inferred by the InferTypes option:
+// an int64, a float64, a bool or a string.
+type TypedEntry struct {
+	Key   string
+	Value any
+}
@@ -0,0 +1,114 @@
+package ini
+
+import "slices"
+
+// SectionDiff holds the differences between two INI documents in one section.
+type SectionDiff struct {
+	// Section is the name of the section, or "" for the global entries.
+	Section string
+	// Added are the entries only found in the second document.
+	Added []Entry
+	// Removed are the entries only found in the first document.
+	Removed []Entry
+	// Changed are the keys set to a different value in the second document.
+	Changed []Change
+}
+
+// Change is a key whose value differs between two INI documents.
+type Change struct {
+	Key      string
+	Old, New string
+}
+
+// Diff compares the INI documents a and b, e.g. the configuration of two environments, and returns
+// their differences grouped by section, for the global entries first and then for the sections in
+// the order they first occur in a and then in b. Sections without differences are left out, as
+// are sections without entries, which have no keys to compare.
+//
+// The documents are compared by the values Lookup finds, so repeated sections are merged as by Merge,
+// and names are compared like a compares them, ignoring case if a has IgnoreCase set. A key with a
+// single value in both documents is reported as changed if its value differs. The values of keys
+// with several values, such as "key[]" arrays, are matched up regardless of their order instead, and
+// those without a match in the other document are reported as removed or added.
+func Diff(a, b Ini) []SectionDiff {
+	b.IgnoreCase = a.IgnoreCase
+	a, b = Merge(a), Merge(b)
+	names := []string{""}
+	for _, s := range slices.Concat(a.Sections, b.Sections) {
+		if !slices.ContainsFunc(names[1:], func(name string) bool { return a.match(name, s.Name) }) {
+			names = append(names, s.Name)
+		}
+	}
+	var diffs []SectionDiff
+	for _, name := range names {
+		d := a.diffEntries(a.entries(name), b.entries(name))
+		if len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0 {
+			d.Section = name
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs
+}
+
+// entries returns the entries of the named section, or the global entries for the empty name.
+func (ini Ini) entries(section string) []Entry {
+	if section == "" {
+		return ini.Global
+	}
+	for _, s := range ini.Sections {
+		if ini.match(s.Name, section) {
+			return s.Entries
+		}
+	}
+	return nil
+}
+
+// diffEntries compares the entries a and b of a section, as described for Diff.
+func (ini Ini) diffEntries(a, b []Entry) SectionDiff {
+	var d SectionDiff
+	var keys []string
+	for _, e := range slices.Concat(a, b) {
+		if !slices.ContainsFunc(keys, func(k string) bool { return ini.match(k, e.Key) }) {
+			keys = append(keys, e.Key)
+		}
+	}
+	of := func(entries []Entry, key string) []Entry {
+		var out []Entry
+		for _, e := range entries {
+			if ini.match(e.Key, key) {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+	for _, key := range keys {
+		ka, kb := of(a, key), of(b, key)
+		if len(ka) == 1 && len(kb) == 1 {
+			if ka[0].Value != kb[0].Value {
+				d.Changed = append(d.Changed, Change{Key: key, Old: ka[0].Value, New: kb[0].Value})
+			}
+			continue
+		}
+		matched := make([]bool, len(kb))
+		for _, e := range ka {
+			i := -1
+			for j, f := range kb {
+				if !matched[j] && f.Value == e.Value {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				d.Removed = append(d.Removed, e)
+				continue
+			}
+			matched[i] = true
+		}
+		for i, e := range kb {
+			if !matched[i] {
+				d.Added = append(d.Added, e)
+			}
+		}
+	}
+	return d
+}
@@ -0,0 +1,39 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	parse := func(s string, opts ...ini.Option) ini.Ini {
+		return ini.ParseINI(s, opts...).Get().First
+	}
+	staging := parse("env = staging\n[db]\nhost = db.staging\nport = 5432\n[hosts]\nnames[] = a\nnames[] = b\n[cache]\nsize = 10\n")
+	prod := parse("env = prod\ndebug = false\n[hosts]\nnames[] = b\nnames[] = c\n[db]\nhost = db.prod\nport = 5432\n[db]\npool = 20\n[log]\nlevel = warn\n")
+
+	assert.Equal(t, []ini.SectionDiff{
+		{Added: []ini.Entry{{Key: "debug", Value: "false"}}, Changed: []ini.Change{{Key: "env", Old: "staging", New: "prod"}}},
+		{Section: "db", Added: []ini.Entry{{Key: "pool", Value: "20"}}, Changed: []ini.Change{{Key: "host", Old: "db.staging", New: "db.prod"}}},
+		{Section: "hosts", Added: []ini.Entry{{Key: "names[]", Value: "c"}}, Removed: []ini.Entry{{Key: "names[]", Value: "a"}}},
+		{Section: "cache", Removed: []ini.Entry{{Key: "size", Value: "10"}}},
+		{Section: "log", Added: []ini.Entry{{Key: "level", Value: "warn"}}},
+	}, ini.Diff(staging, prod))
+
+	t.Run("equal", func(t *testing.T) {
+		assert.Nil(t, ini.Diff(prod, prod))
+		assert.Nil(t, ini.Diff(parse("[a]\nk = 1\n[b]\n"), parse("[a]\nk = 2\n[a]\nk = 1\n")))
+		assert.Nil(t, ini.Diff(parse("[s]\nk[] = 1\nk[] = 2\n"), parse("[s]\nk[] = 2\nk[] = 1\n")))
+	})
+
+	t.Run("ignore case", func(t *testing.T) {
+		a := parse("[Server]\nPort = 80\n", ini.IgnoreCase())
+		assert.Nil(t, ini.Diff(a, parse("[server]\nport = 80\n")))
+		assert.Equal(t, []ini.SectionDiff{
+			{Section: "Server", Changed: []ini.Change{{Key: "Port", Old: "80", New: "8080"}}},
+		}, ini.Diff(a, parse("[SERVER]\nPORT = 8080\n")))
+		assert.Len(t, ini.Diff(parse("[s]\nk = 1\n"), parse("[S]\nk = 1\n")), 2)
+	})
+}
@@ -0,0 +1,272 @@
+package ini
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Document is an INI document that keeps the text of every line, so that a file maintained by
+// hand can be edited with Set and Delete and written back with String with its comments, blank
+// lines, ordering and spacing intact. Lines that are not edited are written exactly as they were read.
+type Document struct {
+	c     *config
+	lines []docLine
+	// newline is the line break of new lines: "\r\n" if the source uses it, "\n" otherwise.
+	newline string
+}
+
+// docLine is a line of a document, or all the lines of an entry with continuation lines.
+type docLine struct {
+	// text is the source text, including the final line break.
+	text string
+	// section is the name of the section the line belongs to, or "" before the first section header.
+	section string
+	// isHeader reports whether the line is the header of section.
+	isHeader bool
+	// entry is the entry on the line, or nil if the line is a header, a comment or blank.
+	entry *Entry
+	// include is the file named by an include directive on the line, see LoadFile.
+	include string
+	// line is the 1-based number of the (first) line in the source.
+	line int
+}
+
+// errMalformedLine is reported for a line that is neither a section header, an entry, a comment nor blank.
+var errMalformedLine = errors.New("expected section header, entry or comment")
+
+// ParseDocument parses the INI document s into an editable Document.
+// It accepts the same documents as IniParse with the same options, and reports the malformed
+// lines like Parse.
+func ParseDocument(s string, opts ...Option) (*Document, error) {
+	return newConfig(opts).document(s, "ini")
+}
+
+// document parses the INI document s into a Document according to the configuration c.
+// Malformed lines are reported as *parser.Error values with the given source.
+func (c *config) document(s, source string) (*Document, error) {
+	if err := c.checkSize(len(s)); err != nil {
+		return nil, err
+	}
+	d := &Document{c: c, newline: "\n"}
+	if strings.Contains(s, "\r\n") {
+		d.newline = "\r\n"
+	}
+	blank := parser.Fmap(parser.OmitLeft(iBlanks(), iLineEnd()), func(struct{}) string { return "" })
+	other := parser.OrElse(IComment(), blank)
+	header := c.header()
+	entry := c.entry()
+	include := iInclude()
+	// line parses a section header or an entry, to describe why a line is neither
+	line := parser.OrElse(
+		parser.Fmap(header, func(string) struct{} { return struct{}{} }),
+		parser.Fmap(entry, func(Entry) struct{} { return struct{}{} }),
+	)
+	section := ""
+	// entries are those of the current section, checked against the duplicate key policy
+	var entries []Entry
+	var errs []error
+	pos := 0
+	if strings.HasPrefix(s, "\ufeff") {
+		// Keep the byte order mark to write it back
+		pos = len("\ufeff")
+		d.lines = append(d.lines, docLine{text: s[:pos], line: 1})
+	}
+	for pos < len(s) {
+		rest := s[pos:]
+		l := docLine{section: section, line: strings.Count(s[:pos], "\n") + 1}
+		if m := other.Parse(rest); m.IsJust() {
+			rest = m.Get().Second
+		} else if m := include.Parse(rest); c.includes && m.IsJust() {
+			l.include = m.Get().First
+			rest = m.Get().Second
+		} else if m := header.Parse(rest); m.IsJust() {
+			section, entries = m.Get().First, nil
+			l.section, l.isHeader = section, true
+			rest = m.Get().Second
+		} else if m := entry.Parse(rest); m.IsJust() {
+			e := m.Get().First
+			l.entry = &e
+			rest = m.Get().Second
+			var err error
+			if entries, err = c.addEntry(entries, e); err != nil {
+				key := pos + len(s[pos:]) - len(strings.TrimLeft(s[pos:], " \t"))
+				errs = append(errs, parser.ErrorAt(source, s, key, err))
+			}
+		} else {
+			// Report the line and resume on the next one
+			text, after, _ := strings.Cut(rest, "\n")
+			_, err := parser.RunLine(line, s, parser.Line{Number: l.line, Offset: pos, Text: strings.TrimSuffix(text, "\r")})
+			if err == nil {
+				err = parser.ErrorAt(source, s, pos, errMalformedLine)
+			}
+			errs = append(errs, parser.Wrap(err, source))
+			rest = after
+		}
+		l.text = s[pos : len(s)-len(rest)]
+		d.lines = append(d.lines, l)
+		pos = len(s) - len(rest)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return d, nil
+}
+
+// String returns the text of the document with the edits made to it.
+func (d *Document) String() string {
+	var b strings.Builder
+	for _, l := range d.lines {
+		b.WriteString(l.text)
+	}
+	return b.String()
+}
+
+// Ini returns the sections and entries of the document, with repeated keys resolved by the
+// duplicate key policy of the document.
+func (d *Document) Ini() Ini {
+	ini := Ini{Sections: []Section{}, IgnoreCase: d.c.ignoreCase}
+	for _, l := range d.lines {
+		switch {
+		case l.isHeader:
+			ini.Sections = append(ini.Sections, Section{Name: l.section})
+		case l.entry == nil:
+		case len(ini.Sections) == 0:
+			ini.Global, _ = d.c.addEntry(ini.Global, *l.entry)
+		default:
+			last := &ini.Sections[len(ini.Sections)-1]
+			last.Entries, _ = d.c.addEntry(last.Entries, *l.entry)
+		}
+	}
+	return d.c.finish(ini)
+}
+
+// Get returns the value of key in the named section, like Ini.Lookup.
+func (d *Document) Get(section, key string) (string, bool) {
+	return d.Ini().Lookup(section, key)
+}
+
+// Set sets the value of key in the named section; the empty section name refers to the global
+// entries. If the key exists, its last occurrence is rewritten in place, keeping the spacing around
+// the delimiter and any inline comment. Otherwise a new entry is added after the last line of the
+// section, and a section that does not exist is added at the end of the document.
+func (d *Document) Set(section, key, value string) {
+	if i := d.find(section, key); i >= 0 {
+		d.lines[i].text = d.rewrite(d.lines[i].text, value)
+		d.lines[i].entry.Value = value
+		return
+	}
+	l := docLine{section: section, entry: &Entry{Key: key, Value: value}}
+	l.text = d.entryText(key+" = ", value, "")
+	at := -1
+	for i, dl := range d.lines {
+		if d.c.match(dl.section, section) && (dl.isHeader || dl.entry != nil) {
+			at = i + 1
+		}
+	}
+	switch {
+	case at >= 0:
+	case section == "":
+		// Before the first section header, or at the end of a document without any
+		at = len(d.lines)
+		for i, dl := range d.lines {
+			if dl.isHeader {
+				at = i
+				break
+			}
+		}
+	default:
+		at = len(d.lines)
+		d.terminate(at)
+		var lines []docLine
+		if at > 0 {
+			lines = append(lines, docLine{text: d.newline, section: d.lines[at-1].section})
+		}
+		lines = append(lines, docLine{text: "[" + section + "]" + d.newline, section: section, isHeader: true}, l)
+		d.lines = append(d.lines, lines...)
+		return
+	}
+	d.terminate(at)
+	d.lines = append(d.lines[:at], append([]docLine{l}, d.lines[at:]...)...)
+}
+
+// Delete removes every occurrence of key in the named section, including any continuation lines,
+// and reports whether there was one. The surrounding comments are kept.
+func (d *Document) Delete(section, key string) bool {
+	n := len(d.lines)
+	d.lines = slices.DeleteFunc(d.lines, func(l docLine) bool {
+		return l.entry != nil && d.c.match(l.section, section) && d.c.match(l.entry.Key, key)
+	})
+	return len(d.lines) < n
+}
+
+// find returns the index of the line holding the last occurrence of key in the named section, or -1.
+func (d *Document) find(section, key string) int {
+	at := -1
+	for i, l := range d.lines {
+		if l.entry != nil && d.c.match(l.section, section) && d.c.match(l.entry.Key, key) {
+			at = i
+		}
+	}
+	return at
+}
+
+// terminate ends the line before index at with a line break, if it is the last line of a
+// document that does not end with one.
+func (d *Document) terminate(at int) {
+	if at == 0 || at < len(d.lines) {
+		return
+	}
+	if l := &d.lines[at-1]; !strings.HasSuffix(l.text, "\n") {
+		l.text += d.newline
+	}
+}
+
+// rewrite returns the text of the entry line text with its value replaced by value.
+// The key, the delimiter with the spacing around it and any inline comment are kept.
+func (d *Document) rewrite(text, value string) string {
+	first, _, _ := strings.Cut(text, "\n")
+	first = strings.TrimRight(first, "\r")
+	eq := strings.IndexByte(first, '=')
+	start := eq + 1
+	for start < len(first) && isBlank(rune(first[start])) {
+		start++
+	}
+	comment := ""
+	if d.c.inlineComments {
+		if _, at := scanInlineComment(first[start:]); at >= 0 {
+			gap := len(strings.TrimRight(first[start:start+at], " \t"))
+			comment = first[start+gap:]
+		}
+	}
+	return d.entryText(first[:start], value, comment)
+}
+
+// entryText returns the text of an entry line made of prefix, which ends with the delimiter,
+// value and comment, which starts with the whitespace before the comment marker. A value
+// containing line breaks continues on indented lines, which need the MultilineValues option
+// to be read back.
+func (d *Document) entryText(prefix, value, comment string) string {
+	lines := strings.Split(value, "\n")
+	if d.c.inlineComments {
+		for i, l := range lines {
+			lines[i] = escapeInlineComment(l)
+		}
+	}
+	var text string
+	switch {
+	case comment == "":
+		text = strings.TrimRight(prefix+lines[0], " \t")
+	case lines[0] == "":
+		text = prefix + strings.TrimLeft(comment, " \t")
+	default:
+		text = prefix + lines[0] + comment
+	}
+	text += d.newline
+	for _, l := range lines[1:] {
+		text += "    " + l + d.newline
+	}
+	return text
+}
@@ -0,0 +1,133 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const userConfig = `# Global settings
+name   =   app
+
+; Database connection
+[db]
+host    = localhost   ; local only
+port    = 5432
+
+[cache]
+# no entries yet
+`
+
+func TestDocument(t *testing.T) {
+	t.Run("unchanged", func(t *testing.T) {
+		d, err := ini.ParseDocument(userConfig)
+		assert.NoError(t, err)
+		assert.Equal(t, userConfig, d.String())
+		want := ini.ParseINI(userConfig).Get().First
+		assert.Equal(t, want, d.Ini())
+	})
+
+	t.Run("set existing", func(t *testing.T) {
+		d, err := ini.ParseDocument(userConfig, ini.InlineComments())
+		assert.NoError(t, err)
+		d.Set("db", "host", "db.example.com")
+		d.Set("", "name", "tool")
+		d.Set("db", "port", "")
+		assert.Equal(t, `# Global settings
+name   =   tool
+
+; Database connection
+[db]
+host    = db.example.com   ; local only
+port    =
+
+[cache]
+# no entries yet
+`, d.String())
+		v, ok := d.Get("db", "host")
+		assert.True(t, ok)
+		assert.Equal(t, "db.example.com", v)
+	})
+
+	t.Run("set new", func(t *testing.T) {
+		d, err := ini.ParseDocument(userConfig)
+		assert.NoError(t, err)
+		d.Set("db", "user", "admin")
+		d.Set("cache", "size", "10")
+		d.Set("", "debug", "true")
+		d.Set("log", "level", "info")
+		assert.Equal(t, `# Global settings
+name   =   app
+debug = true
+
+; Database connection
+[db]
+host    = localhost   ; local only
+port    = 5432
+user = admin
+
+[cache]
+size = 10
+# no entries yet
+
+[log]
+level = info
+`, d.String())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		d, err := ini.ParseDocument(userConfig)
+		assert.NoError(t, err)
+		assert.True(t, d.Delete("db", "host"))
+		assert.False(t, d.Delete("db", "host"))
+		assert.False(t, d.Delete("cache", "port"))
+		assert.Equal(t, `# Global settings
+name   =   app
+
+; Database connection
+[db]
+port    = 5432
+
+[cache]
+# no entries yet
+`, d.String())
+	})
+
+	t.Run("inline comments", func(t *testing.T) {
+		d, err := ini.ParseDocument("a = 1 ; one\nb = 2\n", ini.InlineComments())
+		assert.NoError(t, err)
+		d.Set("", "a", "")
+		d.Set("", "b", "x ; y")
+		assert.Equal(t, "a = ; one\nb = x \\; y\n", d.String())
+		v, _ := d.Get("", "b")
+		assert.Equal(t, "x ; y", v)
+		assert.Equal(t, d.Ini(), ini.ParseINI(d.String(), ini.InlineComments()).Get().First)
+	})
+
+	t.Run("multi-line values", func(t *testing.T) {
+		d, err := ini.ParseDocument("[s]\r\npaths = a\r\n  b\r\nnext = 1", ini.MultilineValues())
+		assert.NoError(t, err)
+		d.Set("s", "paths", "x\ny")
+		d.Set("s", "last", "2")
+		assert.Equal(t, "[s]\r\npaths = x\r\n    y\r\nnext = 1\r\nlast = 2\r\n", d.String())
+		assert.Equal(t, d.Ini(), ini.ParseINI(d.String(), ini.MultilineValues()).Get().First)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		d, err := ini.ParseDocument("")
+		assert.NoError(t, err)
+		d.Set("s", "k", "v")
+		d.Set("", "g", "1")
+		assert.Equal(t, "g = 1\n[s]\nk = v\n", d.String())
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ini.ParseDocument("[s]\n\n  oops  \n")
+		var perr *parser.Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 3, perr.Line)
+		assert.EqualError(t, err, "ini: line 3, col 9: unexpected end of input, expected '='")
+	})
+}
@@ -0,0 +1,64 @@
+package ini
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// checkSize fails with a *parser.LimitError if a document of n bytes exceeds the limit of c.
+func (c *config) checkSize(n int) error {
+	if c.maxBytes > 0 && n > c.maxBytes {
+		return &parser.LimitError{Limit: parser.LimitBytes, Max: c.maxBytes, Offset: c.maxBytes}
+	}
+	return nil
+}
+
+// read reads a document from r according to the configuration c, stopping once it exceeds the
+// size limit, and decodes it with decode.
+func (c *config) read(r io.Reader) (string, error) {
+	if c.maxBytes > 0 {
+		r = io.LimitReader(r, int64(c.maxBytes)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkSize(len(data)); err != nil {
+		return "", err
+	}
+	return decode(data), nil
+}
+
+// decode returns the text of a document in the encodings editors save INI files in: UTF-8, with or
+// without a byte order mark, UTF-16 with a byte order mark, as written by Windows tools, and the
+// Latin-1 code page of legacy Windows files, assumed for input that is not valid UTF-8.
+// The byte order mark of UTF-16 input is kept as a UTF-8 one.
+func decode(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return decodeUTF16(data, func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 })
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return decodeUTF16(data, func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) })
+	case utf8.Valid(data):
+		return string(data)
+	}
+	var b strings.Builder
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// decodeUTF16 decodes UTF-16 data whose code units are read by unit; a trailing odd byte is dropped.
+func decodeUTF16(data []byte, unit func([]byte) uint16) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = unit(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
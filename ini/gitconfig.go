@@ -0,0 +1,172 @@
+package ini
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// GitConfig parses documents in the dialect of Git configuration files such as .gitconfig:
+//   - a section header may name a subsection in double quotes, as in [remote "origin"], where
+//     "\"" and "\\" stand for a quote and a backslash; the section is named remote "origin",
+//     which Section.Path splits into ["remote", "origin"];
+//   - a key alone on its line, without '=', is short for "key = true";
+//   - in values, parts in double quotes keep their whitespace and comment markers, the escapes
+//     \n, \t, \b, \" and \\ are decoded, and a backslash at the end of a line continues the value
+//     on the next line; outside quotes, whitespace at the ends of a value is dropped and every
+//     other whitespace character becomes a space;
+//   - comments starting with ';' or '#' may follow headers and values on the same line.
+//
+// Keys must start with a letter and consist of letters, digits and '-'. Section names, except
+// for the subsection, and keys are case-insensitive in Git and are returned in lower case.
+func GitConfig() Option {
+	return func(c *config) {
+		c.git = true
+	}
+}
+
+// isGitName reports whether r may appear in the name of a section or a key of a Git configuration.
+func isGitName(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-'
+}
+
+// gitLineEnd parses the end of a line of a Git configuration: blanks and an optional comment.
+func gitLineEnd() parser.Parser[struct{}] {
+	comment := parser.ZeroOrOne(parser.OmitLeft(parser.Satisfy(isCommentMarker), parser.ZeroOrMore(parser.NotChar('\n'))))
+	return parser.OmitLeft(iBlanks(), parser.OmitLeft(comment, iLineEnd()))
+}
+
+// gitHeader parses a section header of a Git configuration, with an optional quoted subsection,
+// and returns the name of the section.
+func gitHeader() parser.Parser[string] {
+	name := parser.OmitLeft(iBlanks(), parser.Fmap(
+		parser.OneOrMore(parser.Satisfy(func(r rune) bool { return isGitName(r) || r == '.' })),
+		func(rs []rune) string { return strings.ToLower(parser.Text(rs)) },
+	))
+	escaped := parser.OmitLeft(parser.Char('\\'), parser.NotChar('\n'))
+	plain := parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' && r != '\n' })
+	sub := parser.OmitLeft(
+		parser.OneOrMore(parser.Satisfy(isBlank)),
+		parser.Between(parser.Char('"'), parser.ZeroOrMore(parser.OrElse(escaped, plain)), parser.Char('"')),
+	)
+	return parser.Between(
+		parser.Char('['),
+		parser.Bind(name, func(name string) parser.Parser[string] {
+			return parser.Fmap(parser.ZeroOrOne(sub), func(sub parser.Maybe[[]rune]) string {
+				if sub.IsNothing() {
+					return name
+				}
+				return name + ` "` + parser.Text(sub.Get()) + `"`
+			})
+		}),
+		parser.OmitLeft(iBlanks(), parser.Char(']')),
+	)
+}
+
+// gitEntry parses an entry of a Git configuration according to the configuration c.
+func (c *config) gitEntry() parser.Parser[Entry] {
+	key := parser.SatisfyWith(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(isGitName)), func(rs []rune) string { return strings.ToLower(parser.Text(rs)) }),
+		func(key string) bool { return key[0] >= 'a' && key[0] <= 'z' },
+	)
+	value := parser.OrElse(
+		parser.OmitLeft(parser.Char('='), gitValue()),
+		// A key without a value is set to true
+		parser.Fmap(gitLineEnd(), func(struct{}) string { return "true" }),
+	)
+	return parser.OmitLeft(iSkip(), parser.OmitLeft(iBlanks(), parser.Bind(key, func(key string) parser.Parser[Entry] {
+		return parser.Fmap(parser.OmitLeft(iBlanks(), value), func(value string) Entry {
+			if c.lookupEnv != nil {
+				value = expand(value, c.lookupEnv)
+			}
+			return Entry{Key: key, Value: value}
+		})
+	})))
+}
+
+// gitValue parses the value of an entry of a Git configuration up to the end of its line, or
+// of its last line if it is continued, as described for GitConfig.
+func gitValue() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		// fail records the failure at the offset i of s
+		fail := func(i int, expected string) parser.StateFuncRet[string] {
+			if at, ok := st.Advance(i); ok {
+				at.Fail(expected)
+			}
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		var b strings.Builder
+		quoted := false
+		// spaces counts the whitespace outside quotes not yet written, which is dropped at the ends
+		spaces := 0
+		i := 0
+	scan:
+		for i < len(s) {
+			c := s[i]
+			switch {
+			case c == '\n' && quoted:
+				return fail(i, `'"'`)
+			case c == '\n':
+				i++
+				break scan
+			case !quoted && isCommentMarker(rune(c)):
+				if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+					i += end + 1
+				} else {
+					i = len(s)
+				}
+				break scan
+			case !quoted && isBlank(rune(c)):
+				if b.Len() > 0 {
+					spaces++
+				}
+				i++
+				continue
+			}
+			b.WriteString(strings.Repeat(" ", spaces))
+			spaces = 0
+			if c == '"' {
+				quoted = !quoted
+				i++
+				continue
+			}
+			if c != '\\' {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			next := byte(0)
+			if i+1 < len(s) {
+				next = s[i+1]
+			}
+			switch next {
+			case '\n':
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '"', '\\':
+				b.WriteByte(next)
+			case '\r':
+				if !strings.HasPrefix(s[i+2:], "\n") {
+					return fail(i, "escape sequence")
+				}
+				i++
+			default:
+				return fail(i, "escape sequence")
+			}
+			i += 2
+		}
+		if quoted {
+			return fail(i, `'"'`)
+		}
+		next, ok := st.Advance(i)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(b.String(), next))
+	})
+}
@@ -0,0 +1,80 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitConfig(t *testing.T) {
+	input := `[core]
+	bare = false
+	fileMode ; executable bit
+[remote "origin"]
+	url = https://example.com/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[Branch "Main"] # default
+	remote = origin
+[alias]
+	lg = log --graph \
+	     --oneline
+	say = "echo \"hi; there\"" # greet
+	tab = a\tb  "  c  "
+`
+	want := []ini.Section{
+		{Name: "core", Entries: []ini.Entry{{Key: "bare", Value: "false"}, {Key: "filemode", Value: "true"}}},
+		{Name: `remote "origin"`, Entries: []ini.Entry{
+			{Key: "url", Value: "https://example.com/repo.git"},
+			{Key: "fetch", Value: "+refs/heads/*:refs/remotes/origin/*"},
+		}},
+		{Name: `branch "Main"`, Entries: []ini.Entry{{Key: "remote", Value: "origin"}}},
+		{Name: "alias", Entries: []ini.Entry{
+			{Key: "lg", Value: "log --graph       --oneline"},
+			{Key: "say", Value: `echo "hi; there"`},
+			{Key: "tab", Value: "a\tb    c  "},
+		}},
+	}
+
+	doc, err := ini.Parse(input, ini.GitConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, want, doc.Sections)
+	assert.Equal(t, []string{"branch", "Main"}, doc.Sections[2].Path())
+	mode, ok := doc.GetBool("core", "filemode")
+	assert.True(t, ok)
+	assert.True(t, mode)
+
+	result := ini.IIni(ini.GitConfig()).Parse(input)
+	assert.True(t, result.IsJust())
+	assert.Equal(t, want, result.Get().First.Sections)
+
+	d, err := ini.ParseDocument(input, ini.GitConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, input, d.String())
+
+	t.Run("subsection escapes", func(t *testing.T) {
+		doc, err := ini.Parse("[remote \"a\\\"b\\\\c\"]\nurl = x", ini.GitConfig())
+		assert.NoError(t, err)
+		assert.Equal(t, `remote "a"b\c"`, doc.Sections[0].Name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+			want  string
+		}{
+			{"unterminated quote", "[s]\nk = \"abc\n", `ini: line 2, col 9: unexpected end of input, expected '"'`},
+			{"bad escape", "[s]\nk = a\\qb\n", `ini: line 2, col 6: unexpected '\\', expected escape sequence`},
+			{"bad key", "[s]\n1k = v\n", `ini: line 2, col 3: unexpected ' '`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ini.Parse(tt.input, ini.GitConfig())
+				assert.ErrorAs(t, err, new(*parser.Error))
+				assert.EqualError(t, err, tt.want)
+			})
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ErrIncludeCycle is reported for a file that includes itself, directly or through other files.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// LoadFile reads and parses the INI file name, processing its include directives.
+//
+// A line "!include other.ini" loads the named file, resolved relative to the directory of the
+// file containing the directive, and merges it at that point: its global entries are added to
+// the section the directive appears in, and the entries of each of its sections to the first
+// section of the same name, or to a new section at the end. Included files may include others
+// in turn; a file including itself, directly or indirectly, fails with ErrIncludeCycle. Errors
+// in a file are reported with its name, as a *parser.Error whose Source is the name where a line
+// is at fault.
+//
+// Files are decoded from UTF-8, UTF-16 with a byte order mark or, if they are not valid UTF-8,
+// Latin-1. The MaxBytes option limits the size of each file.
+func LoadFile(name string, opts ...Option) (Ini, error) {
+	l := loader{
+		open: func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		},
+		resolve: func(from, name string) string {
+			if filepath.IsAbs(name) {
+				return filepath.Clean(name)
+			}
+			return filepath.Join(filepath.Dir(from), name)
+		},
+	}
+	if abs, err := filepath.Abs(name); err == nil {
+		name = abs
+	}
+	return l.load(name, opts)
+}
+
+// LoadFS reads and parses the INI file name in fsys like LoadFile. The names in include
+// directives are slash-separated; absolute names are resolved from the root of fsys.
+func LoadFS(fsys fs.FS, name string, opts ...Option) (Ini, error) {
+	l := loader{
+		open: func(name string) (io.ReadCloser, error) {
+			return fsys.Open(name)
+		},
+		resolve: func(from, name string) string {
+			if strings.HasPrefix(name, "/") {
+				return path.Clean(name[1:])
+			}
+			return path.Join(path.Dir(from), name)
+		},
+	}
+	return l.load(path.Clean(name), opts)
+}
+
+// LoadReader reads and parses an INI document from r, decoding it like LoadFile. As the document
+// has no name to resolve them from, include directives are not accepted.
+func LoadReader(r io.Reader, opts ...Option) (Ini, error) {
+	c := newConfig(opts)
+	text, err := c.read(r)
+	if err != nil {
+		return Ini{}, err
+	}
+	return c.parse(text)
+}
+
+// loader loads INI files with include directives from a file system.
+type loader struct {
+	// open opens a file for reading.
+	open func(name string) (io.ReadCloser, error)
+	// resolve returns the name of the file included by a directive in the file from.
+	resolve func(from, name string) string
+	// stack holds the names of the files being loaded, outermost first.
+	stack []string
+}
+
+// load reads, parses and merges the file name and the files it includes.
+func (l *loader) load(name string, opts []Option) (Ini, error) {
+	if slices.Contains(l.stack, name) {
+		return Ini{}, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(l.stack, name), " -> "))
+	}
+	f, err := l.open(name)
+	if err != nil {
+		return Ini{}, err
+	}
+	c := newConfig(opts)
+	c.includes = true
+	text, err := c.read(f)
+	f.Close()
+	if err != nil {
+		// The errors of the file system name the file already
+		if errors.As(err, new(*fs.PathError)) {
+			return Ini{}, err
+		}
+		return Ini{}, fmt.Errorf("%s: %w", name, err)
+	}
+	d, err := c.document(text, name)
+	if err != nil {
+		return Ini{}, err
+	}
+	l.stack = append(l.stack, name)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	ini := Ini{Sections: []Section{}, IgnoreCase: c.ignoreCase}
+	// current is the index of the section lines are added to, or -1 for the global entries.
+	current := -1
+	offset := 0
+	for _, dl := range d.lines {
+		switch {
+		case dl.isHeader:
+			ini.Sections = append(ini.Sections, Section{Name: dl.section})
+			current = len(ini.Sections) - 1
+		case dl.entry != nil:
+			err = ini.addEntries(c, current, *dl.entry)
+		case dl.include != "":
+			var inc Ini
+			if inc, err = l.load(l.resolve(name, dl.include), opts); err != nil {
+				if errors.Is(err, ErrIncludeCycle) {
+					return Ini{}, err
+				}
+				break
+			}
+			err = ini.addEntries(c, current, inc.Global...)
+			for _, s := range inc.Sections {
+				if err != nil {
+					break
+				}
+				i := slices.IndexFunc(ini.Sections, func(t Section) bool { return c.match(t.Name, s.Name) })
+				if i < 0 {
+					ini.Sections = append(ini.Sections, Section{Name: s.Name})
+					i = len(ini.Sections) - 1
+				}
+				err = ini.addEntries(c, i, s.Entries...)
+			}
+		}
+		if err != nil {
+			start := offset + len(dl.text) - len(strings.TrimLeft(dl.text, " \t"))
+			return Ini{}, parser.ErrorAt(name, text, start, err)
+		}
+		offset += len(dl.text)
+	}
+	return c.finish(ini), nil
+}
+
+// addEntries adds entries to the section at index i, or to the global entries if i is negative,
+// resolving repeated keys with the duplicate key policy of c.
+func (ini *Ini) addEntries(c *config, i int, entries ...Entry) error {
+	target := &ini.Global
+	if i >= 0 {
+		target = &ini.Sections[i].Entries
+	}
+	for _, e := range entries {
+		var err error
+		if *target, err = c.addEntry(*target, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
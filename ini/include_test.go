@@ -0,0 +1,145 @@
+package ini_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.ini":          {Data: []byte("name = app\n[db]\nhost = localhost\n!include conf.d/db.ini\nport = 5432\n[log]\n!include /common.ini\n")},
+		"conf.d/db.ini":    {Data: []byte("user = admin\n[db]\npool = 4\n[cache]\nsize = 10\n!include ../shared/cache.ini\n")},
+		"shared/cache.ini": {Data: []byte("ttl = 60\n")},
+		"common.ini":       {Data: []byte("level = info\n")},
+		"loop/a.ini":       {Data: []byte("!include b.ini\n")},
+		"loop/b.ini":       {Data: []byte("[x]\n!include a.ini\n")},
+		"broken.ini":       {Data: []byte("[s]\n!include missing.ini\n")},
+		"bad.ini":          {Data: []byte("[s]\n!include bad-line.ini\n")},
+		"bad-line.ini":     {Data: []byte("a = 1\noops\n")},
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		doc, err := ini.LoadFS(fsys, "app.ini")
+		assert.NoError(t, err)
+		assert.Equal(t, ini.Ini{
+			Global: []ini.Entry{{Key: "name", Value: "app"}},
+			Sections: []ini.Section{
+				{Name: "db", Entries: []ini.Entry{
+					{Key: "host", Value: "localhost"},
+					{Key: "user", Value: "admin"},
+					{Key: "pool", Value: "4"},
+					{Key: "port", Value: "5432"},
+				}},
+				{Name: "cache", Entries: []ini.Entry{{Key: "size", Value: "10"}, {Key: "ttl", Value: "60"}}},
+				{Name: "log", Entries: []ini.Entry{{Key: "level", Value: "info"}}},
+			},
+		}, doc)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := ini.LoadFS(fsys, "loop/a.ini")
+		assert.ErrorIs(t, err, ini.ErrIncludeCycle)
+		assert.EqualError(t, err, "include cycle: loop/a.ini -> loop/b.ini -> loop/a.ini")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ini.LoadFS(fsys, "broken.ini")
+		var perr *parser.Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 2, perr.Line)
+		assert.ErrorContains(t, err, "broken.ini: line 2, col 1: ")
+	})
+
+	t.Run("malformed included file", func(t *testing.T) {
+		_, err := ini.LoadFS(fsys, "bad.ini")
+		assert.ErrorContains(t, err, `bad-line.ini: line 2, col 5: unexpected end of input`)
+	})
+
+	t.Run("directive outside of files", func(t *testing.T) {
+		assert.True(t, ini.ParseINI("!include other.ini").IsNothing())
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.ini"), []byte("[a]\n!include sub/extra.ini\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "extra.ini"), []byte("k = v\n"), 0o644))
+
+	doc, err := ini.LoadFile(filepath.Join(dir, "main.ini"))
+	assert.NoError(t, err)
+	v, ok := doc.GetString("a", "k")
+	assert.True(t, ok)
+	assert.Equal(t, "v", v)
+
+	_, err = ini.LoadFile(filepath.Join(dir, "missing.ini"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadFSDuplicates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.ini":  {Data: []byte("[s]\nk = 1\n!include other.ini\n")},
+		"other.ini": {Data: []byte("[s]\nk = 2\n")},
+	}
+	doc, err := ini.LoadFS(fsys, "main.ini", ini.OnDuplicateKey(ini.DuplicateKeepLast))
+	assert.NoError(t, err)
+	assert.Equal(t, []ini.Entry{{Key: "k", Value: "2"}}, doc.Sections[0].Entries)
+
+	_, err = ini.LoadFS(fsys, "main.ini", ini.OnDuplicateKey(ini.DuplicateError))
+	assert.ErrorIs(t, err, ini.ErrDuplicateKey)
+	assert.EqualError(t, err, `main.ini: line 3, col 1: duplicate key "k"`)
+}
+
+func TestLoadReader(t *testing.T) {
+	want := ini.Ini{Sections: []ini.Section{{Name: "caf\u00e9", Entries: []ini.Entry{{Key: "k", Value: "v"}}}}}
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"UTF-8", "[caf\u00e9]\nk = v\n"},
+		{"UTF-8 with BOM", "\ufeff[caf\u00e9]\r\nk = v\r\n"},
+		{"UTF-16LE", "\xff\xfe[\x00c\x00a\x00f\x00\xe9\x00]\x00\n\x00k\x00=\x00v\x00"},
+		{"UTF-16BE", "\xfe\xff\x00[\x00c\x00a\x00f\x00\xe9\x00]\x00\n\x00k\x00=\x00v"},
+		{"Latin-1", "[caf\xe9]\nk = v\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ini.LoadReader(iotest.OneByteReader(strings.NewReader(tt.data)))
+			assert.NoError(t, err)
+			assert.Equal(t, want, doc)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ini.LoadReader(strings.NewReader("[s]\noops\n"))
+		assert.EqualError(t, err, `ini: line 2, col 5: unexpected end of input, expected '='`)
+		_, err = ini.LoadReader(iotest.TimeoutReader(strings.NewReader("[s]\n")))
+		assert.ErrorIs(t, err, iotest.ErrTimeout)
+	})
+}
+
+func TestMaxBytes(t *testing.T) {
+	_, err := ini.LoadReader(strings.NewReader("[s]\nk = v\n"), ini.MaxBytes(8))
+	assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	_, err = ini.LoadReader(strings.NewReader("[s]\nk = v\n"), ini.MaxBytes(10))
+	assert.NoError(t, err)
+
+	_, err = ini.Parse("[s]\nk = v\n", ini.MaxBytes(8))
+	assert.EqualError(t, err, "parser: maximum input size of 8 bytes exceeded at offset 8")
+
+	fsys := fstest.MapFS{
+		"main.ini": {Data: []byte("[s]\n!include big.ini\n")},
+		"big.ini":  {Data: []byte("k = " + strings.Repeat("x", 100) + "\n")},
+	}
+	_, err = ini.LoadFS(fsys, "main.ini", ini.MaxBytes(50))
+	assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	assert.ErrorContains(t, err, "main.ini: line 2, col 1: big.ini: parser: maximum input size")
+}
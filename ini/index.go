@@ -0,0 +1,204 @@
+package ini
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"weak"
+)
+
+// keyIndex maps the keys of a section to the positions of their last entries, for Section.Get.
+type keyIndex struct {
+	// n is the number of entries when the index was built; the index is not used if it changed.
+	n          int
+	ignoreCase bool
+	keys       map[string]int
+}
+
+// add indexes entries, which follow the entries indexed so far.
+func (k *keyIndex) add(entries []Entry) {
+	for _, e := range entries {
+		k.keys[fold(e.Key, k.ignoreCase)] = k.n
+		k.n++
+	}
+}
+
+// fold returns the name as it is indexed: in lower case if case is ignored.
+func fold(name string, ignoreCase bool) string {
+	if ignoreCase {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// docIndex is the index of the sections and keys of a document, for Ini.Section.
+type docIndex struct {
+	// ignoreCase, global, names and sizes describe the document when it was indexed: whether it
+	// ignores case, its number of global entries, and the names and numbers of entries of its
+	// sections. The index is rebuilt if any of them changed.
+	ignoreCase bool
+	global     int
+	names      []string
+	sizes      []int
+	// sections maps the folded names of the sections to the positions of their occurrences.
+	sections map[string][]int
+	// globalKeys and keys index the keys of the global entries and of each section, with the
+	// entries of all its occurrences in order.
+	globalKeys *keyIndex
+	keys       map[string]*keyIndex
+}
+
+// newDocIndex builds the index of ini.
+func newDocIndex(ini Ini) *docIndex {
+	ic := ini.IgnoreCase
+	idx := &docIndex{
+		ignoreCase: ic,
+		global:     len(ini.Global),
+		names:      make([]string, len(ini.Sections)),
+		sizes:      make([]int, len(ini.Sections)),
+		sections:   make(map[string][]int, len(ini.Sections)),
+		globalKeys: &keyIndex{ignoreCase: ic, keys: make(map[string]int, len(ini.Global))},
+		keys:       make(map[string]*keyIndex, len(ini.Sections)),
+	}
+	idx.globalKeys.add(ini.Global)
+	for i, s := range ini.Sections {
+		idx.names[i], idx.sizes[i] = s.Name, len(s.Entries)
+		name := fold(s.Name, ic)
+		idx.sections[name] = append(idx.sections[name], i)
+		if idx.keys[name] == nil {
+			idx.keys[name] = &keyIndex{ignoreCase: ic, keys: make(map[string]int, len(s.Entries))}
+		}
+		idx.keys[name].add(s.Entries)
+	}
+	return idx
+}
+
+// current reports whether idx is still the index of ini. Entries whose key was changed in place
+// are caught by Section.Get.
+func (idx *docIndex) current(ini Ini) bool {
+	if idx.ignoreCase != ini.IgnoreCase || idx.global != len(ini.Global) || len(idx.names) != len(ini.Sections) {
+		return false
+	}
+	for i, s := range ini.Sections {
+		if s.Name != idx.names[i] || len(s.Entries) != idx.sizes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// indexKey identifies a document by the arrays of its global entries and sections, which its
+// copies share.
+type indexKey struct {
+	global   weak.Pointer[Entry]
+	sections weak.Pointer[Section]
+}
+
+// indexes holds the index of each indexed document. It is kept apart from the Ini, so that an
+// indexed document compares equal to one built by hand, and an index is dropped once the arrays
+// of its document are garbage collected.
+var indexes = struct {
+	sync.Mutex
+	m map[indexKey]*docIndex
+}{m: make(map[indexKey]*docIndex)}
+
+// first returns a pointer to the first element of the array of s, or nil if it has none.
+func first[T any](s []T) *T {
+	if cap(s) == 0 {
+		return nil
+	}
+	return &s[:1][0]
+}
+
+// reindex builds the index of ini. The parsers and loaders of this package index the documents
+// they return, and Section indexes other documents, and documents changed since they were
+// indexed, when it is first called on them.
+func (ini Ini) reindex() *docIndex {
+	idx := newDocIndex(ini)
+	global, sections := first(ini.Global), first(ini.Sections)
+	if global == nil && sections == nil {
+		return idx
+	}
+	key := indexKey{weak.Make(global), weak.Make(sections)}
+	indexes.Lock()
+	defer indexes.Unlock()
+	if _, ok := indexes.m[key]; !ok {
+		drop := func(key indexKey) {
+			indexes.Lock()
+			defer indexes.Unlock()
+			delete(indexes.m, key)
+		}
+		if global != nil {
+			runtime.AddCleanup(global, drop, key)
+		}
+		if sections != nil {
+			runtime.AddCleanup(sections, drop, key)
+		}
+	}
+	indexes.m[key] = idx
+	return idx
+}
+
+// index returns the index of ini, building it if ini is not indexed or changed since it was.
+func (ini Ini) index() *docIndex {
+	key := indexKey{weak.Make(first(ini.Global)), weak.Make(first(ini.Sections))}
+	indexes.Lock()
+	idx := indexes.m[key]
+	indexes.Unlock()
+	if idx != nil && idx.current(ini) {
+		return idx
+	}
+	return ini.reindex()
+}
+
+// Section returns the named section, and whether it was found. The empty name refers to the global
+// entries, which are always found. If a section occurs more than once, the result holds the entries
+// of all its occurrences in document order, so that its Get finds the values Lookup would find.
+// The section is found with the index of the document, built when it was parsed, and comes with
+// the index of its keys, so that reading many keys with Get does not scan the entries for each of
+// them. The index is rebuilt if sections or entries were added, removed or renamed since.
+func (ini Ini) Section(name string) (Section, bool) {
+	idx := ini.index()
+	if name == "" {
+		return Section{Entries: append([]Entry(nil), ini.Global...), keys: idx.globalKeys}, true
+	}
+	name = fold(name, ini.IgnoreCase)
+	at, ok := idx.sections[name]
+	if !ok {
+		return Section{}, false
+	}
+	s := ini.Sections[at[0]]
+	s.Entries = nil
+	for _, i := range at {
+		s.Entries = append(s.Entries, ini.Sections[i].Entries...)
+	}
+	s.keys = idx.keys[name]
+	return s, true
+}
+
+// Get returns the value of key in the section, and whether it was found. If the key occurs more
+// than once, the last occurrence wins. Keys are compared ignoring case if the section was returned
+// by Section of a document with IgnoreCase set. The index of a section returned by Section is only
+// trusted while the section has as many entries as when it was indexed and the indexed entry still
+// has the key, and even then the entries after it are checked, in case one of them was renamed;
+// otherwise, and for sections built by other means, Get scans all the entries.
+func (s Section) Get(key string) (string, bool) {
+	ignoreCase := s.keys != nil && s.keys.ignoreCase
+	match := func(a, b string) bool { return (Ini{IgnoreCase: ignoreCase}).match(a, b) }
+	// The scan stops at the indexed entry if it still has the key, as no entry before it can win.
+	stop := -1
+	if s.keys != nil {
+		if i, ok := s.keys.keys[fold(key, ignoreCase)]; ok && s.keys.n == len(s.Entries) && match(s.Entries[i].Key, key) {
+			stop = i
+		}
+	}
+	for i := len(s.Entries) - 1; i > stop; i-- {
+		if match(s.Entries[i].Key, key) {
+			return s.Entries[i].Value, true
+		}
+	}
+	if stop >= 0 {
+		return s.Entries[stop].Value, true
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package ini
+
+import "slices"
+
+// Merge layers INI documents on top of each other, e.g. default, system and user configuration,
+// and returns the result; the documents themselves are not modified.
+//
+// The sections of all documents are merged by name: the result has one section for each name, at
+// the position of its first occurrence. Within the global entries and each section, a key set by a
+// later document overrides all the entries of that key set before, taking the place of the first of
+// them; keys that are not set before are added at the end. Repeated sections of the same document
+// are merged in the same way, so the result holds the values Lookup would find. Names are compared
+// like base compares them, ignoring case if base has IgnoreCase set.
+func Merge(base Ini, overrides ...Ini) Ini {
+	merged := Ini{Sections: []Section{}, IgnoreCase: base.IgnoreCase}
+	for _, src := range append([]Ini{base}, overrides...) {
+		merged.Global = merged.override(merged.Global, src.Global)
+		for _, s := range src.Sections {
+			i := slices.IndexFunc(merged.Sections, func(t Section) bool { return merged.match(t.Name, s.Name) })
+			if i < 0 {
+				merged.Sections = append(merged.Sections, Section{Name: s.Name})
+				i = len(merged.Sections) - 1
+			}
+			merged.Sections[i].Entries = merged.override(merged.Sections[i].Entries, s.Entries)
+		}
+	}
+	return merged
+}
+
+// override returns the entries of dst overridden by those of src, as described for Merge.
+func (ini Ini) override(dst, src []Entry) []Entry {
+	if len(src) == 0 {
+		return dst
+	}
+	set := func(entries []Entry, key string) bool {
+		return slices.ContainsFunc(entries, func(e Entry) bool { return ini.match(e.Key, key) })
+	}
+	var out, done []Entry
+	for _, e := range dst {
+		if !set(src, e.Key) {
+			out = append(out, e)
+			continue
+		}
+		if set(done, e.Key) {
+			continue
+		}
+		done = append(done, e)
+		for _, s := range src {
+			if ini.match(s.Key, e.Key) {
+				out = append(out, s)
+			}
+		}
+	}
+	for _, s := range src {
+		if !set(dst, s.Key) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
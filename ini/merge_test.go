@@ -0,0 +1,50 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	parse := func(s string, opts ...ini.Option) ini.Ini {
+		return ini.ParseINI(s, opts...).Get().First
+	}
+	defaults := parse("name = app\n[server]\nhost = 0.0.0.0\nport = 80\nflags[] = a\nflags[] = b\n[log]\nlevel = info\n")
+	system := parse("[log]\nlevel = warn\nfile = /var/log/app\n[server]\nport = 8080\n")
+	user := parse("name = mine\n[server]\nflags[] = c\n[cache]\nsize = 10\n")
+
+	merged := ini.Merge(defaults, system, user)
+	assert.Equal(t, ini.Ini{
+		Global: []ini.Entry{{Key: "name", Value: "mine"}},
+		Sections: []ini.Section{
+			{Name: "server", Entries: []ini.Entry{
+				{Key: "host", Value: "0.0.0.0"},
+				{Key: "port", Value: "8080"},
+				{Key: "flags[]", Value: "c"},
+			}},
+			{Name: "log", Entries: []ini.Entry{{Key: "level", Value: "warn"}, {Key: "file", Value: "/var/log/app"}}},
+			{Name: "cache", Entries: []ini.Entry{{Key: "size", Value: "10"}}},
+		},
+	}, merged)
+
+	assert.Equal(t, "0.0.0.0", defaults.Sections[0].Entries[0].Value)
+	assert.Len(t, defaults.Sections[0].Entries, 4, "the inputs are not modified")
+
+	t.Run("repeated sections", func(t *testing.T) {
+		doc := parse("[s]\na = 1\nb = 2\n[t]\n[s]\na = 3\n")
+		assert.Equal(t, ini.Ini{Sections: []ini.Section{
+			{Name: "s", Entries: []ini.Entry{{Key: "a", Value: "3"}, {Key: "b", Value: "2"}}},
+			{Name: "t"},
+		}}, ini.Merge(doc))
+	})
+
+	t.Run("ignore case", func(t *testing.T) {
+		base := parse("[Server]\nPort = 80\n", ini.IgnoreCase())
+		merged := ini.Merge(base, parse("[server]\nport = 8080\n"))
+		assert.Equal(t, []ini.Section{{Name: "Server", Entries: []ini.Entry{{Key: "port", Value: "8080"}}}}, merged.Sections)
+		port, _ := merged.GetInt("SERVER", "PORT")
+		assert.Equal(t, 8080, port)
+	})
+}
@@ -0,0 +1,215 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Option configures the INI grammar built by IIni, IniParse and the other parsers of this package.
+type Option func(*config)
+
+// config holds the settings selected by options.
+type config struct {
+	// inlineComments strips comments from the end of values and section header lines.
+	inlineComments bool
+	// multiline continues values on the lines after a trailing backslash and on indented lines.
+	multiline bool
+	// lookupEnv looks up the variables expanded in values, or is nil if they are not expanded.
+	lookupEnv func(string) (string, bool)
+	// ignoreCase matches section names and keys case-insensitively.
+	ignoreCase bool
+	// duplicates is the policy for keys occurring more than once in a section.
+	duplicates DuplicatePolicy
+	// inferTypes records the entries with typed values.
+	inferTypes bool
+	// git parses the dialect of Git configuration files.
+	git bool
+	// maxBytes is the maximum size of a document in bytes, or 0 for no limit.
+	maxBytes int
+	// includes accepts include directives; it is set by LoadFile and LoadFS.
+	includes bool
+}
+
+// newConfig applies opts to the default configuration.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// InlineComments strips comments written after a value on the same line, as in "port = 80 ; http".
+// A ';' or '#' starts a comment when it begins the value or follows whitespace, and is not inside a
+// value in double or single quotes; a quote only opens a quoted part at the start of the value or after
+// whitespace, so that apostrophes in words are literal. Outside quotes, "\;" and "\#" stand for a
+// literal ';' and '#'. Section header lines may also end with a comment, as in "[db] ; primary".
+func InlineComments() Option {
+	return func(c *config) {
+		c.inlineComments = true
+	}
+}
+
+// MultilineValues lets the value of an entry span several lines, in the styles of systemd unit files
+// and Python's configparser. A value ending with a backslash continues on the next line, joined
+// with a single space in place of the backslash. A line indented with spaces or tabs that is
+// neither blank nor a comment continues the value as well, joined with a newline; in this mode
+// entries themselves must not be indented.
+func MultilineValues() Option {
+	return func(c *config) {
+		c.multiline = true
+	}
+}
+
+// ExpandEnv expands references to environment variables in values, written as ${NAME} or $NAME,
+// when the document is parsed. Variables that are not set expand to the empty string, and "$$"
+// stands for a literal '$'.
+func ExpandEnv() Option {
+	return ExpandWith(os.LookupEnv)
+}
+
+// ExpandWith expands variable references in values like ExpandEnv, looking the variables up with
+// lookup instead of in the environment, e.g. to supply fixed values in tests.
+func ExpandWith(lookup func(name string) (string, bool)) Option {
+	return func(c *config) {
+		c.lookupEnv = lookup
+	}
+}
+
+// expand replaces the variable references in v with their values, following the rules of ExpandEnv.
+func expand(v string, lookup func(string) (string, bool)) string {
+	return os.Expand(v, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		value, _ := lookup(name)
+		return value
+	})
+}
+
+// IgnoreCase matches section names and keys case-insensitively, as Windows does, while keeping
+// their casing as written: the parsed Ini has IgnoreCase set for its lookups, Document matches names
+// in Get, Set and Delete regardless of case, and the duplicate key policy treats keys differing only
+// in case as the same key.
+func IgnoreCase() Option {
+	return func(c *config) {
+		c.ignoreCase = true
+	}
+}
+
+// match reports whether the section names or keys a and b are the same according to the configuration c.
+func (c *config) match(a, b string) bool {
+	return a == b || c.ignoreCase && strings.EqualFold(a, b)
+}
+
+// DuplicatePolicy decides what happens when a key occurs more than once in a section.
+// It applies to each section header separately, and to the global entries. Keys written with
+// the array suffix, as in "key[] = value", are lists and always keep every occurrence.
+type DuplicatePolicy int
+
+const (
+	// DuplicateCollect keeps every occurrence as a separate entry, in document order. It is the default.
+	DuplicateCollect DuplicatePolicy = iota
+	// DuplicateKeepFirst keeps the entry of the first occurrence and drops the others.
+	DuplicateKeepFirst
+	// DuplicateKeepLast keeps the value of the last occurrence, at the position of the first.
+	DuplicateKeepLast
+	// DuplicateError rejects the document, reporting the line of the repeated key.
+	DuplicateError
+)
+
+// OnDuplicateKey sets the policy for keys occurring more than once in a section.
+func OnDuplicateKey(policy DuplicatePolicy) Option {
+	return func(c *config) {
+		c.duplicates = policy
+	}
+}
+
+// ErrDuplicateKey is reported for a repeated key under the DuplicateError policy.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// addEntry adds e to the entries of a section, resolving a repeated key with the duplicate key policy of c.
+// Keys with the array suffix "[]" are always collected.
+func (c *config) addEntry(entries []Entry, e Entry) ([]Entry, error) {
+	i := slices.IndexFunc(entries, func(x Entry) bool { return c.match(x.Key, e.Key) })
+	switch {
+	case i < 0 || c.duplicates == DuplicateCollect || strings.HasSuffix(e.Key, "[]"):
+		return append(entries, e), nil
+	case c.duplicates == DuplicateKeepFirst:
+		return entries, nil
+	case c.duplicates == DuplicateKeepLast:
+		entries[i].Value = e.Value
+		return entries, nil
+	}
+	return entries, fmt.Errorf("%w %q", ErrDuplicateKey, e.Key)
+}
+
+// stripInlineComment removes an inline comment from the end of the trimmed value v and resolves
+// the escaped comment markers before it, following the rules of InlineComments.
+func stripInlineComment(v string) string {
+	value, _ := scanInlineComment(v)
+	return value
+}
+
+// scanInlineComment scans the value v following the rules of InlineComments. It returns the part
+// before any comment, trimmed and with the escaped comment markers resolved, and the offset of the
+// comment marker in v, or -1 if there is no comment.
+func scanInlineComment(v string) (string, int) {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		atWord := i == 0 || isBlank(rune(v[i-1]))
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && atWord:
+			quote = c
+		case c == '\\' && i+1 < len(v) && (v[i+1] == ';' || v[i+1] == '#'):
+			i++
+			c = v[i]
+		case (c == ';' || c == '#') && atWord:
+			return strings.TrimSpace(b.String()), i
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), -1
+}
+
+// escapeInlineComment escapes the comment markers in v that would start a comment under the rules
+// of InlineComments, so that v is read back unchanged.
+func escapeInlineComment(v string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		atWord := i == 0 || isBlank(rune(v[i-1]))
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && atWord:
+			quote = c
+		case (c == ';' || c == '#') && atWord:
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// MaxBytes limits documents to n bytes, so that a service loading configuration it does not
+// control cannot be made to read an unbounded amount of it. Longer documents are rejected with a
+// *parser.LimitError. It applies to Parse, ParseDocument and the loaders LoadFile, LoadFS and
+// LoadReader, which stop reading once the limit is exceeded; with includes, it applies to every file.
+func MaxBytes(n int) Option {
+	return func(c *config) {
+		c.maxBytes = n
+	}
+}
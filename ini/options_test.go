@@ -0,0 +1,231 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"semicolon", "port = 80 ; http", "80"},
+		{"hash", "port = 80\t# http", "80"},
+		{"only a comment", "port = ; unset", ""},
+		{"marker inside a word", "url = a;b#c", "a;b#c"},
+		{"double quoted", `name = "a ; b" ; comment`, `"a ; b"`},
+		{"single quoted", "name = 'x # y' # comment", "'x # y'"},
+		{"apostrophe", "name = it's ; comment", "it's"},
+		{"escaped", `name = a \; b \# c ; comment`, "a ; b # c"},
+		{"no comment", "name = a b", "a b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ini.IEntry(ini.InlineComments()).Parse(tt.input)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, tt.expected, result.Get().First.Value)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		result := ini.IEntry().Parse("port = 80 ; http")
+		assert.True(t, result.IsJust())
+		assert.Equal(t, "80 ; http", result.Get().First.Value)
+	})
+
+	t.Run("documents", func(t *testing.T) {
+		input := "[db] ; primary\nhost = localhost # local\n[cache]#\nport = 6379\n"
+		want := ini.Ini{Sections: []ini.Section{
+			{Name: "db", Entries: []ini.Entry{{Key: "host", Value: "localhost"}}},
+			{Name: "cache", Entries: []ini.Entry{{Key: "port", Value: "6379"}}},
+		}}
+		got := ini.ParseINI(input, ini.InlineComments())
+		assert.True(t, got.IsJust())
+		assert.Equal(t, want, got.Get().First)
+		got = ini.IIni(ini.InlineComments()).Parse(input)
+		assert.True(t, got.IsJust())
+		assert.Equal(t, want, got.Get().First)
+		assert.True(t, ini.ParseINI(input).IsNothing())
+	})
+}
+
+func TestMultilineValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		rest     string
+	}{
+		{"backslash", "cmd = run \\\n  --fast \\\n  --quiet\nnext=1", "run --fast --quiet", "next=1"},
+		{"indented", "hosts = a\n  b\n\tc\nnext=1", "a\nb\nc", "next=1"},
+		{"indented with backslash", "list = a\n  b \\\n  c\n", "a\nb c", ""},
+		{"backslash at end of input", "key = v \\", "v", ""},
+		{"blank line ends the value", "key = a\n\n  b", "a", "\n  b"},
+		{"comment ends the value", "key = a\n  ; note\n", "a", "  ; note\n"},
+		{"single line", "key = a", "a", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ini.IEntry(ini.MultilineValues()).Parse(tt.input)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, tt.expected, result.Get().First.Value)
+			assert.Equal(t, tt.rest, result.Get().Second)
+		})
+	}
+
+	t.Run("documents", func(t *testing.T) {
+		input := "[unit]\nExecStart = /bin/app \\\n    --verbose\n[python]\npaths =\n    /usr/lib\n    /opt/lib ; extra\n"
+		want := ini.Ini{Sections: []ini.Section{
+			{Name: "unit", Entries: []ini.Entry{{Key: "ExecStart", Value: "/bin/app --verbose"}}},
+			{Name: "python", Entries: []ini.Entry{{Key: "paths", Value: "\n/usr/lib\n/opt/lib"}}},
+		}}
+		got := ini.ParseINI(input, ini.MultilineValues(), ini.InlineComments())
+		assert.True(t, got.IsJust())
+		assert.Equal(t, want, got.Get().First)
+		assert.True(t, ini.ParseINI(input).IsNothing())
+	})
+}
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"HOME": "/home/app", "PORT": "8080"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"braces", "dir = ${HOME}/data", "/home/app/data"},
+		{"bare", "addr = localhost:$PORT", "localhost:8080"},
+		{"unset", "x = [${MISSING}]", "[]"},
+		{"dollar", "price = $$5", "$5"},
+		{"no reference", "x = plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ini.IEntry(ini.ExpandWith(lookup)).Parse(tt.input)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, tt.expected, result.Get().First.Value)
+		})
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("TINY_PARSEC_INI_TEST", "value")
+		doc := ini.ParseINI("[s]\nk = ${TINY_PARSEC_INI_TEST}", ini.ExpandEnv()).Get().First
+		v, _ := doc.GetString("s", "k")
+		assert.Equal(t, "value", v)
+		doc = ini.ParseINI("[s]\nk = ${TINY_PARSEC_INI_TEST}").Get().First
+		v, _ = doc.GetString("s", "k")
+		assert.Equal(t, "${TINY_PARSEC_INI_TEST}", v)
+	})
+}
+
+func TestOnDuplicateKey(t *testing.T) {
+	input := "a = 1\na = 2\n[s]\nk = x\nj = y\nk = z\n[s]\nk = w\n"
+	tests := []struct {
+		name     string
+		policy   ini.DuplicatePolicy
+		expected ini.Ini
+	}{
+		{"collect", ini.DuplicateCollect, ini.Ini{
+			Global: []ini.Entry{{Key: "a", Value: "1"}, {Key: "a", Value: "2"}},
+			Sections: []ini.Section{
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "x"}, {Key: "j", Value: "y"}, {Key: "k", Value: "z"}}},
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "w"}}},
+			},
+		}},
+		{"keep first", ini.DuplicateKeepFirst, ini.Ini{
+			Global: []ini.Entry{{Key: "a", Value: "1"}},
+			Sections: []ini.Section{
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "x"}, {Key: "j", Value: "y"}}},
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "w"}}},
+			},
+		}},
+		{"keep last", ini.DuplicateKeepLast, ini.Ini{
+			Global: []ini.Entry{{Key: "a", Value: "2"}},
+			Sections: []ini.Section{
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "z"}, {Key: "j", Value: "y"}}},
+				{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "w"}}},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := ini.OnDuplicateKey(tt.policy)
+			result := ini.ParseINI(input, opt)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, tt.expected, result.Get().First)
+
+			result = ini.IIni(opt).Parse(input)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, tt.expected, result.Get().First)
+
+			d, err := ini.ParseDocument(input, opt)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, d.Ini())
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		opt := ini.OnDuplicateKey(ini.DuplicateError)
+		assert.True(t, ini.ParseINI(input, opt).IsNothing())
+		assert.True(t, ini.IIni(opt).Parse(input).IsNothing())
+
+		var cfg struct{}
+		err := ini.Unmarshal("[s]\nk = 1\nj = 2\nk = 3\n", &cfg, opt)
+		assert.ErrorIs(t, err, ini.ErrDuplicateKey)
+		assert.EqualError(t, err, `ini: line 4, col 1: duplicate key "k"`)
+
+		_, err = ini.ParseDocument("[s]\nk = 1\n[t]\nk = 2\n[s]\nk = 3\nk = 4\n", opt)
+		assert.EqualError(t, err, `ini: line 7, col 1: duplicate key "k"`)
+	})
+}
+
+func TestInferTypes(t *testing.T) {
+	input := "name = app\ndebug = yes\n[server]\nport = 8080\nratio = -0.5\nbig = 99999999999999999999\nversion = 1.2.3\noffset = +3\n"
+	want := ini.Ini{
+		Global: []ini.Entry{{Key: "name", Value: "app"}, {Key: "debug", Value: "yes"}},
+		Typed:  []ini.TypedEntry{{Key: "name", Value: "app"}, {Key: "debug", Value: true}},
+		Sections: []ini.Section{{
+			Name: "server",
+			Entries: []ini.Entry{
+				{Key: "port", Value: "8080"},
+				{Key: "ratio", Value: "-0.5"},
+				{Key: "big", Value: "99999999999999999999"},
+				{Key: "version", Value: "1.2.3"},
+				{Key: "offset", Value: "+3"},
+			},
+			Typed: []ini.TypedEntry{
+				{Key: "port", Value: int64(8080)},
+				{Key: "ratio", Value: -0.5},
+				{Key: "big", Value: "99999999999999999999"},
+				{Key: "version", Value: "1.2.3"},
+				{Key: "offset", Value: int64(3)},
+			},
+		}},
+	}
+
+	doc, err := ini.Parse(input, ini.InferTypes())
+	assert.NoError(t, err)
+	assert.Equal(t, want, doc)
+
+	result := ini.ParseINI(input, ini.InferTypes())
+	assert.True(t, result.IsJust())
+	assert.Equal(t, want, result.Get().First)
+
+	doc, err = ini.Parse(input)
+	assert.NoError(t, err)
+	assert.Nil(t, doc.Typed)
+	assert.Nil(t, doc.Sections[0].Typed)
+}
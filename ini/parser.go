@@ -10,55 +10,267 @@ import (
 // ISectionName returns a parser that parses the name of a section in an INI file.
 // It uses Between to parse the text between square brackets.
 func ISectionName() parser.Parser[string] {
+	return parser.Trim(sectionHeader())
+}
+
+// sectionHeader returns a parser that parses a section name between square brackets,
+// without any whitespace around them.
+func sectionHeader() parser.Parser[string] {
 	return parser.Between(
-		// Parse and trim the opening square bracket
-		parser.Trim(parser.Char('[')),
-		// Parse zero or more characters that are not closing square brackets
+		parser.Char('['),
+		// Parse zero or more characters on the line that are not closing square brackets
 		parser.Bind(
-			parser.ZeroOrMore(parser.NotChar(']')),
+			parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return r != ']' && r != '\n' })),
 			func(rs []rune) parser.Parser[string] {
-				s := strings.TrimSpace(string(rs))
+				s := strings.TrimSpace(parser.Text(rs))
 				if s == "" {
 					return parser.Fail[string]()
 				} else {
 					return parser.Pure(s)
 				}
 			}),
-		// Parse and trim the closing square bracket
-		parser.Trim(parser.Char(']')),
+		parser.Char(']'),
 	)
 }
 
-// ParseINI parses an INI string using the IIni parser.
-// It returns the result of the parsing operation.
-func ParseINI(str string) parser.ParserFuncRet[Ini] {
-	return IniParse().Parse(str)
-}
-
-// Ini represents an INI file with a list of sections.
-func IniParse() parser.Parser[Ini] {
-	return parser.NewParser(func(input string) parser.ParserFuncRet[Ini] {
-		strs := strings.Split(input, "\n")
-		sections := make([]Section, 0)
-		for _, s := range strs {
-			s = strings.TrimSpace(s)
-			if s == "" || strings.HasPrefix(s, ";") || strings.HasPrefix(s, "#") {
-				continue
+// isBlank reports whether r is whitespace within a line.
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+// iBlanks returns a parser that skips whitespace within a line.
+func iBlanks() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isBlank))
+}
+
+// iLineEnd returns a parser that parses the end of a line: a line break or the end of the input.
+func iLineEnd() parser.Parser[struct{}] {
+	return parser.OrElse(
+		parser.Fmap(parser.Char('\n'), func(rune) struct{} { return struct{}{} }),
+		parser.EOF(),
+	)
+}
+
+// isCommentMarker reports whether r starts a comment.
+func isCommentMarker(r rune) bool {
+	return r == ';' || r == '#'
+}
+
+// IComment returns a parser that parses a comment line: a line whose first non-blank character is ';' or '#'.
+// It consumes the line break ending the line and returns the text after the marker with surrounding whitespace trimmed.
+func IComment() parser.Parser[string] {
+	return parser.OmitLeft(
+		// Parse the comment marker after any indentation
+		parser.OmitLeft(iBlanks(), parser.Satisfy(isCommentMarker)),
+		parser.Fmap(
+			parser.OmitRight(parser.ZeroOrMore(parser.NotChar('\n')), iLineEnd()),
+			func(rs []rune) string { return strings.TrimSpace(parser.Text(rs)) },
+		),
+	)
+}
+
+// iSkip returns a parser that skips any blank lines and comment lines.
+func iSkip() parser.Parser[[]string] {
+	return parser.ZeroOrMore(parser.OrElse(
+		IComment(),
+		parser.Fmap(parser.OmitLeft(iBlanks(), parser.Char('\n')), func(rune) string { return "" }),
+	))
+}
+
+// IEntry returns a parser that parses a key-value entry of the form "key = value".
+// The key is everything before the first '=' and must not be blank or start with '['; the value is the rest of the line.
+// Surrounding whitespace is trimmed from both. Blank lines and comment lines before the entry are skipped.
+func IEntry(opts ...Option) parser.Parser[Entry] {
+	return newConfig(opts).entry()
+}
+
+// entry parses a key-value entry according to the configuration c.
+func (c *config) entry() parser.Parser[Entry] {
+	if c.git {
+		return c.gitEntry()
+	}
+	return parser.OmitLeft(iSkip(), parser.Bind(
+		// Parse the key up to and including the '=' separator
+		parser.OmitRight(
+			parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r != '=' && r != '\n' })),
+			parser.Char('='),
+		),
+		func(rs []rune) parser.Parser[Entry] {
+			key := strings.TrimSpace(parser.Text(rs))
+			if key == "" || strings.HasPrefix(key, "[") {
+				return parser.Fail[Entry]()
 			}
-			r := ISectionName().Parse(s)
-			if r.IsJust() {
-				section := Section{Name: r.Get().First}
-				sections = append(sections, section)
-			} else {
-				t := strings.Split(s, "=")
-				entry := Entry{
-					Key:   strings.TrimSpace(t[0]),
-					Value: strings.TrimSpace(t[1]),
+			return parser.Fmap(c.value(), func(value string) Entry {
+				if c.lookupEnv != nil {
+					value = expand(value, c.lookupEnv)
 				}
-				newEntries := append(sections[len(sections)-1].Entries, entry)
-				sections[len(sections)-1].Entries = newEntries
+				return Entry{Key: key, Value: value}
+			})
+		}))
+}
+
+// valueLine parses the remainder of a line as (part of) a value according to the configuration c.
+func (c *config) valueLine() parser.Parser[string] {
+	return parser.Fmap(
+		parser.OmitRight(parser.ZeroOrMore(parser.NotChar('\n')), iLineEnd()),
+		func(vs []rune) string {
+			value := strings.TrimSpace(parser.Text(vs))
+			if c.inlineComments {
+				value = stripInlineComment(value)
 			}
+			return value
+		})
+}
+
+// value parses the value of an entry according to the configuration c: the remainder of the line,
+// followed by its continuation lines if multi-line values are enabled.
+func (c *config) value() parser.Parser[string] {
+	if !c.multiline {
+		return c.valueLine()
+	}
+	// An indented line that is neither blank nor a comment continues the value on a new line
+	indented := parser.OmitLeft(
+		parser.OneOrMore(parser.Satisfy(isBlank)),
+		parser.SatisfyWith(c.valueLine(), func(s string) bool {
+			return s != "" && !isCommentMarker(rune(s[0]))
+		}),
+	)
+	return parser.Bind(c.escapedLines(c.valueLine()), func(first string) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(c.escapedLines(indented)), func(rest []string) string {
+			return strings.Join(append([]string{first}, rest...), "\n")
+		})
+	})
+}
+
+// escapedLines parses a line with p and, as long as it ends with a backslash, the lines after it,
+// joining them with single spaces in place of the backslashes.
+func (c *config) escapedLines(p parser.Parser[string]) parser.Parser[string] {
+	var join func(string) parser.Parser[string]
+	join = func(v string) parser.Parser[string] {
+		if !strings.HasSuffix(v, `\`) {
+			return parser.Pure(v)
 		}
-		return parser.Just(parser.NewTuple(Ini{Sections: sections}, ""))
+		return parser.Bind(c.valueLine(), func(next string) parser.Parser[string] {
+			return join(strings.TrimSpace(strings.TrimSpace(strings.TrimSuffix(v, `\`)) + " " + next))
+		})
+	}
+	return parser.Bind(p, join)
+}
+
+// ISection returns a parser that parses a section: a header line with the section name in
+// square brackets, followed by its entries. Blank lines and comment lines are skipped.
+func ISection(opts ...Option) parser.Parser[Section] {
+	return newConfig(opts).section()
+}
+
+// headerComment parses what may follow the closing bracket of a section header on its line
+// according to the configuration c: only whitespace, or an inline comment if they are enabled.
+func (c *config) headerComment() parser.Parser[struct{}] {
+	blanks := parser.Fmap(iBlanks(), func([]rune) struct{} { return struct{}{} })
+	if !c.inlineComments {
+		return blanks
+	}
+	return parser.OmitLeft(blanks, parser.Fmap(
+		parser.ZeroOrOne(parser.OmitLeft(parser.Satisfy(isCommentMarker), parser.ZeroOrMore(parser.NotChar('\n')))),
+		func(parser.Maybe[[]rune]) struct{} { return struct{}{} },
+	))
+}
+
+// header parses a section header line according to the configuration c and returns the section name.
+func (c *config) header() parser.Parser[string] {
+	if c.git {
+		return parser.Between(iBlanks(), gitHeader(), gitLineEnd())
+	}
+	return parser.Between(iBlanks(), sectionHeader(), parser.OmitLeft(c.headerComment(), iLineEnd()))
+}
+
+// section parses a section according to the configuration c.
+func (c *config) section() parser.Parser[Section] {
+	return parser.Bind(parser.OmitLeft(iSkip(), c.header()), func(name string) parser.Parser[Section] {
+		return parser.Fmap(c.entries(), func(entries []Entry) Section {
+			return Section{Name: name, Entries: entries}
+		})
+	})
+}
+
+// entries parses the entries of a section, or the global entries, and resolves repeated keys
+// with the duplicate key policy of c. It fails if the policy rejects a key.
+func (c *config) entries() parser.Parser[[]Entry] {
+	return parser.Bind(parser.ZeroOrMore(c.entry()), func(all []Entry) parser.Parser[[]Entry] {
+		// A section without entries has none, as built by IniParse
+		var entries []Entry
+		for _, e := range all {
+			var err error
+			if entries, err = c.addEntry(entries, e); err != nil {
+				return parser.Fail[[]Entry]()
+			}
+		}
+		return parser.Pure(entries)
+	})
+}
+
+// IIni returns a declarative parser for a complete INI document: the global entries before the
+// first section header, then a sequence of sections, with blank lines and comment lines anywhere.
+// Lines may end with "\r\n" as well as "\n", and a leading UTF-8 byte order mark is skipped.
+func IIni(opts ...Option) parser.Parser[Ini] {
+	return newConfig(opts).ini()
+}
+
+// ini parses a complete INI document according to the configuration c.
+func (c *config) ini() parser.Parser[Ini] {
+	// A byte order mark left by Windows editors is not part of the document
+	bom := parser.ZeroOrOne(parser.Str("\ufeff"))
+	return parser.Bind(parser.OmitLeft(bom, c.entries()), func(global []Entry) parser.Parser[Ini] {
+		return parser.Fmap(
+			parser.OmitRight(
+				parser.ZeroOrMore(c.section()),
+				parser.OmitLeft(iSkip(), parser.OmitLeft(iBlanks(), parser.EOF())),
+			),
+			func(sections []Section) Ini {
+				return c.finish(Ini{Global: global, Sections: sections, IgnoreCase: c.ignoreCase})
+			})
 	})
 }
+
+// iInclude returns a parser that parses an include directive line, "!include name", and returns the name.
+func iInclude() parser.Parser[string] {
+	return parser.OmitLeft(
+		parser.OmitLeft(iBlanks(), parser.OmitRight(parser.Str("!include"), parser.OneOrMore(parser.Satisfy(isBlank)))),
+		parser.SatisfyWith(
+			parser.Fmap(
+				parser.OmitRight(parser.ZeroOrMore(parser.NotChar('\n')), iLineEnd()),
+				func(rs []rune) string { return strings.TrimSpace(parser.Text(rs)) },
+			),
+			func(name string) bool { return name != "" },
+		),
+	)
+}
+
+// ParseINI parses an INI string using the IniParse parser.
+// It returns the result of the parsing operation.
+func ParseINI(str string, opts ...Option) parser.ParserFuncRet[Ini] {
+	return IniParse(opts...).Parse(str)
+}
+
+// Parse parses the INI document s like IniParse, but reports why it is rejected: the error
+// joins a *parser.Error for every malformed line, with its line and column, in order.
+// Parsing resumes on the line after a malformed one.
+func Parse(s string, opts ...Option) (Ini, error) {
+	return newConfig(opts).parse(s)
+}
+
+// IniParse returns a parser for a complete INI document: the global entries before the first
+// section header, then a sequence of sections, with blank lines and comment lines anywhere.
+// It is the IIni grammar; Parse reports the malformed lines of a document it fails on.
+func IniParse(opts ...Option) parser.Parser[Ini] {
+	return newConfig(opts).ini()
+}
+
+// parse parses the complete INI document input according to the configuration c, like Parse.
+func (c *config) parse(input string) (Ini, error) {
+	d, err := c.document(input, "ini")
+	if err != nil {
+		return Ini{}, err
+	}
+	return d.Ini(), nil
+}
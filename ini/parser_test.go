@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/81120/tiny-parsec/ini"
+	"github.com/81120/tiny-parsec/parser"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,6 +79,27 @@ func TestIniParse(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"global keys",
+			"name = app\n; comment\nversion = 2\n[section]\nkey=value",
+			ini.Ini{
+				Global: []ini.Entry{{Key: "name", Value: "app"}, {Key: "version", Value: "2"}},
+				Sections: []ini.Section{{
+					Name:    "section",
+					Entries: []ini.Entry{{Key: "key", Value: "value"}},
+				}},
+			},
+			false,
+		},
+		{
+			"only global keys",
+			"a=1\nb=2",
+			ini.Ini{
+				Global:   []ini.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+				Sections: []ini.Section{},
+			},
+			false,
+		},
 		{
 			"invalid key format",
 			"[section]\nkeyvalue",
@@ -103,3 +125,171 @@ func TestIniParse(t *testing.T) {
 		})
 	}
 }
+
+func TestIEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ini.Entry
+		err      bool
+	}{
+		{"simple entry", "key=value", ini.Entry{Key: "key", Value: "value"}, false},
+		{"with spaces", "  key  =  some value ", ini.Entry{Key: "key", Value: "some value"}, false},
+		{"equals in value", "url=a=b", ini.Entry{Key: "url", Value: "a=b"}, false},
+		{"empty value", "key=", ini.Entry{Key: "key", Value: ""}, false},
+		{"after comments", "; note\n\n# more\nkey=value", ini.Entry{Key: "key", Value: "value"}, false},
+		{"section header", "[a=b]", ini.Entry{}, true},
+		{"missing separator", "keyvalue", ini.Entry{}, true},
+		{"empty key", " = value", ini.Entry{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ini.IEntry().Parse(tt.input)
+			if tt.err {
+				assert.True(t, result.IsNothing())
+			} else {
+				assert.True(t, result.IsJust())
+				assert.Equal(t, tt.expected, result.Get().First)
+			}
+		})
+	}
+}
+
+func TestIComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		rest     string
+		err      bool
+	}{
+		{"semicolon", "; a comment", "a comment", "", false},
+		{"hash", "# another\nkey=value", "another", "key=value", false},
+		{"indented", "   ;x  \n", "x", "", false},
+		{"empty comment", ";\n", "", "", false},
+		{"not a comment", "key=value ; no", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ini.IComment().Parse(tt.input)
+			if tt.err {
+				assert.True(t, result.IsNothing())
+			} else {
+				assert.True(t, result.IsJust())
+				assert.Equal(t, tt.expected, result.Get().First)
+				assert.Equal(t, tt.rest, result.Get().Second)
+			}
+		})
+	}
+}
+
+func TestISection(t *testing.T) {
+	input := "# servers\n[db]\n; primary\nhost = localhost\n\n  # port\nport=5432\n[cache]\n"
+	result := ini.ISection().Parse(input)
+	assert.True(t, result.IsJust())
+	assert.Equal(t, ini.Section{Name: "db", Entries: []ini.Entry{
+		{Key: "host", Value: "localhost"},
+		{Key: "port", Value: "5432"},
+	}}, result.Get().First)
+	assert.Equal(t, "[cache]\n", result.Get().Second)
+
+	t.Run("comment is not an entry", func(t *testing.T) {
+		result := ini.ISection().Parse("[s]\n; a=b\n# c=d\n")
+		assert.True(t, result.IsJust())
+		assert.Equal(t, ini.Section{Name: "s"}, result.Get().First)
+	})
+}
+
+func TestIIni(t *testing.T) {
+	inputs := []string{
+		"[section]\nkey=value",
+		"[db]\nhost=localhost\n[cache]\nport=6379",
+		"; comment\n[section]\n# another comment\nkey=value",
+		"\n  [a]  \r\n  k = v \r\n\n; end\n  ",
+		"[empty]\n[next]\nx=1=2\n",
+		"",
+		"[section]\nkeyvalue",
+		"key=value\n[section]",
+		"name = app\n; comment\nversion = 2\n[section]\nkey=value",
+		"a=1\nb=2",
+		"[section\nkey=value",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			want := ini.IniParse().Parse(input)
+			got := ini.IIni().Parse(input)
+			assert.Equal(t, want.IsJust(), got.IsJust())
+			if want.IsJust() && got.IsJust() {
+				assert.Equal(t, want.Get().First, got.Get().First)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	doc, err := ini.Parse("[s]\nk = v\n")
+	assert.NoError(t, err)
+	assert.Equal(t, ini.Ini{Sections: []ini.Section{{Name: "s", Entries: []ini.Entry{{Key: "k", Value: "v"}}}}}, doc)
+
+	doc, err = ini.Parse("[caf\u00e9]\n\u00fcber = \u65e5\u672c ; \u00e9\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []ini.Section{{Name: "caf\u00e9", Entries: []ini.Entry{{Key: "\u00fcber", Value: "\u65e5\u672c ; \u00e9"}}}}, doc.Sections)
+
+	t.Run("every malformed line", func(t *testing.T) {
+		_, err := ini.Parse("[section]\nkeyvalue\nok = 1\n[broken\n")
+		assert.EqualError(t, err, "ini: line 2, col 9: unexpected end of input, expected '='\n"+
+			"ini: line 4, col 8: unexpected end of input, expected ']' or '='")
+		var perr *parser.Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 2, perr.Line)
+		assert.Equal(t, len("[section]\nkeyvalue"), perr.Offset)
+	})
+
+	t.Run("multi-line values", func(t *testing.T) {
+		_, err := ini.Parse("[s]\nk = a\n  b\noops\nbad\n", ini.MultilineValues())
+		var perr *parser.Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 4, perr.Line)
+		assert.Equal(t, 5, perr.Column)
+	})
+}
+
+func TestWindowsInput(t *testing.T) {
+	want := ini.Ini{
+		Global: []ini.Entry{{Key: "name", Value: "app"}},
+		Sections: []ini.Section{
+			{Name: "db", Entries: []ini.Entry{{Key: "host", Value: "localhost"}, {Key: "port", Value: "5432"}}},
+			{Name: "log"},
+		},
+	}
+	inputs := map[string]string{
+		"CRLF":           "name = app\r\n[db]\r\nhost = localhost\r\n; comment\r\n\r\nport=5432\r\n[log] \r\n",
+		"BOM":            "\ufeffname = app\n[db]\nhost = localhost\nport = 5432\n[log]",
+		"BOM and CRLF":   "\ufeffname = app\r\n[db]\r\nhost = localhost\r\nport = 5432\r\n[log]\r\n",
+		"inline comment": "name = app ; x\r\n[db] ; y\r\nhost = localhost\r\nport = 5432\r\n[log]\r\n",
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			doc, err := ini.Parse(input, ini.InlineComments())
+			assert.NoError(t, err)
+			assert.Equal(t, want, doc)
+
+			result := ini.IIni(ini.InlineComments()).Parse(input)
+			assert.True(t, result.IsJust())
+			assert.Equal(t, want, result.Get().First)
+
+			d, err := ini.ParseDocument(input, ini.InlineComments())
+			assert.NoError(t, err)
+			assert.Equal(t, input, d.String())
+		})
+	}
+
+	t.Run("multi-line values", func(t *testing.T) {
+		doc, err := ini.Parse("[s]\r\nk = a \\\r\n  b\r\nl = c\r\n  d\r\n", ini.MultilineValues())
+		assert.NoError(t, err)
+		assert.Equal(t, []ini.Entry{{Key: "k", Value: "a b"}, {Key: "l", Value: "c\nd"}}, doc.Sections[0].Entries)
+	})
+}
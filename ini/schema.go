@@ -0,0 +1,275 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueType is the type a schema requires of a value.
+type ValueType int
+
+const (
+	// TypeString accepts any value.
+	TypeString ValueType = iota
+	// TypeInt accepts integers, as GetInt does.
+	TypeInt
+	// TypeFloat accepts numbers, as GetFloat does.
+	TypeFloat
+	// TypeBool accepts booleans, as GetBool does.
+	TypeBool
+	// TypeDuration accepts durations, as GetDuration does.
+	TypeDuration
+)
+
+// String returns the name of the type, e.g. "integer".
+func (t ValueType) String() string {
+	switch t {
+	case TypeInt:
+		return "integer"
+	case TypeFloat:
+		return "number"
+	case TypeBool:
+		return "boolean"
+	case TypeDuration:
+		return "duration"
+	}
+	return "string"
+}
+
+// valid reports whether v is a value of the type t.
+func (t ValueType) valid(v string) bool {
+	var err error
+	switch t {
+	case TypeInt:
+		_, err = strconv.ParseInt(v, 0, strconv.IntSize)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(v, 64)
+	case TypeBool:
+		_, ok := parseBool(v)
+		return ok
+	case TypeDuration:
+		_, err = time.ParseDuration(v)
+	}
+	return err == nil
+}
+
+// Schema describes the sections and keys an application expects in its configuration.
+// Sections and keys that the schema does not mention are accepted.
+type Schema struct {
+	Sections []SectionSchema
+}
+
+// SectionSchema describes a section. The empty name describes the global entries.
+type SectionSchema struct {
+	Name string
+	// Required reports whether the section must be present.
+	Required bool
+	Keys     []KeySchema
+}
+
+// KeySchema describes a key of a section. It applies to the entries of the key written with the
+// array suffix as well, as in "key[] = value".
+type KeySchema struct {
+	Name string
+	// Required reports whether the key must be set. A key with a Default is never missing.
+	Required bool
+	// Type is the type of the values of the key.
+	Type ValueType
+	// Enum lists the allowed values, if not empty.
+	Enum []string
+	// Default is the value WithDefaults sets if the key is missing, unless it is empty.
+	Default string
+}
+
+// Violation is a part of a document that does not conform to its schema.
+type Violation struct {
+	// Line is the 1-based number of the offending line, or 0 if it is unknown: for a missing section,
+	// or for a document validated by Validate. For a missing key it is the line of the section header.
+	Line int
+	// Section is the name of the section, or "" for the global entries.
+	Section string
+	// Key is the offending key, or "" for a missing section.
+	Key string
+	// Message describes the violation.
+	Message string
+}
+
+// String returns the violation as "line N: [section] key: message", leaving out the unknown parts.
+func (v Violation) String() string {
+	var b strings.Builder
+	if v.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", v.Line)
+	}
+	if v.Section != "" {
+		fmt.Fprintf(&b, "[%s] ", v.Section)
+	}
+	if v.Key != "" {
+		b.WriteString(v.Key + ": ")
+	}
+	return b.String() + v.Message
+}
+
+// ValidationError is returned for a document that does not conform to its schema.
+type ValidationError struct {
+	// Violations lists every violation, the invalid values in document order first, then the
+	// missing sections and keys in schema order.
+	Violations []Violation
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "ini: " + strings.Join(msgs, "; ")
+}
+
+// located is an entry or a section header of a validated document, with its line number.
+type located struct {
+	section string
+	// entry is nil for a section header.
+	entry *Entry
+	line  int
+}
+
+// Validate checks ini against the schema. It returns nil if ini conforms, and otherwise a
+// *ValidationError listing all violations; as ini does not record where its entries were
+// written, the violations have no line numbers. Use ValidateDocument to get them.
+func (s *Schema) Validate(ini Ini) error {
+	var items []located
+	add := func(section string, entries []Entry) {
+		for i := range entries {
+			items = append(items, located{section: section, entry: &entries[i]})
+		}
+	}
+	add("", ini.Global)
+	for _, sec := range ini.Sections {
+		items = append(items, located{section: sec.Name})
+		add(sec.Name, sec.Entries)
+	}
+	return s.validate(Ini{IgnoreCase: ini.IgnoreCase}, items)
+}
+
+// ValidateDocument checks the document d against the schema like Validate, reporting the line of each violation.
+func (s *Schema) ValidateDocument(d *Document) error {
+	var items []located
+	for _, l := range d.lines {
+		switch {
+		case l.isHeader:
+			items = append(items, located{section: l.section, line: l.line})
+		case l.entry != nil:
+			items = append(items, located{section: l.section, entry: l.entry, line: l.line})
+		}
+	}
+	return s.validate(Ini{IgnoreCase: d.c.ignoreCase}, items)
+}
+
+// validate checks the entries and section headers items against the schema, comparing names like ini.
+func (s *Schema) validate(ini Ini, items []located) error {
+	var vs []Violation
+	for _, it := range items {
+		if it.entry == nil {
+			continue
+		}
+		k := s.key(ini, it.section, it.entry.Key)
+		if k == nil {
+			continue
+		}
+		v := it.entry.Value
+		switch {
+		case !k.Type.valid(v):
+			vs = append(vs, Violation{it.line, it.section, it.entry.Key, fmt.Sprintf("value %q is not a valid %s", v, k.Type)})
+		case len(k.Enum) > 0 && !slices.Contains(k.Enum, v):
+			vs = append(vs, Violation{it.line, it.section, it.entry.Key, fmt.Sprintf("value %q is not one of %s", v, strings.Join(k.Enum, ", "))})
+		}
+	}
+	for _, sec := range s.Sections {
+		// line is the line of the first header of the section, or 0
+		line, found := 0, sec.Name == ""
+		for _, it := range items {
+			if it.entry == nil && ini.match(it.section, sec.Name) {
+				line, found = it.line, true
+				break
+			}
+		}
+		if !found {
+			if sec.Required {
+				vs = append(vs, Violation{Section: sec.Name, Message: "missing required section"})
+			}
+			continue
+		}
+		for _, k := range sec.Keys {
+			set := slices.ContainsFunc(items, func(it located) bool {
+				return it.entry != nil && ini.match(it.section, sec.Name) && k.matches(ini, it.entry.Key)
+			})
+			if k.Required && k.Default == "" && !set {
+				vs = append(vs, Violation{line, sec.Name, k.Name, "missing required key"})
+			}
+		}
+	}
+	if len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+	return nil
+}
+
+// key returns the schema of key in the named section, or nil if the schema does not describe it.
+func (s *Schema) key(ini Ini, section, key string) *KeySchema {
+	for i := range s.Sections {
+		sec := &s.Sections[i]
+		if !ini.match(sec.Name, section) {
+			continue
+		}
+		for j := range sec.Keys {
+			if sec.Keys[j].matches(ini, key) {
+				return &sec.Keys[j]
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether key, as written in a document, is the key k, comparing names like ini.
+func (k *KeySchema) matches(ini Ini, key string) bool {
+	return ini.match(key, k.Name) || ini.match(key, k.Name+"[]")
+}
+
+// WithDefaults returns ini with the Default of every key the schema describes that is not set in
+// ini. A default is added to the last section with the name, or to a new section at the end if
+// there is none; ini itself is not modified.
+func (s *Schema) WithDefaults(ini Ini) Ini {
+	out := Ini{Global: slices.Clone(ini.Global), Sections: slices.Clone(ini.Sections), IgnoreCase: ini.IgnoreCase}
+	for _, sec := range s.Sections {
+		for _, k := range sec.Keys {
+			if k.Default == "" {
+				continue
+			}
+			e := Entry{Key: k.Name, Value: k.Default}
+			if sec.Name == "" {
+				if !slices.ContainsFunc(out.Global, func(g Entry) bool { return k.matches(out, g.Key) }) {
+					out.Global = append(out.Global, e)
+				}
+				continue
+			}
+			last, set := -1, false
+			for i, t := range out.Sections {
+				if out.match(t.Name, sec.Name) {
+					last = i
+					set = set || slices.ContainsFunc(t.Entries, func(g Entry) bool { return k.matches(out, g.Key) })
+				}
+			}
+			switch {
+			case set:
+			case last < 0:
+				out.Sections = append(out.Sections, Section{Name: sec.Name, Entries: []Entry{e}})
+			default:
+				out.Sections[last].Entries = append(slices.Clone(out.Sections[last].Entries), e)
+			}
+		}
+	}
+	return out
+}
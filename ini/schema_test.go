@@ -0,0 +1,90 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+var serverSchema = &ini.Schema{Sections: []ini.SectionSchema{
+	{Keys: []ini.KeySchema{{Name: "env", Required: true, Enum: []string{"dev", "prod"}}}},
+	{Name: "server", Required: true, Keys: []ini.KeySchema{
+		{Name: "host", Required: true},
+		{Name: "port", Type: ini.TypeInt, Default: "80"},
+		{Name: "timeout", Type: ini.TypeDuration},
+		{Name: "tls", Type: ini.TypeBool},
+	}},
+	{Name: "log", Required: true, Keys: []ini.KeySchema{{Name: "level", Enum: []string{"debug", "info", "warn"}}}},
+	{Name: "cache", Keys: []ini.KeySchema{{Name: "size", Type: ini.TypeInt, Required: true}}},
+}}
+
+func TestValidateDocument(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []ini.Violation
+	}{
+		{"valid", "env = prod\n[server]\nhost = example.com\nport = 0x50\ntimeout = 1m\ntls = yes\n[log]\nlevel = warn\n", nil},
+		{"all violations", "env = test\n[server]\nport = http\ntls = maybe\n\n[server]\ntimeout = 5\n[cache]\n", []ini.Violation{
+			{Line: 1, Key: "env", Message: `value "test" is not one of dev, prod`},
+			{Line: 3, Section: "server", Key: "port", Message: `value "http" is not a valid integer`},
+			{Line: 4, Section: "server", Key: "tls", Message: `value "maybe" is not a valid boolean`},
+			{Line: 7, Section: "server", Key: "timeout", Message: `value "5" is not a valid duration`},
+			{Line: 2, Section: "server", Key: "host", Message: "missing required key"},
+			{Section: "log", Message: "missing required section"},
+			{Line: 8, Section: "cache", Key: "size", Message: "missing required key"},
+		}},
+		{"arrays", "env = dev\n[server]\nhost = a\n[log]\nlevel[] = info\nlevel[] = trace\n", []ini.Violation{
+			{Line: 6, Section: "log", Key: "level[]", Message: `value "trace" is not one of debug, info, warn`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ini.ParseDocument(tt.input)
+			assert.NoError(t, err)
+			err = serverSchema.ValidateDocument(d)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ini.ValidationError
+			assert.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.want, verr.Violations)
+		})
+	}
+
+	t.Run("error message", func(t *testing.T) {
+		d, _ := ini.ParseDocument("env = dev\n[server]\nport = x\n")
+		assert.EqualError(t, serverSchema.ValidateDocument(d),
+			`ini: line 3: [server] port: value "x" is not a valid integer; line 2: [server] host: missing required key; [log] missing required section`)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	doc := ini.ParseINI("ENV = dev\n[Server]\nHost = a\nPort = x\n", ini.IgnoreCase()).Get().First
+	var verr *ini.ValidationError
+	assert.ErrorAs(t, serverSchema.Validate(doc), &verr)
+	assert.Equal(t, []ini.Violation{
+		{Section: "Server", Key: "Port", Message: `value "x" is not a valid integer`},
+		{Section: "log", Message: "missing required section"},
+	}, verr.Violations)
+}
+
+func TestWithDefaults(t *testing.T) {
+	schema := &ini.Schema{Sections: []ini.SectionSchema{
+		{Keys: []ini.KeySchema{{Name: "env", Default: "dev"}}},
+		{Name: "server", Keys: []ini.KeySchema{{Name: "host", Default: "localhost"}, {Name: "port", Default: "80"}}},
+		{Name: "log", Keys: []ini.KeySchema{{Name: "level", Default: "info"}, {Name: "file"}}},
+	}}
+	doc := ini.ParseINI("[server]\nport = 8080\n[server]\ntls = on\n").Get().First
+	assert.Equal(t, ini.Ini{
+		Global: []ini.Entry{{Key: "env", Value: "dev"}},
+		Sections: []ini.Section{
+			{Name: "server", Entries: []ini.Entry{{Key: "port", Value: "8080"}}},
+			{Name: "server", Entries: []ini.Entry{{Key: "tls", Value: "on"}, {Key: "host", Value: "localhost"}}},
+			{Name: "log", Entries: []ini.Entry{{Key: "level", Value: "info"}}},
+		},
+	}, schema.WithDefaults(doc))
+	assert.Len(t, doc.Sections[1].Entries, 1, "the input is not modified")
+}
@@ -0,0 +1,69 @@
+package ini
+
+import (
+	"strconv"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// InferTypes parses the values of the entries when a document is loaded, and records them with
+// their types in the Typed fields of the document and its sections, next to the entries
+// themselves. A value is an int64 if it is a decimal integer with an optional sign, a float64 if
+// it is a decimal number with a fraction, as in "-1.5", and a bool if GetBool accepts it, e.g.
+// "yes". Any other value, including an integer out of the range of int64, stays a string.
+// The Typed fields are not kept up to date by the functions that build new documents, such as Merge.
+func InferTypes() Option {
+	return func(c *config) {
+		c.inferTypes = true
+	}
+}
+
+// finish completes a document parsed according to the configuration c: it records the typed
+// values if c infers types, and indexes the document.
+func (c *config) finish(ini Ini) Ini {
+	ini = c.typed(ini)
+	ini.reindex()
+	return ini
+}
+
+// typed returns ini with its Typed fields set if the configuration c infers types.
+func (c *config) typed(ini Ini) Ini {
+	if !c.inferTypes {
+		return ini
+	}
+	ini.Typed = typedEntries(ini.Global)
+	sections := make([]Section, len(ini.Sections))
+	for i, s := range ini.Sections {
+		s.Typed = typedEntries(s.Entries)
+		sections[i] = s
+	}
+	ini.Sections = sections
+	return ini
+}
+
+// typedEntries returns entries with their values converted by typedValue.
+func typedEntries(entries []Entry) []TypedEntry {
+	typed := make([]TypedEntry, len(entries))
+	for i, e := range entries {
+		typed[i] = TypedEntry{Key: e.Key, Value: typedValue(e.Value)}
+	}
+	return typed
+}
+
+// typedValue converts the value v to the type InferTypes infers for it.
+func typedValue(v string) any {
+	if n, err := parser.Run(parser.Integer(), v); err == nil {
+		// Integer does not detect overflow
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+		return v
+	}
+	if f, err := parser.Run(parser.Float(), v); err == nil {
+		return f
+	}
+	if b, ok := parseBool(v); ok {
+		return b
+	}
+	return v
+}
@@ -0,0 +1,292 @@
+package ini
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// The argument must be a non-nil pointer to a struct.
+type InvalidUnmarshalError struct {
+	// Type is the type of the argument, or nil if the argument was nil.
+	Type reflect.Type
+}
+
+// Error implements the error interface.
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "ini: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "ini: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	if e.Type.Elem().Kind() != reflect.Struct {
+		return "ini: Unmarshal(non-struct " + e.Type.String() + ")"
+	}
+	return "ini: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// UnmarshalTypeError describes an INI value that cannot be converted to the type of the Go field it is decoded into.
+type UnmarshalTypeError struct {
+	// Value is the INI value.
+	Value string
+	// Type is the Go type the value could not be converted to.
+	Type reflect.Type
+	// Section is the name of the section holding the value, or "" for a global entry.
+	Section string
+	// Key is the key of the value.
+	Key string
+}
+
+// Error implements the error interface.
+func (e *UnmarshalTypeError) Error() string {
+	where := e.Key
+	if e.Section != "" {
+		where = "[" + e.Section + "] " + e.Key
+	}
+	return "ini: cannot unmarshal " + strconv.Quote(e.Value) + " into Go value of type " + e.Type.String() + " at " + where
+}
+
+// Unmarshal parses the INI document data and stores its values in the struct pointed to by v.
+//
+// Global entries are stored in the fields of the struct, and each section in a field holding a
+// struct, a pointer to a struct or a map with string keys. Subsections such as [server.tls] are
+// stored in the fields of the struct holding their parent section. Fields are matched by their
+// `ini:"name"` tag, or else case-insensitively by their name; a tag of "-" skips the field.
+// Values are converted to strings, booleans (accepting yes/no and on/off like GetBool), integers,
+// floats and time.Duration, to types implementing encoding.TextUnmarshaler, and to slices of these
+// from comma-separated lists, or from the entries of a key written with the array suffix, "key[]". Entries without a matching field are ignored. If a section or key
+// occurs more than once, the last value wins.
+func Unmarshal(data string, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	ini, err := newConfig(opts).parse(data)
+	if err != nil {
+		return err
+	}
+	return UnmarshalValue(ini, v)
+}
+
+// UnmarshalValue stores the values of the already parsed document ini in the struct pointed to by v, like Unmarshal.
+func UnmarshalValue(ini Ini, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	rv = rv.Elem()
+	if err := unmarshalEntries(ini.Global, rv, ""); err != nil {
+		return err
+	}
+	for _, s := range ini.Sections {
+		fv, ok := sectionField(rv, s.Name)
+		if !ok {
+			continue
+		}
+		if err := unmarshalEntries(s.Entries, fv, s.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sectionField returns the struct or map holding the named section in the struct rv, allocating
+// struct pointers on the way. A field named after the whole section name is used if there is one;
+// otherwise the components of the section path select nested struct fields, so that [server.tls]
+// is stored in the TLS field of the Server field.
+func sectionField(rv reflect.Value, name string) (reflect.Value, bool) {
+	if fv, ok := fieldFor(rv, name); ok {
+		if fv, ok := sectionValue(fv); ok {
+			return fv, true
+		}
+	}
+	for _, c := range sectionPath(name) {
+		if rv.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		fv, ok := fieldFor(rv, c)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		if rv, ok = sectionValue(fv); !ok {
+			return reflect.Value{}, false
+		}
+	}
+	return rv, true
+}
+
+// sectionValue returns the struct or map the field fv holds, allocating a struct pointer if it is nil.
+func sectionValue(fv reflect.Value) (reflect.Value, bool) {
+	if !isSection(fv.Type()) {
+		return reflect.Value{}, false
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	return fv, true
+}
+
+// unmarshalEntries stores entries in the fields of the struct rv, or in the map rv.
+// Fields holding sections are left alone. section names the section of the entries for error messages.
+func unmarshalEntries(entries []Entry, rv reflect.Value, section string) error {
+	if rv.Kind() == reflect.Map {
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(entries)))
+		}
+		for _, e := range entries {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := setValue(elem, e.Value); err != nil {
+				return &UnmarshalTypeError{Value: e.Value, Type: elem.Type(), Section: section, Key: e.Key}
+			}
+			rv.SetMapIndex(reflect.ValueOf(e.Key).Convert(rv.Type().Key()), elem)
+		}
+		return nil
+	}
+	// arrays holds the slice fields filled from "key[]" entries in these entries
+	arrays := make(map[string]bool)
+	for _, e := range entries {
+		name, isArray := strings.CutSuffix(e.Key, "[]")
+		fv, ok := fieldFor(rv, name)
+		if !ok || isSection(fv.Type()) {
+			continue
+		}
+		if isArray && fv.Kind() == reflect.Slice {
+			if !arrays[name] {
+				arrays[name] = true
+				fv.Set(reflect.MakeSlice(fv.Type(), 0, 1))
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setValue(elem, e.Value); err != nil {
+				return &UnmarshalTypeError{Value: e.Value, Type: elem.Type(), Section: section, Key: e.Key}
+			}
+			fv.Set(reflect.Append(fv, elem))
+			continue
+		}
+		if err := setValue(fv, e.Value); err != nil {
+			return &UnmarshalTypeError{Value: e.Value, Type: fv.Type(), Section: section, Key: e.Key}
+		}
+	}
+	return nil
+}
+
+// textUnmarshalerType is the type of encoding.TextUnmarshaler.
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// isSection reports whether a field of type t holds a section rather than a value.
+func isSection(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+// fieldFor returns the field of the struct rv for the section or key name, preferring an exact
+// match of the tag or field name over a case-insensitive one.
+func fieldFor(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	match := -1
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("ini")
+		if tag == "-" || !sf.IsExported() {
+			continue
+		}
+		fname, _, _ := strings.Cut(tag, ",")
+		if fname == "" {
+			fname = sf.Name
+		}
+		if fname == name {
+			return rv.Field(i), true
+		}
+		if match < 0 && strings.EqualFold(fname, name) {
+			match = i
+		}
+	}
+	if match < 0 {
+		return reflect.Value{}, false
+	}
+	return rv.Field(match), true
+}
+
+// durationType is the type of time.Duration, which is decoded from its string form.
+var durationType = reflect.TypeFor[time.Duration]()
+
+// setValue converts the INI value s to the type of rv and stores it.
+func setValue(rv reflect.Value, s string) error {
+	if rv.Kind() == reflect.Pointer {
+		v := reflect.New(rv.Type().Elem())
+		if err := setValue(v.Elem(), s); err != nil {
+			return err
+		}
+		rv.Set(v)
+		return nil
+	}
+	if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, ok := parseBool(s)
+		if !ok {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		if strings.TrimSpace(s) != "" {
+			items = strings.Split(s, ",")
+		}
+		sl := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(sl.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		rv.Set(sl)
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+	return nil
+}
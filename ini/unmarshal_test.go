@@ -0,0 +1,146 @@
+package ini_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverConfig struct {
+	Host    string        `ini:"host"`
+	Port    uint16        `ini:"port"`
+	Debug   bool          `ini:"debug"`
+	Ratio   float64       `ini:"ratio"`
+	Timeout time.Duration `ini:"timeout"`
+	Tags    []string      `ini:"tags"`
+	Ports   []int         `ini:"ports"`
+	Addr    netip.Addr    `ini:"addr"`
+	Secret  string        `ini:"-"`
+}
+
+type appConfig struct {
+	Name    string
+	Version *int `ini:"version"`
+	Server  serverConfig
+	Cache   *struct {
+		Size int `ini:"size"`
+	} `ini:"cache"`
+	Env map[string]string `ini:"env"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := `name = app
+version = 3
+[Server]
+host = localhost
+port = 8080
+debug = yes
+ratio = 0.5
+timeout = 2s
+tags = a, b ,c
+ports = 1,2
+addr = 127.0.0.1
+secret = hidden
+unknown = ignored
+[cache]
+size = 0x10
+[env]
+HOME = /root
+PATH = /bin
+`
+	var cfg appConfig
+	assert.NoError(t, ini.Unmarshal(input, &cfg))
+	version := 3
+	assert.Equal(t, appConfig{
+		Name:    "app",
+		Version: &version,
+		Server: serverConfig{
+			Host:    "localhost",
+			Port:    8080,
+			Debug:   true,
+			Ratio:   0.5,
+			Timeout: 2 * time.Second,
+			Tags:    []string{"a", "b", "c"},
+			Ports:   []int{1, 2},
+			Addr:    netip.MustParseAddr("127.0.0.1"),
+		},
+		Cache: &struct {
+			Size int `ini:"size"`
+		}{Size: 16},
+		Env: map[string]string{"HOME": "/root", "PATH": "/bin"},
+	}, cfg)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Run("invalid argument", func(t *testing.T) {
+		var cfg appConfig
+		assert.EqualError(t, ini.Unmarshal("", nil), "ini: Unmarshal(nil)")
+		assert.EqualError(t, ini.Unmarshal("", cfg), "ini: Unmarshal(non-pointer ini_test.appConfig)")
+		assert.EqualError(t, ini.Unmarshal("", (*appConfig)(nil)), "ini: Unmarshal(nil *ini_test.appConfig)")
+		var m map[string]string
+		assert.EqualError(t, ini.Unmarshal("", &m), "ini: Unmarshal(non-struct *map[string]string)")
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var cfg appConfig
+		err := ini.Unmarshal("[server]\nport = 70000", &cfg)
+		var typeErr *ini.UnmarshalTypeError
+		assert.ErrorAs(t, err, &typeErr)
+		assert.Equal(t, "server", typeErr.Section)
+		assert.EqualError(t, err, `ini: cannot unmarshal "70000" into Go value of type uint16 at [server] port`)
+
+		err = ini.Unmarshal("version = three", &cfg)
+		assert.EqualError(t, err, `ini: cannot unmarshal "three" into Go value of type *int at version`)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		var cfg appConfig
+		err := ini.Unmarshal("[server]\nport", &cfg)
+		var perr *parser.Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 2, perr.Line)
+	})
+}
+
+func TestUnmarshalSubsections(t *testing.T) {
+	var cfg struct {
+		Server struct {
+			Port int `ini:"port"`
+			TLS  *struct {
+				Cert string `ini:"cert"`
+			} `ini:"tls"`
+		} `ini:"server"`
+		Remotes struct {
+			Origin map[string]string `ini:"origin"`
+		} `ini:"remote"`
+		Env map[string]string `ini:"env"`
+	}
+	input := "[server]\nport = 443\n[server.tls]\ncert = a.pem\n[remote \"origin\"]\nurl = x\n[env.extra]\nignored = 1\n"
+	assert.NoError(t, ini.Unmarshal(input, &cfg))
+	assert.Equal(t, 443, cfg.Server.Port)
+	assert.Equal(t, "a.pem", cfg.Server.TLS.Cert)
+	assert.Equal(t, map[string]string{"url": "x"}, cfg.Remotes.Origin)
+	assert.Nil(t, cfg.Env)
+}
+
+func TestUnmarshalArrayKeys(t *testing.T) {
+	var cfg struct {
+		Build struct {
+			Flags []string `ini:"flags"`
+			Jobs  []int    `ini:"jobs"`
+			Src   []string `ini:"src"`
+		} `ini:"build"`
+	}
+	input := "[build]\nflags[] = -O2\nflags[] = -g\njobs[] = 4\nsrc = a.c, b.c\n"
+	assert.NoError(t, ini.Unmarshal(input, &cfg, ini.OnDuplicateKey(ini.DuplicateError)))
+	assert.Equal(t, []string{"-O2", "-g"}, cfg.Build.Flags)
+	assert.Equal(t, []int{4}, cfg.Build.Jobs)
+	assert.Equal(t, []string{"a.c", "b.c"}, cfg.Build.Src)
+
+	err := ini.Unmarshal("[build]\njobs[] = many\n", &cfg)
+	assert.EqualError(t, err, `ini: cannot unmarshal "many" into Go value of type int at [build] jobs[]`)
+}
@@ -0,0 +1,135 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnwritable is reported by WriteINI for a section name, key or value that Parse would not
+// read back as it is, e.g. a key containing '=' or a value with leading spaces.
+var ErrUnwritable = errors.New("cannot be written as INI")
+
+// WriteOptions controls the layout of the text written by WriteINI.
+type WriteOptions struct {
+	// Delimiter is written between each key and its value; " = " if empty.
+	Delimiter string
+	// Align pads the keys of each section with spaces to the width of its longest key,
+	// so that the delimiters line up.
+	Align bool
+	// BlankLines is the number of blank lines written before each section header that
+	// follows other content.
+	BlankLines int
+	// MultilineValues writes values containing line breaks on indented continuation lines,
+	// which Parse reads back with the MultilineValues option. Without it, such values are
+	// rejected.
+	MultilineValues bool
+}
+
+// WriteINI writes the INI text of ini to w, laid out according to opts.
+// The global entries come first, then each section with its header and entries, in order.
+// The INI syntax has no quotes or escapes, so WriteINI fails with an error wrapping
+// ErrUnwritable, before writing anything, if the text would not be read back by Parse as it is.
+func WriteINI(w io.Writer, ini Ini, opts WriteOptions) error {
+	if err := checkINI(ini, opts); err != nil {
+		return err
+	}
+	_, err := w.Write(appendINI(nil, ini, opts))
+	return err
+}
+
+// String returns the INI text of ini with the default layout, a blank line between sections and
+// values with line breaks on continuation lines. Unlike WriteINI, it does not check that the
+// text can be read back.
+func (ini Ini) String() string {
+	return string(appendINI(nil, ini, WriteOptions{BlankLines: 1, MultilineValues: true}))
+}
+
+// checkINI checks that the section names, keys and values of ini are written by WriteINI with
+// opts as text that Parse reads back as it is.
+func checkINI(ini Ini, opts WriteOptions) error {
+	if opts.Delimiter != "" && strings.TrimSpace(opts.Delimiter) != "=" {
+		return fmt.Errorf("%w: delimiter %q", ErrUnwritable, opts.Delimiter)
+	}
+	// Names, keys and lines of values are trimmed by the parser
+	trimmed := func(s string) bool { return s == strings.TrimSpace(s) }
+	check := func(entries []Entry) error {
+		for _, e := range entries {
+			if e.Key == "" || !trimmed(e.Key) || strings.ContainsAny(e.Key, "=\n") ||
+				strings.HasPrefix(e.Key, "[") || isCommentMarker(rune(e.Key[0])) {
+				return fmt.Errorf("%w: key %q", ErrUnwritable, e.Key)
+			}
+			// A trailing backslash joins the next line in multi-line values, and a continuation
+			// line must not look blank or like a comment
+			lines := strings.Split(e.Value, "\n")
+			bad := len(lines) > 1 && !opts.MultilineValues
+			for i, l := range lines {
+				bad = bad || !trimmed(l) || opts.MultilineValues && strings.HasSuffix(l, `\`) ||
+					i > 0 && (l == "" || isCommentMarker(rune(l[0])))
+			}
+			if bad {
+				return fmt.Errorf("%w: value %q of key %q", ErrUnwritable, e.Value, e.Key)
+			}
+		}
+		return nil
+	}
+	if err := check(ini.Global); err != nil {
+		return err
+	}
+	for _, s := range ini.Sections {
+		if s.Name == "" || !trimmed(s.Name) || strings.ContainsAny(s.Name, "]\n") {
+			return fmt.Errorf("%w: section %q", ErrUnwritable, s.Name)
+		}
+		if err := check(s.Entries); err != nil {
+			return fmt.Errorf("%w in section %q", err, s.Name)
+		}
+	}
+	return nil
+}
+
+// appendINI appends the INI text of ini to b, laid out according to opts.
+func appendINI(b []byte, ini Ini, opts WriteOptions) []byte {
+	if opts.Delimiter == "" {
+		opts.Delimiter = " = "
+	}
+	b = appendEntries(b, ini.Global, opts)
+	for _, s := range ini.Sections {
+		if len(b) > 0 {
+			for range opts.BlankLines {
+				b = append(b, '\n')
+			}
+		}
+		b = append(b, '[')
+		b = append(b, s.Name...)
+		b = append(b, "]\n"...)
+		b = appendEntries(b, s.Entries, opts)
+	}
+	return b
+}
+
+// appendEntries appends one line for each of entries to b, followed by any continuation lines.
+func appendEntries(b []byte, entries []Entry, opts WriteOptions) []byte {
+	width := 0
+	if opts.Align {
+		for _, e := range entries {
+			width = max(width, utf8.RuneCountInString(e.Key))
+		}
+	}
+	for _, e := range entries {
+		first, rest, multiline := strings.Cut(e.Value, "\n")
+		line := e.Key + strings.Repeat(" ", width-min(width, utf8.RuneCountInString(e.Key))) + opts.Delimiter + first
+		b = append(b, strings.TrimRight(line, " \t")...)
+		b = append(b, '\n')
+		if !multiline {
+			continue
+		}
+		for _, l := range strings.Split(rest, "\n") {
+			b = append(b, "    "...)
+			b = append(b, l...)
+			b = append(b, '\n')
+		}
+	}
+	return b
+}
@@ -0,0 +1,115 @@
+package ini_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/81120/tiny-parsec/ini"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteINI(t *testing.T) {
+	doc := ini.Ini{
+		Global: []ini.Entry{{Key: "name", Value: "app"}},
+		Sections: []ini.Section{
+			{Name: "server", Entries: []ini.Entry{
+				{Key: "host", Value: "localhost"},
+				{Key: "port", Value: "8080"},
+				{Key: "empty", Value: ""},
+			}},
+			{Name: "paths", Entries: []ini.Entry{{Key: "lib", Value: "/usr/lib\n/opt/lib"}}},
+			{Name: "none"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     ini.WriteOptions
+		expected string
+	}{
+		{
+			"default",
+			ini.WriteOptions{MultilineValues: true},
+			"name = app\n[server]\nhost = localhost\nport = 8080\nempty =\n[paths]\nlib = /usr/lib\n    /opt/lib\n[none]\n",
+		},
+		{
+			"delimiter and blank lines",
+			ini.WriteOptions{Delimiter: "=", BlankLines: 2, MultilineValues: true},
+			"name=app\n\n\n[server]\nhost=localhost\nport=8080\nempty=\n\n\n[paths]\nlib=/usr/lib\n    /opt/lib\n\n\n[none]\n",
+		},
+		{
+			"aligned",
+			ini.WriteOptions{Align: true, BlankLines: 1, MultilineValues: true},
+			"name = app\n\n[server]\nhost  = localhost\nport  = 8080\nempty =\n\n[paths]\nlib = /usr/lib\n    /opt/lib\n\n[none]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, ini.WriteINI(&buf, doc, tt.opts))
+			assert.Equal(t, tt.expected, buf.String())
+			back, err := ini.Parse(buf.String(), ini.MultilineValues())
+			assert.NoError(t, err)
+			assert.Equal(t, doc, back)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		text := doc.String()
+		assert.Equal(t, "name = app\n\n[server]\n", text[:len("name = app\n\n[server]\n")])
+		back, err := ini.Parse(text, ini.MultilineValues())
+		assert.NoError(t, err)
+		assert.Equal(t, doc, back)
+
+		odd := ini.Ini{
+			Global: []ini.Entry{{Key: "url", Value: "http://example.com/?a=b;c#d"}, {Key: "a b", Value: "[x] = y"}},
+			Sections: []ini.Section{
+				{Name: "with spaces [1", Entries: []ini.Entry{{Key: "k[]", Value: `C:\dir\`}, {Key: "k[]", Value: "é"}}},
+				{Name: "with spaces [1", Entries: []ini.Entry{{Key: "k]", Value: "; not a comment"}}},
+			},
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, ini.WriteINI(&buf, odd, ini.WriteOptions{Delimiter: "=", Align: true}))
+		back, err = ini.Parse(buf.String())
+		assert.NoError(t, err)
+		assert.Equal(t, odd, back)
+	})
+
+	t.Run("unwritable", func(t *testing.T) {
+		tests := []struct {
+			name string
+			ini  ini.Ini
+			opts ini.WriteOptions
+			msg  string
+		}{
+			{"key with delimiter", ini.Ini{Global: []ini.Entry{{Key: "a=b", Value: "c"}}}, ini.WriteOptions{}, `cannot be written as INI: key "a=b"`},
+			{"key like a comment", ini.Ini{Global: []ini.Entry{{Key: "#a", Value: "c"}}}, ini.WriteOptions{}, `cannot be written as INI: key "#a"`},
+			{"key like a header", ini.Ini{Global: []ini.Entry{{Key: "[a]", Value: "c"}}}, ini.WriteOptions{}, `cannot be written as INI: key "[a]"`},
+			{"blank key", ini.Ini{Global: []ini.Entry{{Key: " ", Value: "c"}}}, ini.WriteOptions{}, `cannot be written as INI: key " "`},
+			{"padded value", ini.Ini{Sections: []ini.Section{{Name: "s", Entries: []ini.Entry{{Key: "a", Value: " b "}}}}}, ini.WriteOptions{}, `cannot be written as INI: value " b " of key "a" in section "s"`},
+			{"line breaks", ini.Ini{Global: []ini.Entry{{Key: "a", Value: "b\nc"}}}, ini.WriteOptions{}, `cannot be written as INI: value "b\nc" of key "a"`},
+			{"blank continuation line", ini.Ini{Global: []ini.Entry{{Key: "a", Value: "b\n\nc"}}}, ini.WriteOptions{MultilineValues: true}, `cannot be written as INI: value "b\n\nc" of key "a"`},
+			{"comment continuation line", ini.Ini{Global: []ini.Entry{{Key: "a", Value: "b\n;c"}}}, ini.WriteOptions{MultilineValues: true}, `cannot be written as INI: value "b\n;c" of key "a"`},
+			{"trailing backslash", ini.Ini{Global: []ini.Entry{{Key: "a", Value: "b\\\nc"}}}, ini.WriteOptions{MultilineValues: true}, `cannot be written as INI: value "b\\\nc" of key "a"`},
+			{"bracket in section", ini.Ini{Sections: []ini.Section{{Name: "a]b"}}}, ini.WriteOptions{}, `cannot be written as INI: section "a]b"`},
+			{"line break in section", ini.Ini{Sections: []ini.Section{{Name: "a\nb"}}}, ini.WriteOptions{}, `cannot be written as INI: section "a\nb"`},
+			{"empty section", ini.Ini{Sections: []ini.Section{{Name: ""}}}, ini.WriteOptions{}, `cannot be written as INI: section ""`},
+			{"delimiter", ini.Ini{}, ini.WriteOptions{Delimiter: ": "}, `cannot be written as INI: delimiter ": "`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				err := ini.WriteINI(&buf, tt.ini, tt.opts)
+				assert.ErrorIs(t, err, ini.ErrUnwritable)
+				assert.EqualError(t, err, tt.msg)
+				assert.Empty(t, buf.String())
+			})
+		}
+	})
+
+	t.Run("empty document", func(t *testing.T) {
+		assert.Equal(t, "[a]\n", ini.Ini{Sections: []ini.Section{{Name: "a"}}}.String())
+		assert.Equal(t, "", ini.Ini{}.String())
+	})
+}
@@ -1,12 +1,22 @@
 // Package json defines a set of types to represent JSON data in Go.
 package json
 
+import (
+	"slices"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
 // Json is an interface that all JSON types must implement.
 // It includes a method to indicate the type of the JSON value.
 type Json interface {
 	// jsonType is a method that all JSON types must implement.
 	// It serves as a marker for the JSON type.
 	jsonType()
+	// String returns the compact JSON text of the value.
+	String() string
+	// Pointer resolves a JSON Pointer (RFC 6901) against the value.
+	Pointer(ptr string) parser.Maybe[Json]
 }
 
 // JsonNull represents a JSON null value.
@@ -69,6 +79,74 @@ func (j JsonArray) jsonType() {}
 type JsonObject struct {
 	// Val is the map of string keys to Json values that make up the JSON object.
 	Val map[string]Json
+	// Keys lists the keys of Val in document order. Objects built without it are treated as sorted by key.
+	Keys []string
+}
+
+// NewObject creates an object from pairs, keeping their order.
+// If a key occurs more than once, the last value wins but the key keeps its first position.
+func NewObject(pairs ...JsonPair) JsonObject {
+	obj := JsonObject{Val: make(map[string]Json, len(pairs)), Keys: make([]string, 0, len(pairs))}
+	for _, p := range pairs {
+		obj.Set(p.Key, p.Value)
+	}
+	return obj
+}
+
+// Get returns the value of the member key and whether it exists.
+func (j JsonObject) Get(key string) (Json, bool) {
+	v, ok := j.Val[key]
+	return v, ok
+}
+
+// Set sets the member key to v. A new key is added after the existing ones.
+func (j *JsonObject) Set(key string, v Json) {
+	if j.Val == nil {
+		j.Val = make(map[string]Json)
+	}
+	if _, ok := j.Val[key]; !ok {
+		j.Keys = append(j.Keys, key)
+	}
+	j.Val[key] = v
+}
+
+// Len returns the number of members.
+func (j JsonObject) Len() int {
+	return len(j.Val)
+}
+
+// OrderedKeys returns the member keys in document order. Keys missing from Keys,
+// e.g. because Val was filled directly, follow in sorted order.
+func (j JsonObject) OrderedKeys() []string {
+	keys := make([]string, 0, len(j.Val))
+	seen := make(map[string]bool, len(j.Keys))
+	for _, k := range j.Keys {
+		if _, ok := j.Val[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == len(j.Val) {
+		return keys
+	}
+	rest := make([]string, 0, len(j.Val)-len(keys))
+	for k := range j.Val {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	return append(keys, rest...)
+}
+
+// Members returns the members as key-value pairs in document order.
+func (j JsonObject) Members() []JsonPair {
+	keys := j.OrderedKeys()
+	pairs := make([]JsonPair, len(keys))
+	for i, k := range keys {
+		pairs[i] = JsonPair{Key: k, Value: j.Val[k]}
+	}
+	return pairs
 }
 
 // jsonType implements the Json interface for JsonObject.
@@ -9,6 +9,7 @@ import (
 
 func BenchmarkSimpleObject(b *testing.B) {
 	data := `{"name":"John", "age":30, "active":true}`
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		json.ParseJSON(data)
 	}
@@ -16,6 +17,7 @@ func BenchmarkSimpleObject(b *testing.B) {
 
 func BenchmarkNestedStructure(b *testing.B) {
 	data := `{"a":{"b":{"c":{"d":[1,2,{"e":3}]}}}}`
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		json.ParseJSON(data)
@@ -35,6 +37,7 @@ func BenchmarkLargeArray(b *testing.B) {
 	}
 	sb.WriteString(`]`)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		json.ParseJSON(sb.String())
@@ -50,8 +53,25 @@ func BenchmarkMixedTypes(b *testing.B) {
 		"arr": [1, "two", false],
 		"obj": {"key": [{}]}
 	}`
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		json.ParseJSON(data)
 	}
 }
+
+func BenchmarkValid(b *testing.B) {
+	data := `{"a":{"b":{"c":{"d":[1,2,{"e":3}]}}}}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		json.Valid(data)
+	}
+}
+
+func BenchmarkNestedStructureFastPath(b *testing.B) {
+	data := `{"a":{"b":{"c":{"d":[1,2,{"e":3}]}}}}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		json.ParseJSON(data, json.FastPath())
+	}
+}
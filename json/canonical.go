@@ -0,0 +1,119 @@
+package json
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonical returns the JSON Canonicalization Scheme (RFC 8785) form of j: no whitespace,
+// object members sorted by the UTF-16 code units of their keys, numbers formatted like
+// ECMAScript does for IEEE 754 doubles, and strings escaped minimally. The output is
+// byte-stable across runs, so it can be hashed or signed.
+//
+// All numbers are treated as doubles, so integers beyond ±2^53 lose precision as they would
+// in any JCS implementation. It fails with an *UnsupportedValueError for NaN and infinities.
+func Canonical(j Json) ([]byte, error) {
+	return appendCanonical(nil, j)
+}
+
+// appendCanonical appends the canonical form of j to b.
+func appendCanonical(b []byte, j Json) ([]byte, error) {
+	var e encoder
+	var err error
+	switch j := j.(type) {
+	case JsonBool, JsonNull, JsonString:
+		b, _ = e.appendValue(b, j, 0)
+	case JsonInt:
+		b, err = appendES6Number(b, JsonFloat{Val: float64(j.Val)})
+	case JsonFloat:
+		b, err = appendES6Number(b, j)
+	case JsonNumber:
+		f, ferr := j.AsFloat64()
+		if ferr != nil {
+			return nil, &UnsupportedValueError{Value: j, Str: j.Val}
+		}
+		b, err = appendES6Number(b, JsonFloat{Val: f})
+	case JsonArray:
+		b = append(b, '[')
+		for i, v := range j.Val {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			if b, err = appendCanonical(b, v); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, ']')
+	case JsonObject:
+		keys := make([]string, 0, len(j.Val))
+		for k := range j.Val {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+		b = append(b, '{')
+		for i, k := range keys {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = e.appendString(b, k)
+			b = append(b, ':')
+			if b, err = appendCanonical(b, j.Val[k]); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, '}')
+	default:
+		b = append(b, "null"...)
+	}
+	return b, err
+}
+
+// appendES6Number appends a number formatted as by the ECMAScript Number.prototype.toString algorithm.
+func appendES6Number(b []byte, j JsonFloat) ([]byte, error) {
+	f := j.Val
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, &UnsupportedValueError{Value: j, Str: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	if f == 0 {
+		return append(b, '0'), nil
+	}
+	if f < 0 {
+		b = append(b, '-')
+		f = -f
+	}
+	// The shortest representation that round-trips, as d.ddde±x.
+	mant, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mant, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	k, n := len(digits), x+1
+	switch {
+	case k <= n && n <= 21:
+		b = append(b, digits...)
+		b = append(b, strings.Repeat("0", n-k)...)
+	case 0 < n && n <= 21:
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:]...)
+	case -6 < n && n <= 0:
+		b = append(b, "0."...)
+		b = append(b, strings.Repeat("0", -n)...)
+		b = append(b, digits...)
+	default:
+		b = append(b, digits[0])
+		if k > 1 {
+			b = append(b, '.')
+			b = append(b, digits[1:]...)
+		}
+		b = append(b, 'e')
+		if n-1 >= 0 {
+			b = append(b, '+')
+		}
+		b = strconv.AppendInt(b, int64(n-1), 10)
+	}
+	return b, nil
+}
@@ -0,0 +1,66 @@
+package json_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	t.Run("numbers", func(t *testing.T) {
+		tests := []struct {
+			in   float64
+			want string
+		}{
+			{0, "0"},
+			{math.Copysign(0, -1), "0"},
+			{1, "1"},
+			{-1.5, "-1.5"},
+			{1e21, "1e+21"},
+			{1e20, "100000000000000000000"},
+			{123e-7, "0.0000123"},
+			{1e-7, "1e-7"},
+			{4.50, "4.5"},
+			{2e-3, "0.002"},
+			{0.000001, "0.000001"},
+			{333333333.33333329, "333333333.3333333"},
+			{1.7976931348623157e308, "1.7976931348623157e+308"},
+			{5e-324, "5e-324"},
+		}
+		for _, tt := range tests {
+			b, err := json.Canonical(json.JsonFloat{Val: tt.in})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+		}
+	})
+
+	t.Run("document", func(t *testing.T) {
+		doc, err := json.ParseJSON(`{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`)
+		assert.NoError(t, err)
+		b, err := json.Canonical(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(b))
+	})
+
+	t.Run("keys sorted by utf-16 code units", func(t *testing.T) {
+		doc := json.JsonObject{Val: map[string]json.Json{
+			"\U0001F600": json.JsonInt{Val: 1},
+			"\ufb33":     json.JsonInt{Val: 2},
+			"a":          json.JsonInt{Val: 3},
+		}}
+		b, err := json.Canonical(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"a\":3,\"\U0001F600\":1,\"\ufb33\":2}", string(b))
+	})
+
+	t.Run("non-finite", func(t *testing.T) {
+		_, err := json.Canonical(json.JsonFloat{Val: math.NaN()})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,223 @@
+package json
+
+import (
+	"slices"
+	"strings"
+)
+
+// NodeComments are the comments around a node, recorded by ParseNode and ParseLenient with the
+// KeepComments option. Each comment is kept as written, including its delimiters, e.g. "// note"
+// or "/* note */".
+type NodeComments struct {
+	// Leading are the comments on the lines before the value. For an object member they are the
+	// comments before its key.
+	Leading []string
+	// Trailing are the comments after the value on the same line, e.g. after its comma.
+	Trailing []string
+	// Closing are the comments before the closing bracket of an array or object that follow its
+	// last element or member on later lines, or all the comments inside an empty one.
+	Closing []string
+}
+
+// KeepComments records the comments of a document in the Comments of the nodes built by ParseNode
+// and ParseLenient, so that FormatNode can write them back after the tree has been modified.
+// It implies Comments. Comments before and after the top-level value belong to the root node.
+func KeepComments() Option {
+	return func(c *config) {
+		c.comments = true
+		c.keepComments = true
+	}
+}
+
+// comment is a comment found in the source, with its byte offsets.
+type comment struct {
+	text       string
+	start, end int
+}
+
+// scanComments returns the comments of src in document order, skipping over strings.
+// Single-quoted strings are skipped as well if json5 is set.
+func scanComments(src string, json5 bool) []comment {
+	var cs []comment
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'' && json5:
+			i = skipString(src, i)
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexAny(src[i:], "\r\n")
+			if end < 0 {
+				end = len(src) - i
+			}
+			cs = append(cs, comment{text: src[i : i+end], start: i, end: i + end})
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i
+			} else {
+				end += 4
+			}
+			cs = append(cs, comment{text: src[i : i+end], start: i, end: i + end})
+			i += end
+		default:
+			i++
+		}
+	}
+	return cs
+}
+
+// skipString returns the offset just after the string starting with the quote at offset i,
+// or the length of src if the string is not terminated.
+func skipString(src string, i int) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		}
+	}
+	return len(src)
+}
+
+// attachComments attaches the comments cs of the source src to the tree rooted at root.
+func attachComments(root *Node, src string, cs []comment) {
+	var inside []comment
+	for _, c := range cs {
+		switch {
+		case c.end <= root.Span.Start.Offset:
+			root.Comments.Leading = append(root.Comments.Leading, c.text)
+		case c.start >= root.Span.End.Offset:
+			root.Comments.Trailing = append(root.Comments.Trailing, c.text)
+		default:
+			inside = append(inside, c)
+		}
+	}
+	attachInside(root, src, inside)
+}
+
+// child is an element or member of an array or object node, with the source range it covers.
+type child struct {
+	node       *Node
+	start, end int
+}
+
+// attachInside attaches the comments cs, which lie inside the array or object node n, to n and its descendants.
+// A comment belongs to the element or member it follows on the same line, or else to the one it precedes.
+func attachInside(n *Node, src string, cs []comment) {
+	children := make([]child, 0, len(n.Elements)+len(n.Members))
+	for _, e := range n.Elements {
+		children = append(children, child{node: e, start: e.Span.Start.Offset, end: e.Span.End.Offset})
+	}
+	for _, m := range n.Members {
+		children = append(children, child{node: m.Value, start: m.KeySpan.Start.Offset, end: m.Value.Span.End.Offset})
+	}
+	nested := make(map[*Node][]comment)
+	for _, c := range cs {
+		i := 0
+		for i < len(children) && children[i].end <= c.start {
+			i++
+		}
+		switch {
+		case i < len(children) && children[i].node.Span.Start.Offset <= c.start:
+			nested[children[i].node] = append(nested[children[i].node], c)
+		case i < len(children) && children[i].start <= c.start:
+			// Between the key and the value of a member.
+			next := children[i].node
+			next.Comments.Leading = append(next.Comments.Leading, c.text)
+		case i > 0 && !strings.Contains(src[children[i-1].end:c.start], "\n"):
+			prev := children[i-1].node
+			prev.Comments.Trailing = append(prev.Comments.Trailing, c.text)
+		case i < len(children):
+			next := children[i].node
+			next.Comments.Leading = append(next.Comments.Leading, c.text)
+		default:
+			n.Comments.Closing = append(n.Comments.Closing, c.text)
+		}
+	}
+	for _, ch := range children {
+		if cs := nested[ch.node]; cs != nil {
+			attachInside(ch.node, src, cs)
+		}
+	}
+}
+
+// FormatNode returns the JSON text of the tree rooted at n together with the comments recorded
+// in its nodes, so that a document parsed with KeepComments can be modified and written back.
+// Every element and member is written on its own line, indented by nesting level, with its
+// leading comments on the lines before it and its trailing comments after it; the Compact option
+// is ignored. Arrays and objects are written from their Elements and Members, unless they have
+// none, in which case their Value is written, so that nodes for new values need only a Value.
+// It fails with an *UnsupportedValueError if the tree contains a value JSON cannot represent.
+func FormatNode(n *Node, opts FormatOptions) ([]byte, error) {
+	e := encoder{opts: opts}
+	e.opts.Compact = false
+	var b []byte
+	for _, c := range n.Comments.Leading {
+		b = append(append(b, c...), '\n')
+	}
+	b, err := e.appendNode(b, n, 0)
+	for _, c := range n.Comments.Trailing {
+		b = append(append(b, ' '), c...)
+	}
+	return b, err
+}
+
+// appendNode appends the JSON text of the node n, nested depth levels deep, with the comments of its descendants.
+func (e *encoder) appendNode(b []byte, n *Node, depth int) ([]byte, error) {
+	switch n.Value.(type) {
+	case JsonArray:
+		if len(n.Elements) > 0 || len(n.Comments.Closing) > 0 {
+			return e.appendItems(b, '[', ']', n.Elements, nil, n.Comments.Closing, depth)
+		}
+	case JsonObject:
+		if len(n.Members) > 0 || len(n.Comments.Closing) > 0 {
+			members := n.Members
+			if e.opts.SortKeys {
+				members = slices.Clone(members)
+				slices.SortStableFunc(members, func(a, b *Member) int { return strings.Compare(a.Key, b.Key) })
+			}
+			nodes := make([]*Node, len(members))
+			keys := make([]string, len(members))
+			for i, m := range members {
+				nodes[i], keys[i] = m.Value, m.Key
+			}
+			return e.appendItems(b, '{', '}', nodes, keys, n.Comments.Closing, depth)
+		}
+	}
+	return e.appendValue(b, n.Value, depth)
+}
+
+// appendItems appends the elements of an array, or the members of an object if keys holds their keys,
+// one per line and surrounded by their comments, followed by the closing comments of the container.
+func (e *encoder) appendItems(b []byte, open, close byte, nodes []*Node, keys []string, closing []string, depth int) ([]byte, error) {
+	var err error
+	b = append(b, open)
+	for i, n := range nodes {
+		for _, c := range n.Comments.Leading {
+			b = append(e.appendNewline(b, depth+1), c...)
+		}
+		b = e.appendNewline(b, depth+1)
+		if keys != nil {
+			b = append(e.appendString(b, keys[i]), ':', ' ')
+		}
+		var nerr error
+		b, nerr = e.appendNode(b, n, depth+1)
+		if err == nil {
+			err = nerr
+		}
+		if i < len(nodes)-1 {
+			b = append(b, ',')
+		}
+		for _, c := range n.Comments.Trailing {
+			b = append(append(b, ' '), c...)
+		}
+	}
+	for _, c := range closing {
+		b = append(e.appendNewline(b, depth+1), c...)
+	}
+	b = e.appendNewline(b, depth)
+	return append(b, close), err
+}
@@ -0,0 +1,109 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+const settings = `// Editor settings
+{
+  // Font
+  "font": "mono", // the default
+  "size": /* px */ 12,
+  "rulers": [
+    80, // soft
+    // hard limit
+    120
+  ],
+  "empty": [
+    // nothing yet
+  ]
+  // more to come
+} // end`
+
+func TestKeepComments(t *testing.T) {
+	n, err := json.ParseNode(settings, json.KeepComments())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"// Editor settings"}, n.Comments.Leading)
+	assert.Equal(t, []string{"// end"}, n.Comments.Trailing)
+	assert.Equal(t, []string{"// more to come"}, n.Comments.Closing)
+
+	font := n.Member("font")
+	assert.Equal(t, json.NodeComments{Leading: []string{"// Font"}, Trailing: []string{"// the default"}}, font.Comments)
+	assert.Equal(t, []string{"/* px */"}, n.Member("size").Comments.Leading)
+
+	rulers := n.Member("rulers")
+	assert.Equal(t, []string{"// soft"}, rulers.Elements[0].Comments.Trailing)
+	assert.Equal(t, []string{"// hard limit"}, rulers.Elements[1].Comments.Leading)
+	assert.Equal(t, []string{"// nothing yet"}, n.Member("empty").Comments.Closing)
+
+	t.Run("off by default", func(t *testing.T) {
+		n, err := json.ParseNode(settings, json.Comments())
+		assert.NoError(t, err)
+		assert.Equal(t, json.NodeComments{}, n.Comments)
+		assert.Equal(t, json.NodeComments{}, n.Member("font").Comments)
+	})
+
+	t.Run("comment markers in strings", func(t *testing.T) {
+		n, err := json.ParseNode(`{"url": "http://x/*y*/"} // c`, json.KeepComments())
+		assert.NoError(t, err)
+		assert.Equal(t, json.NodeComments{}, n.Member("url").Comments)
+		assert.Equal(t, []string{"// c"}, n.Comments.Trailing)
+	})
+}
+
+func TestFormatNodeRoundTrip(t *testing.T) {
+	n, err := json.ParseNode(settings, json.KeepComments())
+	assert.NoError(t, err)
+
+	b, err := json.FormatNode(n, json.FormatOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `// Editor settings
+{
+  // Font
+  "font": "mono", // the default
+  /* px */
+  "size": 12,
+  "rulers": [
+    80, // soft
+    // hard limit
+    120
+  ],
+  "empty": [
+    // nothing yet
+  ]
+  // more to come
+} // end`, string(b))
+
+	t.Run("after modification", func(t *testing.T) {
+		n.Member("font").Value = json.JsonString{Val: "serif"}
+		rulers := n.Member("rulers")
+		rulers.Elements = rulers.Elements[1:]
+		n.Members = append(n.Members, &json.Member{Key: "tabs", Value: &json.Node{
+			Value:    json.JsonObject{Val: map[string]json.Json{"size": json.JsonInt{Val: 4}}, Keys: []string{"size"}},
+			Comments: json.NodeComments{Leading: []string{"// added"}},
+		}})
+		b, err := json.FormatNode(n, json.FormatOptions{Indent: "\t", SortKeys: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "// Editor settings\n{\n"+
+			"\t\"empty\": [\n\t\t// nothing yet\n\t],\n"+
+			"\t// Font\n\t\"font\": \"serif\", // the default\n"+
+			"\t\"rulers\": [\n\t\t// hard limit\n\t\t120\n\t],\n"+
+			"\t/* px */\n\t\"size\": 12,\n"+
+			"\t// added\n\t\"tabs\": {\n\t\t\"size\": 4\n\t}\n"+
+			"\t// more to come\n} // end", string(b))
+
+		back, err := json.ParseNode(string(b), json.KeepComments())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"// added"}, back.Member("tabs").Comments.Leading)
+	})
+}
+
+func TestParseLenientKeepsComments(t *testing.T) {
+	n, errs := json.ParseLenient("[\n  1, // one\n  ?,\n  3 // three\n]", json.KeepComments())
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"// one"}, n.Elements[0].Comments.Trailing)
+	assert.Equal(t, []string{"// three"}, n.Elements[1].Comments.Trailing)
+}
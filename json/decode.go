@@ -0,0 +1,224 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Decoder converts a JSON value into a T, failing with an *UnmarshalTypeError if the value has the wrong shape.
+type Decoder[T any] func(j Json) (T, error)
+
+// decoders holds the decoders registered for each type, keyed by reflect.Type.
+var decoders sync.Map
+
+// init registers the decoders for the builtin types.
+func init() {
+	Register(AsString)
+	Register(AsBool)
+	Register(AsInt)
+	Register(AsFloat)
+	Register(func(j Json) (int, error) {
+		i, err := AsInt(j)
+		if err == nil && int64(int(i)) != i {
+			return 0, &UnmarshalTypeError{Value: describeValue(j), Type: reflect.TypeFor[int]()}
+		}
+		return int(i), err
+	})
+	Register(func(j Json) (Json, error) { return j, nil })
+	Register(func(j Json) (any, error) { return ToNative(j), nil })
+}
+
+// Register sets the decoder used by Decode for values of type T, replacing any previous one.
+// It is safe to call concurrently with Decode.
+func Register[T any](d Decoder[T]) {
+	decoders.Store(reflect.TypeFor[T](), d)
+}
+
+// Decode parses the JSON document s and converts it into a T with the decoder registered for T.
+// Decoders for string, bool, int, int64, float64, Json and any are registered by default;
+// other types, such as structs, need a decoder built with Struct, ArrayOf or MapOf.
+func Decode[T any](s string, opts ...Option) (T, error) {
+	var zero T
+	d, ok := decoders.Load(reflect.TypeFor[T]())
+	if !ok {
+		return zero, fmt.Errorf("json: no decoder registered for %v", reflect.TypeFor[T]())
+	}
+	j, err := ParseJSON(s, opts...)
+	if err != nil {
+		return zero, err
+	}
+	return d.(Decoder[T])(j)
+}
+
+// AsString decodes a JSON string.
+func AsString(j Json) (string, error) {
+	if s, ok := j.(JsonString); ok {
+		return s.Val, nil
+	}
+	return "", mismatch[string](j)
+}
+
+// AsBool decodes a JSON boolean.
+func AsBool(j Json) (bool, error) {
+	if b, ok := j.(JsonBool); ok {
+		return b.Val, nil
+	}
+	return false, mismatch[bool](j)
+}
+
+// AsInt decodes a JSON number without a fractional part.
+func AsInt(j Json) (int64, error) {
+	switch j := j.(type) {
+	case JsonInt:
+		return j.Val, nil
+	case JsonFloat:
+		if i := int64(j.Val); float64(i) == j.Val {
+			return i, nil
+		}
+	case JsonNumber:
+		if i, err := j.AsInt64(); err == nil {
+			return i, nil
+		}
+	}
+	return 0, mismatch[int64](j)
+}
+
+// AsFloat decodes a JSON number.
+func AsFloat(j Json) (float64, error) {
+	switch j := j.(type) {
+	case JsonInt:
+		return float64(j.Val), nil
+	case JsonFloat:
+		return j.Val, nil
+	case JsonNumber:
+		if f, err := j.AsFloat64(); err == nil {
+			return f, nil
+		}
+	}
+	return 0, mismatch[float64](j)
+}
+
+// ArrayOf returns a decoder for JSON arrays whose elements are decoded by elem.
+// A JSON null decodes to a nil slice.
+func ArrayOf[T any](elem Decoder[T]) Decoder[[]T] {
+	return func(j Json) ([]T, error) {
+		switch j := j.(type) {
+		case JsonNull:
+			return nil, nil
+		case JsonArray:
+			ts := make([]T, len(j.Val))
+			for i, e := range j.Val {
+				t, err := elem(e)
+				if err != nil {
+					return nil, withPath(err, fmt.Sprintf("[%d]", i))
+				}
+				ts[i] = t
+			}
+			return ts, nil
+		}
+		return nil, mismatch[[]T](j)
+	}
+}
+
+// MapOf returns a decoder for JSON objects whose member values are decoded by elem.
+// A JSON null decodes to a nil map.
+func MapOf[T any](elem Decoder[T]) Decoder[map[string]T] {
+	return func(j Json) (map[string]T, error) {
+		switch j := j.(type) {
+		case JsonNull:
+			return nil, nil
+		case JsonObject:
+			m := make(map[string]T, len(j.Val))
+			for k, v := range j.Val {
+				t, err := elem(v)
+				if err != nil {
+					return nil, withPath(err, k)
+				}
+				m[k] = t
+			}
+			return m, nil
+		}
+		return nil, mismatch[map[string]T](j)
+	}
+}
+
+// FieldMapper decodes one object member into a field of a T. It is created with Field or RequiredField.
+type FieldMapper[T any] struct {
+	name     string
+	required bool
+	set      func(*T, Json) error
+}
+
+// Field maps the object member name, decoded by dec, into a T with set.
+// If the member is missing or null, set is not called.
+func Field[T, F any](name string, dec Decoder[F], set func(*T, F)) FieldMapper[T] {
+	return FieldMapper[T]{
+		name: name,
+		set: func(t *T, j Json) error {
+			f, err := dec(j)
+			if err != nil {
+				return err
+			}
+			set(t, f)
+			return nil
+		},
+	}
+}
+
+// RequiredField is like Field but decoding fails if the member is missing.
+func RequiredField[T, F any](name string, dec Decoder[F], set func(*T, F)) FieldMapper[T] {
+	f := Field(name, dec, set)
+	f.required = true
+	return f
+}
+
+// Struct returns a decoder for JSON objects that starts from the zero T and applies the field mappers.
+// Members without a mapper are ignored.
+func Struct[T any](fields ...FieldMapper[T]) Decoder[T] {
+	return func(j Json) (T, error) {
+		var t T
+		obj, ok := j.(JsonObject)
+		if !ok {
+			return t, mismatch[T](j)
+		}
+		for _, f := range fields {
+			v, ok := obj.Val[f.name]
+			if !ok {
+				if f.required {
+					return t, fmt.Errorf("json: missing required member %q for %v", f.name, reflect.TypeFor[T]())
+				}
+				continue
+			}
+			if _, null := v.(JsonNull); null {
+				continue
+			}
+			if err := f.set(&t, v); err != nil {
+				return t, withPath(err, f.name)
+			}
+		}
+		return t, nil
+	}
+}
+
+// mismatch reports that j cannot be decoded into a T.
+func mismatch[T any](j Json) error {
+	return &UnmarshalTypeError{Value: describeValue(j), Type: reflect.TypeFor[T]()}
+}
+
+// withPath prefixes the path of an *UnmarshalTypeError with the member name or index seg, e.g. "name" or "[0]".
+func withPath(err error, seg string) error {
+	var terr *UnmarshalTypeError
+	if errors.As(err, &terr) {
+		switch {
+		case terr.Path == "":
+			terr.Path = seg
+		case terr.Path[0] == '[':
+			terr.Path = seg + terr.Path
+		default:
+			terr.Path = seg + "." + terr.Path
+		}
+	}
+	return err
+}
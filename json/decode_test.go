@@ -0,0 +1,72 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X, Y int64
+}
+
+type shape struct {
+	Name   string
+	Points []point
+	Meta   map[string]float64
+}
+
+func init() {
+	pointDecoder := json.Struct(
+		json.RequiredField("x", json.AsInt, func(p *point, v int64) { p.X = v }),
+		json.RequiredField("y", json.AsInt, func(p *point, v int64) { p.Y = v }),
+	)
+	json.Register(pointDecoder)
+	json.Register(json.Struct(
+		json.Field("name", json.AsString, func(s *shape, v string) { s.Name = v }),
+		json.Field("points", json.ArrayOf(pointDecoder), func(s *shape, v []point) { s.Points = v }),
+		json.Field("meta", json.MapOf(json.AsFloat), func(s *shape, v map[string]float64) { s.Meta = v }),
+	))
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		s, err := json.Decode[shape](`{"name": "tri", "points": [{"x": 0, "y": 0}, {"x": 1, "y": 2}], "meta": {"area": 1}, "extra": true}`)
+		assert.NoError(t, err)
+		assert.Equal(t, shape{Name: "tri", Points: []point{{0, 0}, {1, 2}}, Meta: map[string]float64{"area": 1}}, s)
+	})
+
+	t.Run("missing and null members", func(t *testing.T) {
+		s, err := json.Decode[shape](`{"name": null}`)
+		assert.NoError(t, err)
+		assert.Equal(t, shape{}, s)
+	})
+
+	t.Run("builtin types", func(t *testing.T) {
+		n, err := json.Decode[int](`42`)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, n)
+
+		v, err := json.Decode[any](`{"a": [1]}`)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": []any{int64(1)}}, v)
+	})
+
+	t.Run("path of mismatch", func(t *testing.T) {
+		_, err := json.Decode[shape](`{"points": [{"x": 0, "y": 0}, {"x": "1", "y": 2}]}`)
+		var terr *json.UnmarshalTypeError
+		assert.ErrorAs(t, err, &terr)
+		assert.Equal(t, "points[1].x", terr.Path)
+	})
+
+	t.Run("required member", func(t *testing.T) {
+		_, err := json.Decode[point](`{"x": 1}`)
+		assert.ErrorContains(t, err, `missing required member "y"`)
+	})
+
+	t.Run("unregistered type", func(t *testing.T) {
+		_, err := json.Decode[struct{ A int }](`{}`)
+		assert.ErrorContains(t, err, "no decoder registered")
+	})
+}
@@ -0,0 +1,237 @@
+package json
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// UnsupportedValueError is returned when a Json value cannot be represented in JSON text,
+// such as a JsonFloat holding NaN or an infinity.
+type UnsupportedValueError struct {
+	// Value is the offending value.
+	Value Json
+	// Str describes the value.
+	Str string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedValueError) Error() string {
+	return "json: unsupported value: " + e.Str
+}
+
+// String returns the JSON text of the null value.
+func (j JsonNull) String() string { return stringOf(j) }
+
+// MarshalJSON returns the JSON text of the null value.
+func (j JsonNull) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the JSON text of the boolean.
+func (j JsonBool) String() string { return stringOf(j) }
+
+// MarshalJSON returns the JSON text of the boolean.
+func (j JsonBool) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the JSON text of the integer.
+func (j JsonInt) String() string { return stringOf(j) }
+
+// MarshalJSON returns the JSON text of the integer.
+func (j JsonInt) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the JSON text of the float. NaN and infinities, which JSON cannot represent, are written as null.
+func (j JsonFloat) String() string { return stringOf(j) }
+
+// MarshalJSON returns the JSON text of the float. It fails with an *UnsupportedValueError for NaN and infinities.
+func (j JsonFloat) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the JSON text of the string, quoted and escaped.
+func (j JsonString) String() string { return stringOf(j) }
+
+// MarshalJSON returns the JSON text of the string, quoted and escaped.
+func (j JsonString) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the compact JSON text of the array.
+func (j JsonArray) String() string { return stringOf(j) }
+
+// MarshalJSON returns the compact JSON text of the array.
+func (j JsonArray) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// String returns the compact JSON text of the object, with members in document order.
+func (j JsonObject) String() string { return stringOf(j) }
+
+// MarshalJSON returns the compact JSON text of the object, with members in document order.
+func (j JsonObject) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// stringOf returns the JSON text of j, writing unsupported values as null.
+func stringOf(j Json) string {
+	b, _ := appendValue(nil, j)
+	return string(b)
+}
+
+// appendValue appends the compact JSON text of j to b, with object members in document order.
+// Unsupported values are written as null and reported with an *UnsupportedValueError.
+func appendValue(b []byte, j Json) ([]byte, error) {
+	e := encoder{opts: FormatOptions{Compact: true}}
+	return e.appendValue(b, j, 0)
+}
+
+// encoder writes Json values as JSON text according to its options.
+type encoder struct {
+	opts FormatOptions
+}
+
+// appendValue appends the JSON text of j, nested depth levels deep, to b.
+// Unsupported values are written as null and the first of them is reported with an *UnsupportedValueError.
+func (e *encoder) appendValue(b []byte, j Json, depth int) ([]byte, error) {
+	var err error
+	keep := func(eerr error) {
+		if err == nil {
+			err = eerr
+		}
+	}
+	switch j := j.(type) {
+	case JsonBool:
+		b = strconv.AppendBool(b, j.Val)
+	case JsonInt:
+		b = strconv.AppendInt(b, j.Val, 10)
+	case JsonFloat:
+		b, err = appendFloat(b, j)
+	case JsonNumber:
+		b = append(b, j.Val...)
+	case JsonString:
+		b = e.appendString(b, j.Val)
+	case JsonArray:
+		if len(j.Val) == 0 {
+			return append(b, "[]"...), nil
+		}
+		b = append(b, '[')
+		for i, v := range j.Val {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = e.appendNewline(b, depth+1)
+			var verr error
+			b, verr = e.appendValue(b, v, depth+1)
+			keep(verr)
+		}
+		b = e.appendNewline(b, depth)
+		b = append(b, ']')
+	case JsonObject:
+		if len(j.Val) == 0 {
+			return append(b, "{}"...), nil
+		}
+		b = append(b, '{')
+		for i, k := range e.keys(j) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = e.appendNewline(b, depth+1)
+			b = e.appendString(b, k)
+			b = append(b, ':')
+			if !e.opts.Compact {
+				b = append(b, ' ')
+			}
+			var verr error
+			b, verr = e.appendValue(b, j.Val[k], depth+1)
+			keep(verr)
+		}
+		b = e.appendNewline(b, depth)
+		b = append(b, '}')
+	default:
+		b = append(b, "null"...)
+	}
+	return b, err
+}
+
+// keys returns the member names of obj in the order they are written.
+func (e *encoder) keys(obj JsonObject) []string {
+	if e.opts.SortKeys {
+		return slices.Sorted(maps.Keys(obj.Val))
+	}
+	return obj.OrderedKeys()
+}
+
+// appendNewline starts a new line indented depth levels deep, unless the output is compact.
+func (e *encoder) appendNewline(b []byte, depth int) []byte {
+	if e.opts.Compact {
+		return b
+	}
+	indent := e.opts.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	b = append(b, '\n')
+	for range depth {
+		b = append(b, indent...)
+	}
+	return b
+}
+
+// appendFloat appends a float so that it reads back as a JsonFloat, e.g. 1 is written as 1.0.
+func appendFloat(b []byte, j JsonFloat) ([]byte, error) {
+	f := j.Val
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(b, "null"...), &UnsupportedValueError{Value: j, Str: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	start := len(b)
+	b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	for _, c := range b[start:] {
+		if c == '.' || c == 'e' {
+			return b, nil
+		}
+	}
+	return append(b, ".0"...), nil
+}
+
+// appendString appends s as a quoted JSON string. Quotes, backslashes and control characters
+// are escaped, and invalid UTF-8 is replaced by U+FFFD. Other characters are written as is,
+// or escaped as \uXXXX if the ASCII option is set.
+func (e *encoder) appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c == '\b':
+				b = append(b, '\\', 'b')
+			case c == '\f':
+				b = append(b, '\\', 'f')
+			case c < 0x20:
+				b = appendEscape(b, rune(c))
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case e.opts.ASCII && r > 0xffff:
+			r1, r2 := utf16.EncodeRune(r)
+			b = appendEscape(appendEscape(b, r1), r2)
+		case e.opts.ASCII || r == utf8.RuneError && n == 1:
+			b = appendEscape(b, r)
+		default:
+			b = append(b, s[i:i+n]...)
+		}
+		i += n
+	}
+	return append(b, '"')
+}
+
+// appendEscape appends the \uXXXX escape of the UTF-16 code unit r.
+func appendEscape(b []byte, r rune) []byte {
+	const hex = "0123456789abcdef"
+	return append(b, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+}
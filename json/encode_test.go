@@ -0,0 +1,60 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"math"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name string
+		val  json.Json
+		want string
+	}{
+		{"null", json.JsonNull{}, `null`},
+		{"bool", json.JsonBool{Val: true}, `true`},
+		{"int", json.JsonInt{Val: -42}, `-42`},
+		{"float", json.JsonFloat{Val: 2.5}, `2.5`},
+		{"integral float", json.JsonFloat{Val: 3}, `3.0`},
+		{"large float", json.JsonFloat{Val: 1e21}, `1e+21`},
+		{"escapes", json.JsonString{Val: "a\"b\\c\n\x01é"}, `"a\"b\\c\n\u0001é"`},
+		{"array", json.JsonArray{Val: []json.Json{json.JsonInt{Val: 1}, json.JsonNull{}}}, `[1,null]`},
+		{"object sorted", json.JsonObject{Val: map[string]json.Json{"b": json.JsonInt{Val: 2}, "a": json.JsonArray{}}}, `{"a":[],"b":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.val.String())
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		src := `{"list": [1, 2.0, "x\ty", {"nested": [true, false, null]}], "n": -0.5e-3}`
+		v, err := json.ParseJSON(src)
+		assert.NoError(t, err)
+		back, err := json.ParseJSON(v.String())
+		assert.NoError(t, err)
+		assert.Equal(t, v, back)
+	})
+
+	t.Run("accepted by encoding/json", func(t *testing.T) {
+		v, err := json.ParseJSON(`{"a": [1, "é"]}`)
+		assert.NoError(t, err)
+		b, err := stdjson.Marshal(map[string]json.Json{"doc": v})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"doc":{"a":[1,"é"]}}`, string(b))
+	})
+
+	t.Run("non-finite float", func(t *testing.T) {
+		j := json.JsonArray{Val: []json.Json{json.JsonFloat{Val: math.Inf(1)}}}
+		_, err := j.MarshalJSON()
+		var uerr *json.UnsupportedValueError
+		assert.ErrorAs(t, err, &uerr)
+		assert.Equal(t, `[null]`, j.String())
+	})
+}
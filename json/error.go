@@ -0,0 +1,106 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// SyntaxError describes why a JSON document could not be parsed.
+type SyntaxError struct {
+	// Offset is the byte offset of the error in the document.
+	Offset int
+	// Line is the 1-based line number of the error.
+	Line int
+	// Column is the 1-based column of the error, counted in runes.
+	Column int
+	// Found describes the input at Offset; it is empty at the end of input.
+	Found string
+	// Expected describes what the document should contain at Offset,
+	// e.g. "',' or '}' after object member".
+	Expected string
+	// Err is the underlying cause, either parser.ErrNoMatch or parser.ErrUnexpectedEOF.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	msg := fmt.Sprintf("json: line %d, col %d: ", e.Line, e.Column)
+	if e.Found == "" {
+		msg += "unexpected end of input, "
+	}
+	return msg + "expected " + e.Expected
+}
+
+// Unwrap returns the underlying cause so that errors.Is matches parser.ErrNoMatch and parser.ErrUnexpectedEOF.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// syntaxError converts an error returned by the parser package into a *SyntaxError.
+// Other errors, such as exceeded limits, are returned unchanged.
+func syntaxError(err error) error {
+	var perr *parser.Error
+	if !errors.As(err, &perr) {
+		return err
+	}
+	return &SyntaxError{
+		Offset:   perr.Offset,
+		Line:     perr.Line,
+		Column:   perr.Column,
+		Found:    perr.Found,
+		Expected: describeExpected(perr.Expected),
+		Err:      perr.Err,
+	}
+}
+
+// valueStart lists the descriptions of the parsers that can start a JSON value.
+// The JSON5 number forms are included so that they collapse into "value" as well.
+var valueStart = []string{
+	"string", `"-"`, `"0"`, "digit", `"true"`, `"false"`, `"null"`, "'['", "'{'",
+	"'+'", `"."`, `"0x"`, `"0X"`, `"Infinity"`, `"NaN"`,
+}
+
+// describeExpected phrases the expectations of the failing parsers in terms of the JSON grammar.
+func describeExpected(expected []string) string {
+	has := func(s string) bool { return slices.Contains(expected, s) }
+	switch {
+	case has(numberInRange):
+		// A number was read, so the other values that could start there do not matter
+		return numberInRange
+	case has("','") && has("'}'"):
+		return "',' or '}' after object member"
+	case has("','") && has("']'"):
+		return "',' or ']' after array element"
+	case has("':'"):
+		return "':' after object key"
+	case has("end of input") && !has("'['"):
+		return "end of input"
+	}
+	var parts []string
+	if has("'['") {
+		parts = append(parts, "value")
+	}
+	for _, e := range expected {
+		switch {
+		case e == "whitespace", slices.Contains(valueStart, e) && has("'['"):
+			continue
+		case e == "string", e == "identifier":
+			e = "object key"
+		}
+		// JSON5 keys may be strings or identifiers, which both read as an object key
+		if !slices.Contains(parts, e) {
+			parts = append(parts, e)
+		}
+	}
+	if len(parts) == 0 {
+		return "value"
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " or " + parts[len(parts)-1]
+}
@@ -0,0 +1,294 @@
+package json
+
+import (
+	"unicode/utf8"
+)
+
+// FastPath makes ParseJSON, Unmarshal and Decode parse with a dedicated tokenizer feeding a
+// token-level parser, instead of running the combinator grammar rune by rune. The results are
+// identical: malformed documents, documents hitting a limit and options the fast path does not
+// cover, such as JSON5, Comments, TrailingCommas and AllowNaN, fall back to the combinator
+// grammar, which also produces the error.
+func FastPath() Option {
+	return func(c *config) {
+		c.fast = true
+	}
+}
+
+// parseFast parses s with the fast path. It reports false if s is not accepted by the fast path,
+// in which case the caller parses s with the combinator grammar.
+func parseFast(s string, c *config) (Json, bool) {
+	if c.json5 || c.comments || c.trailingCommas || c.nonFinite || c.limits.MaxBytes > 0 && len(s) > c.limits.MaxBytes {
+		return nil, false
+	}
+	p := fastParser{lex: lexer{s: s, strict: c.strictStrings}, c: c}
+	v, ok := p.value(p.lex.next(), 0)
+	if !ok || p.lex.next().kind != tokEOF {
+		return nil, false
+	}
+	return v, true
+}
+
+// tokenKind is the kind of a token produced by the lexer.
+type tokenKind uint8
+
+const (
+	tokInvalid tokenKind = iota
+	tokEOF
+	tokObjectStart
+	tokObjectEnd
+	tokArrayStart
+	tokArrayEnd
+	tokColon
+	tokComma
+	tokString
+	tokNumber
+	tokTrue
+	tokFalse
+	tokNull
+)
+
+// token is a lexical element of a JSON document.
+type token struct {
+	kind tokenKind
+	// str is the decoded value of a string token.
+	str string
+	// num is the literal text of a number token, and integer reports whether it has neither a fraction nor an exponent.
+	num     string
+	integer bool
+}
+
+// lexer splits a JSON document into tokens.
+type lexer struct {
+	s      string
+	i      int
+	strict bool
+}
+
+// next skips whitespace and returns the next token. Malformed input yields tokInvalid.
+func (l *lexer) next() token {
+	s := l.s
+	for l.i < len(s) && (s[l.i] == ' ' || s[l.i] == '\t' || s[l.i] == '\n' || s[l.i] == '\r') {
+		l.i++
+	}
+	if l.i == len(s) {
+		return token{kind: tokEOF}
+	}
+	c := s[l.i]
+	switch c {
+	case '{':
+		l.i++
+		return token{kind: tokObjectStart}
+	case '}':
+		l.i++
+		return token{kind: tokObjectEnd}
+	case '[':
+		l.i++
+		return token{kind: tokArrayStart}
+	case ']':
+		l.i++
+		return token{kind: tokArrayEnd}
+	case ':':
+		l.i++
+		return token{kind: tokColon}
+	case ',':
+		l.i++
+		return token{kind: tokComma}
+	case '"':
+		return l.string()
+	case 't':
+		return l.keyword("true", tokTrue)
+	case 'f':
+		return l.keyword("false", tokFalse)
+	case 'n':
+		return l.keyword("null", tokNull)
+	}
+	if c == '-' || c >= '0' && c <= '9' {
+		return l.number()
+	}
+	return token{}
+}
+
+// keyword lexes the keyword lit as a token of the given kind.
+func (l *lexer) keyword(lit string, kind tokenKind) token {
+	if len(l.s)-l.i < len(lit) || l.s[l.i:l.i+len(lit)] != lit {
+		return token{}
+	}
+	l.i += len(lit)
+	return token{kind: kind}
+}
+
+// string lexes a string literal. Strings without escape sequences are returned without copying.
+func (l *lexer) string() token {
+	s := l.s
+	start := l.i + 1
+	i := start
+	for i < len(s) && s[i] != '"' && s[i] != '\\' && (!l.strict || s[i] >= 0x20) {
+		i++
+	}
+	if i < len(s) && s[i] == '"' {
+		l.i = i + 1
+		return token{kind: tokString, str: s[start:i]}
+	}
+	b := []byte(s[start:i])
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '"':
+			l.i = i + 1
+			return token{kind: tokString, str: string(b)}
+		case c == '\\':
+			r, n, ok := unescape(s[i:])
+			if !ok {
+				return token{}
+			}
+			b = utf8.AppendRune(b, r)
+			i += n
+		case l.strict && c < 0x20:
+			return token{}
+		default:
+			b = append(b, c)
+			i++
+		}
+	}
+	return token{}
+}
+
+// number lexes a number following the grammar of RFC 8259.
+func (l *lexer) number() token {
+	s := l.s
+	start := l.i
+	i := l.i
+	if s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		from := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i - from
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		digits()
+	default:
+		return token{}
+	}
+	integer := true
+	if i < len(s) && s[i] == '.' {
+		i++
+		integer = false
+		if digits() == 0 {
+			return token{}
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		integer = false
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return token{}
+		}
+	}
+	l.i = i
+	return token{kind: tokNumber, num: s[start:i], integer: integer}
+}
+
+// fastParser builds Json values from the tokens of a lexer.
+type fastParser struct {
+	lex lexer
+	c   *config
+}
+
+// value parses the value starting with the token t, nested depth levels deep.
+func (p *fastParser) value(t token, depth int) (Json, bool) {
+	switch t.kind {
+	case tokString:
+		return JsonString{Val: t.str}, true
+	case tokNumber:
+		v := p.c.number(t.num, t.integer)
+		return v, v != nil
+	case tokTrue:
+		return JsonBool{Val: true}, true
+	case tokFalse:
+		return JsonBool{Val: false}, true
+	case tokNull:
+		return JsonNull{}, true
+	case tokArrayStart:
+		return p.array(depth + 1)
+	case tokObjectStart:
+		return p.object(depth + 1)
+	}
+	return nil, false
+}
+
+// array parses the elements of an array after its opening bracket.
+func (p *fastParser) array(depth int) (Json, bool) {
+	if max := p.c.limits.MaxDepth; max > 0 && depth > max {
+		return nil, false
+	}
+	elems := []Json{}
+	t := p.lex.next()
+	if t.kind == tokArrayEnd {
+		return JsonArray{Val: elems}, true
+	}
+	for {
+		v, ok := p.value(t, depth)
+		if !ok {
+			return nil, false
+		}
+		elems = append(elems, v)
+		switch p.lex.next().kind {
+		case tokComma:
+			t = p.lex.next()
+		case tokArrayEnd:
+			return JsonArray{Val: elems}, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// object parses the members of an object after its opening brace.
+func (p *fastParser) object(depth int) (Json, bool) {
+	if max := p.c.limits.MaxDepth; max > 0 && depth > max {
+		return nil, false
+	}
+	var pairs []JsonPair
+	var seen map[string]bool
+	t := p.lex.next()
+	if t.kind == tokObjectEnd {
+		return buildObject(p.c.duplicates, pairs), true
+	}
+	for {
+		if t.kind != tokString || p.lex.next().kind != tokColon {
+			return nil, false
+		}
+		if p.c.duplicates == DuplicateError {
+			if seen[t.str] {
+				return nil, false
+			}
+			if seen == nil {
+				seen = make(map[string]bool)
+			}
+			seen[t.str] = true
+		}
+		v, ok := p.value(p.lex.next(), depth)
+		if !ok {
+			return nil, false
+		}
+		pairs = append(pairs, JsonPair{Key: t.str, Value: v})
+		switch p.lex.next().kind {
+		case tokComma:
+			t = p.lex.next()
+		case tokObjectEnd:
+			return buildObject(p.c.duplicates, pairs), true
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package json_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastPath(t *testing.T) {
+	docs := []string{
+		`null`, ` true `, `false`, `-0`, `12345678901234567890`, `3.141592653589793238462643`, `1E-3`,
+		`"plain"`, `"escé😀\n\"q\""`, "\"raw\tcontrol\"", `[]`, `{}`,
+		`{"a": [1, 2.5, {"b": null}], "c": "d", "a": 3}`,
+		`[1, 2`, `{"a" 1}`, `[1,]`, `01`, `"\x"`, `{"a":1} x`, `tru`,
+		strings.Repeat("[", 50) + strings.Repeat("]", 50),
+	}
+	optionSets := map[string][]json.Option{
+		"default":         nil,
+		"strict strings":  {json.StrictStrings()},
+		"precise numbers": {json.PreciseNumbers()},
+		"keep first":      {json.OnDuplicateKey(json.DuplicateKeepFirst)},
+		"collect":         {json.OnDuplicateKey(json.DuplicateCollect)},
+		"duplicate error": {json.OnDuplicateKey(json.DuplicateError)},
+		"depth limit":     {json.MaxDepth(3)},
+		"size limit":      {json.MaxBytes(10)},
+		"json5":           {json.JSON5()},
+	}
+	for name, opts := range optionSets {
+		t.Run(name, func(t *testing.T) {
+			for _, doc := range docs {
+				want, wantErr := json.ParseJSON(doc, opts...)
+				got, gotErr := json.ParseJSON(doc, append(opts, json.FastPath())...)
+				assert.Equal(t, want, got, doc)
+				assert.Equal(t, wantErr, gotErr, doc)
+			}
+		})
+	}
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var v struct {
+			Name string `json:"name"`
+			IDs  []int  `json:"ids"`
+		}
+		err := json.Unmarshal(`{"name": "x", "ids": [1, 2]}`, &v, json.FastPath())
+		assert.NoError(t, err)
+		assert.Equal(t, "x", v.Name)
+		assert.Equal(t, []int{1, 2}, v.IDs)
+	})
+}
@@ -0,0 +1,25 @@
+package json
+
+// FormatOptions configures how Format writes JSON text.
+type FormatOptions struct {
+	// Indent is the string used for each level of indentation; two spaces if empty.
+	Indent string
+	// Compact writes the value on a single line without any whitespace, ignoring Indent.
+	Compact bool
+	// SortKeys writes the members of every object sorted lexicographically by the bytes of their keys,
+	// so that equal values are written identically whatever order their members were parsed or set in,
+	// e.g. for readable diffs and golden files. Unlike Canonical, it keeps the other choices of layout
+	// and number formatting. Otherwise members are written in document order.
+	SortKeys bool
+	// ASCII escapes all non-ASCII characters in strings as \uXXXX, using surrogate pairs where needed.
+	ASCII bool
+}
+
+// Format returns the JSON text of j laid out according to opts.
+// By default every array element and object member is written on its own line,
+// indented by nesting level; empty arrays and objects are written as [] and {}.
+// It fails with an *UnsupportedValueError if j contains a value JSON cannot represent.
+func Format(j Json, opts FormatOptions) ([]byte, error) {
+	e := encoder{opts: opts}
+	return e.appendValue(nil, j, 0)
+}
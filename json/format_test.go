@@ -0,0 +1,64 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	doc, err := json.ParseJSON(`{"b": [1, {"c": []}], "a": "é😀"}`)
+	assert.NoError(t, err)
+
+	t.Run("indented", func(t *testing.T) {
+		b, err := json.Format(doc, json.FormatOptions{SortKeys: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n  \"a\": \"é😀\",\n  \"b\": [\n    1,\n    {\n      \"c\": []\n    }\n  ]\n}", string(b))
+	})
+
+	t.Run("custom indent", func(t *testing.T) {
+		b, err := json.Format(json.JsonArray{Val: []json.Json{json.JsonNull{}}}, json.FormatOptions{Indent: "\t"})
+		assert.NoError(t, err)
+		assert.Equal(t, "[\n\tnull\n]", string(b))
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		b, err := json.Format(doc, json.FormatOptions{Compact: true, SortKeys: true})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"é😀","b":[1,{"c":[]}]}`, string(b))
+	})
+
+	t.Run("ascii", func(t *testing.T) {
+		b, err := json.Format(doc, json.FormatOptions{Compact: true, SortKeys: true, ASCII: true})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"\u00e9\ud83d\ude00","b":[1,{"c":[]}]}`, string(b))
+	})
+
+	t.Run("output parses back", func(t *testing.T) {
+		b, err := json.Format(doc, json.FormatOptions{ASCII: true})
+		assert.NoError(t, err)
+		back, err := json.ParseJSON(string(b))
+		assert.NoError(t, err)
+		assert.Equal(t, doc, back)
+	})
+}
+
+func TestFormatSortKeys(t *testing.T) {
+	a, err := json.ParseJSON(`{"z": {"y": 1, "x": [{"b": 2, "a": 1}]}, "m": 1.5, "\ufb01": 0, "\ud83d\ude00": 0}`)
+	assert.NoError(t, err)
+	b, err := json.ParseJSON(`{"\ud83d\ude00": 0, "m": 1.5, "\ufb01": 0, "z": {"x": [{"a": 1, "b": 2}], "y": 1}}`)
+	assert.NoError(t, err)
+
+	fa, err := json.Format(a, json.FormatOptions{SortKeys: true})
+	assert.NoError(t, err)
+	fb, err := json.Format(b, json.FormatOptions{SortKeys: true})
+	assert.NoError(t, err)
+	assert.Equal(t, string(fa), string(fb))
+	assert.Equal(t, "{\n  \"m\": 1.5,\n  \"z\": {\n    \"x\": [\n      {\n        \"a\": 1,\n        \"b\": 2\n      }\n    ],\n    \"y\": 1\n  },\n  \"\ufb01\": 0,\n  \"\U0001f600\": 0\n}", string(fa))
+
+	// Canonical sorts by UTF-16 code units, which puts the surrogate pair of U+1F600 before U+FB01.
+	c, err := json.Canonical(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"m\":1.5,\"z\":{\"x\":[{\"a\":1,\"b\":2}],\"y\":1},\"\U0001f600\":0,\"\ufb01\":0}", string(c))
+}
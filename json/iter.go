@@ -0,0 +1,61 @@
+package json
+
+import (
+	"fmt"
+	"io"
+)
+
+// ArrayIterator yields the elements of a JSON array one at a time. It is created by ArrayIter.
+type ArrayIterator struct {
+	s       *Scanner
+	started bool
+}
+
+// ArrayIter returns an iterator over the elements of the JSON array read from r. Only the
+// current element is held in memory, so arrays far larger than the available memory can be
+// processed. The options select the grammar as for ParseJSON.
+func ArrayIter(r io.Reader, opts ...Option) *ArrayIterator {
+	return &ArrayIterator{s: NewScanner(r, opts...)}
+}
+
+// Next reads and returns the next element of the array. After the last element it checks that
+// the document ends with the array and returns io.EOF. If the document is not an array or is
+// malformed, it fails with an error as described for Scanner.Next; errors are final.
+func (it *ArrayIterator) Next() (Json, error) {
+	if !it.started {
+		e, err := it.s.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e.Kind != ArrayStart {
+			it.s.err = fmt.Errorf("json: document is not an array, found %s at offset %d", describeEvent(e), e.Offset)
+			return nil, it.s.err
+		}
+		it.started = true
+	}
+	if it.s.Depth() == 0 {
+		// The array has been closed; make sure nothing follows it.
+		_, err := it.s.Next()
+		return nil, err
+	}
+	e, err := it.s.Next()
+	if err != nil {
+		return nil, err
+	}
+	if e.Kind == ArrayEnd {
+		_, err := it.s.Next()
+		return nil, err
+	}
+	return it.s.decode(e)
+}
+
+// describeEvent describes the element of the document that produced e for error messages.
+func describeEvent(e Event) string {
+	switch e.Kind {
+	case ObjectStart:
+		return "object"
+	case Value:
+		return describeValue(e.Value)
+	}
+	return e.Kind.String()
+}
@@ -0,0 +1,74 @@
+package json_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayIter(t *testing.T) {
+	it := json.ArrayIter(iotest.OneByteReader(strings.NewReader(`[1, {"a": [true]}, "s", []]`)))
+	var got []string
+	for {
+		v, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{`1`, `{"a":[true]}`, `"s"`, `[]`}, got)
+
+	t.Run("EOF is final", func(t *testing.T) {
+		it := json.ArrayIter(strings.NewReader(` [ ] `))
+		for range 2 {
+			_, err := it.Next()
+			assert.Equal(t, io.EOF, err)
+		}
+	})
+
+	t.Run("large array", func(t *testing.T) {
+		const n = 10000
+		it := json.ArrayIter(strings.NewReader("[" + strings.Repeat(`{"n": 1},`, n-1) + `{"n": 1}]`))
+		count := 0
+		for {
+			_, err := it.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			count++
+		}
+		assert.Equal(t, n, count)
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"not an array", `{"a": 1}`, "json: document is not an array, found object at offset 0"},
+		{"scalar", ` 12`, "json: document is not an array, found number 12 at offset 1"},
+		{"malformed element", `[1, }`, "json: line 1, col 5: expected value"},
+		{"trailing data", `[1] 2`, "json: line 1, col 5: expected end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := json.ArrayIter(strings.NewReader(tt.input))
+			var err error
+			for err == nil {
+				_, err = it.Next()
+			}
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	t.Run("empty input", func(t *testing.T) {
+		_, err := json.ArrayIter(strings.NewReader(``)).Next()
+		assert.EqualError(t, err, "json: line 1, col 1: unexpected end of input, expected value")
+	})
+}
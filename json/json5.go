@@ -0,0 +1,177 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// JSON5 accepts documents written in JSON5 (https://spec.json5.org), a superset of JSON meant for
+// hand-written configuration. In addition to plain JSON it accepts:
+//   - object keys written as unquoted ECMAScript identifiers, e.g. {name: 1};
+//   - strings in single quotes, with the escapes \', \v, \0, \xHH and line continuations;
+//   - hexadecimal numbers, numbers with a leading or trailing decimal point and a leading plus sign;
+//   - the numbers Infinity and NaN, with an optional sign;
+//   - a trailing comma after the last array element or object member;
+//   - // line comments and /* */ block comments;
+//   - additional Unicode whitespace, such as no-break spaces and byte order marks.
+//
+// Unicode escapes in unquoted keys are not supported.
+func JSON5() Option {
+	return func(c *config) {
+		c.json5 = true
+		c.comments = true
+		c.trailingCommas = true
+		c.nonFinite = true
+	}
+}
+
+// space skips whitespace, and comments if they are enabled, according to the configuration c.
+// An unterminated block comment fails the parse.
+func (c *config) space() parser.Parser[string] {
+	if !c.json5 && !c.comments {
+		return parser.Spaces()
+	}
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		i := 0
+	scan:
+		for i < len(s) {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case r == ' ' || r == '\t' || r == '\n' || r == '\r', c.json5 && isSpace5(r):
+				i += n
+			case c.comments && strings.HasPrefix(s[i:], "//"):
+				end := strings.IndexAny(s[i:], "\n\r\u2028\u2029")
+				if end < 0 {
+					end = len(s) - i
+				}
+				i += end
+			case c.comments && strings.HasPrefix(s[i:], "/*"):
+				end := strings.Index(s[i+2:], "*/")
+				if end < 0 {
+					st.Truncated(`"*/"`)
+					return parser.Nothing[parser.Tuple[string, parser.State]]()
+				}
+				i += end + 4
+			case c.comments && s[i:] == "/":
+				// The input may have been cut short in the middle of a comment start.
+				st.Truncated("'/'")
+				break scan
+			default:
+				break scan
+			}
+		}
+		next, ok := st.Advance(i)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:i], next))
+	})
+}
+
+// isSpace5 reports whether r is whitespace in JSON5 beyond the four whitespace characters of JSON.
+func isSpace5(r rune) bool {
+	switch r {
+	case '\v', '\f', '\u00a0', '\u2028', '\u2029', '\ufeff':
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// str parses a string literal according to the configuration c.
+func (c *config) str() parser.Parser[string] {
+	if !c.json5 {
+		return stringLit(c.strictStrings)
+	}
+	return parser.OrElse(quoted('"', c.strictStrings, unescape5), quoted('\'', c.strictStrings, unescape5))
+}
+
+// key parses an object key according to the configuration c. JSON5 also accepts identifiers.
+func (c *config) key() parser.Parser[string] {
+	if !c.json5 {
+		return c.str()
+	}
+	return parser.OrElse(c.str(), identifier())
+}
+
+// identifier parses an ECMAScript identifier name, which JSON5 accepts as an unquoted object key.
+func identifier() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		i := 0
+		for i < len(s) {
+			r, n := utf8.DecodeRuneInString(s[i:])
+			if !isIDStart(r) && (i == 0 || !isIDPart(r)) {
+				break
+			}
+			i += n
+		}
+		if i == 0 {
+			st.Fail("identifier")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(i)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:i], next))
+	})
+}
+
+// isIDStart reports whether r can start an identifier.
+func isIDStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+// isIDPart reports whether r can continue an identifier.
+func isIDPart(r rune) bool {
+	return isIDStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) || r == '\u200c' || r == '\u200d'
+}
+
+// unescape5 decodes a JSON5 escape sequence like unescape. In addition to the JSON escapes it
+// accepts \', \v, \0, \xHH and line continuations, and any other character not a digit escapes itself.
+func unescape5(s string) (rune, int, bool) {
+	if len(s) < 2 {
+		return 0, 1, false
+	}
+	switch s[1] {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't', 'u':
+		return unescape(s)
+	case '\'':
+		return '\'', 2, true
+	case 'v':
+		return '\v', 2, true
+	case '0':
+		if len(s) > 2 && s[2] >= '0' && s[2] <= '9' {
+			return 0, 1, false
+		}
+		return 0, 2, true
+	case 'x':
+		if len(s) < 4 {
+			return 0, len(s), false
+		}
+		v, err := strconv.ParseUint(s[2:4], 16, 8)
+		if err != nil {
+			return 0, 2, false
+		}
+		return rune(v), 4, true
+	case '\n':
+		return noRune, 2, true
+	case '\r':
+		if len(s) > 2 && s[2] == '\n' {
+			return noRune, 3, true
+		}
+		return noRune, 2, true
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return 0, 1, false
+	}
+	r, n := utf8.DecodeRuneInString(s[1:])
+	if r == '\u2028' || r == '\u2029' {
+		return noRune, 1 + n, true
+	}
+	return r, 1 + n, true
+}
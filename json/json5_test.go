@@ -0,0 +1,85 @@
+package json_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON5(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  json.Json
+	}{
+		{"unquoted keys", `{name: 1, $id_2: 2}`, json.NewObject(
+			json.JsonPair{Key: "name", Value: json.JsonInt{Val: 1}},
+			json.JsonPair{Key: "$id_2", Value: json.JsonInt{Val: 2}},
+		)},
+		{"single quotes", `'it\'s "quoted"'`, json.JsonString{Val: `it's "quoted"`}},
+		{"extra escapes", `'\x41\v\0\q'`, json.JsonString{Val: "A\v\x00q"}},
+		{"line continuation", "'a\\\nb'", json.JsonString{Val: "ab"}},
+		{"hex", `0x1F`, json.JsonInt{Val: 31}},
+		{"negative hex", `-0XfF`, json.JsonInt{Val: -255}},
+		{"leading point", `.5`, json.JsonFloat{Val: 0.5}},
+		{"trailing point", `5.`, json.JsonFloat{Val: 5}},
+		{"trailing point with exponent", `5.e1`, json.JsonFloat{Val: 50}},
+		{"plus sign", `+1`, json.JsonInt{Val: 1}},
+		{"infinity", `-Infinity`, json.JsonFloat{Val: math.Inf(-1)}},
+		{"trailing commas", `[1, [2,], {a: 3,},]`, json.JsonArray{Val: []json.Json{
+			json.JsonInt{Val: 1},
+			json.JsonArray{Val: []json.Json{json.JsonInt{Val: 2}}},
+			json.NewObject(json.JsonPair{Key: "a", Value: json.JsonInt{Val: 3}}),
+		}}},
+		{"comments", "// config\n{/* inline */ a: 1 // trailing\n}", json.NewObject(json.JsonPair{Key: "a", Value: json.JsonInt{Val: 1}})},
+		{"unicode whitespace", "\ufeff[\u00a0 1 ]", json.JsonArray{Val: []json.Json{json.JsonInt{Val: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := json.ParseJSON(tt.input, json.JSON5())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+
+	t.Run("NaN", func(t *testing.T) {
+		v, err := json.ParseJSON(`NaN`, json.JSON5())
+		assert.NoError(t, err)
+		assert.True(t, math.IsNaN(v.(json.JsonFloat).Val))
+	})
+
+	for _, input := range []string{`[,]`, `[1,,]`, `{a: 1,,}`, `01`, `0x`, `'\1'`, `{1a: 1}`, `/* open`} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, err := json.ParseJSON(input, json.JSON5())
+			assert.Error(t, err)
+		})
+	}
+
+	for _, input := range []string{`{a: 1}`, `'s'`, `0x1`, `.5`, `[1,]`, `// c` + "\n1", `Infinity`} {
+		t.Run("plain JSON rejects "+input, func(t *testing.T) {
+			_, err := json.ParseJSON(input)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("number as key", func(t *testing.T) {
+		_, err := json.ParseJSON(`{1:2}`, json.JSON5())
+		assert.EqualError(t, err, `json: line 1, col 2: expected object key or '}'`)
+	})
+
+	t.Run("unterminated comment", func(t *testing.T) {
+		_, err := json.ParseJSON(`[1 /* open`, json.JSON5())
+		assert.EqualError(t, err, `json: line 1, col 11: unexpected end of input, expected "*/"`)
+	})
+
+	t.Run("nodes", func(t *testing.T) {
+		n, err := json.ParseNode("{\n  // comment\n  key: 'v',\n}", json.JSON5())
+		assert.NoError(t, err)
+		m := n.Members[0]
+		assert.Equal(t, "key", m.Key)
+		assert.Equal(t, json.Position{Offset: 17, Line: 3, Column: 3}, m.KeySpan.Start)
+		assert.Equal(t, json.JsonString{Val: "v"}, m.Value.Value)
+	})
+}
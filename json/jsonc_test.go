@@ -0,0 +1,51 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComments(t *testing.T) {
+	settings := `// VS Code settings
+{
+	/* editor */
+	"editor.tabSize": 4, // spaces
+	"files.exclude": {
+		"**/.git": true /* hide */
+	}
+}
+// end`
+	v, err := json.ParseJSON(settings, json.Comments())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"editor.tabSize":4,"files.exclude":{"**/.git":true}}`, v.String())
+
+	t.Run("comment markers in strings", func(t *testing.T) {
+		v, err := json.ParseJSON(`["// not a comment", "/* nor this */"]`, json.Comments())
+		assert.NoError(t, err)
+		assert.Equal(t, `["// not a comment","/* nor this */"]`, v.String())
+	})
+
+	t.Run("other JSON5 syntax stays invalid", func(t *testing.T) {
+		_, err := json.ParseJSON(`{a: 1}`, json.Comments())
+		assert.Error(t, err)
+	})
+
+	t.Run("single slash", func(t *testing.T) {
+		_, err := json.ParseJSON(`[1 / 2]`, json.Comments())
+		assert.Error(t, err)
+	})
+
+	t.Run("unterminated block comment", func(t *testing.T) {
+		_, err := json.ParseJSON(`{"a": 1 /* }`, json.Comments())
+		assert.EqualError(t, err, `json: line 1, col 13: unexpected end of input, expected "*/"`)
+	})
+
+	t.Run("nodes", func(t *testing.T) {
+		n, err := json.ParseNode("/* a */ [1, // b\n 2]", json.Comments())
+		assert.NoError(t, err)
+		assert.Equal(t, json.Position{Offset: 8, Line: 1, Column: 9}, n.Span.Start)
+		assert.Equal(t, 2, n.Elements[1].Span.Start.Line)
+	})
+}
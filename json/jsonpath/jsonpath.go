@@ -0,0 +1,395 @@
+// Package jsonpath evaluates JSONPath queries against json.Json values.
+//
+// The supported subset covers the common forms of RFC 9535:
+//
+//	$                     the root value
+//	.name, ['name']       object members
+//	.*, [*]               all members or elements
+//	..name, ..*, ..[…]    descendants at any depth
+//	[0], [-1], [0, 2]     array elements, counted from the end if negative
+//	[start:end:step]      array slices
+//	[?@.price < 10]       filters, also written [?(…)]
+//
+// Filter expressions compare queries ('@' for the current node, '$' for the root) and
+// literals with ==, !=, <, <=, > and >=, test whether a query selects anything, and
+// combine tests with &&, || and !.
+package jsonpath
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Path is a compiled JSONPath expression.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Compile parses a JSONPath expression.
+func Compile(expr string) (*Path, error) {
+	segs, err := parser.Run(path(), expr)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: invalid path %q: %w", expr, err)
+	}
+	return &Path{expr: expr, segments: segs}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expr string) *Path {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Query compiles expr and selects the matching values of doc.
+func Query(doc json.Json, expr string) ([]json.Json, error) {
+	p, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Select(doc), nil
+}
+
+// String returns the source of the expression.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Select returns the values of doc matched by the path, in document order.
+func (p *Path) Select(doc json.Json) []json.Json {
+	return selectAll(p.segments, doc, doc)
+}
+
+// segment selects values from the children, or with descendant set, all descendants of its input.
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+// selectAll applies segs in turn, starting from the single value cur.
+func selectAll(segs []segment, cur, root json.Json) []json.Json {
+	nodes := []json.Json{cur}
+	for _, seg := range segs {
+		var next []json.Json
+		for _, n := range nodes {
+			if seg.descendant {
+				next = seg.applyDescendants(n, root, next)
+			} else {
+				for _, sel := range seg.selectors {
+					next = sel.apply(n, root, next)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// applyDescendants applies the selectors of seg to n and to every value nested in n, in document order.
+func (seg segment) applyDescendants(n, root json.Json, out []json.Json) []json.Json {
+	for _, sel := range seg.selectors {
+		out = sel.apply(n, root, out)
+	}
+	for _, c := range children(n) {
+		out = seg.applyDescendants(c, root, out)
+	}
+	return out
+}
+
+// children returns the elements of an array or the member values of an object in document order.
+func children(n json.Json) []json.Json {
+	switch n := n.(type) {
+	case json.JsonArray:
+		return n.Val
+	case json.JsonObject:
+		keys := n.OrderedKeys()
+		vals := make([]json.Json, len(keys))
+		for i, k := range keys {
+			vals[i] = n.Val[k]
+		}
+		return vals
+	}
+	return nil
+}
+
+// selector picks values from the children of a node.
+type selector interface {
+	apply(n, root json.Json, out []json.Json) []json.Json
+}
+
+// nameSelector selects the object member with the given name.
+type nameSelector struct{ name string }
+
+func (s nameSelector) apply(n, _ json.Json, out []json.Json) []json.Json {
+	if obj, ok := n.(json.JsonObject); ok {
+		if v, ok := obj.Val[s.name]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// wildcard selects all children.
+type wildcard struct{}
+
+func (wildcard) apply(n, _ json.Json, out []json.Json) []json.Json {
+	return append(out, children(n)...)
+}
+
+// indexSelector selects an array element; negative indexes count from the end.
+type indexSelector struct{ index int }
+
+func (s indexSelector) apply(n, _ json.Json, out []json.Json) []json.Json {
+	if arr, ok := n.(json.JsonArray); ok {
+		i := s.index
+		if i < 0 {
+			i += len(arr.Val)
+		}
+		if i >= 0 && i < len(arr.Val) {
+			out = append(out, arr.Val[i])
+		}
+	}
+	return out
+}
+
+// sliceSelector selects the array elements from start up to end, every step elements.
+type sliceSelector struct {
+	start, end, step parser.Maybe[int64]
+}
+
+func (s sliceSelector) apply(n, _ json.Json, out []json.Json) []json.Json {
+	arr, ok := n.(json.JsonArray)
+	step := s.step.Get()
+	if !ok || step == 0 {
+		return out
+	}
+	length := int64(len(arr.Val))
+	normalize := func(m parser.Maybe[int64], def int64) int64 {
+		if m.IsNothing() {
+			return def
+		}
+		if i := m.Get(); i < 0 {
+			return i + length
+		}
+		return m.Get()
+	}
+	if step > 0 {
+		lower := min(max(normalize(s.start, 0), 0), length)
+		upper := min(max(normalize(s.end, length), 0), length)
+		for i := lower; i < upper; i += step {
+			out = append(out, arr.Val[i])
+		}
+	} else {
+		upper := min(max(normalize(s.start, length-1), -1), length-1)
+		lower := min(max(normalize(s.end, -length-1), -1), length-1)
+		for i := upper; i > lower; i += step {
+			out = append(out, arr.Val[i])
+		}
+	}
+	return out
+}
+
+// filterSelector selects the children for which the expression holds.
+type filterSelector struct{ expr expr }
+
+func (s filterSelector) apply(n, root json.Json, out []json.Json) []json.Json {
+	for _, c := range children(n) {
+		if s.expr.test(c, root) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// expr is a filter expression evaluated for a candidate node.
+type expr interface {
+	test(cur, root json.Json) bool
+}
+
+type (
+	// andExpr holds if all of its operands hold.
+	andExpr []expr
+	// orExpr holds if any of its operands holds.
+	orExpr []expr
+	// notExpr negates its operand.
+	notExpr struct{ e expr }
+	// existsExpr holds if the query selects at least one value.
+	existsExpr struct{ q query }
+	// cmpExpr compares two values.
+	cmpExpr struct {
+		op          string
+		left, right value
+	}
+)
+
+func (e andExpr) test(cur, root json.Json) bool {
+	for _, x := range e {
+		if !x.test(cur, root) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e orExpr) test(cur, root json.Json) bool {
+	for _, x := range e {
+		if x.test(cur, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e notExpr) test(cur, root json.Json) bool {
+	return !e.e.test(cur, root)
+}
+
+func (e existsExpr) test(cur, root json.Json) bool {
+	return len(e.q.nodes(cur, root)) > 0
+}
+
+// test compares the operands. A query yields a value only if it selects exactly one;
+// two missing values are equal, and ordering applies only to two numbers or two strings.
+func (e cmpExpr) test(cur, root json.Json) bool {
+	l, lok := e.left.eval(cur, root)
+	r, rok := e.right.eval(cur, root)
+	switch e.op {
+	case "==":
+		return lok == rok && (!lok || equal(l, r))
+	case "!=":
+		return lok != rok || (lok && !equal(l, r))
+	}
+	if !lok || !rok {
+		return false
+	}
+	c, ok := compare(l, r)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// value is an operand of a comparison.
+type value interface {
+	eval(cur, root json.Json) (json.Json, bool)
+}
+
+// literal is a constant operand.
+type literal struct{ v json.Json }
+
+func (l literal) eval(_, _ json.Json) (json.Json, bool) {
+	return l.v, true
+}
+
+// query selects values relative to the current node, or to the root if absolute is set.
+type query struct {
+	absolute bool
+	segments []segment
+}
+
+func (q query) nodes(cur, root json.Json) []json.Json {
+	if q.absolute {
+		cur = root
+	}
+	return selectAll(q.segments, cur, root)
+}
+
+func (q query) eval(cur, root json.Json) (json.Json, bool) {
+	nodes := q.nodes(cur, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// number returns the value of a JSON number.
+func number(j json.Json) (float64, bool) {
+	switch j := j.(type) {
+	case json.JsonInt:
+		return float64(j.Val), true
+	case json.JsonFloat:
+		return j.Val, true
+	case json.JsonNumber:
+		f, err := j.AsFloat64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal reports whether two values are equal; numbers compare by value and objects ignore member order.
+func equal(a, b json.Json) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch a := a.(type) {
+	case json.JsonArray:
+		b, ok := b.(json.JsonArray)
+		if !ok || len(a.Val) != len(b.Val) {
+			return false
+		}
+		for i := range a.Val {
+			if !equal(a.Val[i], b.Val[i]) {
+				return false
+			}
+		}
+		return true
+	case json.JsonObject:
+		b, ok := b.(json.JsonObject)
+		if !ok || len(a.Val) != len(b.Val) {
+			return false
+		}
+		for k, v := range a.Val {
+			w, ok := b.Val[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// compare orders two numbers or two strings. It reports false for any other combination.
+func compare(a, b json.Json) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok || math.IsNaN(x) || math.IsNaN(y) {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	s, ok := a.(json.JsonString)
+	t, ok2 := b.(json.JsonString)
+	if !ok || !ok2 {
+		return 0, false
+	}
+	switch {
+	case s.Val < t.Val:
+		return -1, true
+	case s.Val > t.Val:
+		return 1, true
+	}
+	return 0, true
+}
@@ -0,0 +1,109 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/json/jsonpath"
+	"github.com/stretchr/testify/assert"
+)
+
+const store = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	},
+	"expensive": 10
+}`
+
+func strs(vals ...string) []json.Json {
+	js := make([]json.Json, len(vals))
+	for i, v := range vals {
+		js[i] = json.JsonString{Val: v}
+	}
+	return js
+}
+
+func TestQuery(t *testing.T) {
+	doc, err := json.ParseJSON(store)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		expr string
+		want []json.Json
+	}{
+		{"$.store.book[*].author", strs("Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien")},
+		{"$..author", strs("Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien")},
+		{"$.store['bicycle'].color", strs("red")},
+		{"$..book[2].title", strs("Moby Dick")},
+		{"$..book[-1].title", strs("The Lord of the Rings")},
+		{"$..book[0, 1].title", strs("Sayings of the Century", "Sword of Honour")},
+		{"$..book[:2].title", strs("Sayings of the Century", "Sword of Honour")},
+		{"$..book[1:4:2].title", strs("Sword of Honour", "The Lord of the Rings")},
+		{"$..book[::-1].author", strs("J. R. R. Tolkien", "Herman Melville", "Evelyn Waugh", "Nigel Rees")},
+		{"$..book[?(@.isbn)].title", strs("Moby Dick", "The Lord of the Rings")},
+		{"$..book[?@.price < 10].title", strs("Sayings of the Century", "Moby Dick")},
+		{"$..book[?@.price > $.expensive && @.category == 'fiction'].title", strs("Sword of Honour", "The Lord of the Rings")},
+		{`$..book[?!(@.category == "fiction") || @.price >= 22.99].title`, strs("Sayings of the Century", "The Lord of the Rings")},
+		{"$.store.bicycle.*", []json.Json{json.JsonString{Val: "red"}, json.JsonInt{Val: 399}}},
+		{"$.missing.path", nil},
+		{"$", []json.Json{doc}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := jsonpath.Query(doc, tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryNonASCII(t *testing.T) {
+	doc, err := json.ParseJSON(`{"café": {"ключ": "значение"}, "日本": [1], "é": "latin"}`)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		expr string
+		want []json.Json
+	}{
+		{"$.café.ключ", strs("значение")},
+		{"$['café']['ключ']", strs("значение")},
+		{`$["café"].ключ`, strs("значение")},
+		{"$..ключ", strs("значение")},
+		{"$.日本[0]", []json.Json{json.JsonInt{Val: 1}}},
+		{"$.é", strs("latin")},
+		{"$[?@.ключ == 'значение'].ключ", strs("значение")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := jsonpath.Query(doc, tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = jsonpath.Compile("$.caf\xe9")
+	assert.ErrorContains(t, err, "jsonpath: invalid path")
+}
+
+func TestCompile(t *testing.T) {
+	t.Run("reusable", func(t *testing.T) {
+		p := jsonpath.MustCompile("$[?@ > 1]")
+		assert.Equal(t, "$[?@ > 1]", p.String())
+		doc, err := json.ParseJSON(`[1, 2, 3]`)
+		assert.NoError(t, err)
+		assert.Equal(t, []json.Json{json.JsonInt{Val: 2}, json.JsonInt{Val: 3}}, p.Select(doc))
+	})
+
+	for _, expr := range []string{"store", "$.", "$[]", "$[?]", "$[?@.a ==]", "$['unterminated]", "$[?1]"} {
+		t.Run("invalid "+expr, func(t *testing.T) {
+			_, err := jsonpath.Compile(expr)
+			assert.ErrorContains(t, err, "jsonpath: invalid path")
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package jsonpath provides the grammar of JSONPath expressions, built with the tiny-parsec combinators.
+package jsonpath
+
+import (
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// path parses a complete JSONPath expression starting at the root, e.g. "$.store.book[*].author".
+func path() parser.Parser[[]segment] {
+	var filterExpr parser.Parser[expr]
+	ref := parser.Lazy(func() parser.Parser[expr] { return filterExpr })
+	segs := segments(ref)
+	filterExpr = logicalOr(segs)
+	return parser.OmitLeft(parser.Char('$'), segs)
+}
+
+// segments parses the segments following '$' or '@'; filters nested in them are parsed by filter.
+func segments(filter parser.Parser[expr]) parser.Parser[[]segment] {
+	bracket := bracketSelectors(filter)
+	dotSelector := parser.OrElse(
+		parser.Fmap(parser.Char('*'), func(rune) []selector { return []selector{wildcard{}} }),
+		parser.Fmap(identifier(), func(name string) []selector { return []selector{nameSelector{name}} }),
+	)
+	descendant := parser.OmitLeft(parser.Str(".."), parser.OrElse(dotSelector, bracket))
+	child := parser.OrElse(parser.OmitLeft(parser.Char('.'), dotSelector), bracket)
+	return parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(descendant, func(sels []selector) segment { return segment{descendant: true, selectors: sels} }),
+		parser.Fmap(child, func(sels []selector) segment { return segment{selectors: sels} }),
+	))
+}
+
+// bracketSelectors parses a bracketed filter, e.g. "[?@.price < 10]", or a comma-separated list
+// of names, indexes, slices and wildcards, e.g. "['a', 0, 1:3, *]".
+func bracketSelectors(filter parser.Parser[expr]) parser.Parser[[]selector] {
+	item := parser.OrElse(
+		parser.Fmap(quoted(), func(name string) selector { return nameSelector{name} }),
+		parser.Fmap(parser.Char('*'), func(rune) selector { return wildcard{} }),
+		slice(),
+		parser.Fmap(parser.Integer(), func(i int64) selector { return indexSelector{int(i)} }),
+	)
+	filterSel := parser.Fmap(
+		parser.OmitLeft(parser.Char('?'), parser.TrimLeft(filter)),
+		func(e expr) []selector { return []selector{filterSelector{e}} },
+	)
+	list := parser.SatisfyWithMsg(
+		parser.SepBy(item, parser.Trim(parser.Char(','))),
+		func(sels []selector) bool { return len(sels) > 0 },
+		"selector",
+	)
+	return parser.Between(parser.Char('['), parser.Trim(parser.OrElse(filterSel, list)), parser.Char(']'))
+}
+
+// slice parses an array slice "start:end:step" where every part is optional.
+func slice() parser.Parser[selector] {
+	bound := parser.ZeroOrOne(parser.Trim(parser.Integer()))
+	return parser.Bind(bound, func(start parser.Maybe[int64]) parser.Parser[selector] {
+		return parser.Bind(parser.OmitLeft(parser.Char(':'), bound), func(end parser.Maybe[int64]) parser.Parser[selector] {
+			step := parser.ZeroOrOne(parser.OmitLeft(parser.Char(':'), bound))
+			return parser.Fmap(step, func(step parser.Maybe[parser.Maybe[int64]]) selector {
+				s := sliceSelector{start: start, end: end, step: parser.Just(int64(1))}
+				if step.IsJust() && step.Get().IsJust() {
+					s.step = step.Get()
+				}
+				return s
+			})
+		})
+	})
+}
+
+// logicalOr parses a filter expression: comparisons and existence tests combined with
+// "&&", "||", "!" and parentheses. Queries in the expression are parsed with segs.
+func logicalOr(segs parser.Parser[[]segment]) parser.Parser[expr] {
+	var or parser.Parser[expr]
+	ref := parser.Lazy(func() parser.Parser[expr] { return or })
+
+	var unary parser.Parser[expr]
+	unaryRef := parser.Lazy(func() parser.Parser[expr] { return unary })
+	unary = parser.Trim(parser.OrElse(
+		parser.Fmap(parser.OmitLeft(parser.Char('!'), unaryRef), func(e expr) expr { return notExpr{e} }),
+		parser.Between(parser.Char('('), ref, parser.Char(')')),
+		comparison(segs),
+	))
+	and := parser.Fmap(nonEmpty(parser.SepBy(unary, parser.Str("&&"))), func(es []expr) expr { return andExpr(es) })
+	or = parser.Fmap(nonEmpty(parser.SepBy(and, parser.Str("||"))), func(es []expr) expr { return orExpr(es) })
+	return or
+}
+
+// nonEmpty requires the list parsed by p to have at least one element.
+func nonEmpty[T any](p parser.Parser[[]T]) parser.Parser[[]T] {
+	return parser.SatisfyWithMsg(p, func(ts []T) bool { return len(ts) > 0 }, "filter expression")
+}
+
+// comparison parses "operand op operand", or a query alone, which tests whether it selects anything.
+func comparison(segs parser.Parser[[]segment]) parser.Parser[expr] {
+	op := parser.Trim(parser.OrElse(
+		parser.Str("=="), parser.Str("!="), parser.Str("<="), parser.Str(">="), parser.Str("<"), parser.Str(">"),
+	))
+	return parser.Bind(operand(segs), func(left value) parser.Parser[expr] {
+		rest := parser.ZeroOrOne(parser.Bind(op, func(op string) parser.Parser[expr] {
+			return parser.Fmap(operand(segs), func(right value) expr {
+				return cmpExpr{op: op, left: left, right: right}
+			})
+		}))
+		return parser.Bind(rest, func(m parser.Maybe[expr]) parser.Parser[expr] {
+			if m.IsJust() {
+				return parser.Pure(m.Get())
+			}
+			if q, ok := left.(query); ok {
+				return parser.Pure[expr](existsExpr{q})
+			}
+			return parser.Fail[expr]()
+		})
+	})
+}
+
+// operand parses a literal or a query relative to the current node ('@') or the root ('$').
+func operand(segs parser.Parser[[]segment]) parser.Parser[value] {
+	return parser.OrElse(
+		parser.Fmap(parser.OmitLeft(parser.Char('@'), segs), func(s []segment) value { return query{segments: s} }),
+		parser.Fmap(parser.OmitLeft(parser.Char('$'), segs), func(s []segment) value { return query{absolute: true, segments: s} }),
+		parser.Fmap(quoted(), func(s string) value { return literal{json.JsonString{Val: s}} }),
+		parser.Fmap(parser.OrElse(json.JNumber(), json.JBool(), json.JNull()), func(j json.Json) value { return literal{j} }),
+	)
+}
+
+// isNameStart reports whether the byte r may start a member name in dot notation. As in RFC 9535,
+// names may contain any non-ASCII character, so every byte of a multi-byte UTF-8 sequence is accepted.
+func isNameStart(r rune) bool {
+	return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r >= utf8.RuneSelf
+}
+
+// identifier parses a member name in dot notation.
+func identifier() parser.Parser[string] {
+	start := parser.SatisfyMsg(isNameStart, "name")
+	rest := parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return isNameStart(r) || '0' <= r && r <= '9' }))
+	name := parser.Bind(start, func(r rune) parser.Parser[string] {
+		return parser.Fmap(rest, func(rs []rune) string { return parser.Text(append([]rune{r}, rs...)) })
+	})
+	return parser.SatisfyWithMsg(name, utf8.ValidString, "name")
+}
+
+// quoted parses a member name in single or double quotes, with backslash escapes.
+func quoted() parser.Parser[string] {
+	return parser.OrElse(quotedBy('\''), quotedBy('"'))
+}
+
+// quotedBy parses a string enclosed in q.
+func quotedBy(q rune) parser.Parser[string] {
+	escape := parser.OmitLeft(parser.Char('\\'), parser.Fmap(parser.Satisfy(func(rune) bool { return true }), func(r rune) rune {
+		switch r {
+		case 'n':
+			return '\n'
+		case 't':
+			return '\t'
+		case 'r':
+			return '\r'
+		}
+		return r
+	}))
+	char := parser.SatisfyMsg(func(r rune) bool { return r != q && r != '\\' }, "character")
+	return parser.Between(parser.Char(q), parser.Fmap(parser.ZeroOrMore(parser.OrElse(escape, char)), parser.Text), parser.Char(q))
+}
@@ -0,0 +1,143 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/json/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+// mustParse parses a JSON document for the tests.
+func mustParse(t *testing.T, s string) json.Json {
+	t.Helper()
+	v, err := json.ParseJSON(s)
+	assert.NoError(t, err)
+	return v
+}
+
+const personSchema = `{
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "pattern": "^[A-Z]"},
+		"age": {"type": "integer", "minimum": 0, "maximum": 150},
+		"email": {"type": ["string", "null"]},
+		"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "uniqueItems": true, "maxItems": 3},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"address": {"type": "object", "required": ["city"], "properties": {"city": {"const": "Paris"}}}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema := jsonschema.MustParse(personSchema)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []jsonschema.Violation
+	}{
+		{"valid", `{"name": "Ann", "age": 30, "email": null, "tags": ["a", "b"], "address": {"city": "Paris"}}`, nil},
+		{"integral float", `{"name": "Ann", "age": 30.0}`, nil},
+		{"wrong types", `{"name": 1, "age": 1.5}`, []jsonschema.Violation{
+			{Path: "/name", Keyword: "type", Message: "expected string, found integer"},
+			{Path: "/age", Keyword: "type", Message: "expected integer, found number"},
+		}},
+		{"missing and extra", `{"name": "Ann", "nick": "A"}`, []jsonschema.Violation{
+			{Path: "", Keyword: "required", Message: `missing required property "age"`},
+			{Path: "", Keyword: "additionalProperties", Message: `property "nick" is not allowed`},
+		}},
+		{"string constraints", `{"name": "", "age": 200}`, []jsonschema.Violation{
+			{Path: "/name", Keyword: "minLength", Message: "string of length 0 is shorter than 1"},
+			{Path: "/name", Keyword: "pattern", Message: `string does not match pattern "^[A-Z]"`},
+			{Path: "/age", Keyword: "maximum", Message: "200 is greater than the maximum of 150"},
+		}},
+		{"array constraints", `{"name": "Ann", "age": 1, "tags": ["a", "c", "a", "b"]}`, []jsonschema.Violation{
+			{Path: "/tags", Keyword: "maxItems", Message: "array has 4 items, more than 3"},
+			{Path: "/tags", Keyword: "uniqueItems", Message: "items 0 and 2 are equal"},
+			{Path: "/tags/1", Keyword: "enum", Message: `value "c" is not one of the allowed values`},
+		}},
+		{"reference", `{"name": "Ann", "age": 1, "address": {"city": "Rome"}}`, []jsonschema.Violation{
+			{Path: "/address/city", Keyword: "const", Message: `value "Rome" is not "Paris"`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(mustParse(t, tt.doc))
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *jsonschema.ValidationError
+			assert.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.want, verr.Violations)
+		})
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	tests := []struct {
+		schema string
+		doc    string
+		valid  bool
+	}{
+		{`{"anyOf": [{"type": "string"}, {"minimum": 10}]}`, `"x"`, true},
+		{`{"anyOf": [{"type": "string"}, {"minimum": 10}]}`, `5`, false},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 10}]}`, `5`, true},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 10}]}`, `50`, false},
+		{`{"allOf": [{"minimum": 1}, {"maximum": 3}]}`, `2`, true},
+		{`{"allOf": [{"minimum": 1}, {"maximum": 3}]}`, `4`, false},
+		{`{"not": {"type": "null"}}`, `null`, false},
+		{`{"multipleOf": 0.5}`, `2.5`, true},
+		{`{"multipleOf": 0.5}`, `2.3`, false},
+		{`{"exclusiveMinimum": 0}`, `0`, false},
+		{`true`, `{"anything": 1}`, true},
+		{`false`, `1`, false},
+		{`{"minProperties": 1, "additionalProperties": {"type": "boolean"}}`, `{"a": true}`, true},
+		{`{"minProperties": 1, "additionalProperties": {"type": "boolean"}}`, `{"a": 1}`, false},
+		{`{"$defs": {"tree": {"type": "array", "items": {"$ref": "#/$defs/tree"}}}, "$ref": "#/$defs/tree"}`, `[[], [[]]]`, true},
+		{`{"$defs": {"tree": {"type": "array", "items": {"$ref": "#/$defs/tree"}}}, "$ref": "#/$defs/tree"}`, `[[], [1]]`, false},
+		{`{"enum": [1, {"a": [true]}]}`, `{"a": [true]}`, true},
+		{`{"const": 1}`, `1.0`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema+" "+tt.doc, func(t *testing.T) {
+			schema, err := jsonschema.Parse(tt.schema)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.valid, schema.Valid(mustParse(t, tt.doc)))
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`1`, "jsonschema: #: schema must be an object or a boolean"},
+		{`{"type": "text"}`, `jsonschema: #/type: unknown type "text"`},
+		{`{"minLength": -1}`, "jsonschema: #/minLength: must be a non-negative integer"},
+		{`{"properties": {"a/b": {"pattern": "("}}}`, "jsonschema: #/properties/a~1b/pattern: error parsing regexp: missing closing ): `(`"},
+		{`{"anyOf": []}`, "jsonschema: #/anyOf: must be a non-empty array"},
+		{`{"$ref": "#/$defs/missing"}`, `jsonschema: #: $ref "#/$defs/missing" does not resolve`},
+		{`{"$ref": "other.json"}`, `jsonschema: #: unsupported $ref "other.json", only references within the document are supported`},
+		{`{"type": }`, "jsonschema: json: line 1, col 10: expected value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			_, err := jsonschema.Parse(tt.schema)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	schema := jsonschema.MustParse(`{"type": "array", "items": {"type": "string"}}`)
+	err := schema.Validate(mustParse(t, `["a", 1, null]`))
+	assert.EqualError(t, err, "jsonschema: /1: expected string, found integer; /2: expected string, found null")
+	assert.EqualError(t, jsonschema.MustParse(`{"type": "string"}`).Validate(json.JsonInt{Val: 1}),
+		"jsonschema: /: expected string, found integer")
+}
@@ -0,0 +1,347 @@
+// Package jsonschema validates json.Json values against JSON Schema documents.
+//
+// The supported subset covers the commonly used validation keywords of draft 2020-12:
+//
+//	type, enum, const                          any value
+//	minimum, maximum, exclusiveMinimum,        numbers
+//	exclusiveMaximum, multipleOf
+//	minLength, maxLength, pattern              strings
+//	items, minItems, maxItems, uniqueItems     arrays
+//	properties, required, additionalProperties objects
+//	minProperties, maxProperties
+//	allOf, anyOf, oneOf, not                   combinators
+//	$ref, $defs, definitions                   references within the schema document
+//
+// Boolean schemas are supported as well. Other keywords, such as format or $schema, are ignored.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/81120/tiny-parsec/json"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	// always is set for the boolean schemas true and false, which accept or reject every value.
+	always *bool
+
+	types    []string
+	enum     []json.Json
+	constVal json.Json
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	items                *Schema
+	minItems, maxItems   *int
+	uniqueItems          bool
+	properties           map[string]*Schema
+	propertyOrder        []string
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+	ref                 *Schema
+}
+
+// Parse parses a JSON Schema document with json.ParseJSON and compiles it.
+func Parse(s string) (*Schema, error) {
+	doc, err := json.ParseJSON(s)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return Compile(doc)
+}
+
+// MustParse is like Parse but panics if the schema is invalid.
+func MustParse(s string) *Schema {
+	schema, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Compile compiles a JSON Schema document. References with $ref must point into the document
+// itself, e.g. "#/$defs/address"; they may be recursive.
+func Compile(doc json.Json) (*Schema, error) {
+	c := &compiler{root: doc, refs: make(map[string]*Schema)}
+	return c.compile(doc, "#")
+}
+
+// compiler compiles the subschemas of a schema document.
+type compiler struct {
+	root json.Json
+	// refs caches the schemas referenced with $ref, so that recursive references terminate.
+	refs map[string]*Schema
+}
+
+// compile compiles the schema j found at the location at, e.g. "#/properties/name".
+func (c *compiler) compile(j json.Json, at string) (*Schema, error) {
+	switch j := j.(type) {
+	case json.JsonBool:
+		return &Schema{always: &j.Val}, nil
+	case json.JsonObject:
+		return c.compileObject(j, at)
+	}
+	return nil, fmt.Errorf("jsonschema: %s: schema must be an object or a boolean", at)
+}
+
+// compileObject compiles the keywords of a schema object.
+func (c *compiler) compileObject(obj json.JsonObject, at string) (*Schema, error) {
+	s := &Schema{}
+	var err error
+	kw := keywords{obj: obj, at: at}
+	if t, ok := obj.Get("type"); ok {
+		s.types, err = kw.types(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if e, ok := obj.Get("enum"); ok {
+		arr, isArray := e.(json.JsonArray)
+		if !isArray {
+			return nil, kw.errorf("enum", "must be an array")
+		}
+		s.enum = arr.Val
+	}
+	if v, ok := obj.Get("const"); ok {
+		s.constVal = v
+	}
+	for _, n := range []struct {
+		key string
+		dst **float64
+	}{
+		{"minimum", &s.minimum},
+		{"maximum", &s.maximum},
+		{"exclusiveMinimum", &s.exclusiveMinimum},
+		{"exclusiveMaximum", &s.exclusiveMaximum},
+		{"multipleOf", &s.multipleOf},
+	} {
+		if *n.dst, err = kw.number(n.key); err != nil {
+			return nil, err
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, kw.errorf("multipleOf", "must be greater than 0")
+	}
+	for _, n := range []struct {
+		key string
+		dst **int
+	}{
+		{"minLength", &s.minLength},
+		{"maxLength", &s.maxLength},
+		{"minItems", &s.minItems},
+		{"maxItems", &s.maxItems},
+		{"minProperties", &s.minProperties},
+		{"maxProperties", &s.maxProperties},
+	} {
+		if *n.dst, err = kw.count(n.key); err != nil {
+			return nil, err
+		}
+	}
+	if p, ok := obj.Get("pattern"); ok {
+		str, isString := p.(json.JsonString)
+		if !isString {
+			return nil, kw.errorf("pattern", "must be a string")
+		}
+		if s.pattern, err = regexp.Compile(str.Val); err != nil {
+			return nil, kw.errorf("pattern", "%v", err)
+		}
+	}
+	if u, ok := obj.Get("uniqueItems"); ok {
+		b, isBool := u.(json.JsonBool)
+		if !isBool {
+			return nil, kw.errorf("uniqueItems", "must be a boolean")
+		}
+		s.uniqueItems = b.Val
+	}
+	if r, ok := obj.Get("required"); ok {
+		if s.required, err = kw.strings("required", r); err != nil {
+			return nil, err
+		}
+	}
+	if props, ok := obj.Get("properties"); ok {
+		pobj, isObject := props.(json.JsonObject)
+		if !isObject {
+			return nil, kw.errorf("properties", "must be an object")
+		}
+		s.properties = make(map[string]*Schema, pobj.Len())
+		for _, name := range pobj.OrderedKeys() {
+			sub, err := c.compile(pobj.Val[name], at+"/properties/"+escape(name))
+			if err != nil {
+				return nil, err
+			}
+			s.properties[name] = sub
+			s.propertyOrder = append(s.propertyOrder, name)
+		}
+	}
+	for _, n := range []struct {
+		key string
+		dst **Schema
+	}{
+		{"items", &s.items},
+		{"additionalProperties", &s.additionalProperties},
+		{"not", &s.not},
+	} {
+		if sub, ok := obj.Get(n.key); ok {
+			if *n.dst, err = c.compile(sub, at+"/"+n.key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, n := range []struct {
+		key string
+		dst *[]*Schema
+	}{
+		{"allOf", &s.allOf},
+		{"anyOf", &s.anyOf},
+		{"oneOf", &s.oneOf},
+	} {
+		if *n.dst, err = c.compileList(kw, n.key); err != nil {
+			return nil, err
+		}
+	}
+	if r, ok := obj.Get("$ref"); ok {
+		str, isString := r.(json.JsonString)
+		if !isString {
+			return nil, kw.errorf("$ref", "must be a string")
+		}
+		if s.ref, err = c.resolve(str.Val, at); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// compileList compiles the non-empty array of schemas under the keyword key, if present.
+func (c *compiler) compileList(kw keywords, key string) ([]*Schema, error) {
+	v, ok := kw.obj.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	arr, isArray := v.(json.JsonArray)
+	if !isArray || len(arr.Val) == 0 {
+		return nil, kw.errorf(key, "must be a non-empty array")
+	}
+	list := make([]*Schema, len(arr.Val))
+	for i, sub := range arr.Val {
+		var err error
+		if list[i], err = c.compile(sub, fmt.Sprintf("%s/%s/%d", kw.at, key, i)); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// resolve compiles the schema referenced by ref, a JSON Pointer fragment such as "#/$defs/name".
+func (c *compiler) resolve(ref, at string) (*Schema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("jsonschema: %s: unsupported $ref %q, only references within the document are supported", at, ref)
+	}
+	target := json.Pointer(c.root, ref[1:])
+	if target.IsNothing() {
+		return nil, fmt.Errorf("jsonschema: %s: $ref %q does not resolve", at, ref)
+	}
+	// Register the schema before compiling it, so that references back to it resolve to it.
+	s := &Schema{}
+	c.refs[ref] = s
+	compiled, err := c.compile(target.Get(), ref)
+	if err != nil {
+		return nil, err
+	}
+	*s = *compiled
+	return s, nil
+}
+
+// keywords reads the keywords of the schema object obj found at the location at.
+type keywords struct {
+	obj json.JsonObject
+	at  string
+}
+
+// errorf reports an invalid value of the keyword key.
+func (kw keywords) errorf(key, format string, args ...any) error {
+	return fmt.Errorf("jsonschema: %s/%s: %s", kw.at, key, fmt.Sprintf(format, args...))
+}
+
+// types reads the type keyword, a type name or an array of them.
+func (kw keywords) types(t json.Json) ([]string, error) {
+	names, err := kw.strings("type", t)
+	if s, ok := t.(json.JsonString); ok {
+		names, err = []string{s.Val}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		switch name {
+		case "null", "boolean", "integer", "number", "string", "array", "object":
+		default:
+			return nil, kw.errorf("type", "unknown type %q", name)
+		}
+	}
+	return names, nil
+}
+
+// strings reads the keyword key, whose value v must be an array of strings.
+func (kw keywords) strings(key string, v json.Json) ([]string, error) {
+	arr, ok := v.(json.JsonArray)
+	if !ok {
+		return nil, kw.errorf(key, "must be an array of strings")
+	}
+	strs := make([]string, len(arr.Val))
+	for i, e := range arr.Val {
+		s, ok := e.(json.JsonString)
+		if !ok {
+			return nil, kw.errorf(key, "must be an array of strings")
+		}
+		strs[i] = s.Val
+	}
+	return strs, nil
+}
+
+// number reads the keyword key, which must be a number if present.
+func (kw keywords) number(key string) (*float64, error) {
+	v, ok := kw.obj.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	f, ok := number(v)
+	if !ok {
+		return nil, kw.errorf(key, "must be a number")
+	}
+	return &f, nil
+}
+
+// count reads the keyword key, which must be a non-negative integer if present.
+func (kw keywords) count(key string) (*int, error) {
+	f, err := kw.number(key)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	if *f < 0 || *f != math.Trunc(*f) || *f > math.MaxInt32 {
+		return nil, kw.errorf(key, "must be a non-negative integer")
+	}
+	n := int(*f)
+	return &n, nil
+}
+
+// escape escapes a member name for use in a JSON Pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,304 @@
+// Package jsonschema provides the validation of values against compiled schemas.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/json"
+)
+
+// Violation is a failed schema constraint.
+type Violation struct {
+	// Path is the JSON Pointer of the offending value within the validated value, "" for the value itself.
+	Path string
+	// Keyword is the schema keyword that failed, e.g. "required".
+	Keyword string
+	// Message describes the failure.
+	Message string
+}
+
+// String returns the violation as "path: message", with "/" standing for the validated value itself.
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// ValidationError is returned by Validate for a value that does not conform to the schema.
+type ValidationError struct {
+	// Violations lists every failed constraint, in document order.
+	Violations []Violation
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "jsonschema: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks v against the schema. It returns nil if v conforms, and otherwise
+// a *ValidationError listing all violations.
+func (s *Schema) Validate(v json.Json) error {
+	if vs := s.validate(v, "", nil); len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+	return nil
+}
+
+// Valid reports whether v conforms to the schema.
+func (s *Schema) Valid(v json.Json) bool {
+	return len(s.validate(v, "", nil)) == 0
+}
+
+// validate appends the violations of v, found at path, to out.
+func (s *Schema) validate(v json.Json, path string, out []Violation) []Violation {
+	fail := func(keyword, format string, args ...any) {
+		out = append(out, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.always != nil {
+		if !*s.always {
+			fail("false", "no value is allowed here")
+		}
+		return out
+	}
+	if s.ref != nil {
+		out = s.ref.validate(v, path, out)
+	}
+	if len(s.types) > 0 && !hasType(v, s.types) {
+		fail("type", "expected %s, found %s", strings.Join(s.types, " or "), typeOf(v))
+	}
+	if s.enum != nil && !contains(s.enum, v) {
+		fail("enum", "value %s is not one of the allowed values", v)
+	}
+	if s.constVal != nil && !equal(s.constVal, v) {
+		fail("const", "value %s is not %s", v, s.constVal)
+	}
+	switch v := v.(type) {
+	case json.JsonString:
+		n := utf8.RuneCountInString(v.Val)
+		if s.minLength != nil && n < *s.minLength {
+			fail("minLength", "string of length %d is shorter than %d", n, *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("maxLength", "string of length %d is longer than %d", n, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v.Val) {
+			fail("pattern", "string does not match pattern %q", s.pattern)
+		}
+	case json.JsonArray:
+		out = s.validateArray(v, path, out)
+	case json.JsonObject:
+		out = s.validateObject(v, path, out)
+	default:
+		if f, ok := number(v); ok {
+			out = s.validateNumber(f, path, out)
+		}
+	}
+	for _, sub := range s.allOf {
+		out = sub.validate(v, path, out)
+	}
+	if s.anyOf != nil && count(s.anyOf, v) == 0 {
+		fail("anyOf", "value does not match any schema of anyOf")
+	}
+	if s.oneOf != nil {
+		if n := count(s.oneOf, v); n != 1 {
+			fail("oneOf", "value matches %d schemas of oneOf, expected exactly one", n)
+		}
+	}
+	if s.not != nil && s.not.Valid(v) {
+		fail("not", "value must not match the schema of not")
+	}
+	return out
+}
+
+// validateNumber appends the violations of the number f, found at path, to out.
+func (s *Schema) validateNumber(f float64, path string, out []Violation) []Violation {
+	fail := func(keyword, format string, args ...any) {
+		out = append(out, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+	num := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+	if s.minimum != nil && f < *s.minimum {
+		fail("minimum", "%s is less than the minimum of %s", num(f), num(*s.minimum))
+	}
+	if s.maximum != nil && f > *s.maximum {
+		fail("maximum", "%s is greater than the maximum of %s", num(f), num(*s.maximum))
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		fail("exclusiveMinimum", "%s is not greater than %s", num(f), num(*s.exclusiveMinimum))
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		fail("exclusiveMaximum", "%s is not less than %s", num(f), num(*s.exclusiveMaximum))
+	}
+	if s.multipleOf != nil {
+		if q := f / *s.multipleOf; math.IsInf(q, 0) || q != math.Trunc(q) {
+			fail("multipleOf", "%s is not a multiple of %s", num(f), num(*s.multipleOf))
+		}
+	}
+	return out
+}
+
+// validateArray appends the violations of the array arr, found at path, to out.
+func (s *Schema) validateArray(arr json.JsonArray, path string, out []Violation) []Violation {
+	n := len(arr.Val)
+	if s.minItems != nil && n < *s.minItems {
+		out = append(out, Violation{Path: path, Keyword: "minItems", Message: fmt.Sprintf("array has %d items, fewer than %d", n, *s.minItems)})
+	}
+	if s.maxItems != nil && n > *s.maxItems {
+		out = append(out, Violation{Path: path, Keyword: "maxItems", Message: fmt.Sprintf("array has %d items, more than %d", n, *s.maxItems)})
+	}
+	if s.uniqueItems {
+	unique:
+		for i := range arr.Val {
+			for j := i + 1; j < n; j++ {
+				if equal(arr.Val[i], arr.Val[j]) {
+					out = append(out, Violation{Path: path, Keyword: "uniqueItems", Message: fmt.Sprintf("items %d and %d are equal", i, j)})
+					break unique
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, e := range arr.Val {
+			out = s.items.validate(e, path+"/"+strconv.Itoa(i), out)
+		}
+	}
+	return out
+}
+
+// validateObject appends the violations of the object obj, found at path, to out.
+func (s *Schema) validateObject(obj json.JsonObject, path string, out []Violation) []Violation {
+	n := obj.Len()
+	if s.minProperties != nil && n < *s.minProperties {
+		out = append(out, Violation{Path: path, Keyword: "minProperties", Message: fmt.Sprintf("object has %d properties, fewer than %d", n, *s.minProperties)})
+	}
+	if s.maxProperties != nil && n > *s.maxProperties {
+		out = append(out, Violation{Path: path, Keyword: "maxProperties", Message: fmt.Sprintf("object has %d properties, more than %d", n, *s.maxProperties)})
+	}
+	for _, name := range s.required {
+		if _, ok := obj.Get(name); !ok {
+			out = append(out, Violation{Path: path, Keyword: "required", Message: fmt.Sprintf("missing required property %q", name)})
+		}
+	}
+	for _, name := range obj.OrderedKeys() {
+		v := obj.Val[name]
+		sub, ok := s.properties[name]
+		switch {
+		case ok:
+			out = sub.validate(v, path+"/"+escape(name), out)
+		case s.additionalProperties != nil && s.additionalProperties.always != nil && !*s.additionalProperties.always:
+			out = append(out, Violation{Path: path, Keyword: "additionalProperties", Message: fmt.Sprintf("property %q is not allowed", name)})
+		case s.additionalProperties != nil:
+			out = s.additionalProperties.validate(v, path+"/"+escape(name), out)
+		}
+	}
+	return out
+}
+
+// count returns the number of schemas v conforms to.
+func count(schemas []*Schema, v json.Json) int {
+	n := 0
+	for _, s := range schemas {
+		if s.Valid(v) {
+			n++
+		}
+	}
+	return n
+}
+
+// typeOf returns the JSON Schema type of v, using "integer" for integral numbers.
+func typeOf(v json.Json) string {
+	switch v.(type) {
+	case json.JsonNull:
+		return "null"
+	case json.JsonBool:
+		return "boolean"
+	case json.JsonString:
+		return "string"
+	case json.JsonArray:
+		return "array"
+	case json.JsonObject:
+		return "object"
+	}
+	if f, ok := number(v); ok && f == math.Trunc(f) {
+		return "integer"
+	}
+	return "number"
+}
+
+// hasType reports whether v is of one of the types. Integers are numbers as well.
+func hasType(v json.Json, types []string) bool {
+	t := typeOf(v)
+	for _, want := range types {
+		if want == t || want == "number" && t == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// number returns the value of a JSON number.
+func number(j json.Json) (float64, bool) {
+	switch j := j.(type) {
+	case json.JsonInt:
+		return float64(j.Val), true
+	case json.JsonFloat:
+		return j.Val, true
+	case json.JsonNumber:
+		f, err := j.AsFloat64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// contains reports whether vals contains a value equal to v.
+func contains(vals []json.Json, v json.Json) bool {
+	for _, e := range vals {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal reports whether two values are equal; numbers compare by value and objects ignore member order.
+func equal(a, b json.Json) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch a := a.(type) {
+	case json.JsonArray:
+		b, ok := b.(json.JsonArray)
+		if !ok || len(a.Val) != len(b.Val) {
+			return false
+		}
+		for i := range a.Val {
+			if !equal(a.Val[i], b.Val[i]) {
+				return false
+			}
+		}
+		return true
+	case json.JsonObject:
+		b, ok := b.(json.JsonObject)
+		if !ok || len(a.Val) != len(b.Val) {
+			return false
+		}
+		for k, v := range a.Val {
+			w, ok := b.Val[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
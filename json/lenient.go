@@ -0,0 +1,187 @@
+package json
+
+import (
+	"errors"
+	"io"
+)
+
+// ParseLenient parses s like ParseNode but keeps going after syntax errors, for tools such as
+// editors that must make sense of documents while they are being written. On an error it skips
+// to the next comma or closing bracket outside the broken part, drops the incomplete element or
+// member, and continues from there; arrays and objects left open at the end of the input are
+// closed. It returns the tree of the values that could be parsed, or nil if there is none, and
+// every error in document order: a *SyntaxError with the description ParseJSON would give for
+// each error, followed by a *parser.LimitError if a limit stopped the parse.
+func ParseLenient(s string, opts ...Option) (*Node, []error) {
+	sc := newScanner(nil, false, opts)
+	sc.buf, sc.eof = s, true
+	l := lenient{src: s, s: sc}
+	l.document()
+	if l.root != nil {
+		l.root.locate(newLineIndex(s))
+		if sc.c.keepComments {
+			attachComments(l.root, s, scanComments(s, sc.c.json5))
+		}
+	}
+	return l.root, l.errs
+}
+
+// lenient builds a node tree from the events of a scanner, repairing its state after errors.
+type lenient struct {
+	src  string
+	s    *Scanner
+	root *Node
+	// open holds the array and object nodes that are open, in step with the stack of the scanner.
+	open []*Node
+	// pending is the member of the innermost open object whose value is parsed next.
+	pending *Member
+	errs    []error
+}
+
+// document reads the whole input.
+func (l *lenient) document() {
+	for {
+		e, err := l.s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if l.recover(err) {
+				continue
+			}
+			break
+		}
+		end := l.s.offset
+		switch e.Kind {
+		case ObjectStart, ArrayStart:
+			n := &Node{Span: Span{Start: Position{Offset: e.Offset}}}
+			l.add(n)
+			l.open = append(l.open, n)
+		case ObjectEnd, ArrayEnd:
+			l.close(end)
+		case Key:
+			keyEnd := end
+			if m := l.s.c.key().Parse(l.src[e.Offset:]); m.IsJust() {
+				keyEnd = len(l.src) - len(m.Get().Second)
+			}
+			l.pending = &Member{Key: e.Key, KeySpan: Span{Start: Position{Offset: e.Offset}, End: Position{Offset: keyEnd}}}
+		case Value:
+			l.add(&Node{Value: e.Value, Span: Span{Start: Position{Offset: e.Offset}, End: Position{Offset: end}}})
+		}
+	}
+	for len(l.open) > 0 {
+		l.pop(len(l.src))
+	}
+}
+
+// add adds the node of a value to the innermost open array or object, or makes it the root.
+func (l *lenient) add(n *Node) {
+	if len(l.open) == 0 {
+		l.root = n
+		return
+	}
+	parent := l.open[len(l.open)-1]
+	if l.pending == nil {
+		parent.Elements = append(parent.Elements, n)
+		return
+	}
+	l.pending.Value = n
+	parent.Members = append(parent.Members, l.pending)
+	l.pending = nil
+}
+
+// close completes the innermost open array or object, which ends at the offset end.
+func (l *lenient) close(end int) {
+	n := l.open[len(l.open)-1]
+	l.open = l.open[:len(l.open)-1]
+	n.Span.End.Offset = end
+	if l.src[n.Span.Start.Offset] == '[' {
+		vals := make([]Json, len(n.Elements))
+		for i, e := range n.Elements {
+			vals[i] = e.Value
+		}
+		n.Value = JsonArray{Val: vals}
+		return
+	}
+	pairs := make([]JsonPair, len(n.Members))
+	for i, m := range n.Members {
+		pairs[i] = JsonPair{Key: m.Key, Value: m.Value.Value}
+	}
+	n.Value = buildObject(l.s.c.duplicates, pairs)
+}
+
+// pop closes the innermost open array or object without its closing bracket, at the offset end.
+func (l *lenient) pop(end int) {
+	l.pending = nil
+	if kind := l.s.stack[len(l.s.stack)-1]; kind == ObjectStart && l.s.c.duplicates == DuplicateError {
+		l.s.keys = l.s.keys[:len(l.s.keys)-1]
+	}
+	l.s.stack = l.s.stack[:len(l.s.stack)-1]
+	l.close(end)
+}
+
+// recover records err and repairs the state of the scanner so that it continues at the next
+// comma or closing bracket. It reports false if parsing cannot continue.
+func (l *lenient) recover(err error) bool {
+	l.errs = append(l.errs, err)
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		return false
+	}
+	s := l.s
+	s.err = nil
+	l.pending = nil
+	at := l.boundary(s.offset, serr.Offset)
+	s.consume(at - s.offset)
+	if at == len(l.src) {
+		return false
+	}
+	for len(s.stack) > 0 {
+		top := s.stack[len(s.stack)-1]
+		c := l.src[at]
+		if c == ',' || c == ']' && top == ArrayStart || c == '}' && top == ObjectStart {
+			// Continue after the last complete element or member of the innermost array or object.
+			s.state = scanObjectNext
+			if top == ArrayStart {
+				s.state = scanArrayNext
+			}
+			return true
+		}
+		// The bracket closes an outer array or object, so the innermost one was not closed.
+		l.pop(at)
+	}
+	// Whatever follows a complete top-level value cannot be part of the document.
+	return false
+}
+
+// boundary returns the offset of the first comma or closing bracket at or after the error offset
+// at, skipping strings and any arrays and objects that open after at, or the length of the input
+// if there is none. Scanning for strings starts at from, the start of the failed token, so that
+// an error inside a string does not mistake its closing quote for an opening one.
+func (l *lenient) boundary(from, at int) int {
+	src := l.src
+	var quote byte
+	depth := 0
+	for i := from; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			switch c {
+			case '\\':
+				i++
+			case quote, '\n':
+				quote = 0
+			}
+		case c == '"' || c == '\'' && l.s.c.json5:
+			quote = c
+		case i < at:
+		case c == '[' || c == '{':
+			depth++
+		case (c == ']' || c == '}') && depth > 0:
+			depth--
+		case depth == 0 && (c == ',' || c == ']' || c == '}'):
+			return i
+		}
+	}
+	return len(src)
+}
@@ -0,0 +1,126 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// messages returns the messages of errs.
+func messages(errs []error) []string {
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return msgs
+}
+
+func TestParseLenient(t *testing.T) {
+	for _, tc := range []struct {
+		name, in string
+		opts     []json.Option
+		want     string
+		errs     []string
+	}{
+		{
+			name: "well-formed",
+			in:   `{"a": [1, 2], "b": null}`,
+			want: `{"a":[1,2],"b":null}`,
+		},
+		{
+			name: "several errors",
+			in:   `{"a": 1, "b": , "c": [1, x, 3], "d" 4, "e": true}`,
+			want: `{"a":1,"c":[1,3],"e":true}`,
+			errs: []string{
+				"json: line 1, col 15: expected value",
+				"json: line 1, col 26: expected value",
+				"json: line 1, col 37: expected ':' after object key",
+			},
+		},
+		{
+			name: "skips nested junk",
+			in:   `[1 {"a": [2, 3]}, 4]`,
+			want: `[1,4]`,
+			errs: []string{"json: line 1, col 4: expected ',' or ']' after array element"},
+		},
+		{
+			name: "error inside a string",
+			in:   `["a\x, b", 2]`,
+			want: `[2]`,
+			errs: []string{"json: line 1, col 4: expected escape sequence"},
+		},
+		{
+			name: "truncated",
+			in:   `[1, {"a": 2, "b": [3`,
+			want: `[1,{"a":2,"b":[3]}]`,
+			errs: []string{"json: line 1, col 21: unexpected end of input, expected ',' or ']' after array element"},
+		},
+		{
+			name: "mismatched bracket",
+			in:   `{"a": [1, 2}`,
+			want: `{"a":[1,2]}`,
+			errs: []string{"json: line 1, col 12: expected ',' or ']' after array element"},
+		},
+		{
+			name: "trailing data",
+			in:   `{"a": 1}}`,
+			want: `{"a":1}`,
+			errs: []string{"json: line 1, col 9: expected end of input"},
+		},
+		{
+			name: "no value",
+			in:   `?`,
+			errs: []string{"json: line 1, col 1: expected value"},
+		},
+		{
+			name: "duplicate keys",
+			in:   `{"a": 1, "a": {"b": 2}, "c": 3}`,
+			opts: []json.Option{json.OnDuplicateKey(json.DuplicateError)},
+			want: `{"a":1,"c":3}`,
+			errs: []string{"json: line 1, col 10: expected unique key"},
+		},
+		{
+			name: "comments",
+			in:   "{\n  // name\n  \"a\": 1,\n  \"b\": oops, /* last */ \"c\": 2,\n}",
+			opts: []json.Option{json.Comments()},
+			want: `{"a":1,"c":2}`,
+			errs: []string{"json: line 4, col 8: expected value", "json: line 5, col 1: expected object key"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, errs := json.ParseLenient(tc.in, tc.opts...)
+			assert.Equal(t, tc.errs, messages(errs))
+			if tc.want == "" {
+				assert.Nil(t, n)
+				return
+			}
+			assert.Equal(t, tc.want, n.Value.String())
+		})
+	}
+}
+
+func TestParseLenientSpans(t *testing.T) {
+	src := "{\"a\": [1, ?],\n \"b\": \"x\""
+	n, errs := json.ParseLenient(src)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, json.Position{Offset: len(src), Line: 2, Column: 10}, n.Span.End)
+	assert.Equal(t, json.Span{
+		Start: json.Position{Offset: 15, Line: 2, Column: 2},
+		End:   json.Position{Offset: 18, Line: 2, Column: 5},
+	}, n.Members[1].KeySpan)
+	assert.Equal(t, json.Position{Offset: 7, Line: 1, Column: 8}, n.Member("a").Elements[0].Span.Start)
+	assert.Equal(t, json.Position{Offset: 12, Line: 1, Column: 13}, n.Member("a").Span.End)
+
+	whole, err := json.ParseNode(`{"a": [1], "b": "x"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, whole.Value, n.Value)
+}
+
+func TestParseLenientLimit(t *testing.T) {
+	n, errs := json.ParseLenient(`[[[1]], [x]]`, json.MaxDepth(2))
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], parser.ErrLimitExceeded)
+	assert.Equal(t, `[[]]`, n.Value.String())
+}
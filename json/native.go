@@ -0,0 +1,144 @@
+package json
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// ToNative converts j to plain Go values: map[string]any for objects, []any for arrays,
+// int64 or float64 for numbers, string, bool, and nil for null. A JsonNumber is returned as is,
+// so that its exact value is not lost.
+func ToNative(j Json) any {
+	switch j := j.(type) {
+	case JsonBool:
+		return j.Val
+	case JsonString:
+		return j.Val
+	case JsonInt:
+		return j.Val
+	case JsonFloat:
+		return j.Val
+	case JsonNumber:
+		return j
+	case JsonArray:
+		s := make([]any, len(j.Val))
+		for i, e := range j.Val {
+			s[i] = ToNative(e)
+		}
+		return s
+	case JsonObject:
+		m := make(map[string]any, len(j.Val))
+		for k, v := range j.Val {
+			m[k] = ToNative(v)
+		}
+		return m
+	}
+	return nil
+}
+
+// FromNative converts a plain Go value to a Json value. It accepts nil, bools, strings,
+// integer and floating-point types, Json values, and slices, arrays and maps with string keys
+// of any of these. Map members are ordered by key. Unsigned integers above math.MaxInt64 become
+// a JsonFloat. Other types, such as structs or channels, are rejected with an error.
+func FromNative(v any) (Json, error) {
+	switch v := v.(type) {
+	case nil:
+		return JsonNull{}, nil
+	case Json:
+		return v, nil
+	case bool:
+		return JsonBool{Val: v}, nil
+	case string:
+		return JsonString{Val: v}, nil
+	case int64:
+		return JsonInt{Val: v}, nil
+	case float64:
+		return JsonFloat{Val: v}, nil
+	case []any:
+		elems := make([]Json, len(v))
+		for i, e := range v {
+			j, err := FromNative(e)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = j
+		}
+		return JsonArray{Val: elems}, nil
+	case map[string]any:
+		obj := JsonObject{Val: make(map[string]Json, len(v)), Keys: make([]string, 0, len(v))}
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			j, err := FromNative(v[k])
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(k, j)
+		}
+		return obj, nil
+	}
+	return fromValue(reflect.ValueOf(v))
+}
+
+// fromValue converts the values FromNative has no fast path for using reflection.
+func fromValue(rv reflect.Value) (Json, error) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return JsonInt{Val: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u > math.MaxInt64 {
+			return JsonFloat{Val: float64(u)}, nil
+		}
+		return JsonInt{Val: int64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return JsonFloat{Val: rv.Float()}, nil
+	case reflect.Bool:
+		return JsonBool{Val: rv.Bool()}, nil
+	case reflect.String:
+		return JsonString{Val: rv.String()}, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return JsonNull{}, nil
+		}
+		return FromNative(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return JsonNull{}, nil
+		}
+		elems := make([]Json, rv.Len())
+		for i := range elems {
+			j, err := FromNative(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = j
+		}
+		return JsonArray{Val: elems}, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return JsonNull{}, nil
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		slices.Sort(keys)
+		obj := JsonObject{Val: make(map[string]Json, len(keys)), Keys: make([]string, 0, len(keys))}
+		for _, k := range keys {
+			j, err := FromNative(rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface())
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(k, j)
+		}
+		return obj, nil
+	}
+	if !rv.IsValid() {
+		return JsonNull{}, nil
+	}
+	return nil, fmt.Errorf("json: cannot convert Go value of type %v", rv.Type())
+}
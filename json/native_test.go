@@ -0,0 +1,50 @@
+package json_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToNative(t *testing.T) {
+	doc, err := json.ParseJSON(`{"a": [1, 2.5, "s", true, null], "b": {}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": []any{int64(1), 2.5, "s", true, nil},
+		"b": map[string]any{},
+	}, json.ToNative(doc))
+}
+
+func TestFromNative(t *testing.T) {
+	t.Run("interface tree", func(t *testing.T) {
+		j, err := json.FromNative(map[string]any{"b": []any{int64(1), 2.5, nil}, "a": "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"x","b":[1,2.5,null]}`, j.String())
+	})
+
+	t.Run("typed values", func(t *testing.T) {
+		n := 7
+		j, err := json.FromNative(map[string][]*int{"p": {&n, nil}})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"p":[7,null]}`, j.String())
+
+		j, err = json.FromNative([2]uint64{1, math.MaxUint64})
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonArray{Val: []json.Json{json.JsonInt{Val: 1}, json.JsonFloat{Val: math.MaxUint64}}}, j)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		doc, err := json.ParseJSON(`{"a": [1, {"b": null}], "c": -2.5}`)
+		assert.NoError(t, err)
+		back, err := json.FromNative(json.ToNative(doc))
+		assert.NoError(t, err)
+		assert.Equal(t, doc.String(), back.String())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := json.FromNative(map[string]any{"ch": make(chan int)})
+		assert.EqualError(t, err, "json: cannot convert Go value of type chan int")
+	})
+}
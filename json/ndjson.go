@@ -0,0 +1,66 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"reflect"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ReadNDJSON returns an iterator over the values of the newline-delimited JSON read from r,
+// one JSON document per line. Blank lines are skipped. A malformed line is reported as a
+// *SyntaxError with the line number and offset of the error in r, after which iteration
+// continues with the next line. An error reading r ends the iteration.
+// The options apply to each line as for ParseJSON.
+func ReadNDJSON(r io.Reader, opts ...Option) iter.Seq2[Json, error] {
+	c := newConfig(opts)
+	val := jVal(c)
+	return readLines(r, func(text string) (Json, error) {
+		v, err := parser.RunWith(val, text, c.limits)
+		return v, syntaxError(err)
+	})
+}
+
+// UnmarshalNDJSON is like ReadNDJSON but decodes every line into a T as Unmarshal does.
+// A line that does not fit a T is reported as a *parser.LineError wrapping the *UnmarshalTypeError.
+func UnmarshalNDJSON[T any](r io.Reader, opts ...Option) iter.Seq2[T, error] {
+	c := newConfig(opts)
+	val := jVal(c)
+	return readLines(r, func(text string) (T, error) {
+		var t T
+		v, err := parser.RunWith(val, text, c.limits)
+		if err != nil {
+			return t, syntaxError(err)
+		}
+		err = unmarshal(v, reflect.ValueOf(&t).Elem(), "")
+		return t, err
+	})
+}
+
+// readLines returns an iterator over the non-blank lines of r converted by parse.
+// A *SyntaxError is moved to its line of r; other errors are reported as a *parser.LineError.
+func readLines[T any](r io.Reader, parse func(string) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for l, err := range parser.ReadLines(r, parser.LineOptions{SkipBlank: true}) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			v, err := parse(l.Text)
+			var serr *SyntaxError
+			switch {
+			case errors.As(err, &serr):
+				serr.Line = l.Number
+				serr.Offset += l.Offset
+			case err != nil:
+				err = &parser.LineError{Line: l.Number, Text: l.Text, Err: err}
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
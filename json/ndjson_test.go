@@ -0,0 +1,80 @@
+package json_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadNDJSON(t *testing.T) {
+	input := "{\"a\": 1}\r\n[2, 3]\n\n  \n\"four\"\n{bad}\n5"
+	var got []string
+	var errs []error
+	for v, err := range json.ReadNDJSON(strings.NewReader(input)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{`{"a":1}`, `[2,3]`, `"four"`, `5`}, got)
+	assert.Len(t, errs, 1)
+
+	var serr *json.SyntaxError
+	assert.True(t, errors.As(errs[0], &serr))
+	assert.Equal(t, 6, serr.Line)
+	assert.Equal(t, 2, serr.Column)
+	assert.Equal(t, 29, serr.Offset)
+
+	t.Run("stop early", func(t *testing.T) {
+		count := 0
+		for range json.ReadNDJSON(strings.NewReader("1\n2\n3\n")) {
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		var last error
+		for _, err := range json.ReadNDJSON(iotest.ErrReader(errors.New("boom"))) {
+			last = err
+		}
+		assert.EqualError(t, last, "boom")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		for v, err := range json.ReadNDJSON(strings.NewReader("[1,] // note\n"), json.TrailingCommas(), json.Comments()) {
+			assert.NoError(t, err)
+			assert.Equal(t, `[1]`, v.String())
+		}
+	})
+}
+
+func TestUnmarshalNDJSON(t *testing.T) {
+	type event struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	input := `{"name": "a", "count": 1}
+{"name": "b", "count": "many"}
+{"name": "c", "count": 3}`
+	var got []event
+	var errs []error
+	for e, err := range json.UnmarshalNDJSON[event](strings.NewReader(input)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, e)
+	}
+	assert.Equal(t, []event{{"a", 1}, {"c", 3}}, got)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `line 2: "{\"name\": \"b\", \"count\": \"many\"}": json: cannot unmarshal string into Go value of type int at count`)
+}
@@ -0,0 +1,206 @@
+package json
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Position is a location in the source text.
+type Position struct {
+	// Offset is the 0-based byte offset.
+	Offset int
+	// Line is the 1-based line number.
+	Line int
+	// Column is the 1-based column, counted in runes.
+	Column int
+}
+
+// String returns the position as "line L, column C".
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// Span is the source range of a value, from Start up to but not including End.
+type Span struct {
+	// Start is the position of the first character of the value.
+	Start Position
+	// End is the position just after the last character of the value.
+	End Position
+}
+
+// Node is a JSON value together with its source span.
+// Array elements and object members are nodes as well, so every value in a document can be located.
+type Node struct {
+	// Value is the parsed value, including all nested values.
+	Value Json
+	// Span is the source range of the value, excluding surrounding whitespace.
+	Span Span
+	// Elements are the elements of an array value, in order.
+	Elements []*Node
+	// Members are the members of an object value, in document order.
+	Members []*Member
+	// Comments are the comments around the value, recorded with the KeepComments option.
+	Comments NodeComments
+}
+
+// Member is a key-value pair of an object node.
+type Member struct {
+	// Key is the decoded key.
+	Key string
+	// KeySpan is the source range of the key, including its quotes.
+	KeySpan Span
+	// Value is the member value.
+	Value *Node
+}
+
+// Member returns the value of the last member with the given key, or nil if the node has none.
+func (n *Node) Member(key string) *Node {
+	for i := len(n.Members) - 1; i >= 0; i-- {
+		if n.Members[i].Key == key {
+			return n.Members[i].Value
+		}
+	}
+	return nil
+}
+
+// ParseNode parses a JSON document into a tree of nodes recording source positions,
+// so that tools validating the document can point at the offending value.
+func ParseNode(s string, opts ...Option) (*Node, error) {
+	c := newConfig(opts)
+	n, err := parser.RunWith(parser.OmitRight(jNode(c), c.space()), s, c.limits)
+	if err != nil {
+		return nil, syntaxError(err)
+	}
+	idx := newLineIndex(s)
+	n.locate(idx)
+	if c.keepComments {
+		attachComments(n, s, scanComments(s, c.json5))
+	}
+	return n, nil
+}
+
+// jNode builds the grammar of JSON values producing nodes for the configuration c.
+// Every value skips the whitespace before it, so its span starts at its first character.
+func jNode(c *config) parser.Parser[*Node] {
+	var val parser.Parser[*Node]
+	ref := parser.Lazy(func() parser.Parser[*Node] { return val })
+	scalar := parser.OrElse(
+		parser.Fmap(c.str(), func(s string) Json { return JsonString{Val: s} }),
+		jNumber(c),
+		parser.Fmap(parser.Str("true"), func(string) Json { return JsonBool{Val: true} }),
+		parser.Fmap(parser.Str("false"), func(string) Json { return JsonBool{Val: false} }),
+		parser.Fmap(parser.Str("null"), func(string) Json { return JsonNull{} }),
+	)
+	val = trimLeft(c, parser.OrElse(
+		spanned(scalar, func(v Json, sp Span) *Node { return &Node{Value: v, Span: sp} }),
+		spanned(nodeArray(c, ref), func(elems []*Node, sp Span) *Node {
+			vals := make([]Json, len(elems))
+			for i, e := range elems {
+				vals[i] = e.Value
+			}
+			return &Node{Value: JsonArray{Val: vals}, Span: sp, Elements: elems}
+		}),
+		spanned(nodeObject(c, ref), func(members []*Member, sp Span) *Node {
+			pairs := make([]JsonPair, len(members))
+			for i, m := range members {
+				pairs[i] = JsonPair{Key: m.Key, Value: m.Value.Value}
+			}
+			return &Node{Value: buildObject(c.duplicates, pairs), Span: sp, Members: members}
+		}),
+	))
+	return val
+}
+
+// nodeArray parses the elements of a JSON array, parsed by val, between square brackets.
+func nodeArray(c *config, val parser.Parser[*Node]) parser.Parser[[]*Node] {
+	return parser.Nested(parser.Between(
+		parser.Char('['),
+		list(c, val, trim(c, parser.Char(','))),
+		trimLeft(c, parser.Char(']')),
+	))
+}
+
+// nodeObject parses the members of a JSON object, whose values are parsed by val, between curly braces.
+func nodeObject(c *config, val parser.Parser[*Node]) parser.Parser[[]*Member] {
+	object := func(lit parser.Parser[string]) parser.Parser[[]*Member] {
+		key := spanned(lit, func(k string, sp Span) *Member {
+			return &Member{Key: k, KeySpan: sp}
+		})
+		member := parser.Bind(trimLeft(c, key), func(m *Member) parser.Parser[*Member] {
+			return parser.Fmap(parser.OmitLeft(trim(c, parser.Char(':')), val), func(v *Node) *Member {
+				m.Value = v
+				return m
+			})
+		})
+		return parser.Nested(parser.Between(
+			parser.Char('{'),
+			list(c, member, trim(c, parser.Char(','))),
+			trimLeft(c, parser.Char('}')),
+		))
+	}
+	if c.duplicates != DuplicateError {
+		return object(c.key())
+	}
+	return perObject(func(unique func(string) bool) parser.Parser[[]*Member] {
+		return object(parser.SatisfyWithMsg(c.key(), unique, "unique key"))
+	})
+}
+
+// spanned runs p and passes its result to f together with the byte offsets it consumed.
+// Lines and columns are filled in once parsing is complete.
+func spanned[T, U any](p parser.Parser[T], f func(T, Span) U) parser.Parser[U] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[U] {
+		m := p.RunState(st)
+		if m.IsNothing() {
+			return parser.Nothing[parser.Tuple[U, parser.State]]()
+		}
+		t := m.Get()
+		sp := Span{Start: Position{Offset: st.Offset()}, End: Position{Offset: t.Second.Offset()}}
+		return parser.Just(parser.NewTuple(f(t.First, sp), t.Second))
+	})
+}
+
+// locate fills in the lines and columns of all spans in the tree rooted at n.
+func (n *Node) locate(idx lineIndex) {
+	n.Span = idx.span(n.Span)
+	for _, e := range n.Elements {
+		e.locate(idx)
+	}
+	for _, m := range n.Members {
+		m.KeySpan = idx.span(m.KeySpan)
+		m.Value.locate(idx)
+	}
+}
+
+// lineIndex converts byte offsets of a source text into lines and columns.
+type lineIndex struct {
+	src string
+	// starts holds the byte offset at which each line starts.
+	starts []int
+}
+
+// newLineIndex indexes the line starts of src.
+func newLineIndex(src string) lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return lineIndex{src: src, starts: starts}
+}
+
+// position returns the position of the byte offset.
+func (idx lineIndex) position(offset int) Position {
+	line := sort.Search(len(idx.starts), func(i int) bool { return idx.starts[i] > offset }) - 1
+	col := utf8.RuneCountInString(idx.src[idx.starts[line]:offset]) + 1
+	return Position{Offset: offset, Line: line + 1, Column: col}
+}
+
+// span fills in the lines and columns of sp from its offsets.
+func (idx lineIndex) span(sp Span) Span {
+	return Span{Start: idx.position(sp.Start.Offset), End: idx.position(sp.End.Offset)}
+}
@@ -0,0 +1,54 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNode(t *testing.T) {
+	src := "{\n  \"name\": \"tiny\",\n  \"tags\": [1, true]\n}"
+
+	t.Run("spans", func(t *testing.T) {
+		n, err := json.ParseNode(src)
+		assert.NoError(t, err)
+		assert.Equal(t, json.Position{Offset: 0, Line: 1, Column: 1}, n.Span.Start)
+		assert.Equal(t, json.Position{Offset: len(src), Line: 4, Column: 2}, n.Span.End)
+
+		name := n.Member("name")
+		assert.Equal(t, json.JsonString{Val: "tiny"}, name.Value)
+		assert.Equal(t, "line 2, column 11", name.Span.Start.String())
+		assert.Equal(t, json.Position{Offset: 4, Line: 2, Column: 3}, n.Members[0].KeySpan.Start)
+
+		tags := n.Member("tags")
+		assert.Len(t, tags.Elements, 2)
+		assert.Equal(t, json.Position{Offset: 34, Line: 3, Column: 15}, tags.Elements[1].Span.Start)
+		assert.Equal(t, json.Position{Offset: 38, Line: 3, Column: 19}, tags.Elements[1].Span.End)
+	})
+
+	t.Run("value matches ParseJSON", func(t *testing.T) {
+		n, err := json.ParseNode(src)
+		assert.NoError(t, err)
+		v, err := json.ParseJSON(src)
+		assert.NoError(t, err)
+		assert.Equal(t, v, n.Value)
+	})
+
+	t.Run("columns count runes", func(t *testing.T) {
+		n, err := json.ParseNode(`["é", 1]`)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, n.Elements[1].Span.Start.Column)
+	})
+
+	t.Run("missing member", func(t *testing.T) {
+		n, err := json.ParseNode(`{}`)
+		assert.NoError(t, err)
+		assert.Nil(t, n.Member("x"))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := json.ParseNode(`[1, }`)
+		assert.ErrorContains(t, err, "line 1, col 5")
+	})
+}
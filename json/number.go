@@ -0,0 +1,143 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// JsonNumber represents a JSON number by its literal text, so that no precision is lost.
+// It is produced by the parser only when requested with the PreciseNumbers or UseNumber option.
+type JsonNumber struct {
+	// Val is the number as written in the document, e.g. "12345678901234567890" or "0.1e-2".
+	Val string
+}
+
+// jsonType implements the Json interface for JsonNumber.
+func (j JsonNumber) jsonType() {}
+
+// String returns the literal text of the number.
+func (j JsonNumber) String() string { return stringOf(j) }
+
+// MarshalJSON returns the literal text of the number.
+func (j JsonNumber) MarshalJSON() ([]byte, error) { return appendValue(nil, j) }
+
+// errNotInteger is returned when a number with a fractional part is requested as an integer.
+var errNotInteger = errors.New("json: number is not an integer")
+
+// AsInt64 returns the number as an int64. It fails if the number has a fractional part
+// or does not fit in an int64.
+func (j JsonNumber) AsInt64() (int64, error) {
+	if i, err := strconv.ParseInt(j.Val, 10, 64); err == nil {
+		return i, nil
+	}
+	b, err := j.AsBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !b.IsInt64() {
+		return 0, fmt.Errorf("json: number %s overflows int64", j.Val)
+	}
+	return b.Int64(), nil
+}
+
+// AsFloat64 returns the nearest float64 to the number. It fails if the number is out of the float64 range.
+func (j JsonNumber) AsFloat64() (float64, error) {
+	f, err := strconv.ParseFloat(j.Val, 64)
+	if err != nil {
+		return f, fmt.Errorf("json: number %s overflows float64", j.Val)
+	}
+	return f, nil
+}
+
+// AsBigInt returns the number as an arbitrary-precision integer. It fails if the number has a fractional part.
+func (j JsonNumber) AsBigInt() (*big.Int, error) {
+	r, err := j.AsRat()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, errNotInteger
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// AsRat returns the exact value of the number as a rational number.
+func (j JsonNumber) AsRat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(j.Val)
+	if !ok {
+		return nil, fmt.Errorf("json: invalid number %q", j.Val)
+	}
+	return r, nil
+}
+
+// numberOf converts a number literal, given as its sign and the rest, into a Json value according to c.
+// The JSON5 forms of numbers are converted to their JSON equivalents first. It returns nil like number.
+func (c *config) numberOf(sign, body string) Json {
+	neg := sign == "-"
+	switch {
+	case body == "Infinity" && neg:
+		return JsonFloat{Val: math.Inf(-1)}
+	case body == "Infinity":
+		return JsonFloat{Val: math.Inf(1)}
+	case body == "NaN":
+		return JsonFloat{Val: math.NaN()}
+	case strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X"):
+		n, _ := new(big.Int).SetString(body[2:], 16)
+		if neg {
+			n.Neg(n)
+		}
+		return c.number(n.String(), true)
+	}
+	integer := !strings.ContainsAny(body, ".eE")
+	// Normalize .5 to 0.5 and 5. or 5.e1 to 5 and 5e1.
+	if body[0] == '.' {
+		body = "0" + body
+	}
+	if i := strings.IndexByte(body, '.'); i >= 0 && (i+1 == len(body) || body[i+1] < '0' || body[i+1] > '9') {
+		body = body[:i] + body[i+1:]
+	}
+	if neg {
+		body = "-" + body
+	}
+	return c.number(body, integer)
+}
+
+// number converts the literal text of a JSON number into a Json value according to c.
+// integer reports whether the literal has neither a fraction nor an exponent.
+// It returns nil for a number out of the float64 range that is not kept as a JsonNumber.
+func (c *config) number(text string, integer bool) Json {
+	if c.useNumber {
+		return JsonNumber{Val: text}
+	}
+	if integer {
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return JsonInt{Val: i}
+		}
+		if c.preciseNumbers {
+			return JsonNumber{Val: text}
+		}
+	}
+	// Out of range values become ±Inf, as with strconv.ParseFloat.
+	f, _ := strconv.ParseFloat(text, 64)
+	if c.preciseNumbers && !exactFloat(f, text) {
+		return JsonNumber{Val: text}
+	}
+	if math.IsInf(f, 0) {
+		return nil
+	}
+	return JsonFloat{Val: f}
+}
+
+// exactFloat reports whether f, written in its shortest form, has the same value as the literal text.
+func exactFloat(f float64, text string) bool {
+	if math.IsInf(f, 0) {
+		return false
+	}
+	lit, ok1 := new(big.Rat).SetString(text)
+	short, ok2 := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return ok1 && ok2 && lit.Cmp(short) == 0
+}
@@ -0,0 +1,211 @@
+package json
+
+import "github.com/81120/tiny-parsec/parser"
+
+// Option configures the JSON grammar built by JVal, ParseJSON and the other parsers of this package.
+type Option func(*config)
+
+// config holds the settings selected by options.
+type config struct {
+	// strictStrings rejects unescaped control characters in strings.
+	strictStrings bool
+	// duplicates is the policy for keys occurring more than once in an object.
+	duplicates DuplicatePolicy
+	// preciseNumbers keeps numbers that JsonInt and JsonFloat cannot hold exactly as JsonNumber.
+	preciseNumbers bool
+	// useNumber returns every number as a JsonNumber.
+	useNumber bool
+	// json5 enables the JSON5 extensions to strings, keys, numbers and whitespace.
+	json5 bool
+	// comments accepts // line comments and /* */ block comments wherever whitespace is allowed.
+	comments bool
+	// keepComments attaches comments to the nodes built by ParseNode and ParseLenient.
+	keepComments bool
+	// trailingCommas accepts a comma after the last element of an array or member of an object.
+	trailingCommas bool
+	// nonFinite accepts the number literals NaN, Infinity and -Infinity.
+	nonFinite bool
+	// fast parses with the tokenizer of the fast path where possible.
+	fast bool
+	// limits are the resource limits enforced by ParseJSON and the functions built on it.
+	limits parser.Options
+}
+
+// newConfig applies opts to the default configuration.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// StrictStrings rejects strings containing unescaped control characters (U+0000 to U+001F),
+// which RFC 8259 forbids but the default grammar accepts. It is useful for conformance validation.
+func StrictStrings() Option {
+	return func(c *config) {
+		c.strictStrings = true
+	}
+}
+
+// PreciseNumbers keeps numeric literals that would be corrupted by conversion, such as integers
+// beyond the int64 range or decimals with more digits than a float64 holds, as JsonNumber values
+// carrying the original text. Other numbers are still returned as JsonInt or JsonFloat.
+func PreciseNumbers() Option {
+	return func(c *config) {
+		c.preciseNumbers = true
+	}
+}
+
+// UseNumber returns every numeric literal as a JsonNumber carrying its text, e.g. both 1 and 1.0,
+// instead of choosing between JsonInt and JsonFloat by the form of the literal. Code consuming the
+// values then handles a single number type and converts with the methods of JsonNumber as needed.
+// The JSON5 forms of numbers are converted to their JSON equivalents, e.g. 0x1F to 31 and .5 to
+// 0.5; NaN and the infinities, which have no JSON equivalent, remain JsonFloat values.
+func UseNumber() Option {
+	return func(c *config) {
+		c.useNumber = true
+	}
+}
+
+// Comments accepts // line comments and /* */ block comments wherever whitespace is allowed,
+// as in JSONC files such as VS Code's settings.json and tsconfig.json. Comments are discarded.
+func Comments() Option {
+	return func(c *config) {
+		c.comments = true
+	}
+}
+
+// TrailingCommas accepts a comma after the last element of an array or member of an object,
+// e.g. [1, 2,], as often left in hand-edited configuration files. A lone comma, as in [,], is still rejected.
+func TrailingCommas() Option {
+	return func(c *config) {
+		c.trailingCommas = true
+	}
+}
+
+// AllowNaN accepts the number literals NaN, Infinity and -Infinity, which RFC 8259 forbids,
+// as JsonFloat values. They are written by Python's json.dumps with allow_nan=True, its default.
+func AllowNaN() Option {
+	return func(c *config) {
+		c.nonFinite = true
+	}
+}
+
+// MaxDepth limits the nesting of arrays and objects to n levels. Deeper documents are rejected
+// with a *parser.LimitError before they can exhaust the stack. It applies to ParseJSON, ParseNode,
+// Unmarshal and Decode; parsers returned by JVal are limited with parser.RunWith instead.
+func MaxDepth(n int) Option {
+	return func(c *config) {
+		c.limits.MaxDepth = n
+	}
+}
+
+// MaxBytes limits documents to n bytes. Longer documents are rejected with a *parser.LimitError
+// once parsing reaches the limit. It applies to the same functions as MaxDepth.
+func MaxBytes(n int) Option {
+	return func(c *config) {
+		c.limits.MaxBytes = n
+	}
+}
+
+// Options bundles the settings of the functional options in a single value, so that a complete
+// configuration can be stored, compared or passed through layers of code as one argument.
+// The zero value selects the default grammar without limits.
+type Options struct {
+	// StrictStrings rejects unescaped control characters in strings, like the StrictStrings option.
+	StrictStrings bool
+	// Duplicates is the policy for repeated object keys, as set by OnDuplicateKey.
+	Duplicates DuplicatePolicy
+	// JSON5 accepts JSON5 documents like the JSON5 option. It implies Comments, TrailingCommas and AllowNaN.
+	JSON5 bool
+	// Comments accepts // and /* */ comments, like the Comments option.
+	Comments bool
+	// KeepComments attaches comments to syntax tree nodes, like the KeepComments option. It implies Comments.
+	KeepComments bool
+	// TrailingCommas accepts a comma after the last element or member, like the TrailingCommas option.
+	TrailingCommas bool
+	// AllowNaN accepts NaN, Infinity and -Infinity, like the AllowNaN option.
+	AllowNaN bool
+	// PreciseNumbers keeps numbers that cannot be held exactly as JsonNumber, like the PreciseNumbers option.
+	PreciseNumbers bool
+	// UseNumber returns every number as a JsonNumber, like the UseNumber option.
+	UseNumber bool
+	// FastPath parses with the dedicated tokenizer where possible, like the FastPath option.
+	FastPath bool
+	// MaxDepth and MaxBytes are the limits set by the MaxDepth and MaxBytes options; zero means no limit.
+	MaxDepth, MaxBytes int
+}
+
+// WithOptions returns an Option applying all the settings of o, replacing those made by earlier
+// options, so that an Options value can be used wherever functional options are accepted.
+func WithOptions(o Options) Option {
+	return func(c *config) {
+		*c = config{
+			strictStrings:  o.StrictStrings,
+			duplicates:     o.Duplicates,
+			preciseNumbers: o.PreciseNumbers,
+			useNumber:      o.UseNumber,
+			comments:       o.Comments || o.KeepComments,
+			keepComments:   o.KeepComments,
+			trailingCommas: o.TrailingCommas,
+			nonFinite:      o.AllowNaN,
+			fast:           o.FastPath,
+			limits:         parser.Options{MaxDepth: o.MaxDepth, MaxBytes: o.MaxBytes},
+		}
+		if o.JSON5 {
+			JSON5()(c)
+		}
+	}
+}
+
+// DuplicatePolicy decides what happens when a key occurs more than once in an object.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepLast keeps the value of the last occurrence. It is the default.
+	DuplicateKeepLast DuplicatePolicy = iota
+	// DuplicateKeepFirst keeps the value of the first occurrence.
+	DuplicateKeepFirst
+	// DuplicateError rejects the document, reporting the position of the repeated key.
+	DuplicateError
+	// DuplicateCollect replaces the values of a repeated key by a JsonArray holding all of them in order.
+	// Keys occurring only once keep their value.
+	DuplicateCollect
+)
+
+// OnDuplicateKey sets the policy for keys occurring more than once in an object.
+func OnDuplicateKey(policy DuplicatePolicy) Option {
+	return func(c *config) {
+		c.duplicates = policy
+	}
+}
+
+// buildObject creates an object from pairs in document order, resolving duplicate keys with policy.
+// Every key keeps the position of its first occurrence.
+func buildObject(policy DuplicatePolicy, pairs []JsonPair) JsonObject {
+	obj := JsonObject{Val: make(map[string]Json, len(pairs)), Keys: make([]string, 0, len(pairs))}
+	var counts map[string]int
+	for _, p := range pairs {
+		prev, ok := obj.Val[p.Key]
+		switch {
+		case !ok:
+			obj.Keys = append(obj.Keys, p.Key)
+			obj.Val[p.Key] = p.Value
+		case policy == DuplicateKeepFirst:
+		case policy == DuplicateCollect:
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			if counts[p.Key] == 0 {
+				prev = JsonArray{Val: []Json{prev}}
+			}
+			counts[p.Key]++
+			arr := prev.(JsonArray)
+			obj.Val[p.Key] = JsonArray{Val: append(arr.Val, p.Value)}
+		default:
+			obj.Val[p.Key] = p.Value
+		}
+	}
+	return obj
+}
@@ -2,28 +2,45 @@
 package json
 
 import (
+	"io"
+	"strings"
+
 	"github.com/81120/tiny-parsec/parser"
 )
 
 // JVal parses a JSON value, which can be a string, number, boolean, null, array, or object.
 // It uses the OrElse combinator to try different parsers in order until one succeeds.
-func JVal() parser.Parser[Json] {
-	return parser.OrElse(
-		JString(),
-		JFloat(),
-		JInt(),
-		JBool(),
-		JNull(),
-		parser.Lazy(JArray),
-		parser.Lazy(JObject),
+// Nested values are parsed by the same parser through a Lazy reference, so the grammar is built only once.
+// The options select stricter or more lenient variants of the grammar.
+func JVal(opts ...Option) parser.Parser[Json] {
+	return jVal(newConfig(opts))
+}
+
+// jVal builds the JSON value grammar for the configuration c.
+func jVal(c *config) parser.Parser[Json] {
+	var val parser.Parser[Json]
+	ref := parser.Lazy(func() parser.Parser[Json] { return val })
+	val = parser.OrElse(
+		jString(c),
+		trim(c, jNumber(c)),
+		jBool(c),
+		jNull(c),
+		jArray(c, ref),
+		jObject(c, ref),
 	)
+	return val
 }
 
 // JNull parses the JSON null value and returns a JsonNull object.
 // It uses the Fmap combinator to transform the parsed string "null" into a JsonNull object.
 func JNull() parser.Parser[Json] {
+	return jNull(&config{})
+}
+
+// jNull parses the JSON null value, skipping whitespace according to the configuration c.
+func jNull(c *config) parser.Parser[Json] {
 	return parser.Fmap(
-		parser.Symbol("null"),
+		trim(c, parser.Str("null")),
 		func(_ string) Json {
 			return JsonNull{}
 		})
@@ -32,15 +49,130 @@ func JNull() parser.Parser[Json] {
 // JBool parses a JSON boolean value (true or false) and returns a JsonBool object.
 // It uses the OrElse combinator to try parsing "true" or "false", and then the Fmap combinator to transform the result.
 func JBool() parser.Parser[Json] {
+	return jBool(&config{})
+}
+
+// jBool parses a JSON boolean value, skipping whitespace according to the configuration c.
+func jBool(c *config) parser.Parser[Json] {
 	return parser.Fmap(
-		parser.OrElse(parser.Symbol("true"), parser.Symbol("false")),
+		trim(c, parser.OrElse(parser.Str("true"), parser.Str("false"))),
 		func(str string) Json {
 			return JsonBool{Val: str == "true"}
 		})
 }
 
+// JNumber parses a JSON number following the grammar of RFC 8259: an optional minus sign,
+// an integer part without leading zeros, an optional fraction and an optional exponent.
+// Numbers without a fraction or exponent that fit in an int64 are returned as a JsonInt,
+// all other numbers as a JsonFloat. With the PreciseNumbers option, numbers that neither
+// type can hold exactly are returned as a JsonNumber; otherwise a number out of the float64
+// range, e.g. 1e400, is rejected.
+func JNumber(opts ...Option) parser.Parser[Json] {
+	return parser.Trim(jNumber(newConfig(opts)))
+}
+
+// jNumber parses a JSON number without surrounding whitespace according to the configuration c.
+func jNumber(c *config) parser.Parser[Json] {
+	nonZero := parser.SatisfyMsg(func(r rune) bool { return r >= '1' && r <= '9' }, "digit")
+	digits := parser.ToString(parser.ZeroOrMore(parser.Digit()), false)
+	intPart := parser.OrElse(parser.Str("0"), joined(parser.ToString(nonZero, false), digits))
+	plusMinus := parser.ToString(parser.OrElse(parser.Char('+'), parser.Char('-')), false)
+	exp := optional(joined(
+		parser.ToString(parser.OrElse(parser.Char('e'), parser.Char('E')), false),
+		optional(plusMinus),
+		parser.Digits(),
+	))
+	sign := optional(parser.Str("-"))
+	var bodies []parser.Parser[string]
+	if c.nonFinite {
+		bodies = append(bodies, parser.Str("Infinity"))
+	}
+	if c.json5 {
+		sign = optional(plusMinus)
+		bodies = append(bodies, parser.Str("NaN"))
+		hexDigits := parser.ToString(parser.OneOrMore(parser.SatisfyMsg(isHexDigit, "hex digit")), false)
+		bodies = append(bodies,
+			joined(parser.OrElse(parser.Str("0x"), parser.Str("0X")), hexDigits),
+			joined(intPart, optional(joined(parser.Str("."), digits)), exp),
+			joined(parser.Str("."), parser.Digits(), exp),
+		)
+	} else {
+		bodies = append(bodies, joined(intPart, optional(joined(parser.Str("."), parser.Digits())), exp))
+	}
+	number := parser.Seq(sign, parser.OrElse(bodies...))
+	if c.nonFinite && !c.json5 {
+		// Only JSON5 allows a sign before NaN.
+		number = parser.OrElse(parser.Seq(parser.Pure(""), parser.Str("NaN")), number)
+	}
+	value := parser.Fmap(number, func(parts []string) Json {
+		return c.numberOf(parts[0], parts[1])
+	})
+	return parser.SatisfyWithMsg(value, func(v Json) bool { return v != nil }, numberInRange)
+}
+
+// numberInRange describes the numbers accepted without the PreciseNumbers or UseNumber option.
+const numberInRange = "number within the range of float64"
+
+// isHexDigit reports whether r is a hexadecimal digit.
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+// joined runs ps in sequence and concatenates their results.
+func joined(ps ...parser.Parser[string]) parser.Parser[string] {
+	return parser.Fmap(parser.Seq(ps...), func(strs []string) string {
+		return strings.Join(strs, "")
+	})
+}
+
+// optional runs p if it matches and returns the empty string otherwise.
+func optional(p parser.Parser[string]) parser.Parser[string] {
+	return parser.Fmap(parser.ZeroOrOne(p), func(m parser.Maybe[string]) string {
+		if m.IsJust() {
+			return m.Get()
+		}
+		return ""
+	})
+}
+
+// trim skips the whitespace, and comments if enabled, around p according to the configuration c.
+func trim[T any](c *config, p parser.Parser[T]) parser.Parser[T] {
+	if !c.json5 && !c.comments {
+		return parser.Trim(p)
+	}
+	space := c.space()
+	return parser.OmitLeft(space, parser.OmitRight(p, space))
+}
+
+// trimLeft skips the whitespace, and comments if enabled, before p according to the configuration c.
+func trimLeft[T any](c *config, p parser.Parser[T]) parser.Parser[T] {
+	if !c.json5 && !c.comments {
+		return parser.TrimLeft(p)
+	}
+	return parser.OmitLeft(c.space(), p)
+}
+
+// list parses zero or more p separated by sep. If c permits trailing commas,
+// a separator may also follow the last p, but not stand alone.
+func list[T, U any](c *config, p parser.Parser[T], sep parser.Parser[U]) parser.Parser[[]T] {
+	if !c.trailingCommas {
+		return parser.SepBy(p, sep)
+	}
+	return parser.OrElse(
+		parser.Bind(p, func(first T) parser.Parser[[]T] {
+			return parser.Fmap(
+				parser.OmitRight(parser.ZeroOrMore(parser.OmitLeft(sep, p)), parser.ZeroOrOne(sep)),
+				func(rest []T) []T {
+					return append([]T{first}, rest...)
+				})
+		}),
+		parser.Pure([]T{}),
+	)
+}
+
 // JInt parses a JSON integer value and returns a JsonInt object.
 // It uses the Trim combinator to remove leading and trailing whitespace, and the Fmap combinator to transform the parsed integer.
+// It accepts only plain integers; JVal uses JNumber, which handles the full number grammar.
 func JInt() parser.Parser[Json] {
 	return parser.Trim(
 		parser.Fmap(parser.Integer(), func(i int64) Json {
@@ -50,6 +182,7 @@ func JInt() parser.Parser[Json] {
 
 // JFloat parses a JSON floating-point value and returns a JsonFloat object.
 // It uses the Trim combinator to remove leading and trailing whitespace, and the Fmap combinator to transform the parsed float.
+// It accepts only numbers with a fraction and no exponent; JVal uses JNumber, which handles the full number grammar.
 func JFloat() parser.Parser[Json] {
 	return parser.Trim(
 		parser.Fmap(parser.Float(), func(f float64) Json {
@@ -59,24 +192,46 @@ func JFloat() parser.Parser[Json] {
 
 // JString parses a JSON string value and returns a JsonString object.
 // It uses the Trim combinator to remove leading and trailing whitespace, and the Fmap combinator to transform the parsed string.
-func JString() parser.Parser[Json] {
-	return parser.Trim(
-		parser.Fmap(parser.String(), func(s string) Json {
+// Escape sequences, including \uXXXX and UTF-16 surrogate pairs, are decoded.
+// With the StrictStrings option, unescaped control characters are rejected.
+func JString(opts ...Option) parser.Parser[Json] {
+	return jString(newConfig(opts))
+}
+
+// jString parses a JSON string value according to the configuration c.
+func jString(c *config) parser.Parser[Json] {
+	return trim(c,
+		parser.Fmap(c.str(), func(s string) Json {
+			return JsonString{Val: s}
+		}))
+}
+
+// jKey parses an object key according to the configuration c.
+func jKey(c *config) parser.Parser[Json] {
+	return trim(c,
+		parser.Fmap(c.key(), func(s string) Json {
 			return JsonString{Val: s}
 		}))
 }
 
 // JArray parses a JSON array value and returns a JsonArray object.
 // It uses the Between combinator to parse the array enclosed in square brackets, and the SepBy combinator to parse the elements separated by commas.
-func JArray() parser.Parser[Json] {
+// Each array counts as one level of nesting towards the MaxDepth limit.
+func JArray(opts ...Option) parser.Parser[Json] {
+	c := newConfig(opts)
+	return jArray(c, jVal(c))
+}
+
+// jArray parses a JSON array according to the configuration c, whose elements are parsed by val.
+func jArray(c *config, val parser.Parser[Json]) parser.Parser[Json] {
 	return parser.Fmap(
 		// 处理方括号包围的数组结构
 		// Parse the array structure enclosed in square brackets
-		parser.Between(
-			parser.Trim(parser.Char('[')),                       // 左括号及空白
-			parser.SepBy(JVal(), parser.Trim(parser.Char(','))), // 逗号分隔的元素
-			parser.Trim(parser.Char(']')),                       // 右括号及空白
-		),
+		parser.Nested(parser.Between(
+			trim(c, parser.Char('[')),               // 左括号及空白
+			list(c, val, trim(c, parser.Char(','))), // 逗号分隔的元素
+			trim(c, parser.Char(']')),               // 右括号及空白
+		)),
 		func(elements []Json) Json {
 			return JsonArray{Val: elements}
 		},
@@ -85,17 +240,24 @@ func JArray() parser.Parser[Json] {
 
 // JPair parses a JSON key-value pair and returns a JsonPair object.
 // It uses the Seq combinator to parse the key (a string), the colon separator, and the value, and then the Fmap combinator to transform the result.
-func JPair() parser.Parser[JsonPair] {
+func JPair(opts ...Option) parser.Parser[JsonPair] {
+	c := newConfig(opts)
+	return jPair(c, jKey(c), jVal(c))
+}
+
+// jPair parses a JSON key-value pair according to the configuration c,
+// whose key is parsed by key and whose value is parsed by val.
+func jPair(c *config, key, val parser.Parser[Json]) parser.Parser[JsonPair] {
 	return parser.Fmap(
 		parser.Seq(
-			JString(),
-			parser.Trim(
+			key,
+			trim(c,
 				parser.Fmap(
 					parser.Char(':'),
 					func(r rune) Json {
 						return JsonString{Val: ":"}
 					})),
-			JVal()),
+			val),
 		func(tuple []Json) JsonPair {
 			return JsonPair{
 				Key:   tuple[0].(JsonString).Val,
@@ -107,23 +269,85 @@ func JPair() parser.Parser[JsonPair] {
 
 // JObject parses a JSON object value and returns a JsonObject object.
 // It uses the Between combinator to parse the object enclosed in curly braces, and the SepBy combinator to parse the key-value pairs separated by commas.
-func JObject() parser.Parser[Json] {
-	return parser.Fmap(
-		parser.Between(
-			parser.Trim(parser.Char('{')),
-			parser.SepBy(JPair(), parser.Trim(parser.Char(','))),
-			parser.Trim(parser.Char('}')),
-		),
-		func(pairs []JsonPair) Json {
-			obj := make(map[string]Json)
-			for _, pair := range pairs {
-				obj[pair.Key] = pair.Value
+// Each object counts as one level of nesting towards the MaxDepth limit.
+func JObject(opts ...Option) parser.Parser[Json] {
+	c := newConfig(opts)
+	return jObject(c, jVal(c))
+}
+
+// jObject parses a JSON object according to the configuration c, whose member values are parsed by val.
+// Duplicate keys are handled according to the duplicate key policy of c.
+func jObject(c *config, val parser.Parser[Json]) parser.Parser[Json] {
+	object := func(key parser.Parser[Json]) parser.Parser[Json] {
+		return parser.Fmap(
+			parser.Nested(parser.Between(
+				trim(c, parser.Char('{')),
+				list(c, jPair(c, key, val), trim(c, parser.Char(','))),
+				trim(c, parser.Char('}')),
+			)),
+			func(pairs []JsonPair) Json {
+				return buildObject(c.duplicates, pairs)
+			},
+		)
+	}
+	if c.duplicates != DuplicateError {
+		return object(jKey(c))
+	}
+	return perObject(func(unique func(string) bool) parser.Parser[Json] {
+		return object(trim(c, parser.Fmap(
+			parser.SatisfyWithMsg(c.key(), unique, "unique key"),
+			func(s string) Json {
+				return JsonString{Val: s}
+			})))
+	})
+}
+
+// perObject builds a fresh parser with f for every object it parses. The unique function passed to f
+// reports whether a key has not been seen before in the current object, and records it.
+func perObject[T any](f func(unique func(string) bool) parser.Parser[T]) parser.Parser[T] {
+	return parser.Bind(parser.Pure(struct{}{}), func(struct{}) parser.Parser[T] {
+		seen := make(map[string]bool)
+		return f(func(key string) bool {
+			if seen[key] {
+				return false
 			}
-			return JsonObject{Val: obj}
-		},
-	)
+			seen[key] = true
+			return true
+		})
+	})
+}
+
+// ParseJSON parses a complete JSON document.
+// If the document is malformed, the error is a *SyntaxError describing what was expected and where.
+// If it exceeds a MaxDepth or MaxBytes limit, the error is a *parser.LimitError.
+func ParseJSON(jsonStr string, opts ...Option) (Json, error) {
+	c := newConfig(opts)
+	if c.fast {
+		if v, ok := parseFast(jsonStr, c); ok {
+			return v, nil
+		}
+	}
+	v, err := parser.RunWith(jVal(c), jsonStr, c.limits)
+	return v, syntaxError(err)
+}
+
+// ParseJSONWith parses a complete JSON document like ParseJSON, configured by the settings in opts.
+func ParseJSONWith(s string, opts Options) (Json, error) {
+	return ParseJSON(s, WithOptions(opts))
 }
 
-func ParseJSON(jsonStr string) parser.ParserFuncRet[Json] {
-	return JVal().Parse(jsonStr)
+// ParseJSONReader parses a complete JSON document read from r, like ParseJSON but without
+// requiring the caller to read the whole input into a string first. The input is consumed
+// through a Scanner, so only the resulting value and a small buffer are held in memory.
+// Errors reading r are returned as is.
+func ParseJSONReader(r io.Reader, opts ...Option) (Json, error) {
+	s := NewScanner(r, opts...)
+	v, err := s.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Next(); err != io.EOF {
+		return nil, err
+	}
+	return v, nil
 }
@@ -1,91 +1,589 @@
 package json_test
 
 import (
+	"math"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestParseString(t *testing.T) {
 	t.Run("valid string", func(t *testing.T) {
-		result := json.ParseJSON(`"hello"`)
-		assert.Equal(t, "hello", result.Get().First.(json.JsonString).Val)
+		result, err := json.ParseJSON(`"hello"`)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", result.(json.JsonString).Val)
 	})
 
 	t.Run("invalid string", func(t *testing.T) {
-		result := json.ParseJSON(`"unclosed`)
-		assert.True(t, result.IsNothing())
+		_, err := json.ParseJSON(`"unclosed`)
+		assert.ErrorIs(t, err, parser.ErrUnexpectedEOF)
 	})
 }
 
 func TestParseArray(t *testing.T) {
 	t.Run("nested arrays", func(t *testing.T) {
-		result := json.ParseJSON(`[1, [true, null], "text"]`)
-		assert.Len(t, result.Get().First.(json.JsonArray).Val, 3)
+		result, err := json.ParseJSON(`[1, [true, null], "text"]`)
+		assert.NoError(t, err)
+		assert.Len(t, result.(json.JsonArray).Val, 3)
 	})
 
 	t.Run("empty array", func(t *testing.T) {
-		result := json.ParseJSON(`[]`)
-		assert.Len(t, result.Get().First.(json.JsonArray).Val, 0)
+		result, err := json.ParseJSON(`[]`)
+		assert.NoError(t, err)
+		assert.Len(t, result.(json.JsonArray).Val, 0)
 	})
 }
 
 func TestParseObject(t *testing.T) {
 	t.Run("complex object", func(t *testing.T) {
-		result := json.ParseJSON(`{
+		result, err := json.ParseJSON(`{
 			"num": 42,
 			"arr": [{"k": "v"}],
 			"bool": false
 		}`)
-		m := result.Get().First.(json.JsonObject).Val
+		assert.NoError(t, err)
+		m := result.(json.JsonObject).Val
 		assert.Equal(t, int64(42), m["num"].(json.JsonInt).Val)
 		assert.Len(t, m["arr"].(json.JsonArray).Val, 1)
 		assert.False(t, m["bool"].(json.JsonBool).Val)
 	})
 
 	t.Run("missing comma", func(t *testing.T) {
-		result := json.ParseJSON(`{"a":1 "b":2}`)
-		assert.True(t, result.IsNothing())
+		_, err := json.ParseJSON(`{"a":1 "b":2}`)
+		assert.Error(t, err)
 	})
 }
 
 func TestParseBoolean(t *testing.T) {
 	t.Run("true value", func(t *testing.T) {
-		result := json.ParseJSON(`true`)
-		assert.True(t, result.Get().First.(json.JsonBool).Val)
+		result, err := json.ParseJSON(`true`)
+		assert.NoError(t, err)
+		assert.True(t, result.(json.JsonBool).Val)
 	})
 
 	t.Run("false value", func(t *testing.T) {
-		result := json.ParseJSON(`false`)
-		assert.False(t, result.Get().First.(json.JsonBool).Val)
+		result, err := json.ParseJSON(`false`)
+		assert.NoError(t, err)
+		assert.False(t, result.(json.JsonBool).Val)
 	})
 }
 func TestParseInteger(t *testing.T) {
 	t.Run("positive integer", func(t *testing.T) {
-		result := json.ParseJSON(`42`)
-		assert.Equal(t, int64(42), result.Get().First.(json.JsonInt).Val)
+		result, err := json.ParseJSON(`42`)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), result.(json.JsonInt).Val)
 	})
 
 	t.Run("negative integer", func(t *testing.T) {
-		result := json.ParseJSON(`-42`)
-		assert.Equal(t, int64(-42), result.Get().First.(json.JsonInt).Val)
+		result, err := json.ParseJSON(`-42`)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-42), result.(json.JsonInt).Val)
 	})
 }
 func TestParseFloat(t *testing.T) {
 	t.Run("positive float", func(t *testing.T) {
-		result := json.ParseJSON(`3.14`)
-		assert.Equal(t, float64(3.14), result.Get().First.(json.JsonFloat).Val)
+		result, err := json.ParseJSON(`3.14`)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3.14), result.(json.JsonFloat).Val)
 	})
 
 	t.Run("negative float", func(t *testing.T) {
-		result := json.ParseJSON(`-3.14`)
-		assert.Equal(t, float64(-3.14), result.Get().First.(json.JsonFloat).Val)
+		result, err := json.ParseJSON(`-3.14`)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(-3.14), result.(json.JsonFloat).Val)
 	})
 }
 func TestParseNull(t *testing.T) {
 	t.Run("null value", func(t *testing.T) {
-		result := json.ParseJSON(`null`)
-		assert.True(t, result.Get().First.(json.JsonNull).IsNil())
+		result, err := json.ParseJSON(`null`)
+		assert.NoError(t, err)
+		assert.True(t, result.(json.JsonNull).IsNil())
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	t.Run("depth within limit", func(t *testing.T) {
+		result, err := json.JVal().ParseWith(`{"a": [[1], {"b": 2}]}`, parser.Options{MaxDepth: 3})
+		assert.NoError(t, err)
+		assert.True(t, result.IsJust())
+	})
+
+	t.Run("deeply nested arrays", func(t *testing.T) {
+		input := strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+		result, err := json.JVal().ParseWith(input, parser.Options{MaxDepth: 64})
+		assert.True(t, result.IsNothing())
+		assert.ErrorContains(t, err, "maximum nesting depth of 64")
+	})
+
+	t.Run("ParseJSON depth option", func(t *testing.T) {
+		_, err := json.ParseJSON(`{"a": [[1]]}`, json.MaxDepth(3))
+		assert.NoError(t, err)
+
+		_, err = json.ParseJSON(strings.Repeat("[", 100)+strings.Repeat("]", 100), json.MaxDepth(16))
+		var lerr *parser.LimitError
+		assert.ErrorAs(t, err, &lerr)
+		assert.Equal(t, parser.LimitDepth, lerr.Limit)
+		assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	})
+
+	t.Run("ParseJSON size option", func(t *testing.T) {
+		_, err := json.ParseJSON(`[1, 2, 3]`, json.MaxBytes(9))
+		assert.NoError(t, err)
+
+		_, err = json.ParseJSON(`[1, 2, 3, 4, 5]`, json.MaxBytes(9))
+		assert.ErrorContains(t, err, "maximum input size of 9 bytes")
+	})
+
+	t.Run("Unmarshal honours limits", func(t *testing.T) {
+		var v any
+		err := json.Unmarshal(`[[[1]]]`, &v, json.MaxDepth(2))
+		assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	})
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  json.Json
+	}{
+		{"zero", `0`, json.JsonInt{Val: 0}},
+		{"negative zero", `-0`, json.JsonInt{Val: 0}},
+		{"exponent", `1e10`, json.JsonFloat{Val: 1e10}},
+		{"signed exponent", `2.5E-3`, json.JsonFloat{Val: 2.5e-3}},
+		{"explicit positive exponent", `-1e+2`, json.JsonFloat{Val: -100}},
+		{"beyond int64", `9223372036854775808`, json.JsonFloat{Val: 9223372036854775808}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := parser.Run(json.JVal(), tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+
+	for _, input := range []string{`01`, `1.`, `.5`, `1e`, `+1`, `-`} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, err := parser.Run(json.JVal(), input)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		for _, opts := range [][]json.Option{nil, {json.FastPath()}} {
+			_, err := json.ParseJSON(`[1, -1e400]`, opts...)
+			assert.EqualError(t, err, "json: line 1, col 5: expected number within the range of float64")
+		}
+		v, err := json.ParseJSON(`1e-400`)
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonFloat{Val: 0}, v)
+	})
+
+	t.Run("numbers in arrays", func(t *testing.T) {
+		v, err := parser.Run(json.JVal(), `[0, -1.5e2, 10]`)
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonArray{Val: []json.Json{json.JsonInt{Val: 0}, json.JsonFloat{Val: -150}, json.JsonInt{Val: 10}}}, v)
+	})
+}
+
+func TestPreciseNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  json.Json
+	}{
+		{"small integer", `42`, json.JsonInt{Val: 42}},
+		{"exact float", `0.1`, json.JsonFloat{Val: 0.1}},
+		{"big integer", `12345678901234567890`, json.JsonNumber{Val: "12345678901234567890"}},
+		{"long decimal", `3.141592653589793238462643`, json.JsonNumber{Val: "3.141592653589793238462643"}},
+		{"overflow", `1e400`, json.JsonNumber{Val: "1e400"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := json.ParseJSON(tt.input, json.PreciseNumbers())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		v, err := json.ParseJSON(`[12345678901234567890,1.00000000000000000001]`, json.PreciseNumbers())
+		assert.NoError(t, err)
+		assert.Equal(t, `[12345678901234567890,1.00000000000000000001]`, v.String())
+	})
+
+	t.Run("accessors", func(t *testing.T) {
+		n := json.JsonNumber{Val: "12345678901234567890"}
+		b, err := n.AsBigInt()
+		assert.NoError(t, err)
+		assert.Equal(t, "12345678901234567890", b.String())
+		_, err = n.AsInt64()
+		assert.Error(t, err)
+		f, err := n.AsFloat64()
+		assert.NoError(t, err)
+		assert.Equal(t, 1.2345678901234567e19, f)
+
+		i, err := json.JsonNumber{Val: "1.5e3"}.AsInt64()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1500), i)
+		_, err = json.JsonNumber{Val: "1.5"}.AsBigInt()
+		assert.Error(t, err)
+	})
+
+	t.Run("default keeps floats", func(t *testing.T) {
+		v, err := json.ParseJSON(`12345678901234567890`)
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonFloat{Val: 12345678901234567890}, v)
+	})
+}
+
+func TestUseNumber(t *testing.T) {
+	src := `{"i": 1, "f": 1.0, "e": -2.5E+3, "big": 12345678901234567890}`
+	want := map[string]json.Json{
+		"i":   json.JsonNumber{Val: "1"},
+		"f":   json.JsonNumber{Val: "1.0"},
+		"e":   json.JsonNumber{Val: "-2.5E+3"},
+		"big": json.JsonNumber{Val: "12345678901234567890"},
+	}
+	for name, opts := range map[string][]json.Option{
+		"combinators": {json.UseNumber()},
+		"fast path":   {json.UseNumber(), json.FastPath()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v, err := json.ParseJSON(src, opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, want, v.(json.JsonObject).Val)
+			assert.Equal(t, `{"i":1,"f":1.0,"e":-2.5E+3,"big":12345678901234567890}`, v.String())
+		})
+	}
+
+	t.Run("scanner", func(t *testing.T) {
+		v, err := json.ParseJSONReader(strings.NewReader(src), json.UseNumber())
+		assert.NoError(t, err)
+		assert.Equal(t, want, v.(json.JsonObject).Val)
+	})
+
+	t.Run("JSON5 forms", func(t *testing.T) {
+		v, err := json.ParseJSON(`[0x1F, .5, +5., -Infinity]`, json.JSON5(), json.UseNumber())
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonArray{Val: []json.Json{
+			json.JsonNumber{Val: "31"}, json.JsonNumber{Val: "0.5"}, json.JsonNumber{Val: "5"}, json.JsonFloat{Val: math.Inf(-1)},
+		}}, v)
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var v struct {
+			I int     `json:"i"`
+			F float64 `json:"f"`
+			E int     `json:"e"`
+		}
+		assert.NoError(t, json.Unmarshal(src, &v, json.UseNumber()))
+		assert.Equal(t, 1, v.I)
+		assert.Equal(t, 1.0, v.F)
+		assert.Equal(t, -2500, v.E)
+	})
+}
+
+func TestTrailingCommas(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"array", `[1, 2,]`, `[1,2]`},
+		{"object", `{"a": 1, "b": 2 , }`, `{"a":1,"b":2}`},
+		{"nested", `{"a": [true,],}`, `{"a":[true]}`},
+		{"without trailing comma", `[1, 2]`, `[1,2]`},
+		{"empty", `[ ]`, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := json.ParseJSON(tt.input, json.TrailingCommas())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v.String())
+		})
+	}
+
+	for _, input := range []string{`[,]`, `{,}`, `[1,,]`, `[1 2]`} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, err := json.ParseJSON(input, json.TrailingCommas())
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("strict by default", func(t *testing.T) {
+		_, err := json.ParseJSON(`[1, 2,]`)
+		assert.EqualError(t, err, "json: line 1, col 7: expected value")
+	})
+}
+
+func TestAllowNaN(t *testing.T) {
+	v, err := json.ParseJSON(`[NaN, Infinity, -Infinity, 1.5]`, json.AllowNaN())
+	assert.NoError(t, err)
+	vals := v.(json.JsonArray).Val
+	assert.True(t, math.IsNaN(vals[0].(json.JsonFloat).Val))
+	assert.Equal(t, json.JsonFloat{Val: math.Inf(1)}, vals[1])
+	assert.Equal(t, json.JsonFloat{Val: math.Inf(-1)}, vals[2])
+	assert.Equal(t, json.JsonFloat{Val: 1.5}, vals[3])
+
+	for _, input := range []string{`-NaN`, `+Infinity`, `nan`, `Inf`} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			_, err := json.ParseJSON(input, json.AllowNaN())
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		_, err := json.ParseJSON(`NaN`)
+		assert.Error(t, err)
+	})
+
+	t.Run("precise numbers", func(t *testing.T) {
+		v, err := json.ParseJSON(`Infinity`, json.AllowNaN(), json.PreciseNumbers())
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonFloat{Val: math.Inf(1)}, v)
+	})
+}
+
+func TestParseJSONReader(t *testing.T) {
+	doc := `{"name": "tiny", "list": [1, 2.5, null], "ok": true}`
+	want, err := json.ParseJSON(doc)
+	assert.NoError(t, err)
+	v, err := json.ParseJSONReader(iotest.OneByteReader(strings.NewReader(doc)))
+	assert.NoError(t, err)
+	assert.Equal(t, want, v)
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := json.ParseJSONReader(strings.NewReader("[1,\n 2 3]"))
+		assert.EqualError(t, err, "json: line 2, col 4: expected ',' or ']' after array element")
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		_, err := json.ParseJSONReader(strings.NewReader(`{} x`))
+		assert.EqualError(t, err, "json: line 1, col 4: expected end of input")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		v, err := json.ParseJSONReader(strings.NewReader(`{a: 0x10,}`), json.JSON5())
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":16}`, v.String())
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		_, err := json.ParseJSONReader(iotest.TimeoutReader(strings.NewReader(`[1, `)))
+		assert.ErrorIs(t, err, iotest.ErrTimeout)
+	})
+}
+
+func TestParseEscapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"simple escapes", `"a\"b\\c\/d"`, `a"b\c/d`},
+		{"control escapes", `"\b\f\n\r\t"`, "\b\f\n\r\t"},
+		{"unicode escape", `"\u0041\u00e9"`, "Aé"},
+		{"surrogate pair", `"\ud83d\ude00"`, "😀"},
+		{"unpaired surrogate", `"\ud83dx"`, "\uFFFDx"},
+		{"raw utf-8", `"héllo"`, "héllo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := parser.Run(json.JVal(), tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, json.JsonString{Val: tt.want}, v)
+		})
+	}
+
+	t.Run("invalid escape", func(t *testing.T) {
+		_, err := parser.Run(json.JVal(), `"a\x"`)
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.ErrorContains(t, err, "expected escape sequence")
+	})
+
+	t.Run("truncated unicode escape", func(t *testing.T) {
+		_, err := parser.Run(json.JVal(), `"\u00`)
+		assert.ErrorIs(t, err, parser.ErrUnexpectedEOF)
+	})
+
+	t.Run("escaped key", func(t *testing.T) {
+		v, err := parser.Run(json.JVal(), `{"\u006b": 1}`)
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonInt{Val: 1}, v.(json.JsonObject).Val["k"])
+	})
+}
+
+func TestStrictStrings(t *testing.T) {
+	t.Run("raw control characters are accepted by default", func(t *testing.T) {
+		v, err := parser.Run(json.JVal(), "\"a\tb\"")
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonString{Val: "a\tb"}, v)
+	})
+
+	t.Run("raw control characters are rejected", func(t *testing.T) {
+		_, err := parser.Run(json.JVal(json.StrictStrings()), "[\"ok\", \"a\nb\"]")
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+		assert.ErrorContains(t, err, "expected escaped control character")
+	})
+
+	t.Run("escaped control characters are accepted", func(t *testing.T) {
+		v, err := parser.Run(json.JVal(json.StrictStrings()), `{"k": "a\nb"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonString{Val: "a\nb"}, v.(json.JsonObject).Val["k"])
+	})
+
+	t.Run("applies to keys", func(t *testing.T) {
+		_, err := json.ParseJSON("{\"a\x01\": 1}", json.StrictStrings())
+		assert.Error(t, err)
+	})
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing comma in object", "{\n  \"a\": 1\n  \"b\": 2}", "json: line 3, col 3: expected ',' or '}' after object member"},
+		{"missing comma in array", `[1 2]`, "json: line 1, col 4: expected ',' or ']' after array element"},
+		{"missing colon", `{"a" 1}`, "json: line 1, col 6: expected ':' after object key"},
+		{"missing value", `{"a": }`, "json: line 1, col 7: expected value"},
+		{"trailing comma", `{"a": 1,}`, "json: line 1, col 9: expected object key"},
+		{"unterminated array", `[`, "json: line 1, col 2: unexpected end of input, expected value or ']'"},
+		{"empty document", ``, "json: line 1, col 1: unexpected end of input, expected value"},
+		{"trailing data", `[1] x`, "json: line 1, col 5: expected end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := json.ParseJSON(tt.input)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	t.Run("structured fields", func(t *testing.T) {
+		_, err := json.ParseJSON(`[1, x]`)
+		var serr *json.SyntaxError
+		assert.ErrorAs(t, err, &serr)
+		assert.Equal(t, 4, serr.Offset)
+		assert.Equal(t, "'x'", serr.Found)
+		assert.ErrorIs(t, err, parser.ErrNoMatch)
+	})
+}
+
+func TestObjectOrder(t *testing.T) {
+	t.Run("members keep document order", func(t *testing.T) {
+		v, err := json.ParseJSON(`{"z": 1, "a": 2, "m": 3}`)
+		assert.NoError(t, err)
+		obj := v.(json.JsonObject)
+		assert.Equal(t, []string{"z", "a", "m"}, obj.OrderedKeys())
+		assert.Equal(t, `{"z":1,"a":2,"m":3}`, obj.String())
+	})
+
+	t.Run("duplicate keys keep first position", func(t *testing.T) {
+		v, err := json.ParseJSON(`{"a": 1, "b": 2, "a": 3}`)
+		assert.NoError(t, err)
+		obj := v.(json.JsonObject)
+		assert.Equal(t, []json.JsonPair{{Key: "a", Value: json.JsonInt{Val: 3}}, {Key: "b", Value: json.JsonInt{Val: 2}}}, obj.Members())
+	})
+
+	t.Run("lookup and set", func(t *testing.T) {
+		obj := json.NewObject(json.JsonPair{Key: "x", Value: json.JsonNull{}})
+		obj.Set("y", json.JsonBool{Val: true})
+		obj.Set("x", json.JsonInt{Val: 1})
+		v, ok := obj.Get("x")
+		assert.True(t, ok)
+		assert.Equal(t, json.JsonInt{Val: 1}, v)
+		_, ok = obj.Get("z")
+		assert.False(t, ok)
+		assert.Equal(t, 2, obj.Len())
+		assert.Equal(t, `{"x":1,"y":true}`, obj.String())
+	})
+
+	t.Run("objects without keys are sorted", func(t *testing.T) {
+		obj := json.JsonObject{Val: map[string]json.Json{"b": json.JsonNull{}, "a": json.JsonNull{}}}
+		assert.Equal(t, []string{"a", "b"}, obj.OrderedKeys())
+	})
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	const src = `{"a": 1, "b": {"a": 0}, "a": 2, "a": 3}`
+	tests := []struct {
+		name   string
+		policy json.DuplicatePolicy
+		want   json.Json
+	}{
+		{"keep last", json.DuplicateKeepLast, json.JsonInt{Val: 3}},
+		{"keep first", json.DuplicateKeepFirst, json.JsonInt{Val: 1}},
+		{"collect", json.DuplicateCollect, json.JsonArray{Val: []json.Json{json.JsonInt{Val: 1}, json.JsonInt{Val: 2}, json.JsonInt{Val: 3}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := json.ParseJSON(src, json.OnDuplicateKey(tt.policy))
+			assert.NoError(t, err)
+			obj := v.(json.JsonObject)
+			assert.Equal(t, tt.want, obj.Val["a"])
+			assert.Equal(t, []string{"a", "b"}, obj.OrderedKeys())
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := json.ParseJSON(src, json.OnDuplicateKey(json.DuplicateError))
+		assert.EqualError(t, err, "json: line 1, col 25: expected unique key")
+	})
+
+	t.Run("error allows same key in nested objects", func(t *testing.T) {
+		_, err := json.ParseJSON(`{"a": {"a": {"a": 1}}, "b": [{"a": 1}, {"a": 2}]}`, json.OnDuplicateKey(json.DuplicateError))
+		assert.NoError(t, err)
+	})
+
+	t.Run("error with positions", func(t *testing.T) {
+		_, err := json.ParseNode("{\n  \"a\": 1,\n  \"a\": 2\n}", json.OnDuplicateKey(json.DuplicateError))
+		assert.EqualError(t, err, "json: line 3, col 3: expected unique key")
+	})
+}
+
+func TestParseJSONWith(t *testing.T) {
+	t.Run("matches functional options", func(t *testing.T) {
+		src := "{\n  // comment\n  \"a\": [1, 2,],\n  \"a\": 12345678901234567890,\n}"
+		want, err := json.ParseJSON(src, json.Comments(), json.TrailingCommas(), json.PreciseNumbers(), json.OnDuplicateKey(json.DuplicateCollect))
+		assert.NoError(t, err)
+		got, err := json.ParseJSONWith(src, json.Options{
+			Comments:       true,
+			TrailingCommas: true,
+			PreciseNumbers: true,
+			Duplicates:     json.DuplicateCollect,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("zero value is the default grammar", func(t *testing.T) {
+		_, err := json.ParseJSONWith(`[1,]`, json.Options{})
+		assert.EqualError(t, err, "json: line 1, col 4: expected value")
+	})
+
+	t.Run("JSON5 implies its extensions", func(t *testing.T) {
+		v, err := json.ParseJSONWith(`{a: [NaN, 0x10,], /* c */}`, json.Options{JSON5: true})
+		assert.NoError(t, err)
+		assert.Equal(t, json.JsonInt{Val: 16}, v.(json.JsonObject).Val["a"].(json.JsonArray).Val[1])
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		_, err := json.ParseJSONWith(`[[1]]`, json.Options{MaxDepth: 1})
+		assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+		_, err = json.ParseJSONWith(`[1, 2]`, json.Options{MaxBytes: 3, StrictStrings: true})
+		assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	})
+
+	t.Run("replaces earlier options", func(t *testing.T) {
+		_, err := json.ParseNode(`[1,]`, json.TrailingCommas(), json.WithOptions(json.Options{}))
+		assert.Error(t, err)
+		_, err = json.ParseNode(`[1,]`, json.WithOptions(json.Options{}), json.TrailingCommas())
+		assert.NoError(t, err)
 	})
 }
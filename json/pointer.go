@@ -0,0 +1,82 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Pointer resolves the JSON Pointer ptr, e.g. "/users/0/name", against j.
+// Reference tokens are unescaped per RFC 6901 ("~1" is '/', "~0" is '~'); array indexes
+// must be decimal numbers without leading zeros. The empty pointer refers to j itself.
+// It returns Nothing if ptr is malformed or refers to a value that does not exist.
+func Pointer(j Json, ptr string) parser.Maybe[Json] {
+	if ptr == "" {
+		return parser.Just(j)
+	}
+	if ptr[0] != '/' {
+		return parser.Nothing[Json]()
+	}
+	for _, tok := range strings.Split(ptr[1:], "/") {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(tok, "~0", ""), "~1", ""), "~") {
+			return parser.Nothing[Json]()
+		}
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch v := j.(type) {
+		case JsonObject:
+			m, ok := v.Val[tok]
+			if !ok {
+				return parser.Nothing[Json]()
+			}
+			j = m
+		case JsonArray:
+			i, ok := arrayIndex(tok)
+			if !ok || i >= len(v.Val) {
+				return parser.Nothing[Json]()
+			}
+			j = v.Val[i]
+		default:
+			return parser.Nothing[Json]()
+		}
+	}
+	return parser.Just(j)
+}
+
+// arrayIndex parses an array index reference token.
+func arrayIndex(tok string) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	for _, c := range []byte(tok) {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(tok)
+	return i, err == nil
+}
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonNull) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonBool) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonInt) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonFloat) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonNumber) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the value; see the Pointer function.
+func (j JsonString) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the array; see the Pointer function.
+func (j JsonArray) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
+
+// Pointer resolves the JSON Pointer ptr against the object; see the Pointer function.
+func (j JsonObject) Pointer(ptr string) parser.Maybe[Json] { return Pointer(j, ptr) }
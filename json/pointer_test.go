@@ -0,0 +1,61 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointer(t *testing.T) {
+	// The example document of RFC 6901, section 5.
+	doc, err := json.ParseJSON(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8,
+		"users": [{"name": "ada"}]
+	}`)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ptr  string
+		want json.Json
+	}{
+		{"/foo", json.JsonArray{Val: []json.Json{json.JsonString{Val: "bar"}, json.JsonString{Val: "baz"}}}},
+		{"/foo/0", json.JsonString{Val: "bar"}},
+		{"/", json.JsonInt{Val: 0}},
+		{"/a~1b", json.JsonInt{Val: 1}},
+		{"/c%d", json.JsonInt{Val: 2}},
+		{"/e^f", json.JsonInt{Val: 3}},
+		{"/g|h", json.JsonInt{Val: 4}},
+		{"/i\\j", json.JsonInt{Val: 5}},
+		{"/k\"l", json.JsonInt{Val: 6}},
+		{"/ ", json.JsonInt{Val: 7}},
+		{"/m~0n", json.JsonInt{Val: 8}},
+		{"/users/0/name", json.JsonString{Val: "ada"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ptr, func(t *testing.T) {
+			m := doc.Pointer(tt.ptr)
+			assert.True(t, m.IsJust())
+			assert.Equal(t, tt.want, m.Get())
+		})
+	}
+
+	t.Run("whole document", func(t *testing.T) {
+		assert.Equal(t, doc, doc.Pointer("").Get())
+	})
+
+	for _, ptr := range []string{"foo", "/missing", "/foo/2", "/foo/01", "/foo/-", "/foo/0/x", "/m~2n"} {
+		t.Run("unresolved "+ptr, func(t *testing.T) {
+			assert.True(t, json.Pointer(doc, ptr).IsNothing())
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// EventKind is the kind of an Event reported by a Scanner.
+type EventKind int
+
+const (
+	// ObjectStart is reported for the opening brace of an object.
+	ObjectStart EventKind = iota
+	// ObjectEnd is reported for the closing brace of an object.
+	ObjectEnd
+	// ArrayStart is reported for the opening bracket of an array.
+	ArrayStart
+	// ArrayEnd is reported for the closing bracket of an array.
+	ArrayEnd
+	// Key is reported for the key of an object member, before its value.
+	Key
+	// Value is reported for a string, number, boolean or null value.
+	Value
+)
+
+// String returns the name of the event kind, e.g. "ObjectStart".
+func (k EventKind) String() string {
+	switch k {
+	case ObjectStart:
+		return "ObjectStart"
+	case ObjectEnd:
+		return "ObjectEnd"
+	case ArrayStart:
+		return "ArrayStart"
+	case ArrayEnd:
+		return "ArrayEnd"
+	case Key:
+		return "Key"
+	case Value:
+		return "Value"
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Event is a syntactic element of a JSON document reported by a Scanner.
+type Event struct {
+	// Kind is the kind of the event.
+	Kind EventKind
+	// Key is the decoded member key of a Key event.
+	Key string
+	// Value is the scalar value of a Value event.
+	Value Json
+	// Offset is the byte offset of the element in the document.
+	Offset int
+}
+
+// scanState is the position of a Scanner in the grammar, which decides the tokens allowed next.
+type scanState int
+
+const (
+	// scanValue expects a value: at the start of the document or after an object key.
+	scanValue scanState = iota
+	// scanObjectStart expects the first key of an object or its closing brace.
+	scanObjectStart
+	// scanObjectNext expects a comma and the next key, or the closing brace.
+	scanObjectNext
+	// scanArrayStart expects the first element of an array or its closing bracket.
+	scanArrayStart
+	// scanArrayNext expects a comma and the next element, or the closing bracket.
+	scanArrayNext
+	// scanEnd expects the end of the document.
+	scanEnd
+	// scanDone is reached once the end of the document has been reported.
+	scanDone
+)
+
+// endOfDocument marks the token that matches the end of the document.
+const endOfDocument EventKind = -1
+
+// Scanner reads a JSON document from an io.Reader and reports it as a sequence of events,
+// without building the values of arrays and objects. Memory use is bounded by the nesting
+// depth and the size of the largest scalar, so huge documents can be processed, or a few
+// fields extracted, without materializing the whole document.
+//
+// The options select the grammar as for ParseJSON. Repeated keys are reported as errors
+// under the DuplicateError policy; the other policies apply to the objects built by Decode.
+type Scanner struct {
+	r      io.Reader
+	c      *config
+	tokens [scanDone]parser.Parser[Event]
+	// buf holds the input read but not yet consumed.
+	buf string
+	// eof is set once r has reported the end of its input.
+	eof bool
+	// offset, line and col locate the start of buf in the document.
+	offset, line, col int
+	// stack holds ObjectStart or ArrayStart for every open object or array.
+	stack []EventKind
+	// keys holds the keys seen in every open object under the DuplicateError policy.
+	keys  []map[string]bool
+	state scanState
+	err   error
+	// space skips the whitespace before the next token.
+	space parser.Parser[struct{}]
+}
+
+// NewScanner returns a Scanner reading a JSON document from r.
+func NewScanner(r io.Reader, opts ...Option) *Scanner {
+	return newScanner(r, false, opts)
+}
+
+// NewStreamScanner returns a Scanner reading a stream of JSON values from r, such as
+// concatenated documents or JSON Lines. The values may be separated by whitespace.
+// Next reports the events of one value after the other and returns io.EOF at the end
+// of the input after a complete value, or right away if the input holds no value.
+func NewStreamScanner(r io.Reader, opts ...Option) *Scanner {
+	return newScanner(r, true, opts)
+}
+
+// newScanner returns a Scanner reading a single document, or a stream of values if stream is set.
+func newScanner(r io.Reader, stream bool, opts []Option) *Scanner {
+	c := newConfig(opts)
+	s := &Scanner{r: r, c: c, line: 1, col: 1, space: trimLeft(c, parser.Pure(struct{}{}))}
+	ev := func(kind EventKind) func(rune) Event {
+		return func(rune) Event { return Event{Kind: kind} }
+	}
+	// at records the offset of the token parsed by p in its event.
+	at := func(p parser.Parser[Event]) parser.Parser[Event] {
+		return spanned(p, func(e Event, sp Span) Event {
+			e.Offset = sp.Start.Offset
+			return e
+		})
+	}
+	value := at(parser.OrElse(
+		parser.Fmap(parser.Char('{'), ev(ObjectStart)),
+		parser.Fmap(parser.Char('['), ev(ArrayStart)),
+		parser.Fmap(c.str(), func(str string) Event { return Event{Kind: Value, Value: JsonString{Val: str}} }),
+		parser.Fmap(parser.OrElse(jNumber(c), jBool(c), jNull(c)), func(j Json) Event { return Event{Kind: Value, Value: j} }),
+	))
+	keyLit := c.key()
+	if c.duplicates == DuplicateError {
+		keyLit = parser.SatisfyWithMsg(keyLit, s.unique, "unique key")
+	}
+	key := parser.OmitRight(at(parser.Fmap(keyLit, func(k string) Event {
+		return Event{Kind: Key, Key: k}
+	})), trimLeft(c, parser.Char(':')))
+	objectEnd := at(parser.Fmap(parser.Char('}'), ev(ObjectEnd)))
+	arrayEnd := at(parser.Fmap(parser.Char(']'), ev(ArrayEnd)))
+	nextKey, nextValue := key, value
+	if c.trailingCommas {
+		nextKey = parser.OrElse(key, objectEnd)
+		nextValue = parser.OrElse(value, arrayEnd)
+	}
+	comma := trimLeft(c, parser.Char(','))
+	end := parser.Fmap(parser.EOF(), func(struct{}) Event { return Event{Kind: endOfDocument} })
+	tokens := [scanDone]parser.Parser[Event]{
+		scanValue:       value,
+		scanObjectStart: parser.OrElse(objectEnd, key),
+		scanObjectNext:  parser.OrElse(objectEnd, parser.OmitLeft(comma, trimLeft(c, nextKey))),
+		scanArrayStart:  parser.OrElse(arrayEnd, value),
+		scanArrayNext:   parser.OrElse(arrayEnd, parser.OmitLeft(comma, trimLeft(c, nextValue))),
+		scanEnd:         end,
+	}
+	if stream {
+		// A stream starts, and continues after every value, where either the input ends or the next value starts.
+		tokens[scanEnd] = parser.OrElse(end, value)
+		s.state = scanEnd
+	}
+	for i, p := range tokens {
+		s.tokens[i] = trimLeft(c, p)
+	}
+	return s
+}
+
+// Next returns the next event of the document. It returns io.EOF after the last event of
+// a well-formed document. A malformed document fails with a *SyntaxError, and exceeding
+// a MaxDepth or MaxBytes limit with a *parser.LimitError. Errors are final.
+func (s *Scanner) Next() (Event, error) {
+	if s.err != nil {
+		return Event{}, s.err
+	}
+	if s.state == scanDone {
+		return Event{}, io.EOF
+	}
+	e, err := s.token()
+	if err != nil {
+		s.err = err
+		return Event{}, err
+	}
+	if max := s.c.limits.MaxDepth; max > 0 && len(s.stack) >= max && (e.Kind == ObjectStart || e.Kind == ArrayStart) {
+		s.err = &parser.LimitError{Limit: parser.LimitDepth, Max: max, Offset: e.Offset}
+		return Event{}, s.err
+	}
+	if s.c.duplicates == DuplicateError {
+		switch e.Kind {
+		case ObjectStart:
+			s.keys = append(s.keys, make(map[string]bool))
+		case ObjectEnd:
+			s.keys = s.keys[:len(s.keys)-1]
+		case Key:
+			s.keys[len(s.keys)-1][e.Key] = true
+		}
+	}
+	switch e.Kind {
+	case ObjectStart, ArrayStart:
+		s.stack = append(s.stack, e.Kind)
+		s.state = scanObjectStart
+		if e.Kind == ArrayStart {
+			s.state = scanArrayStart
+		}
+		return e, nil
+	case ObjectEnd, ArrayEnd:
+		s.stack = s.stack[:len(s.stack)-1]
+	case Key:
+		s.state = scanValue
+		return e, nil
+	case endOfDocument:
+		s.state = scanDone
+		return Event{}, io.EOF
+	}
+	// A value or a container is complete.
+	switch {
+	case len(s.stack) == 0:
+		s.state = scanEnd
+	case s.stack[len(s.stack)-1] == ObjectStart:
+		s.state = scanObjectNext
+	default:
+		s.state = scanArrayNext
+	}
+	return e, nil
+}
+
+// Decode reads the next value of the document, such as the value of a member after its Key event
+// or the next element of an array, and returns it as a Json value. Arrays and objects are read
+// completely. At the end of the document it returns io.EOF.
+func (s *Scanner) Decode() (Json, error) {
+	e, err := s.Next()
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(e)
+}
+
+// decode builds the value that starts with the event e.
+func (s *Scanner) decode(e Event) (Json, error) {
+	switch e.Kind {
+	case Value:
+		return e.Value, nil
+	case ArrayStart:
+		elems := []Json{}
+		for {
+			e, err := s.Next()
+			if err != nil {
+				return nil, err
+			}
+			if e.Kind == ArrayEnd {
+				return JsonArray{Val: elems}, nil
+			}
+			v, err := s.decode(e)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+	case ObjectStart:
+		var pairs []JsonPair
+		for {
+			e, err := s.Next()
+			if err != nil {
+				return nil, err
+			}
+			if e.Kind == ObjectEnd {
+				return buildObject(s.c.duplicates, pairs), nil
+			}
+			v, err := s.Decode()
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, JsonPair{Key: e.Key, Value: v})
+		}
+	}
+	return nil, fmt.Errorf("json: no value at offset %d, found %v", e.Offset, e.Kind)
+}
+
+// unique reports whether key has not been seen before in the innermost open object.
+func (s *Scanner) unique(key string) bool {
+	return !s.keys[len(s.keys)-1][key]
+}
+
+// Skip skips the rest of the innermost open array or object, up to and including its end event.
+// It does nothing at the top level of the document. Calling Skip after the ObjectStart event of
+// an object skips the whole object.
+func (s *Scanner) Skip() error {
+	depth := len(s.stack)
+	for len(s.stack) >= depth && depth > 0 {
+		if _, err := s.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Depth returns the number of arrays and objects that are open at the current position.
+func (s *Scanner) Depth() int {
+	return len(s.stack)
+}
+
+// More reports whether another element follows in the innermost open array or object,
+// or, at the top level, whether another value follows in a stream. It skips the whitespace
+// before the next token, reading more input if needed, and reports false if reading fails.
+func (s *Scanner) More() bool {
+	c, err := s.peek()
+	return err == nil && c != ']' && c != '}'
+}
+
+// InputOffset returns the byte offset in the input just after the last token read.
+func (s *Scanner) InputOffset() int {
+	return s.offset
+}
+
+// peek skips the whitespace before the next token and returns its first byte,
+// or io.EOF at the end of the input.
+func (s *Scanner) peek() (byte, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if _, err := scan(s, s.space); err != nil {
+		return 0, err
+	}
+	if s.buf == "" {
+		return 0, io.EOF
+	}
+	return s.buf[0], nil
+}
+
+// readSize is the minimum number of bytes requested from the reader at a time.
+const readSize = 4096
+
+// token parses the next token for the current state, reading more input until the token is complete.
+func (s *Scanner) token() (Event, error) {
+	start := s.offset
+	e, err := scan(s, s.tokens[s.state])
+	e.Offset += start
+	return e, err
+}
+
+// scan runs p on the buffer of s, reading more input while p needs it, and consumes what p matched.
+func scan[T any](s *Scanner, p parser.Parser[T]) (T, error) {
+	var zero T
+	in := parser.NewIncremental(p, parser.Options{})
+	done, err := in.Feed(s.buf)
+	for !done && err == nil && !s.eof {
+		var chunk string
+		if chunk, err = s.read(); err == nil {
+			done, err = in.Feed(chunk)
+		}
+	}
+	if err != nil {
+		return zero, s.locate(err)
+	}
+	t, err := in.Finish()
+	if err != nil {
+		return zero, s.locate(err)
+	}
+	s.consume(len(s.buf) - len(in.Rest()))
+	return t, nil
+}
+
+// read appends the next chunk of input to the buffer and returns it. The chunk grows with
+// the buffer, so that a long token is re-parsed only a logarithmic number of times.
+func (s *Scanner) read() (string, error) {
+	b := make([]byte, max(readSize, len(s.buf)))
+	n, err := s.r.Read(b)
+	if errors.Is(err, io.EOF) {
+		s.eof = true
+	} else if err != nil {
+		return "", err
+	}
+	chunk := string(b[:n])
+	s.buf += chunk
+	if max := s.c.limits.MaxBytes; max > 0 && s.offset+len(s.buf) > max {
+		return "", &parser.LimitError{Limit: parser.LimitBytes, Max: max, Offset: max}
+	}
+	return chunk, nil
+}
+
+// consume drops the first n bytes of the buffer and advances the position of the scanner past them.
+func (s *Scanner) consume(n int) {
+	text := s.buf[:n]
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		s.line += strings.Count(text, "\n")
+		s.col = 1 + utf8.RuneCountInString(text[i+1:])
+	} else {
+		s.col += utf8.RuneCountInString(text)
+	}
+	s.offset += n
+	s.buf = s.buf[n:]
+}
+
+// locate converts an error of the parser package into a *SyntaxError positioned in the whole document.
+// The parser reports positions relative to the start of the buffer.
+func (s *Scanner) locate(err error) error {
+	err = syntaxError(err)
+	var serr *SyntaxError
+	if errors.As(err, &serr) {
+		if serr.Line == 1 {
+			serr.Column += s.col - 1
+		}
+		serr.Line += s.line - 1
+		serr.Offset += s.offset
+	}
+	return err
+}
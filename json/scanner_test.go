@@ -0,0 +1,236 @@
+package json_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// scanAll collects the events of the document read from r until the end or an error.
+func scanAll(r io.Reader, opts ...json.Option) ([]json.Event, error) {
+	s := json.NewScanner(r, opts...)
+	var events []json.Event
+	for {
+		e, err := s.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, e)
+	}
+}
+
+func TestScanner(t *testing.T) {
+	doc := `{"name": "tiny", "tags": ["a", 1, true, null], "nested": {}}`
+	want := []json.Event{
+		{Kind: json.ObjectStart, Offset: 0},
+		{Kind: json.Key, Key: "name", Offset: 1},
+		{Kind: json.Value, Value: json.JsonString{Val: "tiny"}, Offset: 9},
+		{Kind: json.Key, Key: "tags", Offset: 17},
+		{Kind: json.ArrayStart, Offset: 25},
+		{Kind: json.Value, Value: json.JsonString{Val: "a"}, Offset: 26},
+		{Kind: json.Value, Value: json.JsonInt{Val: 1}, Offset: 31},
+		{Kind: json.Value, Value: json.JsonBool{Val: true}, Offset: 34},
+		{Kind: json.Value, Value: json.JsonNull{}, Offset: 40},
+		{Kind: json.ArrayEnd, Offset: 44},
+		{Kind: json.Key, Key: "nested", Offset: 47},
+		{Kind: json.ObjectStart, Offset: 57},
+		{Kind: json.ObjectEnd, Offset: 58},
+		{Kind: json.ObjectEnd, Offset: 59},
+	}
+
+	t.Run("whole input", func(t *testing.T) {
+		events, err := scanAll(strings.NewReader(doc))
+		assert.NoError(t, err)
+		assert.Equal(t, want, events)
+	})
+
+	t.Run("one byte at a time", func(t *testing.T) {
+		events, err := scanAll(iotest.OneByteReader(strings.NewReader(doc)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, events)
+	})
+
+	t.Run("scalar document", func(t *testing.T) {
+		events, err := scanAll(iotest.OneByteReader(strings.NewReader(" 12345 ")))
+		assert.NoError(t, err)
+		assert.Equal(t, []json.Event{{Kind: json.Value, Value: json.JsonInt{Val: 12345}, Offset: 1}}, events)
+	})
+
+	t.Run("long string", func(t *testing.T) {
+		long := strings.Repeat("x", 100000)
+		events, err := scanAll(strings.NewReader(`["` + long + `"]`))
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		assert.Equal(t, json.JsonString{Val: long}, events[1].Value)
+	})
+
+	t.Run("EOF is final", func(t *testing.T) {
+		s := json.NewScanner(strings.NewReader(`[]`))
+		for range 2 {
+			_, err := s.Next()
+			assert.NoError(t, err)
+		}
+		for range 2 {
+			_, err := s.Next()
+			assert.Equal(t, io.EOF, err)
+		}
+	})
+}
+
+func TestScannerSkip(t *testing.T) {
+	s := json.NewScanner(strings.NewReader(`{"skip": {"a": [1, {"b": 2}]}, "want": 42}`))
+	var got json.Json
+	for {
+		e, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if e.Kind == json.Key && e.Key == "skip" {
+			_, err := s.Next()
+			assert.NoError(t, err)
+			assert.NoError(t, s.Skip())
+			assert.Equal(t, 1, s.Depth())
+		}
+		if e.Kind == json.Key && e.Key == "want" {
+			v, err := s.Next()
+			assert.NoError(t, err)
+			got = v.Value
+		}
+	}
+	assert.Equal(t, json.JsonInt{Val: 42}, got)
+}
+
+func TestScannerErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing comma", "{\n  \"a\": 1\n  \"b\": 2}", `json: line 3, col 3: expected ',' or '}' after object member`},
+		{"truncated", `[1, 2`, `json: line 1, col 6: unexpected end of input, expected ',' or ']' after array element`},
+		{"empty", ``, `json: line 1, col 1: unexpected end of input, expected value`},
+		{"trailing data", `[] []`, `json: line 1, col 4: expected end of input`},
+		{"trailing comma", `[1,]`, `json: line 1, col 4: expected value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scanAll(iotest.OneByteReader(strings.NewReader(tt.input)))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	t.Run("depth limit", func(t *testing.T) {
+		_, err := scanAll(strings.NewReader(`[[[1]]]`), json.MaxDepth(2))
+		assert.ErrorIs(t, err, parser.ErrLimitExceeded)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		_, err := scanAll(strings.NewReader(`[1, 2, 3]`), json.MaxBytes(5))
+		assert.ErrorContains(t, err, "maximum input size of 5 bytes")
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		_, err := scanAll(iotest.TimeoutReader(strings.NewReader(`[1, 2]`)))
+		assert.ErrorIs(t, err, iotest.ErrTimeout)
+	})
+}
+
+func TestScannerOptions(t *testing.T) {
+	events, err := scanAll(iotest.OneByteReader(strings.NewReader("{a: 1, /* c */ b: [0x10,],} // end")), json.JSON5())
+	assert.NoError(t, err)
+	kinds := make([]json.EventKind, len(events))
+	for i, e := range events {
+		kinds[i] = e.Kind
+	}
+	assert.Equal(t, []json.EventKind{
+		json.ObjectStart, json.Key, json.Value, json.Key, json.ArrayStart, json.Value, json.ArrayEnd, json.ObjectEnd,
+	}, kinds)
+	assert.Equal(t, json.JsonInt{Val: 16}, events[5].Value)
+	assert.Equal(t, "ArrayEnd", json.ArrayEnd.String())
+}
+
+func TestScannerDecode(t *testing.T) {
+	s := json.NewScanner(strings.NewReader(`{"meta": {"id": 7, "tags": ["x", "y"]}, "items": [1, 2]}`))
+	e, err := s.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, json.ObjectStart, e.Kind)
+	e, err = s.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "meta", e.Key)
+	meta, err := s.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":7,"tags":["x","y"]}`, meta.String())
+	assert.NoError(t, s.Skip())
+	_, err = s.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		s := json.NewScanner(strings.NewReader(`{"a": 1, "a": 2}`), json.OnDuplicateKey(json.DuplicateKeepFirst))
+		v, err := s.Decode()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, v.String())
+
+		_, err = scanAll(strings.NewReader(`{"a": {"a": 1}, "b": 2, "a": 3}`), json.OnDuplicateKey(json.DuplicateError))
+		assert.EqualError(t, err, "json: line 1, col 25: expected unique key")
+	})
+
+	t.Run("not at a value", func(t *testing.T) {
+		s := json.NewScanner(strings.NewReader(`[]`))
+		_, err := s.Next()
+		assert.NoError(t, err)
+		_, err = s.Decode()
+		assert.EqualError(t, err, "json: no value at offset 1, found ArrayEnd")
+	})
+}
+
+func TestStreamScanner(t *testing.T) {
+	s := json.NewStreamScanner(iotest.OneByteReader(strings.NewReader("{\"a\": [1]}\n2 \"x\"[]\n")))
+	var values []string
+	for s.More() {
+		v, err := s.Decode()
+		assert.NoError(t, err)
+		values = append(values, v.String())
+	}
+	assert.Equal(t, []string{`{"a":[1]}`, "2", `"x"`, "[]"}, values)
+	_, err := s.Next()
+	assert.Equal(t, io.EOF, err)
+
+	t.Run("empty", func(t *testing.T) {
+		s := json.NewStreamScanner(strings.NewReader(" \n "))
+		assert.False(t, s.More())
+		_, err := s.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		s := json.NewStreamScanner(strings.NewReader("1\n]"))
+		_, err := s.Decode()
+		assert.NoError(t, err)
+		_, err = s.Decode()
+		assert.EqualError(t, err, "json: line 2, col 1: expected value or end of input")
+	})
+}
+
+func TestScannerMore(t *testing.T) {
+	s := json.NewScanner(strings.NewReader(`{"a": [1, 2], "b": []}`))
+	var more []bool
+	for {
+		more = append(more, s.More())
+		if _, err := s.Next(); err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+	}
+	// Before: {, "a", [, 1, 2, ], "b", [, ], }, end.
+	assert.Equal(t, []bool{true, true, true, true, true, false, true, true, false, false, false}, more)
+	assert.Equal(t, 22, s.InputOffset())
+}
@@ -0,0 +1,482 @@
+// Package stdjson provides the encoding of Go values as JSON text by reflection.
+package stdjson
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/base64"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/json"
+)
+
+var (
+	marshalerType     = reflect.TypeFor[Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// maxNesting is the number of nested pointers, maps and slices after which a value is assumed to be cyclic.
+const maxNesting = 1000
+
+// encodeState holds the settings and the nesting depth of one Marshal or Encode call.
+type encodeState struct {
+	escapeHTML bool
+	depth      int
+}
+
+// appendValue appends the JSON encoding of rv to b. If quoted is set, scalars are encoded
+// inside a JSON string, as requested by the string option of a field tag.
+func (e *encodeState) appendValue(b []byte, rv reflect.Value, quoted bool) ([]byte, error) {
+	if !rv.IsValid() {
+		return append(b, "null"...), nil
+	}
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(marshalerType) {
+		rv = rv.Addr()
+	}
+	if rv.Kind() != reflect.Interface && rv.Type().Implements(marshalerType) {
+		return e.appendMarshaler(b, rv)
+	}
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(textMarshalerType) {
+		rv = rv.Addr()
+	}
+	if rv.Kind() != reflect.Interface && rv.Type().Implements(textMarshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return append(b, "null"...), nil
+		}
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, &MarshalerError{Type: rv.Type(), Err: err, method: "MarshalText"}
+		}
+		return e.appendString(b, string(text)), nil
+	}
+	if quoted {
+		switch rv.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.String:
+			inner, err := e.appendValue(nil, rv, false)
+			if err != nil {
+				return nil, err
+			}
+			return e.appendString(b, string(inner)), nil
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(b, rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(b, rv.Uint(), 10), nil
+	case reflect.Float32:
+		return appendFloat(b, rv, 32)
+	case reflect.Float64:
+		return appendFloat(b, rv, 64)
+	case reflect.String:
+		return e.appendString(b, rv.String()), nil
+	case reflect.Interface:
+		if rv.IsNil() {
+			return append(b, "null"...), nil
+		}
+		return e.appendValue(b, rv.Elem(), false)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return append(b, "null"...), nil
+		}
+		return e.nested(b, rv, func(b []byte) ([]byte, error) { return e.appendValue(b, rv.Elem(), false) })
+	case reflect.Struct:
+		return e.appendStruct(b, rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return append(b, "null"...), nil
+		}
+		return e.nested(b, rv, func(b []byte) ([]byte, error) { return e.appendMap(b, rv) })
+	case reflect.Slice:
+		if rv.IsNil() {
+			return append(b, "null"...), nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(rv.Type().Elem()).Implements(marshalerType) &&
+			!reflect.PointerTo(rv.Type().Elem()).Implements(textMarshalerType) {
+			b = append(b, '"')
+			b = base64.StdEncoding.AppendEncode(b, rv.Bytes())
+			return append(b, '"'), nil
+		}
+		return e.nested(b, rv, func(b []byte) ([]byte, error) { return e.appendArray(b, rv) })
+	case reflect.Array:
+		return e.appendArray(b, rv)
+	}
+	return nil, &UnsupportedTypeError{Type: rv.Type()}
+}
+
+// nested runs f, which appends the value rv refers to, and fails if values are nested too deeply to be acyclic.
+func (e *encodeState) nested(b []byte, rv reflect.Value, f func([]byte) ([]byte, error)) ([]byte, error) {
+	if e.depth++; e.depth > maxNesting {
+		return nil, &UnsupportedValueError{Value: rv, Str: "encountered a cycle via " + rv.Type().String()}
+	}
+	defer func() { e.depth-- }()
+	return f(b)
+}
+
+// appendMarshaler appends the output of the MarshalJSON method of rv, compacted.
+func (e *encodeState) appendMarshaler(b []byte, rv reflect.Value) ([]byte, error) {
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return append(b, "null"...), nil
+	}
+	out, err := rv.Interface().(Marshaler).MarshalJSON()
+	if err == nil {
+		err = json.Validate(string(out))
+	}
+	if err != nil {
+		return nil, &MarshalerError{Type: rv.Type(), Err: err, method: "MarshalJSON"}
+	}
+	return appendCompact(b, out, e.escapeHTML), nil
+}
+
+// appendFloat appends a float of the given bit size the way encoding/json formats it:
+// in decimal notation, or in exponent notation for very small and very large magnitudes.
+func appendFloat(b []byte, rv reflect.Value, bits int) ([]byte, error) {
+	f := rv.Float()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, &UnsupportedValueError{Value: rv, Str: strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// Shorten a two-digit negative exponent such as e-09 to e-9.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
+
+// appendArray appends the elements of a slice or array.
+func (e *encodeState) appendArray(b []byte, rv reflect.Value) ([]byte, error) {
+	b = append(b, '[')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		var err error
+		if b, err = e.appendValue(b, rv.Index(i), false); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// appendMap appends the entries of a map, sorted by key. Keys must implement encoding.TextMarshaler,
+// or be strings or integers.
+func (e *encodeState) appendMap(b []byte, rv reflect.Value) ([]byte, error) {
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	entries := make([]entry, 0, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		k := iter.Key()
+		var key string
+		switch {
+		case k.Type().Implements(textMarshalerType):
+			if k.Kind() == reflect.Pointer && k.IsNil() {
+				break
+			}
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, &MarshalerError{Type: k.Type(), Err: err, method: "MarshalText"}
+			}
+			key = string(text)
+		case k.Kind() == reflect.String:
+			key = k.String()
+		case k.CanInt():
+			key = strconv.FormatInt(k.Int(), 10)
+		case k.CanUint():
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, &UnsupportedTypeError{Type: rv.Type()}
+		}
+		entries = append(entries, entry{key: key, val: iter.Value()})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return cmp.Compare(a.key, b.key) })
+	b = append(b, '{')
+	for i, en := range entries {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = e.appendString(b, en.key)
+		b = append(b, ':')
+		var err error
+		if b, err = e.appendValue(b, en.val, false); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// appendStruct appends the exported fields of a struct as object members, in declaration order.
+func (e *encodeState) appendStruct(b []byte, rv reflect.Value) ([]byte, error) {
+	b = append(b, '{')
+	first := true
+	for _, f := range cachedFields(rv.Type()) {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || f.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		if !first {
+			b = append(b, ',')
+		}
+		first = false
+		b = e.appendString(b, f.name)
+		b = append(b, ':')
+		var err error
+		if b, err = e.appendValue(b, fv, f.quoted); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// field is a struct field encoded as an object member.
+type field struct {
+	// name is the member name, from the json tag or the field name.
+	name string
+	// index is the index sequence of the field, which may be promoted from an embedded struct.
+	index []int
+	// omitEmpty and quoted are set by the omitempty and string tag options.
+	omitEmpty, quoted bool
+}
+
+// fieldCache holds the fields of every struct type encoded so far, keyed by reflect.Type.
+var fieldCache sync.Map
+
+// cachedFields returns the encoded fields of the struct type t.
+func cachedFields(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	fs, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fs.([]field)
+}
+
+// typeFields returns the encoded fields of t in declaration order, including those promoted from
+// embedded structs at the position of the embedded field. Of several fields with the same name,
+// the least deeply nested one is kept.
+func typeFields(t reflect.Type) []field {
+	var all []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, f := range typeFields(ft) {
+				f.index = append([]int{i}, f.index...)
+				all = append(all, f)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := field{name: name, index: []int{i}}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "string":
+				f.quoted = true
+			}
+		}
+		all = append(all, f)
+	}
+	fields := make([]field, 0, len(all))
+	for _, f := range all {
+		shadowed := slices.ContainsFunc(all, func(g field) bool {
+			return g.name == f.name && len(g.index) < len(f.index)
+		})
+		if !shadowed && !slices.ContainsFunc(fields, func(g field) bool { return g.name == f.name }) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// fieldByIndex returns the field of rv at index. It reports false if the field is promoted
+// through an embedded struct pointer that is nil.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// isEmpty reports whether a field with the omitempty option is left out.
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	}
+	return false
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s as a quoted JSON string, escaped as encoding/json does: control characters,
+// U+2028 and U+2029 always, and <, > and & if escapeHTML is set. Invalid UTF-8 is replaced by U+FFFD.
+func (e *encodeState) appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c == '\b':
+				b = append(b, '\\', 'b')
+			case c == '\f':
+				b = append(b, '\\', 'f')
+			case c < 0x20 || e.escapeHTML && (c == '<' || c == '>' || c == '&'):
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && n == 1:
+			b = append(b, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			b = append(b, s[i:i+n]...)
+		}
+		i += n
+	}
+	return append(b, '"')
+}
+
+// appendCompact appends the valid JSON text src without insignificant whitespace. If escapeHTML
+// is set, the characters <, > and &, U+2028 and U+2029 are escaped in strings.
+func appendCompact(b, src []byte, escapeHTML bool) []byte {
+	inString := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case inString && c == '\\':
+			b = append(b, c, src[i+1])
+			i++
+			continue
+		case inString && c == '"':
+			inString = false
+		case c == '"':
+			inString = true
+		case !inString && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			continue
+		case escapeHTML && (c == '<' || c == '>' || c == '&'):
+			b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			continue
+		case escapeHTML && c == 0xe2 && i+2 < len(src) && src[i+1] == 0x80 && src[i+2]&^1 == 0xa8:
+			b = append(b, '\\', 'u', '2', '0', '2', hex[src[i+2]&0xf])
+			i += 2
+			continue
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// appendIndent appends the compact JSON text src with every array element and object member
+// on a new line, beginning with prefix and one indent per level of nesting.
+func appendIndent(b, src []byte, prefix, indent string) []byte {
+	newline := func(b []byte, depth int) []byte {
+		b = append(b, '\n')
+		b = append(b, prefix...)
+		for range depth {
+			b = append(b, indent...)
+		}
+		return b
+	}
+	depth := 0
+	inString, open := false, false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			b = append(b, c)
+			if c == '\\' {
+				i++
+				b = append(b, src[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if open && c != ']' && c != '}' {
+			// The first element or member of a non-empty array or object.
+			open = false
+			depth++
+			b = newline(b, depth)
+		}
+		switch c {
+		case '"':
+			inString = true
+			b = append(b, c)
+		case '[', '{':
+			open = true
+			b = append(b, c)
+		case ',':
+			b = newline(append(b, c), depth)
+		case ':':
+			b = append(b, c, ' ')
+		case ']', '}':
+			if open {
+				open = false
+			} else {
+				depth--
+				b = newline(b, depth)
+			}
+			b = append(b, c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,200 @@
+// Package stdjson mirrors the API of encoding/json on top of the json package, so that code
+// written against the standard library can switch to this parser by changing an import path.
+//
+// Marshal, MarshalIndent, Unmarshal, Valid, NewDecoder and NewEncoder, and the methods of Decoder
+// and Encoder, have the signatures of their encoding/json counterparts, and the errors they return
+// have the same types and fields, including the input Offset of syntax and type errors, except that
+// UnmarshalTypeError.Struct is not filled in. Values are encoded like encoding/json does. They are
+// decoded by json.Unmarshal, so numbers stored in an empty interface are int64 when they have no
+// fractional part, and float64 otherwise, and []byte is decoded from base64 like Marshal encodes it.
+package stdjson
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Marshaler is implemented by types that encode themselves as JSON text.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from JSON text.
+type Unmarshaler = json.Unmarshaler
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal or Decoder.Decode.
+type InvalidUnmarshalError = json.InvalidUnmarshalError
+
+// SyntaxError describes malformed JSON text.
+type SyntaxError struct {
+	msg string
+	err error
+	// Offset is the byte offset in the input at which the error was found.
+	Offset int64
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the *json.SyntaxError describing the error in detail, including its line and column.
+func (e *SyntaxError) Unwrap() error {
+	return e.err
+}
+
+// UnmarshalTypeError describes a JSON value that cannot be stored in the Go value it is decoded into.
+type UnmarshalTypeError struct {
+	// Value describes the JSON value, e.g. "string" or "number 1.5".
+	Value string
+	// Type is the Go type the value could not be assigned to.
+	Type reflect.Type
+	// Offset is the byte offset in the input just after the value.
+	Offset int64
+	// Struct is the name of the struct type containing the field. It is not recorded and always empty.
+	Struct string
+	// Field locates the value in the document, e.g. "users[0].age".
+	Field string
+}
+
+// Error implements the error interface.
+func (e *UnmarshalTypeError) Error() string {
+	if e.Field == "" {
+		return "json: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+	}
+	return "json: cannot unmarshal " + e.Value + " into Go struct field " + e.Field + " of type " + e.Type.String()
+}
+
+// UnsupportedTypeError is returned by Marshal for a Go type that cannot be encoded, such as a channel.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+// Error implements the error interface.
+func (e *UnsupportedTypeError) Error() string {
+	return "json: unsupported type: " + e.Type.String()
+}
+
+// UnsupportedValueError is returned by Marshal for a Go value that cannot be encoded, such as NaN.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedValueError) Error() string {
+	return "json: unsupported value: " + e.Str
+}
+
+// MarshalerError wraps an error returned by a MarshalJSON or MarshalText method.
+type MarshalerError struct {
+	Type reflect.Type
+	Err  error
+	// method is the name of the failing method.
+	method string
+}
+
+// Error implements the error interface.
+func (e *MarshalerError) Error() string {
+	return "json: error calling " + e.method + " for type " + e.Type.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *MarshalerError) Unwrap() error {
+	return e.Err
+}
+
+// Marshal returns the compact JSON encoding of v. Struct fields are encoded by their `json` tags,
+// honoring the omitempty and string options, map members are sorted by key, []byte is encoded as
+// a base64 string, and the characters <, > and & are escaped in strings, as encoding/json does.
+func Marshal(v any) ([]byte, error) {
+	e := encodeState{escapeHTML: true}
+	return e.appendValue(nil, reflect.ValueOf(v), false)
+}
+
+// MarshalIndent is like Marshal but starts each array element and object member on a new line,
+// beginning with prefix followed by one copy of indent for every level of nesting.
+func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	b, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendIndent(nil, b, prefix, indent), nil
+}
+
+// Unmarshal parses the JSON document data and stores the result in the value pointed to by v.
+// A malformed document fails with a *SyntaxError before anything is stored.
+func Unmarshal(data []byte, v any) error {
+	s := string(data)
+	j, err := json.ParseJSON(s)
+	if err != nil {
+		return convertError(err, 0)
+	}
+	err = json.UnmarshalValue(j, v)
+	var terr *json.UnmarshalTypeError
+	if !errors.As(err, &terr) {
+		return err
+	}
+	end := int64(len(data))
+	if n, err := json.ParseNode(s); err == nil {
+		if n = find(n, terr.Path); n != nil {
+			end = int64(n.Span.End.Offset)
+		}
+	}
+	return convertError(err, end)
+}
+
+// Valid reports whether data is a well-formed JSON document.
+func Valid(data []byte) bool {
+	return json.Valid(string(data))
+}
+
+// convertError converts the errors of the json package into their encoding/json counterparts.
+// end is the offset just after the value a type error was found in.
+func convertError(err error, end int64) error {
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		return &SyntaxError{msg: serr.Error(), err: serr, Offset: int64(serr.Offset)}
+	}
+	var terr *json.UnmarshalTypeError
+	if errors.As(err, &terr) {
+		return &UnmarshalTypeError{Value: terr.Value, Type: terr.Type, Offset: end, Field: terr.Path}
+	}
+	return err
+}
+
+// find returns the node of n located by path, as reported in the Path of a *json.UnmarshalTypeError,
+// or nil if there is none. Keys containing dots or brackets are matched against all members.
+func find(n *json.Node, path string) *json.Node {
+	if path == "" {
+		return n
+	}
+	if path[0] == '[' {
+		end := strings.IndexByte(path, ']')
+		i, err := strconv.Atoi(path[1:max(end, 1)])
+		if end < 0 || err != nil || i >= len(n.Elements) {
+			return nil
+		}
+		return find(n.Elements[i], strings.TrimPrefix(path[end+1:], "."))
+	}
+	for _, m := range n.Members {
+		rest, ok := strings.CutPrefix(path, m.Key)
+		if !ok || rest != "" && rest[0] != '.' && rest[0] != '[' {
+			continue
+		}
+		if found := find(m.Value, strings.TrimPrefix(rest, ".")); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// truncated reports whether err is a syntax error at the end of the input.
+func truncated(err error) bool {
+	return errors.Is(err, parser.ErrUnexpectedEOF)
+}
@@ -0,0 +1,303 @@
+package stdjson_test
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/json"
+	compat "github.com/81120/tiny-parsec/json/stdjson"
+	"github.com/stretchr/testify/assert"
+)
+
+type Inner struct {
+	ID   int    `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type Outer struct {
+	Inner
+	Name    string         `json:"name"`
+	Tags    []string       `json:"tags"`
+	Attrs   map[string]int `json:"attrs,omitempty"`
+	Count   int64          `json:"count,string"`
+	Ratio   float64        `json:"ratio"`
+	Skip    string         `json:"-"`
+	Ptr     *Inner         `json:"ptr"`
+	Raw     []byte         `json:"raw"`
+	When    time.Time      `json:"when"`
+	Any     any            `json:"any"`
+	ByID    map[int]string `json:"by_id"`
+	private string
+}
+
+type upper string
+
+func (u upper) MarshalText() ([]byte, error) { return []byte(strings.ToUpper(string(u))), nil }
+
+type spaced struct{}
+
+func (spaced) MarshalJSON() ([]byte, error) { return []byte(" { \"a\" : [ 1 , \"<b>\" ] } "), nil }
+
+func TestMarshalMatchesEncodingJSON(t *testing.T) {
+	when := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	values := map[string]any{
+		"nil":         nil,
+		"scalars":     []any{true, false, 0, -12, uint8(200), "text"},
+		"floats":      []float64{0, 1, -1.5, 1e20, 1e21, 1e-6, 1e-7, 123456789.125, math.MaxFloat64},
+		"float32":     []float32{0.1, 1e21, 3.4e38},
+		"escapes":     "quote\" back\\ nl\n tab\t ctl\x01 html<>& ls  ps  bad\xff",
+		"struct":      Outer{Inner: Inner{ID: 7}, Name: "n", Count: 42, Ratio: 0.5, Skip: "x", Raw: []byte("hi"), When: when, Any: map[string]any{"k": []any{1.5}}, ByID: map[int]string{2: "b", 10: "a"}},
+		"empty":       Outer{},
+		"pointer":     &Inner{ID: 1, Note: "n"},
+		"nilSlice":    []int(nil),
+		"emptySlice":  []int{},
+		"array":       [3]int{1, 2, 3},
+		"textKeys":    map[upper]int{"a": 1, "b": 2},
+		"textValue":   upper("shout"),
+		"marshaler":   []any{spaced{}},
+		"sortedKeys":  map[string]int{"b": 1, "a": 2, "c": 3},
+		"nestedEmpty": map[string]any{"a": []any{}, "o": map[string]any{}},
+	}
+	for name, v := range values {
+		t.Run(name, func(t *testing.T) {
+			want, err := stdjson.Marshal(v)
+			assert.NoError(t, err)
+			got, err := compat.Marshal(v)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+
+			want, err = stdjson.MarshalIndent(v, ">", "\t")
+			assert.NoError(t, err)
+			got, err = compat.MarshalIndent(v, ">", "\t")
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	_, err := compat.Marshal(make(chan int))
+	var terr *compat.UnsupportedTypeError
+	assert.ErrorAs(t, err, &terr)
+	assert.EqualError(t, err, "json: unsupported type: chan int")
+
+	_, err = compat.Marshal(math.NaN())
+	var verr *compat.UnsupportedValueError
+	assert.ErrorAs(t, err, &verr)
+	assert.EqualError(t, err, "json: unsupported value: NaN")
+
+	type cycle struct{ Next *cycle }
+	c := &cycle{}
+	c.Next = c
+	_, err = compat.Marshal(c)
+	assert.ErrorAs(t, err, &verr)
+}
+
+type broken struct{}
+
+func (broken) MarshalJSON() ([]byte, error) { return []byte("{"), nil }
+
+func TestMarshalerError(t *testing.T) {
+	_, err := compat.Marshal(broken{})
+	var merr *compat.MarshalerError
+	assert.ErrorAs(t, err, &merr)
+	assert.Equal(t, reflect.TypeFor[broken](), merr.Type)
+	var serr *json.SyntaxError
+	assert.ErrorAs(t, err, &serr)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var out Outer
+	err := compat.Unmarshal([]byte(`{"id": 3, "name": "n", "tags": ["a"], "count": 5, "when": "2024-05-06T07:08:09Z", "ptr": {"id": 4}}`), &out)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, out.ID)
+	assert.Equal(t, []string{"a"}, out.Tags)
+	assert.Equal(t, &Inner{ID: 4}, out.Ptr)
+	assert.True(t, out.When.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)))
+
+	err = compat.Unmarshal([]byte(`{"a": [1, }`), &out)
+	var serr *compat.SyntaxError
+	assert.ErrorAs(t, err, &serr)
+	assert.Equal(t, int64(10), serr.Offset)
+
+	err = compat.Unmarshal([]byte(`{"tags": ["a", 2], "id": 1}`), &out)
+	var terr *compat.UnmarshalTypeError
+	assert.ErrorAs(t, err, &terr)
+	assert.Equal(t, "number 2", terr.Value)
+	assert.Equal(t, reflect.TypeFor[string](), terr.Type)
+	assert.Equal(t, "tags[1]", terr.Field)
+	assert.Equal(t, int64(16), terr.Offset)
+	assert.EqualError(t, err, "json: cannot unmarshal number 2 into Go struct field tags[1] of type string")
+
+	err = compat.Unmarshal([]byte(`{"a.b": {"c": true}}`), &map[string]map[string]int{})
+	assert.ErrorAs(t, err, &terr)
+	assert.Equal(t, int64(18), terr.Offset)
+
+	var ierr *compat.InvalidUnmarshalError
+	assert.ErrorAs(t, compat.Unmarshal([]byte(`1`), out), &ierr)
+}
+
+func TestRoundTrip(t *testing.T) {
+	type blob struct {
+		Data  []byte  `json:"data"`
+		Empty []byte  `json:"empty"`
+		Nil   []byte  `json:"nil"`
+		Hash  [4]byte `json:"hash"`
+	}
+	in := blob{Data: []byte("hello, world\x00\xff"), Empty: []byte{}, Hash: [4]byte{1, 2, 3, 4}}
+	b, err := compat.Marshal(in)
+	assert.NoError(t, err)
+	var out, want blob
+	assert.NoError(t, compat.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+	assert.NoError(t, stdjson.Unmarshal(b, &want))
+	assert.Equal(t, want, out)
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, compat.Valid([]byte(`{"a": [1, 2]}`)))
+	assert.False(t, compat.Valid([]byte(`{"a": [1, 2}`)))
+}
+
+func TestDecoder(t *testing.T) {
+	d := compat.NewDecoder(strings.NewReader("{\"id\": 1}\n{\"id\": 2} {\"id\": 3}\n"))
+	var ids []int
+	for d.More() {
+		var in Inner
+		assert.NoError(t, d.Decode(&in))
+		ids = append(ids, in.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.Equal(t, io.EOF, d.Decode(&Inner{}))
+
+	d = compat.NewDecoder(strings.NewReader(`{"id": 1} {"id": `))
+	assert.NoError(t, d.Decode(&Inner{}))
+	assert.Equal(t, int64(9), d.InputOffset())
+	assert.Equal(t, io.ErrUnexpectedEOF, d.Decode(&Inner{}))
+
+	d = compat.NewDecoder(strings.NewReader(`{"id": "x"} ]`))
+	var terr *compat.UnmarshalTypeError
+	assert.ErrorAs(t, d.Decode(&Inner{}), &terr)
+	assert.Equal(t, int64(11), terr.Offset)
+	var serr *compat.SyntaxError
+	assert.ErrorAs(t, d.Decode(&Inner{}), &serr)
+	assert.Equal(t, int64(12), serr.Offset)
+}
+
+func TestEncoder(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		prefix, indent string
+		escapeHTML     bool
+	}{
+		{name: "compact", escapeHTML: true},
+		{name: "indented", prefix: "  ", indent: "\t", escapeHTML: true},
+		{name: "unescaped", escapeHTML: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := map[string]any{"html": "<a&b>", "list": []int{1, 2}}
+			var want, got bytes.Buffer
+			se := stdjson.NewEncoder(&want)
+			se.SetIndent(tc.prefix, tc.indent)
+			se.SetEscapeHTML(tc.escapeHTML)
+			ce := compat.NewEncoder(&got)
+			ce.SetIndent(tc.prefix, tc.indent)
+			ce.SetEscapeHTML(tc.escapeHTML)
+			for range 2 {
+				assert.NoError(t, se.Encode(v))
+				assert.NoError(t, ce.Encode(v))
+			}
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
+
+func TestEncoderWriteError(t *testing.T) {
+	failing := errors.New("disk full")
+	e := compat.NewEncoder(errWriter{failing})
+	assert.ErrorIs(t, e.Encode(1), failing)
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+// tokens reads the tokens of the input with next, formatting them so that both packages compare equal.
+func tokens(next func() (any, error)) []string {
+	var out []string
+	for {
+		tok, err := next()
+		if err != nil {
+			return append(out, fmt.Sprintf("error %T", err))
+		}
+		out = append(out, fmt.Sprintf("%T %v", tok, tok))
+	}
+}
+
+func TestDecoderToken(t *testing.T) {
+	for _, in := range []string{
+		`{"a": [1, 2.5, -3e2], "b": {"c": null, "d": true}, "e": "s"}`,
+		"[] {} \"x\"\n7",
+		`[1, ]`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			sd := stdjson.NewDecoder(strings.NewReader(in))
+			cd := compat.NewDecoder(strings.NewReader(in))
+			want := tokens(func() (any, error) { return sd.Token() })
+			got := tokens(func() (any, error) { return cd.Token() })
+			for i := range want {
+				want[i] = strings.NewReplacer("json.Delim", "stdjson.Delim", "json.SyntaxError", "stdjson.SyntaxError").Replace(want[i])
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		d := compat.NewDecoder(strings.NewReader(`[1, 2`))
+		got := tokens(func() (any, error) { return d.Token() })
+		assert.Equal(t, []string{"stdjson.Delim [", "float64 1", "float64 2", "error *errors.errorString"}, got)
+		assert.Equal(t, io.ErrUnexpectedEOF, d.Decode(new(any)))
+	})
+}
+
+func TestDecoderTokenAndDecode(t *testing.T) {
+	d := compat.NewDecoder(strings.NewReader(`{"items": [{"id": 1}, {"id": 2}], "total": 2}`))
+	tok, err := d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, compat.Delim('{'), tok)
+	tok, err = d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "items", tok)
+	tok, err = d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "[", fmt.Sprint(tok))
+	var ids []int
+	for d.More() {
+		var in Inner
+		assert.NoError(t, d.Decode(&in))
+		ids = append(ids, in.ID)
+	}
+	assert.Equal(t, []int{1, 2}, ids)
+	tok, err = d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, compat.Delim(']'), tok)
+	tok, err = d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "total", tok)
+	var total int
+	assert.NoError(t, d.Decode(&total))
+	assert.Equal(t, 2, total)
+	tok, err = d.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, compat.Delim('}'), tok)
+	_, err = d.Token()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,141 @@
+// Package stdjson provides the streaming Decoder and Encoder.
+package stdjson
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/81120/tiny-parsec/json"
+)
+
+// Decoder reads a stream of JSON values from an input, such as concatenated documents or JSON Lines.
+type Decoder struct {
+	s *json.Scanner
+}
+
+// NewDecoder returns a Decoder reading from r. The Decoder buffers its input, so it may read
+// past the values it decodes.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{s: json.NewStreamScanner(r)}
+}
+
+// Decode reads the next JSON value from the input and stores it in the value pointed to by v.
+// It returns io.EOF at the end of the input, and io.ErrUnexpectedEOF if the input ends inside a value.
+func (d *Decoder) Decode(v any) error {
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	j, err := d.s.Decode()
+	if err != nil {
+		return readError(err)
+	}
+	return convertError(json.UnmarshalValue(j, v), d.InputOffset())
+}
+
+// Token is a syntactic element returned by Decoder.Token: a Delim for the brackets and braces
+// of arrays and objects, a string for object keys and strings, float64 for numbers, bool for
+// booleans, and nil for null.
+type Token any
+
+// Delim is one of the delimiters [ ] { } returned as a Token.
+type Delim rune
+
+// String returns the delimiter as a string.
+func (d Delim) String() string {
+	return string(d)
+}
+
+// Token returns the next syntactic element of the input, and io.EOF at its end. Commas and colons
+// are consumed without being reported. Decode may be called between tokens to decode the next
+// array element or object member value, e.g. to stream the elements of a large array.
+func (d *Decoder) Token() (Token, error) {
+	e, err := d.s.Next()
+	if err != nil {
+		return nil, readError(err)
+	}
+	switch e.Kind {
+	case json.ObjectStart:
+		return Delim('{'), nil
+	case json.ObjectEnd:
+		return Delim('}'), nil
+	case json.ArrayStart:
+		return Delim('['), nil
+	case json.ArrayEnd:
+		return Delim(']'), nil
+	case json.Key:
+		return e.Key, nil
+	}
+	switch v := e.Value.(type) {
+	case json.JsonString:
+		return v.Val, nil
+	case json.JsonBool:
+		return v.Val, nil
+	case json.JsonInt:
+		return float64(v.Val), nil
+	case json.JsonFloat:
+		return v.Val, nil
+	case json.JsonNumber:
+		return v.AsFloat64()
+	}
+	return nil, nil
+}
+
+// More reports whether another element follows in the current array or object,
+// or, at the top level, whether another value follows in the input.
+func (d *Decoder) More() bool {
+	return d.s.More()
+}
+
+// InputOffset returns the byte offset in the input just after the last value or token read.
+func (d *Decoder) InputOffset() int64 {
+	return int64(d.s.InputOffset())
+}
+
+// readError converts an error of the scanner into the error encoding/json reports while reading:
+// io.EOF at the end of the input, io.ErrUnexpectedEOF if the input ends inside a value, and a
+// *SyntaxError for malformed input.
+func readError(err error) error {
+	switch {
+	case err == io.EOF:
+		return io.EOF
+	case truncated(err):
+		return io.ErrUnexpectedEOF
+	}
+	return convertError(err, 0)
+}
+
+// Encoder writes JSON values to an output, each followed by a newline.
+type Encoder struct {
+	w              io.Writer
+	prefix, indent string
+	escapeHTML     bool
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, escapeHTML: true}
+}
+
+// Encode writes the JSON encoding of v, as produced by Marshal, followed by a newline.
+func (e *Encoder) Encode(v any) error {
+	es := encodeState{escapeHTML: e.escapeHTML}
+	b, err := es.appendValue(nil, reflect.ValueOf(v), false)
+	if err != nil {
+		return err
+	}
+	if e.prefix != "" || e.indent != "" {
+		b = appendIndent(nil, b, e.prefix, e.indent)
+	}
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+// SetIndent makes the Encoder lay out every value as MarshalIndent does. Empty strings turn indentation off.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix, e.indent = prefix, indent
+}
+
+// SetEscapeHTML sets whether the characters <, > and & are escaped in strings. The default is true.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.escapeHTML = on
+}
@@ -0,0 +1,122 @@
+package json
+
+import (
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// stringLit parses a double-quoted JSON string literal and decodes its escape sequences:
+// \", \\, \/, \b, \f, \n, \r, \t and \uXXXX, where UTF-16 surrogate pairs are combined
+// into a single rune and unpaired surrogates are replaced by U+FFFD.
+// If strict is set, unescaped control characters (U+0000 to U+001F) are rejected as required by RFC 8259.
+func stringLit(strict bool) parser.Parser[string] {
+	return quoted('"', strict, unescape)
+}
+
+// noRune is returned by an unescape function for escape sequences that stand for no character at all,
+// such as the line continuations of JSON5.
+const noRune rune = -1
+
+// quoted parses a string literal delimited by quote, decoding its escape sequences with unesc.
+// If strict is set, unescaped control characters are rejected.
+func quoted(quote byte, strict bool, unesc func(string) (rune, int, bool)) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if len(s) == 0 || s[0] != quote {
+			st.Fail("string")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		var b []byte
+		for i := 1; i < len(s); {
+			switch c := s[i]; c {
+			case quote:
+				next, ok := st.Advance(i + 1)
+				if !ok {
+					return parser.Nothing[parser.Tuple[string, parser.State]]()
+				}
+				return parser.Just(parser.NewTuple(string(b), next))
+			case '\\':
+				r, n, ok := unesc(s[i:])
+				if !ok {
+					if i+n >= len(s) {
+						st.Truncated("escape sequence")
+					} else if bad, ok := st.Advance(i); ok {
+						bad.Fail("escape sequence")
+					}
+					return parser.Nothing[parser.Tuple[string, parser.State]]()
+				}
+				if r != noRune {
+					b = utf8.AppendRune(b, r)
+				}
+				i += n
+			default:
+				if strict && c < 0x20 {
+					if bad, ok := st.Advance(i); ok {
+						bad.Fail("escaped control character")
+					}
+					return parser.Nothing[parser.Tuple[string, parser.State]]()
+				}
+				b = append(b, c)
+				i++
+			}
+		}
+		st.Truncated(strconv.QuoteRune(rune(quote)))
+		return parser.Nothing[parser.Tuple[string, parser.State]]()
+	})
+}
+
+// unescape decodes the escape sequence at the start of s, which begins with a backslash,
+// and returns the rune and the number of bytes consumed. If the sequence is invalid,
+// it reports false and the number of bytes examined before the problem was found.
+func unescape(s string) (rune, int, bool) {
+	if len(s) < 2 {
+		return 0, 1, false
+	}
+	switch s[1] {
+	case '"', '\\', '/':
+		return rune(s[1]), 2, true
+	case 'b':
+		return '\b', 2, true
+	case 'f':
+		return '\f', 2, true
+	case 'n':
+		return '\n', 2, true
+	case 'r':
+		return '\r', 2, true
+	case 't':
+		return '\t', 2, true
+	case 'u':
+		r, ok := hex4(s[2:])
+		if !ok {
+			return 0, min(len(s), 6), false
+		}
+		if !utf16.IsSurrogate(r) {
+			return r, 6, true
+		}
+		// A high surrogate must be followed by an escaped low surrogate.
+		if len(s) >= 12 && s[6] == '\\' && s[7] == 'u' {
+			if r2, ok := hex4(s[8:]); ok {
+				if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+					return dec, 12, true
+				}
+			}
+		}
+		return utf8.RuneError, 6, true
+	}
+	return 0, 1, false
+}
+
+// hex4 decodes the four hexadecimal digits at the start of s.
+func hex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(v), true
+}
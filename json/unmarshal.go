@@ -0,0 +1,357 @@
+package json
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// The argument must be a non-nil pointer.
+type InvalidUnmarshalError struct {
+	// Type is the type of the argument, or nil if the argument was nil.
+	Type reflect.Type
+}
+
+// Error implements the error interface.
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "json: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "json: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "json: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// UnmarshalTypeError describes a JSON value that cannot be stored in the Go value it is decoded into.
+type UnmarshalTypeError struct {
+	// Value describes the JSON value, e.g. "string" or "number 1.5".
+	Value string
+	// Type is the Go type the value could not be assigned to.
+	Type reflect.Type
+	// Path locates the value in the document, e.g. "users[0].age".
+	Path string
+}
+
+// Error implements the error interface.
+func (e *UnmarshalTypeError) Error() string {
+	if e.Path == "" {
+		return "json: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+	}
+	return "json: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String() + " at " + e.Path
+}
+
+// Unmarshaler is implemented by types that decode themselves from JSON text.
+// Its method set matches the Unmarshaler of encoding/json, so existing implementations work unchanged.
+type Unmarshaler interface {
+	UnmarshalJSON(data []byte) error
+}
+
+// Unmarshal parses the JSON document data and stores the result in the value pointed to by v.
+//
+// Values are decoded like encoding/json does: objects into structs, using the `json:"name"`
+// field tags or else case-insensitive field names, and into maps with string keys; arrays into
+// slices and arrays; base64 strings into []byte; and any value into an empty interface as
+// map[string]any, []any, float64, int64, string, bool or nil. JSON null leaves non-nilable values unchanged. Types implementing
+// Unmarshaler receive the JSON text of their value, and types implementing encoding.TextUnmarshaler
+// receive the contents of a JSON string.
+func Unmarshal(data string, v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	j, err := ParseJSON(data, opts...)
+	if err != nil {
+		return err
+	}
+	return unmarshal(j, rv.Elem(), "")
+}
+
+// UnmarshalValue stores the already parsed value j in the value pointed to by v, like Unmarshal.
+func UnmarshalValue(j Json, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	return unmarshal(j, rv.Elem(), "")
+}
+
+// unmarshal stores j in rv, which must be settable. path locates j in the document for error messages.
+func unmarshal(j Json, rv reflect.Value, path string) error {
+	if _, ok := j.(JsonNull); ok {
+		switch rv.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			rv.SetZero()
+		}
+		return nil
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshal(j, rv.Elem(), path)
+	}
+	if rv.CanAddr() {
+		switch u := rv.Addr().Interface().(type) {
+		case Unmarshaler:
+			return u.UnmarshalJSON([]byte(j.String()))
+		case encoding.TextUnmarshaler:
+			if s, ok := j.(JsonString); ok {
+				return u.UnmarshalText([]byte(s.Val))
+			}
+		}
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(ToNative(j)))
+		return nil
+	}
+	mismatch := &UnmarshalTypeError{Value: describeValue(j), Type: rv.Type(), Path: path}
+	switch j := j.(type) {
+	case JsonBool:
+		if rv.Kind() != reflect.Bool {
+			return mismatch
+		}
+		rv.SetBool(j.Val)
+	case JsonString:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return setBytes(rv, j.Val, path)
+		}
+		if rv.Kind() != reflect.String {
+			return mismatch
+		}
+		rv.SetString(j.Val)
+	case JsonInt:
+		return setNumber(rv, float64(j.Val), j.Val, true, mismatch)
+	case JsonFloat:
+		i := int64(j.Val)
+		return setNumber(rv, j.Val, i, float64(i) == j.Val, mismatch)
+	case JsonNumber:
+		f, _ := j.AsFloat64()
+		i, err := j.AsInt64()
+		return setNumber(rv, f, i, err == nil, mismatch)
+	case JsonArray:
+		return unmarshalArray(j.Val, rv, path, mismatch)
+	case JsonObject:
+		return unmarshalObject(j.Val, rv, path, mismatch)
+	}
+	return nil
+}
+
+// setBytes stores the base64-decoded contents of a JSON string in a byte slice.
+func setBytes(rv reflect.Value, s, path string) error {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		if path != "" {
+			return fmt.Errorf("json: cannot decode base64 string into %v at %s: %w", rv.Type(), path, err)
+		}
+		return fmt.Errorf("json: cannot decode base64 string into %v: %w", rv.Type(), err)
+	}
+	rv.SetBytes(b)
+	return nil
+}
+
+// setNumber stores a number in rv. The number is given both as a float and, if exact is set, as an integer.
+func setNumber(rv reflect.Value, f float64, i int64, exact bool, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !exact || rv.OverflowInt(i) {
+			return mismatch
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !exact || i < 0 || rv.OverflowUint(uint64(i)) {
+			return mismatch
+		}
+		rv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		if rv.OverflowFloat(f) && !math.IsInf(f, 0) {
+			return mismatch
+		}
+		rv.SetFloat(f)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// unmarshalArray stores the elements of a JSON array in a slice or array.
+// Extra elements are ignored and missing elements are zeroed when decoding into an array.
+func unmarshalArray(elems []Json, rv reflect.Value, path string, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+		for i, e := range elems {
+			if err := unmarshal(e, s.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(elems) {
+				rv.Index(i).SetZero()
+				continue
+			}
+			if err := unmarshal(elems[i], rv.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// unmarshalObject stores the members of a JSON object in a struct or a map with string keys.
+// Members without a matching struct field are ignored.
+func unmarshalObject(members map[string]Json, rv reflect.Value, path string, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return mismatch
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(members)))
+		}
+		for _, k := range slices.Sorted(maps.Keys(members)) {
+			m := members[k]
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := unmarshal(m, elem, joinPath(path, k)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		fields := structFields(rv.Type())
+		for _, k := range slices.Sorted(maps.Keys(members)) {
+			m := members[k]
+			f, ok := lookupField(fields, k)
+			if !ok {
+				continue
+			}
+			fv, err := fieldByIndex(rv, f.index)
+			if err != nil {
+				return err
+			}
+			if err := unmarshal(m, fv, joinPath(path, k)); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// field is a struct field that can be decoded from an object member.
+type field struct {
+	// name is the member name, from the json tag or the field name.
+	name string
+	// index is the index sequence of the field, which may be promoted from an embedded struct.
+	index []int
+}
+
+// structFields returns the decodable fields of t, including those promoted from embedded structs.
+// Fields of the outer struct take precedence over promoted fields with the same name.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	var embedded []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, f := range structFields(ft) {
+				embedded = append(embedded, field{name: f.name, index: append([]int{i}, f.index...)})
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: []int{i}})
+	}
+	for _, e := range embedded {
+		if _, ok := lookupField(fields, e.name); !ok {
+			fields = append(fields, e)
+		}
+	}
+	return fields
+}
+
+// lookupField finds the field for a member name, preferring an exact match over a case-insensitive one.
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fieldByIndex returns the field of rv at index, allocating embedded struct pointers on the way.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("json: cannot set embedded pointer to unexported struct %v", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+// joinPath appends an object member to a path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describeValue describes a JSON value for error messages.
+func describeValue(j Json) string {
+	switch j := j.(type) {
+	case JsonBool:
+		return "bool"
+	case JsonString:
+		return "string"
+	case JsonInt:
+		return fmt.Sprintf("number %d", j.Val)
+	case JsonFloat:
+		return fmt.Sprintf("number %v", j.Val)
+	case JsonNumber:
+		return "number " + j.Val
+	case JsonArray:
+		return "array"
+	case JsonObject:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,173 @@
+package json_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city"`
+	Zip  *string
+}
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type user struct {
+	base
+	Name    string            `json:"name"`
+	Age     uint8             `json:"age"`
+	Score   float64           `json:"score"`
+	Admin   bool              `json:"admin"`
+	Tags    []string          `json:"tags"`
+	Home    *address          `json:"home"`
+	Extra   map[string]any    `json:"extra"`
+	Labels  map[string]string `json:"labels"`
+	Pair    [2]int            `json:"pair"`
+	Ignored string            `json:"-"`
+	secret  string
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		var u user
+		err := json.Unmarshal(`{
+			"id": 7,
+			"name": "ada",
+			"age": 36,
+			"score": 1e2,
+			"admin": true,
+			"tags": ["a", "b"],
+			"home": {"city": "London", "zip": "N1"},
+			"extra": {"n": 1, "list": [1.5, null]},
+			"labels": {"k": "v"},
+			"pair": [1, 2, 3],
+			"Ignored": "x",
+			"secret": "x",
+			"unknown": 1
+		}`, &u)
+		assert.NoError(t, err)
+		zip := "N1"
+		assert.Equal(t, user{
+			base:   base{ID: 7},
+			Name:   "ada",
+			Age:    36,
+			Score:  100,
+			Admin:  true,
+			Tags:   []string{"a", "b"},
+			Home:   &address{City: "London", Zip: &zip},
+			Extra:  map[string]any{"n": int64(1), "list": []any{1.5, nil}},
+			Labels: map[string]string{"k": "v"},
+			Pair:   [2]int{1, 2},
+		}, u)
+	})
+
+	t.Run("null resets pointers and leaves values", func(t *testing.T) {
+		u := user{Name: "keep", Home: &address{}}
+		err := json.Unmarshal(`{"name": null, "home": null}`, &u)
+		assert.NoError(t, err)
+		assert.Equal(t, "keep", u.Name)
+		assert.Nil(t, u.Home)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var v any
+		err := json.Unmarshal(`[true, "s", {"a": null}]`, &v)
+		assert.NoError(t, err)
+		assert.Equal(t, []any{true, "s", map[string]any{"a": nil}}, v)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var u user
+		err := json.Unmarshal(`{"tags": ["a", 1]}`, &u)
+		var terr *json.UnmarshalTypeError
+		assert.ErrorAs(t, err, &terr)
+		assert.EqualError(t, err, "json: cannot unmarshal number 1 into Go value of type string at tags[1]")
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		var u user
+		err := json.Unmarshal(`{"age": 300}`, &u)
+		assert.EqualError(t, err, "json: cannot unmarshal number 300 into Go value of type uint8 at age")
+	})
+
+	t.Run("fraction into integer", func(t *testing.T) {
+		var n int
+		err := json.Unmarshal(`1.5`, &n)
+		assert.EqualError(t, err, "json: cannot unmarshal number 1.5 into Go value of type int")
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		var v struct {
+			Data []byte `json:"data"`
+			List []byte `json:"list"`
+		}
+		err := json.Unmarshal(`{"data": "aGVsbG8=", "list": [1, 2]}`, &v)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hello"), v.Data)
+		assert.Equal(t, []byte{1, 2}, v.List)
+
+		var b []byte
+		assert.NoError(t, json.UnmarshalValue(json.JsonString{Val: ""}, &b))
+		assert.Equal(t, []byte{}, b)
+		err = json.Unmarshal(`{"data": "not base64"}`, &v)
+		assert.ErrorContains(t, err, "json: cannot decode base64 string into []uint8 at data: illegal base64 data")
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		var u user
+		assert.EqualError(t, json.Unmarshal(`{}`, u), "json: Unmarshal(non-pointer json_test.user)")
+		assert.EqualError(t, json.Unmarshal(`{}`, nil), "json: Unmarshal(nil)")
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		var u user
+		var serr *json.SyntaxError
+		assert.ErrorAs(t, json.Unmarshal(`{"name": }`, &u), &serr)
+	})
+
+	t.Run("unmarshalers", func(t *testing.T) {
+		var v struct {
+			When  time.Time `json:"when"`
+			Level level     `json:"level"`
+			Names []upper   `json:"names"`
+		}
+		err := json.Unmarshal(`{"when": "2024-05-06T07:08:09Z", "level": "warn", "names": ["ada", "bob"]}`, &v)
+		assert.NoError(t, err)
+		assert.True(t, v.When.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)))
+		assert.Equal(t, level(2), v.Level)
+		assert.Equal(t, []upper{"ADA", "BOB"}, v.Names)
+
+		err = json.Unmarshal(`{"names": [1]}`, &v)
+		assert.EqualError(t, err, "json: cannot unmarshal number 1 into Go value of type json_test.upper at names[0]")
+	})
+
+	t.Run("value", func(t *testing.T) {
+		var u user
+		err := json.UnmarshalValue(json.JsonObject{Val: map[string]json.Json{"name": json.JsonString{Val: "ada"}}}, &u)
+		assert.NoError(t, err)
+		assert.Equal(t, "ada", u.Name)
+		assert.EqualError(t, json.UnmarshalValue(json.JsonNull{}, nil), "json: Unmarshal(nil)")
+	})
+}
+
+// level decodes itself from its JSON text.
+type level int
+
+func (l *level) UnmarshalJSON(data []byte) error {
+	*l = map[string]level{`"info"`: 1, `"warn"`: 2}[string(data)]
+	return nil
+}
+
+// upper decodes itself from the contents of a JSON string.
+type upper string
+
+func (u *upper) UnmarshalText(text []byte) error {
+	*u = upper(strings.ToUpper(string(text)))
+	return nil
+}
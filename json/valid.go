@@ -0,0 +1,249 @@
+package json
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Valid reports whether s is a well-formed JSON document, accepting exactly the documents
+// ParseJSON accepts without options. It scans the input in a single pass without allocating
+// values, so it is much faster than parsing when only validity matters.
+func Valid(s string) bool {
+	v := validator{s: s}
+	return v.document()
+}
+
+// Validate is like Valid but returns a *SyntaxError locating the first error, with the same
+// description ParseJSON reports, or nil if s is well-formed.
+func Validate(s string) error {
+	v := validator{s: s}
+	if v.document() {
+		return nil
+	}
+	return v.err()
+}
+
+// validator checks a JSON document in a single pass. Open arrays and objects are tracked on
+// an explicit stack, so deeply nested input cannot exhaust the goroutine stack.
+type validator struct {
+	s string
+	i int
+	// stack holds the closing bracket of every open array or object.
+	stack []byte
+	// at and expected describe the failure once the document is found malformed.
+	at       int
+	expected string
+}
+
+// document checks the whole input.
+func (v *validator) document() bool {
+	var buf [32]byte
+	v.stack = buf[:0]
+	v.space()
+	if !v.value("value") {
+		return false
+	}
+	for {
+		v.space()
+		if len(v.stack) == 0 {
+			if v.i < len(v.s) {
+				return v.fail(v.i, "end of input")
+			}
+			return true
+		}
+		closer := v.stack[len(v.stack)-1]
+		c, ok := v.peek()
+		switch {
+		case ok && c == closer:
+			v.i++
+			v.stack = v.stack[:len(v.stack)-1]
+		case ok && c == ',':
+			v.i++
+			v.space()
+			if closer == '}' && !v.member("object key") {
+				return false
+			}
+			if closer == ']' && !v.value("value") {
+				return false
+			}
+		case closer == '}':
+			return v.fail(v.i, "',' or '}' after object member")
+		default:
+			return v.fail(v.i, "',' or ']' after array element")
+		}
+	}
+}
+
+// value checks the value at the current position. Arrays and objects are only opened, and
+// their first element or member is checked, so that the caller continues with the rest.
+// expected describes what may appear here if no value does.
+func (v *validator) value(expected string) bool {
+	c, _ := v.peek()
+	switch {
+	case c == '{':
+		v.i++
+		v.space()
+		v.stack = append(v.stack, '}')
+		if c, ok := v.peek(); ok && c == '}' {
+			return true
+		}
+		return v.member("object key or '}'")
+	case c == '[':
+		v.i++
+		v.space()
+		v.stack = append(v.stack, ']')
+		if c, ok := v.peek(); ok && c == ']' {
+			return true
+		}
+		return v.value("value or ']'")
+	case c == '"':
+		return v.string()
+	case c == '-' || c >= '0' && c <= '9':
+		return v.number()
+	case c == 't':
+		return v.literal("true", expected)
+	case c == 'f':
+		return v.literal("false", expected)
+	case c == 'n':
+		return v.literal("null", expected)
+	}
+	return v.fail(v.i, expected)
+}
+
+// member checks an object key and the colon after it, then the start of the member value.
+func (v *validator) member(expected string) bool {
+	if c, ok := v.peek(); !ok || c != '"' {
+		return v.fail(v.i, expected)
+	}
+	if !v.string() {
+		return false
+	}
+	v.space()
+	if c, ok := v.peek(); !ok || c != ':' {
+		return v.fail(v.i, "':' after object key")
+	}
+	v.i++
+	v.space()
+	return v.value("value")
+}
+
+// string checks a string literal starting at the opening quote.
+func (v *validator) string() bool {
+	s := v.s
+	for i := v.i + 1; i < len(s); {
+		switch s[i] {
+		case '"':
+			v.i = i + 1
+			return true
+		case '\\':
+			_, n, ok := unescape(s[i:])
+			if !ok {
+				if i+n >= len(s) {
+					return v.fail(len(s), "escape sequence")
+				}
+				return v.fail(i, "escape sequence")
+			}
+			i += n
+		default:
+			i++
+		}
+	}
+	return v.fail(len(s), `'"'`)
+}
+
+// number checks a number following the grammar of RFC 8259.
+func (v *validator) number() bool {
+	if v.s[v.i] == '-' {
+		v.i++
+	}
+	switch c, ok := v.peek(); {
+	case ok && c == '0':
+		v.i++
+	case ok && c >= '1' && c <= '9':
+		v.digits()
+	default:
+		return v.fail(v.i, `"0" or digit`)
+	}
+	if c, ok := v.peek(); ok && c == '.' {
+		v.i++
+		if v.digits() == 0 {
+			return v.fail(v.i, "digit")
+		}
+	}
+	if c, ok := v.peek(); ok && (c == 'e' || c == 'E') {
+		v.i++
+		expected := "'+', '-' or digit"
+		if c, ok := v.peek(); ok && (c == '+' || c == '-') {
+			v.i++
+			expected = "digit"
+		}
+		if v.digits() == 0 {
+			return v.fail(v.i, expected)
+		}
+	}
+	return true
+}
+
+// digits skips decimal digits and returns how many there were.
+func (v *validator) digits() int {
+	start := v.i
+	for v.i < len(v.s) && v.s[v.i] >= '0' && v.s[v.i] <= '9' {
+		v.i++
+	}
+	return v.i - start
+}
+
+// literal checks the keyword lit, e.g. "true". expected describes what may appear here otherwise.
+func (v *validator) literal(lit, expected string) bool {
+	rest := v.s[v.i:]
+	if len(rest) >= len(lit) && rest[:len(lit)] == lit {
+		v.i += len(lit)
+		return true
+	}
+	if len(rest) < len(lit) && lit[:len(rest)] == rest {
+		return v.fail(len(v.s), strconv.Quote(lit))
+	}
+	return v.fail(v.i, expected)
+}
+
+// space skips whitespace.
+func (v *validator) space() {
+	for v.i < len(v.s) {
+		switch v.s[v.i] {
+		case ' ', '\t', '\n', '\r':
+			v.i++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the byte at the current position, reporting false at the end of the input.
+func (v *validator) peek() (byte, bool) {
+	if v.i < len(v.s) {
+		return v.s[v.i], true
+	}
+	return 0, false
+}
+
+// fail records that expected was missing at offset and reports false.
+func (v *validator) fail(offset int, expected string) bool {
+	v.at = offset
+	v.expected = expected
+	return false
+}
+
+// err returns the *SyntaxError for the recorded failure.
+func (v *validator) err() *SyntaxError {
+	pos := newLineIndex(v.s).position(v.at)
+	e := &SyntaxError{Offset: v.at, Line: pos.Line, Column: pos.Column, Expected: v.expected, Err: parser.ErrNoMatch}
+	if v.at >= len(v.s) {
+		e.Err = parser.ErrUnexpectedEOF
+	} else {
+		r, _ := utf8.DecodeRuneInString(v.s[v.at:])
+		e.Found = strconv.QuoteRune(r)
+	}
+	return e
+}
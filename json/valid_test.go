@@ -0,0 +1,34 @@
+package json_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	valid := []string{
+		`null`, ` true `, `-0.5e+10`, `"aé\n\"b"`, `[]`, `{}`, "[1, [2, {\"a\": [null]}],\n 3]",
+		`{"a": {"b": {}}, "c": []}`, strings.Repeat("[", 100000) + strings.Repeat("]", 100000),
+	}
+	for _, s := range valid {
+		assert.True(t, json.Valid(s), s)
+		assert.NoError(t, json.Validate(s))
+	}
+
+	// Validate reports the same errors as ParseJSON.
+	invalid := []string{
+		``, ` `, `x`, `01`, `-`, `-a`, `1.`, `1e`, `1e+`, `.5`, `tru`, `trux`, `nul`, `[`, `[1`, `[1,`, `[1,]`,
+		`[}`, `[1 2]`, `[1x]`, `{`, `{1}`, `{"a"}`, `{"a" 1}`, `{"a":}`, `{"a":1,}`, `{"a":1 "b":2}`,
+		`"abc`, `"\x"`, `"\u12"`, `"\u12`, `[] []`, "{\n  \"a\": [1,\n  2,]\n}", `{"a":[tru]}`,
+	}
+	for _, s := range invalid {
+		t.Run(s, func(t *testing.T) {
+			assert.False(t, json.Valid(s))
+			_, want := json.ParseJSON(s)
+			assert.Equal(t, want, json.Validate(s))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package json
+
+import "strconv"
+
+// Walk traverses j in document order, calling fn for every value before its children.
+// path holds the object keys and array indexes leading from j to the value; it is empty for j itself
+// and is reused between calls, so fn must copy it to keep it. If fn returns false, the children of
+// the value are skipped.
+func Walk(j Json, fn func(path []string, node Json) bool) {
+	walk(j, nil, fn)
+}
+
+// walk calls fn for j at path and then for its children.
+func walk(j Json, path []string, fn func([]string, Json) bool) {
+	if !fn(path, j) {
+		return
+	}
+	switch j := j.(type) {
+	case JsonArray:
+		for i, e := range j.Val {
+			walk(e, append(path, strconv.Itoa(i)), fn)
+		}
+	case JsonObject:
+		for _, k := range j.OrderedKeys() {
+			walk(j.Val[k], append(path, k), fn)
+		}
+	}
+}
+
+// Visitor rewrites Json values by type. Each method receives the path of the value, as for Walk,
+// and returns the value to put in its place. Arrays and objects are visited after their children,
+// so VisitArray and VisitObject see the rewritten children.
+//
+// Embed IdentityVisitor to implement only the methods of interest.
+type Visitor interface {
+	VisitNull(path []string, v JsonNull) Json
+	VisitBool(path []string, v JsonBool) Json
+	VisitInt(path []string, v JsonInt) Json
+	VisitFloat(path []string, v JsonFloat) Json
+	VisitNumber(path []string, v JsonNumber) Json
+	VisitString(path []string, v JsonString) Json
+	VisitArray(path []string, v JsonArray) Json
+	VisitObject(path []string, v JsonObject) Json
+}
+
+// IdentityVisitor is a Visitor that returns every value unchanged.
+type IdentityVisitor struct{}
+
+// VisitNull returns v unchanged.
+func (IdentityVisitor) VisitNull(_ []string, v JsonNull) Json { return v }
+
+// VisitBool returns v unchanged.
+func (IdentityVisitor) VisitBool(_ []string, v JsonBool) Json { return v }
+
+// VisitInt returns v unchanged.
+func (IdentityVisitor) VisitInt(_ []string, v JsonInt) Json { return v }
+
+// VisitFloat returns v unchanged.
+func (IdentityVisitor) VisitFloat(_ []string, v JsonFloat) Json { return v }
+
+// VisitNumber returns v unchanged.
+func (IdentityVisitor) VisitNumber(_ []string, v JsonNumber) Json { return v }
+
+// VisitString returns v unchanged.
+func (IdentityVisitor) VisitString(_ []string, v JsonString) Json { return v }
+
+// VisitArray returns v unchanged.
+func (IdentityVisitor) VisitArray(_ []string, v JsonArray) Json { return v }
+
+// VisitObject returns v unchanged.
+func (IdentityVisitor) VisitObject(_ []string, v JsonObject) Json { return v }
+
+// Rewrite returns a copy of j in which every value has been replaced by the result of the
+// matching Visitor method. j itself is not modified. A nil result removes an array element
+// or object member.
+func Rewrite(j Json, v Visitor) Json {
+	return rewrite(j, nil, v)
+}
+
+// rewrite rewrites j at path and its children with v.
+func rewrite(j Json, path []string, v Visitor) Json {
+	switch j := j.(type) {
+	case JsonNull:
+		return v.VisitNull(path, j)
+	case JsonBool:
+		return v.VisitBool(path, j)
+	case JsonInt:
+		return v.VisitInt(path, j)
+	case JsonFloat:
+		return v.VisitFloat(path, j)
+	case JsonNumber:
+		return v.VisitNumber(path, j)
+	case JsonString:
+		return v.VisitString(path, j)
+	case JsonArray:
+		elems := make([]Json, 0, len(j.Val))
+		for i, e := range j.Val {
+			if r := rewrite(e, append(path, strconv.Itoa(i)), v); r != nil {
+				elems = append(elems, r)
+			}
+		}
+		return v.VisitArray(path, JsonArray{Val: elems})
+	case JsonObject:
+		obj := JsonObject{Val: make(map[string]Json, len(j.Val))}
+		for _, k := range j.OrderedKeys() {
+			if r := rewrite(j.Val[k], append(path, k), v); r != nil {
+				obj.Set(k, r)
+			}
+		}
+		return v.VisitObject(path, obj)
+	}
+	return j
+}
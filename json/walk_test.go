@@ -0,0 +1,76 @@
+package json_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	doc, err := json.ParseJSON(`{"b": [1, {"c": true}], "a": "x"}`)
+	assert.NoError(t, err)
+
+	t.Run("visits in document order", func(t *testing.T) {
+		var paths []string
+		json.Walk(doc, func(path []string, node json.Json) bool {
+			paths = append(paths, "/"+strings.Join(path, "/"))
+			return true
+		})
+		assert.Equal(t, []string{"/", "/b", "/b/0", "/b/1", "/b/1/c", "/a"}, paths)
+	})
+
+	t.Run("skips children", func(t *testing.T) {
+		var leaves []json.Json
+		json.Walk(doc, func(path []string, node json.Json) bool {
+			if _, ok := node.(json.JsonArray); ok {
+				return false
+			}
+			if _, ok := node.(json.JsonString); ok {
+				leaves = append(leaves, node)
+			}
+			return true
+		})
+		assert.Equal(t, []json.Json{json.JsonString{Val: "x"}}, leaves)
+	})
+}
+
+// redactor replaces the values of members named "password" and drops null values.
+type redactor struct {
+	json.IdentityVisitor
+}
+
+func (redactor) VisitString(path []string, v json.JsonString) json.Json {
+	if len(path) > 0 && path[len(path)-1] == "password" {
+		return json.JsonString{Val: "***"}
+	}
+	return v
+}
+
+func (redactor) VisitNull([]string, json.JsonNull) json.Json {
+	return nil
+}
+
+func TestRewrite(t *testing.T) {
+	doc, err := json.ParseJSON(`{"users": [{"name": "ada", "password": "secret", "email": null}], "password": "root"}`)
+	assert.NoError(t, err)
+
+	out := json.Rewrite(doc, redactor{})
+	assert.Equal(t, `{"users":[{"name":"ada","password":"***"}],"password":"***"}`, out.String())
+	assert.Equal(t, "secret", doc.Pointer("/users/0/password").Get().(json.JsonString).Val)
+
+	t.Run("identity", func(t *testing.T) {
+		assert.Equal(t, doc.String(), json.Rewrite(doc, json.IdentityVisitor{}).String())
+	})
+
+	t.Run("paths can be kept by copying", func(t *testing.T) {
+		var kept [][]string
+		json.Walk(doc, func(path []string, _ json.Json) bool {
+			kept = append(kept, slices.Clone(path))
+			return true
+		})
+		assert.Equal(t, []string{"users", "0", "name"}, kept[3])
+	})
+}
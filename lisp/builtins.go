@@ -0,0 +1,201 @@
+// Package lisp provides the builtin procedures of the language.
+package lisp
+
+import (
+	"fmt"
+	"math"
+)
+
+// numbers returns args as numbers, failing with ErrType if one is not a number.
+func numbers(name string, args []Value) ([]float64, error) {
+	fs := make([]float64, len(args))
+	for i, a := range args {
+		n, ok := a.(Number)
+		if !ok {
+			return nil, fmt.Errorf("lisp: %w: %s expects numbers, got %s", ErrType, name, a)
+		}
+		fs[i] = float64(n)
+	}
+	return fs, nil
+}
+
+// arithmetic returns a builtin folding its arguments with op, starting from unit. If
+// inverse is set, the builtin takes at least one argument, from which it starts unless it
+// is the only one, e.g. (- 5 2) and (- 5).
+func arithmetic(name string, unit float64, inverse bool, op func(x, y float64) (float64, error)) *Builtin {
+	return &Builtin{Name: name, Arity: Variadic, Fn: func(args []Value) (Value, error) {
+		fs, err := numbers(name, args)
+		if err != nil {
+			return nil, err
+		}
+		acc := unit
+		if inverse {
+			if len(fs) == 0 {
+				return nil, fmt.Errorf("lisp: %w: %s takes 1 or more, got 0", ErrArity, name)
+			}
+			if len(fs) > 1 {
+				acc, fs = fs[0], fs[1:]
+			}
+		}
+		for _, f := range fs {
+			if acc, err = op(acc, f); err != nil {
+				return nil, err
+			}
+		}
+		return Number(acc), nil
+	}}
+}
+
+// comparison returns a builtin reporting whether cmp holds for each pair of adjacent
+// arguments.
+func comparison(name string, cmp func(x, y float64) bool) *Builtin {
+	return &Builtin{Name: name, Arity: Variadic, Fn: func(args []Value) (Value, error) {
+		fs, err := numbers(name, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(fs) == 0 {
+			return nil, fmt.Errorf("lisp: %w: %s takes 1 or more, got 0", ErrArity, name)
+		}
+		for i := 1; i < len(fs); i++ {
+			if !cmp(fs[i-1], fs[i]) {
+				return Bool(false), nil
+			}
+		}
+		return Bool(true), nil
+	}}
+}
+
+// integer returns a builtin applying op to two numbers, the second of which may not be 0.
+func integer(name string, op func(x, y float64) float64) *Builtin {
+	return &Builtin{Name: name, Arity: 2, Fn: func(args []Value) (Value, error) {
+		fs, err := numbers(name, args)
+		if err != nil {
+			return nil, err
+		}
+		if fs[1] == 0 {
+			return nil, fmt.Errorf("lisp: %s: %w", name, ErrDivisionByZero)
+		}
+		return Number(op(fs[0], fs[1])), nil
+	}}
+}
+
+// predicate returns a builtin of one argument reporting whether f holds for it.
+func predicate(name string, f func(Value) bool) *Builtin {
+	return &Builtin{Name: name, Arity: 1, Fn: func(args []Value) (Value, error) {
+		return Bool(f(args[0])), nil
+	}}
+}
+
+// pair returns the argument of the builtin name as a pair, failing with ErrType otherwise.
+func pair(name string, v Value) (*Pair, error) {
+	p, ok := v.(*Pair)
+	if !ok {
+		return nil, fmt.Errorf("lisp: %w: %s expects a pair, got %s", ErrType, name, v)
+	}
+	return p, nil
+}
+
+// eqv reports whether x and y are the same value: equal numbers, booleans, strings,
+// symbols or empty lists, or the same pair or procedure.
+func eqv(x, y Value) bool {
+	return x == y
+}
+
+// equal reports whether x and y are eqv, or lists whose elements are equal.
+func equal(x, y Value) bool {
+	p, ok := x.(*Pair)
+	q, ok2 := y.(*Pair)
+	if !ok || !ok2 {
+		return eqv(x, y)
+	}
+	return equal(p.Car, q.Car) && equal(p.Cdr, q.Cdr)
+}
+
+// builtins returns the builtin procedures bound by NewEnv.
+func builtins() []*Builtin {
+	add := func(x, y float64) (float64, error) { return x + y, nil }
+	sub := func(x, y float64) (float64, error) { return x - y, nil }
+	mul := func(x, y float64) (float64, error) { return x * y, nil }
+	div := func(x, y float64) (float64, error) {
+		if y == 0 {
+			return 0, fmt.Errorf("lisp: /: %w", ErrDivisionByZero)
+		}
+		return x / y, nil
+	}
+	return []*Builtin{
+		arithmetic("+", 0, false, add),
+		arithmetic("-", 0, true, sub),
+		arithmetic("*", 1, false, mul),
+		arithmetic("/", 1, true, div),
+		comparison("=", func(x, y float64) bool { return x == y }),
+		comparison("<", func(x, y float64) bool { return x < y }),
+		comparison(">", func(x, y float64) bool { return x > y }),
+		comparison("<=", func(x, y float64) bool { return x <= y }),
+		comparison(">=", func(x, y float64) bool { return x >= y }),
+		integer("quotient", func(x, y float64) float64 { return math.Trunc(x / y) }),
+		integer("remainder", math.Mod),
+		integer("modulo", func(x, y float64) float64 {
+			m := math.Mod(x, y)
+			if m != 0 && (m < 0) != (y < 0) {
+				m += y
+			}
+			return m
+		}),
+		{Name: "abs", Arity: 1, Fn: func(args []Value) (Value, error) {
+			fs, err := numbers("abs", args)
+			if err != nil {
+				return nil, err
+			}
+			return Number(math.Abs(fs[0])), nil
+		}},
+		{Name: "not", Arity: 1, Fn: func(args []Value) (Value, error) {
+			return Bool(!truthy(args[0])), nil
+		}},
+		{Name: "cons", Arity: 2, Fn: func(args []Value) (Value, error) {
+			return &Pair{Car: args[0], Cdr: args[1]}, nil
+		}},
+		{Name: "car", Arity: 1, Fn: func(args []Value) (Value, error) {
+			p, err := pair("car", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return p.Car, nil
+		}},
+		{Name: "cdr", Arity: 1, Fn: func(args []Value) (Value, error) {
+			p, err := pair("cdr", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return p.Cdr, nil
+		}},
+		{Name: "list", Arity: Variadic, Fn: func(args []Value) (Value, error) {
+			return List(args...), nil
+		}},
+		{Name: "length", Arity: 1, Fn: func(args []Value) (Value, error) {
+			vs, ok := Slice(args[0])
+			if !ok {
+				return nil, fmt.Errorf("lisp: %w: length expects a list, got %s", ErrType, args[0])
+			}
+			return Number(len(vs)), nil
+		}},
+		{Name: "eq?", Arity: 2, Fn: func(args []Value) (Value, error) {
+			return Bool(eqv(args[0], args[1])), nil
+		}},
+		{Name: "equal?", Arity: 2, Fn: func(args []Value) (Value, error) {
+			return Bool(equal(args[0], args[1])), nil
+		}},
+		predicate("null?", func(v Value) bool { _, ok := v.(Null); return ok }),
+		predicate("pair?", func(v Value) bool { _, ok := v.(*Pair); return ok }),
+		predicate("number?", func(v Value) bool { _, ok := v.(Number); return ok }),
+		predicate("symbol?", func(v Value) bool { _, ok := v.(Symbol); return ok }),
+		predicate("string?", func(v Value) bool { _, ok := v.(String); return ok }),
+		predicate("procedure?", func(v Value) bool {
+			switch v.(type) {
+			case *Builtin, *Lambda:
+				return true
+			}
+			return false
+		}),
+	}
+}
@@ -0,0 +1,28 @@
+// Package lisp provides error reporting for malformed and failing programs.
+package lisp
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrUnbound is reported for a reference to, or an assignment of, a variable that is
+	// not defined.
+	ErrUnbound = errors.New("unbound variable")
+	// ErrSyntax is reported for a special form that is malformed, e.g. (if) or (lambda 1).
+	ErrSyntax = errors.New("bad syntax")
+	// ErrNotProcedure is reported for a call of a value that is not a procedure.
+	ErrNotProcedure = errors.New("not a procedure")
+	// ErrArity is reported for a call with the wrong number of arguments.
+	ErrArity = errors.New("wrong number of arguments")
+	// ErrType is reported for an argument of the wrong type, e.g. (+ 1 "a").
+	ErrType = errors.New("wrong type")
+	// ErrDivisionByZero is reported for a division by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// ParseError describes why a program could not be read. Expected lists what the program should
+// contain at the error, e.g. "')'".
+type ParseError = parser.Error
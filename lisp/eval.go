@@ -0,0 +1,393 @@
+// Package lisp provides the environments and the evaluator of the language.
+package lisp
+
+import "fmt"
+
+// Env is an environment binding symbols to values, nested in the environment it extends.
+type Env struct {
+	vars   map[Symbol]Value
+	parent *Env
+}
+
+// NewEnv returns a top-level environment with the builtin procedures bound, as listed in
+// the package documentation.
+func NewEnv() *Env {
+	e := &Env{vars: map[Symbol]Value{}}
+	for _, b := range builtins() {
+		e.vars[Symbol(b.Name)] = b
+	}
+	return e
+}
+
+// extend returns an empty environment nested in e.
+func (e *Env) extend() *Env {
+	return &Env{vars: map[Symbol]Value{}, parent: e}
+}
+
+// Define binds name to v in e, replacing any binding of name in e itself.
+func (e *Env) Define(name string, v Value) {
+	e.vars[Symbol(name)] = v
+}
+
+// Lookup returns the value bound to name in e or the environments it extends.
+func (e *Env) Lookup(name string) (Value, bool) {
+	for ; e != nil; e = e.parent {
+		if v, ok := e.vars[Symbol(name)]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// set rebinds name in the innermost environment binding it, reporting whether there is one.
+func (e *Env) set(name Symbol, v Value) bool {
+	for ; e != nil; e = e.parent {
+		if _, ok := e.vars[name]; ok {
+			e.vars[name] = v
+			return true
+		}
+	}
+	return false
+}
+
+// Run reads the program src and evaluates its expressions in e in turn, returning the value
+// of the last one, or Null if there is none. Reader errors are reported as a *ParseError.
+func (e *Env) Run(src string) (Value, error) {
+	xs, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	var v Value = Null{}
+	for _, x := range xs {
+		if v, err = e.Eval(x); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Eval evaluates the expression x in e.
+func (e *Env) Eval(x Value) (Value, error) {
+	return eval(x, e)
+}
+
+// Apply calls the procedure f with args.
+func Apply(f Value, args ...Value) (Value, error) {
+	switch f := f.(type) {
+	case *Builtin:
+		return f.call(args)
+	case *Lambda:
+		env, err := f.bind(args)
+		if err != nil {
+			return nil, err
+		}
+		return begin(f.Body, env)
+	}
+	return nil, fmt.Errorf("lisp: %w: %s", ErrNotProcedure, f)
+}
+
+// call checks the number of arguments of the builtin and calls it.
+func (b *Builtin) call(args []Value) (Value, error) {
+	if b.Arity != Variadic && len(args) != b.Arity {
+		return nil, fmt.Errorf("lisp: %w: %s takes %d, got %d", ErrArity, b.Name, b.Arity, len(args))
+	}
+	return b.Fn(args)
+}
+
+// bind returns the environment for a call of the lambda, binding its parameters to args.
+func (l *Lambda) bind(args []Value) (*Env, error) {
+	if len(args) < len(l.Params) || l.Rest == "" && len(args) > len(l.Params) {
+		want := fmt.Sprint(len(l.Params))
+		if l.Rest != "" {
+			want += " or more"
+		}
+		return nil, fmt.Errorf("lisp: %w: %s takes %s, got %d", ErrArity, l, want, len(args))
+	}
+	env := l.Env.extend()
+	for i, p := range l.Params {
+		env.vars[p] = args[i]
+	}
+	if l.Rest != "" {
+		env.vars[l.Rest] = List(args[len(l.Params):]...)
+	}
+	return env, nil
+}
+
+// syntaxError reports the malformed special form x.
+func syntaxError(x Value) error {
+	return fmt.Errorf("lisp: %w: %s", ErrSyntax, x)
+}
+
+// begin evaluates the expressions xs in env in turn, returning the value of the last one, or
+// Null if there is none.
+func begin(xs []Value, env *Env) (Value, error) {
+	if len(xs) == 0 {
+		return Null{}, nil
+	}
+	for _, x := range xs[:len(xs)-1] {
+		if _, err := eval(x, env); err != nil {
+			return nil, err
+		}
+	}
+	return eval(xs[len(xs)-1], env)
+}
+
+// eval evaluates x in env. Expressions in tail position, such as the branches of if and the
+// last expression of a procedure body, are evaluated by the same loop rather than by a
+// recursive call, so that tail-recursive procedures run in constant stack space.
+func eval(x Value, env *Env) (Value, error) {
+	for {
+		if s, ok := x.(Symbol); ok {
+			val, ok := env.Lookup(string(s))
+			if !ok {
+				return nil, fmt.Errorf("lisp: %w: %s", ErrUnbound, s)
+			}
+			return val, nil
+		}
+		if _, ok := x.(*Pair); !ok {
+			return x, nil
+		}
+		form, ok := Slice(x)
+		if !ok {
+			return nil, syntaxError(x)
+		}
+		op, args := form[0], form[1:]
+		if s, ok := op.(Symbol); ok {
+			switch s {
+			case "quote":
+				if len(args) != 1 {
+					return nil, syntaxError(x)
+				}
+				return args[0], nil
+			case "if":
+				if len(args) != 2 && len(args) != 3 {
+					return nil, syntaxError(x)
+				}
+				c, err := eval(args[0], env)
+				if err != nil {
+					return nil, err
+				}
+				switch {
+				case truthy(c):
+					x = args[1]
+				case len(args) == 3:
+					x = args[2]
+				default:
+					return Null{}, nil
+				}
+				continue
+			case "define":
+				return define(x, args, env)
+			case "set!":
+				name, ok := symbol(args, 2)
+				if !ok {
+					return nil, syntaxError(x)
+				}
+				val, err := eval(args[1], env)
+				if err != nil {
+					return nil, err
+				}
+				if !env.set(name, val) {
+					return nil, fmt.Errorf("lisp: %w: %s", ErrUnbound, name)
+				}
+				return val, nil
+			case "lambda":
+				if len(args) < 2 {
+					return nil, syntaxError(x)
+				}
+				return lambda(x, args[0], args[1:], env, "")
+			case "begin":
+				if len(args) == 0 {
+					return Null{}, nil
+				}
+				if _, err := begin(args[:len(args)-1], env); err != nil {
+					return nil, err
+				}
+				x = args[len(args)-1]
+				continue
+			case "let":
+				body, inner, err := let(x, args, env)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := begin(body[:len(body)-1], inner); err != nil {
+					return nil, err
+				}
+				x, env = body[len(body)-1], inner
+				continue
+			case "cond":
+				body, ok, err := cond(x, args, env)
+				if err != nil || !ok {
+					return Null{}, err
+				}
+				if _, err := begin(body[:len(body)-1], env); err != nil {
+					return nil, err
+				}
+				x = body[len(body)-1]
+				continue
+			case "and", "or":
+				if len(args) == 0 {
+					return Bool(s == "and"), nil
+				}
+				for _, a := range args[:len(args)-1] {
+					v, err := eval(a, env)
+					if err != nil {
+						return nil, err
+					}
+					if truthy(v) == (s == "or") {
+						return v, nil
+					}
+				}
+				x = args[len(args)-1]
+				continue
+			}
+		}
+		f, err := eval(op, env)
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]Value, len(args))
+		for i, a := range args {
+			if vals[i], err = eval(a, env); err != nil {
+				return nil, err
+			}
+		}
+		l, ok := f.(*Lambda)
+		if !ok {
+			return Apply(f, vals...)
+		}
+		if env, err = l.bind(vals); err != nil {
+			return nil, err
+		}
+		if _, err := begin(l.Body[:len(l.Body)-1], env); err != nil {
+			return nil, err
+		}
+		x = l.Body[len(l.Body)-1]
+	}
+}
+
+// symbol returns the first of args if it is a symbol and there are n args.
+func symbol(args []Value, n int) (Symbol, bool) {
+	if len(args) != n {
+		return "", false
+	}
+	s, ok := args[0].(Symbol)
+	return s, ok
+}
+
+// define evaluates the define form x with arguments args in env: (define name expr) or
+// (define (name params...) body...). It returns the name defined.
+func define(x Value, args []Value, env *Env) (Value, error) {
+	if len(args) < 2 {
+		return nil, syntaxError(x)
+	}
+	if p, ok := args[0].(*Pair); ok {
+		name, ok := p.Car.(Symbol)
+		if !ok {
+			return nil, syntaxError(x)
+		}
+		f, err := lambda(x, p.Cdr, args[1:], env, string(name))
+		if err != nil {
+			return nil, err
+		}
+		env.vars[name] = f
+		return name, nil
+	}
+	name, ok := symbol(args, 2)
+	if !ok {
+		return nil, syntaxError(x)
+	}
+	val, err := eval(args[1], env)
+	if err != nil {
+		return nil, err
+	}
+	if l, ok := val.(*Lambda); ok && l.Name == "" {
+		l.Name = string(name)
+	}
+	env.vars[name] = val
+	return name, nil
+}
+
+// lambda creates the procedure of the form x with the parameter list params and the
+// expressions body, closing over env. params is a list of symbols, possibly dotted with the
+// name of the rest parameter, or a single symbol naming the rest parameter.
+func lambda(x, params Value, body []Value, env *Env, name string) (*Lambda, error) {
+	if len(body) == 0 {
+		return nil, syntaxError(x)
+	}
+	l := &Lambda{Body: body, Env: env, Name: name}
+	for {
+		switch p := params.(type) {
+		case Null:
+			return l, nil
+		case Symbol:
+			l.Rest = p
+			return l, nil
+		case *Pair:
+			s, ok := p.Car.(Symbol)
+			if !ok {
+				return nil, syntaxError(x)
+			}
+			l.Params = append(l.Params, s)
+			params = p.Cdr
+		default:
+			return nil, syntaxError(x)
+		}
+	}
+}
+
+// let evaluates the bindings of the let form x with arguments args in env, returning its
+// body and the environment to evaluate it in.
+func let(x Value, args []Value, env *Env) ([]Value, *Env, error) {
+	if len(args) < 2 {
+		return nil, nil, syntaxError(x)
+	}
+	bindings, ok := Slice(args[0])
+	if !ok {
+		return nil, nil, syntaxError(x)
+	}
+	inner := env.extend()
+	for _, b := range bindings {
+		kv, ok := Slice(b)
+		if !ok {
+			return nil, nil, syntaxError(x)
+		}
+		name, ok := symbol(kv, 2)
+		if !ok {
+			return nil, nil, syntaxError(x)
+		}
+		val, err := eval(kv[1], env)
+		if err != nil {
+			return nil, nil, err
+		}
+		inner.vars[name] = val
+	}
+	return args[1:], inner, nil
+}
+
+// cond evaluates the tests of the clauses args of the cond form x in env until one is true,
+// returning the expressions of that clause and true, or false if no test is true. A clause
+// without expressions returns the value of its test, quoted.
+func cond(x Value, args []Value, env *Env) ([]Value, bool, error) {
+	for _, c := range args {
+		clause, ok := Slice(c)
+		if !ok || len(clause) == 0 {
+			return nil, false, syntaxError(x)
+		}
+		var v Value = Bool(true)
+		if clause[0] != Symbol("else") {
+			var err error
+			if v, err = eval(clause[0], env); err != nil {
+				return nil, false, err
+			}
+		}
+		if !truthy(v) {
+			continue
+		}
+		if len(clause) == 1 {
+			return []Value{List(Symbol("quote"), v)}, true, nil
+		}
+		return clause[1:], true, nil
+	}
+	return nil, false, nil
+}
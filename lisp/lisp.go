@@ -0,0 +1,42 @@
+// Package lisp implements a small Scheme-like language, as an example of a complete language
+// built with tiny-parsec: a reader parsing program text into values, and a tree-walking
+// evaluator.
+//
+//	(define (fact n)
+//	  (if (= n 0) 1 (* n (fact (- n 1)))))
+//	(fact 10) ; 3628800
+//
+// Programs consist of numbers, strings, booleans (#t and #f), symbols, lists and quoted
+// data ('x for (quote x)), with comments running from ';' to the end of the line. The
+// special forms are
+//
+//	(quote x)  (if c then [else])  (define name x)  (define (name params...) body...)
+//	(set! name x)  (lambda (params...) body...)  (begin x...)  (let ((name x)...) body...)
+//	(cond (test x...)... (else x...))  (and x...)  (or x...)
+//
+// where parameter lists may end in ". rest" to collect the remaining arguments. The builtin
+// procedures are
+//
+//	numbers: + - * / = < > <= >= quotient remainder modulo abs
+//	lists: cons car cdr list length
+//	predicates: not eq? equal? null? pair? number? symbol? string? procedure?
+//
+// Calls in tail position do not grow the stack, so loops may be written as tail recursion.
+package lisp
+
+import "github.com/81120/tiny-parsec/parser"
+
+// Parse reads the program src into the list of its top-level expressions.
+func Parse(src string) ([]Value, error) {
+	xs, err := parser.Run(program(), src)
+	if err != nil {
+		return nil, parser.Wrap(err, "lisp")
+	}
+	return xs, nil
+}
+
+// Run evaluates the program src in a new environment created by NewEnv, returning the value
+// of its last expression.
+func Run(src string) (Value, error) {
+	return NewEnv().Run(src)
+}
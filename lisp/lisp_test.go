@@ -0,0 +1,142 @@
+package lisp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/81120/tiny-parsec/lisp"
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	xs, err := lisp.Parse(`; comment
+(define x 1.5) 'sym "a\"b\n" #t #false -3 - ... (a . b) ()`)
+	assert.NoError(t, err)
+	assert.Equal(t, []lisp.Value{
+		lisp.List(lisp.Symbol("define"), lisp.Symbol("x"), lisp.Number(1.5)),
+		lisp.List(lisp.Symbol("quote"), lisp.Symbol("sym")),
+		lisp.String("a\"b\n"),
+		lisp.Bool(true),
+		lisp.Bool(false),
+		lisp.Number(-3),
+		lisp.Symbol("-"),
+		lisp.Symbol("..."),
+		&lisp.Pair{Car: lisp.Symbol("a"), Cdr: lisp.Symbol("b")},
+		lisp.Null{},
+	}, xs)
+}
+
+func TestString(t *testing.T) {
+	xs, err := lisp.Parse(`(1 "a\tb" (#t . x) () 2.5e3)`)
+	assert.NoError(t, err)
+	assert.Equal(t, `(1 "a\tb" (#t . x) () 2500)`, xs[0].String())
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"arithmetic", "(+ 1 (* 2 3) (- 10 4) (/ 9 3))", "16"},
+		{"negation", "(list (- 5) (/ 4) (+) (*))", "(-5 0.25 0 1)"},
+		{"comparison", "(list (< 1 2 3) (< 1 3 2) (= 2 2) (>= 3 3 1))", "(#t #f #t #t)"},
+		{"integer division", "(list (quotient -7 2) (remainder -7 2) (modulo -7 2))", "(-3 -1 1)"},
+		{"factorial", "(define (fact n) (if (= n 0) 1 (* n (fact (- n 1))))) (fact 10)", "3628800"},
+		{"closure", "(define (adder n) (lambda (x) (+ x n))) ((adder 3) 4)", "7"},
+		{"counter", "(define n 0) (define (inc!) (set! n (+ n 1))) (inc!) (inc!) n", "2"},
+		{"rest parameters", "(define (f a . rest) (list a rest)) (list (f 1) (f 1 2 3) ((lambda args args) 4 5))", "((1 ()) (1 (2 3)) (4 5))"},
+		{"let", "(let ((x 2) (y 3)) (define z 4) (* x y z))", "24"},
+		{"cond", "(define (sign n) (cond ((< n 0) 'neg) ((= n 0) 'zero) (else 'pos))) (list (sign -2) (sign 0) (sign 5))", "(neg zero pos)"},
+		{"cond test value", "(cond (#f 1) (42))", "42"},
+		{"and or", "(list (and) (and 1 2) (and 1 #f 2) (or) (or #f 3) (or #f #f))", "(#t 2 #f #f 3 #f)"},
+		{"begin", "(begin 1 2 3)", "3"},
+		{"if without else", "(if #f 1)", "()"},
+		{"lists", "(list (car '(1 2)) (cdr '(1 2)) (cons 0 '(1)) (length '(1 2 3)) (null? '()) (pair? '()))", "(1 (2) (0 1) 3 #t #f)"},
+		{"equality", "(list (eq? 'a 'a) (eq? '(1) '(1)) (equal? '(1 (2)) '(1 (2))) (eq? \"a\" \"a\"))", "(#t #f #t #t)"},
+		{"predicates", "(list (number? 1) (symbol? 'a) (string? \"s\") (procedure? car) (procedure? 'car) (not 0))", "(#t #t #t #t #f #f)"},
+		{"tail recursion", "(define (loop i acc) (if (= i 0) acc (loop (- i 1) (+ acc 1)))) (loop 100000 0)", "100000"},
+		{"procedure", "(define (f) 1) f", "#<procedure f>"},
+		{"empty", "", "()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := lisp.Run(tt.src)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.Equal(t, tt.expected, v.String())
+			}
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	env := lisp.NewEnv()
+	env.Define("twice", &lisp.Builtin{Name: "twice", Arity: 1, Fn: func(args []lisp.Value) (lisp.Value, error) {
+		return lisp.List(args[0], args[0]), nil
+	}})
+	_, err := env.Run("(define (square x) (* x x))")
+	assert.NoError(t, err)
+	v, err := env.Run("(twice (square 3))")
+	assert.NoError(t, err)
+	assert.Equal(t, "(9 9)", v.String())
+
+	square, ok := env.Lookup("square")
+	assert.True(t, ok)
+	v, err = lisp.Apply(square, lisp.Number(5))
+	assert.NoError(t, err)
+	assert.Equal(t, lisp.Number(25), v)
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+		msg string
+	}{
+		{"x", lisp.ErrUnbound, "lisp: unbound variable: x"},
+		{"(set! x 1)", lisp.ErrUnbound, "lisp: unbound variable: x"},
+		{"(1 2)", lisp.ErrNotProcedure, "lisp: not a procedure: 1"},
+		{"(car 1 2)", lisp.ErrArity, "lisp: wrong number of arguments: car takes 1, got 2"},
+		{"((lambda (x . y) x))", lisp.ErrArity, "lisp: wrong number of arguments: #<procedure> takes 1 or more, got 0"},
+		{"(+ 1 'a)", lisp.ErrType, "lisp: wrong type: + expects numbers, got a"},
+		{"(car '())", lisp.ErrType, "lisp: wrong type: car expects a pair, got ()"},
+		{"(/ 1 0)", lisp.ErrDivisionByZero, "lisp: /: division by zero"},
+		{"(modulo 1 0)", lisp.ErrDivisionByZero, "lisp: modulo: division by zero"},
+		{"(if)", lisp.ErrSyntax, "lisp: bad syntax: (if)"},
+		{"(lambda (1) 1)", lisp.ErrSyntax, "lisp: bad syntax: (lambda (1) 1)"},
+		{"(let ((x)) x)", lisp.ErrSyntax, "lisp: bad syntax: (let ((x)) x)"},
+		{"(+ . 1)", lisp.ErrSyntax, "lisp: bad syntax: (+ . 1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := lisp.Run(tt.src)
+			assert.ErrorIs(t, err, tt.err)
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+		msg string
+	}{
+		{"(+ 1\n  2", parser.ErrUnexpectedEOF, "lisp: line 2, col 4: unexpected end of input"},
+		{"(1))", parser.ErrNoMatch, "lisp: line 1, col 4: unexpected ')'"},
+		{"#x", parser.ErrNoMatch, "lisp: line 1, col 1: unexpected '#', expected datum"},
+		{"(. 1)", parser.ErrNoMatch, "lisp: line 1, col 2: unexpected '.'"},
+		{`"a\q"`, parser.ErrNoMatch, "lisp: line 1, col 4: unexpected 'q', expected escape sequence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := lisp.Parse(tt.src)
+			var perr *lisp.ParseError
+			assert.True(t, errors.As(err, &perr))
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
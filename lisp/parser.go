@@ -0,0 +1,140 @@
+// Package lisp provides the grammar of the reader, which turns program text into values,
+// built with the tiny-parsec combinators.
+package lisp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// isAtom reports whether r may appear in a number, boolean or symbol.
+func isAtom(r rune) bool {
+	return !isSpace(r) && !strings.ContainsRune(`()'";`, r)
+}
+
+// isNumber reports whether the atom s is a number: an optional sign, digits with an
+// optional fraction, and an optional exponent.
+func isNumber(s string) bool {
+	i := 0
+	digits := func() int {
+		start := i
+		for i < len(s) && isDigit(rune(s[i])) {
+			i++
+		}
+		return i - start
+	}
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	n := digits()
+	if i < len(s) && s[i] == '.' {
+		i++
+		n += digits()
+	}
+	if n == 0 {
+		return false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// booleans maps the spellings of the booleans to their values.
+var booleans = map[string]Bool{"#t": true, "#true": true, "#f": false, "#false": false}
+
+// skip parses whitespace and comments, which run from ';' to the end of the line.
+func skip() parser.Parser[[]rune] {
+	comment := parser.OmitLeft(parser.Char(';'), parser.Fmap(parser.ZeroOrMore(parser.NotChar('\n')), func([]rune) rune { return ';' }))
+	return parser.ZeroOrMore(parser.OrElse(parser.Satisfy(isSpace), comment))
+}
+
+// lexeme parses p followed by whitespace and comments.
+func lexeme[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitRight(p, skip())
+}
+
+// atom parses a number, a boolean or a symbol.
+func atom() parser.Parser[Value] {
+	chars := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isAtom, "datum")), parser.Text)
+	valid := parser.SatisfyWithMsg(chars, func(s string) bool {
+		_, ok := booleans[s]
+		return s != "." && (s[0] != '#' || ok)
+	}, "datum")
+	return parser.Fmap(valid, func(s string) Value {
+		if b, ok := booleans[s]; ok {
+			return b
+		}
+		if isNumber(s) {
+			f, _ := strconv.ParseFloat(s, 64)
+			return Number(f)
+		}
+		return Symbol(s)
+	})
+}
+
+// str parses a string literal, undoing the escapes \" \\ \n and \t.
+func str() parser.Parser[Value] {
+	escape := parser.OmitLeft(parser.Char('\\'), parser.Fmap(parser.SatisfyMsg(func(r rune) bool {
+		return strings.ContainsRune(`"\nt`, r)
+	}, "escape sequence"), func(r rune) rune {
+		switch r {
+		case 'n':
+			return '\n'
+		case 't':
+			return '\t'
+		}
+		return r
+	}))
+	plain := parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' })
+	body := parser.ZeroOrMore(parser.OrElse(plain, escape))
+	return parser.Fmap(parser.Between(parser.Char('"'), body, parser.Char('"')), func(rs []rune) Value {
+		return String(parser.Text(rs))
+	})
+}
+
+// list parses a parenthesized list of data, parsed by datum, possibly dotted: "(1 . 2)".
+func list(datum parser.Parser[Value]) parser.Parser[Value] {
+	dot := parser.SatisfyWith(parser.Fmap(parser.OneOrMore(parser.Satisfy(isAtom)), parser.Text), func(s string) bool { return s == "." })
+	dotted := parser.OmitLeft(lexeme(dot), datum)
+	items := parser.Bind(parser.ZeroOrMore(datum), func(vs []Value) parser.Parser[Value] {
+		tail := parser.Pure[Value](Null{})
+		if len(vs) > 0 {
+			tail = parser.OrElse(dotted, tail)
+		}
+		return parser.Fmap(tail, func(l Value) Value {
+			for i := len(vs) - 1; i >= 0; i-- {
+				l = &Pair{Car: vs[i], Cdr: l}
+			}
+			return l
+		})
+	})
+	return parser.Nested(parser.Between(lexeme(parser.Char('(')), items, parser.Char(')')))
+}
+
+// program parses the data of a program, skipping whitespace and comments around them.
+func program() parser.Parser[[]Value] {
+	var datum parser.Parser[Value]
+	ref := parser.Lazy(func() parser.Parser[Value] { return datum })
+	quote := parser.Fmap(parser.OmitLeft(lexeme(parser.Char('\'')), ref), func(v Value) Value {
+		return List(Symbol("quote"), v)
+	})
+	datum = lexeme(parser.OrElse(list(ref), quote, str(), atom()))
+	return parser.OmitLeft(skip(), parser.ZeroOrMore(datum))
+}
@@ -0,0 +1,197 @@
+// Package lisp defines the values of the language, which are also its syntax tree.
+package lisp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Value is implemented by the values of the language: Number, Bool, String, Symbol, Null,
+// *Pair, *Builtin and *Lambda. Programs are made of the same values, lists standing for
+// special forms and procedure calls.
+type Value interface {
+	// String returns the value as written by the reader, e.g. "(1 2)" or "#t".
+	String() string
+	// lispValue is a method that all value types must implement.
+	// It serves as a marker for the value type.
+	lispValue()
+}
+
+// Number is a number. Integers and reals are both represented as float64.
+type Number float64
+
+func (Number) lispValue() {}
+
+// String returns the number in the shortest form that reads back the same, without an
+// exponent for integers below 1e21, e.g. "3", "3628800" or "0.5".
+func (n Number) String() string {
+	f := float64(n)
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Bool is a boolean, written "#t" or "#f". Only #f counts as false in conditions.
+type Bool bool
+
+func (Bool) lispValue() {}
+
+// String returns "#t" or "#f".
+func (b Bool) String() string {
+	if b {
+		return "#t"
+	}
+	return "#f"
+}
+
+// String is a string literal.
+type String string
+
+func (String) lispValue() {}
+
+// String returns the string in double quotes, escaping '"', '\', newlines and tabs as the
+// reader expects.
+func (s String) String() string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Symbol is an identifier, e.g. "x", "+" or "list->string".
+type Symbol string
+
+func (Symbol) lispValue() {}
+
+// String returns the name of the symbol.
+func (s Symbol) String() string {
+	return string(s)
+}
+
+// Null is the empty list, written "()".
+type Null struct{}
+
+func (Null) lispValue() {}
+
+// String returns "()".
+func (Null) String() string {
+	return "()"
+}
+
+// Pair is a cons cell. Lists are chains of pairs ending in Null.
+type Pair struct {
+	Car, Cdr Value
+}
+
+func (*Pair) lispValue() {}
+
+// String returns the pair in list notation, with a " . " before a final Cdr that is not
+// Null, e.g. "(1 2)" or "(1 . 2)".
+func (p *Pair) String() string {
+	var b strings.Builder
+	b.WriteByte('(')
+	var v Value = p
+	for {
+		q := v.(*Pair)
+		b.WriteString(q.Car.String())
+		v = q.Cdr
+		if _, ok := v.(*Pair); !ok {
+			break
+		}
+		b.WriteByte(' ')
+	}
+	if _, ok := v.(Null); !ok {
+		b.WriteString(" . " + v.String())
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Builtin is a procedure implemented in Go.
+type Builtin struct {
+	Name string
+	// Arity is the number of arguments the procedure takes, or Variadic.
+	Arity int
+	// Fn computes the result of the procedure from its evaluated arguments.
+	Fn func(args []Value) (Value, error)
+}
+
+// Variadic is the Arity of builtins taking any number of arguments.
+const Variadic = -1
+
+func (*Builtin) lispValue() {}
+
+// String returns "#<procedure name>".
+func (b *Builtin) String() string {
+	return "#<procedure " + b.Name + ">"
+}
+
+// Lambda is a procedure created by evaluating a lambda expression.
+type Lambda struct {
+	// Params names the parameters the arguments are bound to.
+	Params []Symbol
+	// Rest names the parameter bound to the list of remaining arguments, if not empty.
+	Rest Symbol
+	// Body lists the expressions evaluated in turn when the procedure is called.
+	Body []Value
+	// Env is the environment the lambda expression was evaluated in.
+	Env *Env
+	// Name is the name the procedure was defined with, if any.
+	Name string
+}
+
+func (*Lambda) lispValue() {}
+
+// String returns "#<procedure name>", or "#<procedure>" for anonymous procedures.
+func (l *Lambda) String() string {
+	if l.Name == "" {
+		return "#<procedure>"
+	}
+	return "#<procedure " + l.Name + ">"
+}
+
+// List returns the list of vs.
+func List(vs ...Value) Value {
+	var l Value = Null{}
+	for i := len(vs) - 1; i >= 0; i-- {
+		l = &Pair{Car: vs[i], Cdr: l}
+	}
+	return l
+}
+
+// Slice returns the elements of the list l, and false if l is not a proper list.
+func Slice(l Value) ([]Value, bool) {
+	var vs []Value
+	for {
+		switch v := l.(type) {
+		case Null:
+			return vs, true
+		case *Pair:
+			vs = append(vs, v.Car)
+			l = v.Cdr
+		default:
+			return vs, false
+		}
+	}
+}
+
+// truthy reports whether v counts as true in a condition, which all values but #f do.
+func truthy(v Value) bool {
+	b, ok := v.(Bool)
+	return !ok || bool(b)
+}
@@ -14,9 +14,9 @@ import (
 // Returns:
 // - A parser that always succeeds and returns the given value.
 func Pure[T any](val T) Parser[T] {
-	return NewParser(func(s string) Maybe[Tuple[T, string]] {
-		return Just(NewTuple(val, s))
-	})
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		return Just(NewTuple(val, st))
+	}), "Pure", kindPure)
 }
 
 // Fail creates a parser that always fails without consuming any input.
@@ -24,9 +24,31 @@ func Pure[T any](val T) Parser[T] {
 // Returns:
 // - A parser that always fails.
 func Fail[T any]() Parser[T] {
-	return NewParser(func(s string) Maybe[Tuple[T, string]] {
-		return Nothing[Tuple[T, string]]()
-	})
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		return Nothing[Tuple[T, State]]()
+	}), "Fail", kindFail)
+}
+
+// Pos creates a parser that returns the current byte offset in the input without consuming any input.
+// Combined with Bind it lets semantic actions record where a construct starts and ends.
+//
+// Returns:
+// - A parser that always succeeds and returns the current offset.
+func Pos() Parser[int] {
+	return describe(newParser(func(st State) StateFuncRet[int] {
+		return Just(NewTuple(st.pos(), st))
+	}), "Pos", kindPure)
+}
+
+// GetInput creates a parser that returns the remaining input without consuming it.
+// The returned string shares memory with the input, so it is cheap to obtain.
+//
+// Returns:
+// - A parser that always succeeds and returns the remaining input.
+func GetInput() Parser[string] {
+	return describe(newParser(func(st State) StateFuncRet[string] {
+		return Just(NewTuple(st.input, st))
+	}), "GetInput", kindPure)
 }
 
 // Char creates a parser that matches a single character if it is equal to the given character.
@@ -37,9 +59,9 @@ func Fail[T any]() Parser[T] {
 // Returns:
 // - A parser that matches the given character.
 func Char(c rune) Parser[rune] {
-	return Satisfy(func(r rune) bool {
+	return describeLiteral(SatisfyMsg(func(r rune) bool {
 		return r == c
-	})
+	}, strconv.QuoteRune(c)), "Char", string(c))
 }
 
 // NotChar creates a parser that matches a single character if it is not equal to the given character.
@@ -50,9 +72,9 @@ func Char(c rune) Parser[rune] {
 // Returns:
 // - A parser that matches a single character if it is not equal to the given character.
 func NotChar(c rune) Parser[rune] {
-	return Satisfy(func(r rune) bool {
+	return SatisfyMsg(func(r rune) bool {
 		return r != c
-	})
+	}, "any character except "+strconv.QuoteRune(c))
 }
 
 // Str creates a parser that matches a given string at the beginning of the input.
@@ -63,12 +85,37 @@ func NotChar(c rune) Parser[rune] {
 // Returns:
 // - A parser that matches the given string.
 func Str(str string) Parser[string] {
-	return NewParser(func(s string) Maybe[Tuple[string, string]] {
-		if strings.HasPrefix(s, str) {
-			return Just(NewTuple(str, strings.TrimPrefix(s, str)))
+	return describeLiteral(newParser(func(st State) StateFuncRet[string] {
+		if !strings.HasPrefix(st.input, str) {
+			if strings.HasPrefix(str, st.input) {
+				// The input is a truncated str, so it ended unexpectedly.
+				st.Truncated(strconv.Quote(str))
+			} else {
+				st.fail(strconv.Quote(str))
+			}
+			return Nothing[Tuple[string, State]]()
 		}
-		return Nothing[Tuple[string, string]]()
-	})
+		next, ok := st.advance(len(str))
+		if !ok {
+			return Nothing[Tuple[string, State]]()
+		}
+		return Just(NewTuple(str, next))
+	}), "Str", str)
+}
+
+// EOF creates a parser that succeeds without consuming input only at the end of the input.
+//
+// Returns:
+// - A parser that matches the end of the input.
+func EOF() Parser[struct{}] {
+	return describe(newParser(func(st State) StateFuncRet[struct{}] {
+		if len(st.input) != 0 {
+			st.fail("end of input")
+			return Nothing[Tuple[struct{}, State]]()
+		}
+		st.hitEnd()
+		return Just(NewTuple(struct{}{}, st))
+	}), "EOF", kindAssert)
 }
 
 // Digit creates a parser that matches a single digit character.
@@ -76,9 +123,9 @@ func Str(str string) Parser[string] {
 // Returns:
 // - A parser that matches a single digit character.
 func Digit() Parser[rune] {
-	return Satisfy(func(r rune) bool {
+	return SatisfyMsg(func(r rune) bool {
 		return r >= '0' && r <= '9'
-	})
+	}, "digit")
 }
 
 // Digits creates a parser that matches one or more digit characters and returns them as a string.
@@ -96,9 +143,9 @@ func Digits() Parser[string] {
 // Returns:
 // - A parser that matches a single alphabetic character.
 func Alpha() Parser[rune] {
-	return Satisfy(func(r rune) bool {
+	return SatisfyMsg(func(r rune) bool {
 		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
-	})
+	}, "letter")
 }
 
 // Alphas creates a parser that matches one or more alphabetic characters and returns them as a string.
@@ -116,9 +163,9 @@ func Alphas() Parser[string] {
 // Returns:
 // - A parser that matches a single whitespace character.
 func Space() Parser[rune] {
-	return Satisfy(func(r rune) bool {
+	return SatisfyMsg(func(r rune) bool {
 		return r == ' ' || r == '\t' || r == '\n' || r == '\r'
-	})
+	}, "whitespace")
 }
 
 // Spaces creates a parser that matches zero or more whitespace characters and returns them as a string.
@@ -131,6 +178,29 @@ func Spaces() Parser[string] {
 	})
 }
 
+// WordBoundary creates a parser that succeeds without consuming input between a word character and a non-word character,
+// or at either end of the input next to a word character. Word characters are letters, digits and '_'.
+//
+// Returns:
+// - A parser that matches a word boundary.
+func WordBoundary() Parser[struct{}] {
+	return describe(newParser(func(st State) StateFuncRet[struct{}] {
+		before, ok := st.prev()
+		wordBefore := ok && isWordChar(before)
+		wordAfter := len(st.input) > 0 && isWordChar(rune(st.input[0]))
+		if wordBefore == wordAfter {
+			st.fail("word boundary")
+			return Nothing[Tuple[struct{}, State]]()
+		}
+		return Just(NewTuple(struct{}{}, st))
+	}), "WordBoundary", kindAssert)
+}
+
+// isWordChar reports whether r is a letter, a digit or '_'.
+func isWordChar(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_'
+}
+
 // Symbol creates a parser that matches a given string surrounded by optional whitespace.
 //
 // Parameters:
@@ -148,16 +218,24 @@ func Symbol(str string) Parser[string] {
 // Returns:
 // - A parser that matches an optional sign character.
 func Sign() Parser[rune] {
-	return Bind(
+	return Fmap(
 		ZeroOrOne(OrElse(Char('-'), Char('+'))),
-		func(r Maybe[rune]) Parser[rune] {
+		func(r Maybe[rune]) rune {
 			if r.IsNothing() {
-				return Pure('+')
+				return '+'
 			}
-			return Pure(r.Get())
+			return r.Get()
 		})
 }
 
+// signOf converts a sign character returned by Sign into the factor 1 or -1.
+func signOf[T int64 | float64](sign rune) T {
+	if sign == '-' {
+		return -1
+	}
+	return 1
+}
+
 // IntegerWithoutSign creates a parser that matches one or more digits and returns them as an integer.
 //
 // Returns:
@@ -174,13 +252,8 @@ func IntegerWithoutSign() Parser[int64] {
 // Returns:
 // - A parser that matches an optional sign followed by one or more digits.
 func Integer() Parser[int64] {
-	return Bind(Sign(), func(sign rune) Parser[int64] {
-		return Fmap(IntegerWithoutSign(), func(i int64) int64 {
-			if sign == '-' {
-				return -i
-			}
-			return i
-		})
+	return Fmap(Seq(Fmap(Sign(), signOf[int64]), IntegerWithoutSign()), func(t []int64) int64 {
+		return t[0] * t[1]
 	})
 }
 
@@ -202,13 +275,8 @@ func FloatWithoutSign() Parser[float64] {
 // Returns:
 // - A parser that matches an optional sign followed by a floating-point number.
 func Float() Parser[float64] {
-	return Bind(Sign(), func(sign rune) Parser[float64] {
-		return Fmap(FloatWithoutSign(), func(f float64) float64 {
-			if sign == '-' {
-				return -f
-			}
-			return f
-		})
+	return Fmap(Seq(Fmap(Sign(), signOf[float64]), FloatWithoutSign()), func(t []float64) float64 {
+		return t[0] * t[1]
 	})
 }
 
@@ -217,10 +285,12 @@ func Float() Parser[float64] {
 // Returns:
 // - A parser that matches a double-quoted string.
 func String() Parser[string] {
-	return NewParser(func(s string) ParserFuncRet[string] {
+	return describe(newParser(func(st State) StateFuncRet[string] {
+		s := st.input
 		// Check if the input starts with a double quote
 		if len(s) == 0 || s[0] != '"' {
-			return Nothing[Tuple[string, string]]()
+			st.fail("string")
+			return Nothing[Tuple[string, State]]()
 		}
 		// Skip the opening double quote
 		s = s[1:]
@@ -238,8 +308,12 @@ func String() Parser[string] {
 				// Mark the next character as escaped
 				escaped = true
 			} else if c == '"' {
-				// Return the parsed string and the remaining input
-				return Just(NewTuple(string(b), s[i+1:]))
+				// Return the parsed string and the state after the closing quote
+				next, ok := st.advance(i + 2)
+				if !ok {
+					return Nothing[Tuple[string, State]]()
+				}
+				return Just(NewTuple(string(b), next))
 			} else {
 				// Append the character
 				b = append(b, c)
@@ -247,6 +321,7 @@ func String() Parser[string] {
 		}
 
 		// If no closing double quote is found, return Nothing
-		return Nothing[Tuple[string, string]]()
-	})
+		st.Truncated(`'"'`)
+		return Nothing[Tuple[string, State]]()
+	}), "String", kindToken)
 }
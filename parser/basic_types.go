@@ -7,17 +7,101 @@ type ParserFuncRet[T any] = Maybe[Tuple[T, string]]
 
 // ParserFunc is a function type that takes a string as input and returns a ParserFuncRet[T].
 // It represents a parsing function that attempts to parse the input string and returns the result.
+// The remaining input it returns must be a suffix of the input it was given.
 type ParserFunc[T any] func(string) ParserFuncRet[T]
 
+// StateFuncRet is an alias for Maybe[Tuple[T, State]], representing the result of running a parser against a State.
+// It holds the parsed value and the state after the consumed input.
+type StateFuncRet[T any] = Maybe[Tuple[T, State]]
+
+// StateFunc is a function type that takes a State as input and returns a StateFuncRet[T].
+// Unlike ParserFunc it takes part in the run it is called from, so limits, error
+// reporting and position tracking work across it.
+type StateFunc[T any] func(State) StateFuncRet[T]
+
+// Runner is the interface implemented by anything that can run as a parser.
+// Parser implements it; custom implementations such as hand-optimized scanners
+// can be turned into a Parser with FromRunner to compose with the combinators.
+type Runner[T any] interface {
+	// RunState parses the input of st and returns the result and the state after it.
+	RunState(st State) StateFuncRet[T]
+}
+
 // Parser is a generic struct that encapsulates a parsing function.
 // It provides a unified interface for different parsing operations.
 type Parser[T any] struct {
-	// Parse is the parsing function that attempts to parse a string and returns a ParserFuncRet[T].
-	Parse ParserFunc[T]
+	// run is the parsing function that attempts to parse the input of a State.
+	run StateFunc[T]
+	// info describes how the parser was built, for Diagnose. It is nil for custom parsers.
+	info *info
 }
 
 // NewParser creates a new Parser instance with the given parsing function.
 // It takes a ParserFunc[T] as input and returns a Parser[T] instance.
 func NewParser[T any](parse ParserFunc[T]) Parser[T] {
-	return Parser[T]{Parse: parse}
+	return newParser(func(st State) StateFuncRet[T] {
+		m := parse(st.input)
+		if m.IsNothing() || m.Get().Second == "" {
+			// The function cannot tell whether it ran out of input, so assume it did.
+			st.hitEnd()
+		}
+		if m.IsNothing() {
+			st.fail("")
+			return Nothing[Tuple[T, State]]()
+		}
+		t := m.Get()
+		next, ok := st.advance(len(st.input) - len(t.Second))
+		if !ok {
+			return Nothing[Tuple[T, State]]()
+		}
+		return Just(NewTuple(t.First, next))
+	})
+}
+
+// NewStateParser creates a new Parser instance from a function running against a State.
+// It is the constructor to use for parsers that need to advance, fail or inspect
+// the State themselves.
+func NewStateParser[T any](run StateFunc[T]) Parser[T] {
+	return newParser(run)
+}
+
+// FromRunner converts any Runner into a Parser so that it can be used with the combinators.
+func FromRunner[T any](r Runner[T]) Parser[T] {
+	if p, ok := r.(Parser[T]); ok {
+		return p
+	}
+	return newParser(r.RunState)
+}
+
+// newParser creates a new Parser instance from a function running against a State.
+func newParser[T any](run StateFunc[T]) Parser[T] {
+	return Parser[T]{run: run}
+}
+
+// RunState implements Runner by running the parser against st.
+func (p Parser[T]) RunState(st State) StateFuncRet[T] {
+	return p.run(st)
+}
+
+// Parse attempts to parse the string s and returns the result together with the remaining input.
+// No limits are enforced; use ParseWith to parse untrusted input.
+func (p Parser[T]) Parse(s string) ParserFuncRet[T] {
+	m, _ := p.ParseWith(s, Options{})
+	return m
+}
+
+// ParseWith parses the string s like Parse while enforcing the limits in opts.
+// If a limit is exceeded the parse is aborted, the result is Nothing and the
+// returned error is a *LimitError describing the limit that was hit.
+func (p Parser[T]) ParseWith(s string, opts Options) (ParserFuncRet[T], error) {
+	st := newState(s, opts)
+	m := p.run(st)
+	if st.aborted() {
+		return Nothing[Tuple[T, string]](), st.ctx.err
+	}
+	if m.IsNothing() {
+		return Nothing[Tuple[T, string]](), nil
+	}
+	t := m.Get()
+	return Just(NewTuple(t.First, t.Second.input)), nil
 }
@@ -0,0 +1,53 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/81120/tiny-parsec/parser"
+)
+
+func BenchmarkFmapBindChain(b *testing.B) {
+	p := Bind(Fmap(Char('a'), func(r rune) int { return int(r) }), func(i int) Parser[int] {
+		return Fmap(Char('b'), func(r rune) int { return i + int(r) })
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse("ab")
+	}
+}
+
+func BenchmarkZeroOrMoreLong(b *testing.B) {
+	input := strings.Repeat("a", 10000)
+	p := ZeroOrMore(Char('a'))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(input)
+	}
+}
+
+func BenchmarkOneOrMoreLong(b *testing.B) {
+	input := strings.Repeat("7", 10000)
+	p := OneOrMore(Digit())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(input)
+	}
+}
+
+func BenchmarkSeqLong(b *testing.B) {
+	ps := make([]Parser[rune], 1000)
+	for i := range ps {
+		ps[i] = Char('a')
+	}
+	input := strings.Repeat("a", len(ps))
+	p := Seq(ps...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(input)
+	}
+}
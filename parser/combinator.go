@@ -5,142 +5,219 @@ package parser
 // It takes a parser p of type T and a function f that maps T to U,
 // and returns a new parser that produces a result of type U.
 func Fmap[T, U any](p Parser[T], f func(T) U) Parser[U] {
-	return NewParser(func(s string) ParserFuncRet[U] {
-		m := p.Parse(s)
+	return describe(newParser(func(st State) StateFuncRet[U] {
+		m := p.run(st)
 		if m.IsNothing() {
-			return Nothing[Tuple[U, string]]()
+			return Nothing[Tuple[U, State]]()
 		}
 		t := m.Get()
 		return Just(NewTuple(f(t.First), t.Second))
-	})
+	}), "Fmap", kindMap, p.info)
 }
 
 // Bind sequences two parsers where the second parser depends on the result of the first.
 // It takes a parser p of type T and a function f that maps T to a parser of type U,
 // and returns a new parser that produces a result of type U.
 func Bind[T, U any](p Parser[T], f func(T) Parser[U]) Parser[U] {
-	return NewParser(func(s string) ParserFuncRet[U] {
-		m := p.Parse(s)
+	return describe(newParser(func(st State) StateFuncRet[U] {
+		m := p.run(st)
 		if m.IsNothing() {
-			return Nothing[Tuple[U, string]]()
+			return Nothing[Tuple[U, State]]()
 		}
 		t := m.Get()
-		return f(t.First).Parse(t.Second)
-	})
+		return f(t.First).run(t.Second)
+	}), "Bind", kindBind, p.info)
 }
 
 // OrElse tries a sequence of parsers in order and returns the result of the first successful one.
 // It takes a variable number of parsers of type T and returns a new parser of type T.
 func OrElse[T any](ps ...Parser[T]) Parser[T] {
-	return NewParser(func(s string) ParserFuncRet[T] {
+	return describe(newParser(func(st State) StateFuncRet[T] {
 		for _, p := range ps {
-			m := p.Parse(s)
-			if m.IsJust() {
+			m := p.run(st)
+			if m.IsJust() || st.aborted() {
 				return m
 			}
 		}
-		return Nothing[Tuple[T, string]]()
-	})
+		return Nothing[Tuple[T, State]]()
+	}), "OrElse", kindAlt, infos(ps)...)
 }
 
 // ZeroOrMore matches zero or more occurrences of a parser.
 // It takes a parser p of type T and returns a new parser that produces a slice of type T.
 func ZeroOrMore[T any](p Parser[T]) Parser[[]T] {
-	return NewParser(func(s string) ParserFuncRet[[]T] {
-		m := p.Parse(s)
-		if m.IsNothing() {
-			return Just(NewTuple([]T{}, s))
-		}
-		t := m.Get()
-		return Bind(ZeroOrMore(p), func(ts []T) Parser[[]T] {
-			return Pure(append([]T{t.First}, ts...))
-		}).Parse(t.Second)
-	})
+	return describe(newParser(func(st State) StateFuncRet[[]T] {
+		return many(p, st, []T{})
+	}), "ZeroOrMore", kindMany, p.info)
 }
 
 // OneOrMore matches one or more occurrences of a parser.
 // It takes a parser p of type T and returns a new parser that produces a slice of type T.
 func OneOrMore[T any](p Parser[T]) Parser[[]T] {
-	return NewParser(func(s string) ParserFuncRet[[]T] {
-		m := p.Parse(s)
+	return describe(newParser(func(st State) StateFuncRet[[]T] {
+		m := p.run(st)
 		if m.IsNothing() {
-			return Nothing[Tuple[[]T, string]]()
+			return Nothing[Tuple[[]T, State]]()
 		}
 		t := m.Get()
-		return Bind(ZeroOrMore(p), func(ts []T) Parser[[]T] {
-			return Pure(append([]T{t.First}, ts...))
-		}).Parse(t.Second)
-	})
+		return many(p, t.Second, []T{t.First})
+	}), "OneOrMore", kindMany1, p.info)
+}
+
+// many runs p repeatedly from st until it fails, appending each result to ts.
+// It loops rather than recursing so that long repetitions neither copy the
+// results on every element nor grow the call stack.
+func many[T any](p Parser[T], st State, ts []T) StateFuncRet[[]T] {
+	for {
+		m := p.run(st)
+		if m.IsNothing() {
+			if st.aborted() {
+				return Nothing[Tuple[[]T, State]]()
+			}
+			return Just(NewTuple(ts, st))
+		}
+		t := m.Get()
+		ts = append(ts, t.First)
+		st = t.Second
+	}
 }
 
 // ZeroOrOne matches zero or one occurrence of a parser.
 // It takes a parser p of type T and returns a new parser that produces a Maybe type of T.
 func ZeroOrOne[T any](p Parser[T]) Parser[Maybe[T]] {
-	return NewParser(func(s string) ParserFuncRet[Maybe[T]] {
-		m := p.Parse(s)
+	return describe(newParser(func(st State) StateFuncRet[Maybe[T]] {
+		m := p.run(st)
 		if m.IsNothing() {
-			return Just(NewTuple(Nothing[T](), s))
+			if st.aborted() {
+				return Nothing[Tuple[Maybe[T], State]]()
+			}
+			return Just(NewTuple(Nothing[T](), st))
 		}
 		t := m.Get()
 		return Just(NewTuple(Just(t.First), t.Second))
-	})
+	}), "ZeroOrOne", kindOptional, p.info)
 }
 
 // OmitLeft runs two parsers in sequence and discards the result of the first.
 // It takes a parser p of type T and a parser q of type U, and returns a new parser of type U.
 func OmitLeft[T, U any](p Parser[T], q Parser[U]) Parser[U] {
-	return Bind(p, func(_ T) Parser[U] {
-		return q
-	})
+	return describe(newParser(func(st State) StateFuncRet[U] {
+		m := p.run(st)
+		if m.IsNothing() {
+			return Nothing[Tuple[U, State]]()
+		}
+		return q.run(m.Get().Second)
+	}), "OmitLeft", kindSeq, p.info, q.info)
 }
 
 // OmitRight runs two parsers in sequence and discards the result of the second.
 // It takes a parser p of type T and a parser q of type U, and returns a new parser of type T.
 func OmitRight[T, U any](p Parser[T], q Parser[U]) Parser[T] {
-	return Bind(p, func(t T) Parser[T] {
-		return Bind(q, func(_ U) Parser[T] {
-			return Pure(t)
-		})
-	})
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		m := p.run(st)
+		if m.IsNothing() {
+			return m
+		}
+		n := q.run(m.Get().Second)
+		if n.IsNothing() {
+			return Nothing[Tuple[T, State]]()
+		}
+		return Just(NewTuple(m.Get().First, n.Get().Second))
+	}), "OmitRight", kindSeq, p.info, q.info)
 }
 
 // SepBy parses a sequence of elements separated by a separator.
 // It takes a parser p of type T and a parser sep of type U, and returns a new parser that produces a slice of type T.
 func SepBy[T, U any](p Parser[T], sep Parser[U]) Parser[[]T] {
-	return OrElse(
+	return describe(OrElse(
 		Bind(p, func(first T) Parser[[]T] {
-			return Fmap(
-				ZeroOrMore(Bind(sep, func(_ U) Parser[T] { return p })),
-				func(rest []T) []T { return append([]T{first}, rest...) },
-			)
+			return newParser(func(st State) StateFuncRet[[]T] {
+				return many(OmitLeft(sep, p), st, []T{first})
+			})
 		}),
 		Pure([]T{}),
-	)
+	), "SepBy", kindSepBy, p.info, sep.info)
 }
 
 // Satisfy parses a single rune that satisfies a given predicate.
 // It takes a function f that tests a rune and returns a new parser that produces a rune.
 func Satisfy(f func(rune) bool) Parser[rune] {
-	return NewParser(func(s string) ParserFuncRet[rune] {
-		if len(s) == 0 {
-			return Nothing[Tuple[rune, string]]()
+	return SatisfyMsg(f, "")
+}
+
+// SatisfyMsg is like Satisfy but takes a description of the accepted runes, e.g. "hex digit".
+// The description is reported as the expected input when the parser fails.
+func SatisfyMsg(f func(rune) bool, expected string) Parser[rune] {
+	return describe(newParser(func(st State) StateFuncRet[rune] {
+		if len(st.input) == 0 {
+			st.hitEnd()
+			st.fail(expected)
+			return Nothing[Tuple[rune, State]]()
 		}
-		if f(rune(s[0])) {
-			return Just(NewTuple(rune(s[0]), s[1:]))
+		r := rune(st.input[0])
+		if !f(r) {
+			st.fail(expected)
+			return Nothing[Tuple[rune, State]]()
 		}
-		return Nothing[Tuple[rune, string]]()
-	})
+		next, ok := st.advance(1)
+		if !ok {
+			return Nothing[Tuple[rune, State]]()
+		}
+		return Just(NewTuple(r, next))
+	}), "Satisfy", kindToken)
+}
+
+// LookBehind checks the rune immediately before the current position without consuming any input.
+// It takes a function f that tests a rune and returns a new parser that produces the preceding rune
+// if it satisfies f. The parser fails at the start of the input.
+func LookBehind(f func(rune) bool) Parser[rune] {
+	return describe(newParser(func(st State) StateFuncRet[rune] {
+		r, ok := st.prev()
+		if !ok || !f(r) {
+			st.fail("")
+			return Nothing[Tuple[rune, State]]()
+		}
+		return Just(NewTuple(r, st))
+	}), "LookBehind", kindAssert)
+}
+
+// NotLookBehind succeeds without consuming input unless the rune immediately before the current position satisfies f.
+// It takes a function f that tests a rune and returns a new parser that also succeeds at the start of the input,
+// e.g. to match a '-' that is not preceded by a digit.
+func NotLookBehind(f func(rune) bool) Parser[struct{}] {
+	return describe(newParser(func(st State) StateFuncRet[struct{}] {
+		if r, ok := st.prev(); ok && f(r) {
+			st.fail("")
+			return Nothing[Tuple[struct{}, State]]()
+		}
+		return Just(NewTuple(struct{}{}, st))
+	}), "NotLookBehind", kindAssert)
 }
 
 // SatisfyWith applies a predicate to the result of a parser and succeeds only if the predicate is true.
 // It takes a parser p of type T and a function f that tests T, and returns a new parser of type T.
 func SatisfyWith[T any](p Parser[T], f func(T) bool) Parser[T] {
-	return Bind(p, func(t T) Parser[T] {
-		if f(t) {
-			return Pure(t)
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		m := p.run(st)
+		if m.IsJust() && !f(m.Get().First) {
+			return Nothing[Tuple[T, State]]()
 		}
-		return Fail[T]()
-	})
+		return m
+	}), "SatisfyWith", kindFilter, p.info)
+}
+
+// SatisfyWithMsg is like SatisfyWith but takes a description of the accepted values, e.g. "port number".
+// When p succeeds but its result fails the predicate, the description is reported as the
+// expected input at the position where p started.
+func SatisfyWithMsg[T any](p Parser[T], f func(T) bool, expected string) Parser[T] {
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		m := p.run(st)
+		if m.IsJust() && !f(m.Get().First) {
+			st.failSpan(m.Get().Second, expected)
+			return Nothing[Tuple[T, State]]()
+		}
+		return m
+	}), "SatisfyWithMsg", kindFilter, p.info)
 }
 
 // TrimLeft removes leading whitespace from the result of a parser.
@@ -164,37 +241,34 @@ func Trim[T any](p Parser[T]) Parser[T] {
 // Seq parses a sequence of parsers in order and returns a slice of their results.
 // It takes a variable number of parsers of type T and returns a new parser that produces a slice of type T.
 func Seq[T any](ps ...Parser[T]) Parser[[]T] {
-	return NewParser(func(s string) ParserFuncRet[[]T] {
-		if len(ps) == 0 {
-			return Just(NewTuple([]T{}, s))
+	return describe(newParser(func(st State) StateFuncRet[[]T] {
+		ts := make([]T, 0, len(ps))
+		for _, p := range ps {
+			m := p.run(st)
+			if m.IsNothing() {
+				return Nothing[Tuple[[]T, State]]()
+			}
+			t := m.Get()
+			ts = append(ts, t.First)
+			st = t.Second
 		}
-		return Bind(ps[0], func(t T) Parser[[]T] {
-			return Bind(Seq(ps[1:]...), func(ts []T) Parser[[]T] {
-				return Pure(append([]T{t}, ts...))
-			})
-		}).Parse(s)
-	})
+		return Just(NewTuple(ts, st))
+	}), "Seq", kindSeq, infos(ps)...)
 }
 
 // Between parses a value between two other values and returns the middle value.
 // It takes a parser p of type T, a parser q of type U, and a parser r of type V,
 // and returns a new parser that produces a result of type U.
 func Between[T, U, V any](p Parser[T], q Parser[U], r Parser[V]) Parser[U] {
-	return Bind(p, func(_ T) Parser[U] {
-		return Bind(q, func(t U) Parser[U] {
-			return Bind(r, func(_ V) Parser[U] {
-				return Pure(t)
-			})
-		})
-	})
+	return describe(OmitLeft(p, OmitRight(q, r)), "Between", kindSeq, p.info, q.info, r.info)
 }
 
 // Lazy defers the creation of a parser until it is needed.
 // It takes a function f that returns a parser of type T and returns a new parser of type T.
 func Lazy[T any](f func() Parser[T]) Parser[T] {
-	return NewParser(func(s string) ParserFuncRet[T] {
-		return f().Parse(s)
-	})
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		return f().run(st)
+	}), "Lazy", kindOpaque)
 }
 
 // ToString converts the result of a parser to a string.
@@ -207,3 +281,37 @@ func ToString[T rune | []rune](p Parser[T], shouldTrim bool) Parser[string] {
 		return string(t)
 	})
 }
+
+// Text converts the runes produced by Satisfy, which holds one byte of the input each, back into
+// the string they were parsed from, so that multi-byte UTF-8 sequences are kept intact.
+func Text(rs []rune) string {
+	b := make([]byte, len(rs))
+	for i, r := range rs {
+		b[i] = byte(r)
+	}
+	return string(b)
+}
+
+// Memo caches the result of r at each position of the input for the duration of a run.
+// It takes any Runner of type T and returns a parser that runs r at most once per position,
+// which keeps grammars that backtrack over the same input from doing the work repeatedly.
+func Memo[T any](r Runner[T]) Parser[T] {
+	id := new(int)
+	var child *info
+	if p, ok := r.(Parser[T]); ok {
+		child = p.info
+	}
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		ctx := st.ctx
+		key := memoKey{id: id, pos: st.pos()}
+		if m, ok := ctx.memo[key]; ok {
+			return m.(StateFuncRet[T])
+		}
+		m := r.RunState(st)
+		if ctx.memo == nil {
+			ctx.memo = make(map[memoKey]any)
+		}
+		ctx.memo[key] = m
+		return m
+	}), "Memo", kindMap, child)
+}
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -224,3 +225,356 @@ func TestOmitSides(t *testing.T) {
 		assert.Equal(t, "value", result.Get().Second)
 	})
 }
+
+func TestParseWith(t *testing.T) {
+	var nested Parser[int]
+	nested = OrElse(
+		Nested(Between(Char('('), Lazy(func() Parser[int] { return nested }), Char(')'))),
+		Pure(0),
+	)
+
+	t.Run("限制内正常解析", func(t *testing.T) {
+		result, err := nested.ParseWith("((()))", Options{MaxDepth: 3})
+		assert.NoError(t, err)
+		assert.True(t, result.IsJust())
+		assert.Equal(t, "", result.Get().Second)
+	})
+
+	t.Run("超过嵌套深度", func(t *testing.T) {
+		result, err := nested.ParseWith("(((())))", Options{MaxDepth: 3})
+		assert.True(t, result.IsNothing())
+		var limitErr *LimitError
+		assert.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitDepth, limitErr.Limit)
+		assert.Equal(t, 3, limitErr.Offset)
+	})
+
+	t.Run("超过输入字节数", func(t *testing.T) {
+		result, err := ZeroOrMore(Char('a')).ParseWith("aaaaa", Options{MaxBytes: 3})
+		assert.True(t, result.IsNothing())
+		var limitErr *LimitError
+		assert.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitBytes, limitErr.Limit)
+		assert.Contains(t, err.Error(), "3 bytes")
+	})
+}
+
+func TestRun(t *testing.T) {
+	list := Between(Char('['), SepBy(Integer(), Char(',')), Char(']'))
+
+	t.Run("完整输入解析成功", func(t *testing.T) {
+		v, err := Run(list, "[1,2,3]")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, v)
+	})
+
+	t.Run("不匹配错误", func(t *testing.T) {
+		_, err := Run(list, "[1,x]")
+		assert.ErrorIs(t, err, ErrNoMatch)
+		var perr *Error
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, 3, perr.Offset)
+		assert.Equal(t, 1, perr.Line)
+		assert.Equal(t, 4, perr.Column)
+		assert.Equal(t, "parser: line 1, col 4: unexpected 'x', expected '-', '+' or digit", err.Error())
+	})
+
+	t.Run("输入提前结束", func(t *testing.T) {
+		_, err := Run(list, "[1,2")
+		assert.ErrorIs(t, err, ErrUnexpectedEOF)
+		assert.EqualError(t, err, "parser: line 1, col 5: unexpected end of input, expected digit, ',' or ']'")
+	})
+
+	t.Run("多余输入", func(t *testing.T) {
+		_, err := Run(Str("ab"), "ab\ncd")
+		assert.ErrorIs(t, err, ErrNoMatch)
+		assert.EqualError(t, err, `parser: line 1, col 3: unexpected '\n', expected end of input`)
+	})
+
+	t.Run("超过限制", func(t *testing.T) {
+		_, err := RunWith(list, "[1,2,3]", Options{MaxBytes: 4})
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+	})
+}
+
+func TestErrorHelpers(t *testing.T) {
+	t.Run("按偏移报告错误", func(t *testing.T) {
+		err := ErrorAt("ini", "a = 1\nb = é2", 12, ErrNoMatch)
+		assert.Equal(t, &Error{Source: "ini", Offset: 12, Line: 2, Column: 6, Err: ErrNoMatch}, err)
+		assert.EqualError(t, err, "ini: line 2, col 6: no match")
+	})
+
+	t.Run("标注错误来源", func(t *testing.T) {
+		_, err := Run(Str("ab"), "ax")
+		wrapped := Wrap(err, "demo")
+		assert.EqualError(t, wrapped, "demo: line 1, col 1: unexpected 'a', expected \"ab\"")
+		assert.ErrorIs(t, wrapped, ErrNoMatch)
+		assert.EqualError(t, err, "parser: line 1, col 1: unexpected 'a', expected \"ab\"")
+		other := errors.New("other")
+		assert.Equal(t, other, Wrap(other, "demo"))
+		assert.NoError(t, Wrap(nil, "demo"))
+	})
+
+	t.Run("描述错误", func(t *testing.T) {
+		assert.Equal(t, "unexpected 'x', expected 'a', 'b' or 'c'", Describe("'x'", []string{"'a'", "'b'", "'c'"}, ErrNoMatch))
+		assert.Equal(t, "unexpected end of input", Describe("", nil, ErrUnexpectedEOF))
+	})
+
+	t.Run("还原字节", func(t *testing.T) {
+		v, err := Run(Fmap(ZeroOrMore(Satisfy(func(rune) bool { return true })), Text), "café ключ")
+		assert.NoError(t, err)
+		assert.Equal(t, "café ключ", v)
+	})
+}
+
+// hexScanner is a hand-written Runner that scans hexadecimal digits in a tight loop.
+type hexScanner struct{}
+
+func (hexScanner) RunState(st State) StateFuncRet[string] {
+	in := st.Input()
+	n := 0
+	for n < len(in) && strings.IndexByte("0123456789abcdefABCDEF", in[n]) >= 0 {
+		n++
+	}
+	if n == 0 {
+		st.Fail("hex digit")
+		return Nothing[Tuple[string, State]]()
+	}
+	next, ok := st.Advance(n)
+	if !ok {
+		return Nothing[Tuple[string, State]]()
+	}
+	return Just(NewTuple(in[:n], next))
+}
+
+func TestRunner(t *testing.T) {
+	hex := FromRunner[string](hexScanner{})
+
+	t.Run("自定义实现组合使用", func(t *testing.T) {
+		v, err := Run(OmitLeft(Str("0x"), hex), "0x1F")
+		assert.NoError(t, err)
+		assert.Equal(t, "1F", v)
+	})
+
+	t.Run("自定义实现报告错误", func(t *testing.T) {
+		_, err := Run(OmitLeft(Str("0x"), hex), "0xZZ")
+		assert.EqualError(t, err, "parser: line 1, col 3: unexpected 'Z', expected hex digit")
+	})
+
+	t.Run("Memo避免重复解析", func(t *testing.T) {
+		calls := 0
+		counted := NewStateParser(func(st State) StateFuncRet[string] {
+			calls++
+			return hex.RunState(st)
+		})
+		word := Memo(counted)
+		p := OrElse(OmitRight(word, Char('!')), OmitRight(word, Char('?')))
+		v, err := Run(p, "abc?")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", v)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestIncremental(t *testing.T) {
+	message := OmitRight(Str("PING"), Str("\r\n"))
+
+	t.Run("分块输入完成解析", func(t *testing.T) {
+		in := NewIncremental(message, Options{})
+		done, err := in.Feed("PI")
+		assert.NoError(t, err)
+		assert.False(t, done)
+		done, err = in.Feed("NG\r")
+		assert.NoError(t, err)
+		assert.False(t, done)
+		done, err = in.Feed("\nPING")
+		assert.NoError(t, err)
+		assert.True(t, done)
+		v, err := in.Finish()
+		assert.NoError(t, err)
+		assert.Equal(t, "PING", v)
+		assert.Equal(t, "PING", in.Rest())
+	})
+
+	t.Run("无法匹配立即报错", func(t *testing.T) {
+		in := NewIncremental(message, Options{})
+		done, err := in.Feed("PONG")
+		assert.False(t, done)
+		assert.ErrorIs(t, err, ErrNoMatch)
+	})
+
+	t.Run("Finish后确定结果", func(t *testing.T) {
+		in := NewIncremental(Digits(), Options{})
+		done, err := in.Feed("12")
+		assert.NoError(t, err)
+		assert.False(t, done)
+		done, _ = in.Feed("3")
+		assert.False(t, done)
+		v, err := in.Finish()
+		assert.NoError(t, err)
+		assert.Equal(t, "123", v)
+	})
+
+	t.Run("Finish时输入不完整", func(t *testing.T) {
+		in := NewIncremental(message, Options{})
+		in.Feed("PIN")
+		_, err := in.Finish()
+		assert.ErrorIs(t, err, ErrUnexpectedEOF)
+	})
+}
+
+func TestParseLines(t *testing.T) {
+	opts := LineOptions{TrimSpace: true, SkipBlank: true, CommentPrefixes: []string{"#"}}
+	sum := func(acc int64, _ Line, v int64) (int64, error) { return acc + v, nil }
+
+	t.Run("逐行累加", func(t *testing.T) {
+		total, errs := ParseLines("1\n  2\n\n# comment\n3", opts, Integer(), int64(0), sum)
+		assert.Empty(t, errs)
+		assert.Equal(t, int64(6), total)
+	})
+
+	t.Run("收集错误位置", func(t *testing.T) {
+		opts := opts
+		opts.Source = "sum"
+		total, errs := ParseLines("1\nx\r\n3\n  4y", opts, Integer(), int64(0), sum)
+		assert.Equal(t, int64(4), total)
+		if assert.Len(t, errs, 2) {
+			assert.EqualError(t, errs[0], "sum: line 2, col 1: unexpected 'x', expected '-', '+' or digit")
+			assert.ErrorIs(t, errs[0], ErrNoMatch)
+			assert.EqualError(t, errs[1], "sum: line 4, col 4: unexpected 'y', expected digit or end of input")
+			var perr *Error
+			assert.ErrorAs(t, errs[1], &perr)
+			assert.Equal(t, 10, perr.Offset)
+		}
+	})
+
+	t.Run("拒绝的行", func(t *testing.T) {
+		positive := func(acc int64, _ Line, v int64) (int64, error) {
+			if v <= 0 {
+				return acc, errors.New("not positive")
+			}
+			return acc + v, nil
+		}
+		_, errs := ParseLines("1\n0", LineOptions{Source: "sum"}, Integer(), int64(0), positive)
+		if assert.Len(t, errs, 1) {
+			assert.EqualError(t, errs[0], "sum: line 2, col 1: not positive")
+		}
+	})
+
+	t.Run("读取行", func(t *testing.T) {
+		var lines []Line
+		for l, err := range ReadLines(strings.NewReader("a\r\n\n  b\nc"), LineOptions{SkipBlank: true}) {
+			assert.NoError(t, err)
+			lines = append(lines, l)
+		}
+		assert.Equal(t, []Line{{Number: 1, Offset: 0, Text: "a"}, {Number: 3, Offset: 4, Text: "  b"}, {Number: 4, Offset: 8, Text: "c"}}, lines)
+		assert.Equal(t, lines, Lines("a\r\n\n  b\nc", LineOptions{SkipBlank: true}))
+	})
+}
+
+func TestIntrospection(t *testing.T) {
+	t.Run("Pos计算跨度", func(t *testing.T) {
+		span := Bind(TrimLeft(Pos()), func(start int) Parser[[2]int] {
+			return OmitLeft(Alphas(), Fmap(Pos(), func(end int) [2]int { return [2]int{start, end} }))
+		})
+		v, err := Run(OmitRight(span, Spaces()), "  hello ")
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{2, 7}, v)
+	})
+
+	t.Run("GetInput不消耗输入", func(t *testing.T) {
+		p := Bind(GetInput(), func(rest string) Parser[string] {
+			if strings.HasPrefix(rest, "--") {
+				return Str("--")
+			}
+			return Str("-")
+		})
+		result := p.Parse("--x")
+		assert.True(t, result.IsJust())
+		assert.Equal(t, "--", result.Get().First)
+		assert.Equal(t, "x", result.Get().Second)
+	})
+}
+
+func TestLookBehind(t *testing.T) {
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+
+	t.Run("前一个字符满足条件", func(t *testing.T) {
+		p := OmitLeft(Alphas(), OmitLeft(LookBehind(func(r rune) bool { return r == 'c' }), Digits()))
+		result := p.Parse("abc123")
+		assert.True(t, result.IsJust())
+		assert.Equal(t, "123", result.Get().First)
+	})
+
+	t.Run("输入开头没有前一个字符", func(t *testing.T) {
+		result := LookBehind(isDigit).Parse("1")
+		assert.True(t, result.IsNothing())
+	})
+
+	t.Run("减号前不能是数字", func(t *testing.T) {
+		minus := OmitLeft(NotLookBehind(isDigit), Char('-'))
+		term := OrElse(Digits(), ToString(minus, false))
+		v, err := Run(ZeroOrMore(term), "-1-2")
+		assert.Nil(t, v)
+		assert.Error(t, err)
+		v, err = Run(ZeroOrMore(OrElse(term, Str(" "))), "-1 -2")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"-", "1", " ", "-", "2"}, v)
+	})
+
+	t.Run("单词边界", func(t *testing.T) {
+		keyword := Between(WordBoundary(), Str("if"), WordBoundary())
+		assert.True(t, OmitLeft(Str(" "), keyword).Parse(" if x").IsJust())
+		assert.True(t, keyword.Parse("iffy").IsNothing())
+	})
+}
+
+func TestSatisfyMsg(t *testing.T) {
+	hexDigit := SatisfyMsg(func(r rune) bool {
+		return strings.ContainsRune("0123456789abcdefABCDEF", r)
+	}, "hex digit")
+
+	t.Run("SatisfyMsg错误描述", func(t *testing.T) {
+		_, err := Run(OmitLeft(Char('#'), OneOrMore(hexDigit)), "#zz")
+		assert.EqualError(t, err, "parser: line 1, col 2: unexpected 'z', expected hex digit")
+	})
+
+	t.Run("SatisfyWithMsg错误描述", func(t *testing.T) {
+		port := SatisfyWithMsg(IntegerWithoutSign(), func(i int64) bool { return i > 0 && i < 65536 }, "port number")
+		v, err := Run(OmitLeft(Char(':'), port), ":8080")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8080), v)
+		_, err = Run(OmitLeft(Char(':'), port), ":99999")
+		assert.EqualError(t, err, "parser: line 1, col 2: unexpected '9', expected port number")
+	})
+}
+
+func TestDiagnose(t *testing.T) {
+	t.Run("前缀遮蔽的分支", func(t *testing.T) {
+		p := OrElse(Str("<"), Str("<="), Str(">"))
+		ds := Diagnose(p)
+		assert.Len(t, ds, 1)
+		assert.Equal(t, `OrElse: alternative 1 is unreachable: alternative 0 already matches its prefix "<"`, ds[0].String())
+	})
+
+	t.Run("总是成功的分支", func(t *testing.T) {
+		p := Seq(Str("a"), OrElse(ToString(ZeroOrMore(Char('b')), false), Str("c")))
+		ds := Diagnose(p)
+		assert.Len(t, ds, 1)
+		assert.Equal(t, "Seq[1] > OrElse", ds[0].Path)
+		assert.Contains(t, ds[0].Message, "alternative 0 always succeeds")
+	})
+
+	t.Run("空循环", func(t *testing.T) {
+		p := OmitLeft(Char('x'), ZeroOrMore(Spaces()))
+		ds := Diagnose(p)
+		assert.Len(t, ds, 1)
+		assert.Equal(t, "OmitLeft[1] > ZeroOrMore", ds[0].Path)
+		assert.Contains(t, ds[0].Message, "would loop forever")
+	})
+
+	t.Run("正确的语法没有警告", func(t *testing.T) {
+		p := SepBy(OrElse(Str("<="), Str("<"), Trim(Digits())), Symbol(","))
+		assert.Empty(t, Diagnose(p))
+	})
+}
@@ -3,45 +3,43 @@ package parser
 
 // Maybe is a generic type that represents an optional value.
 // It can either hold a value (Just) or be empty (Nothing).
+// The value is stored inline rather than behind a pointer, so creating a Maybe never allocates;
+// this matters because every parser step returns one.
 type Maybe[T any] struct {
-	// value is a pointer to the underlying value. If nil, the Maybe is Nothing.
-	value *T
+	// value is the underlying value. It is the zero value of T if the Maybe is Nothing.
+	value T
+	// ok reports whether the Maybe holds a value.
+	ok bool
 }
 
 // Just creates a new Maybe instance that holds a value.
 // It takes a value of type T and returns a Maybe[T] containing that value.
 func Just[T any](value T) Maybe[T] {
-	return Maybe[T]{value: &value}
+	return Maybe[T]{value: value, ok: true}
 }
 
 // Nothing creates a new Maybe instance that represents the absence of a value.
-// It returns a Maybe[T] with a nil value pointer.
+// It returns the zero Maybe[T], which holds no value.
 func Nothing[T any]() Maybe[T] {
-	return Maybe[T]{value: nil}
+	return Maybe[T]{}
 }
 
 // Get retrieves the value from the Maybe instance.
 // If the Maybe is Nothing, it returns the zero value of type T.
 func (o Maybe[T]) Get() T {
-	if o.value == nil {
-		// Return the zero value of type T if the Maybe is Nothing.
-		var zero T
-		return zero
-	}
-	// Return the underlying value if the Maybe is Just.
-	return *o.value
+	return o.value
 }
 
 // IsJust checks if the Maybe instance contains a value.
 // It returns true if the Maybe is Just, false otherwise.
 func (o Maybe[T]) IsJust() bool {
-	return o.value != nil
+	return o.ok
 }
 
 // IsNothing checks if the Maybe instance is empty.
 // It returns true if the Maybe is Nothing, false otherwise.
 func (o Maybe[T]) IsNothing() bool {
-	return o.value == nil
+	return !o.ok
 }
 
 // Tuple is a generic type that represents a pair of values.
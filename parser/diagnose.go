@@ -0,0 +1,298 @@
+// Package parser provides static analysis of grammars built from the combinators.
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// kind classifies how a parser behaves for the purpose of grammar analysis.
+type kind uint8
+
+const (
+	// kindOpaque parsers cannot be inspected, e.g. Bind continuations, Lazy or custom parsers.
+	kindOpaque kind = iota
+	// kindPure parsers always succeed without consuming input.
+	kindPure
+	// kindFail parsers never succeed.
+	kindFail
+	// kindLiteral parsers match exactly their literal.
+	kindLiteral
+	// kindToken parsers consume at least one character if they succeed.
+	kindToken
+	// kindAssert parsers never consume input but may fail.
+	kindAssert
+	// kindMap parsers accept exactly what their only child accepts.
+	kindMap
+	// kindFilter parsers accept a subset of what their only child accepts.
+	kindFilter
+	// kindBind parsers start with their only child and continue with a parser chosen at run time.
+	kindBind
+	// kindSeq parsers run all children in order.
+	kindSeq
+	// kindAlt parsers return the first child that succeeds.
+	kindAlt
+	// kindMany parsers repeat their only child zero or more times.
+	kindMany
+	// kindMany1 parsers repeat their only child one or more times.
+	kindMany1
+	// kindOptional parsers run their only child at most once.
+	kindOptional
+	// kindSepBy parsers repeat their first child separated by their second.
+	kindSepBy
+)
+
+// info describes how a parser was built, so that grammars can be analyzed without running them.
+type info struct {
+	// name is the name of the constructor, used in diagnostic paths.
+	name string
+	// kind is the behavior of the parser.
+	kind kind
+	// literal is the text matched by a kindLiteral parser.
+	literal string
+	// children are the parsers this parser is built from.
+	children []*info
+}
+
+// describe attaches analysis metadata to p and returns it.
+func describe[T any](p Parser[T], name string, k kind, children ...*info) Parser[T] {
+	p.info = &info{name: name, kind: k, children: children}
+	return p
+}
+
+// infos returns the metadata of each parser in ps.
+func infos[T any](ps []Parser[T]) []*info {
+	is := make([]*info, len(ps))
+	for i, p := range ps {
+		is[i] = p.info
+	}
+	return is
+}
+
+// describeLiteral attaches analysis metadata for a parser matching exactly literal.
+func describeLiteral[T any](p Parser[T], name, literal string) Parser[T] {
+	p.info = &info{name: name, kind: kindLiteral, literal: literal}
+	return p
+}
+
+// Diagnostic is a potential problem found in a grammar by Diagnose.
+type Diagnostic struct {
+	// Path locates the offending parser by constructor names and child indexes, e.g. "Seq[1] > OrElse".
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// String returns the diagnostic as "path: message".
+func (d Diagnostic) String() string {
+	return d.Path + ": " + d.Message
+}
+
+// Diagnose inspects the grammar rooted at p and reports likely mistakes:
+//   - OrElse alternatives that can never be reached because an earlier alternative
+//     always succeeds or matches a prefix of everything the later one accepts;
+//   - repetitions wrapping a parser that can succeed without consuming input,
+//     which would loop forever.
+//
+// The analysis is conservative: parsers whose behavior is only known at run time,
+// such as Bind continuations, Lazy and custom parsers, are not inspected.
+func Diagnose[T any](p Parser[T]) []Diagnostic {
+	var ds []Diagnostic
+	visited := make(map[*info]bool)
+	var walk func(n *info, path string)
+	walk = func(n *info, path string) {
+		if n == nil || visited[n] {
+			return
+		}
+		visited[n] = true
+		ds = append(ds, check(n, path)...)
+		for i, c := range n.children {
+			if c != nil {
+				walk(c, fmt.Sprintf("%s[%d] > %s", path, i, c.name))
+			}
+		}
+	}
+	if p.info != nil {
+		walk(p.info, p.info.name)
+	}
+	return ds
+}
+
+// check reports the problems of a single parser.
+func check(n *info, path string) []Diagnostic {
+	var ds []Diagnostic
+	switch n.kind {
+	case kindMany, kindMany1:
+		if nullable(n.children[0]) == yes {
+			ds = append(ds, Diagnostic{Path: path, Message: n.name + " wraps a parser that can succeed without consuming input and would loop forever"})
+		}
+	case kindSepBy:
+		if nullable(n.children[0]) == yes && nullable(n.children[1]) == yes {
+			ds = append(ds, Diagnostic{Path: path, Message: "SepBy element and separator can both succeed without consuming input and would loop forever"})
+		}
+	case kindAlt:
+		for i, alt := range n.children {
+			for j, earlier := range n.children[:i] {
+				if always(earlier) {
+					ds = append(ds, Diagnostic{Path: path, Message: fmt.Sprintf("alternative %d is unreachable: alternative %d always succeeds", i, j)})
+					break
+				}
+				if lit, ok := exact(earlier); ok && strings.HasPrefix(prefix(alt), lit) {
+					ds = append(ds, Diagnostic{Path: path, Message: fmt.Sprintf("alternative %d is unreachable: alternative %d already matches its prefix %q", i, j, lit)})
+					break
+				}
+			}
+		}
+	}
+	return ds
+}
+
+// tri is a three-valued answer for properties that cannot always be decided statically.
+type tri uint8
+
+const (
+	unknown tri = iota
+	no
+	yes
+)
+
+// nullable reports whether the parser can succeed without consuming input.
+func nullable(n *info) tri {
+	if n == nil {
+		return unknown
+	}
+	switch n.kind {
+	case kindPure, kindMany, kindOptional, kindSepBy, kindAssert:
+		return yes
+	case kindFail, kindToken:
+		return no
+	case kindLiteral:
+		if n.literal == "" {
+			return yes
+		}
+		return no
+	case kindMap, kindFilter, kindMany1:
+		return nullable(n.children[0])
+	case kindBind:
+		if nullable(n.children[0]) == no {
+			return no
+		}
+		return unknown
+	case kindSeq:
+		result := yes
+		for _, c := range n.children {
+			switch nullable(c) {
+			case no:
+				return no
+			case unknown:
+				result = unknown
+			}
+		}
+		return result
+	case kindAlt:
+		result := no
+		for _, c := range n.children {
+			switch nullable(c) {
+			case yes:
+				return yes
+			case unknown:
+				result = unknown
+			}
+		}
+		return result
+	}
+	return unknown
+}
+
+// always reports whether the parser succeeds on every input.
+func always(n *info) bool {
+	if n == nil {
+		return false
+	}
+	switch n.kind {
+	case kindPure, kindMany, kindOptional, kindSepBy:
+		return true
+	case kindLiteral:
+		return n.literal == ""
+	case kindMap:
+		return always(n.children[0])
+	case kindSeq:
+		for _, c := range n.children {
+			if !always(c) {
+				return false
+			}
+		}
+		return true
+	case kindAlt:
+		for _, c := range n.children {
+			if always(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exact returns the literal a parser matches if it succeeds exactly on inputs starting with that literal.
+func exact(n *info) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	switch n.kind {
+	case kindLiteral:
+		return n.literal, true
+	case kindMap:
+		return exact(n.children[0])
+	case kindSeq:
+		var b strings.Builder
+		for _, c := range n.children {
+			lit, ok := exact(c)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(lit)
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// prefix returns a literal that every input accepted by the parser starts with, possibly empty.
+func prefix(n *info) string {
+	if n == nil {
+		return ""
+	}
+	if lit, ok := exact(n); ok {
+		return lit
+	}
+	switch n.kind {
+	case kindMap, kindFilter, kindMany1, kindBind:
+		return prefix(n.children[0])
+	case kindSeq:
+		var b strings.Builder
+		for _, c := range n.children {
+			lit, ok := exact(c)
+			if !ok {
+				b.WriteString(prefix(c))
+				break
+			}
+			b.WriteString(lit)
+		}
+		return b.String()
+	case kindAlt:
+		if len(n.children) == 0 {
+			return ""
+		}
+		common := prefix(n.children[0])
+		for _, c := range n.children[1:] {
+			p := prefix(c)
+			i := 0
+			for i < len(common) && i < len(p) && common[i] == p[i] {
+				i++
+			}
+			common = common[:i]
+		}
+		return common
+	}
+	return ""
+}
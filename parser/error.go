@@ -0,0 +1,158 @@
+// Package parser provides error reporting for failed parser runs.
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNoMatch is reported when the input does not match the parser.
+	ErrNoMatch = errors.New("no match")
+	// ErrUnexpectedEOF is reported when the input ends before the parser is satisfied.
+	ErrUnexpectedEOF = errors.New("unexpected end of input")
+	// ErrLimitExceeded is reported when a run is aborted by one of the limits in Options.
+	// Errors of type *LimitError match it with errors.Is.
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
+// Error describes why a parser failed on its input.
+// It points at the furthest position reached by any parser before the run failed,
+// which is usually the most precise location of the mistake in the input.
+type Error struct {
+	// Source names the input in the message: its format, e.g. "toml", or the name of the file it
+	// was read from. It is empty for the errors of Run, whose messages start with "parser".
+	Source string
+	// Offset is the byte offset of the failure in the input.
+	Offset int
+	// Line is the 1-based line number of the failure.
+	Line int
+	// Column is the 1-based column of the failure, counted in runes.
+	Column int
+	// Found describes the input at Offset; it is empty at the end of input.
+	Found string
+	// Expected lists what the parsers failing at Offset were looking for.
+	Expected []string
+	// Err is the underlying cause, either ErrNoMatch or ErrUnexpectedEOF.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "parser"
+	}
+	return fmt.Sprintf("%s: line %d, col %d: ", source, e.Line, e.Column) + Describe(e.Found, e.Expected, e.Err)
+}
+
+// Unwrap returns the underlying cause so that errors.Is matches ErrNoMatch and ErrUnexpectedEOF.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorAt returns an *Error of the given source for err at the byte offset of input, for failures
+// found after a successful run, e.g. a duplicate key. Found and Expected are left empty.
+func ErrorAt(source, input string, offset int, err error) *Error {
+	line, col := lineCol(input, offset)
+	return &Error{Source: source, Offset: offset, Line: line, Column: col, Err: err}
+}
+
+// Wrap returns a copy of the *Error in the chain of err with its Source set to source, so that
+// packages built on this one report their failures under their own name.
+// Other errors are returned unchanged.
+func Wrap(err error, source string) error {
+	var perr *Error
+	if !errors.As(err, &perr) {
+		return err
+	}
+	e := *perr
+	e.Source = source
+	return &e
+}
+
+// Describe describes a failure the way Error does after its position: "unexpected 'x', expected a,
+// b or c", or the message of err when found is empty, at the end of input or for failures reported
+// by ErrorAt.
+func Describe(found string, expected []string, err error) string {
+	msg := err.Error()
+	if found != "" {
+		msg = "unexpected " + found
+	}
+	if len(expected) > 0 {
+		msg += ", expected " + joinExpected(expected)
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) report true for every *LimitError.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Run parses the whole of input with p and returns the parsed value.
+// Unlike Parse, it fails if any input is left over after p succeeds.
+// On failure the returned error is an *Error wrapping ErrNoMatch or ErrUnexpectedEOF.
+func Run[T any](p Parser[T], input string) (T, error) {
+	return RunWith(p, input, Options{})
+}
+
+// RunWith is like Run but enforces the limits in opts.
+// If a limit is exceeded the returned error is a *LimitError.
+func RunWith[T any](p Parser[T], input string, opts Options) (T, error) {
+	return run(p, newState(input, opts))
+}
+
+// run parses the remaining input of st with p, which must consume all of it, like RunWith.
+func run[T any](p Parser[T], st State) (T, error) {
+	m := OmitRight(p, EOF()).run(st)
+	if st.aborted() {
+		var zero T
+		return zero, st.ctx.err
+	}
+	if m.IsNothing() {
+		var zero T
+		return zero, st.ctx.newError()
+	}
+	return m.Get().First, nil
+}
+
+// newError builds an *Error from the furthest failure recorded during the run.
+func (ctx *context) newError() *Error {
+	pos := max(ctx.failPos, 0)
+	line, col := lineCol(ctx.input, pos)
+	e := &Error{
+		Offset:   pos,
+		Line:     line,
+		Column:   col,
+		Expected: slices.Clone(ctx.expected),
+		Err:      ErrNoMatch,
+	}
+	if pos >= len(ctx.input) {
+		e.Err = ErrUnexpectedEOF
+	} else {
+		r, _ := utf8.DecodeRuneInString(ctx.input[pos:])
+		e.Found = strconv.QuoteRune(r)
+	}
+	return e
+}
+
+// lineCol converts a byte offset into a 1-based line and rune column.
+func lineCol(input string, offset int) (int, int) {
+	before := input[:offset]
+	line := strings.Count(before, "\n") + 1
+	start := strings.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCountInString(before[start:]) + 1
+}
+
+// joinExpected formats a list of alternatives as "a, b or c".
+func joinExpected(expected []string) string {
+	if len(expected) == 1 {
+		return expected[0]
+	}
+	return strings.Join(expected[:len(expected)-1], ", ") + " or " + expected[len(expected)-1]
+}
@@ -0,0 +1,79 @@
+// Package parser provides a driver for parsing input that arrives in chunks.
+package parser
+
+// Incremental drives a parser over input that arrives over time, e.g. from a network connection.
+// Input is supplied with Feed; the parse suspends while the buffered input is not
+// enough to decide the result and completes as soon as more input cannot change it.
+//
+// Each call to Feed re-runs the parser over everything buffered so far, so the
+// driver suits messages of moderate size delivered in a handful of chunks.
+type Incremental[T any] struct {
+	p    Parser[T]
+	opts Options
+	buf  string
+	done bool
+	val  T
+	rest string
+	err  error
+}
+
+// NewIncremental creates a driver that runs p over chunked input, enforcing the limits in opts.
+func NewIncremental[T any](p Parser[T], opts Options) *Incremental[T] {
+	return &Incremental[T]{p: p, opts: opts}
+}
+
+// Feed appends chunk to the buffered input and tries to complete the parse.
+// It reports true once the parser has produced a value that more input cannot change,
+// and false while it needs more input. A non-nil error means the input can never match,
+// or that a limit was exceeded. Feeding a driver that is done or failed has no effect.
+func (in *Incremental[T]) Feed(chunk string) (bool, error) {
+	if in.done || in.err != nil {
+		return in.done, in.err
+	}
+	in.buf += chunk
+	st := newState(in.buf, in.opts)
+	m := in.p.run(st)
+	switch {
+	case st.aborted():
+		in.err = st.ctx.err
+	case st.ctx.hitEnd:
+		// The parser looked past the end of the buffer; wait for more input.
+	case m.IsNothing():
+		in.err = st.ctx.newError()
+	default:
+		in.finish(m.Get())
+	}
+	return in.done, in.err
+}
+
+// Finish signals that no more input will arrive and returns the parsed value.
+// Unlike Feed, running out of input is now final, so the parser either matches
+// the buffered input or fails with an *Error.
+func (in *Incremental[T]) Finish() (T, error) {
+	if !in.done && in.err == nil {
+		st := newState(in.buf, in.opts)
+		m := in.p.run(st)
+		switch {
+		case st.aborted():
+			in.err = st.ctx.err
+		case m.IsNothing():
+			in.err = st.ctx.newError()
+		default:
+			in.finish(m.Get())
+		}
+	}
+	return in.val, in.err
+}
+
+// Rest returns the buffered input left over after the parsed value, e.g. the start
+// of the next message. It is empty until the parse is done.
+func (in *Incremental[T]) Rest() string {
+	return in.rest
+}
+
+// finish stores the result of a completed parse.
+func (in *Incremental[T]) finish(t Tuple[T, State]) {
+	in.done = true
+	in.val = t.First
+	in.rest = t.Second.input
+}
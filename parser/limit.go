@@ -0,0 +1,58 @@
+// Package parser provides resource limits for running parsers on untrusted input.
+package parser
+
+import "fmt"
+
+// Limit names one of the resource limits that can be configured in Options.
+type Limit string
+
+const (
+	// LimitDepth is the limit on the nesting depth entered through Nested.
+	LimitDepth Limit = "depth"
+	// LimitBytes is the limit on the number of input bytes consumed.
+	LimitBytes Limit = "bytes"
+)
+
+// Options configures the limits enforced while running a parser.
+// A zero value for a field means the corresponding limit is disabled.
+type Options struct {
+	// MaxDepth is the maximum nesting depth of parsers wrapped in Nested.
+	MaxDepth int
+	// MaxBytes is the maximum number of input bytes a parser may consume.
+	MaxBytes int
+}
+
+// LimitError reports that a parse was aborted because it exceeded one of the limits in Options.
+type LimitError struct {
+	// Limit is the limit that was exceeded.
+	Limit Limit
+	// Max is the configured value of the limit.
+	Max int
+	// Offset is the byte offset in the input at which the limit was exceeded.
+	Offset int
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitDepth:
+		return fmt.Sprintf("parser: maximum nesting depth of %d exceeded at offset %d", e.Max, e.Offset)
+	case LimitBytes:
+		return fmt.Sprintf("parser: maximum input size of %d bytes exceeded at offset %d", e.Max, e.Offset)
+	default:
+		return fmt.Sprintf("parser: %s limit of %d exceeded at offset %d", e.Limit, e.Max, e.Offset)
+	}
+}
+
+// Nested marks p as one level of nesting, e.g. the body of an array or object.
+// When the run was started with a MaxDepth limit and p consumes input beyond it,
+// the whole parse is aborted with a *LimitError instead of recursing further.
+func Nested[T any](p Parser[T]) Parser[T] {
+	return describe(newParser(func(st State) StateFuncRet[T] {
+		ctx := st.ctx
+		ctx.depth++
+		m := p.run(st)
+		ctx.depth--
+		return m
+	}), "Nested", kindMap, p.info)
+}
@@ -0,0 +1,151 @@
+// Package parser provides helpers for line-oriented formats.
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Line is a single line of a line-oriented input.
+type Line struct {
+	// Number is the 1-based line number in the input.
+	Number int
+	// Offset is the byte offset of Text in the input.
+	Offset int
+	// Text is the content of the line without the line terminator, "\n" or "\r\n".
+	Text string
+}
+
+// LineError reports a line that was rejected for a reason that has no position within the line,
+// e.g. a value of the wrong type.
+type LineError struct {
+	// Line is the 1-based number of the offending line.
+	Line int
+	// Text is the content of the offending line.
+	Text string
+	// Err is the reason the line was rejected.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %q: %v", e.Line, e.Text, e.Err)
+}
+
+// Unwrap returns the reason the line was rejected.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineOptions configures how ParseLines splits and filters its input.
+type LineOptions struct {
+	// TrimSpace removes leading and trailing whitespace from every line before it is parsed.
+	TrimSpace bool
+	// SkipBlank skips lines that are empty after trimming.
+	SkipBlank bool
+	// CommentPrefixes lists prefixes that mark a line as a comment to be skipped.
+	CommentPrefixes []string
+	// Source is the Source of the errors reported by ParseLines, e.g. "hosts".
+	Source string
+}
+
+// Lines splits input into numbered lines, applying the trimming and filtering of opts.
+func Lines(input string, opts LineOptions) []Line {
+	var lines []Line
+	for n, offset := 1, 0; ; n++ {
+		text, rest, found := strings.Cut(input[offset:], "\n")
+		if l, ok := opts.line(n, offset, text); ok {
+			lines = append(lines, l)
+		}
+		if !found {
+			return lines
+		}
+		offset = len(input) - len(rest)
+	}
+}
+
+// ReadLines returns an iterator over the numbered lines read from r, applying the trimming and
+// filtering of opts like Lines. An error reading r ends the iteration.
+func ReadLines(r io.Reader, opts LineOptions) iter.Seq2[Line, error] {
+	return func(yield func(Line, error) bool) {
+		br := bufio.NewReader(r)
+		for n, offset := 1, 0; ; n++ {
+			text, err := br.ReadString('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				yield(Line{}, err)
+				return
+			}
+			if l, ok := opts.line(n, offset, strings.TrimSuffix(text, "\n")); ok && !yield(l, nil) {
+				return
+			}
+			if err != nil {
+				return
+			}
+			offset += len(text)
+		}
+	}
+}
+
+// line returns line n, whose text starts at offset and ends before its "\n", after trimming it
+// according to opts, and whether opts keeps it.
+func (opts LineOptions) line(n, offset int, text string) (Line, bool) {
+	text = strings.TrimSuffix(text, "\r")
+	if opts.TrimSpace {
+		trimmed := strings.TrimLeft(text, " \t\r\v\f")
+		offset += len(text) - len(trimmed)
+		text = strings.TrimSpace(trimmed)
+	}
+	if opts.SkipBlank && strings.TrimSpace(text) == "" || isComment(text, opts.CommentPrefixes) {
+		return Line{}, false
+	}
+	return Line{Number: n, Offset: offset, Text: text}, true
+}
+
+// RunLine parses the text of the line l of input with p like Run, but reports a failure at its
+// position in input rather than in the line.
+func RunLine[T any](p Parser[T], input string, l Line) (T, error) {
+	end := l.Offset + len(l.Text)
+	st := newState(input[:end], Options{})
+	st.input = input[l.Offset:end]
+	return run(p, st)
+}
+
+// ParseLines parses input line by line and accumulates the results.
+// Every line kept by opts must be matched entirely by p; the value is then passed
+// to step together with the accumulator, which returns the new accumulator or an
+// error rejecting the line. Lines that fail are reported as *Error values with the
+// Source of opts and their position in input, and skipped, so all problems in the
+// input are reported at once.
+func ParseLines[T, S any](input string, opts LineOptions, p Parser[T], init S, step func(S, Line, T) (S, error)) (S, []error) {
+	acc := init
+	var errs []error
+	for _, line := range Lines(input, opts) {
+		v, err := RunLine(p, input, line)
+		if err != nil {
+			errs = append(errs, Wrap(err, opts.Source))
+			continue
+		}
+		next, err := step(acc, line, v)
+		if err != nil {
+			errs = append(errs, ErrorAt(opts.Source, input, line.Offset, err))
+			continue
+		}
+		acc = next
+	}
+	return acc, errs
+}
+
+// isComment reports whether text starts with one of the comment prefixes, ignoring leading whitespace.
+func isComment(text string, prefixes []string) bool {
+	text = strings.TrimLeft(text, " \t")
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// Package parser provides the parsing state threaded through parsers.
+package parser
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// State is the input a parser runs against, paired with the bookkeeping shared
+// by every parser taking part in the same run.
+type State struct {
+	// input is the remaining, not yet consumed input.
+	input string
+	// ctx holds the options and counters of the current run.
+	ctx *context
+}
+
+// context is the per-run data shared by all states derived from the same input.
+type context struct {
+	// opts are the limits the run was started with.
+	opts Options
+	// input is the complete input of the run.
+	input string
+	// depth is the current nesting depth entered through Nested.
+	depth int
+	// err is set when the run has been aborted, e.g. because a limit was exceeded.
+	err error
+	// failPos is the furthest byte offset at which a parser has failed, or -1.
+	failPos int
+	// expected lists what the parsers failing at failPos were looking for.
+	expected []string
+	// memo caches the results of Memo parsers by parser and position.
+	memo map[memoKey]any
+	// hitEnd is set when a parser needed to look past the end of the input,
+	// meaning that more input could have changed the result of the run.
+	hitEnd bool
+}
+
+// memoKey identifies the result of one Memo parser at one position.
+type memoKey struct {
+	id  *int
+	pos int
+}
+
+// newState creates the initial state for running a parser over s.
+func newState(s string, opts Options) State {
+	return State{input: s, ctx: &context{opts: opts, input: s, failPos: -1}}
+}
+
+// Input returns the remaining, not yet consumed input.
+func (st State) Input() string {
+	return st.input
+}
+
+// Offset returns the byte offset of the state within the complete input.
+func (st State) Offset() int {
+	return st.pos()
+}
+
+// Advance returns the state after consuming n bytes of the remaining input.
+// It reports false if consuming the input exceeds one of the limits of the run,
+// in which case the parser must fail.
+func (st State) Advance(n int) (State, bool) {
+	return st.advance(n)
+}
+
+// Fail records that the parser failed at this state while looking for expected,
+// e.g. "hex digit", so that the failure shows up in the error returned by Run.
+func (st State) Fail(expected string) {
+	st.fail(expected)
+}
+
+// Truncated records that the parser needed more input than remains, e.g. a fixed-size
+// field cut short. The failure is reported at the end of the input, so the error returned
+// by Run wraps ErrUnexpectedEOF, and the Incremental driver waits for more input.
+func (st State) Truncated(expected string) {
+	st.hitEnd()
+	State{ctx: st.ctx}.fail(expected)
+}
+
+// pos returns the byte offset of the state within the complete input.
+func (st State) pos() int {
+	return len(st.ctx.input) - len(st.input)
+}
+
+// prev returns the rune immediately before the state in the complete input.
+// It reports false at the start of the input.
+func (st State) prev() (rune, bool) {
+	before := st.ctx.input[:st.pos()]
+	if before == "" {
+		return 0, false
+	}
+	r, _ := utf8.DecodeLastRuneInString(before)
+	return r, true
+}
+
+// aborted reports whether the run has been aborted.
+// Parsers that backtrack must not try alternatives once this is true.
+func (st State) aborted() bool {
+	return st.ctx.err != nil
+}
+
+// abort stops the run with the given error. Only the first error is kept.
+func (st State) abort(err error) {
+	if st.ctx.err == nil {
+		st.ctx.err = err
+	}
+}
+
+// fail records that a parser failed at the current position while looking for
+// expected, which may be empty if the parser has no description.
+// Only the failures at the furthest position reached are kept for error reporting.
+func (st State) fail(expected string) {
+	ctx := st.ctx
+	pos := st.pos()
+	if pos < ctx.failPos {
+		return
+	}
+	if pos > ctx.failPos {
+		ctx.failPos = pos
+		ctx.expected = ctx.expected[:0]
+	}
+	if expected == "" || slices.Contains(ctx.expected, expected) {
+		return
+	}
+	ctx.expected = append(ctx.expected, expected)
+}
+
+// failSpan records a failure at the current position for a construct that was parsed up to end
+// but then rejected. Failures recorded inside the construct are discarded, since they only
+// describe how it could have continued, not why it was rejected.
+func (st State) failSpan(end State, expected string) {
+	ctx := st.ctx
+	if ctx.failPos <= end.pos() {
+		ctx.failPos = -1
+		ctx.expected = ctx.expected[:0]
+	}
+	st.fail(expected)
+}
+
+// hitEnd records that a parser ran out of input while deciding whether it matches.
+func (st State) hitEnd() {
+	st.ctx.hitEnd = true
+}
+
+// advance returns the state after consuming n bytes of input.
+// It reports false, and aborts the run, if consuming input at the current
+// nesting depth or up to the new position exceeds one of the limits.
+// Limits are checked on consumption rather than when entering Nested, so that
+// alternatives which are merely tried at a deeper level do not abort the run.
+func (st State) advance(n int) (State, bool) {
+	ctx := st.ctx
+	if max := ctx.opts.MaxDepth; max > 0 && ctx.depth > max {
+		st.abort(&LimitError{Limit: LimitDepth, Max: max, Offset: st.pos()})
+		return st, false
+	}
+	next := State{input: st.input[n:], ctx: ctx}
+	if max := ctx.opts.MaxBytes; max > 0 && next.pos() > max {
+		st.abort(&LimitError{Limit: LimitBytes, Max: max, Offset: st.pos()})
+		return st, false
+	}
+	return next, true
+}
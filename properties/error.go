@@ -0,0 +1,8 @@
+// Package properties provides error reporting for malformed properties files.
+package properties
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a properties file could not be parsed. Expected lists what the file
+// should contain at the error, e.g. "hex digit".
+type ParseError = parser.Error
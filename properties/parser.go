@@ -0,0 +1,170 @@
+// Package properties provides the grammar of properties files, built with the tiny-parsec
+// combinators.
+package properties
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func join(ss []string) string {
+	return strings.Join(ss, "")
+}
+
+// isBlank reports whether r is whitespace within a line.
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\f'
+}
+
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+// blanks parses any whitespace within a line.
+func blanks() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isBlank))
+}
+
+// newline parses a line break: "\r\n", "\r" or "\n".
+func newline() parser.Parser[string] {
+	return parser.OrElse(parser.Str("\r\n"), parser.Str("\r"), parser.Str("\n"))
+}
+
+// lineEnd parses a line break or the end of the input.
+func lineEnd() parser.Parser[struct{}] {
+	return parser.OrElse(parser.Fmap(newline(), func(string) struct{} { return struct{}{} }), parser.EOF())
+}
+
+// more succeeds without consuming input unless the input is exhausted, so that the lines of a
+// file are not repeated forever at its end. It records no failure.
+func more() parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		if st.Input() == "" {
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+}
+
+// hex4 parses the four hex digits of a \uXXXX escape.
+func hex4() parser.Parser[uint16] {
+	hex := parser.SatisfyMsg(isHexDigit, "hex digit")
+	return parser.Fmap(parser.Seq(hex, hex, hex, hex), func(rs []rune) uint16 {
+		v, _ := strconv.ParseUint(parser.Text(rs), 16, 16)
+		return uint16(v)
+	})
+}
+
+// unicode parses the hex digits of a \uXXXX escape following the 'u'. A high surrogate followed
+// by the escape of a low surrogate makes a single character; a lone surrogate is replaced by
+// U+FFFD.
+func unicode() parser.Parser[string] {
+	return parser.Bind(hex4(), func(c uint16) parser.Parser[string] {
+		if !utf16.IsSurrogate(rune(c)) {
+			return parser.Pure(string(rune(c)))
+		}
+		low := parser.SatisfyWith(parser.OmitLeft(parser.Str(`\u`), hex4()), func(d uint16) bool {
+			return utf16.DecodeRune(rune(c), rune(d)) != utf8.RuneError
+		})
+		return parser.OrElse(
+			parser.Fmap(low, func(d uint16) string { return string(utf16.DecodeRune(rune(c), rune(d))) }),
+			parser.Pure(string(utf8.RuneError)),
+		)
+	})
+}
+
+// continuation parses the line break following a backslash, which continues the logical line,
+// and the leading whitespace of the next line. It returns the empty string.
+func continuation() parser.Parser[string] {
+	return parser.Fmap(parser.OmitRight(newline(), blanks()), func(string) string { return "" })
+}
+
+// escape parses a backslash and what follows it: a continuation, a \uXXXX escape, one of \t, \n,
+// \r and \f, or any other character standing for itself. A backslash at the end of the input is
+// dropped.
+func escape() parser.Parser[string] {
+	// A 'u' always starts a \uXXXX escape, so that a malformed one is reported.
+	char := parser.Fmap(parser.Satisfy(func(r rune) bool { return r != 'u' }), func(r rune) string {
+		switch r {
+		case 't':
+			return "\t"
+		case 'n':
+			return "\n"
+		case 'r':
+			return "\r"
+		case 'f':
+			return "\f"
+		}
+		return string([]byte{byte(r)})
+	})
+	return parser.OmitLeft(parser.Char('\\'), parser.OrElse(
+		continuation(),
+		parser.OmitLeft(parser.Char('u'), unicode()),
+		parser.Fmap(parser.EOF(), func(struct{}) string { return "" }),
+		char,
+	))
+}
+
+// chars parses escapes and the characters accepted by f.
+func chars(f func(rune) bool) parser.Parser[string] {
+	plain := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool {
+		return r != '\\' && r != '\r' && r != '\n' && f(r)
+	})), parser.Text)
+	return parser.Fmap(parser.ZeroOrMore(parser.OrElse(escape(), plain)), join)
+}
+
+// line is a comment line or an entry.
+type line struct {
+	offset  int
+	comment string
+	entry   *Entry
+}
+
+// separator parses what separates a key from its value: whitespace, an '=' or a ':', or both,
+// possibly spread over continuation lines.
+func separator() parser.Parser[struct{}] {
+	skip := parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(parser.Satisfy(isBlank), func(rune) string { return "" }),
+		parser.OmitLeft(parser.Char('\\'), continuation()),
+	))
+	mark := parser.ZeroOrOne(parser.OrElse(parser.Char('='), parser.Char(':')))
+	return parser.Fmap(parser.OmitLeft(skip, parser.OmitRight(mark, skip)), func(parser.Maybe[rune]) struct{} { return struct{}{} })
+}
+
+// lines parses the comment lines and entries of a file, skipping blank lines. Each line may be
+// indented. A comment line starts with '#' or '!', which is kept in the comment.
+func lines() parser.Parser[[]line] {
+	comment := parser.Fmap(
+		parser.Seq(
+			parser.Fmap(parser.OrElse(parser.Char('#'), parser.Char('!')), func(r rune) []rune { return []rune{r} }),
+			parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return r != '\r' && r != '\n' })),
+		),
+		func(parts [][]rune) line { return line{comment: parser.Text(append(parts[0], parts[1]...))} },
+	)
+	// A line with nothing but whitespace is blank; blank is checked before entry, which would
+	// read it as an entry with an empty key.
+	blank := parser.NewStateParser(func(st parser.State) parser.StateFuncRet[line] {
+		if s := st.Input(); s != "" && s[0] != '\r' && s[0] != '\n' {
+			return parser.Nothing[parser.Tuple[line, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(line{}, st))
+	})
+	key := chars(func(r rune) bool { return !isBlank(r) && r != '=' && r != ':' })
+	entry := parser.Bind(parser.OmitRight(key, separator()), func(k string) parser.Parser[line] {
+		return parser.Fmap(chars(func(rune) bool { return true }), func(v string) line {
+			return line{entry: &Entry{Key: k, Value: v}}
+		})
+	})
+	body := parser.OrElse(comment, blank, entry)
+	item := parser.Bind(parser.OmitLeft(more(), parser.OmitLeft(blanks(), parser.Pos())), func(offset int) parser.Parser[line] {
+		return parser.Fmap(parser.OmitRight(body, lineEnd()), func(l line) line {
+			l.offset = offset
+			return l
+		})
+	})
+	return parser.ZeroOrMore(item)
+}
@@ -0,0 +1,143 @@
+// Package properties parses and writes Java properties files, the key-value format read by
+// java.util.Properties:
+//
+//	# Database settings
+//	db.url = jdbc:postgresql://localhost/app
+//	db.user: admin
+//	greeting = Hello, \
+//	           world
+//	title = Café
+//
+// A key is separated from its value by '=', ':' or whitespace. A backslash escapes the next
+// character, introduces a \uXXXX escape, or at the end of a line continues the value on the next
+// line, whose leading whitespace is skipped. Lines starting with '#' or '!' are comments.
+//
+// Parse keeps the entries in order along with the comments above them, and Write writes them
+// back with the escapes Parse needs to read the same entries.
+package properties
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Entry is a key-value pair.
+type Entry struct {
+	// Comments are the comment lines above the entry, as written with their '#' or '!'.
+	Comments []string
+	// Key is the unescaped key.
+	Key string
+	// Value is the unescaped value, joined from all its continuation lines.
+	Value string
+	// Line is the 1-based line number of the entry in the source; it is 0 for an entry added by Set.
+	Line int
+}
+
+// Properties is the content of a properties file.
+type Properties struct {
+	// Entries are the entries in the order of the file. A key may appear more than once, in which
+	// case the last entry wins, as with java.util.Properties.
+	Entries []Entry
+	// Comments are the comment lines after the last entry.
+	Comments []string
+}
+
+// Parse parses the properties file s. The only malformed input is a \u escape not followed by
+// four hex digits; anything else is a comment, a blank line or an entry.
+func Parse(s string) (*Properties, error) {
+	ls, err := parser.Run(lines(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "properties")
+	}
+	p := &Properties{}
+	var comments []string
+	for _, l := range ls {
+		switch {
+		case l.entry != nil:
+			e := *l.entry
+			e.Comments = comments
+			e.Line = lineOf(s, l.offset)
+			p.Entries = append(p.Entries, e)
+			comments = nil
+		case l.comment != "":
+			comments = append(comments, l.comment)
+		}
+	}
+	p.Comments = comments
+	return p, nil
+}
+
+// lineOf returns the 1-based number of the line at the byte offset of s, counting "\r\n", "\r"
+// and "\n" as line breaks.
+func lineOf(s string, offset int) int {
+	before := s[:offset]
+	return strings.Count(before, "\n") + strings.Count(before, "\r") - strings.Count(before, "\r\n") + 1
+}
+
+// index returns the index of the last entry with the key, or -1 if there is none.
+func (p *Properties) index(key string) int {
+	for i := len(p.Entries) - 1; i >= 0; i-- {
+		if p.Entries[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value of the last entry with the key, and false if there is none.
+func (p *Properties) Get(key string) (string, bool) {
+	if i := p.index(key); i >= 0 {
+		return p.Entries[i].Value, true
+	}
+	return "", false
+}
+
+// Set sets the value of the last entry with the key, or appends an entry if there is none.
+func (p *Properties) Set(key, value string) {
+	if i := p.index(key); i >= 0 {
+		p.Entries[i].Value = value
+		return
+	}
+	p.Entries = append(p.Entries, Entry{Key: key, Value: value})
+}
+
+// Delete removes all the entries with the key, and reports whether there was any.
+func (p *Properties) Delete(key string) bool {
+	n := len(p.Entries)
+	p.Entries = slices.DeleteFunc(p.Entries, func(e Entry) bool { return e.Key == key })
+	return len(p.Entries) < n
+}
+
+// Keys returns the distinct keys in the order they first appear.
+func (p *Properties) Keys() []string {
+	seen := make(map[string]bool, len(p.Entries))
+	var keys []string
+	for _, e := range p.Entries {
+		if !seen[e.Key] {
+			seen[e.Key] = true
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// Map returns the values by key, the last entry winning for repeated keys.
+func (p *Properties) Map() map[string]string {
+	m := make(map[string]string, len(p.Entries))
+	for _, e := range p.Entries {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// FromMap returns properties with the entries of m, sorted by key.
+func FromMap(m map[string]string) *Properties {
+	p := &Properties{}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		p.Entries = append(p.Entries, Entry{Key: k, Value: m[k]})
+	}
+	return p
+}
@@ -0,0 +1,109 @@
+package properties_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/properties"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []properties.Entry
+	}{
+		{"equals", "a=1", []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"colon", "a : 1", []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"whitespace", "a   1 2", []properties.Entry{{Key: "a", Value: "1 2", Line: 1}}},
+		{"whitespace then equals", "a \t= \f1", []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"second separator kept", "a==1", []properties.Entry{{Key: "a", Value: "=1", Line: 1}}},
+		{"trailing whitespace kept", "a = 1  ", []properties.Entry{{Key: "a", Value: "1  ", Line: 1}}},
+		{"indented", "   a = 1", []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"key only", "a", []properties.Entry{{Key: "a", Line: 1}}},
+		{"empty key", "= 1", []properties.Entry{{Key: "", Value: "1", Line: 1}}},
+		{"escaped key", `a\ b\=c\:d = 1`, []properties.Entry{{Key: "a b=c:d", Value: "1", Line: 1}}},
+		{"escapes", `a = \t\n\r\f\\\x\#`, []properties.Entry{{Key: "a", Value: "\t\n\r\f\\x#", Line: 1}}},
+		{"leading space escaped", `a = \  b`, []properties.Entry{{Key: "a", Value: "  b", Line: 1}}},
+		{"unicode escapes", `a = caf\u00e9 \uD83D\uDE00`, []properties.Entry{{Key: "a", Value: "café 😀", Line: 1}}},
+		{"lone surrogate", `a = \uD83Dx`, []properties.Entry{{Key: "a", Value: "\uFFFDx", Line: 1}}},
+		{"utf-8", "ключ = значение", []properties.Entry{{Key: "ключ", Value: "значение", Line: 1}}},
+		{"continuation", "a = one, \\\n    two, \\\r\n\tthree", []properties.Entry{{Key: "a", Value: "one, two, three", Line: 1}}},
+		{"continuation in key", "lo\\\n  ng = 1", []properties.Entry{{Key: "long", Value: "1", Line: 1}}},
+		{"continuation before value", "a = \\\n  1", []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"escaped backslash ends line", "a = 1\\\\\nb = 2", []properties.Entry{{Key: "a", Value: `1\`, Line: 1}, {Key: "b", Value: "2", Line: 2}}},
+		{"backslash at end", `a = 1\`, []properties.Entry{{Key: "a", Value: "1", Line: 1}}},
+		{"continued comment marker", "a = 1\\\n# not a comment", []properties.Entry{{Key: "a", Value: "1# not a comment", Line: 1}}},
+		{
+			"comments and blank lines",
+			"# one\n\n  ! two\na = 1\n\nb = 2 # not a comment\n",
+			[]properties.Entry{
+				{Comments: []string{"# one", "! two"}, Key: "a", Value: "1", Line: 4},
+				{Key: "b", Value: "2 # not a comment", Line: 6},
+			},
+		},
+		{"line breaks", "a = 1\rb = 2\r\nc = 3\n\n  \nd = 4", []properties.Entry{
+			{Key: "a", Value: "1", Line: 1}, {Key: "b", Value: "2", Line: 2}, {Key: "c", Value: "3", Line: 3}, {Key: "d", Value: "4", Line: 6},
+		}},
+		{"empty", "", nil},
+		{"blank", "  \n\t\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := properties.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.Entries)
+		})
+	}
+
+	t.Run("trailing comments", func(t *testing.T) {
+		p, err := properties.Parse("a = 1\n# end\n!done")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"# end", "!done"}, p.Comments)
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{`a = \u12g4`, parser.ErrNoMatch, "properties: line 1, col 9: unexpected 'g', expected hex digit"},
+		{"a = 1\nb = \\u00", parser.ErrUnexpectedEOF, "properties: line 2, col 9: unexpected end of input, expected hex digit"},
+		{`k\u = 1`, parser.ErrNoMatch, "properties: line 1, col 4: unexpected ' ', expected hex digit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := properties.Parse(tt.input)
+			var perr *properties.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.EqualError(t, err, tt.msg)
+		})
+	}
+}
+
+func TestAccess(t *testing.T) {
+	p, err := properties.Parse("a = 1\nb = 2\na = 3\n")
+	assert.NoError(t, err)
+
+	v, ok := p.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "3", v)
+	_, ok = p.Get("c")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, p.Keys())
+	assert.Equal(t, map[string]string{"a": "3", "b": "2"}, p.Map())
+
+	p.Set("a", "4")
+	p.Set("c", "5")
+	assert.Equal(t, "a = 1\nb = 2\na = 4\nc = 5\n", p.String())
+
+	assert.True(t, p.Delete("a"))
+	assert.False(t, p.Delete("a"))
+	assert.Equal(t, "b = 2\nc = 5\n", p.String())
+
+	assert.Equal(t, "x = 1\ny = 2\n", properties.FromMap(map[string]string{"y": "2", "x": "1"}).String())
+}
@@ -0,0 +1,113 @@
+// Package properties provides writing properties files back as text.
+package properties
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteOptions controls the text written by Write.
+type WriteOptions struct {
+	// Separator is written between each key and its value; " = " if empty. It should consist of
+	// whitespace and at most one '=' or ':', so that Parse reads it as a separator.
+	Separator string
+	// ASCII escapes the characters outside of printable ASCII as \uXXXX, as
+	// java.util.Properties.store does, for files read as ISO 8859-1 rather than UTF-8.
+	ASCII bool
+}
+
+// Write writes the properties to w: each entry on its own line after its comments, then the
+// trailing comments. Keys and values are escaped so that Parse reads back the same entries;
+// line breaks in values are written as \n, so that each entry takes a single line.
+func Write(w io.Writer, p *Properties, opts WriteOptions) error {
+	_, err := io.WriteString(w, write(p, opts))
+	return err
+}
+
+// String returns the text of the properties with the default options.
+func (p *Properties) String() string {
+	return write(p, WriteOptions{})
+}
+
+// write returns the text of the properties written according to opts.
+func write(p *Properties, opts WriteOptions) string {
+	if opts.Separator == "" {
+		opts.Separator = " = "
+	}
+	var b strings.Builder
+	for _, e := range p.Entries {
+		writeComments(&b, e.Comments)
+		b.WriteString(quote(e.Key, true, opts.ASCII))
+		if e.Value == "" {
+			b.WriteString(strings.TrimRight(opts.Separator, " \t\f"))
+		} else {
+			b.WriteString(opts.Separator)
+			b.WriteString(quote(e.Value, false, opts.ASCII))
+		}
+		b.WriteByte('\n')
+	}
+	writeComments(&b, p.Comments)
+	return b.String()
+}
+
+// writeComments writes comment lines, adding a "# " marker to those without one.
+func writeComments(b *strings.Builder, comments []string) {
+	for _, c := range comments {
+		for _, l := range strings.Split(c, "\n") {
+			if !strings.HasPrefix(l, "#") && !strings.HasPrefix(l, "!") {
+				b.WriteString("# ")
+			}
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+	}
+}
+
+// quote escapes a key or a value. In a key, whitespace and the characters that would end it or
+// start a comment are escaped; in a value, only its leading whitespace needs to be.
+func quote(s string, key, ascii bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case ' ':
+			if key || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(' ')
+		case '=', ':', '#', '!':
+			if key {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			if ascii && (r < 0x20 || r > 0x7e) {
+				writeUnicode(&b, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// writeUnicode writes r as a \uXXXX escape, or as the escapes of a surrogate pair beyond the
+// Basic Multilingual Plane.
+func writeUnicode(b *strings.Builder, r rune) {
+	if r > 0xffff {
+		r -= 0x10000
+		fmt.Fprintf(b, `\u%04X\u%04X`, 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+		return
+	}
+	fmt.Fprintf(b, `\u%04X`, r)
+}
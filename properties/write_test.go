@@ -0,0 +1,66 @@
+package properties_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/81120/tiny-parsec/properties"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	p := &properties.Properties{
+		Entries: []properties.Entry{
+			{Comments: []string{"# Settings", "plain comment"}, Key: "name", Value: "app"},
+			{Key: "key with spaces=:#!", Value: "  leading, trailing  "},
+			{Key: "multi", Value: "one\ntwo\tthree\\"},
+			{Key: "title", Value: "Café 😀"},
+			{Key: "empty"},
+		},
+		Comments: []string{"! end"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     properties.WriteOptions
+		expected string
+	}{
+		{
+			"default",
+			properties.WriteOptions{},
+			"# Settings\n# plain comment\nname = app\n" +
+				`key\ with\ spaces\=\:\#\! = \  leading, trailing  ` + "\n" +
+				`multi = one\ntwo\tthree\\` + "\n" +
+				"title = Café 😀\nempty =\n! end\n",
+		},
+		{
+			"separator and ascii",
+			properties.WriteOptions{Separator: ":", ASCII: true},
+			"# Settings\n# plain comment\nname:app\n" +
+				`key\ with\ spaces\=\:\#\!:\  leading, trailing  ` + "\n" +
+				`multi:one\ntwo\tthree\\` + "\n" +
+				`title:Caf\u00E9 \uD83D\uDE00` + "\nempty:\n! end\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, properties.Write(&buf, p, tt.opts))
+			assert.Equal(t, tt.expected, buf.String())
+
+			back, err := properties.Parse(buf.String())
+			assert.NoError(t, err)
+			assert.Equal(t, p.Map(), back.Map())
+			assert.Equal(t, []string{"# Settings", "# plain comment"}, back.Entries[0].Comments)
+			assert.Equal(t, p.Comments, back.Comments)
+		})
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		src := "# header\nurl = http://example.com/a?b=c\npath = C:\\\\dir\\\\file\nlist = a, \\\n  b\n"
+		p, err := properties.Parse(src)
+		assert.NoError(t, err)
+		assert.Equal(t, "# header\nurl = http://example.com/a?b=c\npath = C:\\\\dir\\\\file\nlist = a, b\n", p.String())
+	})
+}
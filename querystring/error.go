@@ -0,0 +1,43 @@
+// Package querystring provides error reporting for malformed query strings.
+package querystring
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmptyKey is reported in strict mode for a pair with a value but no key, e.g. "=1".
+	ErrEmptyKey = errors.New("empty key")
+	// ErrMalformedKey is reported in strict mode for a key with unbalanced brackets or
+	// without a name before them, e.g. "a[b=1" or "[a]=1".
+	ErrMalformedKey = errors.New("malformed key")
+	// ErrInvalidEscape is reported in strict mode for a '%' not followed by two hex digits.
+	ErrInvalidEscape = errors.New("invalid escape")
+	// ErrTypeConflict is reported in strict mode for a key used both for a value and for nested
+	// keys, or both for an array and an object, e.g. "a=1&a[b]=2".
+	ErrTypeConflict = errors.New("conflicting types")
+	// ErrIndexOutOfRange is reported in strict mode with Indices for a position past the end
+	// of an array, e.g. "a[0]=x&a[2]=y".
+	ErrIndexOutOfRange = errors.New("array index out of range")
+)
+
+// ParseError describes a malformed pair of a query string.
+type ParseError struct {
+	// Offset is the byte offset of the pair in the query string.
+	Offset int
+	// Pair is the pair as written, e.g. "a[b=1".
+	Pair string
+	// Err is the cause, one of the errors of this package.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("querystring: pair %q at offset %d: %v", e.Pair, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
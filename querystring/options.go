@@ -0,0 +1,83 @@
+// Package querystring provides options that select the conventions of a query string.
+package querystring
+
+// Option configures how Parse reads a query string.
+type Option func(*config)
+
+// config holds the settings selected by options.
+type config struct {
+	// separator separates the pairs.
+	separator rune
+	// strict rejects malformed pairs instead of reading them as well as possible.
+	strict bool
+	// indices makes numeric keys positions in arrays.
+	indices bool
+	// repeat collects the values of a repeated key into an array.
+	repeat bool
+	// dots splits keys at dots as well as at brackets.
+	dots bool
+	// maxDepth is the number of nested keys split off a key; the rest is kept as one key.
+	maxDepth int
+}
+
+// newConfig applies opts to the default configuration.
+func newConfig(opts []Option) *config {
+	c := &config{separator: '&', maxDepth: 32}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Separator separates pairs with r instead of '&', e.g. ';' as recommended by old HTML
+// specifications.
+func Separator(r rune) Option {
+	return func(c *config) {
+		c.separator = r
+	}
+}
+
+// Strict makes Parse fail with a *ParseError on the first malformed pair: an empty key, a
+// key with unbalanced brackets, an invalid percent escape, or a key used both for a value and
+// for nested keys. By default such pairs are read as well as possible: a malformed key is
+// used literally, an invalid escape is kept as written, and a later pair replaces what
+// conflicts with it.
+func Strict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+// Indices makes numeric keys positions in arrays as in "a[0]=x&a[1]=y", rather than keys of
+// objects. Positions must follow each other from 0; a position past the end of an array appends
+// to it, and fails with ErrIndexOutOfRange in strict mode.
+func Indices() Option {
+	return func(c *config) {
+		c.indices = true
+	}
+}
+
+// RepeatedKeys collects the values of a key repeated without brackets into an array, so that
+// "a=x&a=y" gives ["x", "y"] as in most web frameworks other than PHP and Rails, where the last
+// value wins.
+func RepeatedKeys() Option {
+	return func(c *config) {
+		c.repeat = true
+	}
+}
+
+// DotNotation also splits keys at dots, so that "a.b=1" is the same as "a[b]=1".
+func DotNotation() Option {
+	return func(c *config) {
+		c.dots = true
+	}
+}
+
+// MaxDepth limits the nesting of keys to n levels below the first name; the remaining brackets
+// are kept as written in the innermost key, so that with MaxDepth(1) "a[b][c]=1" gives
+// {"a": {"b": {"[c]": "1"}}}. The default is 32.
+func MaxDepth(n int) Option {
+	return func(c *config) {
+		c.maxDepth = n
+	}
+}
@@ -0,0 +1,302 @@
+// Package querystring parses URL query strings such as "a=1&b[c]=2&d[]=x&d[]=y" into nested
+// json.Json values, following the bracket conventions of PHP and Rails:
+//
+//	a=1            {"a": "1"}
+//	b[c]=2         {"b": {"c": "2"}}
+//	d[]=x&d[]=y    {"d": ["x", "y"]}
+//	e[][f]=3       {"e": [{"f": "3"}]}
+//
+// Values are always strings; a key without '=' has the empty string as value. Keys and values
+// are percent-decoded, with '+' standing for a space. Options select other conventions for
+// separators, arrays and nesting, and a strict mode rejecting malformed pairs.
+package querystring
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// pair is a key-value pair as written.
+type pair struct {
+	offset   int
+	key      string
+	value    string
+	hasValue bool
+}
+
+// raw returns the pair as written.
+func (p pair) raw() string {
+	if p.hasValue {
+		return p.key + "=" + p.value
+	}
+	return p.key
+}
+
+// pairs parses the pairs of a query string separated by sep.
+func pairs(sep rune) parser.Parser[[]pair] {
+	chars := func(f func(rune) bool) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(f)), parser.Text)
+	}
+	key := chars(func(r rune) bool { return r != sep && r != '=' })
+	value := parser.ZeroOrOne(parser.OmitLeft(parser.Char('='), chars(func(r rune) bool { return r != sep })))
+	item := parser.Bind(parser.Pos(), func(offset int) parser.Parser[pair] {
+		return parser.Bind(key, func(k string) parser.Parser[pair] {
+			return parser.Fmap(value, func(v parser.Maybe[string]) pair {
+				return pair{offset: offset, key: k, value: v.Get(), hasValue: v.IsJust()}
+			})
+		})
+	})
+	return parser.SepBy(item, parser.Char(sep))
+}
+
+// segment is one level of a decoded key, e.g. "c" in "b[c]", with its text as written.
+type segment struct {
+	name, raw string
+}
+
+// keyPath parses a decoded key into the name before the first bracket and the names within
+// the brackets, e.g. "b[c][]" into "b", "c" and "". With dots, ".c" is the same as "[c]".
+func keyPath(dots bool) parser.Parser[[]segment] {
+	name := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool {
+		return r != '[' && !(dots && r == '.')
+	})), func(rs []rune) []segment { return []segment{{parser.Text(rs), parser.Text(rs)}} })
+	bracket := parser.Fmap(
+		parser.Between(parser.Char('['), parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return r != '[' && r != ']' })), parser.Char(']')),
+		func(rs []rune) segment { return segment{parser.Text(rs), "[" + parser.Text(rs) + "]"} },
+	)
+	nested := bracket
+	if dots {
+		dot := parser.Fmap(
+			parser.OmitLeft(parser.Char('.'), parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r != '[' && r != '.' }))),
+			func(rs []rune) segment { return segment{parser.Text(rs), "." + parser.Text(rs)} },
+		)
+		nested = parser.OrElse(bracket, dot)
+	}
+	return parser.Fmap(parser.Seq(name, parser.ZeroOrMore(nested)), func(parts [][]segment) []segment {
+		return append(parts[0], parts[1]...)
+	})
+}
+
+// Parse parses a query string, with or without its leading '?', into an object. Pairs without
+// a key or a value, e.g. between two separators, are skipped. Members keep the order in which
+// their keys first appear.
+func Parse(s string, opts ...Option) (json.JsonObject, error) {
+	c := newConfig(opts)
+	ps, err := parser.Run(pairs(c.separator), strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return json.JsonObject{}, err
+	}
+	if strings.HasPrefix(s, "?") {
+		for i := range ps {
+			ps[i].offset++
+		}
+	}
+	root := &object{vals: map[string]any{}}
+	for _, p := range ps {
+		if p.key == "" && !p.hasValue {
+			continue
+		}
+		if err := c.add(root, p); err != nil {
+			return json.JsonObject{}, &ParseError{Offset: p.offset, Pair: p.raw(), Err: err}
+		}
+	}
+	return root.json(), nil
+}
+
+// add inserts the value of p into root. It only fails in strict mode.
+func (c *config) add(root *object, p pair) error {
+	key, err := c.decode(p.key)
+	if err != nil {
+		return err
+	}
+	value, err := c.decode(p.value)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		if c.strict {
+			return ErrEmptyKey
+		}
+		return nil
+	}
+	segs, err := parser.Run(keyPath(c.dots), key)
+	if err != nil {
+		if c.strict {
+			return ErrMalformedKey
+		}
+		segs = []segment{{key, key}}
+	}
+	path := make([]string, 0, len(segs))
+	for i, seg := range segs {
+		if i > c.maxDepth {
+			var rest strings.Builder
+			for _, seg := range segs[i:] {
+				rest.WriteString(seg.raw)
+			}
+			path = append(path, rest.String())
+			break
+		}
+		path = append(path, seg.name)
+	}
+	_, err = c.insert(root, path, value)
+	return err
+}
+
+// decode percent-decodes s, reading '+' as a space. Outside of strict mode an invalid escape
+// is kept as written.
+func (c *config) decode(s string) (string, error) {
+	if !strings.ContainsAny(s, "%+") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '+':
+			b.WriteByte(' ')
+		case '%':
+			if i+2 < len(s) {
+				if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+					b.WriteByte(byte(v))
+					i += 2
+					continue
+				}
+			}
+			if c.strict {
+				return "", ErrInvalidEscape
+			}
+			b.WriteByte('%')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// object is an object under construction, keeping the order of its keys.
+type object struct {
+	keys []string
+	vals map[string]any
+}
+
+// array is an array under construction.
+type array struct {
+	elems []any
+}
+
+// isIndex reports whether seg is a position in an array.
+func (c *config) isIndex(seg string) bool {
+	if !c.indices || seg == "" || len(seg) > 9 {
+		return false
+	}
+	for _, r := range seg {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// insert stores v at path below old, which is nil, a string, an *object or an *array, and
+// returns the updated value. A value of the wrong type for path is a conflict, which replaces it
+// outside of strict mode.
+func (c *config) insert(old any, path []string, v string) (any, error) {
+	if len(path) == 0 {
+		switch old := old.(type) {
+		case string:
+			if c.repeat {
+				return &array{[]any{old, v}}, nil
+			}
+		case *array:
+			if c.repeat {
+				old.elems = append(old.elems, v)
+				return old, nil
+			}
+			if c.strict {
+				return nil, ErrTypeConflict
+			}
+		case *object:
+			if c.strict {
+				return nil, ErrTypeConflict
+			}
+		}
+		return v, nil
+	}
+	seg, rest := path[0], path[1:]
+	push := seg == "" || c.isIndex(seg)
+	switch o := old.(type) {
+	case *object:
+		if seg != "" {
+			child, err := c.insert(o.vals[seg], rest, v)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := o.vals[seg]; !ok {
+				o.keys = append(o.keys, seg)
+			}
+			o.vals[seg] = child
+			return o, nil
+		}
+	case *array:
+		if push {
+			i := len(o.elems)
+			if seg != "" {
+				n, _ := strconv.Atoi(seg)
+				if n > i && c.strict {
+					return nil, ErrIndexOutOfRange
+				}
+				i = min(n, i)
+			}
+			var prev any
+			if i < len(o.elems) {
+				prev = o.elems[i]
+			}
+			child, err := c.insert(prev, rest, v)
+			if err != nil {
+				return nil, err
+			}
+			if i < len(o.elems) {
+				o.elems[i] = child
+			} else {
+				o.elems = append(o.elems, child)
+			}
+			return o, nil
+		}
+	case nil:
+		if push {
+			return c.insert(&array{}, path, v)
+		}
+		return c.insert(&object{vals: map[string]any{}}, path, v)
+	}
+	if c.strict {
+		return nil, ErrTypeConflict
+	}
+	return c.insert(nil, path, v)
+}
+
+// json converts the object into a json.JsonObject.
+func (o *object) json() json.JsonObject {
+	obj := json.JsonObject{Val: make(map[string]json.Json, len(o.keys)), Keys: o.keys}
+	for k, v := range o.vals {
+		obj.Val[k] = toJSON(v)
+	}
+	return obj
+}
+
+// toJSON converts a value under construction into a json.Json.
+func toJSON(v any) json.Json {
+	switch v := v.(type) {
+	case *object:
+		return v.json()
+	case *array:
+		elems := make([]json.Json, len(v.elems))
+		for i, e := range v.elems {
+			elems[i] = toJSON(e)
+		}
+		return json.JsonArray{Val: elems}
+	}
+	return json.JsonString{Val: v.(string)}
+}
@@ -0,0 +1,96 @@
+package querystring_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/json"
+	"github.com/81120/tiny-parsec/querystring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  []querystring.Option
+		want  string
+	}{
+		{"a=1&b[c]=2&d[]=x&d[]=y", nil, `{"a":"1","b":{"c":"2"},"d":["x","y"]}`},
+		{"?a=1", nil, `{"a":"1"}`},
+		{"", nil, `{}`},
+		{"flag&empty=&&x=1", nil, `{"flag":"","empty":"","x":"1"}`},
+		{"e[][f]=3&e[][g]=4", nil, `{"e":[{"f":"3"},{"g":"4"}]}`},
+		{"u[name]=ann&u[tags][]=a&u[tags][]=b&u[addr][city]=X", nil, `{"u":{"name":"ann","tags":["a","b"],"addr":{"city":"X"}}}`},
+		{"a=1&a=2", nil, `{"a":"2"}`},
+		{"q=a+b%20c%26d&%6B%5Bx%5D=v", nil, `{"q":"a b c&d","k":{"x":"v"}}`},
+		{"x=%zz&y=100%", nil, `{"x":"%zz","y":"100%"}`},
+		{"a[b=1&[c]=2&d[e]f=3", nil, `{"a[b":"1","[c]":"2","d[e]f":"3"}`},
+		{"a=1&a[b]=2&c[]=1&c[d]=2", nil, `{"a":{"b":"2"},"c":{"d":"2"}}`},
+		{"=1&b", nil, `{"b":""}`},
+		{"a[0]=x&a[1]=y", nil, `{"a":{"0":"x","1":"y"}}`},
+		{"a[0]=x&a[1]=y&a[0]=z", []querystring.Option{querystring.Indices()}, `{"a":["z","y"]}`},
+		{"a[0][n]=x&a[0][m]=y&a[1][n]=z", []querystring.Option{querystring.Indices()}, `{"a":[{"n":"x","m":"y"},{"n":"z"}]}`},
+		{"a[5]=x", []querystring.Option{querystring.Indices()}, `{"a":["x"]}`},
+		{"a=x&a=y&b[]=1&b=2", []querystring.Option{querystring.RepeatedKeys()}, `{"a":["x","y"],"b":["1","2"]}`},
+		{"a.b.c=1&a[d]=2", []querystring.Option{querystring.DotNotation()}, `{"a":{"b":{"c":"1"},"d":"2"}}`},
+		{"a.b=1", nil, `{"a.b":"1"}`},
+		{"a;b=2", []querystring.Option{querystring.Separator(';')}, `{"a":"","b":"2"}`},
+		{"a[b][c][d]=1", []querystring.Option{querystring.MaxDepth(1)}, `{"a":{"b":{"[c][d]":"1"}}}`},
+		{"a[b]=1", []querystring.Option{querystring.MaxDepth(0)}, `{"a":{"[b]":"1"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := querystring.Parse(tt.input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	got, err := querystring.Parse("z=1&a=2&m[y]=3&m[b]=4&z=5")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"z", "a", "m"}, got.OrderedKeys())
+	m, _ := got.Get("m")
+	assert.Equal(t, []string{"y", "b"}, m.(json.JsonObject).OrderedKeys())
+	assert.Equal(t, map[string]any{"z": "5", "a": "2", "m": map[string]any{"y": "3", "b": "4"}}, json.ToNative(got))
+}
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		input  string
+		opts   []querystring.Option
+		err    error
+		offset int
+		msg    string
+	}{
+		{"a=1&=2", nil, querystring.ErrEmptyKey, 4, `querystring: pair "=2" at offset 4: empty key`},
+		{"a[b=1", nil, querystring.ErrMalformedKey, 0, `querystring: pair "a[b=1" at offset 0: malformed key`},
+		{"?x=1&[c]=2", nil, querystring.ErrMalformedKey, 5, `querystring: pair "[c]=2" at offset 5: malformed key`},
+		{"a[b]c=1", nil, querystring.ErrMalformedKey, 0, ""},
+		{"a[b[c]]=1", nil, querystring.ErrMalformedKey, 0, ""},
+		{"x=%zz", nil, querystring.ErrInvalidEscape, 0, `querystring: pair "x=%zz" at offset 0: invalid escape`},
+		{"x%2=1", nil, querystring.ErrInvalidEscape, 0, ""},
+		{"a=1&a[b]=2", nil, querystring.ErrTypeConflict, 4, `querystring: pair "a[b]=2" at offset 4: conflicting types`},
+		{"a[b]=1&a=2", nil, querystring.ErrTypeConflict, 7, ""},
+		{"a[]=1&a[b]=2", nil, querystring.ErrTypeConflict, 6, ""},
+		{"a[b]=1&a[]=2", nil, querystring.ErrTypeConflict, 7, ""},
+		{"a[0]=x&a[2]=y", []querystring.Option{querystring.Indices()}, querystring.ErrIndexOutOfRange, 7, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := querystring.Parse(tt.input, append(tt.opts, querystring.Strict())...)
+			var perr *querystring.ParseError
+			if assert.ErrorAs(t, err, &perr) {
+				assert.Equal(t, tt.offset, perr.Offset)
+			}
+			assert.ErrorIs(t, err, tt.err)
+			if tt.msg != "" {
+				assert.EqualError(t, err, tt.msg)
+			}
+		})
+	}
+
+	got, err := querystring.Parse("a=1&&b[]=2&b[]=3&c[d]=4&c[d]=5&e", querystring.Strict())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"1","b":["2","3"],"c":{"d":"5"},"e":""}`, got.String())
+}
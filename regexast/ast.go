@@ -0,0 +1,400 @@
+// Package regexast defines the nodes of the syntax tree of a regular expression.
+package regexast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Node is implemented by the nodes of a syntax tree: Literal, Any, Anchor, PerlClass,
+// UnicodeClass, *Class, *Group, SetFlags, Backref, *Repeat, *Concat and *Alternate.
+type Node interface {
+	// String returns the node in regular expression syntax.
+	String() string
+	// regexNode is a method that all node types must implement.
+	// It serves as a marker for the node type.
+	regexNode()
+}
+
+// ClassItem is implemented by the items of a character class: Range, PerlClass,
+// UnicodeClass and POSIXClass.
+type ClassItem interface {
+	// String returns the item in character class syntax.
+	String() string
+	// classItem is a method that all item types must implement.
+	// It serves as a marker for the item type.
+	classItem()
+}
+
+// Literal matches a single character, e.g. "a", "\." or "\x{263a}".
+type Literal struct {
+	Rune rune
+}
+
+func (Literal) regexNode() {}
+
+// String returns the character, escaped if it is a metacharacter or not printable.
+func (l Literal) String() string {
+	return quoteRune(l.Rune, `\.+*?()|[]{}^$`)
+}
+
+// Any matches any character, written ".".
+type Any struct{}
+
+func (Any) regexNode() {}
+
+// String returns ".".
+func (Any) String() string {
+	return "."
+}
+
+// Anchor matches the empty string at a position, e.g. "^" or "\b".
+type Anchor int
+
+const (
+	// LineStart is "^", the start of the text or, in multi-line mode, of a line.
+	LineStart Anchor = iota
+	// LineEnd is "$", the end of the text or, in multi-line mode, of a line.
+	LineEnd
+	// TextStart is "\A", the start of the text.
+	TextStart
+	// TextEnd is "\z", the end of the text.
+	TextEnd
+	// WordBoundary is "\b", between a word character and a non-word character.
+	WordBoundary
+	// NoWordBoundary is "\B", anywhere but at a word boundary.
+	NoWordBoundary
+)
+
+func (Anchor) regexNode() {}
+
+// String returns the anchor as written, e.g. "^".
+func (a Anchor) String() string {
+	switch a {
+	case LineStart:
+		return "^"
+	case LineEnd:
+		return "$"
+	case TextStart:
+		return `\A`
+	case TextEnd:
+		return `\z`
+	case WordBoundary:
+		return `\b`
+	case NoWordBoundary:
+		return `\B`
+	}
+	return fmt.Sprintf("Anchor(%d)", int(a))
+}
+
+// PerlClass is one of the character classes "\d", "\s" and "\w", or their negations "\D",
+// "\S" and "\W".
+type PerlClass struct {
+	// Class is the lower-case letter of the class: 'd', 's' or 'w'.
+	Class   rune
+	Negated bool
+}
+
+func (PerlClass) regexNode() {}
+func (PerlClass) classItem() {}
+
+// String returns the class as written, e.g. "\D".
+func (c PerlClass) String() string {
+	if c.Negated {
+		return `\` + string(unicode.ToUpper(c.Class))
+	}
+	return `\` + string(c.Class)
+}
+
+// UnicodeClass is a Unicode category or script, e.g. "\pL", "\p{Greek}" or "\P{Lu}".
+type UnicodeClass struct {
+	// Name is the name of the category or script, e.g. "L" or "Greek".
+	Name    string
+	Negated bool
+}
+
+func (UnicodeClass) regexNode() {}
+func (UnicodeClass) classItem() {}
+
+// String returns the class, with braces unless its name is a single letter.
+func (c UnicodeClass) String() string {
+	p := `\p`
+	if c.Negated {
+		p = `\P`
+	}
+	if len(c.Name) == 1 {
+		return p + c.Name
+	}
+	return p + "{" + c.Name + "}"
+}
+
+// POSIXClass is a named class within a character class, e.g. "[:alpha:]" or "[:^space:]".
+type POSIXClass struct {
+	// Name is the name of the class, e.g. "alpha".
+	Name    string
+	Negated bool
+}
+
+func (POSIXClass) classItem() {}
+
+// String returns the class as written, e.g. "[:alpha:]".
+func (c POSIXClass) String() string {
+	if c.Negated {
+		return "[:^" + c.Name + ":]"
+	}
+	return "[:" + c.Name + ":]"
+}
+
+// Range is a range of characters within a character class, e.g. "a-z", or a single
+// character, whose Lo and Hi are equal.
+type Range struct {
+	Lo, Hi rune
+}
+
+func (Range) classItem() {}
+
+// String returns the range as written, e.g. "a-z".
+func (r Range) String() string {
+	const meta = `\[]^-`
+	if r.Lo == r.Hi {
+		return quoteRune(r.Lo, meta)
+	}
+	return quoteRune(r.Lo, meta) + "-" + quoteRune(r.Hi, meta)
+}
+
+// Class matches one character of a bracketed character class, e.g. "[a-z_]" or "[^\d]".
+type Class struct {
+	// Negated is set for classes starting with "^", which match characters not listed.
+	Negated bool
+	// Items lists the contents of the class in order.
+	Items []ClassItem
+}
+
+func (*Class) regexNode() {}
+
+// String returns the class in brackets.
+func (c *Class) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	if c.Negated {
+		b.WriteByte('^')
+	}
+	for _, it := range c.Items {
+		b.WriteString(it.String())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// GroupKind is the kind of a parenthesized group.
+type GroupKind int
+
+const (
+	// Capture is a capturing group, "(re)", or a named one, "(?P<name>re)" or "(?<name>re)".
+	Capture GroupKind = iota
+	// NonCapture is a group that does not capture, "(?:re)", possibly setting flags for
+	// its contents, "(?i:re)".
+	NonCapture
+	// Lookahead is the assertion "(?=re)".
+	Lookahead
+	// NegativeLookahead is the assertion "(?!re)".
+	NegativeLookahead
+	// Lookbehind is the assertion "(?<=re)".
+	Lookbehind
+	// NegativeLookbehind is the assertion "(?<!re)".
+	NegativeLookbehind
+)
+
+// Group is a parenthesized subexpression.
+type Group struct {
+	Kind GroupKind
+	// Index is the number of a capturing group, counting opening parentheses from 1, or 0
+	// for other groups.
+	Index int
+	// Name is the name of a named capturing group.
+	Name string
+	// Flags are the flags set for the contents of a non-capturing group, e.g. "i" or "i-s".
+	Flags string
+	Sub   Node
+}
+
+func (*Group) regexNode() {}
+
+// String returns the group in parentheses. Named groups are written "(?P<name>re)".
+func (g *Group) String() string {
+	open := "("
+	switch g.Kind {
+	case Capture:
+		if g.Name != "" {
+			open = "(?P<" + g.Name + ">"
+		}
+	case NonCapture:
+		open = "(?" + g.Flags + ":"
+	case Lookahead:
+		open = "(?="
+	case NegativeLookahead:
+		open = "(?!"
+	case Lookbehind:
+		open = "(?<="
+	case NegativeLookbehind:
+		open = "(?<!"
+	}
+	return open + g.Sub.String() + ")"
+}
+
+// SetFlags sets flags up to the end of the enclosing group, e.g. "(?i)" or "(?s-m)".
+type SetFlags struct {
+	// Flags are the flags to set, followed by the flags to clear after a "-".
+	Flags string
+}
+
+func (SetFlags) regexNode() {}
+
+// String returns the flags in parentheses.
+func (f SetFlags) String() string {
+	return "(?" + f.Flags + ")"
+}
+
+// Backref matches the text matched by a capturing group, e.g. "\1" or "\k<name>".
+type Backref struct {
+	// Index is the number of the group, or 0 if it is referenced by Name.
+	Index int
+	Name  string
+}
+
+func (Backref) regexNode() {}
+
+// String returns the reference as written.
+func (r Backref) String() string {
+	if r.Name != "" {
+		return `\k<` + r.Name + ">"
+	}
+	return `\` + strconv.Itoa(r.Index)
+}
+
+// Repeat matches its subexpression a number of times, e.g. "a*", "a+?" or "a{2,5}".
+type Repeat struct {
+	// Min is the least number of repetitions.
+	Min int
+	// Max is the greatest number of repetitions, or -1 if there is no limit.
+	Max int
+	// Lazy is set for repetitions followed by "?", which prefer fewer repetitions.
+	Lazy bool
+	Sub  Node
+}
+
+func (*Repeat) regexNode() {}
+
+// String returns the subexpression followed by its quantifier, grouping the subexpression
+// if necessary.
+func (r *Repeat) String() string {
+	s := r.Sub.String()
+	switch sub := r.Sub.(type) {
+	case *Concat:
+		if len(sub.Subs) != 1 {
+			s = "(?:" + s + ")"
+		}
+	case *Alternate, *Repeat:
+		s = "(?:" + s + ")"
+	}
+	switch {
+	case r.Min == 0 && r.Max == -1:
+		s += "*"
+	case r.Min == 1 && r.Max == -1:
+		s += "+"
+	case r.Min == 0 && r.Max == 1:
+		s += "?"
+	case r.Max == -1:
+		s += fmt.Sprintf("{%d,}", r.Min)
+	case r.Min == r.Max:
+		s += fmt.Sprintf("{%d}", r.Min)
+	default:
+		s += fmt.Sprintf("{%d,%d}", r.Min, r.Max)
+	}
+	if r.Lazy {
+		s += "?"
+	}
+	return s
+}
+
+// Concat matches its subexpressions one after the other. An empty Concat matches the empty
+// string.
+type Concat struct {
+	Subs []Node
+}
+
+func (*Concat) regexNode() {}
+
+// String returns the subexpressions in order, grouping alternations.
+func (c *Concat) String() string {
+	var b strings.Builder
+	for _, n := range c.Subs {
+		if _, ok := n.(*Alternate); ok {
+			b.WriteString("(?:" + n.String() + ")")
+		} else {
+			b.WriteString(n.String())
+		}
+	}
+	return b.String()
+}
+
+// Alternate matches any one of its subexpressions, preferring the first, e.g. "a|b|c".
+type Alternate struct {
+	Subs []Node
+}
+
+func (*Alternate) regexNode() {}
+
+// String returns the subexpressions separated by "|".
+func (a *Alternate) String() string {
+	subs := make([]string, len(a.Subs))
+	for i, n := range a.Subs {
+		subs[i] = n.String()
+	}
+	return strings.Join(subs, "|")
+}
+
+// quoteRune writes the character r, escaping it if it is one of meta or not printable.
+func quoteRune(r rune, meta string) string {
+	switch {
+	case strings.ContainsRune(meta, r):
+		return `\` + string(r)
+	case r == '\t':
+		return `\t`
+	case r == '\n':
+		return `\n`
+	case r == '\r':
+		return `\r`
+	case r == '\f':
+		return `\f`
+	case r == '\v':
+		return `\v`
+	case !unicode.IsPrint(r):
+		return fmt.Sprintf(`\x{%x}`, r)
+	}
+	return string(r)
+}
+
+// Walk traverses n in order, calling fn for every node before its subexpressions. If fn
+// returns false, the subexpressions of the node are skipped.
+func Walk(n Node, fn func(Node) bool) {
+	if !fn(n) {
+		return
+	}
+	switch n := n.(type) {
+	case *Group:
+		Walk(n.Sub, fn)
+	case *Repeat:
+		Walk(n.Sub, fn)
+	case *Concat:
+		for _, sub := range n.Subs {
+			Walk(sub, fn)
+		}
+	case *Alternate:
+		for _, sub := range n.Subs {
+			Walk(sub, fn)
+		}
+	}
+}
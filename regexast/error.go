@@ -0,0 +1,29 @@
+// Package regexast provides error reporting for malformed regular expressions.
+package regexast
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ParseError describes why a regular expression could not be parsed. Expected lists what the
+// expression should contain at the error, e.g. "hex digit".
+type ParseError = parser.Error
+
+// ErrInvalidRepeatCount is reported at the brace of a counted repetition whose minimum is greater
+// than its maximum, e.g. "a{2,1}", or whose counts are above 1000.
+var ErrInvalidRepeatCount = errors.New("invalid repeat count")
+
+// parseError converts an error of the parser package into a *ParseError. A failure on the counts
+// of a repetition is reported with ErrInvalidRepeatCount rather than with what the expression
+// should contain.
+func parseError(err error) error {
+	err = parser.Wrap(err, "regexast")
+	var perr *ParseError
+	if errors.As(err, &perr) && slices.Contains(perr.Expected, repeatCount) {
+		perr.Found, perr.Expected, perr.Err = "", nil, ErrInvalidRepeatCount
+	}
+	return err
+}
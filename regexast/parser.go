@@ -0,0 +1,420 @@
+// Package regexast provides the grammar of regular expressions, built with the tiny-parsec
+// combinators.
+package regexast
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// isWord reports whether r may appear in the name of a group.
+func isWord(r rune) bool {
+	return isLetter(r) || isDigit(r) || r == '_'
+}
+
+// isPunct reports whether r is an ASCII punctuation character, which may be escaped to stand
+// for itself.
+func isPunct(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsPunct(r) || unicode.IsSymbol(r))
+}
+
+// controls maps the letters of control character escapes, e.g. 'n' in "\n", to the
+// characters.
+var controls = map[rune]rune{'a': '\a', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v'}
+
+// anchors maps the letters of anchor escapes, e.g. 'b' in "\b", to the anchors.
+var anchors = map[rune]Anchor{'A': TextStart, 'z': TextEnd, 'b': WordBoundary, 'B': NoWordBoundary}
+
+// posixClasses lists the names of the classes written "[:name:]".
+var posixClasses = []string{
+	"alnum", "alpha", "ascii", "blank", "cntrl", "digit", "graph",
+	"lower", "print", "punct", "space", "upper", "word", "xdigit",
+}
+
+// maxRepeat is the greatest count allowed in a counted repetition.
+const maxRepeat = 1000
+
+// char parses a UTF-8 encoded character satisfying f.
+func char(f func(rune) bool, expected string) parser.Parser[rune] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[rune] {
+		s := st.Input()
+		r, n := utf8.DecodeRuneInString(s)
+		if s == "" {
+			st.Truncated(expected)
+			return parser.Nothing[parser.Tuple[rune, parser.State]]()
+		}
+		if r == utf8.RuneError && n == 1 || !f(r) {
+			st.Fail(expected)
+			return parser.Nothing[parser.Tuple[rune, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[rune, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(r, next))
+	})
+}
+
+// name parses the name of a group.
+func name() parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isWord, "group name")), parser.Text)
+}
+
+// hexCode parses the code point of a "\x" escape: two hex digits or up to eight in braces.
+func hexCode() parser.Parser[rune] {
+	digit := parser.SatisfyMsg(isHexDigit, "hex digit")
+	short := parser.Fmap(parser.Seq(digit, digit), parser.Text)
+	long := parser.Between(parser.Char('{'), parser.Fmap(parser.OneOrMore(digit), parser.Text), parser.Char('}'))
+	valid := parser.SatisfyWithMsg(parser.OrElse(short, long), func(s string) bool {
+		v, err := strconv.ParseUint(s, 16, 32)
+		return err == nil && v <= unicode.MaxRune
+	}, "code point")
+	return parser.Fmap(valid, func(s string) rune {
+		v, _ := strconv.ParseUint(s, 16, 32)
+		return rune(v)
+	})
+}
+
+// unicodeClass parses the name of a Unicode class following "\p" or "\P": a single letter or
+// a name in braces.
+func unicodeClass(negated bool) parser.Parser[UnicodeClass] {
+	letter := parser.Fmap(parser.SatisfyMsg(isLetter, "Unicode class name"), func(r rune) string { return string(r) })
+	braced := parser.Between(parser.Char('{'), parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isWord, "Unicode class name")), parser.Text), parser.Char('}'))
+	valid := parser.SatisfyWithMsg(parser.OrElse(letter, braced), func(s string) bool {
+		return s == "Any" || unicode.Categories[s] != nil || unicode.Scripts[s] != nil
+	}, "Unicode class name")
+	return parser.Fmap(valid, func(s string) UnicodeClass {
+		return UnicodeClass{Name: s, Negated: negated}
+	})
+}
+
+// escape parses a backslash followed by a character accepted by allowed, and continues with
+// the parser returned by then for that character.
+func escape[T any](allowed func(rune) bool, then func(rune) parser.Parser[T]) parser.Parser[T] {
+	return parser.Bind(parser.OmitLeft(parser.Char('\\'), char(allowed, "escape sequence")), then)
+}
+
+// isCharEscape reports whether r may follow a backslash to stand for a single character.
+func isCharEscape(r rune) bool {
+	_, ok := controls[r]
+	return ok || r == 'x' || isPunct(r)
+}
+
+// escapedChar continues an escape standing for a single character after its letter r.
+func escapedChar(r rune) parser.Parser[rune] {
+	if c, ok := controls[r]; ok {
+		return parser.Pure(c)
+	}
+	if r == 'x' {
+		return hexCode()
+	}
+	return parser.Pure(r)
+}
+
+// isClassEscape reports whether r may follow a backslash to stand for a class.
+func isClassEscape(r rune) bool {
+	return strings.ContainsRune("dDsSwWpP", r)
+}
+
+// classEscape continues an escape standing for a class after its letter r.
+func classEscape(r rune) parser.Parser[ClassItem] {
+	switch r {
+	case 'p', 'P':
+		return parser.Fmap(unicodeClass(r == 'P'), func(c UnicodeClass) ClassItem { return c })
+	}
+	return parser.Pure[ClassItem](PerlClass{Class: unicode.ToLower(r), Negated: unicode.IsUpper(r)})
+}
+
+// atomEscape parses an escape outside a character class: a character, a class, an anchor
+// or a backreference.
+func atomEscape() parser.Parser[Node] {
+	allowed := func(r rune) bool {
+		_, anchor := anchors[r]
+		return anchor || isCharEscape(r) || isClassEscape(r) || r == 'k' || r >= '1' && r <= '9'
+	}
+	return escape(allowed, func(r rune) parser.Parser[Node] {
+		if a, ok := anchors[r]; ok {
+			return parser.Pure[Node](a)
+		}
+		switch {
+		case isClassEscape(r):
+			return parser.Fmap(classEscape(r), func(c ClassItem) Node { return c.(Node) })
+		case r == 'k':
+			return parser.Fmap(parser.Between(parser.Char('<'), name(), parser.Char('>')), func(s string) Node {
+				return Backref{Name: s}
+			})
+		case isDigit(r):
+			return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isDigit)), func(rs []rune) Node {
+				n, _ := strconv.Atoi(string(r) + parser.Text(rs))
+				return Backref{Index: n}
+			})
+		}
+		return parser.Fmap(escapedChar(r), func(c rune) Node { return Literal{Rune: c} })
+	})
+}
+
+// braces returns the bounds of the counted repetition "{n}", "{n,}" or "{n,m}" at the start
+// of s, with hi -1 if there is no upper bound, and its length, or a length of 0 if there is
+// none. Counts too large to represent are returned as maxRepeat+1.
+func braces(s string) (lo, hi, n int) {
+	count := func(i int) (int, int) {
+		j := i
+		for j < len(s) && isDigit(rune(s[j])) {
+			j++
+		}
+		v, err := strconv.Atoi(s[i:j])
+		if err != nil && j > i {
+			v = maxRepeat + 1
+		}
+		return v, j
+	}
+	if !strings.HasPrefix(s, "{") {
+		return 0, 0, 0
+	}
+	lo, i := count(1)
+	if i == 1 {
+		return 0, 0, 0
+	}
+	hi = lo
+	if i < len(s) && s[i] == ',' {
+		hi = -1
+		if j := i + 1; j < len(s) && isDigit(rune(s[j])) {
+			hi, i = count(j)
+		} else {
+			i = j
+		}
+	}
+	if i >= len(s) || s[i] != '}' {
+		return 0, 0, 0
+	}
+	return lo, hi, i + 1
+}
+
+// repeatCount describes the counts accepted by counted; parseError reports a failure on it as
+// ErrInvalidRepeatCount.
+const repeatCount = "repetition count"
+
+// counted parses a counted repetition, rejecting counts out of order or above maxRepeat.
+func counted() parser.Parser[Repeat] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[Repeat] {
+		lo, hi, n := braces(st.Input())
+		if n == 0 {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[Repeat, parser.State]]()
+		}
+		if lo > maxRepeat || hi > maxRepeat || hi >= 0 && hi < lo {
+			st.Fail(repeatCount)
+			return parser.Nothing[parser.Tuple[Repeat, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[Repeat, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(Repeat{Min: lo, Max: hi}, next))
+	})
+}
+
+// quantifier parses "*", "+", "?" or a counted repetition, optionally followed by "?" to
+// make it lazy.
+func quantifier() parser.Parser[Repeat] {
+	bounds := func(c rune, r Repeat) parser.Parser[Repeat] {
+		return parser.Fmap(parser.Char(c), func(rune) Repeat { return r })
+	}
+	q := parser.OrElse(
+		bounds('*', Repeat{Min: 0, Max: -1}),
+		bounds('+', Repeat{Min: 1, Max: -1}),
+		bounds('?', Repeat{Min: 0, Max: 1}),
+		counted(),
+	)
+	return parser.Bind(q, func(r Repeat) parser.Parser[Repeat] {
+		return parser.Fmap(parser.ZeroOrOne(parser.Char('?')), func(m parser.Maybe[rune]) Repeat {
+			r.Lazy = m.IsJust()
+			return r
+		})
+	})
+}
+
+// literal parses a character standing for itself. A "{" is literal unless it starts a counted
+// repetition, which has nothing to repeat there.
+func literal() parser.Parser[Node] {
+	plain := char(func(r rune) bool { return !strings.ContainsRune(`\.+*?()|[^${`, r) }, "")
+	brace := parser.NewStateParser(func(st parser.State) parser.StateFuncRet[rune] {
+		if _, _, n := braces(st.Input()); n > 0 {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[rune, parser.State]]()
+		}
+		return char(func(r rune) bool { return r == '{' }, "").RunState(st)
+	})
+	return parser.Fmap(parser.OrElse(plain, brace), func(r rune) Node { return Literal{Rune: r} })
+}
+
+// classChar parses a character in a character class. A "]" is literal only as the first
+// character and a "-" only as the first or last one, and a "[" may not start "[:".
+func classChar(first bool) parser.Parser[rune] {
+	plain := parser.NewStateParser(func(st parser.State) parser.StateFuncRet[rune] {
+		s := st.Input()
+		if strings.HasPrefix(s, "[:") || !first && (strings.HasPrefix(s, "]") || strings.HasPrefix(s, "-") && !strings.HasPrefix(s, "-]")) {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[rune, parser.State]]()
+		}
+		return char(func(r rune) bool { return r != '\\' }, "").RunState(st)
+	})
+	return parser.OrElse(plain, escape(isCharEscape, escapedChar))
+}
+
+// posixClass parses a class such as "[:alpha:]" within a character class.
+func posixClass() parser.Parser[ClassItem] {
+	negated := parser.Fmap(parser.ZeroOrOne(parser.Char('^')), parser.Maybe[rune].IsJust)
+	known := parser.SatisfyWithMsg(parser.Fmap(parser.OneOrMore(parser.Satisfy(isLetter)), parser.Text), func(s string) bool {
+		for _, c := range posixClasses {
+			if c == s {
+				return true
+			}
+		}
+		return false
+	}, "POSIX class name")
+	return parser.OmitLeft(parser.Str("[:"), parser.Bind(negated, func(neg bool) parser.Parser[ClassItem] {
+		return parser.OmitRight(parser.Fmap(known, func(s string) ClassItem {
+			return POSIXClass{Name: s, Negated: neg}
+		}), parser.Str(":]"))
+	}))
+}
+
+// classRange parses a character or a range of characters in a character class.
+func classRange(first bool) parser.Parser[ClassItem] {
+	return parser.Bind(classChar(first), func(lo rune) parser.Parser[ClassItem] {
+		hi := parser.SatisfyWithMsg(classChar(false), func(hi rune) bool { return hi >= lo }, "end of range")
+		return parser.OrElse(
+			parser.Fmap(parser.OmitLeft(parser.Char('-'), hi), func(hi rune) ClassItem { return Range{Lo: lo, Hi: hi} }),
+			parser.Pure[ClassItem](Range{Lo: lo, Hi: lo}),
+		)
+	})
+}
+
+// classItem parses an item of a character class.
+func classItem(first bool) parser.Parser[ClassItem] {
+	return parser.OrElse(posixClass(), escape(isClassEscape, classEscape), classRange(first))
+}
+
+// class parses a bracketed character class.
+func class() parser.Parser[Node] {
+	negated := parser.Fmap(parser.ZeroOrOne(parser.Char('^')), parser.Maybe[rune].IsJust)
+	items := parser.Bind(classItem(true), func(it ClassItem) parser.Parser[[]ClassItem] {
+		return parser.Fmap(parser.ZeroOrMore(classItem(false)), func(its []ClassItem) []ClassItem {
+			return append([]ClassItem{it}, its...)
+		})
+	})
+	return parser.OmitLeft(parser.Char('['), parser.Bind(negated, func(neg bool) parser.Parser[Node] {
+		return parser.OmitRight(parser.Fmap(items, func(its []ClassItem) Node {
+			return &Class{Negated: neg, Items: its}
+		}), parser.Char(']'))
+	}))
+}
+
+// flags parses the flags of a group, e.g. "i" or "i-s".
+func flags() parser.Parser[string] {
+	flag := parser.SatisfyMsg(func(r rune) bool { return strings.ContainsRune("imsU", r) }, "flag")
+	clear := parser.Fmap(parser.OmitLeft(parser.Char('-'), parser.OneOrMore(flag)), func(rs []rune) string {
+		return "-" + parser.Text(rs)
+	})
+	return parser.SatisfyWithMsg(parser.Bind(parser.Fmap(parser.ZeroOrMore(flag), parser.Text), func(set string) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrOne(clear), func(m parser.Maybe[string]) string { return set + m.Get() })
+	}), func(s string) bool { return s != "" }, "flag")
+}
+
+// setFlags parses flags set up to the end of the enclosing group, e.g. "(?i)".
+func setFlags() parser.Parser[Node] {
+	return parser.Fmap(parser.Between(parser.Str("(?"), flags(), parser.Char(')')), func(f string) Node {
+		return SetFlags{Flags: f}
+	})
+}
+
+// group parses a parenthesized group whose contents are parsed by re.
+func group(re parser.Parser[Node]) parser.Parser[Node] {
+	kind := func(prefix string, k GroupKind) parser.Parser[Group] {
+		return parser.Fmap(parser.Str(prefix), func(string) Group { return Group{Kind: k} })
+	}
+	open := parser.OrElse(
+		kind("?:", NonCapture),
+		kind("?=", Lookahead),
+		kind("?!", NegativeLookahead),
+		kind("?<=", Lookbehind),
+		kind("?<!", NegativeLookbehind),
+		parser.Fmap(parser.Between(parser.OrElse(parser.Str("?P<"), parser.Str("?<")), name(), parser.Char('>')), func(s string) Group {
+			return Group{Kind: Capture, Name: s}
+		}),
+		parser.Fmap(parser.Between(parser.Char('?'), flags(), parser.Char(':')), func(f string) Group {
+			return Group{Kind: NonCapture, Flags: f}
+		}),
+		parser.Bind(parser.GetInput(), func(s string) parser.Parser[Group] {
+			if strings.HasPrefix(s, "?") {
+				return parser.Fail[Group]()
+			}
+			return parser.Pure(Group{Kind: Capture})
+		}),
+	)
+	return parser.Nested(parser.OmitLeft(parser.Char('('), parser.Bind(open, func(g Group) parser.Parser[Node] {
+		return parser.OmitRight(parser.Fmap(re, func(sub Node) Node {
+			g.Sub = sub
+			return &g
+		}), parser.Char(')'))
+	})))
+}
+
+// regexp parses a regular expression: alternatives of sequences of repeated atoms.
+func regexp() parser.Parser[Node] {
+	var re parser.Parser[Node]
+	ref := parser.Lazy(func() parser.Parser[Node] { return re })
+	atom := parser.OrElse(
+		setFlags(),
+		group(ref),
+		class(),
+		parser.Fmap(parser.Char('.'), func(rune) Node { return Any{} }),
+		parser.Fmap(parser.Char('^'), func(rune) Node { return LineStart }),
+		parser.Fmap(parser.Char('$'), func(rune) Node { return LineEnd }),
+		atomEscape(),
+		literal(),
+	)
+	repeat := parser.Bind(atom, func(n Node) parser.Parser[Node] {
+		return parser.Fmap(parser.ZeroOrOne(quantifier()), func(m parser.Maybe[Repeat]) Node {
+			if m.IsNothing() {
+				return n
+			}
+			r := m.Get()
+			r.Sub = n
+			return &r
+		})
+	})
+	concat := parser.Fmap(parser.ZeroOrMore(repeat), func(ns []Node) Node {
+		switch len(ns) {
+		case 0:
+			return &Concat{}
+		case 1:
+			return ns[0]
+		}
+		return &Concat{Subs: ns}
+	})
+	re = parser.Fmap(parser.SepBy(concat, parser.Char('|')), func(ns []Node) Node {
+		if len(ns) == 1 {
+			return ns[0]
+		}
+		return &Alternate{Subs: ns}
+	})
+	return re
+}
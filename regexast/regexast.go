@@ -0,0 +1,43 @@
+// Package regexast parses the syntax of regular expressions into a syntax tree, for tools
+// that inspect or rewrite expressions rather than match text with them, such as linters
+// and converters between dialects.
+//
+// The syntax is that of Go's regexp package, extended with the assertions and
+// backreferences of Perl-compatible dialects:
+//
+//	x|y               alternation
+//	(re) (?P<n>re)    capturing groups, also named with (?<n>re)
+//	(?:re) (?i:re)    non-capturing groups, possibly setting flags
+//	(?i) (?s-m)       flags set up to the end of the enclosing group
+//	(?=re) (?!re)     lookahead assertions
+//	(?<=re) (?<!re)   lookbehind assertions
+//	[a-z] [^\d]       character classes, also containing [:alpha:]
+//	x* x+ x? x{n,m}   repetitions, lazy if followed by ?
+//	^ $ \A \z \b \B   anchors
+//	\d \s \w \pL      Perl and Unicode classes, negated by upper case
+//	\1 \k<n>          backreferences
+//	\n \x41 \x{263a}  escaped characters
+//
+// A "{" that does not start a counted repetition stands for itself, as do "]" and "}".
+package regexast
+
+import "github.com/81120/tiny-parsec/parser"
+
+// Parse parses the regular expression pattern into a syntax tree. The Index of each
+// capturing group is set from the order of the opening parentheses. Backreferences are not
+// checked against the groups they refer to.
+func Parse(pattern string) (Node, error) {
+	n, err := parser.Run(regexp(), pattern)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	index := 0
+	Walk(n, func(n Node) bool {
+		if g, ok := n.(*Group); ok && g.Kind == Capture {
+			index++
+			g.Index = index
+		}
+		return true
+	})
+	return n, nil
+}
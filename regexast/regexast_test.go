@@ -0,0 +1,161 @@
+package regexast_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/regexast"
+	"github.com/stretchr/testify/assert"
+)
+
+func lit(s string) []regexast.Node {
+	var ns []regexast.Node
+	for _, r := range s {
+		ns = append(ns, regexast.Literal{Rune: r})
+	}
+	return ns
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected regexast.Node
+	}{
+		{"literal", "a", regexast.Literal{Rune: 'a'}},
+		{"empty", "", &regexast.Concat{}},
+		{"concat", "ab", &regexast.Concat{Subs: lit("ab")}},
+		{"alternation", "a|bc|", &regexast.Alternate{Subs: []regexast.Node{
+			regexast.Literal{Rune: 'a'}, &regexast.Concat{Subs: lit("bc")}, &regexast.Concat{},
+		}}},
+		{"repetitions", `a*?b{2,}c{3}\d{0,5}`, &regexast.Concat{Subs: []regexast.Node{
+			&regexast.Repeat{Min: 0, Max: -1, Lazy: true, Sub: regexast.Literal{Rune: 'a'}},
+			&regexast.Repeat{Min: 2, Max: -1, Sub: regexast.Literal{Rune: 'b'}},
+			&regexast.Repeat{Min: 3, Max: 3, Sub: regexast.Literal{Rune: 'c'}},
+			&regexast.Repeat{Min: 0, Max: 5, Sub: regexast.PerlClass{Class: 'd'}},
+		}}},
+		{"literal braces", "a{,2}{x}", &regexast.Concat{Subs: lit("a{,2}{x}")}},
+		{"groups", `(a)(?:b)(?P<c>c)(?<d>d)(?i-s:e)`, &regexast.Concat{Subs: []regexast.Node{
+			&regexast.Group{Kind: regexast.Capture, Index: 1, Sub: regexast.Literal{Rune: 'a'}},
+			&regexast.Group{Kind: regexast.NonCapture, Sub: regexast.Literal{Rune: 'b'}},
+			&regexast.Group{Kind: regexast.Capture, Index: 2, Name: "c", Sub: regexast.Literal{Rune: 'c'}},
+			&regexast.Group{Kind: regexast.Capture, Index: 3, Name: "d", Sub: regexast.Literal{Rune: 'd'}},
+			&regexast.Group{Kind: regexast.NonCapture, Flags: "i-s", Sub: regexast.Literal{Rune: 'e'}},
+		}}},
+		{"nested groups", "((a)|(b))", &regexast.Group{Kind: regexast.Capture, Index: 1, Sub: &regexast.Alternate{Subs: []regexast.Node{
+			&regexast.Group{Kind: regexast.Capture, Index: 2, Sub: regexast.Literal{Rune: 'a'}},
+			&regexast.Group{Kind: regexast.Capture, Index: 3, Sub: regexast.Literal{Rune: 'b'}},
+		}}}},
+		{"assertions", "(?=a)(?!b)(?<=c)(?<!d)", &regexast.Concat{Subs: []regexast.Node{
+			&regexast.Group{Kind: regexast.Lookahead, Sub: regexast.Literal{Rune: 'a'}},
+			&regexast.Group{Kind: regexast.NegativeLookahead, Sub: regexast.Literal{Rune: 'b'}},
+			&regexast.Group{Kind: regexast.Lookbehind, Sub: regexast.Literal{Rune: 'c'}},
+			&regexast.Group{Kind: regexast.NegativeLookbehind, Sub: regexast.Literal{Rune: 'd'}},
+		}}},
+		{"flags", "(?i)a", &regexast.Concat{Subs: []regexast.Node{regexast.SetFlags{Flags: "i"}, regexast.Literal{Rune: 'a'}}}},
+		{"anchors", `^\A\b\B\z$.`, &regexast.Concat{Subs: []regexast.Node{
+			regexast.LineStart, regexast.TextStart, regexast.WordBoundary, regexast.NoWordBoundary,
+			regexast.TextEnd, regexast.LineEnd, regexast.Any{},
+		}}},
+		{"escapes", `\.\t\x41\x{263a}é\S\pL\P{Greek}`, &regexast.Concat{Subs: []regexast.Node{
+			regexast.Literal{Rune: '.'}, regexast.Literal{Rune: '\t'}, regexast.Literal{Rune: 'A'},
+			regexast.Literal{Rune: '☺'}, regexast.Literal{Rune: 'é'}, regexast.PerlClass{Class: 's', Negated: true},
+			regexast.UnicodeClass{Name: "L"}, regexast.UnicodeClass{Name: "Greek", Negated: true},
+		}}},
+		{"backreferences", `(a)\1\k<x>`, &regexast.Concat{Subs: []regexast.Node{
+			&regexast.Group{Kind: regexast.Capture, Index: 1, Sub: regexast.Literal{Rune: 'a'}},
+			regexast.Backref{Index: 1}, regexast.Backref{Name: "x"},
+		}}},
+		{"class", `[^a-z_\d[:^space:]\]]`, &regexast.Class{Negated: true, Items: []regexast.ClassItem{
+			regexast.Range{Lo: 'a', Hi: 'z'}, regexast.Range{Lo: '_', Hi: '_'}, regexast.PerlClass{Class: 'd'},
+			regexast.POSIXClass{Name: "space", Negated: true}, regexast.Range{Lo: ']', Hi: ']'},
+		}}},
+		{"class literals", `[]a-][-]`, &regexast.Concat{Subs: []regexast.Node{
+			&regexast.Class{Items: []regexast.ClassItem{regexast.Range{Lo: ']', Hi: ']'}, regexast.Range{Lo: 'a', Hi: 'a'}, regexast.Range{Lo: '-', Hi: '-'}}},
+			&regexast.Class{Items: []regexast.ClassItem{regexast.Range{Lo: '-', Hi: '-'}}},
+		}}},
+		{"quantified group", "(?:ab)+", &regexast.Repeat{Min: 1, Max: -1, Sub: &regexast.Group{Kind: regexast.NonCapture, Sub: &regexast.Concat{Subs: lit("ab")}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := regexast.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, n)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a|b*c`, `a|b*c`},
+		{`(?<x>a)(?i:b)(?i)\k<x>\1`, `(?P<x>a)(?i:b)(?i)\k<x>\1`},
+		{`[\]a-z\-][^\d[:alpha:]]`, `[\]a-z\-][^\d[:alpha:]]`},
+		{`\x41\.\x{7}{}\p{Lu}\pN`, `A\.\x{7}\{\}\p{Lu}\pN`},
+		{`a{2}b{2,}?c{2,3}d??`, `a{2}b{2,}?c{2,3}d??`},
+		{`(?=a)(?<!b)^$\b`, `(?=a)(?<!b)^$\b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := regexast.Parse(tt.input)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.Equal(t, tt.expected, n.String())
+			}
+		})
+	}
+
+	r := &regexast.Repeat{Min: 0, Max: -1, Sub: &regexast.Alternate{Subs: lit("ab")}}
+	assert.Equal(t, "(?:a|b)*", r.String())
+	c := &regexast.Concat{Subs: []regexast.Node{regexast.Literal{Rune: 'x'}, &regexast.Alternate{Subs: lit("ab")}}}
+	assert.Equal(t, "x(?:a|b)", c.String())
+}
+
+func TestWalk(t *testing.T) {
+	n, err := regexast.Parse(`(a(b))|c*`)
+	assert.NoError(t, err)
+	var visited []string
+	regexast.Walk(n, func(n regexast.Node) bool {
+		visited = append(visited, n.String())
+		_, group := n.(*regexast.Group)
+		return !group
+	})
+	assert.Equal(t, []string{"(a(b))|c*", "(a(b))", "c*", "c"}, visited)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"a)", parser.ErrNoMatch, "regexast: line 1, col 2: unexpected ')'"},
+		{"*a", parser.ErrNoMatch, "regexast: line 1, col 1: unexpected '*'"},
+		{"a**", parser.ErrNoMatch, "regexast: line 1, col 3: unexpected '*'"},
+		{"(a", parser.ErrUnexpectedEOF, "regexast: line 1, col 3: unexpected end of input"},
+		{"[a", parser.ErrUnexpectedEOF, "regexast: line 1, col 3: unexpected end of input"},
+		{"[z-a]", parser.ErrNoMatch, "regexast: line 1, col 4: unexpected 'a', expected end of range"},
+		{"[a-b-c]", parser.ErrNoMatch, "regexast: line 1, col 5: unexpected '-'"},
+		{"[[:foo:]]", parser.ErrNoMatch, "regexast: line 1, col 4: unexpected 'f', expected POSIX class name"},
+		{"a{2,1}", regexast.ErrInvalidRepeatCount, "regexast: line 1, col 2: invalid repeat count"},
+		{"ab{3,2}?", regexast.ErrInvalidRepeatCount, "regexast: line 1, col 3: invalid repeat count"},
+		{"a{1001}", regexast.ErrInvalidRepeatCount, "regexast: line 1, col 2: invalid repeat count"},
+		{`\q`, parser.ErrNoMatch, "regexast: line 1, col 2: unexpected 'q', expected escape sequence"},
+		{`[\b]`, parser.ErrNoMatch, "regexast: line 1, col 3: unexpected 'b', expected escape sequence"},
+		{`\xZ1`, parser.ErrNoMatch, "regexast: line 1, col 3: unexpected 'Z', expected hex digit"},
+		{`\p{Klingon}`, parser.ErrNoMatch, "regexast: line 1, col 3: unexpected '{', expected Unicode class name"},
+		{"(?x)", parser.ErrNoMatch, "regexast: line 1, col 3: unexpected 'x'"},
+		{"(?P<>a)", parser.ErrNoMatch, "regexast: line 1, col 5: unexpected '>', expected group name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := regexast.Parse(tt.input)
+			var perr *regexast.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Package search provides error reporting for malformed search queries.
+package search
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a search query could not be parsed. Expected lists what the query should
+// contain at the error, e.g. "term".
+type ParseError = parser.Error
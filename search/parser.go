@@ -0,0 +1,160 @@
+// Package search provides the grammar of search queries, built with the tiny-parsec
+// combinators.
+package search
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// isWord reports whether r may appear in an unquoted value.
+func isWord(r rune) bool {
+	return !isSpace(r) && r != '(' && r != ')' && r != '"'
+}
+
+// isField reports whether r may appear in a field name.
+func isField(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-'
+}
+
+// keywords lists the operators, which are not terms unless quoted.
+var keywords = []string{"AND", "OR", "NOT"}
+
+func isKeyword(s string) bool {
+	for _, k := range keywords {
+		if s == k {
+			return true
+		}
+	}
+	return false
+}
+
+// needsQuotes reports whether the value v of a term must be quoted to read back the same.
+// Free text may not look like a keyword, a negation or a qualified value, and a qualified
+// value without an operator may not start with one.
+func needsQuotes(v string, free, plain bool) bool {
+	if v == "" || strings.IndexFunc(v, func(r rune) bool { return !isWord(r) }) >= 0 {
+		return true
+	}
+	if free {
+		return isKeyword(v) || v[0] == '-' || strings.Contains(v, ":")
+	}
+	return plain && (v[0] == '<' || v[0] == '>')
+}
+
+// quote returns v in double quotes, escaping '"' and '\'.
+func quote(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// ws parses optional whitespace.
+func ws() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isSpace))
+}
+
+// lexeme parses p followed by optional whitespace.
+func lexeme[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitRight(p, ws())
+}
+
+// keyword parses the operator k, which must not be followed by a character of a value.
+func keyword(k string) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if !strings.HasPrefix(s, k) || len(s) > len(k) && isWord(rune(s[len(k)])) {
+			st.Fail(`"` + k + `"`)
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(len(k))
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(k, next))
+	})
+}
+
+// phrase parses a value in double quotes, undoing the escapes \" and \\.
+func phrase() parser.Parser[string] {
+	escape := parser.OmitLeft(parser.Char('\\'), parser.SatisfyMsg(func(r rune) bool { return r == '"' || r == '\\' }, `'"' or '\'`))
+	plain := parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' })
+	return parser.Fmap(parser.Between(parser.Char('"'), parser.ZeroOrMore(parser.OrElse(plain, escape)), parser.Char('"')), parser.Text)
+}
+
+// word parses an unquoted value.
+func word() parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isWord, "value")), parser.Text)
+}
+
+// value parses a quoted or unquoted value into the term t.
+func value(t Term) parser.Parser[Term] {
+	return parser.OrElse(
+		parser.Fmap(phrase(), func(v string) Term { t.Value, t.Phrase = v, true; return t }),
+		parser.Fmap(word(), func(v string) Term { t.Value = v; return t }),
+	)
+}
+
+// qualified parses a value qualified by a field name, e.g. label:bug or stars:>=10.
+func qualified() parser.Parser[Term] {
+	field := parser.OmitRight(parser.Fmap(parser.OneOrMore(parser.Satisfy(isField)), parser.Text), parser.Char(':'))
+	op := parser.Fmap(parser.ZeroOrOne(parser.OrElse(parser.Str(">="), parser.Str("<="), parser.Str(">"), parser.Str("<"))), func(m parser.Maybe[string]) string {
+		return m.Get()
+	})
+	return parser.Bind(field, func(f string) parser.Parser[Term] {
+		return parser.Bind(op, func(o string) parser.Parser[Term] {
+			return value(Term{Field: f, Op: o})
+		})
+	})
+}
+
+// term parses a qualified value, a phrase or a word of free text other than a keyword.
+func term() parser.Parser[Expr] {
+	first := parser.SatisfyMsg(func(r rune) bool { return isWord(r) && r != '-' }, "term")
+	chars := parser.Bind(first, func(r rune) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isWord)), func(rs []rune) string {
+			return parser.Text(append([]rune{r}, rs...))
+		})
+	})
+	free := parser.OrElse(
+		parser.Fmap(phrase(), func(v string) Term { return Term{Value: v, Phrase: true} }),
+		parser.Fmap(parser.SatisfyWithMsg(chars, func(v string) bool { return !isKeyword(v) }, "term"), func(v string) Term {
+			return Term{Value: v}
+		}),
+	)
+	return parser.Fmap(parser.OrElse(qualified(), free), func(t Term) Expr { return t })
+}
+
+// chain parses one or more p separated by sep, combining several with join.
+func chain(p parser.Parser[Expr], sep parser.Parser[string], join func([]Expr) Expr) parser.Parser[Expr] {
+	return parser.Bind(p, func(first Expr) parser.Parser[Expr] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(sep, p)), func(rest []Expr) Expr {
+			if len(rest) == 0 {
+				return first
+			}
+			return join(append([]Expr{first}, rest...))
+		})
+	})
+}
+
+// query parses a query: alternatives of conjunctions of possibly negated terms and groups.
+func query() parser.Parser[Expr] {
+	var or parser.Parser[Expr]
+	ref := parser.Lazy(func() parser.Parser[Expr] { return or })
+	var unary parser.Parser[Expr]
+	unaryRef := parser.Lazy(func() parser.Parser[Expr] { return unary })
+	group := parser.Nested(parser.Between(lexeme(parser.Char('(')), ref, lexeme(parser.Char(')'))))
+	negation := parser.OrElse(parser.Fmap(parser.Char('-'), func(rune) string { return "-" }), lexeme(keyword("NOT")))
+	unary = parser.OrElse(
+		parser.Fmap(parser.OmitLeft(negation, unaryRef), func(x Expr) Expr { return Not{X: x} }),
+		group,
+		lexeme(term()),
+	)
+	and := parser.Fmap(parser.ZeroOrOne(lexeme(keyword("AND"))), func(parser.Maybe[string]) string { return "AND" })
+	conj := chain(unary, and, func(xs []Expr) Expr { return And(xs) })
+	or = chain(conj, lexeme(keyword("OR")), func(xs []Expr) Expr { return Or(xs) })
+	return parser.OmitLeft(ws(), parser.OrElse(or, parser.Pure[Expr](And{})))
+}
@@ -0,0 +1,159 @@
+// Package search parses search queries in the style of GitHub and Jira issue searches, e.g.
+//
+//	status:open author:"jane doe" AND (label:bug OR label:perf) -archived
+//
+// into a boolean expression tree, and evaluates them against an item through a Matcher.
+//
+// A query combines terms, which are free text, a "quoted phrase", or a value qualified by a
+// field name, e.g. label:bug or stars:>=10. Terms written one after the other must all
+// match, as if joined by AND, which binds more tightly than OR. A term, or a parenthesized
+// query, is negated by a leading "-" or by NOT. The keywords AND, OR and NOT are only
+// recognized in upper case.
+package search
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Matcher decides whether the item being searched matches the terms of a query.
+type Matcher interface {
+	// Match reports whether the item matches the term t.
+	Match(t Term) (bool, error)
+}
+
+// MatchFunc is a Matcher calling a function.
+type MatchFunc func(t Term) (bool, error)
+
+// Match implements the Matcher interface.
+func (f MatchFunc) Match(t Term) (bool, error) {
+	return f(t)
+}
+
+// Expr is implemented by the nodes of a query: Term, Not, And and Or.
+type Expr interface {
+	// Eval reports whether the item matched by m matches the expression. Operands are
+	// evaluated from left to right, only as far as needed to decide, and the first error of
+	// m is returned.
+	Eval(m Matcher) (bool, error)
+	// String returns the expression in query syntax.
+	String() string
+}
+
+// Term is a single condition of a query.
+type Term struct {
+	// Field is the field name qualifying the value, e.g. "label", or empty for free text.
+	Field string
+	// Op is the comparison of a qualified value: "" for a plain match, or one of ">", ">=",
+	// "<" and "<=", as in stars:>=10.
+	Op string
+	// Value is the value or text to match, with the quotes and escapes of a phrase undone.
+	Value string
+	// Phrase is set for values written in double quotes.
+	Phrase bool
+}
+
+// Eval implements the Expr interface.
+func (t Term) Eval(m Matcher) (bool, error) {
+	return m.Match(t)
+}
+
+// String returns the term as written, quoting the value if needed.
+func (t Term) String() string {
+	v := t.Value
+	if t.Phrase || needsQuotes(v, t.Field == "", t.Op == "") {
+		v = quote(v)
+	}
+	if t.Field == "" {
+		return v
+	}
+	return t.Field + ":" + t.Op + v
+}
+
+// Not matches items that its operand does not match.
+type Not struct {
+	X Expr
+}
+
+// Eval implements the Expr interface.
+func (n Not) Eval(m Matcher) (bool, error) {
+	ok, err := n.X.Eval(m)
+	return !ok && err == nil, err
+}
+
+// String returns the operand preceded by "-".
+func (n Not) String() string {
+	switch n.X.(type) {
+	case And, Or:
+		return "-(" + n.X.String() + ")"
+	}
+	return "-" + n.X.String()
+}
+
+// And matches items that all of its operands match. An And without operands, as returned
+// for an empty query, matches every item.
+type And []Expr
+
+// Eval implements the Expr interface.
+func (a And) Eval(m Matcher) (bool, error) {
+	for _, x := range a {
+		if ok, err := x.Eval(m); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// String returns the operands separated by spaces, grouping alternatives.
+func (a And) String() string {
+	xs := make([]string, len(a))
+	for i, x := range a {
+		xs[i] = x.String()
+		if _, ok := x.(Or); ok {
+			xs[i] = "(" + xs[i] + ")"
+		}
+	}
+	return strings.Join(xs, " ")
+}
+
+// Or matches items that any of its operands matches.
+type Or []Expr
+
+// Eval implements the Expr interface.
+func (o Or) Eval(m Matcher) (bool, error) {
+	for _, x := range o {
+		if ok, err := x.Eval(m); ok || err != nil {
+			return ok && err == nil, err
+		}
+	}
+	return false, nil
+}
+
+// String returns the operands separated by " OR ".
+func (o Or) String() string {
+	xs := make([]string, len(o))
+	for i, x := range o {
+		xs[i] = x.String()
+	}
+	return strings.Join(xs, " OR ")
+}
+
+// Parse parses a search query. An empty query, or one of only whitespace, parses as an
+// empty And, which matches every item.
+func Parse(q string) (Expr, error) {
+	x, err := parser.Run(query(), q)
+	if err != nil {
+		return nil, parser.Wrap(err, "search")
+	}
+	return x, nil
+}
+
+// Match parses the query q and evaluates it with m.
+func Match(q string, m Matcher) (bool, error) {
+	x, err := Parse(q)
+	if err != nil {
+		return false, err
+	}
+	return x.Eval(m)
+}
@@ -0,0 +1,163 @@
+package search_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	x, err := search.Parse(`status:open author:"jane doe" AND (label:bug OR label:perf) -archived`)
+	assert.NoError(t, err)
+	assert.Equal(t, search.And{
+		search.Term{Field: "status", Value: "open"},
+		search.Term{Field: "author", Value: "jane doe", Phrase: true},
+		search.Or{search.Term{Field: "label", Value: "bug"}, search.Term{Field: "label", Value: "perf"}},
+		search.Not{X: search.Term{Value: "archived"}},
+	}, x)
+
+	tests := []struct {
+		input    string
+		expected search.Expr
+	}{
+		{"", search.And{}},
+		{"  ", search.And{}},
+		{"bug", search.Term{Value: "bug"}},
+		{`"a \"quoted\" \\ phrase"`, search.Term{Value: `a "quoted" \ phrase`, Phrase: true}},
+		{"a OR b c", search.Or{search.Term{Value: "a"}, search.And{search.Term{Value: "b"}, search.Term{Value: "c"}}}},
+		{"stars:>=10 created:<2024-01-01", search.And{
+			search.Term{Field: "stars", Op: ">=", Value: "10"},
+			search.Term{Field: "created", Op: "<", Value: "2024-01-01"},
+		}},
+		{"NOT(a OR b)", search.Not{X: search.Or{search.Term{Value: "a"}, search.Term{Value: "b"}}}},
+		{"NOT -a", search.Not{X: search.Not{X: search.Term{Value: "a"}}}},
+		{"and or not ANDROID", search.And{
+			search.Term{Value: "and"}, search.Term{Value: "or"}, search.Term{Value: "not"}, search.Term{Value: "ANDROID"},
+		}},
+		{"status: open", search.And{search.Term{Value: "status:"}, search.Term{Value: "open"}}},
+		{"url:http://x.y/z", search.Term{Field: "url", Value: "http://x.y/z"}},
+		{"( a )", search.Term{Value: "a"}},
+		{"über café", search.And{search.Term{Value: "über"}, search.Term{Value: "café"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			x, err := search.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, x)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`status:open author:"jane doe" AND (label:bug OR label:perf) -archived`, `status:open author:"jane doe" (label:bug OR label:perf) -archived`},
+		{`NOT (a b) OR "x"`, `-(a b) OR "x"`},
+		{`stars:>10 title:">x" "AND" "-x" "a:b"`, `stars:>10 title:">x" "AND" "-x" "a:b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			x, err := search.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, x.String())
+		})
+	}
+	assert.Equal(t, `"" tag:"a b" "x\"y"`, search.And{search.Term{}, search.Term{Field: "tag", Value: "a b"}, search.Term{Value: `x"y`}}.String())
+}
+
+// issue is an item to search, matched by field.
+type issue map[string]string
+
+func (i issue) Match(t search.Term) (bool, error) {
+	if t.Field == "" {
+		return strings.Contains(i["title"], t.Value), nil
+	}
+	v, ok := i[t.Field]
+	if !ok {
+		return false, errors.New("unknown field " + t.Field)
+	}
+	if t.Op == "" {
+		return v == t.Value, nil
+	}
+	a, _ := strconv.Atoi(v)
+	b, _ := strconv.Atoi(t.Value)
+	switch t.Op {
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	case "<":
+		return a < b, nil
+	}
+	return a <= b, nil
+}
+
+func TestMatch(t *testing.T) {
+	i := issue{"title": "crash on start", "status": "open", "label": "bug", "stars": "12"}
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"", true},
+		{"crash", true},
+		{`"on start" status:open`, true},
+		{"status:open label:perf", false},
+		{"label:perf OR label:bug", true},
+		{"-label:bug", false},
+		{"NOT (status:closed OR label:perf) stars:>=12", true},
+		{"stars:>12", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ok, err := search.Match(tt.query, i)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	_, err := search.Match("crash OR milestone:v1", i)
+	assert.NoError(t, err)
+	_, err = search.Match("-milestone:v1", i)
+	assert.EqualError(t, err, "unknown field milestone")
+
+	var seen []string
+	m := search.MatchFunc(func(t search.Term) (bool, error) {
+		seen = append(seen, t.Value)
+		return t.Value == "b", nil
+	})
+	ok, err := search.Match("(a b c) OR b OR c", m)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, seen)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"(a OR b", parser.ErrUnexpectedEOF, "search: line 1, col 8: unexpected end of input"},
+		{"a OR", parser.ErrUnexpectedEOF, "search: line 1, col 5: unexpected end of input"},
+		{"a)", parser.ErrNoMatch, "search: line 1, col 2: unexpected ')'"},
+		{"- a", parser.ErrNoMatch, "search: line 1, col 2: unexpected ' '"},
+		{`"open`, parser.ErrUnexpectedEOF, "search: line 1, col 6: unexpected end of input"},
+		{"AND a", parser.ErrNoMatch, "search: line 1, col 1: unexpected 'A', expected term"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := search.Parse(tt.input)
+			var perr *search.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
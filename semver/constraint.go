@@ -0,0 +1,313 @@
+// Package semver provides version constraints such as "^1.2.3" or ">=1.0 <2.0 || 3.x", with the
+// semantics of npm.
+package semver
+
+import (
+	"slices"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Constraint is a set of version ranges, as used by package managers to declare the versions
+// of a dependency they accept. A version satisfies the constraint if it is in any of the ranges.
+//
+// The syntax is that of npm:
+//
+//	1.2.3, =1.2.3          exactly 1.2.3
+//	>1.2.3, >=1.2.3        comparisons, also "<" and "<="
+//	1.x, 1.2.*, 1, *       x-ranges: any version matching the given numbers
+//	~1.2.3, ~1.2           tilde ranges: patch updates, >=1.2.3 <1.3.0
+//	^1.2.3, ^0.2.3         caret ranges: updates not changing the leftmost non-zero number
+//	1.2.3 - 2.3            hyphen ranges: inclusive bounds, >=1.2.3 <2.4.0
+//	>=1.0 <2.0             space-separated comparators must all hold
+//	1.x || >=2.5.0         ranges separated by "||" are alternatives
+//
+// A missing number in a comparison acts as a wildcard: ">1.2" means ">=1.3.0" and "<=1.2" means
+// "<1.3.0". An empty constraint accepts any version.
+//
+// A prerelease version only satisfies a range if one of its comparators names a prerelease of
+// the same major, minor and patch numbers: ">=1.2.3-beta" accepts "1.2.3-rc" but not
+// "1.2.4-rc". This keeps unstable versions out of ranges that do not opt into them.
+type Constraint struct {
+	src    string
+	ranges [][]comparator
+}
+
+// comparator compares versions with a bound.
+type comparator struct {
+	op string
+	v  Version
+}
+
+// test reports whether v compares with the bound as required.
+func (c comparator) test(v Version) bool {
+	r := v.Compare(c.v)
+	switch c.op {
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	}
+	return r == 0
+}
+
+// partial is a version with wildcards or missing numbers, e.g. "1.x" or "1.2". Only the first
+// n numbers of v are given; the prerelease is only kept if all three are.
+type partial struct {
+	n int
+	v Version
+}
+
+// lower returns the lowest version matching p, e.g. 1.2.0 for "1.2".
+func (p partial) lower() Version {
+	return p.v
+}
+
+// upper returns the lowest version above those matching p as a bound excluding the
+// prereleases of that version, e.g. 1.3.0-0 for "1.2". For a complete version it returns the
+// version itself.
+func (p partial) upper() Version {
+	v := Version{Major: p.v.Major, Minor: p.v.Minor, Prerelease: []string{"0"}}
+	switch p.n {
+	case 1:
+		v.Major++
+		v.Minor = 0
+	case 2:
+		v.Minor++
+	default:
+		return p.v
+	}
+	return v
+}
+
+// anyVersion is a comparator set accepting any version.
+var anyVersion = []comparator{{">=", Version{}}}
+
+// none is a comparator set accepting no version.
+var none = []comparator{{"<", Version{Prerelease: []string{"0"}}}}
+
+// xRange returns the comparators of a bare partial version, e.g. ">=1.2.0 <1.3.0-0" for "1.2".
+func xRange(p partial) []comparator {
+	switch p.n {
+	case 0:
+		return anyVersion
+	case 3:
+		return []comparator{{"=", p.v}}
+	}
+	return []comparator{{">=", p.lower()}, {"<", p.upper()}}
+}
+
+// primitive returns the comparators of a comparison with a partial version.
+func primitive(op string, p partial) []comparator {
+	if p.n == 3 {
+		return []comparator{{op, p.v}}
+	}
+	switch op {
+	case ">":
+		if p.n == 0 {
+			return none
+		}
+		return []comparator{{">=", p.upper()}}
+	case ">=":
+		return []comparator{{">=", p.lower()}}
+	case "<":
+		return []comparator{{"<", Version{Prerelease: []string{"0"}, Major: p.v.Major, Minor: p.v.Minor}}}
+	case "<=":
+		if p.n == 0 {
+			return anyVersion
+		}
+		return []comparator{{"<", p.upper()}}
+	}
+	return xRange(p)
+}
+
+// tilde returns the comparators of "~p": patch updates if the minor number is given, minor
+// updates otherwise.
+func tilde(p partial) []comparator {
+	if p.n == 0 {
+		return anyVersion
+	}
+	up := partial{n: min(p.n, 2), v: p.v}
+	return []comparator{{">=", p.lower()}, {"<", up.upper()}}
+}
+
+// caret returns the comparators of "^p": updates that do not change the leftmost non-zero
+// number, or the last given number if they are all zero.
+func caret(p partial) []comparator {
+	if p.n == 0 {
+		return anyVersion
+	}
+	switch {
+	case p.v.Major != 0 || p.n == 1:
+		return []comparator{{">=", p.lower()}, {"<", partial{n: 1, v: p.v}.upper()}}
+	case p.v.Minor != 0 || p.n == 2:
+		return []comparator{{">=", p.lower()}, {"<", partial{n: 2, v: p.v}.upper()}}
+	}
+	return []comparator{{">=", p.lower()}, {"<", Version{Patch: p.v.Patch + 1, Prerelease: []string{"0"}}}}
+}
+
+// hyphen returns the comparators of "lo - hi", which includes both bounds.
+func hyphen(lo, hi partial) []comparator {
+	var cs []comparator
+	if lo.n > 0 {
+		cs = append(cs, comparator{">=", lo.lower()})
+	}
+	switch hi.n {
+	case 0:
+	case 3:
+		cs = append(cs, comparator{"<=", hi.v})
+	default:
+		cs = append(cs, comparator{"<", hi.upper()})
+	}
+	if cs == nil {
+		return anyVersion
+	}
+	return cs
+}
+
+// xr parses a version number or a wildcard, 'x', 'X' or '*'.
+func xr() parser.Parser[parser.Maybe[uint64]] {
+	wildcard := parser.Fmap(parser.OrElse(parser.Char('x'), parser.Char('X'), parser.Char('*')), func(rune) parser.Maybe[uint64] {
+		return parser.Nothing[uint64]()
+	})
+	return parser.OrElse(wildcard, parser.Fmap(number(), parser.Just[uint64]))
+}
+
+// partialVersion parses a version whose numbers may be wildcards or missing, e.g. "1.x" or "1.2".
+// Numbers following a wildcard are ignored.
+func partialVersion() parser.Parser[partial] {
+	qualifier := parser.Bind(
+		parser.ZeroOrOne(parser.OmitLeft(parser.Char('-'), dotted(parser.SatisfyWithMsg(identifier(), noLeadingZero, "identifier without leading zeros")))),
+		func(pre parser.Maybe[[]string]) parser.Parser[[]string] {
+			return parser.OmitRight(parser.Pure(pre.Get()), parser.ZeroOrOne(parser.OmitLeft(parser.Char('+'), dotted(identifier()))))
+		},
+	)
+	next := parser.OmitLeft(parser.Char('.'), xr())
+	return parser.Bind(xr(), func(major parser.Maybe[uint64]) parser.Parser[partial] {
+		return parser.Bind(parser.ZeroOrOne(next), func(minor parser.Maybe[parser.Maybe[uint64]]) parser.Parser[partial] {
+			return parser.Bind(parser.ZeroOrOne(next), func(patch parser.Maybe[parser.Maybe[uint64]]) parser.Parser[partial] {
+				return parser.Fmap(qualifier, func(pre []string) partial {
+					nums := []parser.Maybe[uint64]{major}
+					if minor.IsJust() {
+						nums = append(nums, minor.Get())
+					}
+					if patch.IsJust() {
+						nums = append(nums, patch.Get())
+					}
+					var p partial
+					fields := []*uint64{&p.v.Major, &p.v.Minor, &p.v.Patch}
+					for i, n := range nums {
+						if n.IsNothing() {
+							break
+						}
+						*fields[i] = n.Get()
+						p.n++
+					}
+					if p.n == 3 {
+						p.v.Prerelease = pre
+					}
+					return p
+				})
+			})
+		})
+	})
+}
+
+// constraint parses ranges separated by "||", each made of comparators separated by
+// whitespace or of a hyphen range.
+func constraint() parser.Parser[[][]comparator] {
+	blank := parser.SatisfyMsg(func(r rune) bool { return r == ' ' || r == '\t' }, "whitespace")
+	spaces := parser.ZeroOrMore(blank)
+	spaces1 := parser.OneOrMore(blank)
+	op := parser.OmitRight(parser.OrElse(
+		parser.Str(">="), parser.Str("<="), parser.Str(">"), parser.Str("<"), parser.Str("="),
+	), spaces)
+	simple := parser.OrElse(
+		parser.Bind(op, func(op string) parser.Parser[[]comparator] {
+			return parser.Fmap(partialVersion(), func(p partial) []comparator { return primitive(op, p) })
+		}),
+		parser.Fmap(parser.OmitLeft(parser.OmitRight(parser.Char('~'), spaces), partialVersion()), tilde),
+		parser.Fmap(parser.OmitLeft(parser.OmitRight(parser.Char('^'), spaces), partialVersion()), caret),
+		parser.Fmap(partialVersion(), xRange),
+	)
+	hyphenRange := parser.Bind(parser.OmitRight(partialVersion(), parser.OmitLeft(spaces1, parser.OmitRight(parser.Char('-'), spaces1))), func(lo partial) parser.Parser[[]comparator] {
+		return parser.Fmap(partialVersion(), func(hi partial) []comparator { return hyphen(lo, hi) })
+	})
+	simples := parser.Fmap(parser.SepBy(simple, spaces1), func(css [][]comparator) []comparator {
+		if len(css) == 0 {
+			return anyVersion
+		}
+		return slices.Concat(css...)
+	})
+	rng := parser.Between(spaces, parser.OrElse(hyphenRange, simples), spaces)
+	return parser.SepBy(rng, parser.Str("||"))
+}
+
+// ParseConstraint parses a constraint such as "^1.2.3" or ">=1.0 <2.0 || 3.x".
+func ParseConstraint(s string) (*Constraint, error) {
+	ranges, err := parser.Run(constraint(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "semver")
+	}
+	return &Constraint{src: s, ranges: ranges}, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics if s is not a valid constraint.
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the source of the constraint.
+func (c *Constraint) String() string {
+	return c.src
+}
+
+// Check reports whether v satisfies the constraint.
+func (c *Constraint) Check(v Version) bool {
+	for _, cs := range c.ranges {
+		if allows(cs, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether v satisfies all the comparators of a range, and if v is a prerelease,
+// whether one of them names a prerelease of the same version.
+func allows(cs []comparator, v Version) bool {
+	for _, c := range cs {
+		if !c.test(v) {
+			return false
+		}
+	}
+	if !v.IsPrerelease() {
+		return true
+	}
+	for _, c := range cs {
+		if c.v.IsPrerelease() && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// Max returns the version with the highest precedence among those of vs satisfying the
+// constraint, and false if there is none.
+func (c *Constraint) Max(vs []Version) (Version, bool) {
+	var best Version
+	found := false
+	for _, v := range vs {
+		if c.Check(v) && (!found || v.Compare(best) > 0) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
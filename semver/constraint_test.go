@@ -0,0 +1,100 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		accepts    []string
+		rejects    []string
+	}{
+		{"1.2.3", []string{"1.2.3", "1.2.3+build"}, []string{"1.2.4", "1.2.3-rc"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.2"}},
+		{">1.2.3", []string{"1.2.4", "2.0.0"}, []string{"1.2.3", "1.2.4-rc"}},
+		{"<=1.2.3", []string{"1.2.3", "0.1.0"}, []string{"1.2.4"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{">=1.2", []string{"1.2.0"}, []string{"1.1.9"}},
+		{"<1.2", []string{"1.1.9"}, []string{"1.2.0", "1.2.0-rc"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.0", "1.5.0-rc"}},
+		{"1.2.*", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"1", []string{"1.4.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.0", "9.9.9"}, []string{"1.0.0-rc"}},
+		{"", []string{"1.0.0"}, nil},
+		{">*", nil, []string{"0.0.0", "1.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"2.0.0", "2.0.0-rc", "1.2.2"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0.0", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^0.x", []string{"0.0.0", "0.9.0"}, []string{"1.0.0"}},
+		{"^1.2.3-beta.2", []string{"1.2.3-beta.3", "1.2.3", "1.5.0"}, []string{"1.2.3-beta.1", "1.2.4-beta.3"}},
+		{"1.2.3 - 2.3.4", []string{"1.2.3", "2.3.4"}, []string{"1.2.2", "2.3.5"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.9"}, []string{"1.1.9", "2.4.0"}},
+		{">=1.0 <2.0 || 3.x", []string{"1.0.0", "1.9.9", "3.1.0"}, []string{"2.0.0", "4.0.0"}},
+		{"  >= 1.2.3   <  2 ", []string{"1.2.3"}, []string{"2.0.0"}},
+		{"1.2.3||2.x", []string{"1.2.3", "2.1.0"}, []string{"1.2.4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := semver.ParseConstraint(tt.constraint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.constraint, c.String())
+			for _, v := range tt.accepts {
+				assert.True(t, c.Check(semver.MustParse(v)), v)
+			}
+			for _, v := range tt.rejects {
+				assert.False(t, c.Check(semver.MustParse(v)), v)
+			}
+		})
+	}
+}
+
+func TestConstraintMax(t *testing.T) {
+	var vs []semver.Version
+	for _, s := range []string{"1.0.0", "1.4.2", "1.10.0", "2.0.0-rc.1", "2.0.0", "1.11.0-beta"} {
+		vs = append(vs, semver.MustParse(s))
+	}
+	best, ok := semver.MustParseConstraint("^1.2").Max(vs)
+	assert.True(t, ok)
+	assert.Equal(t, "1.10.0", best.String())
+
+	best, ok = semver.MustParseConstraint("~1.4 || >=2.0.0-rc <2.0.0").Max(vs)
+	assert.True(t, ok)
+	assert.Equal(t, "2.0.0-rc.1", best.String())
+
+	_, ok = semver.MustParseConstraint(">=3").Max(vs)
+	assert.False(t, ok)
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{">=1.0<2.0", parser.ErrNoMatch, "semver: line 1, col 6: unexpected '<'"},
+		{"^v1", parser.ErrNoMatch, "semver: line 1, col 2: unexpected 'v'"},
+		{"1.2.3 ||| 2", parser.ErrNoMatch, "semver: line 1, col 9: unexpected '|'"},
+		{"1.2.3 -", parser.ErrUnexpectedEOF, "semver: line 1, col 8: unexpected end of input"},
+		{"01.2", parser.ErrNoMatch, "semver: line 1, col 1: unexpected '0', expected number without leading zeros"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := semver.ParseConstraint(tt.input)
+			var perr *semver.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+	assert.Panics(t, func() { semver.MustParseConstraint("~>1") })
+}
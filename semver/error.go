@@ -0,0 +1,8 @@
+// Package semver provides error reporting for malformed versions.
+package semver
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a version could not be parsed. Expected lists what the version should
+// contain at the error, e.g. "'.'".
+type ParseError = parser.Error
@@ -0,0 +1,72 @@
+// Package semver provides the grammar of semantic versions, built with the tiny-parsec
+// numeric and identifier primitives.
+package semver
+
+import (
+	"strconv"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// isNumeric reports whether the identifier s consists of digits only.
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// noLeadingZero reports whether s is not a numeric identifier with a leading zero, e.g. "01".
+func noLeadingZero(s string) bool {
+	return !isNumeric(s) || s == "0" || s[0] != '0'
+}
+
+// number parses a version number: digits without a leading zero, fitting in a uint64.
+func number() parser.Parser[uint64] {
+	digits := parser.SatisfyWithMsg(parser.Digits(), noLeadingZero, "number without leading zeros")
+	return parser.Fmap(
+		parser.SatisfyWithMsg(digits, func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		}, "number below 2^64"),
+		func(s string) uint64 {
+			n, _ := strconv.ParseUint(s, 10, 64)
+			return n
+		},
+	)
+}
+
+// identifier parses a non-empty run of ASCII letters, digits and hyphens.
+func identifier() parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(func(r rune) bool {
+		return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-'
+	}, "identifier")), parser.Text)
+}
+
+// dotted parses one or more identifiers separated by dots.
+func dotted(p parser.Parser[string]) parser.Parser[[]string] {
+	return parser.Bind(p, func(first string) parser.Parser[[]string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(parser.Char('.'), p)), func(rest []string) []string {
+			return append([]string{first}, rest...)
+		})
+	})
+}
+
+// version parses "MAJOR.MINOR.PATCH", followed by an optional "-prerelease" and "+build".
+// Numeric prerelease identifiers may not have leading zeros; build identifiers may.
+func version() parser.Parser[Version] {
+	core := parser.Seq(number(), parser.OmitLeft(parser.Char('.'), number()), parser.OmitLeft(parser.Char('.'), number()))
+	pre := parser.ZeroOrOne(parser.OmitLeft(parser.Char('-'), dotted(
+		parser.SatisfyWithMsg(identifier(), noLeadingZero, "identifier without leading zeros"),
+	)))
+	build := parser.ZeroOrOne(parser.OmitLeft(parser.Char('+'), dotted(identifier())))
+	return parser.Bind(core, func(nums []uint64) parser.Parser[Version] {
+		return parser.Bind(pre, func(pre parser.Maybe[[]string]) parser.Parser[Version] {
+			return parser.Fmap(build, func(build parser.Maybe[[]string]) Version {
+				return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Prerelease: pre.Get(), Build: build.Get()}
+			})
+		})
+	})
+}
@@ -0,0 +1,132 @@
+// Package semver parses and compares semantic versions as defined by Semantic Versioning 2.0.0,
+// e.g. "1.4.2-rc.1+build.5".
+package semver
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Version is a semantic version.
+type Version struct {
+	Major, Minor, Patch uint64
+	// Prerelease lists the dot-separated prerelease identifiers, e.g. "rc" and "1" in
+	// "1.0.0-rc.1"; it is empty for a release.
+	Prerelease []string
+	// Build lists the dot-separated build metadata identifiers, which do not affect precedence.
+	Build []string
+}
+
+// Parse parses a semantic version. A leading "v" as in "v1.2.3" is not part of the syntax
+// and is rejected.
+func Parse(s string) (Version, error) {
+	v, err := parser.Run(version(), s)
+	if err != nil {
+		return Version{}, parser.Wrap(err, "semver")
+	}
+	return v, nil
+}
+
+// MustParse is like Parse but panics if s is not a valid version.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the version in its canonical form, e.g. "1.0.0-rc.1+build.5".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// IsPrerelease reports whether v has prerelease identifiers.
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or +1 depending on whether v has a lower, the same or a higher
+// precedence than w. Major, minor and patch numbers are compared numerically; a prerelease has
+// a lower precedence than the release, and prereleases are compared identifier by identifier,
+// numerically for numeric identifiers, which have a lower precedence than the others, and in
+// ASCII order otherwise. A shorter list of identifiers that is a prefix of the other has a lower
+// precedence. Build metadata is ignored.
+func (v Version) Compare(w Version) int {
+	if c := cmp.Compare(v.Major, w.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, w.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, w.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.Prerelease) == 0 && len(w.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(w.Prerelease) == 0:
+		return -1
+	}
+	for i := range min(len(v.Prerelease), len(w.Prerelease)) {
+		if c := compareIdentifiers(v.Prerelease[i], w.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.Prerelease), len(w.Prerelease))
+}
+
+// compareIdentifiers compares two prerelease identifiers. Numeric identifiers may be too large
+// for an integer, but having no leading zeros, they compare by length first.
+func compareIdentifiers(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+	switch {
+	case an && bn:
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Less reports whether v has a lower precedence than w.
+func (v Version) Less(w Version) bool {
+	return v.Compare(w) < 0
+}
+
+// Compare returns v.Compare(w); it can be passed to slices.SortFunc to sort versions by precedence.
+func Compare(v, w Version) int {
+	return v.Compare(w)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that versions can be decoded from
+// configuration files.
+func (v *Version) UnmarshalText(b []byte) error {
+	w, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*v = w
+	return nil
+}
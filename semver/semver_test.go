@@ -0,0 +1,112 @@
+package semver_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  semver.Version
+	}{
+		{"0.0.0", semver.Version{}},
+		{"1.2.3", semver.Version{Major: 1, Minor: 2, Patch: 3}},
+		{"10.20.30-rc.1", semver.Version{Major: 10, Minor: 20, Patch: 30, Prerelease: []string{"rc", "1"}}},
+		{"1.0.0-alpha-beta.0.x-y", semver.Version{Major: 1, Prerelease: []string{"alpha-beta", "0", "x-y"}}},
+		{"1.0.0-0A.is.legal", semver.Version{Major: 1, Prerelease: []string{"0A", "is", "legal"}}},
+		{"1.0.0+build.007", semver.Version{Major: 1, Build: []string{"build", "007"}}},
+		{"1.0.0-rc.1+exp.sha.5114f85", semver.Version{Major: 1, Prerelease: []string{"rc", "1"}, Build: []string{"exp", "sha", "5114f85"}}},
+		{"18446744073709551615.0.0", semver.Version{Major: 18446744073709551615}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := semver.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v)
+			assert.Equal(t, tt.input, v.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"1.2", parser.ErrUnexpectedEOF, "semver: line 1, col 4: unexpected end of input, expected digit or '.'"},
+		{"v1.2.3", parser.ErrNoMatch, "semver: line 1, col 1: unexpected 'v', expected digit"},
+		{"01.2.3", parser.ErrNoMatch, "semver: line 1, col 1: unexpected '0', expected number without leading zeros"},
+		{"1.2.3-01", parser.ErrNoMatch, "semver: line 1, col 7: unexpected '0', expected identifier without leading zeros"},
+		{"1.2.3-", parser.ErrUnexpectedEOF, "semver: line 1, col 7: unexpected end of input, expected identifier"},
+		{"1.2.3-a..b", parser.ErrNoMatch, "semver: line 1, col 9: unexpected '.', expected identifier"},
+		{"1.2.3+", parser.ErrUnexpectedEOF, "semver: line 1, col 7: unexpected end of input, expected identifier"},
+		{"1.2.3_4", parser.ErrNoMatch, "semver: line 1, col 6: unexpected '_'"},
+		{"18446744073709551616.0.0", parser.ErrNoMatch, "semver: line 1, col 1: unexpected '1', expected number below 2^64"},
+		{"1.2.3 ", parser.ErrNoMatch, "semver: line 1, col 6: unexpected ' '"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := semver.Parse(tt.input)
+			var perr *semver.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+	assert.Panics(t, func() { semver.MustParse("1") })
+}
+
+func TestCompare(t *testing.T) {
+	// In increasing order of precedence, from the semver specification.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"1.10.0",
+		"2.0.0-99999999999999999999999",
+		"2.0.0-99999999999999999999999a",
+		"2.0.0",
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, semver.MustParse(a).Compare(semver.MustParse(b)), "%s <=> %s", a, b)
+		}
+	}
+
+	assert.Equal(t, 0, semver.MustParse("1.0.0+a").Compare(semver.MustParse("1.0.0+b")))
+	assert.True(t, semver.MustParse("1.0.0-rc.1").Less(semver.MustParse("1.0.0")))
+	assert.True(t, semver.MustParse("1.0.0-rc.1").IsPrerelease())
+
+	vs := []semver.Version{semver.MustParse("1.10.0"), semver.MustParse("1.2.0"), semver.MustParse("1.2.0-rc.1")}
+	slices.SortFunc(vs, semver.Compare)
+	assert.Equal(t, "1.2.0-rc.1 1.2.0 1.10.0", vs[0].String()+" "+vs[1].String()+" "+vs[2].String())
+}
+
+func TestText(t *testing.T) {
+	var v semver.Version
+	assert.NoError(t, v.UnmarshalText([]byte("2.1.0-beta+x")))
+	assert.Equal(t, semver.Version{Major: 2, Minor: 1, Prerelease: []string{"beta"}, Build: []string{"x"}}, v)
+	b, err := v.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1.0-beta+x", string(b))
+	assert.Error(t, v.UnmarshalText([]byte("2.1")))
+}
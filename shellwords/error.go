@@ -0,0 +1,8 @@
+// Package shellwords provides error reporting for malformed command lines.
+package shellwords
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a command line could not be split. Expected lists what the command line
+// should contain at the error, e.g. "'\"'".
+type ParseError = parser.Error
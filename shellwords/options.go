@@ -0,0 +1,36 @@
+// Package shellwords provides options for splitting command lines.
+package shellwords
+
+import "os"
+
+// Option configures Split.
+type Option func(*config)
+
+// config holds the settings of Split.
+type config struct {
+	// lookupEnv looks up the variables of expansions, which are not expanded if it is nil.
+	lookupEnv func(name string) (string, bool)
+}
+
+// newConfig returns the configuration set by opts.
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ExpandEnv expands $NAME and ${NAME} outside single quotes to the value of the environment
+// variable NAME, or to nothing if it is not set. Without it, '$' is an ordinary character.
+func ExpandEnv() Option {
+	return ExpandWith(os.LookupEnv)
+}
+
+// ExpandWith expands variables like ExpandEnv, looking them up with lookup instead of in the
+// environment, e.g. to supply fixed values in tests.
+func ExpandWith(lookup func(name string) (string, bool)) Option {
+	return func(c *config) {
+		c.lookupEnv = lookup
+	}
+}
@@ -0,0 +1,157 @@
+// Package shellwords provides the grammar of command lines, built with the tiny-parsec
+// combinators.
+package shellwords
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// isOperator reports whether r is a shell operator or starts a command substitution, which
+// must be quoted to appear in a word.
+func isOperator(r rune) bool {
+	return strings.ContainsRune("|&;<>()`", r)
+}
+
+func isNameStart(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_'
+}
+
+func isName(r rune) bool {
+	return isNameStart(r) || r >= '0' && r <= '9'
+}
+
+// part is a piece of a word.
+type part struct {
+	s string
+	// literal is set for quoted and plain text, which make a word even if empty, unlike
+	// expansions and line continuations.
+	literal bool
+}
+
+// ws parses blanks and comments, which run from a '#' starting a word to the end of the
+// line.
+func ws() parser.Parser[[][]rune] {
+	comment := parser.OmitLeft(parser.Char('#'), parser.ZeroOrMore(parser.NotChar('\n')))
+	return parser.ZeroOrMore(parser.OrElse(parser.OneOrMore(parser.Satisfy(isBlank)), comment))
+}
+
+// name parses the name of a variable.
+func name() parser.Parser[string] {
+	return parser.Bind(parser.SatisfyMsg(isNameStart, "variable name"), func(first rune) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isName)), func(rest []rune) string {
+			return parser.Text(append([]rune{first}, rest...))
+		})
+	})
+}
+
+// dollar parses a '$' that does not start an expansion, which stands for itself. A '$'
+// followed by '{' must start a braced expansion.
+func dollar() parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		if !strings.HasPrefix(s, "$") || strings.HasPrefix(s, "${") {
+			st.Fail("'$'")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(1)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple("$", next))
+	})
+}
+
+// expansion parses $NAME or ${NAME}, returning the value of the variable, and fails if
+// variables are not expanded.
+func (c *config) expansion() parser.Parser[string] {
+	if c.lookupEnv == nil {
+		return parser.Fail[string]()
+	}
+	braced := parser.Between(parser.Str("${"), name(), parser.Char('}'))
+	return parser.Fmap(parser.OrElse(braced, parser.OmitLeft(parser.Char('$'), name())), func(name string) string {
+		v, _ := c.lookupEnv(name)
+		return v
+	})
+}
+
+// single parses text in single quotes, which is taken literally.
+func single() parser.Parser[string] {
+	return parser.Fmap(parser.Between(parser.Char('\''), parser.ZeroOrMore(parser.NotChar('\'')), parser.Char('\'')), parser.Text)
+}
+
+// double parses text in double quotes, where a backslash only escapes '$', '`', '"', '\'
+// and newlines, and variables are expanded.
+func (c *config) double() parser.Parser[string] {
+	escaped := parser.OrElse(
+		parser.Fmap(parser.Satisfy(func(r rune) bool { return strings.ContainsRune("$`\"\\", r) }), func(r rune) string { return string(r) }),
+		parser.Fmap(parser.Char('\n'), func(rune) string { return "" }),
+		parser.Pure(`\`),
+	)
+	plain := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool {
+		return r != '"' && r != '\\' && (r != '$' || c.lookupEnv == nil)
+	})), parser.Text)
+	content := parser.OrElse(plain, parser.OmitLeft(parser.Char('\\'), escaped), c.expansion(), dollar())
+	return parser.Fmap(parser.Between(parser.Char('"'), parser.ZeroOrMore(content), parser.Char('"')), func(ss []string) string {
+		return strings.Join(ss, "")
+	})
+}
+
+// escape parses a backslash outside quotes, which takes the next character literally and
+// removes a following newline.
+func escape() parser.Parser[part] {
+	return parser.OmitLeft(parser.Char('\\'), parser.OrElse(
+		parser.Fmap(parser.Char('\n'), func(rune) part { return part{} }),
+		parser.Fmap(parser.SatisfyMsg(func(rune) bool { return true }, "escaped character"), func(r rune) part {
+			return part{s: parser.Text([]rune{r}), literal: true}
+		}),
+	))
+}
+
+// word parses a word, made of plain text, quoted text, escapes and expansions written next
+// to each other. It returns nothing for a word consisting only of expansions to empty
+// values, which the shell drops.
+func (c *config) word() parser.Parser[parser.Maybe[string]] {
+	plain := parser.OneOrMore(parser.SatisfyMsg(func(r rune) bool {
+		return !isBlank(r) && !isOperator(r) && !strings.ContainsRune(`'"\`, r) && (r != '$' || c.lookupEnv == nil)
+	}, "word"))
+	literal := func(s string) part { return part{s: s, literal: true} }
+	p := parser.OrElse(
+		parser.Fmap(parser.Fmap(plain, parser.Text), literal),
+		parser.Fmap(single(), literal),
+		parser.Fmap(c.double(), literal),
+		escape(),
+		parser.Fmap(c.expansion(), func(s string) part { return part{s: s} }),
+		parser.Fmap(dollar(), literal),
+	)
+	return parser.Fmap(parser.OneOrMore(p), func(ps []part) parser.Maybe[string] {
+		var b strings.Builder
+		keep := false
+		for _, p := range ps {
+			b.WriteString(p.s)
+			keep = keep || p.literal
+		}
+		if !keep && b.Len() == 0 {
+			return parser.Nothing[string]()
+		}
+		return parser.Just(b.String())
+	})
+}
+
+// words parses a command line into its words.
+func (c *config) words() parser.Parser[[]string] {
+	return parser.Fmap(parser.OmitLeft(ws(), parser.ZeroOrMore(parser.OmitRight(c.word(), ws()))), func(ms []parser.Maybe[string]) []string {
+		var args []string
+		for _, m := range ms {
+			if m.IsJust() {
+				args = append(args, m.Get())
+			}
+		}
+		return args
+	})
+}
@@ -0,0 +1,56 @@
+// Package shellwords splits command lines into argument vectors following the quoting rules
+// of the POSIX shell, e.g. for configuration values holding a command to run:
+//
+//	grep -e 'a b' "$HOME/notes.txt" \# # comment
+//
+// splits into grep, -e, a b, $HOME/notes.txt and #. Words are separated by blanks and
+// newlines. Single quotes take everything up to the next single quote literally; double
+// quotes do too, except that a backslash escapes '$', '`', '"', '\' and newlines. Outside
+// quotes, a backslash takes the next character literally, and a backslash before a newline
+// joins the lines. A '#' starting a word starts a comment running to the end of the line.
+//
+// Variables, written $NAME or ${NAME}, are only expanded with the ExpandEnv or ExpandWith
+// options. Expanded values are not split into words or matched against file names, and a
+// word consisting only of expansions to empty values is dropped, as in the shell. The
+// operators | & ; < > ( ) and '`' must be quoted, since splitting does not run pipelines,
+// redirections or command substitutions.
+package shellwords
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Split splits the command line s into its words, with their quotes and escapes undone. It
+// returns no words for a line that is blank or only a comment.
+func Split(s string, opts ...Option) ([]string, error) {
+	args, err := parser.Run(newConfig(opts).words(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "shellwords")
+	}
+	return args, nil
+}
+
+// isSafe reports whether r may appear unquoted in a word written by Quote.
+func isSafe(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,+@%", r)
+}
+
+// Quote returns s as a single word that Split reads back unchanged, in single quotes unless
+// it consists only of letters, digits and the characters -_./:=,+@%.
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !isSafe(r) }) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Join returns the command line of the words args, quoted as needed and separated by spaces.
+func Join(args []string) string {
+	ws := make([]string, len(args))
+	for i, a := range args {
+		ws[i] = Quote(a)
+	}
+	return strings.Join(ws, " ")
+}
@@ -0,0 +1,119 @@
+package shellwords_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/shellwords"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{" \t\n", nil},
+		{"# only a comment", nil},
+		{"ls -l", []string{"ls", "-l"}},
+		{"  a   b\tc\nd  ", []string{"a", "b", "c", "d"}},
+		{`grep -e 'a b' "$HOME/notes.txt" \# # comment`, []string{"grep", "-e", "a b", "$HOME/notes.txt", "#"}},
+		{`a#b 'c#'d`, []string{"a#b", "c#d"}},
+		{`'it'\''s'`, []string{"it's"}},
+		{`'a "b" \c'`, []string{`a "b" \c`}},
+		{`"a 'b' \"c\" \\ \$ \x"`, []string{`a 'b' "c" \ $ \x`}},
+		{`'' ""`, []string{"", ""}},
+		{`a\ b \'c\"`, []string{"a b", `'c"`}},
+		{"a\\\nb c \\\n d", []string{"ab", "c", "d"}},
+		{"\"a\\\nb\"", []string{"ab"}},
+		{`"a|b" 'c;d' e\&f`, []string{"a|b", "c;d", "e&f"}},
+		{"über 'café au lait' \\ü", []string{"über", "café au lait", "ü"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, err := shellwords.Split(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"HOME": "/home/jane", "NAME": "a b", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"$HOME/bin", []string{"/home/jane/bin"}},
+		{"${HOME}x $HOMEx", []string{"/home/janex"}},
+		{`echo $NAME "$NAME"`, []string{"echo", "a b", "a b"}},
+		{`a $EMPTY $UNSET "$EMPTY" ''$EMPTY b`, []string{"a", "", "", "b"}},
+		{`'$HOME' \$HOME "\$HOME"`, []string{"$HOME", "$HOME", "$HOME"}},
+		{`$ a$ "$" $1 "cost: $5"`, []string{"$", "a$", "$", "$1", "cost: $5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, err := shellwords.Split(tt.input, shellwords.ExpandWith(lookup))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+
+	t.Setenv("SHELLWORDS_TEST", "value")
+	args, err := shellwords.Split("x${SHELLWORDS_TEST}", shellwords.ExpandEnv())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"xvalue"}, args)
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"ls", "-l", "/tmp/a_b.txt"}, "ls -l /tmp/a_b.txt"},
+		{[]string{"a b", "", "it's", "$HOME", "#"}, `'a b' '' 'it'\''s' '$HOME' '#'`},
+		{[]string{"x=1,y=2", "user@host:~"}, `x=1,y=2 'user@host:~'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			line := shellwords.Join(tt.args)
+			assert.Equal(t, tt.expected, line)
+			args, err := shellwords.Split(line, shellwords.ExpandWith(func(string) (string, bool) { return "", false }))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  []shellwords.Option
+		err   error
+		msg   string
+	}{
+		{"'abc", nil, parser.ErrUnexpectedEOF, "shellwords: line 1, col 5: unexpected end of input"},
+		{"a \"b\nc", nil, parser.ErrUnexpectedEOF, "shellwords: line 2, col 2: unexpected end of input"},
+		{`abc\`, nil, parser.ErrUnexpectedEOF, "shellwords: line 1, col 5: unexpected end of input"},
+		{"a | b", nil, parser.ErrNoMatch, "shellwords: line 1, col 3: unexpected '|'"},
+		{"echo `date`", nil, parser.ErrNoMatch, "shellwords: line 1, col 6: unexpected '`'"},
+		{"a>b", nil, parser.ErrNoMatch, "shellwords: line 1, col 2: unexpected '>'"},
+		{"${HOME", []shellwords.Option{shellwords.ExpandEnv()}, parser.ErrUnexpectedEOF, "shellwords: line 1, col 7: unexpected end of input, expected '}'"},
+		{"${1}", []shellwords.Option{shellwords.ExpandEnv()}, parser.ErrNoMatch, "shellwords: line 1, col 3: unexpected '1', expected variable name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := shellwords.Split(tt.input, tt.opts...)
+			var perr *shellwords.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
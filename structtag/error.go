@@ -0,0 +1,19 @@
+// Package structtag provides error reporting for malformed struct tags.
+package structtag
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrDuplicateKey is reported for a key that is given more than once in a tag, which
+	// reflect.StructTag.Get ignores after the first.
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// ParseError describes why a struct tag could not be parsed. Expected lists what the tag should
+// contain at the error, e.g. "key". Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping ErrDuplicateKey.
+type ParseError = parser.Error
@@ -0,0 +1,99 @@
+// Package structtag provides the grammar of struct tags, built with the tiny-parsec
+// combinators.
+package structtag
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// isKey reports whether r may appear in a key, as reflect.StructTag.Lookup accepts.
+func isKey(r rune) bool {
+	return r > ' ' && r != ':' && r != '"' && r != 0x7f
+}
+
+func isHex(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func isOctal(r rune) bool {
+	return r >= '0' && r <= '7'
+}
+
+// count parses exactly n characters accepted by f.
+func count(n int, f func(rune) bool, expected string) parser.Parser[[]rune] {
+	ps := make([]parser.Parser[rune], n)
+	for i := range ps {
+		ps[i] = parser.SatisfyMsg(f, expected)
+	}
+	return parser.Seq(ps...)
+}
+
+// escape parses an escape sequence of a Go string literal, returning the bytes it stands for.
+func escape() parser.Parser[string] {
+	seq := parser.OrElse(
+		parser.Fmap(parser.SatisfyMsg(func(r rune) bool { return strings.ContainsRune(`abfnrtv\"`, r) }, "escape sequence"), func(r rune) []rune {
+			return []rune{r}
+		}),
+		prefixed('x', count(2, isHex, "hexadecimal digit")),
+		prefixed('u', count(4, isHex, "hexadecimal digit")),
+		prefixed('U', count(8, isHex, "hexadecimal digit")),
+		count(3, isOctal, "octal digit"),
+	)
+	raw := parser.Fmap(parser.OmitLeft(parser.Char('\\'), seq), func(rs []rune) string { return `\` + parser.Text(rs) })
+	valid := parser.SatisfyWithMsg(raw, func(s string) bool {
+		_, _, _, err := strconv.UnquoteChar(s, '"')
+		return err == nil
+	}, "valid escape sequence")
+	return parser.Fmap(valid, func(s string) string {
+		r, multibyte, _, _ := strconv.UnquoteChar(s, '"')
+		if r < utf8.RuneSelf || !multibyte {
+			return string([]byte{byte(r)})
+		}
+		return string(r)
+	})
+}
+
+// prefixed parses the character c followed by p, returning both.
+func prefixed(c rune, p parser.Parser[[]rune]) parser.Parser[[]rune] {
+	return parser.Bind(parser.Char(c), func(c rune) parser.Parser[[]rune] {
+		return parser.Fmap(p, func(rs []rune) []rune { return append([]rune{c}, rs...) })
+	})
+}
+
+// value parses a Go string literal in double quotes, returning its value.
+func value() parser.Parser[string] {
+	plain := parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' && r != '\n' })), parser.Text)
+	return parser.Fmap(parser.Between(parser.Char('"'), parser.ZeroOrMore(parser.OrElse(plain, escape())), parser.Char('"')), func(ss []string) string {
+		return strings.Join(ss, "")
+	})
+}
+
+// tag parses a key:"value" pair.
+func tag() parser.Parser[Tag] {
+	key := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isKey, "key")), parser.Text)
+	return parser.Bind(parser.Pos(), func(offset int) parser.Parser[Tag] {
+		return parser.Bind(parser.OmitRight(key, parser.Char(':')), func(key string) parser.Parser[Tag] {
+			return parser.Fmap(value(), func(v string) Tag {
+				return newTag(key, v, offset)
+			})
+		})
+	})
+}
+
+// tags parses pairs separated by spaces, with optional spaces around them.
+func tags() parser.Parser[[]Tag] {
+	spaces := parser.ZeroOrMore(parser.Char(' '))
+	sep := parser.OneOrMore(parser.Char(' '))
+	all := parser.Bind(tag(), func(first Tag) parser.Parser[[]Tag] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(sep, tag())), func(rest []Tag) []Tag {
+			return append([]Tag{first}, rest...)
+		})
+	})
+	return parser.Fmap(parser.Between(spaces, parser.ZeroOrOne(all), spaces), func(m parser.Maybe[[]Tag]) []Tag {
+		return m.Get()
+	})
+}
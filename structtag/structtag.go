@@ -0,0 +1,103 @@
+// Package structtag parses the tags of Go struct fields, e.g.
+//
+//	json:"name,omitempty" validate:"min=3"
+//
+// into their key:"value" pairs, splitting each value into a name and comma-separated
+// options as encoding/json does. Unlike reflect.StructTag.Get, which silently returns an
+// empty value for a malformed tag, Parse reports where and why a tag is malformed: pairs
+// must be separated by spaces, keys must be followed by a colon and a double-quoted Go
+// string literal with valid escapes, and keys may not be repeated.
+package structtag
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Tag is a key:"value" pair of a struct tag.
+type Tag struct {
+	// Key is the key, e.g. "json".
+	Key string
+	// Value is the value, with its quotes and escapes undone, e.g. "name,omitempty".
+	Value string
+	// Name is the part of the value before the first comma, e.g. "name".
+	Name string
+	// Options lists the comma-separated parts of the value after the name, e.g.
+	// ["omitempty"], or is nil if there are none.
+	Options []string
+	// Offset is the byte offset of the key in the struct tag.
+	Offset int
+}
+
+// newTag returns the pair key:"value" starting at offset, splitting its value.
+func newTag(key, value string, offset int) Tag {
+	t := Tag{Key: key, Value: value, Name: value, Offset: offset}
+	if name, opts, ok := strings.Cut(value, ","); ok {
+		t.Name = name
+		t.Options = strings.Split(opts, ",")
+	}
+	return t
+}
+
+// HasOption reports whether opt is one of the options of the tag.
+func (t Tag) HasOption(opt string) bool {
+	return slices.Contains(t.Options, opt)
+}
+
+// String returns the pair as written in a struct tag, e.g. `json:"name,omitempty"`.
+func (t Tag) String() string {
+	return t.Key + ":" + strconv.Quote(t.Value)
+}
+
+// Tags lists the pairs of a struct tag in order.
+type Tags []Tag
+
+// Get returns the pair with the given key, and false if there is none.
+func (ts Tags) Get(key string) (Tag, bool) {
+	for _, t := range ts {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return Tag{}, false
+}
+
+// Keys returns the keys of the pairs in order.
+func (ts Tags) Keys() []string {
+	keys := make([]string, len(ts))
+	for i, t := range ts {
+		keys[i] = t.Key
+	}
+	return keys
+}
+
+// String returns the struct tag of the pairs, separated by single spaces.
+func (ts Tags) String() string {
+	ss := make([]string, len(ts))
+	for i, t := range ts {
+		ss[i] = t.String()
+	}
+	return strings.Join(ss, " ")
+}
+
+// Parse parses the struct tag s, e.g. string(field.Tag) for a reflect.StructField. It
+// returns no pairs for an empty tag, and an error wrapping ErrDuplicateKey at the second
+// occurrence of a repeated key.
+func Parse(s string) (Tags, error) {
+	ts, err := parser.Run(tags(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "structtag")
+	}
+	seen := map[string]bool{}
+	for _, t := range ts {
+		if seen[t.Key] {
+			return nil, parser.ErrorAt("structtag", s, t.Offset, fmt.Errorf("%w: %s", ErrDuplicateKey, t.Key))
+		}
+		seen[t.Key] = true
+	}
+	return ts, nil
+}
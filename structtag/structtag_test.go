@@ -0,0 +1,104 @@
+package structtag_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/structtag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	ts, err := structtag.Parse(`json:"name,omitempty" validate:"min=3"`)
+	assert.NoError(t, err)
+	assert.Equal(t, structtag.Tags{
+		{Key: "json", Value: "name,omitempty", Name: "name", Options: []string{"omitempty"}, Offset: 0},
+		{Key: "validate", Value: "min=3", Name: "min=3", Offset: 22},
+	}, ts)
+	assert.Equal(t, []string{"json", "validate"}, ts.Keys())
+
+	json, ok := ts.Get("json")
+	assert.True(t, ok)
+	assert.True(t, json.HasOption("omitempty"))
+	assert.False(t, json.HasOption("string"))
+	_, ok = ts.Get("xml")
+	assert.False(t, ok)
+
+	tests := []struct {
+		input    string
+		expected structtag.Tags
+	}{
+		{"", nil},
+		{"   ", nil},
+		{`  a:""  `, structtag.Tags{{Key: "a", Offset: 2}}},
+		{`json:"-"`, structtag.Tags{{Key: "json", Value: "-", Name: "-"}}},
+		{`json:",string,omitempty"`, structtag.Tags{{Key: "json", Value: ",string,omitempty", Options: []string{"string", "omitempty"}}}},
+		{`json:"a,"`, structtag.Tags{{Key: "json", Value: "a,", Name: "a", Options: []string{""}}}},
+		{`x:"a\"b\\c\td\x41\101é\U0001F600"`, structtag.Tags{{Key: "x", Value: "a\"b\\c\td\x41\101é\U0001F600", Name: "a\"b\\c\td\x41\101é\U0001F600"}}},
+		{`x:"\xff"`, structtag.Tags{{Key: "x", Value: "\xff", Name: "\xff"}}},
+		{`x:"é ü"`, structtag.Tags{{Key: "x", Value: "é ü", Name: "é ü"}}},
+		{`protobuf:"bytes,1,opt,name=id" db.col-1:"id"`, structtag.Tags{
+			{Key: "protobuf", Value: "bytes,1,opt,name=id", Name: "bytes", Options: []string{"1", "opt", "name=id"}},
+			{Key: "db.col-1", Value: "id", Name: "id", Offset: 31},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ts, err := structtag.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ts)
+			for _, tag := range ts {
+				v, ok := reflect.StructTag(tt.input).Lookup(tag.Key)
+				assert.True(t, ok)
+				assert.Equal(t, v, tag.Value)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{`  json:"name,omitempty"   xml:"n"  `, `json:"name,omitempty" xml:"n"`},
+		{`x:"a\x41é\t"`, `x:"aAé\t"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ts, err := structtag.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ts.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{`json`, parser.ErrUnexpectedEOF, "structtag: line 1, col 5: unexpected end of input"},
+		{`json:name`, parser.ErrNoMatch, `structtag: line 1, col 6: unexpected 'n', expected '"'`},
+		{`json: "name"`, parser.ErrNoMatch, `structtag: line 1, col 6: unexpected ' ', expected '"'`},
+		{`json:"name`, parser.ErrUnexpectedEOF, "structtag: line 1, col 11: unexpected end of input"},
+		{`json:"a"xml:"b"`, parser.ErrNoMatch, "structtag: line 1, col 9: unexpected 'x'"},
+		{`:"a"`, parser.ErrNoMatch, "structtag: line 1, col 1: unexpected ':', expected ' ', key or end of input"},
+		{`x:"a\qb"`, parser.ErrNoMatch, "structtag: line 1, col 6: unexpected 'q'"},
+		{`x:"\x4"`, parser.ErrNoMatch, `structtag: line 1, col 7: unexpected '"', expected hexadecimal digit`},
+		{`x:"\uD800"`, parser.ErrNoMatch, "expected valid escape sequence"},
+		{`json:"a" xml:"b" json:"c"`, structtag.ErrDuplicateKey, "structtag: line 1, col 18: duplicate key: json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := structtag.Parse(tt.input)
+			var perr *structtag.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Package syslog provides error reporting for malformed syslog messages.
+package syslog
+
+import "github.com/81120/tiny-parsec/parser"
+
+// ParseError describes why a syslog message could not be parsed. Expected lists what the message
+// should contain at the error, e.g. "timestamp".
+type ParseError = parser.Error
@@ -0,0 +1,30 @@
+// Package syslog provides options for parsing syslog messages.
+package syslog
+
+import "time"
+
+// Option configures Parse.
+type Option func(*config)
+
+// config holds the settings of Parse.
+type config struct {
+	// now returns the time giving the year and location of BSD timestamps.
+	now func() time.Time
+}
+
+// newConfig returns the configuration set by opts.
+func newConfig(opts []Option) *config {
+	c := &config{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ReferenceTime completes the timestamps of BSD messages, which have neither a year nor a
+// time zone, from t instead of the current time, e.g. to parse old logs.
+func ReferenceTime(t time.Time) Option {
+	return func(c *config) {
+		c.now = func() time.Time { return t }
+	}
+}
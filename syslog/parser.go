@@ -0,0 +1,263 @@
+// Package syslog provides the grammars of RFC 5424 and BSD (RFC 3164) syslog messages, built
+// with the tiny-parsec combinators.
+package syslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/81120/tiny-parsec/datetime"
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isPrint reports whether r is a PRINTUSASCII character, which header fields consist of.
+func isPrint(r rune) bool {
+	return r > ' ' && r < 0x7f
+}
+
+// isSDName reports whether r may appear in the name of a structured data element or
+// parameter.
+func isSDName(r rune) bool {
+	return isPrint(r) && r != '=' && r != ']' && r != '"'
+}
+
+var months = map[string]time.Month{
+	"Jan": time.January, "Feb": time.February, "Mar": time.March, "Apr": time.April,
+	"May": time.May, "Jun": time.June, "Jul": time.July, "Aug": time.August,
+	"Sep": time.September, "Oct": time.October, "Nov": time.November, "Dec": time.December,
+}
+
+// setter is a parser storing the value it parsed in a message.
+type setter = parser.Parser[func(*Message)]
+
+// set returns a parser storing the value parsed by p in a message with f.
+func set[T any](p parser.Parser[T], f func(*Message, T)) setter {
+	return parser.Fmap(p, func(v T) func(*Message) {
+		return func(m *Message) { f(m, v) }
+	})
+}
+
+// all returns a parser running the setters in sequence and storing all their values.
+func all(ps ...setter) setter {
+	return parser.Fmap(parser.Seq(ps...), func(fs []func(*Message)) func(*Message) {
+		return func(m *Message) {
+			for _, f := range fs {
+				f(m)
+			}
+		}
+	})
+}
+
+// space parses the space before a field.
+func space[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitLeft(parser.Char(' '), p)
+}
+
+// number parses up to n digits, without leading zeros, giving a number between lo and hi.
+func number(n, lo, hi int, expected string) parser.Parser[int] {
+	ds := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isDigit, expected)), parser.Text)
+	valid := parser.SatisfyWithMsg(ds, func(s string) bool {
+		v, err := strconv.Atoi(s)
+		return len(s) <= n && (s[0] != '0' || s == "0") && err == nil && v >= lo && v <= hi
+	}, expected)
+	return parser.Fmap(valid, func(s string) int {
+		v, _ := strconv.Atoi(s)
+		return v
+	})
+}
+
+// priority parses the priority of a message, e.g. "<34>".
+func priority() parser.Parser[int] {
+	return parser.Between(parser.Char('<'), number(3, 0, 191, "priority between 0 and 191"), parser.Char('>'))
+}
+
+// field parses a header field of at most max characters, giving "" for "-".
+func field(max int, expected string) parser.Parser[string] {
+	f := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isPrint, expected)), parser.Text)
+	short := parser.SatisfyWithMsg(f, func(s string) bool {
+		return len(s) <= max
+	}, fmt.Sprintf("%s of at most %d characters", expected, max))
+	return parser.Fmap(short, func(s string) string {
+		if s == "-" {
+			return ""
+		}
+		return s
+	})
+}
+
+// rest parses the free-form message at the end of a line, dropping the byte order mark
+// RFC 5424 marks UTF-8 messages with.
+func rest() parser.Parser[string] {
+	all := parser.ZeroOrMore(parser.Satisfy(func(rune) bool { return true }))
+	return parser.Fmap(all, func(rs []rune) string {
+		return strings.TrimPrefix(parser.Text(rs), "\ufeff")
+	})
+}
+
+// sdName parses the name of a structured data element or parameter.
+func sdName(expected string) parser.Parser[string] {
+	name := parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isSDName, expected)), parser.Text)
+	return parser.SatisfyWithMsg(name, func(s string) bool {
+		return len(s) <= 32
+	}, expected+" of at most 32 characters")
+}
+
+// sdParam parses a parameter of a structured data element, e.g. `eventID="1011"`, undoing
+// the escapes \", \\ and \] of its value.
+func sdParam() parser.Parser[Param] {
+	byteString := func(r rune) string { return string([]byte{byte(r)}) }
+	escaped := parser.Satisfy(func(r rune) bool { return r == '"' || r == '\\' || r == ']' })
+	escape := parser.OmitLeft(parser.Char('\\'), parser.OrElse(
+		parser.Fmap(escaped, byteString),
+		// A backslash escaping nothing else stands for itself.
+		parser.Pure(`\`),
+	))
+	char := parser.Fmap(parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' }), byteString)
+	value := parser.Fmap(parser.ZeroOrMore(parser.OrElse(escape, char)), func(ss []string) string {
+		return strings.Join(ss, "")
+	})
+	return parser.Bind(parser.OmitRight(sdName("parameter name"), parser.Char('=')), func(name string) parser.Parser[Param] {
+		return parser.Fmap(parser.Between(parser.Char('"'), value, parser.Char('"')), func(value string) Param {
+			return Param{Name: name, Value: value}
+		})
+	})
+}
+
+// sdElement parses a structured data element, e.g. `[exampleSDID@32473 iut="3"]`.
+func sdElement() parser.Parser[Element] {
+	params := parser.ZeroOrMore(space(sdParam()))
+	elem := parser.Bind(sdName("SD-ID"), func(id string) parser.Parser[Element] {
+		return parser.Fmap(params, func(ps []Param) Element {
+			return Element{ID: id, Params: ps}
+		})
+	})
+	return parser.Between(parser.Char('['), elem, parser.Char(']'))
+}
+
+// ietf parses the rest of an RFC 5424 message after its priority.
+func ietf() setter {
+	timestamp := parser.SatisfyWithMsg(field(64, "timestamp"), func(s string) bool {
+		_, err := datetime.ParseRFC3339(s)
+		return s == "" || err == nil
+	}, "timestamp")
+	sd := parser.OrElse(
+		parser.Fmap(parser.Char('-'), func(rune) []Element { return nil }),
+		parser.OneOrMore(sdElement()),
+	)
+	return all(
+		set(number(2, 1, 99, "version"), func(m *Message, v int) { m.Version = v }),
+		set(space(timestamp), func(m *Message, v string) {
+			if v != "" {
+				m.Timestamp, _ = datetime.ParseRFC3339(v)
+			}
+		}),
+		set(space(field(255, "hostname")), func(m *Message, v string) { m.Hostname = v }),
+		set(space(field(48, "app name")), func(m *Message, v string) { m.AppName = v }),
+		set(space(field(128, "process ID")), func(m *Message, v string) { m.ProcID = v }),
+		set(space(field(32, "message ID")), func(m *Message, v string) { m.MsgID = v }),
+		set(space(sd), func(m *Message, v []Element) { m.StructuredData = v }),
+		set(parser.ZeroOrOne(space(rest())), func(m *Message, v parser.Maybe[string]) {
+			if v.IsJust() {
+				m.Text = v.Get()
+			}
+		}),
+	)
+}
+
+// stamp is a BSD timestamp, e.g. "Oct  1 22:14:15", which has neither a year nor a zone.
+type stamp struct {
+	month                     time.Month
+	day, hour, minute, second int
+}
+
+// valid reports whether the day exists in a leap year, so that "Feb 29" is accepted.
+func (s stamp) valid() bool {
+	return time.Date(2000, s.month, s.day, 0, 0, 0, 0, time.UTC).Day() == s.day
+}
+
+// in returns the time of the stamp in the year of now, or the year before if it would be
+// more than a month after now, in the location of now.
+func (s stamp) in(now time.Time) time.Time {
+	t := time.Date(now.Year(), s.month, s.day, s.hour, s.minute, s.second, 0, now.Location())
+	if t.After(now.AddDate(0, 1, 0)) || t.Day() != s.day {
+		t = time.Date(now.Year()-1, s.month, s.day, s.hour, s.minute, s.second, 0, now.Location())
+	}
+	return t
+}
+
+// bsdStamp parses a BSD timestamp, in which the day is padded with a space, as in
+// "Oct  1 22:14:15", though a zero or no padding is also accepted.
+func bsdStamp() parser.Parser[stamp] {
+	month := parser.SatisfyWithMsg(parser.Fmap(parser.Seq(parser.Alpha(), parser.Alpha(), parser.Alpha()), parser.Text), func(s string) bool {
+		_, ok := months[s]
+		return ok
+	}, "month name")
+	day := parser.OmitLeft(parser.ZeroOrOne(parser.OrElse(parser.Char(' '), parser.Char('0'))), number(2, 1, 31, "day between 1 and 31"))
+	two := func(hi int, expected string) parser.Parser[int] {
+		d := parser.SatisfyMsg(isDigit, expected)
+		return parser.SatisfyWithMsg(parser.Fmap(parser.Seq(d, d), func(rs []rune) int {
+			v, _ := strconv.Atoi(parser.Text(rs))
+			return v
+		}), func(v int) bool { return v <= hi }, expected)
+	}
+	clock := parser.Seq(
+		two(23, "hour between 00 and 23"),
+		parser.OmitLeft(parser.Char(':'), two(59, "minute between 00 and 59")),
+		parser.OmitLeft(parser.Char(':'), two(59, "second between 00 and 59")),
+	)
+	s := parser.Bind(month, func(month string) parser.Parser[stamp] {
+		return parser.Bind(space(day), func(day int) parser.Parser[stamp] {
+			return parser.Fmap(space(clock), func(hms []int) stamp {
+				return stamp{month: months[month], day: day, hour: hms[0], minute: hms[1], second: hms[2]}
+			})
+		})
+	})
+	return parser.SatisfyWithMsg(s, stamp.valid, "timestamp")
+}
+
+// bsd parses the rest of a BSD message after its priority: a timestamp, usually a hostname,
+// usually a tag naming the program, e.g. "sshd[1234]:", and the message.
+func bsd(c *config) setter {
+	isTagChar := func(r rune) bool { return isPrint(r) && r != ':' && r != '[' && r != ']' }
+	pid := parser.Between(parser.Char('['), parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isTagChar, "process ID")), parser.Text), parser.Char(']'))
+	tag := all(
+		set(parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isTagChar, "tag")), parser.Text), func(m *Message, v string) { m.AppName = v }),
+		set(parser.ZeroOrOne(pid), func(m *Message, v parser.Maybe[string]) {
+			if v.IsJust() {
+				m.ProcID = v.Get()
+			}
+		}),
+		set(parser.OmitLeft(parser.Char(':'), parser.ZeroOrOne(parser.Char(' '))), func(*Message, parser.Maybe[rune]) {}),
+	)
+	host := all(
+		set(field(255, "hostname"), func(m *Message, v string) { m.Hostname = v }),
+		space(parser.Fmap(parser.ZeroOrOne(tag), func(t parser.Maybe[func(*Message)]) func(*Message) {
+			if t.IsJust() {
+				return t.Get()
+			}
+			return func(*Message) {}
+		})),
+	)
+	return all(
+		set(bsdStamp(), func(m *Message, v stamp) { m.Timestamp = v.in(c.now()) }),
+		space(parser.OrElse(tag, host)),
+		set(rest(), func(m *Message, v string) { m.Text = v }),
+	)
+}
+
+// message parses an RFC 5424 or BSD message.
+func message(c *config) parser.Parser[*Message] {
+	return parser.Bind(priority(), func(pri int) parser.Parser[*Message] {
+		return parser.Fmap(parser.OrElse(ietf(), bsd(c)), func(f func(*Message)) *Message {
+			m := &Message{Priority: pri}
+			f(m)
+			return m
+		})
+	})
+}
@@ -0,0 +1,91 @@
+// Package syslog parses syslog messages in both the format of RFC 5424:
+//
+//	<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] An application event
+//
+// and the older BSD format described by RFC 3164:
+//
+//	<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8
+package syslog
+
+import (
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Message is a syslog message. Fields that are missing, or written as "-" in RFC 5424
+// messages, are empty.
+type Message struct {
+	// Priority combines the facility and severity of the message as Facility*8 + Severity.
+	Priority int
+	// Version is the version of the RFC 5424 format, which is 1, or 0 for BSD messages.
+	Version int
+	// Timestamp is the time the message was logged. BSD timestamps are completed with the
+	// year and location of the current time, or of the time given by ReferenceTime.
+	Timestamp time.Time
+	// Hostname is the name or address of the machine that logged the message.
+	Hostname string
+	// AppName is the program that logged the message, taken from the tag of BSD messages.
+	AppName string
+	// ProcID is the process ID of the program, or another identifier of its instance.
+	ProcID string
+	// MsgID identifies the type of the message, in RFC 5424 messages.
+	MsgID string
+	// StructuredData lists the structured data elements of RFC 5424 messages, in order.
+	StructuredData []Element
+	// Text is the free-form message, without the byte order mark that marks UTF-8 text.
+	Text string
+}
+
+// Facility returns the facility of the message, e.g. 4 for security messages.
+func (m *Message) Facility() int {
+	return m.Priority / 8
+}
+
+// Severity returns the severity of the message, from 0 for emergencies to 7 for debug
+// messages.
+func (m *Message) Severity() int {
+	return m.Priority % 8
+}
+
+// Element is a structured data element, e.g. `[exampleSDID@32473 iut="3" eventID="1011"]`.
+type Element struct {
+	// ID names the element, e.g. "exampleSDID@32473".
+	ID string
+	// Params lists the parameters of the element, in order.
+	Params []Param
+}
+
+// Param is a parameter of a structured data element.
+type Param struct {
+	// Name is the parameter name, e.g. "eventID".
+	Name string
+	// Value is the parameter value, with its escapes undone.
+	Value string
+}
+
+// Get returns the value of the first parameter of the element named name.
+func (e Element) Get(name string) (string, bool) {
+	for _, p := range e.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse parses a syslog message in the RFC 5424 or BSD format, telling them apart by the
+// version following the priority of RFC 5424 messages.
+//
+// BSD messages are parsed leniently, as their format varies between senders: the hostname
+// and the tag, e.g. "sshd[1234]:", may each be missing, and the day may be padded with a
+// space, as specified, a zero, or nothing. Their timestamps have neither a year nor a time
+// zone, so they are taken in the year and location of the current time, or in the year
+// before if that would place them more than a month in the future.
+func Parse(s string, opts ...Option) (*Message, error) {
+	m, err := parser.Run(message(newConfig(opts)), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "syslog")
+	}
+	return m, nil
+}
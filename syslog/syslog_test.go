@@ -0,0 +1,99 @@
+package syslog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/syslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	m, err := syslog.Parse(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] ` + "\ufeffAn application event")
+	assert.NoError(t, err)
+	assert.Equal(t, &syslog.Message{
+		Priority:  165,
+		Version:   1,
+		Timestamp: time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC),
+		Hostname:  "mymachine.example.com",
+		AppName:   "evntslog",
+		MsgID:     "ID47",
+		StructuredData: []syslog.Element{
+			{ID: "exampleSDID@32473", Params: []syslog.Param{{"iut", "3"}, {"eventSource", "Application"}, {"eventID", "1011"}}},
+			{ID: "examplePriority@32473", Params: []syslog.Param{{"class", "high"}}},
+		},
+		Text: "An application event",
+	}, m)
+	assert.Equal(t, 20, m.Facility())
+	assert.Equal(t, 5, m.Severity())
+	v, ok := m.StructuredData[0].Get("eventID")
+	assert.True(t, ok)
+	assert.Equal(t, "1011", v)
+}
+
+func TestParse(t *testing.T) {
+	ref := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		input    string
+		expected syslog.Message
+	}{
+		{"nil values", "<0>1 - - - - - -", syslog.Message{Version: 1}},
+		{"offset timestamp", "<13>1 2003-08-24T05:14:15.000003-07:00 192.0.2.1 myproc 8710 - - %% It's time to make the do-nuts.",
+			syslog.Message{Priority: 13, Version: 1, Timestamp: time.Date(2003, time.August, 24, 12, 14, 15, 3000, time.UTC),
+				Hostname: "192.0.2.1", AppName: "myproc", ProcID: "8710", Text: "%% It's time to make the do-nuts."}},
+		{"escaped values", `<1>1 - - - - - [id a="x\"y\\z\]" b="c\d" c=""]`, syslog.Message{Priority: 1, Version: 1,
+			StructuredData: []syslog.Element{{ID: "id", Params: []syslog.Param{{"a", `x"y\z]`}, {"b", `c\d`}, {"c", ""}}}}}},
+		{"bsd", "<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8",
+			syslog.Message{Priority: 34, Timestamp: time.Date(2023, time.October, 11, 22, 14, 15, 0, time.UTC),
+				Hostname: "mymachine", AppName: "su", Text: "'su root' failed for lonvick on /dev/pts/8"}},
+		{"bsd with pid", "<38>Feb  5 17:32:18 10.0.0.99 sshd[1234]: Accepted publickey",
+			syslog.Message{Priority: 38, Timestamp: time.Date(2024, time.February, 5, 17, 32, 18, 0, time.UTC),
+				Hostname: "10.0.0.99", AppName: "sshd", ProcID: "1234", Text: "Accepted publickey"}},
+		{"bsd without hostname", "<13>Mar  1 00:00:00 kernel: boot",
+			syslog.Message{Priority: 13, Timestamp: ref, AppName: "kernel", Text: "boot"}},
+		{"bsd without tag", "<13>Feb 29 01:02:03 host just text",
+			syslog.Message{Priority: 13, Timestamp: time.Date(2024, time.February, 29, 1, 2, 3, 0, time.UTC), Hostname: "host", Text: "just text"}},
+		{"bsd zero padded day", "<13>Mar 01 00:00:00 host app: x",
+			syslog.Message{Priority: 13, Timestamp: ref, Hostname: "host", AppName: "app", Text: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := syslog.Parse(tt.input, syslog.ReferenceTime(ref))
+			assert.NoError(t, err)
+			if err == nil {
+				assert.True(t, tt.expected.Timestamp.Equal(m.Timestamp), "timestamp %v", m.Timestamp)
+				m.Timestamp = tt.expected.Timestamp
+				assert.Equal(t, tt.expected, *m)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"<192>1 - - - - - -", parser.ErrNoMatch, "syslog: line 1, col 2: unexpected '1', expected priority between 0 and 191"},
+		{"<01>1 - - - - - -", parser.ErrNoMatch, "syslog: line 1, col 2: unexpected '0', expected priority between 0 and 191"},
+		{"<1>1 2003-13-11T22:14:15Z - - - - -", parser.ErrNoMatch, "syslog: line 1, col 6: unexpected '2', expected timestamp"},
+		{"<1>1 - - - - -", parser.ErrUnexpectedEOF, "syslog: line 1, col 15: unexpected end of input, expected message ID or ' '"},
+		{"<1>1 - - - - - [id a=1]", parser.ErrNoMatch, "syslog: line 1, col 22: unexpected '1', expected '\"'"},
+		{"<1>1 - - - - - [id", parser.ErrUnexpectedEOF, "syslog: line 1, col 19: unexpected end of input"},
+		{"<1>1 - - - - - x", parser.ErrNoMatch, "syslog: line 1, col 16: unexpected 'x', expected '-' or '['"},
+		{"<1>Feb 30 00:00:00 host x", parser.ErrNoMatch, "syslog: line 1, col 4: unexpected 'F', expected timestamp"},
+		{"<1>Foo 1 00:00:00 host x", parser.ErrNoMatch, "syslog: line 1, col 4: unexpected 'F', expected month name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := syslog.Parse(tt.input)
+			var perr *syslog.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package toml defines a set of types to represent TOML documents in Go.
+package toml
+
+import (
+	"slices"
+	"time"
+)
+
+// Toml is an interface that all TOML types must implement.
+type Toml interface {
+	// tomlType is a method that all TOML types must implement.
+	// It serves as a marker for the TOML type.
+	tomlType()
+}
+
+// TomlString represents a TOML string, written in any of the four string forms.
+type TomlString struct {
+	// Val is the value of the string, with escape sequences decoded.
+	Val string
+}
+
+// tomlType implements the Toml interface for TomlString.
+func (t TomlString) tomlType() {}
+
+// TomlInt represents a TOML integer, written in decimal, hexadecimal, octal or binary.
+type TomlInt struct {
+	// Val is the value of the integer.
+	Val int64
+}
+
+// tomlType implements the Toml interface for TomlInt.
+func (t TomlInt) tomlType() {}
+
+// TomlFloat represents a TOML float, including inf and nan.
+type TomlFloat struct {
+	// Val is the value of the float.
+	Val float64
+}
+
+// tomlType implements the Toml interface for TomlFloat.
+func (t TomlFloat) tomlType() {}
+
+// TomlBool represents a TOML boolean.
+type TomlBool struct {
+	// Val is the value of the boolean.
+	Val bool
+}
+
+// tomlType implements the Toml interface for TomlBool.
+func (t TomlBool) tomlType() {}
+
+// DatetimeKind tells which of the four TOML date and time forms a TomlDatetime was written in.
+type DatetimeKind int
+
+const (
+	// OffsetDatetime is a date and time with an offset from UTC, e.g. 1979-05-27T07:32:00Z.
+	OffsetDatetime DatetimeKind = iota
+	// LocalDatetime is a date and time without an offset, e.g. 1979-05-27T07:32:00.
+	LocalDatetime
+	// LocalDate is a date alone, e.g. 1979-05-27.
+	LocalDate
+	// LocalTime is a time of day alone, e.g. 07:32:00.
+	LocalTime
+)
+
+// String returns the name of the kind as used by the TOML specification, e.g. "offset date-time".
+func (k DatetimeKind) String() string {
+	switch k {
+	case OffsetDatetime:
+		return "offset date-time"
+	case LocalDatetime:
+		return "local date-time"
+	case LocalDate:
+		return "local date"
+	case LocalTime:
+		return "local time"
+	}
+	return "date-time"
+}
+
+// TomlDatetime represents a TOML date, time or date-time.
+type TomlDatetime struct {
+	// Val is the point in time. Local values have the location time.UTC, and local times the
+	// date 0000-01-01.
+	Val time.Time
+	// Kind is the form the value was written in.
+	Kind DatetimeKind
+}
+
+// tomlType implements the Toml interface for TomlDatetime.
+func (t TomlDatetime) tomlType() {}
+
+// TomlArray represents a TOML array. An array of tables, written with [[name]] headers, is an
+// array of TomlTable values.
+type TomlArray struct {
+	// Val is the slice of values in the array.
+	Val []Toml
+}
+
+// tomlType implements the Toml interface for TomlArray.
+func (t TomlArray) tomlType() {}
+
+// TomlTable represents a TOML table: the document itself, a table defined with a [name] header
+// or dotted keys, an inline table or an element of an array of tables.
+type TomlTable struct {
+	// Val maps the keys of the table to their values. Subtables are TomlTable values.
+	Val map[string]Toml
+	// Keys lists the keys of Val in document order.
+	Keys []string
+}
+
+// tomlType implements the Toml interface for TomlTable.
+func (t TomlTable) tomlType() {}
+
+// Get returns the value of key in the table and whether it exists.
+func (t TomlTable) Get(key string) (Toml, bool) {
+	v, ok := t.Val[key]
+	return v, ok
+}
+
+// Lookup returns the value at the path of keys below the table, e.g. Lookup("server", "port")
+// for the key port of the table [server], and whether it exists.
+func (t TomlTable) Lookup(path ...string) (Toml, bool) {
+	var v Toml = t
+	for _, key := range path {
+		tbl, ok := v.(TomlTable)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = tbl.Val[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// OrderedKeys returns the keys of the table in document order. Keys missing from Keys, e.g.
+// because Val was filled directly, follow in sorted order.
+func (t TomlTable) OrderedKeys() []string {
+	keys := make([]string, 0, len(t.Val))
+	for _, k := range t.Keys {
+		if _, ok := t.Val[k]; ok && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	var rest []string
+	for k := range t.Val {
+		if !slices.Contains(keys, k) {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	return append(keys, rest...)
+}
+
+// ToNative converts t into plain Go values: map[string]any for tables, []any for arrays,
+// and string, int64, float64, bool or time.Time for the other values.
+func ToNative(t Toml) any {
+	switch t := t.(type) {
+	case TomlString:
+		return t.Val
+	case TomlInt:
+		return t.Val
+	case TomlFloat:
+		return t.Val
+	case TomlBool:
+		return t.Val
+	case TomlDatetime:
+		return t.Val
+	case TomlArray:
+		s := make([]any, len(t.Val))
+		for i, v := range t.Val {
+			s[i] = ToNative(v)
+		}
+		return s
+	case TomlTable:
+		m := make(map[string]any, len(t.Val))
+		for k, v := range t.Val {
+			m[k] = ToNative(v)
+		}
+		return m
+	}
+	return nil
+}
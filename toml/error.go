@@ -0,0 +1,47 @@
+// Package toml provides error reporting for malformed TOML documents.
+package toml
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+var (
+	// ErrDuplicateKey is reported for a key that is defined more than once in a table,
+	// including keys used as tables after they were given a value.
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrDuplicateTable is reported for a table that is defined more than once, with headers,
+	// dotted keys or as an inline table.
+	ErrDuplicateTable = errors.New("duplicate table")
+)
+
+// ParseError describes why a TOML document could not be parsed. Its Err is parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping ErrDuplicateKey or
+// ErrDuplicateTable for errors in the structure of the document, which have no Found or Expected.
+type ParseError = parser.Error
+
+// parseError reports an error returned by the parser package as a TOML error, with the
+// expectations of the parsers that can start a value collapsed into "value".
+// Other errors are returned unchanged.
+func parseError(err error) error {
+	err = parser.Wrap(err, "toml")
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.Expected = describeExpected(perr.Expected)
+	}
+	return err
+}
+
+// valueStart lists the descriptions of the parsers that can start a value other than a number,
+// boolean or date-time.
+var valueStart = []string{`"\"\"\""`, `'"'`, `"'''"`, `'\''`, "'['", "'{'"}
+
+// describeExpected collapses the expectations of the parsers that can start a value into "value".
+func describeExpected(expected []string) []string {
+	if !slices.Contains(expected, "value") {
+		return expected
+	}
+	return slices.DeleteFunc(slices.Clone(expected), func(e string) bool { return slices.Contains(valueStart, e) })
+}
@@ -0,0 +1,536 @@
+// Package toml provides a parser for TOML documents as specified by TOML 1.0, built on the
+// combinators of the parser package.
+//
+// The grammar is parsed in two steps: the combinators turn the document into a sequence of
+// expressions, key-value pairs and table headers, with their positions, and Parse then builds
+// the tables from them, reporting keys and tables that are defined more than once.
+package toml
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// join concatenates the parts of a string.
+func join(parts []string) string {
+	return strings.Join(parts, "")
+}
+
+// isSpace reports whether r is whitespace within a line.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isText reports whether r may appear in a comment or a single-line string: anything but
+// control characters other than tab.
+func isText(r rune) bool {
+	return r == '\t' || r >= 0x20 && r != 0x7f
+}
+
+// isMultilineText reports whether r may appear in a multi-line string.
+func isMultilineText(r rune) bool {
+	return isText(r) || r == '\n' || r == '\r'
+}
+
+// isBare reports whether r may appear in a bare key.
+func isBare(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
+
+// ws parses whitespace within a line.
+func ws() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isSpace))
+}
+
+// newline parses a line break, "\n" or "\r\n".
+func newline() parser.Parser[string] {
+	return parser.OrElse(parser.Str("\n"), parser.Str("\r\n"))
+}
+
+// comment parses a comment up to the end of its line and returns its text.
+func comment() parser.Parser[string] {
+	return parser.OmitLeft(parser.Char('#'), parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isText)), parser.Text))
+}
+
+// wsCommentNewline parses the whitespace, comments and line breaks allowed between the elements
+// of an array.
+func wsCommentNewline() parser.Parser[[]string] {
+	return parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(isSpace)), parser.Text),
+		comment(),
+		newline(),
+	))
+}
+
+// run parses one or more bytes satisfying f and returns them.
+func run(f func(rune) bool) parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.Satisfy(f)), parser.Text)
+}
+
+// escape parses an escape sequence of a basic string and returns the character it stands for.
+func escape() parser.Parser[string] {
+	unicode := func(n int) parser.Parser[string] {
+		digits := make([]parser.Parser[rune], n)
+		for i := range digits {
+			digits[i] = parser.SatisfyMsg(isHexDigit, "hex digit")
+		}
+		return parser.Bind(parser.Seq(digits...), func(rs []rune) parser.Parser[string] {
+			code, _ := strconv.ParseUint(parser.Text(rs), 16, 32)
+			if !utf8.ValidRune(rune(code)) {
+				return parser.Fail[string]()
+			}
+			return parser.Pure(string(rune(code)))
+		})
+	}
+	escapes := map[rune]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", '"': `"`, '\\': `\`}
+	return parser.OmitLeft(parser.Char('\\'), parser.OrElse(
+		parser.Fmap(
+			parser.SatisfyMsg(func(r rune) bool { _, ok := escapes[r]; return ok }, "escape sequence"),
+			func(r rune) string { return escapes[r] },
+		),
+		parser.OmitLeft(parser.Char('u'), unicode(4)),
+		parser.OmitLeft(parser.Char('U'), unicode(8)),
+	))
+}
+
+// isHexDigit reports whether r is a hexadecimal digit.
+func isHexDigit(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+// basicString parses a string in double quotes, with escape sequences.
+func basicString() parser.Parser[string] {
+	chars := run(func(r rune) bool { return r != '"' && r != '\\' && isText(r) })
+	return parser.Fmap(
+		parser.Between(parser.Char('"'), parser.ZeroOrMore(parser.OrElse(escape(), chars)), parser.Char('"')),
+		join,
+	)
+}
+
+// literalString parses a string in single quotes, taken literally.
+func literalString() parser.Parser[string] {
+	return parser.Between(
+		parser.Char('\''),
+		parser.Fmap(parser.ZeroOrMore(parser.Satisfy(func(r rune) bool { return r != '\'' && isText(r) })), parser.Text),
+		parser.Char('\''),
+	)
+}
+
+// quotes parses one or two quotes q within a multi-line string, which three quotes would close.
+func quotes(q byte) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		n := 0
+		for n < len(s) && s[n] == q {
+			n++
+		}
+		if n == 0 || n >= 3 {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:n], next))
+	})
+}
+
+// closing parses the three quotes q closing a multi-line string. Up to two more quotes right
+// before them belong to the string and are returned.
+func closing(q byte) parser.Parser[string] {
+	delim := strings.Repeat(string(q), 3)
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		n := 0
+		for n < len(s) && s[n] == q {
+			n++
+		}
+		if n < 3 || n > 5 {
+			st.Fail(strconv.Quote(delim))
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:n-3], next))
+	})
+}
+
+// multiline parses a multi-line string delimited by three quotes q, whose contents are parsed
+// by parts. A line break right after the opening quotes is not part of the string.
+func multiline(q byte, parts ...parser.Parser[string]) parser.Parser[string] {
+	open := parser.OmitRight(parser.Str(strings.Repeat(string(q), 3)), parser.ZeroOrOne(newline()))
+	body := parser.ZeroOrMore(parser.OrElse(append(parts, quotes(q))...))
+	return parser.OmitLeft(open, parser.Bind(body, func(parts []string) parser.Parser[string] {
+		return parser.Fmap(closing(q), func(extra string) string { return join(parts) + extra })
+	}))
+}
+
+// multilineBasicString parses a string in triple double quotes, with escape sequences. A
+// backslash at the end of a line removes the line break and the whitespace after it.
+func multilineBasicString() parser.Parser[string] {
+	blank := parser.ZeroOrMore(parser.OrElse(parser.Fmap(parser.Satisfy(isSpace), func(rune) string { return "" }), newline()))
+	lineEnding := parser.Fmap(
+		parser.OmitLeft(parser.Char('\\'), parser.OmitLeft(ws(), parser.OmitLeft(newline(), blank))),
+		func([]string) string { return "" },
+	)
+	chars := run(func(r rune) bool { return r != '"' && r != '\\' && isMultilineText(r) })
+	return multiline('"', lineEnding, escape(), chars)
+}
+
+// multilineLiteralString parses a string in triple single quotes, taken literally.
+func multilineLiteralString() parser.Parser[string] {
+	return multiline('\'', run(func(r rune) bool { return r != '\'' && isMultilineText(r) }))
+}
+
+// String returns a parser for a TOML string in any of its four forms and returns its value.
+func String() parser.Parser[string] {
+	return parser.OrElse(multilineBasicString(), basicString(), multilineLiteralString(), literalString())
+}
+
+// Key returns a parser for a key, which may be dotted, and returns its parts. The parts are bare
+// keys or quoted keys in basic or literal strings, separated by dots with optional whitespace.
+func Key() parser.Parser[[]string] {
+	simple := parser.OrElse(basicString(), literalString(), run(isBare))
+	dot := parser.Between(ws(), parser.Char('.'), ws())
+	return parser.Bind(simple, func(first string) parser.Parser[[]string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.OmitLeft(dot, simple)), func(rest []string) []string {
+			return append([]string{first}, rest...)
+		})
+	})
+}
+
+// node is a value as parsed, before inline tables are built into tables.
+type node struct {
+	// offset is the byte offset of the value in the document.
+	offset int
+	// value holds a string, number, boolean or date-time.
+	value Toml
+	// array holds the elements of an array, if isArray is set.
+	array   []node
+	isArray bool
+	// table holds the key-value pairs of an inline table, if isTable is set.
+	table   []keyval
+	isTable bool
+}
+
+// keyval is a key-value pair.
+type keyval struct {
+	// offset is the byte offset of the key in the document.
+	offset int
+	key    []string
+	value  node
+}
+
+// value returns a parser for any TOML value.
+// Nested values are parsed by the same parser through a Lazy reference, so the grammar is built only once.
+func value() parser.Parser[node] {
+	var val parser.Parser[node]
+	ref := parser.Lazy(func() parser.Parser[node] { return val })
+	val = parser.Bind(parser.Pos(), func(offset int) parser.Parser[node] {
+		return parser.Fmap(parser.OrElse(
+			parser.Fmap(String(), func(s string) node { return node{value: TomlString{Val: s}} }),
+			array(ref),
+			inlineTable(ref),
+			parser.Fmap(scalar(), func(t Toml) node { return node{value: t} }),
+		), func(n node) node {
+			n.offset = offset
+			return n
+		})
+	})
+	return val
+}
+
+// array parses an array of values parsed by val, which may span several lines with comments
+// and end with a comma.
+func array(val parser.Parser[node]) parser.Parser[node] {
+	space := wsCommentNewline()
+	elem := parser.Between(space, val, space)
+	elems := parser.Bind(parser.SepBy(elem, parser.Char(',')), func(elems []node) parser.Parser[[]node] {
+		if len(elems) == 0 {
+			return parser.OmitLeft(space, parser.Pure(elems))
+		}
+		return parser.OmitLeft(parser.ZeroOrOne(parser.OmitLeft(parser.Char(','), space)), parser.Pure(elems))
+	})
+	return parser.Fmap(
+		parser.Between(parser.Char('['), elems, parser.Char(']')),
+		func(elems []node) node { return node{array: elems, isArray: true} },
+	)
+}
+
+// keyvalue parses a key-value pair whose value is parsed by val.
+func keyvalue(val parser.Parser[node]) parser.Parser[keyval] {
+	return parser.Bind(parser.Pos(), func(offset int) parser.Parser[keyval] {
+		return parser.Bind(parser.OmitRight(Key(), parser.Between(ws(), parser.Char('='), ws())), func(key []string) parser.Parser[keyval] {
+			return parser.Fmap(val, func(v node) keyval { return keyval{offset: offset, key: key, value: v} })
+		})
+	})
+}
+
+// inlineTable parses an inline table on a single line, whose values are parsed by val.
+func inlineTable(val parser.Parser[node]) parser.Parser[node] {
+	pairs := parser.SepBy(parser.Between(ws(), keyvalue(val), ws()), parser.Char(','))
+	return parser.Fmap(
+		parser.Between(parser.Char('{'), parser.OmitRight(pairs, ws()), parser.Char('}')),
+		func(kvs []keyval) node { return node{table: kvs, isTable: true} },
+	)
+}
+
+// isScalar reports whether c may appear in a number, boolean or date-time.
+func isScalar(c byte) bool {
+	return isBare(rune(c)) || c == '+' || c == '.' || c == ':'
+}
+
+// scalar parses a number, boolean or date-time: the longest run of the characters they consist
+// of, which must form one of them.
+func scalar() parser.Parser[Toml] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[Toml] {
+		s := st.Input()
+		n := 0
+		for n < len(s) && isScalar(s[n]) {
+			n++
+		}
+		// A space may separate the date from the time of a date-time
+		if n == 10 && isDate(s[:n]) && len(s) > n+3 && s[n] == ' ' && isDigit(s[n+1]) && isDigit(s[n+2]) && s[n+3] == ':' {
+			n++
+			for n < len(s) && isScalar(s[n]) {
+				n++
+			}
+		}
+		v, ok := scalarValue(s[:n])
+		if !ok {
+			st.Fail("value")
+			return parser.Nothing[parser.Tuple[Toml, parser.State]]()
+		}
+		next, ok := st.Advance(n)
+		if !ok {
+			return parser.Nothing[parser.Tuple[Toml, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(v, next))
+	})
+}
+
+// isDigit reports whether c is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isDate reports whether s has the shape of a date, as in 1979-05-27.
+func isDate(s string) bool {
+	return len(s) == 10 && s[4] == '-' && s[7] == '-'
+}
+
+// scalarValue returns the number, boolean or date-time written as s, and whether s is one.
+func scalarValue(s string) (Toml, bool) {
+	switch s {
+	case "true", "false":
+		return TomlBool{Val: s == "true"}, true
+	case "inf", "+inf":
+		return TomlFloat{Val: math.Inf(1)}, true
+	case "-inf":
+		return TomlFloat{Val: math.Inf(-1)}, true
+	case "nan", "+nan", "-nan":
+		return TomlFloat{Val: math.NaN()}, true
+	}
+	if len(s) >= 10 && s[4] == '-' || len(s) >= 3 && s[2] == ':' {
+		return datetime(s)
+	}
+	if i, ok := integer(s); ok {
+		return TomlInt{Val: i}, true
+	}
+	if validFloat(s) {
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+		if err == nil {
+			return TomlFloat{Val: f}, true
+		}
+	}
+	return nil, false
+}
+
+// validDigits reports whether s is a non-empty sequence of digits accepted by isDigit, where
+// each underscore is between two digits.
+func validDigits(s string, isDigit func(rune) bool) bool {
+	if s == "" || s[0] == '_' || s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && !isDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// validDecimal reports whether s is a decimal integer without sign or leading zeros.
+func validDecimal(s string) bool {
+	return validDigits(s, func(r rune) bool { return r >= '0' && r <= '9' }) && (s == "0" || s[0] != '0')
+}
+
+// trimSign returns s without a leading '+' or '-'.
+func trimSign(s string) string {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		return s[1:]
+	}
+	return s
+}
+
+// integer returns the integer written as s, and whether s is one that fits in an int64.
+func integer(s string) (int64, bool) {
+	bases := map[string]struct {
+		base    int
+		isDigit func(rune) bool
+	}{
+		"0x": {16, isHexDigit},
+		"0o": {8, func(r rune) bool { return r >= '0' && r <= '7' }},
+		"0b": {2, func(r rune) bool { return r == '0' || r == '1' }},
+	}
+	if len(s) > 2 {
+		if b, ok := bases[s[:2]]; ok {
+			if !validDigits(s[2:], b.isDigit) {
+				return 0, false
+			}
+			i, err := strconv.ParseInt(strings.ReplaceAll(s[2:], "_", ""), b.base, 64)
+			return i, err == nil
+		}
+	}
+	if !validDecimal(trimSign(s)) {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64)
+	return i, err == nil
+}
+
+// validFloat reports whether s is a decimal float: an integer part, then a fraction, an exponent
+// or both.
+func validFloat(s string) bool {
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(trimSign(s)), "e")
+	whole, frac, hasFrac := strings.Cut(mantissa, ".")
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	return (hasFrac || hasExp) && validDecimal(whole) &&
+		(!hasFrac || validDigits(frac, isDigit)) &&
+		(!hasExp || validDigits(trimSign(exp), isDigit))
+}
+
+// datetime returns the date-time, date or time written as s, and whether s is one.
+func datetime(s string) (Toml, bool) {
+	u := strings.ToUpper(s)
+	if len(u) > 10 && u[10] == ' ' {
+		u = u[:10] + "T" + u[11:]
+	}
+	var layout string
+	var kind DatetimeKind
+	switch {
+	case u[2] == ':':
+		layout, kind = time.TimeOnly, LocalTime
+	case len(u) == 10:
+		layout, kind = time.DateOnly, LocalDate
+	case len(u) < 19:
+		return nil, false
+	case strings.ContainsAny(u[19:], "Z+-"):
+		layout, kind = time.RFC3339, OffsetDatetime
+	default:
+		layout, kind = "2006-01-02T15:04:05", LocalDatetime
+	}
+	t, err := time.Parse(layout, u)
+	if err != nil {
+		return nil, false
+	}
+	return TomlDatetime{Val: t, Kind: kind}, true
+}
+
+// exprKind tells what an expression of a document is.
+type exprKind int
+
+const (
+	exprKeyval exprKind = iota
+	exprTable
+	exprArrayTable
+)
+
+// expr is an expression of a document: a key-value pair or a table header.
+type expr struct {
+	kind exprKind
+	// keyval is the key-value pair; for headers, it holds the offset and the key.
+	keyval keyval
+}
+
+// notEOF succeeds without consuming input unless the input is at its end.
+func notEOF() parser.Parser[struct{}] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		if st.Input() == "" {
+			st.Fail("")
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+}
+
+// document returns a parser for the expressions of a complete TOML document: one per line,
+// each followed by an optional comment, with blank lines and comment lines in between.
+func document() parser.Parser[[]expr] {
+	header := func(open, close string, kind exprKind) parser.Parser[expr] {
+		return parser.Bind(parser.Pos(), func(offset int) parser.Parser[expr] {
+			return parser.Fmap(
+				parser.Between(parser.Str(open), parser.Between(ws(), Key(), ws()), parser.Str(close)),
+				func(key []string) expr { return expr{kind: kind, keyval: keyval{offset: offset, key: key}} },
+			)
+		})
+	}
+	line := parser.OrElse(
+		header("[[", "]]", exprArrayTable),
+		header("[", "]", exprTable),
+		parser.Fmap(keyvalue(value()), func(kv keyval) expr { return expr{kind: exprKeyval, keyval: kv} }),
+	)
+	end := parser.OmitLeft(ws(), parser.OmitLeft(parser.ZeroOrOne(comment()), parser.OrElse(
+		parser.Fmap(newline(), func(string) struct{} { return struct{}{} }),
+		parser.EOF(),
+	)))
+	lines := parser.ZeroOrMore(parser.OmitLeft(notEOF(), parser.OmitLeft(ws(), parser.OmitRight(parser.ZeroOrOne(line), end))))
+	return parser.Fmap(parser.OmitRight(lines, parser.EOF()), func(lines []parser.Maybe[expr]) []expr {
+		var exprs []expr
+		for _, l := range lines {
+			if l.IsJust() {
+				exprs = append(exprs, l.Get())
+			}
+		}
+		return exprs
+	})
+}
+
+// Parse parses the TOML document s and returns its root table. It fails with a *ParseError,
+// whose Err is parser.ErrNoMatch or parser.ErrUnexpectedEOF for syntax errors and wraps
+// ErrDuplicateKey or ErrDuplicateTable for keys and tables defined more than once.
+func Parse(s string) (TomlTable, error) {
+	exprs, err := parser.Run(document(), s)
+	if err != nil {
+		return TomlTable{}, parseError(err)
+	}
+	root := &table{kind: tableHeader}
+	current := root
+	for _, e := range exprs {
+		var err error
+		switch e.kind {
+		case exprKeyval:
+			err = current.set(e.keyval)
+		case exprTable:
+			current, err = root.define(e.keyval.key)
+		case exprArrayTable:
+			current, err = root.appendTable(e.keyval.key)
+		}
+		if err != nil {
+			offset := e.keyval.offset
+			if inner, ok := err.(*inlineError); ok {
+				offset, err = inner.offset, inner.err
+			}
+			return TomlTable{}, parser.ErrorAt("toml", s, offset, err)
+		}
+	}
+	return root.toml(), nil
+}
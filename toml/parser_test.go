@@ -0,0 +1,199 @@
+package toml_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc := `# This is a TOML document
+title = "TOML Example"
+
+[owner]
+name = "Tom Preston-Werner"
+dob = 1979-05-27T07:32:00-08:00
+
+[database]
+enabled = true
+ports = [ 8000, 8001, 8002 ]
+temp_targets = { cpu = 79.5, case = 72.0 }
+
+[servers.alpha]
+ip = "10.0.0.1" # comment
+
+[[products]]
+name = "Hammer"
+
+[[products]]  # empty table within the array
+
+[[products]]
+name = "Nail"
+`
+	got, err := toml.Parse(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"title", "owner", "database", "servers", "products"}, got.Keys)
+	assert.Equal(t, map[string]any{
+		"title": "TOML Example",
+		"owner": map[string]any{
+			"name": "Tom Preston-Werner",
+			"dob":  time.Date(1979, 5, 27, 7, 32, 0, 0, time.FixedZone("", -8*3600)),
+		},
+		"database": map[string]any{
+			"enabled":      true,
+			"ports":        []any{int64(8000), int64(8001), int64(8002)},
+			"temp_targets": map[string]any{"cpu": 79.5, "case": 72.0},
+		},
+		"servers":  map[string]any{"alpha": map[string]any{"ip": "10.0.0.1"}},
+		"products": []any{map[string]any{"name": "Hammer"}, map[string]any{}, map[string]any{"name": "Nail"}},
+	}, toml.ToNative(got))
+
+	port, ok := got.Lookup("database", "ports")
+	assert.True(t, ok)
+	assert.Equal(t, toml.TomlArray{Val: []toml.Toml{toml.TomlInt{Val: 8000}, toml.TomlInt{Val: 8001}, toml.TomlInt{Val: 8002}}}, port)
+	_, ok = got.Lookup("title", "x")
+	assert.False(t, ok)
+}
+
+func TestValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  toml.Toml
+	}{
+		{"basic string", `"tab\there \"q\" \u00e9\U0001F600"`, toml.TomlString{Val: "tab\there \"q\" \u00e9\U0001F600"}},
+		{"literal string", `'C:\Users\nodejs'`, toml.TomlString{Val: `C:\Users\nodejs`}},
+		{"multi-line basic string", "\"\"\"\nRoses are red\r\nViolets are blue\"\"\"", toml.TomlString{Val: "Roses are red\r\nViolets are blue"}},
+		{"line ending backslash", "\"\"\"\nThe quick \\\n\n   brown fox.\"\"\"", toml.TomlString{Val: "The quick brown fox."}},
+		{"quotes before closing", `"""Here are two quotation marks: "". Simple enough."""""`, toml.TomlString{Val: `Here are two quotation marks: "". Simple enough.""`}},
+		{"multi-line literal string", "'''\nThe first newline is\ntrimmed in 'raw' strings.'''", toml.TomlString{Val: "The first newline is\ntrimmed in 'raw' strings."}},
+		{"integer", "+1_000", toml.TomlInt{Val: 1000}},
+		{"negative integer", "-17", toml.TomlInt{Val: -17}},
+		{"hex", "0xDEAD_beef", toml.TomlInt{Val: 0xdeadbeef}},
+		{"octal", "0o755", toml.TomlInt{Val: 0o755}},
+		{"binary", "0b1101", toml.TomlInt{Val: 13}},
+		{"float", "6.626e-34", toml.TomlFloat{Val: 6.626e-34}},
+		{"float with underscores", "-224_617.445_991", toml.TomlFloat{Val: -224617.445991}},
+		{"exponent", "5e+22", toml.TomlFloat{Val: 5e22}},
+		{"inf", "-inf", toml.TomlFloat{Val: math.Inf(-1)}},
+		{"boolean", "false", toml.TomlBool{Val: false}},
+		{"offset date-time", "1979-05-27T00:32:00.5+07:00", toml.TomlDatetime{Val: time.Date(1979, 5, 27, 0, 32, 0, 5e8, time.FixedZone("", 7*3600)), Kind: toml.OffsetDatetime}},
+		{"space separator", "1979-05-27 07:32:00Z", toml.TomlDatetime{Val: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), Kind: toml.OffsetDatetime}},
+		{"local date-time", "1979-05-27T07:32:00", toml.TomlDatetime{Val: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), Kind: toml.LocalDatetime}},
+		{"local date", "1979-05-27", toml.TomlDatetime{Val: time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC), Kind: toml.LocalDate}},
+		{"local time", "07:32:00.999", toml.TomlDatetime{Val: time.Date(0, 1, 1, 7, 32, 0, 999e6, time.UTC), Kind: toml.LocalTime}},
+		{"nested array", "[ [ 1, 2 ], ['a', \"b\"], ]", toml.TomlArray{Val: []toml.Toml{
+			toml.TomlArray{Val: []toml.Toml{toml.TomlInt{Val: 1}, toml.TomlInt{Val: 2}}},
+			toml.TomlArray{Val: []toml.Toml{toml.TomlString{Val: "a"}, toml.TomlString{Val: "b"}}},
+		}}},
+		{"multi-line array", "[\n  1, # one\n  2\n]", toml.TomlArray{Val: []toml.Toml{toml.TomlInt{Val: 1}, toml.TomlInt{Val: 2}}}},
+		{"empty array", "[ ]", toml.TomlArray{Val: []toml.Toml{}}},
+		{"inline table", "{ x = 1, y.z = 2 }", toml.TomlTable{
+			Val: map[string]toml.Toml{
+				"x": toml.TomlInt{Val: 1},
+				"y": toml.TomlTable{Val: map[string]toml.Toml{"z": toml.TomlInt{Val: 2}}, Keys: []string{"z"}},
+			},
+			Keys: []string{"x", "y"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toml.Parse("v = " + tt.input)
+			assert.NoError(t, err)
+			if f, ok := tt.want.(toml.TomlFloat); ok {
+				assert.InDelta(t, f.Val, got.Val["v"].(toml.TomlFloat).Val, 1e-9*math.Abs(f.Val))
+				return
+			}
+			assert.Equal(t, tt.want, got.Val["v"])
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	got, err := toml.Parse(`
+"quoted key" = 1
+site."google.com" = true
+'literal' . bare = 2
+[fruit]
+apple.color = "red"
+[fruit.apple.texture]
+smooth = true
+[a.b.c]
+[a]
+d = 1
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"quoted key": int64(1),
+		"site":       map[string]any{"google.com": true},
+		"literal":    map[string]any{"bare": int64(2)},
+		"fruit":      map[string]any{"apple": map[string]any{"color": "red", "texture": map[string]any{"smooth": true}}},
+		"a":          map[string]any{"b": map[string]any{"c": map[string]any{}}, "d": int64(1)},
+	}, toml.ToNative(got))
+}
+
+func TestArrayOfTables(t *testing.T) {
+	got, err := toml.Parse(`
+[[fruits]]
+name = "apple"
+
+[fruits.physical]
+color = "red"
+
+[[fruits.varieties]]
+name = "red delicious"
+
+[[fruits]]
+name = "banana"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"fruits": []any{
+			map[string]any{
+				"name":      "apple",
+				"physical":  map[string]any{"color": "red"},
+				"varieties": []any{map[string]any{"name": "red delicious"}},
+			},
+			map[string]any{"name": "banana"},
+		},
+	}, toml.ToNative(got))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+		msg   string
+	}{
+		{"duplicate key", "a = 1\na = 2", toml.ErrDuplicateKey, "toml: line 2, col 1: duplicate key a"},
+		{"duplicate table", "[a]\n[a]", toml.ErrDuplicateTable, "toml: line 2, col 1: duplicate table a"},
+		{"table over dotted key", "[fruit]\napple.color = 1\n[fruit.apple]", toml.ErrDuplicateTable, "toml: line 3, col 1: duplicate table fruit.apple"},
+		{"dotted key over table", "[a.b]\n[a]\nb.c = 1", toml.ErrDuplicateTable, "toml: line 3, col 1: duplicate table a.b"},
+		{"key over value", "a = 1\n[a.b]", toml.ErrDuplicateKey, "toml: line 2, col 1: duplicate key a"},
+		{"extend inline table", "a = {}\n[a]", toml.ErrDuplicateKey, "toml: line 2, col 1: duplicate key a"},
+		{"inline table", "x = { \"a b\" = 1, 'a b' = 2 }", toml.ErrDuplicateKey, `toml: line 1, col 18: duplicate key x."a b"`},
+		{"array of tables over array", "a = []\n[[a]]", toml.ErrDuplicateKey, "toml: line 2, col 1: duplicate key a"},
+		{"leading zero", "a = 01", parser.ErrNoMatch, "toml: line 1, col 5: unexpected '0', expected value"},
+		{"bad underscore", "a = 1__0", parser.ErrNoMatch, "expected value"},
+		{"bad date", "a = 1979-13-01", parser.ErrNoMatch, "expected value"},
+		{"unterminated string", "a = \"x", parser.ErrUnexpectedEOF, "toml: line 1, col 7: unexpected end of input"},
+		{"newline in string", "a = \"x\ny\"", parser.ErrNoMatch, "toml: line 1, col 7: unexpected '\\n'"},
+		{"missing value", "a =\n", parser.ErrNoMatch, "line 1, col 4"},
+		{"two values", "a = 1 b = 2", parser.ErrNoMatch, "toml: line 1, col 7: unexpected 'b'"},
+		{"bad escape", `a = "\x"`, parser.ErrNoMatch, "toml: line 1, col 7: unexpected 'x'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toml.Parse(tt.input)
+			var perr *toml.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package toml provides the construction of tables from the expressions of a TOML document.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tableKind tells how a table was created, which decides how it may be extended.
+type tableKind int
+
+const (
+	// tableImplicit is a table created as the parent of a table defined with a header.
+	// A header may define it later.
+	tableImplicit tableKind = iota
+	// tableHeader is a table defined with a header, or the root table.
+	tableHeader
+	// tableDotted is a table created by a dotted key. Other dotted keys may extend it, and
+	// headers may define tables below it, but not the table itself.
+	tableDotted
+)
+
+// table is a table under construction.
+type table struct {
+	kind tableKind
+	// path is the key of the table from the root table, for error messages.
+	path  []string
+	keys  []string
+	items map[string]*item
+}
+
+// item is the value of a key in a table under construction: a value, a table or an array of
+// tables. Inline tables and arrays are values, since they cannot be extended.
+type item struct {
+	value  Toml
+	table  *table
+	tables []*table
+}
+
+// inlineError is an error in an inline table, at the key with the byte offset in the document.
+type inlineError struct {
+	offset int
+	err    error
+}
+
+// Error implements the error interface.
+func (e *inlineError) Error() string {
+	return e.err.Error()
+}
+
+// formatKey returns the dotted key of path, with the parts that are not bare keys quoted.
+func formatKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = p
+		if p == "" || strings.IndexFunc(p, func(r rune) bool { return !isBare(r) }) >= 0 {
+			parts[i] = strconv.Quote(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// keyError returns err for the key of path.
+func keyError(err error, path []string) error {
+	return fmt.Errorf("%w %s", err, formatKey(path))
+}
+
+// sub returns the path of the key below t.
+func (t *table) sub(key ...string) []string {
+	return append(t.path[:len(t.path):len(t.path)], key...)
+}
+
+// add adds the item for key to t.
+func (t *table) add(key string, it *item) {
+	if t.items == nil {
+		t.items = make(map[string]*item)
+	}
+	t.keys = append(t.keys, key)
+	t.items[key] = it
+}
+
+// set adds the key-value pair kv to t, creating the tables of a dotted key.
+func (t *table) set(kv keyval) error {
+	last := len(kv.key) - 1
+	for i, k := range kv.key[:last] {
+		it := t.items[k]
+		switch {
+		case it == nil:
+			sub := &table{kind: tableDotted, path: t.sub(k)}
+			t.add(k, &item{table: sub})
+			t = sub
+		case it.table != nil && it.table.kind == tableDotted:
+			t = it.table
+		case it.table != nil || it.tables != nil:
+			return keyError(ErrDuplicateTable, t.sub(kv.key[:i+1]...))
+		default:
+			return keyError(ErrDuplicateKey, t.sub(kv.key[:i+1]...))
+		}
+	}
+	if t.items[kv.key[last]] != nil {
+		return keyError(ErrDuplicateKey, t.sub(kv.key...))
+	}
+	v, err := build(kv.value, t.sub(kv.key...))
+	if err != nil {
+		return err
+	}
+	t.add(kv.key[last], &item{value: v})
+	return nil
+}
+
+// build returns the value of n, whose key has the path.
+func build(n node, path []string) (Toml, error) {
+	switch {
+	case n.isArray:
+		elems := make([]Toml, len(n.array))
+		for i, e := range n.array {
+			v, err := build(e, path)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = v
+		}
+		return TomlArray{Val: elems}, nil
+	case n.isTable:
+		t := &table{kind: tableHeader, path: path}
+		for _, kv := range n.table {
+			if err := t.set(kv); err != nil {
+				if _, ok := err.(*inlineError); !ok {
+					err = &inlineError{offset: kv.offset, err: err}
+				}
+				return nil, err
+			}
+		}
+		return t.toml(), nil
+	}
+	return n.value, nil
+}
+
+// parent returns the table holding the last key of path below the root table t, creating the
+// tables on the way. The last element of an array of tables stands for the array.
+func (t *table) parent(path []string) (*table, error) {
+	for i, k := range path[:len(path)-1] {
+		it := t.items[k]
+		switch {
+		case it == nil:
+			sub := &table{kind: tableImplicit, path: path[:i+1]}
+			t.add(k, &item{table: sub})
+			t = sub
+		case it.table != nil:
+			t = it.table
+		case it.tables != nil:
+			t = it.tables[len(it.tables)-1]
+		default:
+			return nil, keyError(ErrDuplicateKey, path[:i+1])
+		}
+	}
+	return t, nil
+}
+
+// define defines the table of a [path] header below the root table t and returns it.
+func (t *table) define(path []string) (*table, error) {
+	t, err := t.parent(path)
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	it := t.items[key]
+	switch {
+	case it == nil:
+		sub := &table{kind: tableHeader, path: path}
+		t.add(key, &item{table: sub})
+		return sub, nil
+	case it.table != nil && it.table.kind == tableImplicit:
+		it.table.kind = tableHeader
+		return it.table, nil
+	case it.table != nil || it.tables != nil:
+		return nil, keyError(ErrDuplicateTable, path)
+	}
+	return nil, keyError(ErrDuplicateKey, path)
+}
+
+// appendTable appends a table to the array of tables of a [[path]] header below the root
+// table t and returns it.
+func (t *table) appendTable(path []string) (*table, error) {
+	t, err := t.parent(path)
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	sub := &table{kind: tableHeader, path: path}
+	it := t.items[key]
+	switch {
+	case it == nil:
+		t.add(key, &item{tables: []*table{sub}})
+	case it.tables != nil:
+		it.tables = append(it.tables, sub)
+	case it.table != nil:
+		return nil, keyError(ErrDuplicateTable, path)
+	default:
+		return nil, keyError(ErrDuplicateKey, path)
+	}
+	return sub, nil
+}
+
+// toml returns the finished table.
+func (t *table) toml() TomlTable {
+	tbl := TomlTable{Val: make(map[string]Toml, len(t.keys)), Keys: t.keys}
+	for _, k := range t.keys {
+		it := t.items[k]
+		switch {
+		case it.table != nil:
+			tbl.Val[k] = it.table.toml()
+		case it.tables != nil:
+			elems := make([]Toml, len(it.tables))
+			for i, sub := range it.tables {
+				elems[i] = sub.toml()
+			}
+			tbl.Val[k] = TomlArray{Val: elems}
+		default:
+			tbl.Val[k] = it.value
+		}
+	}
+	return tbl
+}
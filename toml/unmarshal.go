@@ -0,0 +1,298 @@
+// Package toml provides decoding of TOML documents into Go values using reflection.
+package toml
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// The argument must be a non-nil pointer.
+type InvalidUnmarshalError struct {
+	// Type is the type of the argument, or nil if the argument was nil.
+	Type reflect.Type
+}
+
+// Error implements the error interface.
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "toml: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "toml: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "toml: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// UnmarshalTypeError describes a TOML value that cannot be stored in the Go value it is decoded into.
+type UnmarshalTypeError struct {
+	// Value describes the TOML value, e.g. "string" or "integer 8080".
+	Value string
+	// Type is the Go type the value could not be assigned to.
+	Type reflect.Type
+	// Path locates the value in the document, e.g. "servers[0].port".
+	Path string
+}
+
+// Error implements the error interface.
+func (e *UnmarshalTypeError) Error() string {
+	if e.Path == "" {
+		return "toml: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+	}
+	return "toml: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String() + " at " + e.Path
+}
+
+// timeType is the type of time.Time, which date-times are decoded into.
+var timeType = reflect.TypeFor[time.Time]()
+
+// Unmarshal parses the TOML document data and stores the result in the value pointed to by v.
+//
+// Tables are decoded into structs, using the `toml:"name"` field tags or else case-insensitive
+// field names, and into maps with string keys; arrays, including arrays of tables, into slices
+// and arrays; date-times into time.Time; and any value into an empty interface as ToNative
+// returns it. Types implementing encoding.TextUnmarshaler receive the contents of a string.
+func Unmarshal(data string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	t, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	return unmarshal(t, rv.Elem(), "")
+}
+
+// UnmarshalValue stores the already parsed value t in the value pointed to by v, like Unmarshal.
+func UnmarshalValue(t Toml, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	return unmarshal(t, rv.Elem(), "")
+}
+
+// unmarshal stores t in rv, which must be settable. path locates t in the document for error messages.
+func unmarshal(t Toml, rv reflect.Value, path string) error {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshal(t, rv.Elem(), path)
+	}
+	if d, ok := t.(TomlDatetime); ok && rv.Type() == timeType {
+		rv.Set(reflect.ValueOf(d.Val))
+		return nil
+	}
+	if s, ok := t.(TomlString); ok && rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s.Val))
+		}
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(ToNative(t)))
+		return nil
+	}
+	mismatch := &UnmarshalTypeError{Value: describeValue(t), Type: rv.Type(), Path: path}
+	switch t := t.(type) {
+	case TomlBool:
+		if rv.Kind() != reflect.Bool {
+			return mismatch
+		}
+		rv.SetBool(t.Val)
+	case TomlString:
+		if rv.Kind() != reflect.String {
+			return mismatch
+		}
+		rv.SetString(t.Val)
+	case TomlInt:
+		return setInt(rv, t.Val, mismatch)
+	case TomlFloat:
+		if rv.Kind() != reflect.Float32 && rv.Kind() != reflect.Float64 ||
+			rv.OverflowFloat(t.Val) && !math.IsInf(t.Val, 0) {
+			return mismatch
+		}
+		rv.SetFloat(t.Val)
+	case TomlDatetime:
+		return mismatch
+	case TomlArray:
+		return unmarshalArray(t.Val, rv, path, mismatch)
+	case TomlTable:
+		return unmarshalTable(t, rv, path, mismatch)
+	}
+	return nil
+}
+
+// setInt stores an integer in rv, which may also be a float.
+func setInt(rv reflect.Value, i int64, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.OverflowInt(i) {
+			return mismatch
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i < 0 || rv.OverflowUint(uint64(i)) {
+			return mismatch
+		}
+		rv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(float64(i))
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// unmarshalArray stores the elements of a TOML array in a slice or array.
+// Extra elements are ignored and missing elements are zeroed when decoding into an array.
+func unmarshalArray(elems []Toml, rv reflect.Value, path string, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+		for i, e := range elems {
+			if err := unmarshal(e, s.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if i >= len(elems) {
+				rv.Index(i).SetZero()
+				continue
+			}
+			if err := unmarshal(elems[i], rv.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// unmarshalTable stores the values of a TOML table in a struct or a map with string keys.
+// Keys without a matching struct field are ignored.
+func unmarshalTable(t TomlTable, rv reflect.Value, path string, mismatch error) error {
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return mismatch
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(t.Val)))
+		}
+		for _, k := range t.OrderedKeys() {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := unmarshal(t.Val[k], elem, joinPath(path, k)); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		fields := structFields(rv.Type())
+		for _, k := range t.OrderedKeys() {
+			f, ok := lookupField(fields, k)
+			if !ok {
+				continue
+			}
+			if err := unmarshal(t.Val[k], rv.FieldByIndex(f.index), joinPath(path, k)); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// field is a struct field that can be decoded from the value of a key.
+type field struct {
+	// name is the key, from the toml tag or the field name.
+	name string
+	// index is the index sequence of the field, which may be promoted from an embedded struct.
+	index []int
+}
+
+// structFields returns the decodable fields of t, including those promoted from embedded structs.
+// Fields of the outer struct take precedence over promoted fields with the same name.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	var embedded []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("toml")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			for _, f := range structFields(sf.Type) {
+				embedded = append(embedded, field{name: f.name, index: append([]int{i}, f.index...)})
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: []int{i}})
+	}
+	for _, e := range embedded {
+		if _, ok := lookupField(fields, e.name); !ok {
+			fields = append(fields, e)
+		}
+	}
+	return fields
+}
+
+// lookupField finds the field for a key, preferring an exact match over a case-insensitive one.
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// joinPath appends a key to a path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describeValue describes a TOML value for error messages.
+func describeValue(t Toml) string {
+	switch t := t.(type) {
+	case TomlBool:
+		return "boolean"
+	case TomlString:
+		return "string"
+	case TomlInt:
+		return fmt.Sprintf("integer %d", t.Val)
+	case TomlFloat:
+		return fmt.Sprintf("float %v", t.Val)
+	case TomlDatetime:
+		return t.Kind.String()
+	case TomlArray:
+		return "array"
+	case TomlTable:
+		return "table"
+	}
+	return "value"
+}
@@ -0,0 +1,99 @@
+package toml_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/81120/tiny-parsec/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	Name string `toml:"name"`
+	IP   net.IP `toml:"ip"`
+	Port uint16
+	Tags []string `toml:"tags"`
+}
+
+type config struct {
+	Title   string    `toml:"title"`
+	Started time.Time `toml:"started"`
+	Timeout float64   `toml:"timeout"`
+	Servers []server  `toml:"servers"`
+	Owner   *struct{ Name string }
+	Extra   map[string]any `toml:"extra"`
+	Ignored string         `toml:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	doc := `
+title = "example"
+started = 2024-03-01T10:00:00Z
+timeout = 5
+
+[owner]
+name = "Ada"
+
+[[servers]]
+name = "alpha"
+ip = "10.0.0.1"
+port = 8080
+tags = ["a", "b"]
+
+[[servers]]
+name = "beta"
+
+[extra]
+flag = true
+level = { n = 1 }
+`
+	var c config
+	assert.NoError(t, toml.Unmarshal(doc, &c))
+	assert.Equal(t, "example", c.Title)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), c.Started)
+	assert.Equal(t, 5.0, c.Timeout)
+	assert.Equal(t, []server{
+		{Name: "alpha", IP: net.ParseIP("10.0.0.1"), Port: 8080, Tags: []string{"a", "b"}},
+		{Name: "beta"},
+	}, c.Servers)
+	assert.Equal(t, "Ada", c.Owner.Name)
+	assert.Equal(t, map[string]any{"flag": true, "level": map[string]any{"n": int64(1)}}, c.Extra)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Run("non-pointer", func(t *testing.T) {
+		var c config
+		assert.EqualError(t, toml.Unmarshal("", c), "toml: Unmarshal(non-pointer toml_test.config)")
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var c config
+		err := toml.Unmarshal("[[servers]]\nport = 70000", &c)
+		var terr *toml.UnmarshalTypeError
+		assert.ErrorAs(t, err, &terr)
+		assert.EqualError(t, err, "toml: cannot unmarshal integer 70000 into Go value of type uint16 at servers[0].port")
+	})
+
+	t.Run("date-time into string", func(t *testing.T) {
+		var v struct{ Title string }
+		assert.EqualError(t, toml.Unmarshal("title = 1979-05-27", &v),
+			"toml: cannot unmarshal local date into Go value of type string at title")
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		var c config
+		var perr *toml.ParseError
+		assert.ErrorAs(t, toml.Unmarshal("title =", &c), &perr)
+	})
+}
+
+func TestUnmarshalValue(t *testing.T) {
+	doc, err := toml.Parse("[point]\nx = 1\ny = 2.5")
+	assert.NoError(t, err)
+	point, _ := doc.Get("point")
+	var p struct{ X, Y float64 }
+	assert.NoError(t, toml.UnmarshalValue(point, &p))
+	assert.Equal(t, 1.0, p.X)
+	assert.Equal(t, 2.5, p.Y)
+}
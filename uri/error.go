@@ -0,0 +1,15 @@
+// Package uri provides error reporting for malformed URIs.
+package uri
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ErrInvalidEscape is reported by Unescape for a '%' not followed by two hex digits.
+var ErrInvalidEscape = errors.New("invalid escape")
+
+// ParseError describes why a URI could not be parsed. Expected lists what the URI should contain at
+// the error, e.g. "hex digit".
+type ParseError = parser.Error
@@ -0,0 +1,60 @@
+// Package uri provides percent-encoding of URI components.
+package uri
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unescape decodes the percent-encoded bytes of a URI component, e.g. "a%20b" into "a b".
+// Unlike query decoding it leaves '+' unchanged. It fails with ErrInvalidEscape for a '%'
+// not followed by two hex digits.
+func Unescape(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) || !isHexDigit(rune(s[i+1])) || !isHexDigit(rune(s[i+2])) {
+			return "", fmt.Errorf("uri: %w %q", ErrInvalidEscape, s[i:min(i+3, len(s))])
+		}
+		b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// unhex returns the value of the hex digit c.
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
+
+// Escape percent-encodes every byte of s other than the unreserved characters (letters,
+// digits, '-', '.', '_' and '~'), so that the result can be used as any component of a URI,
+// e.g. as a single path segment.
+func Escape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; isUnreserved(rune(c)) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String()
+}
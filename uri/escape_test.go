@@ -0,0 +1,43 @@
+package uri_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/uri"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"a%20b+c", "a b+c"},
+		{"%2f%2F", "//"},
+		{"%E2%82%AC", "€"},
+	}
+	for _, tt := range tests {
+		got, err := uri.Unescape(tt.input)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	for _, bad := range []string{"%", "%2", "%zz", "a%2x"} {
+		_, err := uri.Unescape(bad)
+		assert.ErrorIs(t, err, uri.ErrInvalidEscape, bad)
+	}
+	_, err := uri.Unescape("a%2xb")
+	assert.EqualError(t, err, `uri: invalid escape "%2x"`)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "a-b_c.d~e", uri.Escape("a-b_c.d~e"))
+	assert.Equal(t, "a%20b%2Fc%3F%25", uri.Escape("a b/c?%"))
+	assert.Equal(t, "%E2%82%AC", uri.Escape("€"))
+	for _, s := range []string{"", "a b", "€/?#[]@!$&'()*+,;=%"} {
+		got, err := uri.Unescape(uri.Escape(s))
+		assert.NoError(t, err)
+		assert.Equal(t, s, got)
+	}
+}
@@ -0,0 +1,196 @@
+// Package uri provides the RFC 3986 grammar of URIs and relative references, built with the
+// tiny-parsec combinators.
+package uri
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func join(ss []string) string {
+	return strings.Join(ss, "")
+}
+
+func isAlpha(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+// isUnreserved reports whether r may appear unescaped anywhere in a URI.
+func isUnreserved(r rune) bool {
+	return isAlpha(r) || isDigit(r) || strings.ContainsRune("-._~", r)
+}
+
+// isSubDelim reports whether r is one of the sub-delims, which components may use unescaped.
+func isSubDelim(r rune) bool {
+	return strings.ContainsRune("!$&'()*+,;=", r)
+}
+
+// isPathChar reports whether r is a pchar other than a percent-encoded byte.
+func isPathChar(r rune) bool {
+	return isUnreserved(r) || isSubDelim(r) || r == ':' || r == '@'
+}
+
+// pctEncoded parses a percent-encoded byte such as "%2F", keeping it encoded.
+func pctEncoded() parser.Parser[string] {
+	hex := parser.SatisfyMsg(isHexDigit, "hex digit")
+	return parser.Fmap(parser.Seq(parser.Char('%'), hex, hex), parser.Text)
+}
+
+// run parses the characters accepted by f and percent-encoded bytes, keeping them as written.
+func run(f func(rune) bool) parser.Parser[string] {
+	return parser.Fmap(parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(f)), parser.Text),
+		pctEncoded(),
+	)), join)
+}
+
+// scheme parses a scheme such as "https" and the colon following it.
+func scheme() parser.Parser[string] {
+	rest := parser.ZeroOrMore(parser.Satisfy(func(r rune) bool {
+		return isAlpha(r) || isDigit(r) || r == '+' || r == '-' || r == '.'
+	}))
+	return parser.OmitRight(
+		parser.Fmap(parser.Seq(parser.Fmap(parser.SatisfyMsg(isAlpha, "scheme"), func(r rune) []rune { return []rune{r} }), rest),
+			func(parts [][]rune) string { return parser.Text(append(parts[0], parts[1]...)) }),
+		parser.Char(':'),
+	)
+}
+
+// ipLiteral parses a bracketed IPv6 address or IPvFuture literal, e.g. "[::1]" or "[v1.x]".
+func ipLiteral() parser.Parser[Authority] {
+	future := parser.Fmap(parser.Seq(
+		parser.Fmap(parser.OrElse(parser.Char('v'), parser.Char('V')), func(r rune) string { return string(r) }),
+		parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isHexDigit, "hex digit")), parser.Text),
+		parser.Str("."),
+		parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(func(r rune) bool {
+			return isUnreserved(r) || isSubDelim(r) || r == ':'
+		}, "IPvFuture address")), parser.Text),
+	), join)
+	ipv6 := parser.SatisfyWithMsg(
+		parser.Fmap(parser.OneOrMore(parser.Satisfy(func(r rune) bool { return isHexDigit(r) || r == ':' || r == '.' })), parser.Text),
+		func(s string) bool {
+			addr, err := netip.ParseAddr(s)
+			return err == nil && addr.Is6() && strings.Contains(s, ":")
+		},
+		"IPv6 address",
+	)
+	return parser.Between(parser.Char('['), parser.OrElse(
+		parser.Fmap(future, func(s string) Authority { return Authority{Host: s, HostType: IPvFuture} }),
+		parser.Fmap(ipv6, func(s string) Authority { return Authority{Host: s, HostType: IPv6} }),
+	), parser.Char(']'))
+}
+
+// host parses an IP literal, an IPv4 address or a registered name.
+func host() parser.Parser[Authority] {
+	regName := parser.Fmap(run(func(r rune) bool { return isUnreserved(r) || isSubDelim(r) }), func(s string) Authority {
+		if addr, err := netip.ParseAddr(s); err == nil && addr.Is4() {
+			return Authority{Host: s, HostType: IPv4}
+		}
+		return Authority{Host: s}
+	})
+	return parser.OrElse(ipLiteral(), regName)
+}
+
+// authority parses "[userinfo@]host[:port]", following the "//" that introduces it.
+func authority() parser.Parser[Authority] {
+	// Only try userinfo if an '@' ends the authority, so that an error in the host or port is
+	// not reported as a missing '@'.
+	hasUserinfo := parser.NewStateParser(func(st parser.State) parser.StateFuncRet[struct{}] {
+		s := st.Input()
+		if i := strings.IndexAny(s, "@/?#"); i < 0 || s[i] != '@' {
+			return parser.Nothing[parser.Tuple[struct{}, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(struct{}{}, st))
+	})
+	userinfo := parser.ZeroOrOne(parser.OmitLeft(hasUserinfo, parser.OmitRight(
+		run(func(r rune) bool { return isUnreserved(r) || isSubDelim(r) || r == ':' }),
+		parser.Char('@'),
+	)))
+	port := parser.ZeroOrOne(parser.OmitLeft(parser.Char(':'), parser.Fmap(parser.ZeroOrMore(parser.SatisfyMsg(isDigit, "port")), parser.Text)))
+	return parser.Bind(userinfo, func(user parser.Maybe[string]) parser.Parser[Authority] {
+		return parser.Bind(host(), func(a Authority) parser.Parser[Authority] {
+			return parser.Fmap(port, func(p parser.Maybe[string]) Authority {
+				if user.IsJust() {
+					a.Userinfo, a.HasUserinfo = user.Get(), true
+				}
+				if p.IsJust() {
+					a.Port = p.Get()
+				}
+				return a
+			})
+		})
+	})
+}
+
+// segments parses the path segments following the authority or the first segment, each with its
+// leading slash.
+func segments() parser.Parser[string] {
+	return parser.Fmap(parser.ZeroOrMore(parser.Fmap(
+		parser.Seq(parser.Str("/"), run(isPathChar)),
+		join,
+	)), join)
+}
+
+// hierPart parses an authority and the path following it, or a path alone whose first segment
+// is parsed by first. A path without an authority may not start with "//".
+func hierPart(first parser.Parser[string]) parser.Parser[*URI] {
+	withAuthority := parser.Bind(parser.OmitLeft(parser.Str("//"), authority()), func(a Authority) parser.Parser[*URI] {
+		return parser.Fmap(segments(), func(path string) *URI { return &URI{Authority: &a, Path: path} })
+	})
+	path := parser.SatisfyWithMsg(
+		parser.Fmap(parser.Seq(first, segments()), join),
+		func(path string) bool { return !strings.HasPrefix(path, "//") },
+		"authority",
+	)
+	return parser.OrElse(withAuthority, parser.Fmap(path, func(path string) *URI { return &URI{Path: path} }))
+}
+
+// tail parses the optional query and fragment ending u.
+func tail(u *URI) parser.Parser[*URI] {
+	chars := run(func(r rune) bool { return isPathChar(r) || r == '/' || r == '?' })
+	query := parser.ZeroOrOne(parser.OmitLeft(parser.Char('?'), chars))
+	fragment := parser.ZeroOrOne(parser.OmitLeft(parser.Char('#'), chars))
+	return parser.Bind(query, func(q parser.Maybe[string]) parser.Parser[*URI] {
+		return parser.Fmap(fragment, func(f parser.Maybe[string]) *URI {
+			if q.IsJust() {
+				u.Query, u.HasQuery = q.Get(), true
+			}
+			if f.IsJust() {
+				u.Fragment, u.HasFragment = f.Get(), true
+			}
+			return u
+		})
+	})
+}
+
+// absoluteURI parses a URI with a scheme, e.g. "https://example.com/a?b#c".
+func absoluteURI() parser.Parser[*URI] {
+	return parser.Bind(scheme(), func(s string) parser.Parser[*URI] {
+		return parser.Bind(hierPart(run(isPathChar)), func(u *URI) parser.Parser[*URI] {
+			u.Scheme = s
+			return tail(u)
+		})
+	})
+}
+
+// relativeRef parses a relative reference, e.g. "../a?b". Its first segment may not contain a
+// colon, which would make it read as a scheme.
+func relativeRef() parser.Parser[*URI] {
+	first := run(func(r rune) bool { return isPathChar(r) && r != ':' })
+	return parser.Bind(hierPart(first), tail)
+}
+
+// reference parses a URI or, failing that, a relative reference.
+func reference() parser.Parser[*URI] {
+	return parser.OrElse(absoluteURI(), relativeRef())
+}
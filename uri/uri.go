@@ -0,0 +1,161 @@
+// Package uri parses URIs and relative references as defined by RFC 3986 into their
+// components.
+//
+// Components are kept percent-encoded as written, so that an encoded delimiter such as
+// "%2F" in a path segment stays distinct from the delimiter itself. Unescape decodes a
+// component, and Escape encodes one.
+package uri
+
+import (
+	"strings"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// HostType identifies the syntax of the host of an authority.
+type HostType int
+
+const (
+	// RegName is a registered name such as "example.com", possibly empty.
+	RegName HostType = iota
+	// IPv4 is a dotted-decimal IPv4 address such as "192.0.2.1".
+	IPv4
+	// IPv6 is an IPv6 address written between brackets, such as "[2001:db8::1]".
+	IPv6
+	// IPvFuture is an address of a future version written between brackets, such as "[v7.abc]".
+	IPvFuture
+)
+
+// String returns the name of the host type, e.g. "IPv6".
+func (t HostType) String() string {
+	switch t {
+	case IPv4:
+		return "IPv4"
+	case IPv6:
+		return "IPv6"
+	case IPvFuture:
+		return "IPvFuture"
+	}
+	return "reg-name"
+}
+
+// Authority is the authority component of a URI: "[userinfo@]host[:port]".
+type Authority struct {
+	// Userinfo is the user information before "@", e.g. "user:password".
+	Userinfo string
+	// HasUserinfo distinguishes an empty Userinfo from a missing one.
+	HasUserinfo bool
+	// Host is the host, without the brackets around an IP literal.
+	Host string
+	// HostType is the syntax of Host.
+	HostType HostType
+	// Port is the decimal port number; it is empty if there is none, or if the colon before it
+	// is not followed by any digit.
+	Port string
+}
+
+// String returns the authority as it is written in a URI.
+func (a *Authority) String() string {
+	var b strings.Builder
+	if a.HasUserinfo {
+		b.WriteString(a.Userinfo)
+		b.WriteByte('@')
+	}
+	if a.HostType == IPv6 || a.HostType == IPvFuture {
+		b.WriteString("[" + a.Host + "]")
+	} else {
+		b.WriteString(a.Host)
+	}
+	if a.Port != "" {
+		b.WriteString(":" + a.Port)
+	}
+	return b.String()
+}
+
+// URI is a URI or a relative reference split into its components.
+type URI struct {
+	// Scheme is the scheme, e.g. "https"; it is empty for a relative reference.
+	Scheme string
+	// Authority is nil if the reference has no authority, i.e. does not contain "//"
+	// before its path.
+	Authority *Authority
+	// Path is the path, e.g. "/a/b%20c", possibly empty.
+	Path string
+	// Query is the query after "?", e.g. "q=1&r=2".
+	Query string
+	// HasQuery distinguishes an empty Query from a missing one.
+	HasQuery bool
+	// Fragment is the fragment after "#".
+	Fragment string
+	// HasFragment distinguishes an empty Fragment from a missing one.
+	HasFragment bool
+}
+
+// Parse parses an absolute URI, which has a scheme, e.g. "https://user@example.com:8080/a?b#c".
+func Parse(s string) (*URI, error) {
+	u, err := parser.Run(absoluteURI(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "uri")
+	}
+	return u, nil
+}
+
+// ParseReference parses a URI or a relative reference, e.g. "../a/b?c" or "//example.com/a".
+func ParseReference(s string) (*URI, error) {
+	u, err := parser.Run(reference(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "uri")
+	}
+	return u, nil
+}
+
+// MustParse is like Parse but panics if s is not a valid URI.
+func MustParse(s string) *URI {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// IsAbs reports whether u has a scheme.
+func (u *URI) IsAbs() bool {
+	return u.Scheme != ""
+}
+
+// Segments returns the percent-decoded segments of the path. The leading slash of an absolute
+// path does not start an empty segment, so "/a/b" has the segments "a" and "b" and "/" has a
+// single empty segment; the empty path has none.
+func (u *URI) Segments() ([]string, error) {
+	if u.Path == "" {
+		return nil, nil
+	}
+	segs := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	for i, seg := range segs {
+		s, err := Unescape(seg)
+		if err != nil {
+			return nil, err
+		}
+		segs[i] = s
+	}
+	return segs, nil
+}
+
+// String recomposes the reference from its components as described in RFC 3986 section 5.3.
+func (u *URI) String() string {
+	var b strings.Builder
+	if u.Scheme != "" {
+		b.WriteString(u.Scheme + ":")
+	}
+	if u.Authority != nil {
+		b.WriteString("//" + u.Authority.String())
+	}
+	b.WriteString(u.Path)
+	if u.HasQuery {
+		b.WriteString("?" + u.Query)
+	}
+	if u.HasFragment {
+		b.WriteString("#" + u.Fragment)
+	}
+	return b.String()
+}
@@ -0,0 +1,134 @@
+package uri_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/uri"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uri.URI
+	}{
+		{
+			"https://user:pw@Example.com:8080/a/b%20c?x=1&y=2#frag",
+			uri.URI{
+				Scheme:    "https",
+				Authority: &uri.Authority{Userinfo: "user:pw", HasUserinfo: true, Host: "Example.com", Port: "8080"},
+				Path:      "/a/b%20c",
+				Query:     "x=1&y=2", HasQuery: true,
+				Fragment: "frag", HasFragment: true,
+			},
+		},
+		{"mailto:joe@example.com", uri.URI{Scheme: "mailto", Path: "joe@example.com"}},
+		{"urn:isbn:0451450523", uri.URI{Scheme: "urn", Path: "isbn:0451450523"}},
+		{"file:///etc/hosts", uri.URI{Scheme: "file", Authority: &uri.Authority{}, Path: "/etc/hosts"}},
+		{"http://[2001:db8::1]:80/", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "2001:db8::1", HostType: uri.IPv6, Port: "80"}, Path: "/"}},
+		{"http://[::ffff:192.0.2.1]", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "::ffff:192.0.2.1", HostType: uri.IPv6}}},
+		{"http://[v7.a:b]/", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "v7.a:b", HostType: uri.IPvFuture}, Path: "/"}},
+		{"http://192.0.2.1/", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "192.0.2.1", HostType: uri.IPv4}, Path: "/"}},
+		{"http://192.0.2.256/", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "192.0.2.256"}, Path: "/"}},
+		{"svn+ssh://@host:/", uri.URI{Scheme: "svn+ssh", Authority: &uri.Authority{HasUserinfo: true, Host: "host"}, Path: "/"}},
+		{"http://x/?a?b/c#d/e?", uri.URI{Scheme: "http", Authority: &uri.Authority{Host: "x"}, Path: "/", Query: "a?b/c", HasQuery: true, Fragment: "d/e?", HasFragment: true}},
+		{"about:?#", uri.URI{Scheme: "about", HasQuery: true, HasFragment: true}},
+		{"x:/a//b", uri.URI{Scheme: "x", Path: "/a//b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			u, err := uri.Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, &tt.want, u)
+			assert.True(t, u.IsAbs())
+		})
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uri.URI
+	}{
+		{"", uri.URI{}},
+		{"../a/b?q#f", uri.URI{Path: "../a/b", Query: "q", HasQuery: true, Fragment: "f", HasFragment: true}},
+		{"//example.com/a", uri.URI{Authority: &uri.Authority{Host: "example.com"}, Path: "/a"}},
+		{"/a:b", uri.URI{Path: "/a:b"}},
+		{"./a:b", uri.URI{Path: "./a:b"}},
+		{"#top", uri.URI{Fragment: "top", HasFragment: true}},
+		{"http:x", uri.URI{Scheme: "http", Path: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			u, err := uri.ParseReference(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, &tt.want, u)
+			assert.Equal(t, tt.input, u.String())
+		})
+	}
+
+	_, err := uri.Parse("../a")
+	assert.Error(t, err)
+}
+
+func TestString(t *testing.T) {
+	for _, s := range []string{
+		"https://user:pw@example.com:8080/a/b%20c?x=1&y=2#frag",
+		"http://[2001:db8::1]:80/",
+		"http://[v7.a:b]/",
+		"file:///etc/hosts",
+		"urn:isbn:0451450523",
+		"about:?#",
+	} {
+		assert.Equal(t, s, uri.MustParse(s).String())
+	}
+	// An empty port is dropped.
+	assert.Equal(t, "http://a/", uri.MustParse("http://a:/").String())
+}
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"", nil},
+		{"/", []string{""}},
+		{"/a/b%2Fc/", []string{"a", "b/c", ""}},
+		{"a/%E2%82%AC", []string{"a", "€"}},
+	}
+	for _, tt := range tests {
+		segs, err := (&uri.URI{Path: tt.path}).Segments()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, segs)
+	}
+	_, err := (&uri.URI{Path: "/a%zz"}).Segments()
+	assert.ErrorIs(t, err, uri.ErrInvalidEscape)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{"http://a:80x", parser.ErrNoMatch, `uri: line 1, col 12: unexpected 'x', expected port, "/", '?', '#' or end of input`},
+		{"http://[::1", parser.ErrUnexpectedEOF, "uri: line 1, col 12: unexpected end of input, expected ']'"},
+		{"http://[1.2.3.4]/", parser.ErrNoMatch, "uri: line 1, col 9: unexpected '1', expected IPv6 address"},
+		{"http://%zz", parser.ErrNoMatch, "uri: line 1, col 9: unexpected 'z', expected hex digit"},
+		{"http://a/b c", parser.ErrNoMatch, "uri: line 1, col 11: unexpected ' '"},
+		{"http://a/é", parser.ErrNoMatch, "uri: line 1, col 10: unexpected"},
+		{"1a:b", parser.ErrNoMatch, "uri: line 1, col 1: unexpected '1', expected scheme"},
+		{"x", parser.ErrUnexpectedEOF, "uri: line 1, col 2: unexpected end of input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := uri.Parse(tt.input)
+			var perr *uri.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+	assert.Panics(t, func() { uri.MustParse("") })
+}
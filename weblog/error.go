@@ -0,0 +1,17 @@
+// Package weblog provides error reporting for malformed log lines.
+package weblog
+
+import (
+	"errors"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ErrInvalidTime is reported for a timestamp naming a day that does not exist, e.g.
+// "31/Feb/2024:00:00:00 +0000".
+var ErrInvalidTime = errors.New("invalid time")
+
+// ParseError describes why a log line could not be parsed. Expected lists what the line should
+// contain at the error, e.g. "status code". Err is the underlying cause: parser.ErrNoMatch or
+// parser.ErrUnexpectedEOF for syntax errors, or an error wrapping ErrInvalidTime.
+type ParseError = parser.Error
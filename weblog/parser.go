@@ -0,0 +1,175 @@
+// Package weblog provides the grammar of the access log lines written by Apache httpd and
+// nginx, built with the tiny-parsec combinators.
+package weblog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+var months = map[string]time.Month{
+	"Jan": time.January, "Feb": time.February, "Mar": time.March, "Apr": time.April,
+	"May": time.May, "Jun": time.June, "Jul": time.July, "Aug": time.August,
+	"Sep": time.September, "Oct": time.October, "Nov": time.November, "Dec": time.December,
+}
+
+// line is a log line as parsed, before its timestamp is checked.
+type line struct {
+	Record
+	// stamp is the offset of the timestamp in the line.
+	stamp int
+	// year, month, day, hour, minute, second and zone are the fields of the timestamp, with
+	// zone in seconds east of UTC.
+	year, month, day, hour, minute, second, zone int
+}
+
+// setter is a parser storing the value it parsed in a line.
+type setter = parser.Parser[func(*line)]
+
+// set returns a parser storing the value parsed by p in a line with f.
+func set[T any](p parser.Parser[T], f func(*line, T)) setter {
+	return parser.Fmap(p, func(v T) func(*line) {
+		return func(l *line) { f(l, v) }
+	})
+}
+
+// all returns a parser running the setters in sequence and storing all their values.
+func all(ps ...setter) setter {
+	return parser.Fmap(parser.Seq(ps...), func(fs []func(*line)) func(*line) {
+		return func(l *line) {
+			for _, f := range fs {
+				f(l)
+			}
+		}
+	})
+}
+
+// space parses the space before a field.
+func space[T any](p parser.Parser[T]) parser.Parser[T] {
+	return parser.OmitLeft(parser.Char(' '), p)
+}
+
+// number parses n digits giving a number between lo and hi.
+func number(n, lo, hi int, expected string) parser.Parser[int] {
+	ds := make([]parser.Parser[rune], n)
+	for i := range ds {
+		ds[i] = parser.SatisfyMsg(isDigit, expected)
+	}
+	num := parser.Fmap(parser.Seq(ds...), func(rs []rune) int {
+		v, _ := strconv.Atoi(parser.Text(rs))
+		return v
+	})
+	return parser.SatisfyWithMsg(num, func(v int) bool { return v >= lo && v <= hi }, expected)
+}
+
+// field parses a field without spaces, such as the host, giving "" for "-".
+func field(expected string) parser.Parser[string] {
+	isFieldChar := func(r rune) bool { return r > ' ' && r != 0x7f }
+	return parser.Fmap(parser.OneOrMore(parser.SatisfyMsg(isFieldChar, expected)), func(rs []rune) string {
+		if s := parser.Text(rs); s != "-" {
+			return s
+		}
+		return ""
+	})
+}
+
+// quoted parses a quoted field such as the request line, undoing the escapes of Apache
+// httpd and nginx: \" and \\, and \xHH for other bytes. A lone "-" gives "".
+func quoted() parser.Parser[string] {
+	hex := parser.SatisfyMsg(isHexDigit, "hex digit")
+	escape := parser.OmitLeft(parser.Char('\\'), parser.OrElse(
+		parser.Fmap(parser.Char('"'), func(rune) string { return `"` }),
+		parser.Fmap(parser.Char('\\'), func(rune) string { return `\` }),
+		parser.Fmap(parser.OmitLeft(parser.Char('x'), parser.Seq(hex, hex)), func(rs []rune) string {
+			b, _ := strconv.ParseUint(parser.Text(rs), 16, 8)
+			return string([]byte{byte(b)})
+		}),
+		parser.Fmap(parser.Pure[rune]('\\'), func(rune) string { return `\` }),
+	))
+	char := parser.Fmap(parser.Satisfy(func(r rune) bool { return r != '"' && r != '\\' }), func(r rune) string {
+		return string([]byte{byte(r)})
+	})
+	content := parser.Fmap(parser.ZeroOrMore(parser.OrElse(escape, char)), func(ss []string) string {
+		if s := strings.Join(ss, ""); s != "-" {
+			return s
+		}
+		return ""
+	})
+	return parser.Between(parser.Char('"'), content, parser.Char('"'))
+}
+
+// timestamp parses a timestamp such as "[10/Oct/2000:13:55:36 -0700]".
+func timestamp() setter {
+	month := parser.SatisfyWithMsg(parser.Fmap(parser.Seq(parser.Alpha(), parser.Alpha(), parser.Alpha()), parser.Text), func(s string) bool {
+		_, ok := months[s]
+		return ok
+	}, "month name")
+	sign := parser.OrElse(parser.Char('+'), parser.Char('-'))
+	zone := parser.Bind(sign, func(sign rune) parser.Parser[int] {
+		return parser.Bind(number(2, 0, 23, "offset hour"), func(h int) parser.Parser[int] {
+			return parser.Fmap(number(2, 0, 59, "offset minute"), func(m int) int {
+				if sign == '-' {
+					return -(h*3600 + m*60)
+				}
+				return h*3600 + m*60
+			})
+		})
+	})
+	after := func(c rune, p parser.Parser[int]) parser.Parser[int] {
+		return parser.OmitLeft(parser.Char(c), p)
+	}
+	stamp := all(
+		set(parser.Pos(), func(l *line, v int) { l.stamp = v }),
+		set(after('[', number(2, 1, 31, "day between 01 and 31")), func(l *line, v int) { l.day = v }),
+		set(parser.OmitLeft(parser.Char('/'), month), func(l *line, v string) { l.month = int(months[v]) }),
+		set(after('/', number(4, 0, 9999, "year")), func(l *line, v int) { l.year = v }),
+		set(after(':', number(2, 0, 23, "hour between 00 and 23")), func(l *line, v int) { l.hour = v }),
+		set(after(':', number(2, 0, 59, "minute between 00 and 59")), func(l *line, v int) { l.minute = v }),
+		set(after(':', number(2, 0, 60, "second between 00 and 60")), func(l *line, v int) { l.second = v }),
+		set(after(' ', zone), func(l *line, v int) { l.zone = v }),
+	)
+	return parser.OmitRight(stamp, parser.Char(']'))
+}
+
+// logLine parses a line in the Common Log Format, optionally followed by the referer and
+// user agent of the Combined Log Format.
+func logLine() parser.Parser[*line] {
+	bytes := parser.OrElse(
+		parser.Fmap(parser.Char('-'), func(rune) int64 { return 0 }),
+		parser.IntegerWithoutSign(),
+	)
+	combined := all(
+		set(space(quoted()), func(l *line, v string) { l.Referer = v }),
+		set(space(quoted()), func(l *line, v string) { l.UserAgent = v }),
+	)
+	fields := all(
+		set(field("host"), func(l *line, v string) { l.Host = v }),
+		set(space(field("identity")), func(l *line, v string) { l.Ident = v }),
+		set(space(field("user")), func(l *line, v string) { l.User = v }),
+		space(timestamp()),
+		set(space(quoted()), func(l *line, v string) { l.Request = v }),
+		set(space(number(3, 100, 599, "status code")), func(l *line, v int) { l.Status = v }),
+		set(space(bytes), func(l *line, v int64) { l.Bytes = v }),
+		set(parser.ZeroOrOne(combined), func(l *line, v parser.Maybe[func(*line)]) {
+			if v.IsJust() {
+				v.Get()(l)
+			}
+		}),
+	)
+	return parser.Fmap(fields, func(f func(*line)) *line {
+		l := &line{}
+		f(l)
+		return l
+	})
+}
@@ -0,0 +1,104 @@
+// Package weblog parses the access logs of web servers such as Apache httpd and nginx, in
+// the Common Log Format:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+//
+// and the Combined Log Format, which adds the referer and user agent:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"
+//
+// Parse parses a single line, and Read iterates over the lines of a log without loading it
+// into memory.
+package weblog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Record is a request logged in the Common or Combined Log Format. Fields logged as "-" are
+// empty.
+type Record struct {
+	// Host is the client address, usually an IP address, or a host name if the server
+	// resolved it.
+	Host string
+	// Ident is the RFC 1413 identity of the client, which servers rarely log.
+	Ident string
+	// User is the name of the authenticated user.
+	User string
+	// Time is the time the request was received, in the time zone of the log.
+	Time time.Time
+	// Request is the request line, e.g. "GET /index.html HTTP/1.1", with its escapes undone.
+	Request string
+	// Method, Target and Protocol are the parts of the request line, e.g. "GET",
+	// "/index.html" and "HTTP/1.1". They are empty if the request line is malformed, and
+	// Protocol is also empty for HTTP/0.9 requests.
+	Method, Target, Protocol string
+	// Status is the status code of the response.
+	Status int
+	// Bytes is the size of the response body, which is 0 if it was logged as "-".
+	Bytes int64
+	// Referer is the Referer header of the request, in the Combined Log Format.
+	Referer string
+	// UserAgent is the User-Agent header of the request, in the Combined Log Format.
+	UserAgent string
+}
+
+// IP returns the client address as an IP address. The result is false if Host is not one,
+// e.g. a host name.
+func (r *Record) IP() (netip.Addr, bool) {
+	ip, err := netip.ParseAddr(r.Host)
+	return ip, err == nil
+}
+
+// Parse parses a line in the Common or Combined Log Format.
+func Parse(s string) (*Record, error) {
+	l, err := parser.Run(logLine(), s)
+	if err != nil {
+		return nil, parser.Wrap(err, "weblog")
+	}
+	loc := time.FixedZone("", l.zone)
+	l.Time = time.Date(l.year, time.Month(l.month), l.day, l.hour, l.minute, l.second, 0, loc)
+	if l.Time.Day() != l.day {
+		stamp := s[l.stamp+1 : strings.IndexByte(s[l.stamp:], ']')+l.stamp]
+		return nil, parser.ErrorAt("weblog", s, l.stamp, fmt.Errorf("%w: %s", ErrInvalidTime, stamp))
+	}
+	switch parts := strings.Split(l.Request, " "); len(parts) {
+	case 3:
+		l.Method, l.Target, l.Protocol = parts[0], parts[1], parts[2]
+	case 2:
+		l.Method, l.Target = parts[0], parts[1]
+	}
+	return &l.Record, nil
+}
+
+// Read returns an iterator over the records of the log read from r, one per line. Blank
+// lines are skipped. A malformed line is reported as a *ParseError with the line number and
+// offset of the error in the log, after which iteration continues with the next line.
+// An error reading r ends the iteration.
+func Read(r io.Reader) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for l, err := range parser.ReadLines(r, parser.LineOptions{SkipBlank: true}) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			rec, err := Parse(l.Text)
+			var perr *ParseError
+			if errors.As(err, &perr) {
+				perr.Line = l.Number
+				perr.Offset += l.Offset
+			}
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package weblog_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/weblog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	zone := time.FixedZone("", -7*3600)
+	stamp := time.Date(2000, time.October, 10, 13, 55, 36, 0, zone)
+	tests := []struct {
+		name     string
+		input    string
+		expected weblog.Record
+	}{
+		{"common", `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			weblog.Record{Host: "127.0.0.1", User: "frank", Time: stamp, Request: "GET /apache_pb.gif HTTP/1.0",
+				Method: "GET", Target: "/apache_pb.gif", Protocol: "HTTP/1.0", Status: 200, Bytes: 2326}},
+		{"combined", `::1 - - [10/Oct/2000:13:55:36 -0700] "POST /a?b=c HTTP/2.0" 404 - "http://example.com/" "Mozilla/5.0 (X11; Linux)"`,
+			weblog.Record{Host: "::1", Time: stamp, Request: "POST /a?b=c HTTP/2.0", Method: "POST", Target: "/a?b=c",
+				Protocol: "HTTP/2.0", Status: 404, Referer: "http://example.com/", UserAgent: "Mozilla/5.0 (X11; Linux)"}},
+		{"empty referer", `h - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 304 0 "-" "curl/8.0"`,
+			weblog.Record{Host: "h", Time: stamp, Request: "GET / HTTP/1.1", Method: "GET", Target: "/",
+				Protocol: "HTTP/1.1", Status: 304, UserAgent: "curl/8.0"}},
+		{"escapes", `h - - [10/Oct/2000:13:55:36 -0700] "\x16\x03\x01 \"a\\b\" \q z" 400 0`,
+			weblog.Record{Host: "h", Time: stamp, Request: "\x16\x03\x01 \"a\\b\" \\q z", Status: 400}},
+		{"http/0.9", `h - - [10/Oct/2000:13:55:36 -0700] "GET /" 200 1`,
+			weblog.Record{Host: "h", Time: stamp, Request: "GET /", Method: "GET", Target: "/", Status: 200, Bytes: 1}},
+		{"malformed request", `h - - [10/Oct/2000:13:55:36 -0700] "-" 408 0`,
+			weblog.Record{Host: "h", Time: stamp, Status: 408}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := weblog.Parse(tt.input)
+			assert.NoError(t, err)
+			if err == nil {
+				assert.True(t, tt.expected.Time.Equal(r.Time))
+				_, offset := r.Time.Zone()
+				assert.Equal(t, -7*3600, offset)
+				r.Time = tt.expected.Time
+				assert.Equal(t, tt.expected, *r)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+		msg   string
+	}{
+		{`h - - [10/Oct/2000:13:55:36 -0700] "GET /" 600 0`, parser.ErrNoMatch, "weblog: line 1, col 44: unexpected '6', expected status code"},
+		{`h - - [10/Foo/2000:13:55:36 -0700] "GET /" 200 0`, parser.ErrNoMatch, "weblog: line 1, col 11: unexpected 'F', expected month name"},
+		{`h - - [10/Oct/2000:24:00:00 -0700] "GET /" 200 0`, parser.ErrNoMatch, "weblog: line 1, col 20: unexpected '2', expected hour between 00 and 23"},
+		{`h - - [31/Feb/2000:13:55:36 -0700] "GET /" 200 0`, weblog.ErrInvalidTime, "weblog: line 1, col 7: invalid time: 31/Feb/2000:13:55:36 -0700"},
+		{`h - - [10/Oct/2000:13:55:36 -0700] "GET / 200 0`, parser.ErrUnexpectedEOF, "weblog: line 1, col 48: unexpected end of input"},
+		{`h - - [10/Oct/2000:13:55:36 -0700] "GET /" 200 0 "r"`, parser.ErrUnexpectedEOF, "weblog: line 1, col 53: unexpected end of input, expected ' '"},
+		{`h - - [10/Oct/2000:13:55:36 -0700] "GET /" 200 0 extra`, parser.ErrNoMatch, "weblog: line 1, col 50: unexpected 'e', expected '\"'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := weblog.Parse(tt.input)
+			var perr *weblog.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestRecordIP(t *testing.T) {
+	ip, ok := (&weblog.Record{Host: "192.0.2.1"}).IP()
+	assert.True(t, ok)
+	assert.Equal(t, "192.0.2.1", ip.String())
+	_, ok = (&weblog.Record{Host: "example.com"}).IP()
+	assert.False(t, ok)
+}
+
+func TestRead(t *testing.T) {
+	log := `a - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 200 1` + "\r\n\n" +
+		"garbage\n" +
+		`b - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 200 2`
+
+	var hosts []string
+	var errs []error
+	for r, err := range weblog.Read(strings.NewReader(log)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		hosts = append(hosts, r.Host)
+	}
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	if assert.Len(t, errs, 1) {
+		var perr *weblog.ParseError
+		assert.ErrorAs(t, errs[0], &perr)
+		assert.Equal(t, 3, perr.Line)
+		assert.EqualError(t, perr, "weblog: line 3, col 8: unexpected end of input, expected host or ' '")
+	}
+
+	t.Run("stops early", func(t *testing.T) {
+		n := 0
+		for range weblog.Read(strings.NewReader(log)) {
+			n++
+			break
+		}
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		boom := errors.New("boom")
+		for _, err := range weblog.Read(iotest.ErrReader(boom)) {
+			assert.ErrorIs(t, err, boom)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+// Package xml defines a set of types to represent XML documents in Go.
+package xml
+
+import "strings"
+
+// Well-known namespaces bound to the reserved prefixes xml and xmlns.
+const (
+	// XMLNamespace is the namespace of the xml prefix, as in xml:lang.
+	XMLNamespace = "http://www.w3.org/XML/1998/namespace"
+	// XMLNSNamespace is the namespace of namespace declarations, as in xmlns:svg.
+	XMLNSNamespace = "http://www.w3.org/2000/xmlns/"
+)
+
+// Name is the name of an element or attribute, split at its colon and resolved against the
+// namespace declarations in scope.
+type Name struct {
+	// Space is the namespace URI the prefix is bound to, or for elements without a prefix the
+	// default namespace. It is empty if there is none.
+	Space string
+	// Prefix is the namespace prefix as written, e.g. "svg" in svg:rect.
+	Prefix string
+	// Local is the local part of the name, e.g. "rect" in svg:rect.
+	Local string
+}
+
+// SplitName splits a qualified name such as svg:rect into its prefix and local part.
+// A name without a colon has an empty prefix.
+func SplitName(qname string) Name {
+	prefix, local, ok := strings.Cut(qname, ":")
+	if !ok || prefix == "" || local == "" {
+		return Name{Local: qname}
+	}
+	return Name{Prefix: prefix, Local: local}
+}
+
+// String returns the qualified name as written, e.g. svg:rect.
+func (n Name) String() string {
+	if n.Prefix == "" {
+		return n.Local
+	}
+	return n.Prefix + ":" + n.Local
+}
+
+// Attr is an attribute of an element.
+type Attr struct {
+	Name Name
+	// Value is the value of the attribute, with references replaced and whitespace normalized.
+	Value string
+}
+
+// Node is implemented by the nodes of an element tree: *Element, Text, Comment, ProcInst and
+// Directive.
+type Node interface {
+	// xmlNode is a method that all node types must implement.
+	// It serves as a marker for the node type.
+	xmlNode()
+}
+
+// Element is an element with its attributes and content.
+type Element struct {
+	Name  Name
+	Attrs []Attr
+	// Children holds the content of the element in document order.
+	Children []Node
+}
+
+// xmlNode implements the Node interface for *Element.
+func (e *Element) xmlNode() {}
+
+// Attr returns the value of the attribute with the qualified name, e.g. "id" or "xml:lang",
+// and whether the element has it.
+func (e *Element) Attr(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Name.String() == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// AttrNS returns the value of the attribute with the local name in the namespace space, and
+// whether the element has it. Attributes without a prefix are in no namespace.
+func (e *Element) AttrNS(space, local string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Elements returns the child elements of e.
+func (e *Element) Elements() []*Element {
+	var elems []*Element
+	for _, c := range e.Children {
+		if el, ok := c.(*Element); ok {
+			elems = append(elems, el)
+		}
+	}
+	return elems
+}
+
+// Child returns the first child element with the local name, and whether there is one.
+func (e *Element) Child(local string) (*Element, bool) {
+	for _, el := range e.Elements() {
+		if el.Name.Local == local {
+			return el, true
+		}
+	}
+	return nil, false
+}
+
+// Text returns the text of e and all its descendants, including CDATA sections, concatenated
+// in document order.
+func (e *Element) Text() string {
+	var b strings.Builder
+	var walk func(*Element)
+	walk = func(e *Element) {
+		for _, c := range e.Children {
+			switch c := c.(type) {
+			case Text:
+				b.WriteString(c.Data)
+			case *Element:
+				walk(c)
+			}
+		}
+	}
+	walk(e)
+	return b.String()
+}
+
+// Text is character data: text with references replaced, or the contents of a CDATA section.
+type Text struct {
+	Data string
+	// CDATA is set for a CDATA section.
+	CDATA bool
+}
+
+// xmlNode implements the Node interface for Text.
+func (t Text) xmlNode() {}
+
+// Comment is a comment, without the <!-- and --> delimiters.
+type Comment struct {
+	Data string
+}
+
+// xmlNode implements the Node interface for Comment.
+func (c Comment) xmlNode() {}
+
+// ProcInst is a processing instruction such as <?xml version="1.0"?>.
+type ProcInst struct {
+	// Target is the name after <?, e.g. "xml".
+	Target string
+	// Inst is the rest of the instruction, without surrounding whitespace.
+	Inst string
+}
+
+// xmlNode implements the Node interface for ProcInst.
+func (p ProcInst) xmlNode() {}
+
+// Directive is a declaration such as <!DOCTYPE html>, without the <! and > delimiters.
+type Directive struct {
+	Data string
+}
+
+// xmlNode implements the Node interface for Directive.
+func (d Directive) xmlNode() {}
+
+// Document is a parsed XML document.
+type Document struct {
+	// Prolog holds the comments, processing instructions, including the XML declaration, and
+	// the document type declaration before the root element.
+	Prolog []Node
+	Root   *Element
+	// Epilog holds the comments and processing instructions after the root element.
+	Epilog []Node
+}
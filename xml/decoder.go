@@ -0,0 +1,316 @@
+// Package xml provides streaming access to the tokens and elements of an XML document.
+package xml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// Token is implemented by the tokens a Decoder reports: StartElement, EndElement, Text,
+// Comment, ProcInst and Directive.
+type Token interface {
+	// xmlToken is a method that all token types must implement.
+	// It serves as a marker for the token type.
+	xmlToken()
+}
+
+// StartElement is the start tag of an element, with its name and attributes resolved against the
+// namespace declarations in scope.
+type StartElement struct {
+	Name  Name
+	Attrs []Attr
+}
+
+// EndElement is the end tag of an element. An empty-element tag such as <br/> is reported as a
+// StartElement followed by an EndElement.
+type EndElement struct {
+	Name Name
+}
+
+// xmlToken implements the Token interface for StartElement.
+func (StartElement) xmlToken() {}
+
+// xmlToken implements the Token interface for EndElement.
+func (EndElement) xmlToken() {}
+
+// xmlToken implements the Token interface for Text.
+func (Text) xmlToken() {}
+
+// xmlToken implements the Token interface for Comment.
+func (Comment) xmlToken() {}
+
+// xmlToken implements the Token interface for ProcInst.
+func (ProcInst) xmlToken() {}
+
+// xmlToken implements the Token interface for Directive.
+func (Directive) xmlToken() {}
+
+// rawToken is a token as parsed: a tag, the name of an end tag, a node or the end of the input.
+type rawToken struct {
+	start *tag
+	end   string
+	node  Node
+	eof   bool
+}
+
+// open is an element whose end tag has not been read yet.
+type open struct {
+	// raw is the name as written, which the end tag must repeat.
+	raw   string
+	name  Name
+	scope map[string]string
+}
+
+// Decoder reads the tokens of an XML document from a reader, checking that it is well-formed as
+// Parse does. Only the current token is held in memory, so documents far larger than the
+// available memory can be processed.
+type Decoder struct {
+	r io.Reader
+	// buf holds the input read but not yet consumed.
+	buf string
+	// eof is set once r has reported the end of its input.
+	eof bool
+	// offset, line and col locate the start of buf in the document.
+	offset, line, col int
+	// stack holds the open elements, innermost last.
+	stack []open
+	// end is the EndElement to report next, after the StartElement of an empty-element tag.
+	end *EndElement
+	// root is set once the root element has started.
+	root bool
+	done bool
+	err  error
+	// prolog, content and epilog parse the next token before, inside and after the root element,
+	// and first the first token of the document.
+	first, prolog, content, epilog parser.Parser[rawToken]
+}
+
+// NewDecoder returns a Decoder reading an XML document from r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{r: r, line: 1, col: 1}
+	start := parser.Fmap(startTag(), func(t tag) rawToken { return rawToken{start: &t} })
+	node := func(p parser.Parser[Node]) parser.Parser[rawToken] {
+		return parser.Fmap(p, func(n Node) rawToken { return rawToken{node: n} })
+	}
+	comment := node(parser.Fmap(comment(), func(c Comment) Node { return c }))
+	procInst := node(parser.Fmap(procInst(), func(p ProcInst) Node { return p }))
+	d.prolog = parser.OmitLeft(optSpaces(), parser.OrElse(
+		comment, procInst, node(parser.Fmap(directive(), func(d Directive) Node { return d })), start,
+	))
+	// A byte order mark may only start the document
+	d.first = parser.OmitLeft(parser.ZeroOrOne(parser.Str("\ufeff")), d.prolog)
+	end := parser.Lazy(func() parser.Parser[rawToken] {
+		return parser.Fmap(endTag(d.stack[len(d.stack)-1].raw), func(name string) rawToken { return rawToken{end: name} })
+	})
+	d.content = parser.OrElse(
+		end,
+		start,
+		node(parser.Fmap(charData(), func(s string) Node { return Text{Data: s} })),
+		node(parser.Fmap(cdata(), func(s string) Node { return Text{Data: s, CDATA: true} })),
+		comment,
+		procInst,
+	)
+	d.epilog = parser.OmitLeft(optSpaces(), parser.OrElse(
+		comment, procInst, parser.Fmap(parser.EOF(), func(struct{}) rawToken { return rawToken{eof: true} }),
+	))
+	return d
+}
+
+// Token returns the next token of the document. Whitespace outside of the root element is
+// skipped. It returns io.EOF after the last token of a well-formed document. A malformed document
+// fails with a *ParseError; errors are final.
+func (d *Decoder) Token() (Token, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.end != nil {
+		end := *d.end
+		d.end = nil
+		d.pop()
+		return end, nil
+	}
+	if d.done {
+		return nil, io.EOF
+	}
+	p := d.content
+	switch {
+	case d.offset == 0:
+		p = d.first
+	case !d.root:
+		p = d.prolog
+	case len(d.stack) == 0:
+		p = d.epilog
+	}
+	t, n, err := scan(d, p)
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	if t.start != nil {
+		var scope map[string]string
+		if len(d.stack) > 0 {
+			scope = d.stack[len(d.stack)-1].scope
+		}
+		start := StartElement{Name: SplitName(t.start.name), Attrs: t.start.attrs}
+		scope, prefix := bindAll(scope, &start.Name, start.Attrs)
+		if prefix != "" {
+			// The tag is still in the buffer, to locate the error at its start
+			d.err = d.locate(parser.ErrorAt("xml", d.buf, t.start.offset, fmt.Errorf("%w: %s", ErrUndeclaredPrefix, prefix)))
+			return nil, d.err
+		}
+		d.consume(n)
+		d.root = true
+		d.stack = append(d.stack, open{raw: t.start.name, name: start.Name, scope: scope})
+		if t.start.empty {
+			d.end = &EndElement{Name: start.Name}
+		}
+		return start, nil
+	}
+	d.consume(n)
+	switch {
+	case t.end != "":
+		end := EndElement{Name: d.stack[len(d.stack)-1].name}
+		d.pop()
+		return end, nil
+	case t.eof:
+		d.done = true
+		return nil, io.EOF
+	}
+	return t.node.(Token), nil
+}
+
+// pop closes the innermost open element.
+func (d *Decoder) pop() {
+	d.stack = d.stack[:len(d.stack)-1]
+}
+
+// ReadElement reads the rest of the element started by start, which must be the token Token has
+// just returned, and returns it with its content.
+func (d *Decoder) ReadElement(start StartElement) (*Element, error) {
+	e := &Element{Name: start.Name, Attrs: start.Attrs}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case StartElement:
+			child, err := d.ReadElement(tok)
+			if err != nil {
+				return nil, err
+			}
+			e.Children = append(e.Children, child)
+		case EndElement:
+			return e, nil
+		case Node:
+			e.Children = append(e.Children, tok)
+		}
+	}
+}
+
+// Elements returns an iterator over the elements with the name read from r, in document order,
+// each with its content. The name is compared with the local name of the elements, or with
+// their qualified name if it contains a colon. Only the current element is held in memory; an
+// element nested in another one that is yielded is part of its content and not yielded on its own.
+// A malformed document ends the iteration with a *ParseError.
+func Elements(r io.Reader, name string) iter.Seq2[*Element, error] {
+	return func(yield func(*Element, error) bool) {
+		d := NewDecoder(r)
+		for {
+			tok, err := d.Token()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			start, ok := tok.(StartElement)
+			if !ok || start.Name.Local != name && start.Name.String() != name {
+				continue
+			}
+			e, err := d.ReadElement(start)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readSize is the minimum number of bytes requested from the reader at a time.
+const readSize = 4096
+
+// scan runs p on the buffer of d, reading more input while p needs it, and returns the number of
+// bytes p matched, which the caller consumes.
+func scan[T any](d *Decoder, p parser.Parser[T]) (T, int, error) {
+	var zero T
+	in := parser.NewIncremental(p, parser.Options{})
+	done, err := in.Feed(d.buf)
+	for !done && err == nil && !d.eof {
+		var chunk string
+		if chunk, err = d.read(); err == nil {
+			done, err = in.Feed(chunk)
+		}
+	}
+	if err != nil {
+		return zero, 0, d.locate(err)
+	}
+	t, err := in.Finish()
+	if err != nil {
+		return zero, 0, d.locate(err)
+	}
+	return t, len(d.buf) - len(in.Rest()), nil
+}
+
+// read appends the next chunk of input to the buffer and returns it. The chunk grows with
+// the buffer, so that a long token is re-parsed only a logarithmic number of times.
+func (d *Decoder) read() (string, error) {
+	b := make([]byte, max(readSize, len(d.buf)))
+	n, err := d.r.Read(b)
+	if errors.Is(err, io.EOF) {
+		d.eof = true
+	} else if err != nil {
+		return "", err
+	}
+	chunk := string(b[:n])
+	d.buf += chunk
+	return chunk, nil
+}
+
+// consume drops the first n bytes of the buffer and advances the position of the decoder past them.
+func (d *Decoder) consume(n int) {
+	text := d.buf[:n]
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		d.line += strings.Count(text, "\n")
+		d.col = 1 + utf8.RuneCountInString(text[i+1:])
+	} else {
+		d.col += utf8.RuneCountInString(text)
+	}
+	d.offset += n
+	d.buf = d.buf[n:]
+}
+
+// locate converts an error of the parser package into a *ParseError positioned in the whole document.
+// The parser reports positions relative to the start of the buffer.
+func (d *Decoder) locate(err error) error {
+	err = parseError(err)
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		if perr.Line == 1 {
+			perr.Column += d.col - 1
+		}
+		perr.Line += d.line - 1
+		perr.Offset += d.offset
+	}
+	return err
+}
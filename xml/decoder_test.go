@@ -0,0 +1,96 @@
+package xml_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/81120/tiny-parsec/xml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	d := xml.NewDecoder(iotest.OneByteReader(strings.NewReader(`<?xml version="1.0"?>
+<r xmlns:x="urn:x"><x:a k="v &amp; w">one &lt; two</x:a><br/><!--c--></r>
+`)))
+	var tokens []xml.Token
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		tokens = append(tokens, tok)
+	}
+	a := xml.Name{Space: "urn:x", Prefix: "x", Local: "a"}
+	assert.Equal(t, []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: `version="1.0"`},
+		xml.StartElement{Name: xml.Name{Local: "r"}, Attrs: []xml.Attr{{Name: xml.Name{Space: xml.XMLNSNamespace, Prefix: "xmlns", Local: "x"}, Value: "urn:x"}}},
+		xml.StartElement{Name: a, Attrs: []xml.Attr{{Name: xml.Name{Local: "k"}, Value: "v & w"}}},
+		xml.Text{Data: "one < two"},
+		xml.EndElement{Name: a},
+		xml.StartElement{Name: xml.Name{Local: "br"}},
+		xml.EndElement{Name: xml.Name{Local: "br"}},
+		xml.Comment{Data: "c"},
+		xml.EndElement{Name: xml.Name{Local: "r"}},
+	}, tokens)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	d := xml.NewDecoder(strings.NewReader("<a>\n  <b>text</c>"))
+	var err error
+	for err == nil {
+		_, err = d.Token()
+	}
+	assert.EqualError(t, err, "xml: line 2, col 12: unexpected 'c', expected </b>")
+	_, again := d.Token()
+	assert.Equal(t, err, again)
+
+	for input, msg := range map[string]string{
+		"<a>\n  <b>x</b><u:c/></a>": "xml: line 2, col 11: undeclared namespace prefix: u",
+		"<a>x]]>y</a>":              `xml: line 1, col 7: unexpected '>', expected "&gt;" after "]]"`,
+		"<a>\n &#xZZ;</a>":          "xml: line 2, col 2: invalid character reference",
+	} {
+		d := xml.NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+		for err = nil; err == nil; {
+			_, err = d.Token()
+		}
+		assert.EqualError(t, err, msg, input)
+	}
+}
+
+func TestElements(t *testing.T) {
+	doc := `<rss><channel><title>News</title>
+<item><title>First</title><item><title>nested</title></item></item>
+<item><title>Second</title></item>
+</channel></rss>`
+	var titles []string
+	for item, err := range xml.Elements(iotest.HalfReader(strings.NewReader(doc)), "item") {
+		assert.NoError(t, err)
+		title, _ := item.Child("title")
+		titles = append(titles, title.Text())
+	}
+	assert.Equal(t, []string{"First", "Second"}, titles)
+
+	t.Run("stop early", func(t *testing.T) {
+		n := 0
+		for range xml.Elements(strings.NewReader(doc), "item") {
+			n++
+			break
+		}
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		var errs []error
+		for _, err := range xml.Elements(strings.NewReader("<a><item></a>"), "item") {
+			errs = append(errs, err)
+		}
+		assert.Len(t, errs, 1)
+		assert.ErrorContains(t, errs[0], "expected </item>")
+	})
+}
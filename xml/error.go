@@ -0,0 +1,33 @@
+// Package xml provides error reporting for malformed XML documents.
+package xml
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// ParseError describes why an XML document could not be parsed. Expected lists what the document
+// should contain at the error, e.g. "</item>".
+type ParseError = parser.Error
+
+var (
+	// ErrInvalidCharRef is reported for a character reference that does not refer to a character,
+	// e.g. "&#xZZ;" or "&#0;".
+	ErrInvalidCharRef = errors.New("invalid character reference")
+	// ErrUndeclaredPrefix is reported for an element or attribute name whose namespace prefix is
+	// not declared.
+	ErrUndeclaredPrefix = errors.New("undeclared namespace prefix")
+)
+
+// parseError converts an error of the parser package into a *ParseError. A failure on a character
+// reference is reported with ErrInvalidCharRef rather than with what the document should contain.
+func parseError(err error) error {
+	err = parser.Wrap(err, "xml")
+	var perr *ParseError
+	if errors.As(err, &perr) && slices.Contains(perr.Expected, validCharRef) {
+		perr.Found, perr.Expected, perr.Err = "", nil, ErrInvalidCharRef
+	}
+	return err
+}
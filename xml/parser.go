@@ -0,0 +1,416 @@
+// Package xml provides a parser for XML 1.0 documents built on the combinators of the parser package.
+//
+// Documents must be well-formed: a single root element, matching start and end tags, unique
+// attribute names and known references. Document type declarations are kept as a Directive but
+// not interpreted, so only the predefined entities, such as &amp;, and character references,
+// such as &#xE9;, are replaced.
+package xml
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/81120/tiny-parsec/parser"
+)
+
+// join concatenates the parts of a text.
+func join(parts []string) string {
+	return strings.Join(parts, "")
+}
+
+// isSpace reports whether r is XML whitespace.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// isNameStart reports whether r may start a name. Bytes of multi-byte UTF-8 characters are
+// accepted, so that names may use any letters.
+func isNameStart(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || r == ':' || r >= 0x80
+}
+
+// isNameChar reports whether r may appear in a name after its first character.
+func isNameChar(r rune) bool {
+	return isNameStart(r) || r >= '0' && r <= '9' || r == '-' || r == '.'
+}
+
+// spaces parses one or more whitespace characters.
+func spaces() parser.Parser[[]rune] {
+	return parser.OneOrMore(parser.Satisfy(isSpace))
+}
+
+// optSpaces parses optional whitespace.
+func optSpaces() parser.Parser[[]rune] {
+	return parser.ZeroOrMore(parser.Satisfy(isSpace))
+}
+
+// run parses one or more bytes satisfying f and returns them.
+func run(f func(rune) bool) parser.Parser[string] {
+	return parser.Fmap(parser.OneOrMore(parser.Satisfy(f)), parser.Text)
+}
+
+// xmlName parses the name of an element, attribute, entity or processing instruction.
+func xmlName() parser.Parser[string] {
+	return parser.Bind(parser.SatisfyMsg(isNameStart, "name"), func(first rune) parser.Parser[string] {
+		return parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isNameChar)), func(rest []rune) string {
+			return parser.Text(append([]rune{first}, rest...))
+		})
+	})
+}
+
+// entities maps the predefined entities to their replacement text.
+var entities = map[string]string{"lt": "<", "gt": ">", "amp": "&", "apos": "'", "quot": `"`}
+
+// character returns the character referred to by the code of a character reference, the text
+// between "&#" and ';', e.g. "xE9" or "233", and whether it refers to one.
+func character(code string) (rune, bool) {
+	base, digits := 10, code
+	if strings.HasPrefix(code, "x") {
+		base, digits = 16, code[1:]
+	}
+	n, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || n == 0 || !utf8.ValidRune(rune(n)) {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// validCharRef describes the character references accepted by reference; parseError reports a
+// failure on it as ErrInvalidCharRef.
+const validCharRef = "valid character reference"
+
+// reference parses an entity or character reference and returns its replacement text.
+func reference() parser.Parser[string] {
+	isCodeChar := func(r rune) bool { return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }
+	// The code is read up to the ';' before it is checked, so that "&#xZZ;" is reported as a
+	// whole rather than as a missing digit
+	code := parser.OmitLeft(parser.Str("&#"), parser.Fmap(parser.ZeroOrMore(parser.Satisfy(isCodeChar)), parser.Text))
+	charRef := parser.Fmap(
+		parser.SatisfyWithMsg(code, func(code string) bool { _, ok := character(code); return ok }, validCharRef),
+		func(code string) string { r, _ := character(code); return string(r) },
+	)
+	entityRef := parser.OmitLeft(parser.Char('&'), parser.Fmap(
+		parser.SatisfyWithMsg(xmlName(), func(name string) bool { _, ok := entities[name]; return ok }, "entity name"),
+		func(name string) string { return entities[name] },
+	))
+	return parser.OmitRight(parser.OrElse(entityRef, charRef), parser.Char(';'))
+}
+
+// until parses the input up to and including stop and returns the input before it.
+func until(stop string) parser.Parser[string] {
+	return parser.NewStateParser(func(st parser.State) parser.StateFuncRet[string] {
+		s := st.Input()
+		i := strings.Index(s, stop)
+		if i < 0 {
+			st.Truncated(strconv.Quote(stop))
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		next, ok := st.Advance(i + len(stop))
+		if !ok {
+			return parser.Nothing[parser.Tuple[string, parser.State]]()
+		}
+		return parser.Just(parser.NewTuple(s[:i], next))
+	})
+}
+
+// charData parses text up to the next markup, with references replaced. The text must not
+// contain "]]>", which only ends CDATA sections; it fails at its '>'.
+func charData() parser.Parser[string] {
+	closing := parser.SatisfyWithMsg(parser.ZeroOrOne(parser.Char('>')), func(m parser.Maybe[rune]) bool {
+		return m.IsNothing()
+	}, `"&gt;" after "]]"`)
+	brackets := parser.Bind(parser.OneOrMore(parser.Char(']')), func(rs []rune) parser.Parser[string] {
+		if len(rs) < 2 {
+			return parser.Pure("]")
+		}
+		return parser.Fmap(closing, func(parser.Maybe[rune]) string { return strings.Repeat("]", len(rs)) })
+	})
+	return parser.Fmap(parser.OneOrMore(parser.OrElse(
+		reference(),
+		run(func(r rune) bool { return r != '<' && r != '&' && r != ']' }),
+		brackets,
+	)), join)
+}
+
+// cdata parses a CDATA section and returns its contents.
+func cdata() parser.Parser[string] {
+	return parser.OmitLeft(parser.Str("<![CDATA["), until("]]>"))
+}
+
+// comment parses a comment.
+func comment() parser.Parser[Comment] {
+	return parser.Fmap(parser.OmitLeft(parser.Str("<!--"), until("-->")), func(s string) Comment { return Comment{Data: s} })
+}
+
+// procInst parses a processing instruction.
+func procInst() parser.Parser[ProcInst] {
+	return parser.OmitLeft(parser.Str("<?"), parser.Bind(xmlName(), func(target string) parser.Parser[ProcInst] {
+		return parser.Fmap(until("?>"), func(inst string) ProcInst {
+			return ProcInst{Target: target, Inst: strings.TrimSpace(inst)}
+		})
+	}))
+}
+
+// directive parses a declaration such as <!DOCTYPE ...>, up to the '>' outside of quotes and of
+// the brackets of an internal subset.
+func directive() parser.Parser[Directive] {
+	body := parser.NewStateParser(func(st parser.State) parser.StateFuncRet[Directive] {
+		s := st.Input()
+		if s == "" || s[0] < 'A' || s[0] > 'Z' {
+			st.Fail("declaration")
+			return parser.Nothing[parser.Tuple[Directive, parser.State]]()
+		}
+		depth := 0
+		var quote byte
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '[':
+				depth++
+			case c == ']' && depth > 0:
+				depth--
+			case c == '>' && depth == 0:
+				next, ok := st.Advance(i + 1)
+				if !ok {
+					return parser.Nothing[parser.Tuple[Directive, parser.State]]()
+				}
+				return parser.Just(parser.NewTuple(Directive{Data: s[:i]}, next))
+			}
+		}
+		st.Truncated("'>'")
+		return parser.Nothing[parser.Tuple[Directive, parser.State]]()
+	})
+	return parser.OmitLeft(parser.Str("<!"), body)
+}
+
+// normalize replaces the whitespace characters of an attribute value by spaces.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isSpace(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// attrValue parses an attribute value in quotes q, with references replaced and whitespace normalized.
+func attrValue(q rune) parser.Parser[string] {
+	chars := parser.Fmap(run(func(r rune) bool { return r != q && r != '<' && r != '&' }), normalize)
+	return parser.Between(parser.Char(q), parser.Fmap(parser.ZeroOrMore(parser.OrElse(reference(), chars)), join), parser.Char(q))
+}
+
+// attribute parses an attribute, name="value" or name='value'.
+func attribute() parser.Parser[Attr] {
+	eq := parser.Between(optSpaces(), parser.Char('='), optSpaces())
+	return parser.Bind(xmlName(), func(name string) parser.Parser[Attr] {
+		return parser.Fmap(parser.OmitLeft(eq, parser.OrElse(attrValue('"'), attrValue('\''))), func(value string) Attr {
+			return Attr{Name: SplitName(name), Value: value}
+		})
+	})
+}
+
+// attributes parses the attributes of a tag after those already seen, whose names they must not repeat.
+func attributes(seen []Attr) parser.Parser[[]Attr] {
+	unique := parser.SatisfyWithMsg(attribute(), func(a Attr) bool {
+		return !slices.ContainsFunc(seen, func(b Attr) bool { return a.Name == b.Name })
+	}, "unique attribute name")
+	return parser.OrElse(
+		parser.Bind(parser.OmitLeft(spaces(), unique), func(a Attr) parser.Parser[[]Attr] {
+			return attributes(append(seen[:len(seen):len(seen)], a))
+		}),
+		parser.Pure(seen),
+	)
+}
+
+// tag is a start tag or an empty-element tag, with its name as written.
+type tag struct {
+	name  string
+	attrs []Attr
+	empty bool
+	// offset is the byte offset of the '<' in the input.
+	offset int
+}
+
+// startTag parses a start tag, or an empty-element tag ending with "/>".
+func startTag() parser.Parser[tag] {
+	return parser.Bind(parser.Pos(), func(offset int) parser.Parser[tag] {
+		return parser.OmitLeft(parser.Char('<'), parser.Bind(xmlName(), func(name string) parser.Parser[tag] {
+			return parser.Bind(attributes(nil), func(attrs []Attr) parser.Parser[tag] {
+				return parser.Fmap(parser.OmitLeft(optSpaces(), parser.OrElse(parser.Str("/>"), parser.Str(">"))), func(end string) tag {
+					return tag{name: name, attrs: attrs, empty: end == "/>", offset: offset}
+				})
+			})
+		}))
+	})
+}
+
+// endTag parses the end tag of the element with the name as written.
+func endTag(name string) parser.Parser[string] {
+	return parser.Between(
+		parser.Str("</"),
+		parser.SatisfyWithMsg(xmlName(), func(n string) bool { return n == name }, "</"+name+">"),
+		parser.OmitLeft(optSpaces(), parser.Char('>')),
+	)
+}
+
+// element returns a parser for an element with its content, which records the offset of the
+// start tag of every element it parses in offsets.
+// Nested elements are parsed by the same parser through a Lazy reference, so the grammar is built only once.
+func element(offsets map[*Element]int) parser.Parser[*Element] {
+	var elem parser.Parser[*Element]
+	ref := parser.Lazy(func() parser.Parser[*Element] { return elem })
+	content := parser.ZeroOrMore(parser.OrElse(
+		parser.Fmap(ref, func(e *Element) Node { return e }),
+		parser.Fmap(charData(), func(s string) Node { return Text{Data: s} }),
+		parser.Fmap(cdata(), func(s string) Node { return Text{Data: s, CDATA: true} }),
+		parser.Fmap(comment(), func(c Comment) Node { return c }),
+		parser.Fmap(procInst(), func(p ProcInst) Node { return p }),
+	))
+	elem = parser.Bind(startTag(), func(t tag) parser.Parser[*Element] {
+		if t.empty {
+			e := &Element{Name: SplitName(t.name), Attrs: t.attrs}
+			offsets[e] = t.offset
+			return parser.Pure(e)
+		}
+		return parser.Fmap(parser.OmitRight(content, endTag(t.name)), func(children []Node) *Element {
+			e := &Element{Name: SplitName(t.name), Attrs: t.attrs, Children: children}
+			offsets[e] = t.offset
+			return e
+		})
+	})
+	return elem
+}
+
+// misc parses the whitespace, comments and processing instructions around the root element,
+// and the document type declaration if directives is set. Whitespace is returned as nil.
+func misc(directives bool) parser.Parser[[]Node] {
+	alts := []parser.Parser[Node]{
+		parser.Fmap(spaces(), func([]rune) Node { return nil }),
+		parser.Fmap(comment(), func(c Comment) Node { return c }),
+		parser.Fmap(procInst(), func(p ProcInst) Node { return p }),
+	}
+	if directives {
+		alts = append(alts, parser.Fmap(directive(), func(d Directive) Node { return d }))
+	}
+	return parser.Fmap(parser.ZeroOrMore(parser.OrElse(alts...)), func(nodes []Node) []Node {
+		return slices.DeleteFunc(nodes, func(n Node) bool { return n == nil })
+	})
+}
+
+// document returns a parser for a complete XML document: a prolog, the root element and an
+// epilog. A leading UTF-8 byte order mark is skipped. The offsets of the start tags of the
+// elements are recorded in offsets.
+func document(offsets map[*Element]int) parser.Parser[Document] {
+	bom := parser.ZeroOrOne(parser.Str("\ufeff"))
+	return parser.OmitLeft(bom, parser.Bind(misc(true), func(prolog []Node) parser.Parser[Document] {
+		return parser.Bind(element(offsets), func(root *Element) parser.Parser[Document] {
+			return parser.Fmap(misc(false), func(epilog []Node) Document {
+				return Document{Prolog: prolog, Root: root, Epilog: epilog}
+			})
+		})
+	}))
+}
+
+// Parse parses the XML document s into an element tree, with the names of elements and
+// attributes resolved against the namespace declarations in scope. It fails with a *ParseError.
+func Parse(s string) (*Document, error) {
+	offsets := make(map[*Element]int)
+	doc, err := parser.Run(document(offsets), s)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if err := resolve(doc.Root, nil, func(e *Element, prefix string) error {
+		return parser.ErrorAt("xml", s, offsets[e], fmt.Errorf("%w: %s", ErrUndeclaredPrefix, prefix))
+	}); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// declare returns the namespace scope of an element with the attributes attrs inside scope,
+// which maps prefixes, and "" for the default namespace, to namespace URIs.
+func declare(scope map[string]string, attrs []Attr) map[string]string {
+	cloned := false
+	for _, a := range attrs {
+		prefix, ok := "", false
+		switch {
+		case a.Name.Prefix == "xmlns":
+			prefix, ok = a.Name.Local, true
+		case a.Name.Prefix == "" && a.Name.Local == "xmlns":
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+		if !cloned {
+			scope, cloned = maps.Clone(scope), true
+			if scope == nil {
+				scope = make(map[string]string)
+			}
+		}
+		scope[prefix] = a.Value
+	}
+	return scope
+}
+
+// bind returns the name n with its namespace from scope. Attributes without a prefix are in
+// no namespace, except namespace declarations. It reports false if the prefix of n is not declared.
+func bind(scope map[string]string, n Name, attr bool) (Name, bool) {
+	switch {
+	case n.Prefix == "xml":
+		n.Space = XMLNamespace
+	case n.Prefix == "xmlns", attr && n.Prefix == "" && n.Local == "xmlns":
+		n.Space = XMLNSNamespace
+	case attr && n.Prefix == "":
+	default:
+		space, ok := scope[n.Prefix]
+		if !ok && n.Prefix != "" {
+			return n, false
+		}
+		n.Space = space
+	}
+	return n, true
+}
+
+// bindAll sets the namespaces of the name and the attributes of an element inside scope, with the
+// namespace declarations among the attributes added to it, and returns the scope of its content.
+// It returns the first prefix that is not declared, if any.
+func bindAll(scope map[string]string, name *Name, attrs []Attr) (map[string]string, string) {
+	scope = declare(scope, attrs)
+	var ok bool
+	if *name, ok = bind(scope, *name, false); !ok {
+		return scope, name.Prefix
+	}
+	for i := range attrs {
+		if attrs[i].Name, ok = bind(scope, attrs[i].Name, true); !ok {
+			return scope, attrs[i].Name.Prefix
+		}
+	}
+	return scope, ""
+}
+
+// resolve sets the namespaces of the names of e and its descendants, inside scope. It stops at
+// the first element using a prefix that is not declared and returns the error of undeclared.
+func resolve(e *Element, scope map[string]string, undeclared func(e *Element, prefix string) error) error {
+	scope, prefix := bindAll(scope, &e.Name, e.Attrs)
+	if prefix != "" {
+		return undeclared(e, prefix)
+	}
+	for _, c := range e.Children {
+		if el, ok := c.(*Element); ok {
+			if err := resolve(el, scope, undeclared); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package xml_test
+
+import (
+	"testing"
+
+	"github.com/81120/tiny-parsec/parser"
+	"github.com/81120/tiny-parsec/xml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := xml.Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE note [<!ELEMENT note (#PCDATA)>]>
+<!-- prolog -->
+<note id='n1' lang="en
+gb">
+  <to>Tove &amp; Jani</to>
+  <body><![CDATA[<b>bold</b>]]> &#169;&#xE9;</body>
+  <?render fast?>
+  <empty/>
+</note>
+<!-- epilog -->
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []xml.Node{
+		xml.ProcInst{Target: "xml", Inst: `version="1.0" encoding="UTF-8"`},
+		xml.Directive{Data: "DOCTYPE note [<!ELEMENT note (#PCDATA)>]"},
+		xml.Comment{Data: " prolog "},
+	}, doc.Prolog)
+	assert.Equal(t, []xml.Node{xml.Comment{Data: " epilog "}}, doc.Epilog)
+
+	root := doc.Root
+	assert.Equal(t, xml.Name{Local: "note"}, root.Name)
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "id"}, Value: "n1"}, {Name: xml.Name{Local: "lang"}, Value: "en gb"}}, root.Attrs)
+	id, ok := root.Attr("id")
+	assert.True(t, ok)
+	assert.Equal(t, "n1", id)
+
+	to, ok := root.Child("to")
+	assert.True(t, ok)
+	assert.Equal(t, []xml.Node{xml.Text{Data: "Tove & Jani"}}, to.Children)
+	body, _ := root.Child("body")
+	assert.Equal(t, []xml.Node{xml.Text{Data: "<b>bold</b>", CDATA: true}, xml.Text{Data: " ©é"}}, body.Children)
+	assert.Equal(t, "<b>bold</b> ©é", body.Text())
+	assert.Len(t, root.Elements(), 3)
+	assert.Contains(t, root.Children, xml.Node(xml.ProcInst{Target: "render", Inst: "fast"}))
+	empty, _ := root.Child("empty")
+	assert.Equal(t, &xml.Element{Name: xml.Name{Local: "empty"}}, empty)
+}
+
+func TestNamespaces(t *testing.T) {
+	doc, err := xml.Parse(`<feed xmlns="urn:atom" xmlns:m="urn:media" xml:lang="en">
+<m:thumbnail m:url="a.png" width="10"/>
+<entry xmlns=""><title>x</title></entry>
+<m:player xmlns:m="urn:other"/>
+</feed>`)
+	assert.NoError(t, err)
+	root := doc.Root
+	assert.Equal(t, xml.Name{Space: "urn:atom", Local: "feed"}, root.Name)
+	lang, ok := root.AttrNS(xml.XMLNamespace, "lang")
+	assert.True(t, ok)
+	assert.Equal(t, "en", lang)
+	assert.Equal(t, xml.Name{Space: xml.XMLNSNamespace, Prefix: "xmlns", Local: "m"}, root.Attrs[1].Name)
+
+	elems := root.Elements()
+	assert.Equal(t, xml.Name{Space: "urn:media", Prefix: "m", Local: "thumbnail"}, elems[0].Name)
+	url, ok := elems[0].AttrNS("urn:media", "url")
+	assert.True(t, ok)
+	assert.Equal(t, "a.png", url)
+	width, ok := elems[0].AttrNS("", "width")
+	assert.True(t, ok)
+	assert.Equal(t, "10", width)
+
+	assert.Equal(t, xml.Name{Local: "entry"}, elems[1].Name)
+	assert.Equal(t, xml.Name{Local: "title"}, elems[1].Elements()[0].Name)
+	assert.Equal(t, xml.Name{Space: "urn:other", Prefix: "m", Local: "player"}, elems[2].Name)
+}
+
+func TestSplitName(t *testing.T) {
+	assert.Equal(t, xml.Name{Prefix: "svg", Local: "rect"}, xml.SplitName("svg:rect"))
+	assert.Equal(t, xml.Name{Local: "rect"}, xml.SplitName("rect"))
+	assert.Equal(t, xml.Name{Local: ":rect"}, xml.SplitName(":rect"))
+	assert.Equal(t, "svg:rect", xml.Name{Space: "urn:svg", Prefix: "svg", Local: "rect"}.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+		msg   string
+	}{
+		{"mismatched end tag", "<a><b></a>", parser.ErrNoMatch, "xml: line 1, col 9: unexpected 'a', expected </b>"},
+		{"duplicate attribute", "<a x='1' x=\"2\"/>", parser.ErrNoMatch, "xml: line 1, col 10: unexpected 'x', expected unique attribute name"},
+		{"unknown entity", "<a>&nbsp;</a>", parser.ErrNoMatch, "xml: line 1, col 5: unexpected 'n', expected entity name"},
+		{"unclosed element", "<a>\n<b/>", parser.ErrUnexpectedEOF, "xml: line 2, col 5: unexpected end of input"},
+		{"unterminated comment", "<a><!-- x</a>", parser.ErrUnexpectedEOF, `xml: line 1, col 14: unexpected end of input, expected "-->"`},
+		{"two roots", "<a/><b/>", parser.ErrNoMatch, "xml: line 1, col 5: unexpected '<'"},
+		{"text outside root", "<a/> x", parser.ErrNoMatch, "xml: line 1, col 6: unexpected 'x'"},
+		{"unquoted attribute", "<a x=1/>", parser.ErrNoMatch, "xml: line 1, col 6: unexpected '1'"},
+		{"empty", "", parser.ErrUnexpectedEOF, "xml: line 1, col 1: unexpected end of input"},
+		{"end of CDATA in text", "<a>x]]>y</a>", parser.ErrNoMatch, `xml: line 1, col 7: unexpected '>', expected "&gt;" after "]]"`},
+		{"invalid hexadecimal reference", "<a>&#xZZ;</a>", xml.ErrInvalidCharRef, "xml: line 1, col 4: invalid character reference"},
+		{"invalid decimal reference", "<a x='&#0;'/>", xml.ErrInvalidCharRef, "xml: line 1, col 7: invalid character reference"},
+		{"undeclared element prefix", "<a>\n <u:b/></a>", xml.ErrUndeclaredPrefix, "xml: line 2, col 2: undeclared namespace prefix: u"},
+		{"undeclared attribute prefix", "<a xmlns:m='urn:m'><b m:x='1' n:y='2'/></a>", xml.ErrUndeclaredPrefix, "xml: line 1, col 20: undeclared namespace prefix: n"},
+		{"prefix out of scope", "<a><b xmlns:m='urn:m'/><m:c/></a>", xml.ErrUndeclaredPrefix, "xml: line 1, col 24: undeclared namespace prefix: m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := xml.Parse(tt.input)
+			var perr *xml.ParseError
+			assert.ErrorAs(t, err, &perr)
+			assert.ErrorIs(t, err, tt.err)
+			assert.ErrorContains(t, err, tt.msg)
+		})
+	}
+}